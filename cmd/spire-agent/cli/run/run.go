@@ -51,6 +51,7 @@ const (
 	defaultDefaultBundleName           = "ROOTCA"
 	defaultDefaultAllBundlesName       = "ALL"
 	defaultDisableSPIFFECertValidation = false
+	defaultRebootstrapDelay            = "10m"
 
 	minimumAvailabilityTarget = 24 * time.Hour
 )
@@ -92,6 +93,8 @@ type agentConfig struct {
 	AvailabilityTarget            string    `hcl:"availability_target"`
 	X509SVIDCacheMaxSize          int       `hcl:"x509_svid_cache_max_size"`
 	JWTSVIDCacheMaxSize           int       `hcl:"jwt_svid_cache_max_size"`
+	CacheSnapshotEnabled          bool      `hcl:"cache_snapshot_enabled"`
+	CacheSnapshotInterval         string    `hcl:"cache_snapshot_interval"`
 
 	AuthorizedDelegates []string `hcl:"authorized_delegates"`
 
@@ -120,6 +123,8 @@ type workloadAPIRateLimitConfig struct {
 	FetchJWTSVID     *int `hcl:"fetch_jwt_svid"`
 	FetchX509Bundles *int `hcl:"fetch_x509_bundles"`
 	FetchJWTBundles  *int `hcl:"fetch_jwt_bundles"`
+	FetchWITSVID     *int `hcl:"fetch_wit_svid"`
+	FetchWITBundles  *int `hcl:"fetch_wit_bundles"`
 	StreamSecrets    *int `hcl:"stream_secrets"`
 	FetchSecrets     *int `hcl:"fetch_secrets"`
 
@@ -197,7 +202,60 @@ func LoadConfig(name string, args []string, logOptions []log.Option, output io.W
 		return nil, fmt.Errorf("error loading feature flags: %w", err)
 	}
 
-	return NewAgentConfig(input, logOptions, allowUnknownConfig)
+	ac, err := NewAgentConfig(input, logOptions, allowUnknownConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ac.ConfigLoader = newConfigLoader(input, cliInput)
+	return ac, nil
+}
+
+// reloadableSettings are the settings that can be changed while the agent is
+// running, named after their configuration keys.
+var reloadableSettings = []string{
+	"agent.experimental.feature_flags",
+	"telemetry",
+	"plugins",
+}
+
+// newConfigLoader returns the loader used to reload the configuration while
+// the agent is running. Changes are tracked against the given configuration
+// the agent was started with.
+func newConfigLoader(startup *Config, cliInput *agentConfig) agent.ConfigLoader {
+	tracker := config.NewChangeTracker(startup, reloadableSettings...)
+
+	return func() (*agent.ReloadableConfig, error) {
+		fileInput, err := ParseFile(cliInput.ConfigPath, cliInput.ExpandEnv)
+		if err != nil {
+			return nil, err
+		}
+		c, err := mergeInput(fileInput, cliInput)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateConfig(c); err != nil {
+			return nil, err
+		}
+		// Fill in the defaults set by NewAgentConfig, so they are not
+		// reported as changes.
+		if c.Agent.RebootstrapDelay == "" {
+			c.Agent.RebootstrapDelay = defaultRebootstrapDelay
+		}
+
+		pluginConfigs, err := catalog.PluginConfigsFromHCLNode(c.Plugins)
+		if err != nil {
+			return nil, err
+		}
+
+		rc := &agent.ReloadableConfig{
+			Telemetry:     c.Telemetry,
+			PluginConfigs: pluginConfigs,
+			FeatureFlags:  c.Agent.Experimental.Flags,
+		}
+		rc.Changed, rc.RequiresRestart = tracker.Track(c)
+		return rc, nil
+	}
 }
 
 func (cmd *Command) Run(args []string) int {
@@ -427,7 +485,7 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	}
 
 	if c.Agent.RebootstrapDelay == "" {
-		c.Agent.RebootstrapDelay = "10m"
+		c.Agent.RebootstrapDelay = defaultRebootstrapDelay
 	}
 	delay, err := time.ParseDuration(c.Agent.RebootstrapDelay)
 	if err != nil {
@@ -507,6 +565,21 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	}
 	ac.JWTSVIDCacheMaxSize = c.Agent.JWTSVIDCacheMaxSize
 
+	ac.CacheSnapshotEnabled = c.Agent.CacheSnapshotEnabled
+	if c.Agent.CacheSnapshotInterval != "" {
+		if !c.Agent.CacheSnapshotEnabled {
+			return nil, errors.New("cache_snapshot_interval requires cache_snapshot_enabled")
+		}
+		interval, err := time.ParseDuration(c.Agent.CacheSnapshotInterval)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse cache_snapshot_interval: %w", err)
+		}
+		if interval <= 0 {
+			return nil, errors.New("cache_snapshot_interval must be positive")
+		}
+		ac.CacheSnapshotInterval = interval
+	}
+
 	td, err := common_cli.ParseTrustDomain(c.Agent.TrustDomain, logger)
 	if err != nil {
 		return nil, err
@@ -613,6 +686,10 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		ac.AvailabilityTarget = t
 	}
 
+	ac.IsWITSVIDsDisabled = func() bool {
+		return !fflag.IsSet(fflag.FlagWITSVID)
+	}
+
 	ac.TLSPolicy = tlspolicy.Policy{
 		RequirePQKEM: c.Agent.Experimental.RequirePQKEM,
 	}
@@ -630,6 +707,8 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		FetchJWTSVID:     intVal(c.Agent.Experimental.RateLimit.FetchJWTSVID),
 		FetchX509Bundles: intVal(c.Agent.Experimental.RateLimit.FetchX509Bundles),
 		FetchJWTBundles:  intVal(c.Agent.Experimental.RateLimit.FetchJWTBundles),
+		FetchWITSVID:     intVal(c.Agent.Experimental.RateLimit.FetchWITSVID),
+		FetchWITBundles:  intVal(c.Agent.Experimental.RateLimit.FetchWITBundles),
 		StreamSecrets:    intVal(c.Agent.Experimental.RateLimit.StreamSecrets),
 		FetchSecrets:     intVal(c.Agent.Experimental.RateLimit.FetchSecrets),
 	}
@@ -645,6 +724,12 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	if ac.WorkloadAPIRateLimit.FetchJWTBundles < 0 {
 		return nil, errors.New("experimental.ratelimit.fetch_jwt_bundles must not be negative")
 	}
+	if ac.WorkloadAPIRateLimit.FetchWITSVID < 0 {
+		return nil, errors.New("experimental.ratelimit.fetch_wit_svid must not be negative")
+	}
+	if ac.WorkloadAPIRateLimit.FetchWITBundles < 0 {
+		return nil, errors.New("experimental.ratelimit.fetch_wit_bundles must not be negative")
+	}
 	if ac.WorkloadAPIRateLimit.StreamSecrets < 0 {
 		return nil, errors.New("experimental.ratelimit.stream_secrets must not be negative")
 	}
@@ -727,6 +812,14 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		detectedUnknown("InMem", p.UnusedKeyPositions)
 	}
 
+	if p := c.Telemetry.OTLP; p != nil && len(p.UnusedKeyPositions) != 0 {
+		detectedUnknown("OTLP", p.UnusedKeyPositions)
+	}
+
+	if p := c.Telemetry.Tracing; p != nil && len(p.UnusedKeyPositions) != 0 {
+		detectedUnknown("Tracing", p.UnusedKeyPositions)
+	}
+
 	if len(c.HealthChecks.UnusedKeyPositions) != 0 {
 		detectedUnknown("health check", c.HealthChecks.UnusedKeyPositions)
 	}
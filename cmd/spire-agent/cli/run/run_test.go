@@ -15,6 +15,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent"
 	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/agent/workloadkey"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/test/spiretest"
@@ -968,6 +969,58 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "cache_snapshot_enabled is not set",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.False(t, c.CacheSnapshotEnabled)
+				require.Zero(t, c.CacheSnapshotInterval)
+			},
+		},
+		{
+			msg: "cache_snapshot_enabled and cache_snapshot_interval are set",
+			input: func(c *Config) {
+				c.Agent.CacheSnapshotEnabled = true
+				c.Agent.CacheSnapshotInterval = "5m"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.True(t, c.CacheSnapshotEnabled)
+				require.Equal(t, 5*time.Minute, c.CacheSnapshotInterval)
+			},
+		},
+		{
+			msg:         "cache_snapshot_interval without cache_snapshot_enabled",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.CacheSnapshotInterval = "5m"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "cache_snapshot_interval is invalid",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.CacheSnapshotEnabled = true
+				c.Agent.CacheSnapshotInterval = "often"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "cache_snapshot_interval is not positive",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.CacheSnapshotEnabled = true
+				c.Agent.CacheSnapshotInterval = "0s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "allowed_foreign_jwt_claims provided",
 			input: func(c *Config) {
@@ -1170,15 +1223,17 @@ func TestNewAgentConfig(t *testing.T) {
 			},
 		},
 		{
-			msg: "ratelimit all six knobs are configurable",
+			msg: "ratelimit all knobs are configurable",
 			input: func(c *Config) {
-				a, b, d, e, f, g := 10, 20, 30, 40, 50, 60
+				a, b, d, e, f, g, h, i := 10, 20, 30, 40, 50, 60, 70, 80
 				c.Agent.Experimental.RateLimit.FetchX509SVID = &a
 				c.Agent.Experimental.RateLimit.FetchJWTSVID = &b
 				c.Agent.Experimental.RateLimit.FetchX509Bundles = &d
 				c.Agent.Experimental.RateLimit.FetchJWTBundles = &e
 				c.Agent.Experimental.RateLimit.StreamSecrets = &f
 				c.Agent.Experimental.RateLimit.FetchSecrets = &g
+				c.Agent.Experimental.RateLimit.FetchWITSVID = &h
+				c.Agent.Experimental.RateLimit.FetchWITBundles = &i
 			},
 			test: func(t *testing.T, ac *agent.Config) {
 				require.Equal(t, agent.WorkloadAPIRateLimitConfig{
@@ -1186,6 +1241,8 @@ func TestNewAgentConfig(t *testing.T) {
 					FetchJWTSVID:     20,
 					FetchX509Bundles: 30,
 					FetchJWTBundles:  40,
+					FetchWITSVID:     70,
+					FetchWITBundles:  80,
 					StreamSecrets:    50,
 					FetchSecrets:     60,
 				}, ac.WorkloadAPIRateLimit)
@@ -1213,6 +1270,28 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Nil(t, ac)
 			},
 		},
+		{
+			msg:         "ratelimit fetch_wit_svid negative value returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				v := -1
+				c.Agent.Experimental.RateLimit.FetchWITSVID = &v
+			},
+			test: func(t *testing.T, ac *agent.Config) {
+				require.Nil(t, ac)
+			},
+		},
+		{
+			msg:         "ratelimit fetch_wit_bundles negative value returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				v := -1
+				c.Agent.Experimental.RateLimit.FetchWITBundles = &v
+			},
+			test: func(t *testing.T, ac *agent.Config) {
+				require.Nil(t, ac)
+			},
+		},
 		{
 			msg:         "ratelimit stream_secrets negative value returns an error",
 			expectError: true,
@@ -1387,6 +1466,26 @@ func TestWarnOnUnknownConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			msg:      "in nested OTLP block",
+			confFile: "server_and_agent_bad_nested_OTLP_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "OTLP",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in nested Tracing block",
+			confFile: "server_and_agent_bad_nested_Tracing_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "Tracing",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
 		{
 			msg:      "in nested health_checks block",
 			confFile: "server_and_agent_bad_nested_health_checks_block.conf",
@@ -1555,6 +1654,83 @@ func TestLogOptions(t *testing.T) {
 	require.Equal(t, fd.Name(), logger.Out.(*log.ReopenableFile).Name())
 }
 
+func TestConfigLoader(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	configPath := filepath.Join(dir, "agent.conf")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`agent {
+	data_dir = "`+dir+`"
+	server_address = "127.0.0.1"
+	server_port = "8081"
+	trust_domain = "example.org"
+	insecure_bootstrap = true
+}
+plugins {
+	KeyManager "memory" {
+		plugin_data {}
+	}
+}`), 0o600))
+
+	cliInput := &agentConfig{ConfigPath: configPath}
+	fileInput, err := ParseFile(configPath, false)
+	require.NoError(t, err)
+	input, err := mergeInput(fileInput, cliInput)
+	require.NoError(t, err)
+	_, err = NewAgentConfig(input, []log.Option{log.WithOutputFile(os.DevNull)}, false)
+	require.NoError(t, err)
+	loadConfig := newConfigLoader(input, cliInput)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`agent {
+	data_dir = "`+dir+`"
+	server_address = "127.0.0.1"
+	server_port = "8082"
+	trust_domain = "example.org"
+	insecure_bootstrap = true
+	experimental {
+		feature_flags = ["wit-svid"]
+	}
+}
+plugins {
+	KeyManager "memory" {
+		plugin_data {
+			foo = "bar"
+		}
+	}
+}
+telemetry {
+	MetricPrefix = "spire"
+}`), 0o600))
+
+	rc, err := loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"agent.experimental.feature_flags",
+		"plugins",
+		"telemetry.MetricPrefix",
+	}, rc.Changed)
+	assert.Equal(t, []string{"agent.server_port"}, rc.RequiresRestart)
+	assert.Equal(t, fflag.RawConfig{"wit-svid"}, rc.FeatureFlags)
+	assert.Equal(t, "spire", rc.Telemetry.MetricPrefix)
+	require.Len(t, rc.PluginConfigs, 1)
+	data, err := rc.PluginConfigs[0].DataSource.Load()
+	require.NoError(t, err)
+	assert.Contains(t, data, "foo")
+
+	// Reloading the same configuration again reports no changes, but the
+	// changes that require a restart are still reported.
+	rc, err = loadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, rc.Changed)
+	assert.Equal(t, []string{"agent.server_port"}, rc.RequiresRestart)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`plugins {}`), 0o600))
+	_, err = loadConfig()
+	require.EqualError(t, err, "server_address must be configured")
+
+	_, err = newConfigLoader(input, &agentConfig{ConfigPath: filepath.Join(dir, "missing.conf")})()
+	require.ErrorContains(t, err, "could not find config file")
+}
+
 func TestExpandEnv(t *testing.T) {
 	require.NoError(t, os.Setenv("TEST_DATA_TRUST_DOMAIN", "example.org"))
 
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+)
+
+const verifyCommandName = "audit verify"
+
+// NewVerifyCommand creates a new "verify" subcommand for "audit" command.
+func NewVerifyCommand() cli.Command {
+	return newVerifyCommand(commoncli.DefaultEnv)
+}
+
+func newVerifyCommand(env *commoncli.Env) *verifyCommand {
+	c := &verifyCommand{
+		env:   env,
+		flags: flag.NewFlagSet(verifyCommandName, flag.ContinueOnError),
+	}
+	c.flags.SetOutput(env.Stderr)
+	c.flags.StringVar(&c.path, "path", "", "Path to the audit log written by the audit log sink. If set to '-', the audit log is read from stdin")
+	c.flags.StringVar(&c.keyFile, "keyFile", "", "Path to the key the audit log records are hashed with, as configured in the audit log sink")
+	c.flags.BoolVar(&c.allowNewChains, "allowNewChains", false, "Accept records starting a new chain after the first one, as written by the syslog and webhook outputs every time the server starts")
+	c.flags.Uint64Var(&c.checkpointSeq, "checkpointSeq", 0, "Sequence number of the last checkpoint logged by the audit log sink (optional). Requires checkpointHash")
+	c.flags.StringVar(&c.checkpointHash, "checkpointHash", "", "Hash of the last checkpoint logged by the audit log sink (optional). Requires checkpointSeq")
+	return c
+}
+
+type verifyCommand struct {
+	env   *commoncli.Env
+	flags *flag.FlagSet
+
+	// Path to the audit log
+	path string
+
+	// Path to the key the records are hashed with
+	keyFile string
+
+	// Checkpoint the audit log must contain
+	checkpointSeq  uint64
+	checkpointHash string
+
+	// Whether records can start new chains after the first one
+	allowNewChains bool
+}
+
+func (c *verifyCommand) Help() string {
+	return c.flags.Parse([]string{"-h"}).Error()
+}
+
+func (c *verifyCommand) Synopsis() string {
+	return "Verifies the hash chain of an audit log written by the audit log sink"
+}
+
+func (c *verifyCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if err := c.run(); err != nil {
+		_ = c.env.ErrPrintln("Error: " + err.Error())
+		return 1
+	}
+	return 0
+}
+
+func (c *verifyCommand) run() error {
+	if c.path == "" {
+		return errors.New("a path to the audit log is required")
+	}
+	if c.keyFile == "" {
+		return errors.New("a path to the audit log key is required")
+	}
+	if (c.checkpointSeq == 0) != (c.checkpointHash == "") {
+		return errors.New("the checkpoint sequence number and hash must be set together")
+	}
+
+	key, err := audit.LoadKey(c.env.JoinPath(c.keyFile))
+	if err != nil {
+		return err
+	}
+	var checkpoint *audit.Checkpoint
+	if c.checkpointSeq != 0 {
+		checkpoint = &audit.Checkpoint{
+			Seq:  c.checkpointSeq,
+			Hash: c.checkpointHash,
+		}
+	}
+
+	var r io.Reader = c.env.Stdin
+	if c.path != "-" {
+		f, err := os.Open(c.env.JoinPath(c.path))
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := audit.Verify(r, audit.VerifyOptions{
+		Key:            key,
+		Checkpoint:     checkpoint,
+		AllowNewChains: c.allowNewChains,
+	})
+	if err != nil {
+		return fmt.Errorf("audit log verification failed: %w", err)
+	}
+
+	if err := c.env.Printf("Audit log verified: %d records in %d chain(s)\n", result.Records, len(result.Chains)); err != nil {
+		return err
+	}
+	for i, chain := range result.Chains {
+		if err := c.env.Printf("Chain %d: %d records starting at line %d (%s)\n", i+1, chain.Records, chain.Line, chain.Start.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
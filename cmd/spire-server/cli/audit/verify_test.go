@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyHelp(t *testing.T) {
+	cmd, _, stderr := setupTest(t)
+	require.Equal(t, "flag: help requested", cmd.Help())
+	require.Contains(t, stderr.String(), "Usage of audit verify:")
+}
+
+func TestVerifySynopsis(t *testing.T) {
+	cmd, _, _ := setupTest(t)
+	require.Equal(t, "Verifies the hash chain of an audit log written by the audit log sink", cmd.Synopsis())
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "audit.key")
+	require.NoError(t, os.WriteFile(keyPath, bytes.Repeat([]byte("k"), audit.MinKeySize), 0600))
+	otherKeyPath := filepath.Join(dir, "other.key")
+	require.NoError(t, os.WriteFile(otherKeyPath, bytes.Repeat([]byte("o"), audit.MinKeySize), 0600))
+	shortKeyPath := filepath.Join(dir, "short.key")
+	require.NoError(t, os.WriteFile(shortKeyPath, []byte("short"), 0600))
+
+	path := filepath.Join(dir, "audit.log")
+	checkpointSeq, checkpointHash := writeAuditLog(t, path, keyPath, 3)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	tamperedPath := filepath.Join(dir, "tampered.log")
+	require.NoError(t, os.WriteFile(tamperedPath, []byte(lines[0]+"\n"+lines[2]+"\n"), 0600))
+	truncatedPath := filepath.Join(dir, "truncated.log")
+	require.NoError(t, os.WriteFile(truncatedPath, []byte(lines[0]+"\n"+lines[1]+"\n"), 0600))
+
+	// A second chain is started when the sink can't resume the chain
+	restartedPath := filepath.Join(dir, "restarted.log")
+	writeAuditLog(t, restartedPath, keyPath, 2)
+	restarted, err := os.ReadFile(restartedPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(restartedPath, append(append([]byte{}, data...), restarted...), 0600))
+	chainStarts := recordTimes(t, keyPath, path, restartedPath)
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		stdin     string
+		expCode   int
+		expStdout string
+		expStderr string
+	}{
+		{
+			name:      "missing path",
+			expCode:   1,
+			expStderr: "Error: a path to the audit log is required\n",
+		},
+		{
+			name:      "missing key file",
+			args:      []string{"-path", path},
+			expCode:   1,
+			expStderr: "Error: a path to the audit log key is required\n",
+		},
+		{
+			name:      "key too short",
+			args:      []string{"-path", path, "-keyFile", shortKeyPath},
+			expCode:   1,
+			expStderr: "Error: audit log key must be at least 32 bytes long, got 5\n",
+		},
+		{
+			name:      "checkpoint hash without sequence number",
+			args:      []string{"-path", path, "-keyFile", keyPath, "-checkpointHash", checkpointHash},
+			expCode:   1,
+			expStderr: "Error: the checkpoint sequence number and hash must be set together\n",
+		},
+		{
+			name:      "file does not exist",
+			args:      []string{"-path", filepath.Join(dir, "missing.log"), "-keyFile", keyPath},
+			expCode:   1,
+			expStderr: "Error: failed to open audit log: ",
+		},
+		{
+			name:      "valid audit log",
+			args:      []string{"-path", path, "-keyFile", keyPath},
+			expStdout: "Audit log verified: 3 records in 1 chain(s)\nChain 1: 3 records starting at line 1 (" + chainStarts[0] + ")\n",
+		},
+		{
+			name:      "valid audit log from stdin",
+			args:      []string{"-path", "-", "-keyFile", keyPath},
+			stdin:     string(data),
+			expStdout: "Audit log verified: 3 records in 1 chain(s)\nChain 1: 3 records starting at line 1 (" + chainStarts[0] + ")\n",
+		},
+		{
+			name:      "new chain started",
+			args:      []string{"-path", restartedPath, "-keyFile", keyPath},
+			expCode:   1,
+			expStderr: "Error: audit log verification failed: line 4: unexpected start of a new chain\n",
+		},
+		{
+			name: "new chain started when allowed",
+			args: []string{"-path", restartedPath, "-keyFile", keyPath, "-allowNewChains"},
+			expStdout: "Audit log verified: 5 records in 2 chain(s)\n" +
+				"Chain 1: 3 records starting at line 1 (" + chainStarts[0] + ")\n" +
+				"Chain 2: 2 records starting at line 4 (" + chainStarts[1] + ")\n",
+		},
+		{
+			name:      "wrong key",
+			args:      []string{"-path", path, "-keyFile", otherKeyPath},
+			expCode:   1,
+			expStderr: "Error: audit log verification failed: line 1: record hash does not match\n",
+		},
+		{
+			name:      "checkpoint found",
+			args:      []string{"-path", path, "-keyFile", keyPath, "-checkpointSeq", strconv.FormatUint(checkpointSeq, 10), "-checkpointHash", checkpointHash},
+			expStdout: "Audit log verified: 3 records in 1 chain(s)\nChain 1: 3 records starting at line 1 (" + chainStarts[0] + ")\n",
+		},
+		{
+			name:      "truncated audit log",
+			args:      []string{"-path", truncatedPath, "-keyFile", keyPath, "-checkpointSeq", strconv.FormatUint(checkpointSeq, 10), "-checkpointHash", checkpointHash},
+			expCode:   1,
+			expStderr: "Error: audit log verification failed: checkpoint record 3 not found: records were removed from the end of the chain\n",
+		},
+		{
+			name:      "tampered audit log",
+			args:      []string{"-path", tamperedPath, "-keyFile", keyPath},
+			expCode:   1,
+			expStderr: "Error: audit log verification failed: line 2: expected sequence number 2, got 3\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, stdout, stderr := setupTest(t)
+			cmd.env.Stdin = strings.NewReader(tt.stdin)
+
+			code := cmd.Run(tt.args)
+			assert.Equal(t, tt.expCode, code)
+			assert.Equal(t, tt.expStdout, stdout.String())
+			if tt.expStderr == "" {
+				assert.Empty(t, stderr.String())
+			} else {
+				assert.True(t, strings.HasPrefix(stderr.String(), tt.expStderr), "unexpected stderr: %s", stderr.String())
+			}
+		})
+	}
+}
+
+func setupTest(t *testing.T) (*verifyCommand, *bytes.Buffer, *bytes.Buffer) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newVerifyCommand(&commoncli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	return cmd, stdout, stderr
+}
+
+// recordTimes returns the time of the first record of each audit log.
+func recordTimes(t *testing.T, keyPath string, paths ...string) []string {
+	key, err := audit.LoadKey(keyPath)
+	require.NoError(t, err)
+
+	var times []string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		result, err := audit.Verify(f, audit.VerifyOptions{Key: key, AllowNewChains: true})
+		f.Close()
+		require.NoError(t, err)
+		times = append(times, result.Chains[len(result.Chains)-1].Start.Format(time.RFC3339))
+	}
+	return times
+}
+
+// writeAuditLog writes the records to the audit log, returning the checkpoint
+// logged by the sink.
+func writeAuditLog(t *testing.T, path, keyPath string, records int) (uint64, string) {
+	log, logHook := test.NewNullLogger()
+	sink, err := audit.NewChainSink(audit.SinkConfig{KeyFile: keyPath, File: &audit.FileSinkConfig{Path: path}}, log)
+	require.NoError(t, err)
+	for range records {
+		sink.Append(logrus.Fields{"type": "audit"})
+	}
+	require.NoError(t, sink.Close())
+
+	checkpoint := logHook.LastEntry()
+	require.Equal(t, "Audit log chain checkpoint", checkpoint.Message)
+	return checkpoint.Data[telemetry.SequenceNumber].(uint64), checkpoint.Data[telemetry.Hash].(string)
+}
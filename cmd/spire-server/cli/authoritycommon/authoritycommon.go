@@ -11,6 +11,10 @@ func PrettyPrintJWTAuthorityState(env *commoncli.Env, authorityState *localautho
 	prettyPrintAuthorityState(env, authorityState, false)
 }
 
+func PrettyPrintWITAuthorityState(env *commoncli.Env, authorityState *localauthorityv1.AuthorityState) {
+	prettyPrintAuthorityState(env, authorityState, false)
+}
+
 func PrettyPrintX509AuthorityState(env *commoncli.Env, authorityState *localauthorityv1.AuthorityState) {
 	prettyPrintAuthorityState(env, authorityState, true)
 }
@@ -9,12 +9,17 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
+	"github.com/spiffe/spire/cmd/spire-server/cli/audit"
 	"github.com/spiffe/spire/cmd/spire-server/cli/bundle"
+	"github.com/spiffe/spire/cmd/spire-server/cli/datastore"
 	"github.com/spiffe/spire/cmd/spire-server/cli/entry"
+	"github.com/spiffe/spire/cmd/spire-server/cli/entrytemplate"
+	"github.com/spiffe/spire/cmd/spire-server/cli/featureflags"
 	"github.com/spiffe/spire/cmd/spire-server/cli/federation"
 	"github.com/spiffe/spire/cmd/spire-server/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-server/cli/jwt"
 	localauthority_jwt "github.com/spiffe/spire/cmd/spire-server/cli/localauthority/jwt"
+	localauthority_wit "github.com/spiffe/spire/cmd/spire-server/cli/localauthority/wit"
 	localauthority_x509 "github.com/spiffe/spire/cmd/spire-server/cli/localauthority/x509"
 	"github.com/spiffe/spire/cmd/spire-server/cli/logger"
 	"github.com/spiffe/spire/cmd/spire-server/cli/run"
@@ -57,6 +62,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"agent purge": func() (cli.Command, error) {
 			return agent.NewPurgeCommand(), nil
 		},
+		"audit verify": func() (cli.Command, error) {
+			return audit.NewVerifyCommand(), nil
+		},
 		"bundle count": func() (cli.Command, error) {
 			return bundle.NewCountCommand(), nil
 		},
@@ -72,6 +80,15 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"bundle delete": func() (cli.Command, error) {
 			return bundle.NewDeleteCommand(), nil
 		},
+		"datastore export": func() (cli.Command, error) {
+			return datastore.NewExportCommand(), nil
+		},
+		"datastore import": func() (cli.Command, error) {
+			return datastore.NewImportCommand(), nil
+		},
+		"entry apply": func() (cli.Command, error) {
+			return entry.NewApplyCommand(), nil
+		},
 		"entry count": func() (cli.Command, error) {
 			return entry.NewCountCommand(), nil
 		},
@@ -87,6 +104,15 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"entry show": func() (cli.Command, error) {
 			return entry.NewShowCommand(), nil
 		},
+		"entry template create": func() (cli.Command, error) {
+			return entrytemplate.NewCreateCommand(), nil
+		},
+		"entry template show": func() (cli.Command, error) {
+			return entrytemplate.NewShowCommand(), nil
+		},
+		"entry template delete": func() (cli.Command, error) {
+			return entrytemplate.NewDeleteCommand(), nil
+		},
 		"federation create": func() (cli.Command, error) {
 			return federation.NewCreateCommand(), nil
 		},
@@ -105,6 +131,12 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"federation update": func() (cli.Command, error) {
 			return federation.NewUpdateCommand(), nil
 		},
+		"featureflags list": func() (cli.Command, error) {
+			return featureflags.NewListCommand(), nil
+		},
+		"featureflags reload": func() (cli.Command, error) {
+			return featureflags.NewReloadCommand(), nil
+		},
 		"logger get": func() (cli.Command, error) {
 			return logger.NewGetCommand(), nil
 		},
@@ -162,6 +194,21 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"localauthority jwt revoke": func() (cli.Command, error) {
 			return localauthority_jwt.NewJWTRevokeCommand(), nil
 		},
+		"localauthority wit show": func() (cli.Command, error) {
+			return localauthority_wit.NewWITShowCommand(), nil
+		},
+		"localauthority wit prepare": func() (cli.Command, error) {
+			return localauthority_wit.NewWITPrepareCommand(), nil
+		},
+		"localauthority wit activate": func() (cli.Command, error) {
+			return localauthority_wit.NewWITActivateCommand(), nil
+		},
+		"localauthority wit taint": func() (cli.Command, error) {
+			return localauthority_wit.NewWITTaintCommand(), nil
+		},
+		"localauthority wit revoke": func() (cli.Command, error) {
+			return localauthority_wit.NewWITRevokeCommand(), nil
+		},
 		"upstreamauthority taint": func() (cli.Command, error) {
 			return upstreamauthority.NewTaintCommand(), nil
 		},
@@ -0,0 +1,149 @@
+package datastore
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultConfigPath = "conf/server/server.conf"
+
+	// serverCheckTimeout bounds the check for a running server.
+	serverCheckTimeout = 5 * time.Second
+)
+
+// configFlags are the flags shared by the datastore commands to locate the
+// server configuration, which is used to open the datastore directly.
+type configFlags struct {
+	configPath string
+	expandEnv  bool
+}
+
+func (f *configFlags) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.configPath, "config", defaultConfigPath, "Path to the SPIRE server configuration file")
+	fs.BoolVar(&f.expandEnv, "expandEnv", false, "Expand environment variables in the SPIRE server configuration file")
+}
+
+// openDataStore loads the server configuration and opens the datastore it
+// configures. The caller must close the returned datastore. The datastore is
+// only opened if the server is stopped.
+func (f *configFlags) openDataStore(ctx context.Context, env *commoncli.Env) (catalog.DataStoreCloser, spiffeid.TrustDomain, error) {
+	input, err := run.ParseFileWithDefaults(env.JoinPath(f.configPath), f.expandEnv)
+	if err != nil {
+		return nil, spiffeid.TrustDomain{}, err
+	}
+
+	config, err := run.NewServerConfig(input, []log.Option{log.WithOutputWriter(io.Discard)}, false)
+	if err != nil {
+		return nil, spiffeid.TrustDomain{}, err
+	}
+
+	if err := checkServerStopped(ctx, config.BindLocalAddress); err != nil {
+		return nil, spiffeid.TrustDomain{}, err
+	}
+
+	ds, err := catalog.LoadDataStore(ctx, config.Log, config.TrustDomain, config.PluginConfigs)
+	if err != nil {
+		return nil, spiffeid.TrustDomain{}, fmt.Errorf("failed to open datastore: %w", err)
+	}
+	return ds, config.TrustDomain, nil
+}
+
+// checkServerStopped fails if a server answers on the local API address of
+// the configuration. The datastore is opened directly, which is only safe
+// while the server is stopped: the kv datastore is locked by the server, and
+// the server would not notice the changes made behind its caches.
+func checkServerStopped(ctx context.Context, addr net.Addr) error {
+	target, err := util.GetTargetName(addr)
+	if err != nil {
+		return fmt.Errorf("failed to check whether the server is running: %w", err)
+	}
+	conn, err := util.NewGRPCClient(target)
+	if err != nil {
+		return fmt.Errorf("failed to check whether the server is running: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, serverCheckTimeout)
+	defer cancel()
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if status.Code(err) == codes.Unavailable {
+		return nil
+	}
+	return fmt.Errorf("a server is running on %s: the server must be stopped before the datastore is exported or imported", addr)
+}
+
+// command is implemented by the datastore commands. Unlike the commands
+// adapted by util.AdaptCommand, they do not talk to a running server.
+type command interface {
+	Name() string
+	Synopsis() string
+	AppendFlags(*flag.FlagSet)
+	Run(context.Context, *commoncli.Env) error
+}
+
+type adapter struct {
+	env   *commoncli.Env
+	cmd   command
+	flags *flag.FlagSet
+}
+
+func adaptCommand(env *commoncli.Env, cmd command) *adapter {
+	f := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	f.SetOutput(env.Stderr)
+	cmd.AppendFlags(f)
+
+	return &adapter{
+		env:   env,
+		cmd:   cmd,
+		flags: f,
+	}
+}
+
+func (a *adapter) Run(args []string) int {
+	if err := a.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if err := a.cmd.Run(context.Background(), a.env); err != nil {
+		fmt.Fprintln(a.env.Stderr, "Error: "+err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (a *adapter) Help() string {
+	return a.flags.Parse([]string{"-h"}).Error()
+}
+
+func (a *adapter) Synopsis() string {
+	return a.cmd.Synopsis()
+}
+
+func formatCounts(counts archive.Counts) string {
+	return fmt.Sprintf("bundles: %d, federation relationships: %d, attested nodes: %d, registration entries: %d, entry templates: %d, join tokens: %d, CA journals: %d",
+		counts.Bundles,
+		counts.FederationRelationships,
+		counts.AttestedNodes,
+		counts.RegistrationEntries,
+		counts.EntryTemplates,
+		counts.JoinTokens,
+		counts.CAJournals)
+}
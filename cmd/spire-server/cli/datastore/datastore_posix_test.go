@@ -0,0 +1,68 @@
+//go:build !windows
+
+package datastore
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestRunningServerIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "api.sock")
+	writeFile(t, filepath.Join(dir, "server.conf"), fmt.Sprintf(`
+server {
+    trust_domain = "example.org"
+    data_dir = %q
+    socket_path = %q
+}
+
+plugins {
+    DataStore "sql" {
+        plugin_data {
+            database_type = "sqlite3"
+            connection_string = %q
+        }
+    }
+
+    KeyManager "memory" {
+        plugin_data = {}
+    }
+}
+`, dir, socketPath, filepath.Join(dir, "datastore.sqlite3")))
+
+	key := testkey.NewEC256(t)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "signing.key"), string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})))
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "verification.pem"), string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})))
+	writeFile(t, filepath.Join(dir, "archive.jsonl"), "")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	expected := fmt.Sprintf("Error: a server is running on %s: the server must be stopped before the datastore is exported or imported\n", socketPath)
+
+	stderr := runCommand(t, newExportCommand, dir, 1, "-config", "server.conf", "-signingKey", "signing.key")
+	assert.Equal(t, expected, stderr)
+
+	stderr = runCommand(t, newImportCommand, dir, 1, "-config", "server.conf", "-input", "archive.jsonl", "-verificationKey", "verification.pem")
+	assert.Equal(t, expected, stderr)
+}
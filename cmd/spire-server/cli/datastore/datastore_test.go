@@ -0,0 +1,139 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverConfig = `
+server {
+    trust_domain = "example.org"
+    data_dir = %q
+}
+
+plugins {
+    %s
+
+    KeyManager "memory" {
+        plugin_data = {}
+    }
+}
+`
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "sql.conf"), fmt.Sprintf(serverConfig, dir, fmt.Sprintf(`DataStore "sql" {
+        plugin_data {
+            database_type = "sqlite3"
+            connection_string = %q
+        }
+    }`, filepath.Join(dir, "datastore.sqlite3"))))
+	writeFile(t, filepath.Join(dir, "kv.conf"), fmt.Sprintf(serverConfig, dir, fmt.Sprintf(`DataStore "kv" {
+        plugin_data {
+            database_path = %q
+        }
+    }`, filepath.Join(dir, "datastore.db"))))
+
+	key := testkey.NewEC256(t)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "signing.key"), string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})))
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "verification.pem"), string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})))
+
+	// Populate the SQL datastore
+	src := &configFlags{configPath: "sql.conf"}
+	ds, _, err := src.openDataStore(ctx, &commoncli.Env{BaseDir: dir})
+	require.NoError(t, err)
+	_, err = ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: "spiffe://example.org"})
+	require.NoError(t, err)
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:   "entry",
+		ParentId:  "spiffe://example.org/node",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.CreateJoinToken(ctx, &datastore.JoinToken{Token: "token", Expiry: time.Now().Add(time.Hour)}))
+	require.NoError(t, ds.Close())
+
+	t.Run("export requires a signing key", func(t *testing.T) {
+		stderr := runCommand(t, newExportCommand, dir, 1, "-config", "sql.conf")
+		assert.Equal(t, "Error: a signing key is required\n", stderr)
+	})
+
+	t.Run("import requires an input archive", func(t *testing.T) {
+		stderr := runCommand(t, newImportCommand, dir, 1, "-config", "kv.conf", "-verificationKey", "verification.pem")
+		assert.Equal(t, "Error: an input archive is required\n", stderr)
+	})
+
+	t.Run("import rejects unknown conflict modes", func(t *testing.T) {
+		stderr := runCommand(t, newImportCommand, dir, 1, "-config", "kv.conf", "-input", "archive.jsonl", "-verificationKey", "verification.pem", "-conflict", "merge")
+		assert.Equal(t, "Error: unknown conflict mode \"merge\": expected \"fail\", \"skip\" or \"overwrite\"\n", stderr)
+	})
+
+	stderr := runCommand(t, newExportCommand, dir, 0, "-config", "sql.conf", "-output", "archive.jsonl", "-signingKey", "signing.key")
+	assert.Equal(t, "Exported 3 records (bundles: 1, federation relationships: 0, attested nodes: 0, registration entries: 1, entry templates: 0, join tokens: 1, CA journals: 0)\n", stderr)
+
+	stdout := runImport(t, dir, "-dryRun")
+	assert.Equal(t, "Would import 3 records (bundles: 1, federation relationships: 0, attested nodes: 0, registration entries: 1, entry templates: 0, join tokens: 1, CA journals: 0)\n", stdout)
+
+	stdout = runImport(t, dir)
+	assert.Equal(t, "Imported 3 records (bundles: 1, federation relationships: 0, attested nodes: 0, registration entries: 1, entry templates: 0, join tokens: 1, CA journals: 0)\n", stdout)
+
+	stderr = runCommand(t, newImportCommand, dir, 1, "-config", "kv.conf", "-input", "archive.jsonl", "-verificationKey", "verification.pem")
+	assert.Equal(t, "Error: bundle \"spiffe://example.org\" already exists\n", stderr)
+
+	stdout = runImport(t, dir, "-conflict", "skip")
+	assert.Equal(t, "Imported 0 records (bundles: 0, federation relationships: 0, attested nodes: 0, registration entries: 0, entry templates: 0, join tokens: 0, CA journals: 0)\n"+
+		"Skipped 3 existing records (bundles: 1, federation relationships: 0, attested nodes: 0, registration entries: 1, entry templates: 0, join tokens: 1, CA journals: 0)\n", stdout)
+
+	// Check the migrated KV datastore
+	dst := &configFlags{configPath: "kv.conf"}
+	ds, _, err = dst.openDataStore(ctx, &commoncli.Env{BaseDir: dir})
+	require.NoError(t, err)
+	defer ds.Close()
+	entry, err := ds.FetchRegistrationEntry(ctx, "entry")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "spiffe://example.org/workload", entry.SpiffeId)
+}
+
+func runImport(t *testing.T, dir string, args ...string) string {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newImportCommand(&commoncli.Env{Stdout: stdout, Stderr: stderr, BaseDir: dir})
+	code := cmd.Run(append([]string{"-config", "kv.conf", "-input", "archive.jsonl", "-verificationKey", "verification.pem"}, args...))
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	return stdout.String()
+}
+
+func runCommand(t *testing.T, newCmd func(*commoncli.Env) cli.Command, dir string, expectedCode int, args ...string) string {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newCmd(&commoncli.Env{Stdout: stdout, Stderr: stderr, BaseDir: dir})
+	require.Equal(t, expectedCode, cmd.Run(args), "stderr: %s", stderr.String())
+	return stderr.String()
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
@@ -0,0 +1,88 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+)
+
+// NewExportCommand creates a new "export" subcommand for "datastore" command.
+func NewExportCommand() cli.Command {
+	return newExportCommand(commoncli.DefaultEnv)
+}
+
+func newExportCommand(env *commoncli.Env) cli.Command {
+	return adaptCommand(env, new(exportCommand))
+}
+
+type exportCommand struct {
+	configFlags
+
+	// Path to the file the archive is written to. Writes to stdout if unset.
+	outputPath string
+
+	// Path to the PEM encoded private key used to sign the archive
+	signingKeyPath string
+}
+
+func (c *exportCommand) Name() string {
+	return "datastore export"
+}
+
+func (c *exportCommand) Synopsis() string {
+	return "Exports the contents of the datastore to a signed archive"
+}
+
+func (c *exportCommand) AppendFlags(fs *flag.FlagSet) {
+	c.configFlags.appendFlags(fs)
+	fs.StringVar(&c.outputPath, "output", "", "Path to write the archive to. If unset, the archive is written to stdout")
+	fs.StringVar(&c.signingKeyPath, "signingKey", "", "Path to the PEM encoded private key used to sign the archive")
+}
+
+func (c *exportCommand) Run(ctx context.Context, env *commoncli.Env) (err error) {
+	if c.signingKeyPath == "" {
+		return errors.New("a signing key is required")
+	}
+
+	signer, err := pemutil.LoadSigner(env.JoinPath(c.signingKeyPath))
+	if err != nil {
+		return fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	ds, trustDomain, err := c.openDataStore(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	var w io.Writer = env.Stdout
+	if c.outputPath != "" {
+		f, err := os.OpenFile(env.JoinPath(c.outputPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close archive: %w", closeErr)
+			}
+		}()
+		w = f
+	}
+
+	counts, err := archive.Export(ctx, ds, w, trustDomain, signer)
+	if err != nil {
+		return err
+	}
+
+	// The summary goes to stderr so it does not corrupt an archive written
+	// to stdout.
+	return env.ErrPrintf("Exported %d records (%s)\n", counts.Total(), formatCounts(counts))
+}
@@ -0,0 +1,108 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+)
+
+// NewImportCommand creates a new "import" subcommand for "datastore" command.
+func NewImportCommand() cli.Command {
+	return newImportCommand(commoncli.DefaultEnv)
+}
+
+func newImportCommand(env *commoncli.Env) cli.Command {
+	return adaptCommand(env, new(importCommand))
+}
+
+type importCommand struct {
+	configFlags
+
+	// Path to the archive to import
+	inputPath string
+
+	// Path to the PEM encoded public key used to verify the archive
+	verificationKeyPath string
+
+	// Check the archive against the datastore without making any change
+	dryRun bool
+
+	// How to handle records that already exist
+	conflict string
+}
+
+func (c *importCommand) Name() string {
+	return "datastore import"
+}
+
+func (c *importCommand) Synopsis() string {
+	return "Imports a signed archive into the datastore"
+}
+
+func (c *importCommand) AppendFlags(fs *flag.FlagSet) {
+	c.configFlags.appendFlags(fs)
+	fs.StringVar(&c.inputPath, "input", "", "Path to the archive to import")
+	fs.StringVar(&c.verificationKeyPath, "verificationKey", "", "Path to the PEM encoded public key used to verify the archive signature")
+	fs.BoolVar(&c.dryRun, "dryRun", false, "Check the archive against the datastore without making any change")
+	fs.StringVar(&c.conflict, "conflict", string(archive.ConflictFail), "How to handle records that already exist: fail, skip or overwrite")
+}
+
+func (c *importCommand) Run(ctx context.Context, env *commoncli.Env) error {
+	if c.inputPath == "" {
+		return errors.New("an input archive is required")
+	}
+	if c.verificationKeyPath == "" {
+		return errors.New("a verification key is required")
+	}
+
+	conflict, err := archive.ParseConflictMode(c.conflict)
+	if err != nil {
+		return err
+	}
+
+	verificationKey, err := pemutil.LoadPublicKey(env.JoinPath(c.verificationKeyPath))
+	if err != nil {
+		return fmt.Errorf("failed to load verification key: %w", err)
+	}
+
+	f, err := os.Open(env.JoinPath(c.inputPath))
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	ds, trustDomain, err := c.openDataStore(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	result, err := archive.Import(ctx, ds, f, archive.ImportOptions{
+		TrustDomain:     trustDomain,
+		VerificationKey: verificationKey,
+		Conflict:        conflict,
+		DryRun:          c.dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Imported"
+	if c.dryRun {
+		verb = "Would import"
+	}
+	if err := env.Printf("%s %d records (%s)\n", verb, result.Imported.Total(), formatCounts(result.Imported)); err != nil {
+		return err
+	}
+	if result.Skipped.Total() > 0 {
+		return env.Printf("Skipped %d existing records (%s)\n", result.Skipped.Total(), formatCounts(result.Skipped))
+	}
+	return nil
+}
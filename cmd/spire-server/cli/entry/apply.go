@@ -0,0 +1,537 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	serverutil "github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// Owners are used as the entry ID prefix, so they cannot contain the
+	// separator. Both owners and names only use characters that are valid
+	// in entry IDs.
+	validOwner = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	validName  = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+	// applyEntryMask is the mask used to update entries with every field
+	// that can be declared in an apply file.
+	applyEntryMask = &types.EntryMask{
+		SpiffeId:             true,
+		ParentId:             true,
+		Selectors:            true,
+		X509SvidTtl:          true,
+		JwtSvidTtl:           true,
+		FederatesWith:        true,
+		Admin:                true,
+		Downstream:           true,
+		ExpiresAt:            true,
+		DnsNames:             true,
+		StoreSvid:            true,
+		Hint:                 true,
+		AdditionalAttributes: true,
+	}
+)
+
+// NewApplyCommand creates a new "apply" subcommand for "entry" command.
+func NewApplyCommand() cli.Command {
+	return newApplyCommand(commoncli.DefaultEnv)
+}
+
+func newApplyCommand(env *commoncli.Env) cli.Command {
+	return serverutil.AdaptCommand(env, &applyCommand{env: env})
+}
+
+type applyCommand struct {
+	// Path to the file declaring the entries. If set to "-", the
+	// declaration is read from stdin.
+	path string
+
+	// Owner of the declared entries, overriding the owner in the file
+	owner string
+
+	// Only show the plan, without applying it
+	dryRun bool
+
+	env *commoncli.Env
+}
+
+// applyFile is the declaration of the registration entries managed by a
+// single owner.
+type applyFile struct {
+	Owner   string       `json:"owner"`
+	Entries []applyEntry `json:"entries"`
+}
+
+type applyEntry struct {
+	Name                    string   `json:"name"`
+	SPIFFEID                string   `json:"spiffe_id"`
+	ParentID                string   `json:"parent_id"`
+	Node                    bool     `json:"node"`
+	Selectors               []string `json:"selectors"`
+	FederatesWith           []string `json:"federates_with"`
+	DNSNames                []string `json:"dns_names"`
+	X509SVIDTTL             int32    `json:"x509_svid_ttl"`
+	JWTSVIDTTL              int32    `json:"jwt_svid_ttl"`
+	ExpiresAt               int64    `json:"expires_at"`
+	Admin                   bool     `json:"admin"`
+	Downstream              bool     `json:"downstream"`
+	StoreSVID               bool     `json:"store_svid"`
+	Hint                    string   `json:"hint"`
+	DisableX509SVIDPrefetch bool     `json:"disable_x509_svid_prefetch"`
+	JWTSVIDIncludeJTI       bool     `json:"jwt_svid_include_jti"`
+}
+
+// entryUpdate is an entry to update along with the fields that changed.
+type entryUpdate struct {
+	entry   *types.Entry
+	changed []string
+}
+
+// applyPlan holds the changes needed to converge the server to the declared
+// entries.
+type applyPlan struct {
+	create    []*types.Entry
+	update    []entryUpdate
+	delete    []*types.Entry
+	unchanged int
+}
+
+func (p *applyPlan) empty() bool {
+	return len(p.create) == 0 && len(p.update) == 0 && len(p.delete) == 0
+}
+
+func (*applyCommand) Name() string {
+	return "entry apply"
+}
+
+func (*applyCommand) Synopsis() string {
+	return "Converges registration entries to a declarative YAML file"
+}
+
+func (c *applyCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.path, "f", "", "Path to a YAML file declaring the registration entries. If set to '-', read the YAML from stdin")
+	f.StringVar(&c.owner, "owner", "", "Owner of the declared entries (optional). Overrides the owner set in the file")
+	f.BoolVar(&c.dryRun, "dryRun", false, "Only show the changes that would be made, without applying them")
+}
+
+func (c *applyCommand) Run(ctx context.Context, env *commoncli.Env, serverClient serverutil.ServerClient) error {
+	if c.path == "" {
+		return errors.New("a path to the entries file is required")
+	}
+
+	owner, desired, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+
+	client := serverClient.NewEntryClient()
+	current, err := listOwnedEntries(ctx, client, owner, desired)
+	if err != nil {
+		return err
+	}
+
+	plan := computePlan(current, desired)
+	printPlan(env, plan)
+	if c.dryRun || plan.empty() {
+		return nil
+	}
+
+	return applyChanges(ctx, env, client, plan)
+}
+
+func (c *applyCommand) loadEntries() (string, []*types.Entry, error) {
+	var r io.Reader = c.env.Stdin
+	if c.path != "-" {
+		f, err := os.Open(c.env.JoinPath(c.path))
+		if err != nil {
+			return "", nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	file := new(applyFile)
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return "", nil, fmt.Errorf("failed to parse entries file: %w", err)
+	}
+
+	owner := file.Owner
+	if c.owner != "" {
+		owner = c.owner
+	}
+	if owner == "" {
+		return "", nil, errors.New("an owner is required, either in the entries file or through the owner flag")
+	}
+	if !validOwner.MatchString(owner) {
+		return "", nil, fmt.Errorf("invalid owner %q: only letters, digits, '-' and '_' are allowed", owner)
+	}
+
+	names := make(map[string]bool)
+	entries := make([]*types.Entry, 0, len(file.Entries))
+	for i, e := range file.Entries {
+		if e.Name == "" {
+			return "", nil, fmt.Errorf("entry %d: a name is required", i)
+		}
+		if names[e.Name] {
+			return "", nil, fmt.Errorf("entry %q: duplicate name", e.Name)
+		}
+		names[e.Name] = true
+
+		entry, err := e.toProto(owner)
+		if err != nil {
+			return "", nil, fmt.Errorf("entry %q: %w", e.Name, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return owner, entries, nil
+}
+
+func (e *applyEntry) toProto(owner string) (*types.Entry, error) {
+	if !validName.MatchString(e.Name) {
+		return nil, errors.New("invalid name: only letters, digits, '-', '_' and '.' are allowed")
+	}
+	if len(e.Selectors) == 0 {
+		return nil, errors.New("at least one selector is required")
+	}
+	if e.SPIFFEID == "" {
+		return nil, errors.New("a SPIFFE ID is required")
+	}
+	if e.Node && e.ParentID != "" {
+		return nil, errors.New("a parent ID cannot be set on node entries")
+	}
+	if e.Node && len(e.FederatesWith) > 0 {
+		return nil, errors.New("node entries can not federate")
+	}
+	if !e.Node && e.ParentID == "" {
+		return nil, errors.New("a parent ID is required if the node field is not set")
+	}
+	if e.X509SVIDTTL < 0 {
+		return nil, errors.New("a positive x509-SVID TTL is required")
+	}
+	if e.JWTSVIDTTL < 0 {
+		return nil, errors.New("a positive JWT-SVID TTL is required")
+	}
+	if e.Hint != "" {
+		return nil, errors.New("the hint cannot be set: it records the owner of the entry")
+	}
+
+	spiffeID, err := idStringToProto(e.SPIFFEID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID: %w", err)
+	}
+
+	parentID := &types.SPIFFEID{
+		TrustDomain: spiffeID.TrustDomain,
+		Path:        idutil.ServerIDPath,
+	}
+	if !e.Node {
+		parentID, err = idStringToProto(e.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent ID: %w", err)
+		}
+	}
+
+	selectors := make([]*types.Selector, 0, len(e.Selectors))
+	for _, s := range e.Selectors {
+		selector, err := serverutil.ParseSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+
+	entry := &types.Entry{
+		Id:            entryIDForOwner(owner, e.Name),
+		SpiffeId:      spiffeID,
+		ParentId:      parentID,
+		Selectors:     selectors,
+		FederatesWith: e.FederatesWith,
+		DnsNames:      e.DNSNames,
+		X509SvidTtl:   e.X509SVIDTTL,
+		JwtSvidTtl:    e.JWTSVIDTTL,
+		ExpiresAt:     e.ExpiresAt,
+		Admin:         e.Admin,
+		Downstream:    e.Downstream,
+		StoreSvid:     e.StoreSVID,
+		Hint:          ownerHint(owner),
+	}
+	if e.DisableX509SVIDPrefetch || e.JWTSVIDIncludeJTI {
+		entry.AdditionalAttributes = &types.Entry_AdditionalAttributes{
+			DisableX509SvidPrefetch: e.DisableX509SVIDPrefetch,
+			JwtSvidIncludeJti:       e.JWTSVIDIncludeJTI,
+		}
+	}
+	return entry, nil
+}
+
+// entryIDForOwner returns the ID of a managed entry.
+func entryIDForOwner(owner, name string) string {
+	return owner + "." + name
+}
+
+// ownerHint returns the hint of the entries managed by the given owner. The
+// hint records the owner on the server, which lists the entries of an owner
+// by hint.
+func ownerHint(owner string) string {
+	return "managed-by:" + owner
+}
+
+// listOwnedEntries returns the entries managed by the given owner, which are
+// the entries with both the owner hint and the owner ID prefix. Entries with
+// the owner hint only were not created by apply, and are left alone.
+//
+// Entries created before the owner hint was recorded are only found by ID, so
+// the declared entries that are not listed are fetched by ID. They get the
+// owner hint when updated, but the ones no longer declared are not pruned.
+func listOwnedEntries(ctx context.Context, client entryv1.EntryClient, owner string, desired []*types.Entry) ([]*types.Entry, error) {
+	prefix := entryIDForOwner(owner, "")
+
+	var entries []*types.Entry
+	listed := make(map[string]bool)
+	pageToken := ""
+	for {
+		resp, err := client.ListEntries(ctx, &entryv1.ListEntriesRequest{
+			Filter: &entryv1.ListEntriesRequest_Filter{
+				ByHint: wrapperspb.String(ownerHint(owner)),
+			},
+			PageSize:  listEntriesRequestPageSize,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching entries: %w", err)
+		}
+		for _, entry := range resp.Entries {
+			if strings.HasPrefix(entry.Id, prefix) {
+				entries = append(entries, entry)
+				listed[entry.Id] = true
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	for _, entry := range desired {
+		if listed[entry.Id] {
+			continue
+		}
+		existing, err := client.GetEntry(ctx, &entryv1.GetEntryRequest{Id: entry.Id})
+		switch status.Code(err) {
+		case codes.OK:
+			entries = append(entries, existing)
+		case codes.NotFound:
+		default:
+			return nil, fmt.Errorf("error fetching entry %q: %w", entry.Id, err)
+		}
+	}
+	return entries, nil
+}
+
+func computePlan(current, desired []*types.Entry) *applyPlan {
+	plan := new(applyPlan)
+
+	currentByID := make(map[string]*types.Entry, len(current))
+	for _, entry := range current {
+		currentByID[entry.Id] = entry
+	}
+
+	for _, entry := range desired {
+		existing, ok := currentByID[entry.Id]
+		if !ok {
+			plan.create = append(plan.create, entry)
+			continue
+		}
+		delete(currentByID, entry.Id)
+
+		if changed := changedEntryFields(existing, entry); len(changed) > 0 {
+			plan.update = append(plan.update, entryUpdate{
+				entry:   entry,
+				changed: changed,
+			})
+		} else {
+			plan.unchanged++
+		}
+	}
+
+	for _, entry := range current {
+		if _, ok := currentByID[entry.Id]; ok {
+			plan.delete = append(plan.delete, entry)
+		}
+	}
+
+	return plan
+}
+
+// changedEntryFields returns the names of the declarable fields that differ
+// between the two entries. Selectors and federated trust domains are
+// compared as sets.
+func changedEntryFields(current, desired *types.Entry) []string {
+	var changed []string
+	if protoToIDString(current.SpiffeId) != protoToIDString(desired.SpiffeId) {
+		changed = append(changed, "spiffe_id")
+	}
+	if protoToIDString(current.ParentId) != protoToIDString(desired.ParentId) {
+		changed = append(changed, "parent_id")
+	}
+	if !sameSet(selectorStrings(current.Selectors), selectorStrings(desired.Selectors)) {
+		changed = append(changed, "selectors")
+	}
+	if !sameSet(current.FederatesWith, desired.FederatesWith) {
+		changed = append(changed, "federates_with")
+	}
+	if !slices.Equal(current.DnsNames, desired.DnsNames) {
+		changed = append(changed, "dns_names")
+	}
+	if current.X509SvidTtl != desired.X509SvidTtl {
+		changed = append(changed, "x509_svid_ttl")
+	}
+	if current.JwtSvidTtl != desired.JwtSvidTtl {
+		changed = append(changed, "jwt_svid_ttl")
+	}
+	if current.ExpiresAt != desired.ExpiresAt {
+		changed = append(changed, "expires_at")
+	}
+	if current.Admin != desired.Admin {
+		changed = append(changed, "admin")
+	}
+	if current.Downstream != desired.Downstream {
+		changed = append(changed, "downstream")
+	}
+	if current.StoreSvid != desired.StoreSvid {
+		changed = append(changed, "store_svid")
+	}
+	if current.Hint != desired.Hint {
+		changed = append(changed, "hint")
+	}
+	if current.AdditionalAttributes.GetDisableX509SvidPrefetch() != desired.AdditionalAttributes.GetDisableX509SvidPrefetch() {
+		changed = append(changed, "disable_x509_svid_prefetch")
+	}
+	if current.AdditionalAttributes.GetJwtSvidIncludeJti() != desired.AdditionalAttributes.GetJwtSvidIncludeJti() {
+		changed = append(changed, "jwt_svid_include_jti")
+	}
+	return changed
+}
+
+func selectorStrings(selectors []*types.Selector) []string {
+	s := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		s = append(s, selector.Type+":"+selector.Value)
+	}
+	return s
+}
+
+func sameSet(a, b []string) bool {
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+func printPlan(env *commoncli.Env, plan *applyPlan) {
+	if plan.empty() {
+		_ = env.Printf("No changes. %d entries are up to date.\n", plan.unchanged)
+		return
+	}
+
+	_ = env.Printf("Plan: %d to create, %d to update, %d to delete, %d unchanged\n",
+		len(plan.create), len(plan.update), len(plan.delete), plan.unchanged)
+	for _, entry := range plan.create {
+		_ = env.Printf("  + %s (%s)\n", entry.Id, protoToIDString(entry.SpiffeId))
+	}
+	for _, u := range plan.update {
+		_ = env.Printf("  ~ %s (%s): %s\n", u.entry.Id, protoToIDString(u.entry.SpiffeId), strings.Join(u.changed, ", "))
+	}
+	for _, entry := range plan.delete {
+		_ = env.Printf("  - %s (%s)\n", entry.Id, protoToIDString(entry.SpiffeId))
+	}
+}
+
+// applyChanges applies the plan. Entries are deleted first and created last
+// so that entries renamed in the file do not conflict with their previous
+// declaration.
+func applyChanges(ctx context.Context, env *commoncli.Env, client entryv1.EntryClient, plan *applyPlan) error {
+	failed := 0
+
+	if len(plan.delete) > 0 {
+		ids := make([]string, 0, len(plan.delete))
+		for _, entry := range plan.delete {
+			ids = append(ids, entry.Id)
+		}
+		resp, err := client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: ids})
+		if err != nil {
+			return fmt.Errorf("failed to delete entries: %w", err)
+		}
+		for _, r := range resp.Results {
+			failed += reportFailure(env, "delete", r.Id, r.Status)
+		}
+	}
+
+	if len(plan.update) > 0 {
+		entries := make([]*types.Entry, 0, len(plan.update))
+		for _, u := range plan.update {
+			entries = append(entries, u.entry)
+		}
+		resp, err := client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries:   entries,
+			InputMask: applyEntryMask,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update entries: %w", err)
+		}
+		for i, r := range resp.Results {
+			failed += reportFailure(env, "update", entries[i].Id, r.Status)
+		}
+	}
+
+	if len(plan.create) > 0 {
+		resp, err := client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: plan.create})
+		if err != nil {
+			return fmt.Errorf("failed to create entries: %w", err)
+		}
+		for i, r := range resp.Results {
+			failed += reportFailure(env, "create", plan.create[i].Id, r.Status)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to apply %d of %d changes", failed, len(plan.create)+len(plan.update)+len(plan.delete))
+	}
+	return env.Printf("Applied %d changes.\n", len(plan.create)+len(plan.update)+len(plan.delete))
+}
+
+func reportFailure(env *commoncli.Env, action, id string, status *types.Status) int {
+	if status.GetCode() == int32(codes.OK) {
+		return 0
+	}
+	_ = env.ErrPrintf("Failed to %s entry %q (code: %s, msg: %q)\n",
+		action,
+		id,
+		util.MustCast[codes.Code](status.GetCode()),
+		status.GetMessage())
+	return 1
+}
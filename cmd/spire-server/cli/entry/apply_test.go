@@ -0,0 +1,274 @@
+package entry
+
+import (
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const applyYAML = `
+owner: gitops
+entries:
+  - name: web
+    spiffe_id: spiffe://example.org/web
+    parent_id: spiffe://example.org/agent
+    selectors: ["unix:uid:1001"]
+    x509_svid_ttl: 3600
+  - name: same
+    spiffe_id: spiffe://example.org/same
+    parent_id: spiffe://example.org/agent
+    selectors: ["unix:uid:1002", "unix:gid:1000"]
+  - name: legacy
+    spiffe_id: spiffe://example.org/legacy
+    parent_id: spiffe://example.org/agent
+    selectors: ["unix:uid:1005"]
+  - name: new
+    spiffe_id: spiffe://example.org/new
+    node: true
+    selectors: ["k8s_psat:cluster:demo"]
+    dns_names: ["new.example.org"]
+`
+
+func TestApplyHelp(t *testing.T) {
+	test := setupTest(t, newApplyCommand)
+	test.client.Help()
+
+	require.Equal(t, applyUsage, test.stderr.String())
+}
+
+func TestApplySynopsis(t *testing.T) {
+	test := setupTest(t, newApplyCommand)
+	require.Equal(t, "Converges registration entries to a declarative YAML file", test.client.Synopsis())
+}
+
+func TestApply(t *testing.T) {
+	agentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"}
+
+	// The entries listed by the owner hint
+	current := []*types.Entry{
+		{
+			Id:          "gitops.web",
+			SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/web"},
+			ParentId:    agentID,
+			Selectors:   []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+			X509SvidTtl: 3600,
+			Hint:        "managed-by:gitops",
+		},
+		{
+			Id:        "gitops.same",
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/same"},
+			ParentId:  agentID,
+			Selectors: []*types.Selector{{Type: "unix", Value: "gid:1000"}, {Type: "unix", Value: "uid:1002"}},
+			Hint:      "managed-by:gitops",
+		},
+		{
+			Id:        "gitops.old",
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/old"},
+			ParentId:  agentID,
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1003"}},
+			Hint:      "managed-by:gitops",
+		},
+		{
+			Id:        "unmanaged",
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/unmanaged"},
+			ParentId:  agentID,
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1004"}},
+			Hint:      "managed-by:gitops",
+		},
+	}
+
+	// An entry created before the owner hint was recorded, only found by ID
+	legacyEntry := &types.Entry{
+		Id:        "gitops.legacy",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/legacy"},
+		ParentId:  agentID,
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1005"}},
+	}
+
+	updatedEntries := []*types.Entry{
+		{
+			Id:          "gitops.web",
+			SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/web"},
+			ParentId:    agentID,
+			Selectors:   []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+			X509SvidTtl: 3600,
+			Hint:        "managed-by:gitops",
+		},
+		{
+			Id:        "gitops.legacy",
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/legacy"},
+			ParentId:  agentID,
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1005"}},
+			Hint:      "managed-by:gitops",
+		},
+	}
+	createdEntry := &types.Entry{
+		Id:        "gitops.new",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/new"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+		Selectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:demo"}},
+		DnsNames:  []string{"new.example.org"},
+		Hint:      "managed-by:gitops",
+	}
+
+	okStatus := &types.Status{Code: int32(codes.OK), Message: "OK"}
+	updateResults := []*entryv1.BatchUpdateEntryResponse_Result{
+		{Entry: updatedEntries[0], Status: okStatus},
+		{Entry: updatedEntries[1], Status: okStatus},
+	}
+
+	const expectedPlan = `Plan: 1 to create, 2 to update, 1 to delete, 1 unchanged
+  + gitops.new (spiffe://example.org/new)
+  ~ gitops.web (spiffe://example.org/web): selectors
+  ~ gitops.legacy (spiffe://example.org/legacy): hint
+  - gitops.old (spiffe://example.org/old)
+`
+
+	for _, tt := range []struct {
+		name    string
+		args    []string
+		yaml    string
+		current []*types.Entry
+
+		createResp *entryv1.BatchCreateEntryResponse
+		updateResp *entryv1.BatchUpdateEntryResponse
+		deleteResp *entryv1.BatchDeleteEntryResponse
+
+		expectApply bool
+		expOut      string
+		expErr      string
+	}{
+		{
+			name:   "missing file",
+			expErr: "Error: a path to the entries file is required\n",
+		},
+		{
+			name:   "missing owner",
+			args:   []string{"-f", "-"},
+			yaml:   "entries: []",
+			expErr: "Error: an owner is required, either in the entries file or through the owner flag\n",
+		},
+		{
+			name:   "invalid owner",
+			args:   []string{"-f", "-", "-owner", "git.ops"},
+			yaml:   applyYAML,
+			expErr: "Error: invalid owner \"git.ops\": only letters, digits, '-' and '_' are allowed\n",
+		},
+		{
+			name:   "unknown field",
+			args:   []string{"-f", "-"},
+			yaml:   "owner: gitops\nentries:\n  - name: a\n    spiffeID: spiffe://example.org/a\n",
+			expErr: "Error: failed to parse entries file: error unmarshaling JSON: while decoding JSON: json: unknown field \"spiffeID\"\n",
+		},
+		{
+			name:   "invalid entry",
+			args:   []string{"-f", "-"},
+			yaml:   "owner: gitops\nentries:\n  - name: a\n",
+			expErr: "Error: entry \"a\": at least one selector is required\n",
+		},
+		{
+			name:   "hint set",
+			args:   []string{"-f", "-"},
+			yaml:   "owner: gitops\nentries:\n  - name: a\n    spiffe_id: spiffe://example.org/a\n    parent_id: spiffe://example.org/agent\n    selectors: [\"unix:uid:1001\"]\n    hint: external\n",
+			expErr: "Error: entry \"a\": the hint cannot be set: it records the owner of the entry\n",
+		},
+		{
+			name:   "duplicate name",
+			args:   []string{"-f", "-"},
+			yaml:   applyYAML + "  - name: web\n",
+			expErr: "Error: entry \"web\": duplicate name\n",
+		},
+		{
+			name:    "no changes",
+			args:    []string{"-f", "-"},
+			yaml:    "owner: gitops\nentries: []\n",
+			current: current[3:],
+			expOut:  "No changes. 0 entries are up to date.\n",
+		},
+		{
+			name:    "dry run",
+			args:    []string{"-f", "-", "-dryRun"},
+			yaml:    applyYAML,
+			current: current,
+			expOut:  expectedPlan,
+		},
+		{
+			name:        "apply",
+			args:        []string{"-f", "-"},
+			yaml:        applyYAML,
+			current:     current,
+			expectApply: true,
+			deleteResp: &entryv1.BatchDeleteEntryResponse{
+				Results: []*entryv1.BatchDeleteEntryResponse_Result{{Id: "gitops.old", Status: okStatus}},
+			},
+			updateResp: &entryv1.BatchUpdateEntryResponse{
+				Results: updateResults,
+			},
+			createResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{{Entry: createdEntry, Status: okStatus}},
+			},
+			expOut: expectedPlan + "Applied 4 changes.\n",
+		},
+		{
+			name:        "apply with failures",
+			args:        []string{"-f", "-"},
+			yaml:        applyYAML,
+			current:     current,
+			expectApply: true,
+			deleteResp: &entryv1.BatchDeleteEntryResponse{
+				Results: []*entryv1.BatchDeleteEntryResponse_Result{{Id: "gitops.old", Status: okStatus}},
+			},
+			updateResp: &entryv1.BatchUpdateEntryResponse{
+				Results: updateResults,
+			},
+			createResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{
+					{Status: &types.Status{Code: int32(codes.AlreadyExists), Message: "similar entry already exists"}},
+				},
+			},
+			expOut: expectedPlan,
+			expErr: "Failed to create entry \"gitops.new\" (code: AlreadyExists, msg: \"similar entry already exists\")\n" +
+				"Error: failed to apply 1 of 4 changes\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newApplyCommand)
+			test.stdin.WriteString(tt.yaml)
+			test.server.expListEntriesReq = &entryv1.ListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByHint: wrapperspb.String("managed-by:gitops"),
+				},
+				PageSize: listEntriesRequestPageSize,
+			}
+			test.server.listEntriesResp = &entryv1.ListEntriesResponse{Entries: tt.current}
+			test.server.getEntryByID = map[string]*types.Entry{legacyEntry.Id: legacyEntry}
+			if tt.expectApply {
+				test.server.expBatchDeleteEntryReq = &entryv1.BatchDeleteEntryRequest{Ids: []string{"gitops.old"}}
+				test.server.batchDeleteEntryResp = tt.deleteResp
+				test.server.expBatchUpdateEntryReq = &entryv1.BatchUpdateEntryRequest{
+					Entries:   updatedEntries,
+					InputMask: applyEntryMask,
+				}
+				test.server.batchUpdateEntryResp = tt.updateResp
+				test.server.expBatchCreateEntryReq = &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{createdEntry}}
+				test.server.batchCreateEntryResp = tt.createResp
+			}
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				require.Equal(t, tt.expOut, test.stdout.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Empty(t, test.stderr.String())
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
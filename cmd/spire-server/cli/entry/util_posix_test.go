@@ -108,6 +108,18 @@ const (
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
   -x509SVIDTTL int
     	The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.
+`
+	applyUsage = `Usage of entry apply:
+  -dryRun
+    	Only show the changes that would be made, without applying them
+  -f string
+    	Path to a YAML file declaring the registration entries. If set to '-', read the YAML from stdin
+  -instance string
+    	Instance name to substitute into socket templates (env SPIRE_SERVER_PRIVATE_SOCKET_TEMPLATE).
+  -owner string
+    	Owner of the declared entries (optional). Overrides the owner set in the file
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
 `
 	deleteUsage = `Usage of entry delete:
   -entryID string
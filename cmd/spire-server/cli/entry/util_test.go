@@ -17,6 +17,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var availableFormats = []string{"pretty", "json"}
@@ -187,6 +189,7 @@ type fakeEntryServer struct {
 	expBatchUpdateEntryReq *entryv1.BatchUpdateEntryRequest
 
 	getEntryResp         *types.Entry
+	getEntryByID         map[string]*types.Entry
 	countEntriesResp     *entryv1.CountEntriesResponse
 	listEntriesResp      *entryv1.ListEntriesResponse
 	batchDeleteEntryResp *entryv1.BatchDeleteEntryResponse
@@ -213,6 +216,13 @@ func (f fakeEntryServer) GetEntry(_ context.Context, req *entryv1.GetEntryReques
 	if f.err != nil {
 		return nil, f.err
 	}
+	if f.getEntryByID != nil {
+		entry, ok := f.getEntryByID[req.Id]
+		if !ok {
+			return nil, status.Error(codes.NotFound, "entry not found")
+		}
+		return entry, nil
+	}
 	spiretest.AssertProtoEqual(f.t, f.expGetEntryReq, req)
 	return f.getEntryResp, nil
 }
@@ -102,6 +102,16 @@ const (
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
   -x509SVIDTTL int
     	The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.
+`
+	applyUsage = `Usage of entry apply:
+  -dryRun
+    	Only show the changes that would be made, without applying them
+  -f string
+    	Path to a YAML file declaring the registration entries. If set to '-', read the YAML from stdin
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -owner string
+    	Owner of the declared entries (optional). Overrides the owner set in the file
 `
 	deleteUsage = `Usage of entry delete:
   -entryID string
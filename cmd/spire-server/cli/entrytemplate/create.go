@@ -0,0 +1,152 @@
+package entrytemplate
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/cli/entry"
+	serverutil "github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	"github.com/spiffe/spire/pkg/common/util"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+type createCommand struct {
+	env     *commoncli.Env
+	printer cliprinter.Printer
+
+	// Entry template ID
+	templateID string
+
+	// Workload parent spiffeID
+	parentID string
+
+	// SPIFFE ID template rendered for each workload
+	spiffeIDTemplate string
+
+	// Type and value are delimited by a colon (:)
+	// ex. "k8s:pod-label:app:frontend"
+	selectors entry.StringsFlag
+
+	// TTL for x509 SVIDs issued to the workloads
+	x509SVIDTTL int
+
+	// TTL for JWT SVIDs issued to the workloads
+	jwtSVIDTTL int
+
+	// List of SPIFFE IDs of trust domains the workloads are federated with
+	federatesWith entry.StringsFlag
+
+	// DNSNames for SVIDs issued to the workloads
+	dnsNames entry.StringsFlag
+
+	// Entry hint, used to disambiguate entries with the same SPIFFE ID
+	hint string
+}
+
+// NewCreateCommand creates a new "entry template create" subcommand using
+// the default cli environment.
+func NewCreateCommand() cli.Command {
+	return NewCreateCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewCreateCommandWithEnv creates a new "entry template create" subcommand
+// using the given cli environment.
+func NewCreateCommandWithEnv(env *commoncli.Env) cli.Command {
+	return serverutil.AdaptCommand(env, &createCommand{env: env})
+}
+
+// The name of the command.
+func (*createCommand) Name() string {
+	return "entry template create"
+}
+
+// The help presented description of the command.
+func (*createCommand) Synopsis() string {
+	return "Creates an entry template"
+}
+
+// Adds additional flags specific to the command.
+func (c *createCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.templateID, "templateID", "", "A custom ID for this entry template (optional). If not set, a new template ID will be generated")
+	fs.StringVar(&c.parentID, "parentID", "", "The SPIFFE ID of the parent of the workloads")
+	fs.StringVar(&c.spiffeIDTemplate, "spiffeID", "", "The SPIFFE ID template rendered from the selectors of each workload")
+	fs.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
+	fs.IntVar(&c.x509SVIDTTL, "x509SVIDTTL", 0, "The lifetime, in seconds, for x509-SVIDs issued based on this entry template.")
+	fs.IntVar(&c.jwtSVIDTTL, "jwtSVIDTTL", 0, "The lifetime, in seconds, for JWT-SVIDs issued based on this entry template.")
+	fs.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain to federate with. Can be used more than once")
+	fs.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on this entry template, where appropriate. Can be used more than once")
+	fs.StringVar(&c.hint, "hint", "", "The entry hint, used to disambiguate entries with the same SPIFFE ID")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintCreate)
+}
+
+// The routine that executes the command
+func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient serverutil.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	x509SvidTTL, err := util.CheckedCast[int32](c.x509SVIDTTL)
+	if err != nil {
+		return fmt.Errorf("invalid value for X509 SVID TTL: %w", err)
+	}
+
+	jwtSvidTTL, err := util.CheckedCast[int32](c.jwtSVIDTTL)
+	if err != nil {
+		return fmt.Errorf("invalid value for JWT SVID TTL: %w", err)
+	}
+
+	template := &common.EntryTemplate{
+		TemplateId:       c.templateID,
+		ParentId:         c.parentID,
+		SpiffeIdTemplate: c.spiffeIDTemplate,
+		X509SvidTtl:      x509SvidTTL,
+		JwtSvidTtl:       jwtSvidTTL,
+		FederatesWith:    c.federatesWith,
+		DnsNames:         c.dnsNames,
+		Hint:             c.hint,
+	}
+	for _, s := range c.selectors {
+		selector, err := serverutil.ParseSelector(s)
+		if err != nil {
+			return err
+		}
+		template.Selectors = append(template.Selectors, &common.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+
+	resp, err := serverClient.NewEntryTemplatesClient().CreateEntryTemplate(ctx, &entrytemplatev1.CreateEntryTemplateRequest{
+		Template: template,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating entry template: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func (c *createCommand) validate() error {
+	if c.parentID == "" {
+		return errors.New("a parent ID is required")
+	}
+	if c.spiffeIDTemplate == "" {
+		return errors.New("a SPIFFE ID template is required")
+	}
+	if len(c.selectors) < 1 {
+		return errors.New("at least one selector is required")
+	}
+	if c.x509SVIDTTL < 0 {
+		return errors.New("a positive x509-SVID TTL is required")
+	}
+	if c.jwtSVIDTTL < 0 {
+		return errors.New("a positive JWT-SVID TTL is required")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package entrytemplate
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	serverutil "github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+)
+
+type deleteCommand struct {
+	env     *commoncli.Env
+	printer cliprinter.Printer
+
+	// ID of the entry template to delete
+	templateID string
+}
+
+// NewDeleteCommand creates a new "entry template delete" subcommand using
+// the default cli environment.
+func NewDeleteCommand() cli.Command {
+	return NewDeleteCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewDeleteCommandWithEnv creates a new "entry template delete" subcommand
+// using the given cli environment.
+func NewDeleteCommandWithEnv(env *commoncli.Env) cli.Command {
+	return serverutil.AdaptCommand(env, &deleteCommand{env: env})
+}
+
+// The name of the command.
+func (*deleteCommand) Name() string {
+	return "entry template delete"
+}
+
+// The help presented description of the command.
+func (*deleteCommand) Synopsis() string {
+	return "Deletes an entry template"
+}
+
+// Adds additional flags specific to the command.
+func (c *deleteCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.templateID, "templateID", "", "The ID of the entry template to delete")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintDelete)
+}
+
+// The routine that executes the command
+func (c *deleteCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient serverutil.ServerClient) error {
+	if c.templateID == "" {
+		return errors.New("a template ID is required")
+	}
+
+	resp, err := serverClient.NewEntryTemplatesClient().DeleteEntryTemplate(ctx, &entrytemplatev1.DeleteEntryTemplateRequest{
+		TemplateId: c.templateID,
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting entry template: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
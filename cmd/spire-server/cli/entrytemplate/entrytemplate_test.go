@@ -0,0 +1,350 @@
+package entrytemplate_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/cli/entrytemplate"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	template1 = &common.EntryTemplate{
+		TemplateId:       "template1",
+		ParentId:         "spiffe://example.org/node",
+		SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+		Selectors:        []*common.Selector{{Type: "k8s", Value: "pod-label:app:frontend"}},
+		X509SvidTtl:      60,
+		FederatesWith:    []string{"spiffe://domain1.org"},
+		DnsNames:         []string{"dns1"},
+		Hint:             "external",
+		CreatedAt:        1700000000,
+	}
+	template2 = &common.EntryTemplate{
+		TemplateId:       "template2",
+		ParentId:         "spiffe://example.org/node",
+		SpiffeIdTemplate: "spiffe://example.org/sa/{{ .k8s.sa }}",
+		Selectors:        []*common.Selector{{Type: "k8s", Value: "ns:default"}},
+		CreatedAt:        1700000000,
+	}
+
+	template1Pretty = `Template ID      : template1
+SPIFFE ID        : spiffe://example.org/ns/{{ .k8s.ns }}
+Parent ID        : spiffe://example.org/node
+X509-SVID TTL    : 60
+JWT-SVID TTL     : default
+Selector         : k8s:pod-label:app:frontend
+FederatesWith    : spiffe://domain1.org
+DNS name         : dns1
+Hint             : external
+`
+)
+
+func TestCreateHelp(t *testing.T) {
+	test := setupTest(t, &fakeEntryTemplatesServer{}, entrytemplate.NewCreateCommandWithEnv)
+	test.client.Help()
+	require.Equal(t, "", test.stdout.String())
+	require.Equal(t, createUsage, test.stderr.String())
+}
+
+func TestCreateSynopsis(t *testing.T) {
+	cmd := entrytemplate.NewCreateCommand()
+	require.Equal(t, "Creates an entry template", cmd.Synopsis())
+}
+
+func TestCreate(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		server           *fakeEntryTemplatesServer
+		args             []string
+		expectReturnCode int
+		expectRequest    *entrytemplatev1.CreateEntryTemplateRequest
+		expectStdout     string
+		expectStderr     string
+	}{
+		{
+			name:   "success",
+			server: &fakeEntryTemplatesServer{template: template1},
+			args: []string{
+				"-templateID", "template1",
+				"-parentID", "spiffe://example.org/node",
+				"-spiffeID", "spiffe://example.org/ns/{{ .k8s.ns }}",
+				"-selector", "k8s:pod-label:app:frontend",
+				"-x509SVIDTTL", "60",
+				"-federatesWith", "spiffe://domain1.org",
+				"-dns", "dns1",
+				"-hint", "external",
+			},
+			expectRequest: &entrytemplatev1.CreateEntryTemplateRequest{
+				Template: &common.EntryTemplate{
+					TemplateId:       "template1",
+					ParentId:         "spiffe://example.org/node",
+					SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+					Selectors:        []*common.Selector{{Type: "k8s", Value: "pod-label:app:frontend"}},
+					X509SvidTtl:      60,
+					FederatesWith:    []string{"spiffe://domain1.org"},
+					DnsNames:         []string{"dns1"},
+					Hint:             "external",
+				},
+			},
+			expectStdout: template1Pretty,
+		},
+		{
+			name:   "json output",
+			server: &fakeEntryTemplatesServer{template: template2},
+			args: []string{
+				"-output", "json",
+				"-parentID", "spiffe://example.org/node",
+				"-spiffeID", "spiffe://example.org/sa/{{ .k8s.sa }}",
+				"-selector", "k8s:ns:default",
+			},
+			expectRequest: &entrytemplatev1.CreateEntryTemplateRequest{
+				Template: &common.EntryTemplate{
+					ParentId:         "spiffe://example.org/node",
+					SpiffeIdTemplate: "spiffe://example.org/sa/{{ .k8s.sa }}",
+					Selectors:        []*common.Selector{{Type: "k8s", Value: "ns:default"}},
+				},
+			},
+			expectStdout: `{"template":{"created_at":"1700000000","dns_names":[],"federates_with":[],"hint":"","jwt_svid_ttl":0,"parent_id":"spiffe://example.org/node","selectors":[{"type":"k8s","value":"ns:default"}],"spiffe_id_template":"spiffe://example.org/sa/{{ .k8s.sa }}","template_id":"template2","x509_svid_ttl":0}}
+`,
+		},
+		{
+			name:             "missing parent ID",
+			args:             []string{"-spiffeID", "spiffe://example.org/ns/{{ .k8s.ns }}", "-selector", "k8s:ns:default"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: a parent ID is required\n",
+		},
+		{
+			name:             "missing SPIFFE ID template",
+			args:             []string{"-parentID", "spiffe://example.org/node", "-selector", "k8s:ns:default"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: a SPIFFE ID template is required\n",
+		},
+		{
+			name:             "missing selectors",
+			args:             []string{"-parentID", "spiffe://example.org/node", "-spiffeID", "spiffe://example.org/ns/{{ .k8s.ns }}"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: at least one selector is required\n",
+		},
+		{
+			name:             "malformed selector",
+			args:             []string{"-parentID", "spiffe://example.org/node", "-spiffeID", "spiffe://example.org/ns/{{ .k8s.ns }}", "-selector", "k8s"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: selector \"k8s\" must be formatted as type:value\n",
+		},
+		{
+			name:   "server fails",
+			server: &fakeEntryTemplatesServer{err: status.Error(codes.InvalidArgument, "invalid entry template: selector list is empty")},
+			args: []string{
+				"-parentID", "spiffe://example.org/node",
+				"-spiffeID", "spiffe://example.org/ns/{{ .k8s.ns }}",
+				"-selector", "k8s:ns:default",
+			},
+			expectReturnCode: 1,
+			expectStderr:     "Error: error creating entry template: rpc error: code = InvalidArgument desc = invalid entry template: selector list is empty\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.server == nil {
+				tt.server = &fakeEntryTemplatesServer{}
+			}
+			test := setupTest(t, tt.server, entrytemplate.NewCreateCommandWithEnv)
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			require.Equal(t, tt.expectStderr, test.stderr.String())
+			require.Equal(t, tt.expectReturnCode, returnCode)
+			if tt.expectRequest != nil {
+				spiretest.AssertProtoEqual(t, tt.expectRequest, tt.server.createReq)
+			}
+		})
+	}
+}
+
+func TestShowHelp(t *testing.T) {
+	test := setupTest(t, &fakeEntryTemplatesServer{}, entrytemplate.NewShowCommandWithEnv)
+	test.client.Help()
+	require.Equal(t, "", test.stdout.String())
+	require.Equal(t, showUsage, test.stderr.String())
+}
+
+func TestShowSynopsis(t *testing.T) {
+	cmd := entrytemplate.NewShowCommand()
+	require.Equal(t, "Displays configured entry templates", cmd.Synopsis())
+}
+
+func TestShow(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		server           *fakeEntryTemplatesServer
+		args             []string
+		expectReturnCode int
+		expectStdout     string
+		expectStderr     string
+	}{
+		{
+			name:   "all templates",
+			server: &fakeEntryTemplatesServer{templates: []*common.EntryTemplate{template1, template2}},
+			expectStdout: `Found 2 entry templates
+
+` + template1Pretty + `
+Template ID      : template2
+SPIFFE ID        : spiffe://example.org/sa/{{ .k8s.sa }}
+Parent ID        : spiffe://example.org/node
+X509-SVID TTL    : default
+JWT-SVID TTL     : default
+Selector         : k8s:ns:default
+`,
+		},
+		{
+			name:   "by template ID",
+			server: &fakeEntryTemplatesServer{templates: []*common.EntryTemplate{template1, template2}},
+			args:   []string{"-templateID", "template1"},
+			expectStdout: `Found 1 entry template
+
+` + template1Pretty,
+		},
+		{
+			name:   "json output",
+			server: &fakeEntryTemplatesServer{templates: []*common.EntryTemplate{template2}},
+			args:   []string{"-output", "json"},
+			expectStdout: `{"templates":[{"created_at":"1700000000","dns_names":[],"federates_with":[],"hint":"","jwt_svid_ttl":0,"parent_id":"spiffe://example.org/node","selectors":[{"type":"k8s","value":"ns:default"}],"spiffe_id_template":"spiffe://example.org/sa/{{ .k8s.sa }}","template_id":"template2","x509_svid_ttl":0}]}
+`,
+		},
+		{
+			name:             "server fails",
+			server:           &fakeEntryTemplatesServer{err: status.Error(codes.Internal, "failed to list entry templates")},
+			expectReturnCode: 1,
+			expectStderr:     "Error: error listing entry templates: rpc error: code = Internal desc = failed to list entry templates\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, tt.server, entrytemplate.NewShowCommandWithEnv)
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			require.Equal(t, tt.expectStderr, test.stderr.String())
+			require.Equal(t, tt.expectReturnCode, returnCode)
+		})
+	}
+}
+
+func TestDeleteHelp(t *testing.T) {
+	test := setupTest(t, &fakeEntryTemplatesServer{}, entrytemplate.NewDeleteCommandWithEnv)
+	test.client.Help()
+	require.Equal(t, "", test.stdout.String())
+	require.Equal(t, deleteUsage, test.stderr.String())
+}
+
+func TestDeleteSynopsis(t *testing.T) {
+	cmd := entrytemplate.NewDeleteCommand()
+	require.Equal(t, "Deletes an entry template", cmd.Synopsis())
+}
+
+func TestDelete(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		server           *fakeEntryTemplatesServer
+		args             []string
+		expectReturnCode int
+		expectStdout     string
+		expectStderr     string
+	}{
+		{
+			name:         "success",
+			server:       &fakeEntryTemplatesServer{template: template1},
+			args:         []string{"-templateID", "template1"},
+			expectStdout: "Deleted entry template with ID: template1\n",
+		},
+		{
+			name:             "missing template ID",
+			server:           &fakeEntryTemplatesServer{},
+			expectReturnCode: 1,
+			expectStderr:     "Error: a template ID is required\n",
+		},
+		{
+			name:             "not found",
+			server:           &fakeEntryTemplatesServer{err: status.Error(codes.NotFound, "entry template not found")},
+			args:             []string{"-templateID", "template1"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: error deleting entry template: rpc error: code = NotFound desc = entry template not found\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, tt.server, entrytemplate.NewDeleteCommandWithEnv)
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			require.Equal(t, tt.expectStderr, test.stderr.String())
+			require.Equal(t, tt.expectReturnCode, returnCode)
+		})
+	}
+}
+
+type cliTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+	args   []string
+	client cli.Command
+}
+
+func setupTest(t *testing.T, server *fakeEntryTemplatesServer, newClient func(*commoncli.Env) cli.Command) *cliTest {
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		entrytemplatev1.RegisterEntryTemplatesServer(s, server)
+	})
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	client := newClient(&commoncli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	return &cliTest{
+		stdout: stdout,
+		stderr: stderr,
+		args:   []string{clitest.AddrArg, clitest.GetAddr(addr)},
+		client: client,
+	}
+}
+
+type fakeEntryTemplatesServer struct {
+	entrytemplatev1.UnimplementedEntryTemplatesServer
+
+	template  *common.EntryTemplate
+	templates []*common.EntryTemplate
+	createReq *entrytemplatev1.CreateEntryTemplateRequest
+	err       error
+}
+
+func (s *fakeEntryTemplatesServer) CreateEntryTemplate(_ context.Context, req *entrytemplatev1.CreateEntryTemplateRequest) (*entrytemplatev1.CreateEntryTemplateResponse, error) {
+	s.createReq = proto.Clone(req).(*entrytemplatev1.CreateEntryTemplateRequest)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &entrytemplatev1.CreateEntryTemplateResponse{Template: s.template}, nil
+}
+
+func (s *fakeEntryTemplatesServer) ListEntryTemplates(context.Context, *entrytemplatev1.ListEntryTemplatesRequest) (*entrytemplatev1.ListEntryTemplatesResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &entrytemplatev1.ListEntryTemplatesResponse{Templates: s.templates}, nil
+}
+
+func (s *fakeEntryTemplatesServer) DeleteEntryTemplate(context.Context, *entrytemplatev1.DeleteEntryTemplateRequest) (*entrytemplatev1.DeleteEntryTemplateResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &entrytemplatev1.DeleteEntryTemplateResponse{Template: s.template}, nil
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package entrytemplate_test
+
+var (
+	createUsage = `Usage of entry template create:
+  -dns value
+    	A DNS name that will be included in SVIDs issued based on this entry template, where appropriate. Can be used more than once
+  -federatesWith value
+    	SPIFFE ID of a trust domain to federate with. Can be used more than once
+  -hint string
+    	The entry hint, used to disambiguate entries with the same SPIFFE ID
+  -jwtSVIDTTL int
+    	The lifetime, in seconds, for JWT-SVIDs issued based on this entry template.
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -parentID string
+    	The SPIFFE ID of the parent of the workloads
+  -selector value
+    	A colon-delimited type:value selector. Can be used more than once
+  -spiffeID string
+    	The SPIFFE ID template rendered from the selectors of each workload
+  -templateID string
+    	A custom ID for this entry template (optional). If not set, a new template ID will be generated
+  -x509SVIDTTL int
+    	The lifetime, in seconds, for x509-SVIDs issued based on this entry template.
+`
+	showUsage = `Usage of entry template show:
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -templateID string
+    	The ID of the entry template to show (optional)
+`
+	deleteUsage = `Usage of entry template delete:
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -templateID string
+    	The ID of the entry template to delete
+`
+)
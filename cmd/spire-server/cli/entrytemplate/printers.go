@@ -0,0 +1,84 @@
+package entrytemplate
+
+import (
+	"fmt"
+
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+func prettyPrintCreate(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*entrytemplatev1.CreateEntryTemplateResponse)
+	if !ok {
+		return fmt.Errorf("internal error: unexpected type %T returned; please report this as a bug", results[0])
+	}
+	return printTemplate(env, resp.Template)
+}
+
+func prettyPrintShow(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*entrytemplatev1.ListEntryTemplatesResponse)
+	if !ok {
+		return fmt.Errorf("internal error: unexpected type %T returned; please report this as a bug", results[0])
+	}
+
+	msg := fmt.Sprintf("Found %d ", len(resp.Templates))
+	msg = util.Pluralizer(msg, "entry template", "entry templates", len(resp.Templates))
+	if err := env.Println(msg); err != nil {
+		return err
+	}
+	for _, template := range resp.Templates {
+		if err := env.Println(); err != nil {
+			return err
+		}
+		if err := printTemplate(env, template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prettyPrintDelete(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*entrytemplatev1.DeleteEntryTemplateResponse)
+	if !ok {
+		return fmt.Errorf("internal error: unexpected type %T returned; please report this as a bug", results[0])
+	}
+	return env.Printf("Deleted entry template with ID: %s\n", resp.Template.TemplateId)
+}
+
+func printTemplate(env *commoncli.Env, t *common.EntryTemplate) error {
+	lines := []string{
+		fmt.Sprintf("Template ID      : %s", t.TemplateId),
+		fmt.Sprintf("SPIFFE ID        : %s", t.SpiffeIdTemplate),
+		fmt.Sprintf("Parent ID        : %s", t.ParentId),
+		fmt.Sprintf("X509-SVID TTL    : %s", printableTTL(t.X509SvidTtl)),
+		fmt.Sprintf("JWT-SVID TTL     : %s", printableTTL(t.JwtSvidTtl)),
+	}
+	for _, s := range t.Selectors {
+		lines = append(lines, fmt.Sprintf("Selector         : %s:%s", s.Type, s.Value))
+	}
+	for _, id := range t.FederatesWith {
+		lines = append(lines, fmt.Sprintf("FederatesWith    : %s", id))
+	}
+	for _, dnsName := range t.DnsNames {
+		lines = append(lines, fmt.Sprintf("DNS name         : %s", dnsName))
+	}
+	if t.Hint != "" {
+		lines = append(lines, fmt.Sprintf("Hint             : %s", t.Hint))
+	}
+
+	for _, line := range lines {
+		if err := env.Println(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printableTTL(ttl int32) string {
+	if ttl == 0 {
+		return "default"
+	}
+	return fmt.Sprint(ttl)
+}
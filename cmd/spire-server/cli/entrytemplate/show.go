@@ -0,0 +1,70 @@
+package entrytemplate
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	serverutil "github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+type showCommand struct {
+	env     *commoncli.Env
+	printer cliprinter.Printer
+
+	// ID of the entry template to show
+	templateID string
+}
+
+// NewShowCommand creates a new "entry template show" subcommand using the
+// default cli environment.
+func NewShowCommand() cli.Command {
+	return NewShowCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewShowCommandWithEnv creates a new "entry template show" subcommand using
+// the given cli environment.
+func NewShowCommandWithEnv(env *commoncli.Env) cli.Command {
+	return serverutil.AdaptCommand(env, &showCommand{env: env})
+}
+
+// The name of the command.
+func (*showCommand) Name() string {
+	return "entry template show"
+}
+
+// The help presented description of the command.
+func (*showCommand) Synopsis() string {
+	return "Displays configured entry templates"
+}
+
+// Adds additional flags specific to the command.
+func (c *showCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.templateID, "templateID", "", "The ID of the entry template to show (optional)")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintShow)
+}
+
+// The routine that executes the command
+func (c *showCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient serverutil.ServerClient) error {
+	resp, err := serverClient.NewEntryTemplatesClient().ListEntryTemplates(ctx, &entrytemplatev1.ListEntryTemplatesRequest{})
+	if err != nil {
+		return fmt.Errorf("error listing entry templates: %w", err)
+	}
+
+	if c.templateID != "" {
+		var templates []*common.EntryTemplate
+		for _, template := range resp.Templates {
+			if template.TemplateId == c.templateID {
+				templates = append(templates, template)
+			}
+		}
+		resp.Templates = templates
+	}
+
+	return c.printer.PrintProto(resp)
+}
@@ -0,0 +1,255 @@
+package featureflags_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/cli/featureflags"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
+	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListHelp(t *testing.T) {
+	test := setupTest(t, &fakeFeatureFlagsServer{}, featureflags.NewListCommandWithEnv)
+	test.client.Help()
+	require.Equal(t, "", test.stdout.String())
+	require.Equal(t, listUsage, test.stderr.String())
+}
+
+func TestListSynopsis(t *testing.T) {
+	cmd := featureflags.NewListCommand()
+	require.Equal(t, "Lists the feature flags enabled on the server and its agents", cmd.Synopsis())
+}
+
+func TestList(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		server           *fakeFeatureFlagsServer
+		args             []string
+		expectReturnCode int
+		expectStdout     string
+		expectStderr     string
+	}{
+		{
+			name: "servers and agents",
+			server: &fakeFeatureFlagsServer{
+				listResp: &featureflagsv1.ListFeatureFlagsResponse{
+					Server: []*featureflagsv1.FeatureFlag{
+						{Name: "wit-svid", Enabled: true},
+					},
+					Servers: []*featureflagsv1.ServerFeatureFlags{
+						{
+							ServerId:   "server-a",
+							Enabled:    []string{"wit-svid"},
+							ReportedAt: 1700000000,
+						},
+						{
+							ServerId:   "server-b",
+							ReportedAt: 1700000060,
+						},
+					},
+					Agents: []*featureflagsv1.AgentFeatureFlags{
+						{
+							SpiffeId:   "spiffe://example.org/spire/agent/a",
+							Enabled:    []string{"wit-svid"},
+							ReportedAt: 1700000000,
+						},
+						{
+							SpiffeId:   "spiffe://example.org/spire/agent/b",
+							ReportedAt: 1700000060,
+						},
+					},
+				},
+			},
+			expectStdout: `Server feature flags:
+  wit-svid: enabled
+
+Feature flags reported by 2 servers:
+
+Server ID   : server-a
+Enabled     : wit-svid
+Reported at : 2023-11-14T22:13:20Z
+
+Server ID   : server-b
+Enabled     : none
+Reported at : 2023-11-14T22:14:20Z
+
+Feature flags reported by 2 agents:
+
+SPIFFE ID   : spiffe://example.org/spire/agent/a
+Enabled     : wit-svid
+Reported at : 2023-11-14T22:13:20Z
+
+SPIFFE ID   : spiffe://example.org/spire/agent/b
+Enabled     : none
+Reported at : 2023-11-14T22:14:20Z
+`,
+		},
+		{
+			name: "no agents",
+			server: &fakeFeatureFlagsServer{
+				listResp: &featureflagsv1.ListFeatureFlagsResponse{
+					Server: []*featureflagsv1.FeatureFlag{
+						{Name: "wit-svid", Enabled: false},
+					},
+				},
+			},
+			expectStdout: `Server feature flags:
+  wit-svid: disabled
+
+No agent feature flags reported
+`,
+		},
+		{
+			name: "json output",
+			args: []string{"-output", "json"},
+			server: &fakeFeatureFlagsServer{
+				listResp: &featureflagsv1.ListFeatureFlagsResponse{
+					Server: []*featureflagsv1.FeatureFlag{
+						{Name: "wit-svid", Enabled: true},
+					},
+				},
+			},
+			expectStdout: `{"agents":[],"server":[{"enabled":true,"name":"wit-svid"}],"servers":[]}
+`,
+		},
+		{
+			name: "server fails",
+			server: &fakeFeatureFlagsServer{
+				err: errors.New("server is unavailable"),
+			},
+			expectReturnCode: 1,
+			expectStderr: `Error: error listing feature flags: rpc error: code = Unknown desc = server is unavailable
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, tt.server, featureflags.NewListCommandWithEnv)
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			require.Equal(t, tt.expectStderr, test.stderr.String())
+			require.Equal(t, tt.expectReturnCode, returnCode)
+		})
+	}
+}
+
+func TestReloadHelp(t *testing.T) {
+	test := setupTest(t, &fakeFeatureFlagsServer{}, featureflags.NewReloadCommandWithEnv)
+	test.client.Help()
+	require.Equal(t, "", test.stdout.String())
+	require.Equal(t, reloadUsage, test.stderr.String())
+}
+
+func TestReloadSynopsis(t *testing.T) {
+	cmd := featureflags.NewReloadCommand()
+	require.Equal(t, "Reloads the server feature flags from its configuration file", cmd.Synopsis())
+}
+
+func TestReload(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		server           *fakeFeatureFlagsServer
+		args             []string
+		expectReturnCode int
+		expectStdout     string
+		expectStderr     string
+	}{
+		{
+			name: "success",
+			server: &fakeFeatureFlagsServer{
+				reloadResp: &featureflagsv1.ReloadFeatureFlagsResponse{
+					Server: []*featureflagsv1.FeatureFlag{
+						{Name: "wit-svid", Enabled: true},
+					},
+				},
+			},
+			expectStdout: `Feature flags reloaded:
+  wit-svid: enabled
+`,
+		},
+		{
+			name: "json output",
+			args: []string{"-output", "json"},
+			server: &fakeFeatureFlagsServer{
+				reloadResp: &featureflagsv1.ReloadFeatureFlagsResponse{
+					Server: []*featureflagsv1.FeatureFlag{
+						{Name: "wit-svid", Enabled: false},
+					},
+				},
+			},
+			expectStdout: `{"server":[{"enabled":false,"name":"wit-svid"}]}
+`,
+		},
+		{
+			name: "invalid configuration",
+			server: &fakeFeatureFlagsServer{
+				err: status.Error(codes.FailedPrecondition, "failed to reload feature flags: unknown feature flag(s): [bogus]"),
+			},
+			expectReturnCode: 1,
+			expectStderr: `Error: failed to reload feature flags: rpc error: code = FailedPrecondition desc = failed to reload feature flags: unknown feature flag(s): [bogus]
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, tt.server, featureflags.NewReloadCommandWithEnv)
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			require.Equal(t, tt.expectStderr, test.stderr.String())
+			require.Equal(t, tt.expectReturnCode, returnCode)
+		})
+	}
+}
+
+type cliTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+	args   []string
+	client cli.Command
+}
+
+func setupTest(t *testing.T, server *fakeFeatureFlagsServer, newClient func(*commoncli.Env) cli.Command) *cliTest {
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		featureflagsv1.RegisterFeatureFlagsServer(s, server)
+	})
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	client := newClient(&commoncli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	return &cliTest{
+		stdout: stdout,
+		stderr: stderr,
+		args:   []string{clitest.AddrArg, clitest.GetAddr(addr)},
+		client: client,
+	}
+}
+
+type fakeFeatureFlagsServer struct {
+	featureflagsv1.UnimplementedFeatureFlagsServer
+
+	listResp   *featureflagsv1.ListFeatureFlagsResponse
+	reloadResp *featureflagsv1.ReloadFeatureFlagsResponse
+	err        error
+}
+
+func (s *fakeFeatureFlagsServer) ListFeatureFlags(context.Context, *featureflagsv1.ListFeatureFlagsRequest) (*featureflagsv1.ListFeatureFlagsResponse, error) {
+	return s.listResp, s.err
+}
+
+func (s *fakeFeatureFlagsServer) ReloadFeatureFlags(context.Context, *featureflagsv1.ReloadFeatureFlagsRequest) (*featureflagsv1.ReloadFeatureFlagsResponse, error) {
+	return s.reloadResp, s.err
+}
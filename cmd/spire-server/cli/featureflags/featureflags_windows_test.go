@@ -0,0 +1,18 @@
+//go:build windows
+
+package featureflags_test
+
+var (
+	listUsage = `Usage of featureflags list:
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+`
+	reloadUsage = `Usage of featureflags reload:
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+`
+)
@@ -0,0 +1,55 @@
+package featureflags
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
+)
+
+type listCommand struct {
+	env     *commoncli.Env
+	printer cliprinter.Printer
+}
+
+// NewListCommand creates a new "featureflags list" subcommand using the
+// default cli environment.
+func NewListCommand() cli.Command {
+	return NewListCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewListCommandWithEnv creates a new "featureflags list" subcommand using
+// the given cli environment.
+func NewListCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &listCommand{env: env})
+}
+
+// The name of the command.
+func (*listCommand) Name() string {
+	return "featureflags list"
+}
+
+// The help presented description of the command.
+func (*listCommand) Synopsis() string {
+	return "Lists the feature flags enabled on the server and its agents"
+}
+
+// Adds additional flags specific to the command.
+func (c *listCommand) AppendFlags(fs *flag.FlagSet) {
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintList)
+}
+
+// The routine that executes the command
+func (c *listCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	resp, err := serverClient.NewFeatureFlagsClient().ListFeatureFlags(ctx, &featureflagsv1.ListFeatureFlagsRequest{})
+	if err != nil {
+		return fmt.Errorf("error listing feature flags: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
@@ -0,0 +1,93 @@
+package featureflags
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
+)
+
+func prettyPrintList(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*featureflagsv1.ListFeatureFlagsResponse)
+	if !ok {
+		return fmt.Errorf("internal error: unexpected type %T returned; please report this as a bug", results[0])
+	}
+
+	if err := env.Println("Server feature flags:"); err != nil {
+		return err
+	}
+	if err := printFlags(env, resp.Server); err != nil {
+		return err
+	}
+
+	if len(resp.Servers) > 0 {
+		msg := fmt.Sprintf("\nFeature flags reported by %d ", len(resp.Servers))
+		msg = util.Pluralizer(msg, "server", "servers", len(resp.Servers))
+		if err := env.Printf("%s:\n", msg); err != nil {
+			return err
+		}
+		for _, server := range resp.Servers {
+			if err := printReported(env, "Server ID", server.ServerId, server.Enabled, server.ReportedAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(resp.Agents) == 0 {
+		return env.Printf("\nNo agent feature flags reported\n")
+	}
+
+	msg := fmt.Sprintf("\nFeature flags reported by %d ", len(resp.Agents))
+	msg = util.Pluralizer(msg, "agent", "agents", len(resp.Agents))
+	if err := env.Printf("%s:\n", msg); err != nil {
+		return err
+	}
+	for _, agent := range resp.Agents {
+		if err := printReported(env, "SPIFFE ID", agent.SpiffeId, agent.Enabled, agent.ReportedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prettyPrintReload(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*featureflagsv1.ReloadFeatureFlagsResponse)
+	if !ok {
+		return fmt.Errorf("internal error: unexpected type %T returned; please report this as a bug", results[0])
+	}
+
+	if err := env.Println("Feature flags reloaded:"); err != nil {
+		return err
+	}
+	return printFlags(env, resp.Server)
+}
+
+func printFlags(env *commoncli.Env, flags []*featureflagsv1.FeatureFlag) error {
+	for _, flag := range flags {
+		state := "disabled"
+		if flag.Enabled {
+			state = "enabled"
+		}
+		if err := env.Printf("  %s: %s\n", flag.Name, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printReported(env *commoncli.Env, idLabel, id string, enabledFlags []string, reportedAt int64) error {
+	enabled := "none"
+	if len(enabledFlags) > 0 {
+		enabled = strings.Join(enabledFlags, ", ")
+	}
+	if err := env.Printf("\n%-11s : %s\n", idLabel, id); err != nil {
+		return err
+	}
+	if err := env.Printf("Enabled     : %s\n", enabled); err != nil {
+		return err
+	}
+	return env.Printf("Reported at : %s\n", time.Unix(reportedAt, 0).UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,55 @@
+package featureflags
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
+)
+
+type reloadCommand struct {
+	env     *commoncli.Env
+	printer cliprinter.Printer
+}
+
+// NewReloadCommand creates a new "featureflags reload" subcommand using the
+// default cli environment.
+func NewReloadCommand() cli.Command {
+	return NewReloadCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewReloadCommandWithEnv creates a new "featureflags reload" subcommand
+// using the given cli environment.
+func NewReloadCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &reloadCommand{env: env})
+}
+
+// The name of the command.
+func (*reloadCommand) Name() string {
+	return "featureflags reload"
+}
+
+// The help presented description of the command.
+func (*reloadCommand) Synopsis() string {
+	return "Reloads the server feature flags from its configuration file"
+}
+
+// Adds additional flags specific to the command.
+func (c *reloadCommand) AppendFlags(fs *flag.FlagSet) {
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintReload)
+}
+
+// The routine that executes the command
+func (c *reloadCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	resp, err := serverClient.NewFeatureFlagsClient().ReloadFeatureFlags(ctx, &featureflagsv1.ReloadFeatureFlagsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to reload feature flags: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
@@ -0,0 +1,86 @@
+package wit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	localauthorityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/localauthority/v1"
+	"github.com/spiffe/spire/cmd/spire-server/cli/authoritycommon"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+)
+
+// NewWITActivateCommand creates a new "wit activate" subcommand for "localauthority" command.
+func NewWITActivateCommand() cli.Command {
+	return NewWITActivateCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewWITActivateCommandWithEnv creates a new "wit activate" subcommand for "localauthority" command
+// using the environment specified
+func NewWITActivateCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &witActivateCommand{env: env})
+}
+
+type witActivateCommand struct {
+	authorityID string
+	printer     cliprinter.Printer
+	env         *commoncli.Env
+}
+
+func (c *witActivateCommand) Name() string {
+	return "localauthority wit activate"
+}
+
+func (*witActivateCommand) Synopsis() string {
+	return "Activates a prepared WIT authority for use, which will cause it to be used for all WIT signing operations serviced by this server going forward"
+}
+
+func (c *witActivateCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.authorityID, "authorityID", "", "The authority ID of the WIT authority to activate")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintWITActivate)
+}
+
+// Run executes all logic associated with a single invocation of the
+// `spire-server localauthority wit activate` CLI command
+func (c *witActivateCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	client := serverClient.NewLocalAuthorityClient()
+	resp, err := client.ActivateWITAuthority(ctx, &localauthorityv1.ActivateWITAuthorityRequest{
+		AuthorityId: c.authorityID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not activate WIT authority: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func (c *witActivateCommand) validate() error {
+	if c.authorityID == "" {
+		return errors.New("an authority ID is required")
+	}
+
+	return nil
+}
+
+func prettyPrintWITActivate(env *commoncli.Env, results ...any) error {
+	r, ok := results[0].(*localauthorityv1.ActivateWITAuthorityResponse)
+	if !ok {
+		return errors.New("internal error: cli printer; please report this bug")
+	}
+
+	env.Println("Activated WIT authority:")
+	if r.ActivatedAuthority == nil {
+		return errors.New("internal error: expected to have activated WIT authority information")
+	}
+	authoritycommon.PrettyPrintWITAuthorityState(env, r.ActivatedAuthority)
+
+	return nil
+}
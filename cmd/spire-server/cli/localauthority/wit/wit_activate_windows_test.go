@@ -0,0 +1,14 @@
+//go:build windows
+
+package wit_test
+
+var (
+	witActivateUsage = `Usage of localauthority wit activate:
+  -authorityID string
+    	The authority ID of the WIT authority to activate
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+`
+)
@@ -0,0 +1,70 @@
+package wit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	localauthorityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/localauthority/v1"
+	"github.com/spiffe/spire/cmd/spire-server/cli/authoritycommon"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+)
+
+// NewWITPrepareCommand creates a new "wit prepare" subcommand for "localauthority" command.
+func NewWITPrepareCommand() cli.Command {
+	return NewWITPrepareCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewWITPrepareCommandWithEnv creates a new "wit prepare" subcommand for "localauthority" command
+// using the environment specified
+func NewWITPrepareCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &witPrepareCommand{env: env})
+}
+
+type witPrepareCommand struct {
+	printer cliprinter.Printer
+	env     *commoncli.Env
+}
+
+func (c *witPrepareCommand) Name() string {
+	return "localauthority wit prepare"
+}
+
+func (*witPrepareCommand) Synopsis() string {
+	return "Prepares a new WIT authority for use by generating a new key and injecting it into the bundle"
+}
+
+func (c *witPrepareCommand) AppendFlags(f *flag.FlagSet) {
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintWITPrepare)
+}
+
+// Run executes all logic associated with a single invocation of the
+// `spire-server localauthority wit prepare` CLI command
+func (c *witPrepareCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	client := serverClient.NewLocalAuthorityClient()
+	resp, err := client.PrepareWITAuthority(ctx, &localauthorityv1.PrepareWITAuthorityRequest{})
+	if err != nil {
+		return fmt.Errorf("could not prepare WIT authority: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func prettyPrintWITPrepare(env *commoncli.Env, results ...any) error {
+	r, ok := results[0].(*localauthorityv1.PrepareWITAuthorityResponse)
+	if !ok {
+		return errors.New("internal error: cli printer; please report this bug")
+	}
+
+	env.Println("Prepared WIT authority:")
+	if r.PreparedAuthority == nil {
+		return errors.New("internal error: expected to have prepared WIT authority information")
+	}
+	authoritycommon.PrettyPrintWITAuthorityState(env, r.PreparedAuthority)
+
+	return nil
+}
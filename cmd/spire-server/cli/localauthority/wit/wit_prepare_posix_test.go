@@ -0,0 +1,14 @@
+//go:build !windows
+
+package wit_test
+
+var (
+	witPrepareUsage = `Usage of localauthority wit prepare:
+  -instance string
+    	Instance name to substitute into socket templates (env SPIRE_SERVER_PRIVATE_SOCKET_TEMPLATE).
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+`
+)
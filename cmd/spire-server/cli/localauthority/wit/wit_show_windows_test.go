@@ -0,0 +1,12 @@
+//go:build windows
+
+package wit_test
+
+var (
+	witShowUsage = `Usage of localauthority wit show:
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+`
+)
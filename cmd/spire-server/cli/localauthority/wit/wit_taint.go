@@ -0,0 +1,90 @@
+package wit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	localauthorityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/localauthority/v1"
+	"github.com/spiffe/spire/cmd/spire-server/cli/authoritycommon"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+)
+
+// NewWITTaintCommand creates a new "wit taint" subcommand for "localauthority" command.
+func NewWITTaintCommand() cli.Command {
+	return newWITTaintCommand(commoncli.DefaultEnv)
+}
+
+// NewWITTaintCommandWithEnv creates a new "wit taint" subcommand for "localauthority" command
+// using the environment specified
+func NewWITTaintCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &witTaintCommand{env: env})
+}
+
+func newWITTaintCommand(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &witTaintCommand{env: env})
+}
+
+type witTaintCommand struct {
+	authorityID string
+	printer     cliprinter.Printer
+	env         *commoncli.Env
+}
+
+func (c *witTaintCommand) Name() string {
+	return "localauthority wit taint"
+}
+
+func (*witTaintCommand) Synopsis() string {
+	return "Marks the previously active WIT authority as being tainted"
+}
+
+func (c *witTaintCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.authorityID, "authorityID", "", "The authority ID of the WIT authority to taint")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintWITTaint)
+}
+
+// Run executes all logic associated with a single invocation of the
+// `spire-server localauthority wit taint` CLI command
+func (c *witTaintCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	client := serverClient.NewLocalAuthorityClient()
+	resp, err := client.TaintWITAuthority(ctx, &localauthorityv1.TaintWITAuthorityRequest{
+		AuthorityId: c.authorityID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not taint WIT authority: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func prettyPrintWITTaint(env *commoncli.Env, results ...any) error {
+	r, ok := results[0].(*localauthorityv1.TaintWITAuthorityResponse)
+	if !ok {
+		return errors.New("internal error: cli printer; please report this bug")
+	}
+
+	env.Println("Tainted WIT authority:")
+	if r.TaintedAuthority == nil {
+		return errors.New("internal error: expected to have tainted WIT authority information")
+	}
+	authoritycommon.PrettyPrintWITAuthorityState(env, r.TaintedAuthority)
+
+	return nil
+}
+
+func (c *witTaintCommand) validate() error {
+	if c.authorityID == "" {
+		return errors.New("an authority ID is required")
+	}
+
+	return nil
+}
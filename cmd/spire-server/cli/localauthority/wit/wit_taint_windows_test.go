@@ -0,0 +1,14 @@
+//go:build windows
+
+package wit_test
+
+var (
+	witTaintUsage = `Usage of localauthority wit taint:
+  -authorityID string
+    	The authority ID of the WIT authority to taint
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+`
+)
@@ -39,10 +39,12 @@ import (
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/ca/manager"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
+	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 )
@@ -66,33 +68,34 @@ type Config struct {
 }
 
 type serverConfig struct {
-	AdminIDs                     []string           `hcl:"admin_ids"`
-	AgentTTL                     string             `hcl:"agent_ttl"`
-	AuditLogEnabled              bool               `hcl:"audit_log_enabled"`
-	BindAddress                  string             `hcl:"bind_address"`
-	BindPort                     int                `hcl:"bind_port"`
-	CAKeyType                    string             `hcl:"ca_key_type"`
-	CASubject                    *caSubjectConfig   `hcl:"ca_subject"`
-	CATTL                        string             `hcl:"ca_ttl"`
-	DataDir                      string             `hcl:"data_dir"`
-	DefaultX509SVIDTTL           string             `hcl:"default_x509_svid_ttl"`
-	DefaultJWTSVIDTTL            string             `hcl:"default_jwt_svid_ttl"`
-	Experimental                 experimentalConfig `hcl:"experimental"`
-	Federation                   *federationConfig  `hcl:"federation"`
-	DisableJWTSVIDs              bool               `hcl:"disable_jwt_svids"`
-	JWTIssuer                    string             `hcl:"jwt_issuer"`
-	JWTKeyType                   string             `hcl:"jwt_key_type"`
-	LogFile                      string             `hcl:"log_file"`
-	LogLevel                     string             `hcl:"log_level"`
-	LogFormat                    string             `hcl:"log_format"`
-	LogSourceLocation            bool               `hcl:"log_source_location"`
-	PruneAttestedNodesExpiredFor string             `hcl:"prune_attested_nodes_expired_for"`
-	PruneNonReattestableNodes    bool               `hcl:"prune_tofu_nodes"`
-	ProxyProtocolTrustedCIDRs    []string           `hcl:"proxy_protocol_trusted_cidrs"`
-	RateLimit                    rateLimitConfig    `hcl:"ratelimit"`
-	SocketPath                   string             `hcl:"socket_path"`
-	TrustDomain                  string             `hcl:"trust_domain"`
-	MaxAttestedNodeInfoStaleness *string            `hcl:"max_attested_node_info_staleness"`
+	AdminIDs                     []string            `hcl:"admin_ids"`
+	AgentTTL                     string              `hcl:"agent_ttl"`
+	AuditLogEnabled              bool                `hcl:"audit_log_enabled"`
+	AuditLogSink                 *auditLogSinkConfig `hcl:"audit_log_sink"`
+	BindAddress                  string              `hcl:"bind_address"`
+	BindPort                     int                 `hcl:"bind_port"`
+	CAKeyType                    string              `hcl:"ca_key_type"`
+	CASubject                    *caSubjectConfig    `hcl:"ca_subject"`
+	CATTL                        string              `hcl:"ca_ttl"`
+	DataDir                      string              `hcl:"data_dir"`
+	DefaultX509SVIDTTL           string              `hcl:"default_x509_svid_ttl"`
+	DefaultJWTSVIDTTL            string              `hcl:"default_jwt_svid_ttl"`
+	Experimental                 experimentalConfig  `hcl:"experimental"`
+	Federation                   *federationConfig   `hcl:"federation"`
+	DisableJWTSVIDs              bool                `hcl:"disable_jwt_svids"`
+	JWTIssuer                    string              `hcl:"jwt_issuer"`
+	JWTKeyType                   string              `hcl:"jwt_key_type"`
+	LogFile                      string              `hcl:"log_file"`
+	LogLevel                     string              `hcl:"log_level"`
+	LogFormat                    string              `hcl:"log_format"`
+	LogSourceLocation            bool                `hcl:"log_source_location"`
+	PruneAttestedNodesExpiredFor string              `hcl:"prune_attested_nodes_expired_for"`
+	PruneNonReattestableNodes    bool                `hcl:"prune_tofu_nodes"`
+	ProxyProtocolTrustedCIDRs    []string            `hcl:"proxy_protocol_trusted_cidrs"`
+	RateLimit                    rateLimitConfig     `hcl:"ratelimit"`
+	SocketPath                   string              `hcl:"socket_path"`
+	TrustDomain                  string              `hcl:"trust_domain"`
+	MaxAttestedNodeInfoStaleness *string             `hcl:"max_attested_node_info_staleness"`
 
 	ConfigPath string
 	ExpandEnv  bool
@@ -126,6 +129,33 @@ type experimentalConfig struct {
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
 
+type auditLogSinkConfig struct {
+	KeyFile            string                     `hcl:"key_file"`
+	File               *auditLogFileSinkConfig    `hcl:"file"`
+	Syslog             *auditLogSyslogSinkConfig  `hcl:"syslog"`
+	Webhook            *auditLogWebhookSinkConfig `hcl:"webhook"`
+	UnusedKeyPositions map[string][]token.Pos     `hcl:",unusedKeyPositions"`
+}
+
+type auditLogFileSinkConfig struct {
+	Path               string                 `hcl:"path"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type auditLogSyslogSinkConfig struct {
+	Network            string                 `hcl:"network"`
+	Address            string                 `hcl:"address"`
+	Tag                string                 `hcl:"tag"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type auditLogWebhookSinkConfig struct {
+	URL                string                 `hcl:"url"`
+	Timeout            string                 `hcl:"timeout"`
+	QueueSize          int                    `hcl:"queue_size"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
 type caSubjectConfig struct {
 	Country            []string               `hcl:"country"`
 	Organization       []string               `hcl:"organization"`
@@ -262,7 +292,111 @@ func LoadConfig(name string, args []string, logOptions []log.Option, output io.W
 		return nil, fmt.Errorf("error loading feature flags: %w", err)
 	}
 
-	return NewServerConfig(input, logOptions, allowUnknownConfig)
+	sc, err := NewServerConfig(input, logOptions, allowUnknownConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.FeatureFlagsLoader = func() (fflag.RawConfig, error) {
+		return loadFeatureFlags(cliInput.ConfigPath, cliInput.ExpandEnv)
+	}
+	sc.ConfigLoader = newConfigLoader(input, cliInput)
+	return sc, nil
+}
+
+// reloadableSettings are the settings that can be changed while the server is
+// running, named after their configuration keys.
+var reloadableSettings = []string{
+	"server.ratelimit",
+	"server.agent_ttl",
+	"server.default_x509_svid_ttl",
+	"server.default_jwt_svid_ttl",
+	"server.federation.federates_with",
+	"server.experimental.feature_flags",
+	"telemetry",
+	"plugins",
+}
+
+// newConfigLoader returns the loader used to reload the configuration while
+// the server is running. Changes are tracked against the given configuration
+// the server was started with.
+func newConfigLoader(startup *Config, cliInput *serverConfig) server.ConfigLoader {
+	prepareForDiff(startup)
+	tracker := config.NewChangeTracker(startup, reloadableSettings...)
+
+	return func() (*server.ReloadableConfig, error) {
+		fileInput, err := ParseFile(cliInput.ConfigPath, cliInput.ExpandEnv)
+		if err != nil {
+			return nil, err
+		}
+		c, err := mergeInput(fileInput, cliInput)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateConfig(c); err != nil {
+			return nil, err
+		}
+		c.Server.setDefaultsIfNeeded()
+
+		rc, err := newReloadableConfig(c)
+		if err != nil {
+			return nil, err
+		}
+
+		prepareForDiff(c)
+		rc.Changed, rc.RequiresRestart = tracker.Track(c)
+		return rc, nil
+	}
+}
+
+func newReloadableConfig(c *Config) (*server.ReloadableConfig, error) {
+	rc := &server.ReloadableConfig{
+		RateLimit:    parseRateLimit(c.Server),
+		Telemetry:    c.Telemetry,
+		FeatureFlags: c.Server.Experimental.Flags,
+	}
+
+	var err error
+	rc.AgentTTL, rc.X509SVIDTTL, rc.JWTSVIDTTL, err = parseSVIDTTLs(c.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Server.Federation != nil {
+		rc.FederatesWith, err = parseFederatesWith(c.Server.Federation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rc.PluginConfigs, err = catalog.PluginConfigsFromHCLNode(c.Plugins)
+	if err != nil {
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+// prepareForDiff fills in the optional sections that hold reloadable
+// settings, so that adding them to the configuration is not reported as a
+// change to the section itself.
+func prepareForDiff(c *Config) {
+	if c.Server.Federation == nil {
+		c.Server.Federation = &federationConfig{}
+	}
+}
+
+// loadFeatureFlags parses the config file again to get the feature flags
+// when they are reloaded.
+func loadFeatureFlags(path string, expandEnv bool) (fflag.RawConfig, error) {
+	c, err := ParseFile(path, expandEnv)
+	if err != nil {
+		return nil, err
+	}
+	if c.Server == nil {
+		return nil, errors.New("server section must be configured")
+	}
+	return c.Server.Experimental.Flags, nil
 }
 
 // Run the SPIFFE Server
@@ -361,6 +495,16 @@ func parseFlags(name string, args []string, output io.Writer) (*serverConfig, er
 	return c, nil
 }
 
+// ParseFileWithDefaults parses the SPIRE server config file and fills in the
+// defaults, without consulting command line flags or loading feature flags.
+func ParseFileWithDefaults(path string, expandEnv bool) (*Config, error) {
+	fileInput, err := ParseFile(path, expandEnv)
+	if err != nil {
+		return nil, err
+	}
+	return mergeInput(fileInput, &serverConfig{})
+}
+
 func mergeInput(fileInput *Config, cliInput *serverConfig) (*Config, error) {
 	c := &Config{Server: &serverConfig{}}
 
@@ -432,6 +576,12 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 
 	sc.DataDir = c.Server.DataDir
 	sc.AuditLogEnabled = c.Server.AuditLogEnabled
+	if c.Server.AuditLogSink != nil {
+		sc.AuditLogSink, err = auditLogSinkConfigFromHCL(c.Server.AuditLogSink)
+		if err != nil {
+			return nil, err
+		}
+	}
 	sc.ProxyProtocolTrustedCIDRs = c.Server.ProxyProtocolTrustedCIDRs
 
 	td, err := spiffeid.TrustDomainFromString(c.Server.TrustDomain)
@@ -441,15 +591,7 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	common_cli.WarnOnLongTrustDomainName(td, logger)
 	sc.TrustDomain = td
 
-	if c.Server.RateLimit.Attestation == nil {
-		c.Server.RateLimit.Attestation = &defaultRateLimit
-	}
-	sc.RateLimit.Attestation = *c.Server.RateLimit.Attestation
-
-	if c.Server.RateLimit.Signing == nil {
-		c.Server.RateLimit.Signing = &defaultRateLimit
-	}
-	sc.RateLimit.Signing = *c.Server.RateLimit.Signing
+	sc.RateLimit = parseRateLimit(c.Server)
 
 	if c.Server.Federation != nil {
 		if c.Server.Federation.BundleEndpoint != nil {
@@ -491,27 +633,10 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 			}
 		}
 
-		federatesWith := map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{}
-
-		for trustDomain, config := range c.Server.Federation.FederatesWith {
-			td, err := spiffeid.TrustDomainFromString(trustDomain)
-			if err != nil {
-				return nil, err
-			}
-
-			var trustDomainConfig *bundleClient.TrustDomainConfig
-			switch {
-			case config.BundleEndpointProfile != nil:
-				trustDomainConfig, err = parseBundleEndpointProfile(config)
-				if err != nil {
-					return nil, fmt.Errorf("error parsing federation relationship for trust domain %q: %w", trustDomain, err)
-				}
-			default:
-				return nil, fmt.Errorf("federation configuration for trust domain %q: missing bundle endpoint configuration", trustDomain)
-			}
-			federatesWith[td] = *trustDomainConfig
+		sc.Federation.FederatesWith, err = parseFederatesWith(c.Server.Federation)
+		if err != nil {
+			return nil, err
 		}
-		sc.Federation.FederatesWith = federatesWith
 	}
 
 	sc.ProfilingEnabled = c.Server.ProfilingEnabled
@@ -542,37 +667,9 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.AdminIDs = append(sc.AdminIDs, id)
 	}
 
-	if c.Server.AgentTTL != "" {
-		ttl, err := time.ParseDuration(c.Server.AgentTTL)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse agent ttl %q: %w", c.Server.AgentTTL, err)
-		}
-		sc.AgentTTL = ttl
-	}
-
-	switch {
-	case c.Server.DefaultX509SVIDTTL != "":
-		ttl, err := time.ParseDuration(c.Server.DefaultX509SVIDTTL)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse default X509 SVID ttl %q: %w", c.Server.DefaultX509SVIDTTL, err)
-		}
-		sc.X509SVIDTTL = ttl
-	default:
-		// If neither new nor deprecated config value is set, then use hard-coded default TTL
-		// Note, due to back-compat issues we cannot set this default inside defaultConfig() function
-		sc.X509SVIDTTL = credtemplate.DefaultX509SVIDTTL
-	}
-
-	if c.Server.DefaultJWTSVIDTTL != "" {
-		ttl, err := time.ParseDuration(c.Server.DefaultJWTSVIDTTL)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse default JWT SVID ttl %q: %w", c.Server.DefaultJWTSVIDTTL, err)
-		}
-		sc.JWTSVIDTTL = ttl
-	} else {
-		// If not set using new field then use hard-coded default TTL
-		// Note, due to back-compat issues we cannot set this default inside defaultConfig() function
-		sc.JWTSVIDTTL = credtemplate.DefaultJWTSVIDTTL
+	sc.AgentTTL, sc.X509SVIDTTL, sc.JWTSVIDTTL, err = parseSVIDTTLs(c.Server)
+	if err != nil {
+		return nil, err
 	}
 
 	if c.Server.CATTL != "" {
@@ -924,6 +1021,114 @@ func parseBundleEndpointProfileASTNode(node ast.Node) (string, error) {
 	return data.String(), nil
 }
 
+func auditLogSinkConfigFromHCL(c *auditLogSinkConfig) (*audit.SinkConfig, error) {
+	sinkConfig := &audit.SinkConfig{
+		KeyFile: c.KeyFile,
+	}
+	if c.File != nil {
+		sinkConfig.File = &audit.FileSinkConfig{
+			Path: c.File.Path,
+		}
+	}
+	if c.Syslog != nil {
+		sinkConfig.Syslog = &audit.SyslogSinkConfig{
+			Network: c.Syslog.Network,
+			Address: c.Syslog.Address,
+			Tag:     c.Syslog.Tag,
+		}
+		if sinkConfig.Syslog.Tag == "" {
+			sinkConfig.Syslog.Tag = "spire-server"
+		}
+	}
+	if c.Webhook != nil {
+		sinkConfig.Webhook = &audit.WebhookSinkConfig{
+			URL:       c.Webhook.URL,
+			QueueSize: c.Webhook.QueueSize,
+		}
+		if c.Webhook.Timeout != "" {
+			timeout, err := time.ParseDuration(c.Webhook.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse audit_log_sink webhook timeout: %w", err)
+			}
+			sinkConfig.Webhook.Timeout = timeout
+		}
+	}
+	return sinkConfig, nil
+}
+
+// parseRateLimit returns the rate limiting configuration, enabling the rate
+// limits that are not configured.
+func parseRateLimit(c *serverConfig) endpoints.RateLimitConfig {
+	if c.RateLimit.Attestation == nil {
+		c.RateLimit.Attestation = &defaultRateLimit
+	}
+	if c.RateLimit.Signing == nil {
+		c.RateLimit.Signing = &defaultRateLimit
+	}
+	return endpoints.RateLimitConfig{
+		Attestation: *c.RateLimit.Attestation,
+		Signing:     *c.RateLimit.Signing,
+	}
+}
+
+func parseFederatesWith(c *federationConfig) (map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig, error) {
+	federatesWith := map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{}
+
+	for trustDomain, config := range c.FederatesWith {
+		td, err := spiffeid.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, err
+		}
+
+		var trustDomainConfig *bundleClient.TrustDomainConfig
+		switch {
+		case config.BundleEndpointProfile != nil:
+			trustDomainConfig, err = parseBundleEndpointProfile(config)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing federation relationship for trust domain %q: %w", trustDomain, err)
+			}
+		default:
+			return nil, fmt.Errorf("federation configuration for trust domain %q: missing bundle endpoint configuration", trustDomain)
+		}
+		federatesWith[td] = *trustDomainConfig
+	}
+	return federatesWith, nil
+}
+
+func parseSVIDTTLs(c *serverConfig) (agentTTL, x509SVIDTTL, jwtSVIDTTL time.Duration, err error) {
+	if c.AgentTTL != "" {
+		agentTTL, err = time.ParseDuration(c.AgentTTL)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("could not parse agent ttl %q: %w", c.AgentTTL, err)
+		}
+	}
+
+	switch {
+	case c.DefaultX509SVIDTTL != "":
+		x509SVIDTTL, err = time.ParseDuration(c.DefaultX509SVIDTTL)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("could not parse default X509 SVID ttl %q: %w", c.DefaultX509SVIDTTL, err)
+		}
+	default:
+		// If neither new nor deprecated config value is set, then use hard-coded default TTL
+		// Note, due to back-compat issues we cannot set this default inside defaultConfig() function
+		x509SVIDTTL = credtemplate.DefaultX509SVIDTTL
+	}
+
+	if c.DefaultJWTSVIDTTL != "" {
+		jwtSVIDTTL, err = time.ParseDuration(c.DefaultJWTSVIDTTL)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("could not parse default JWT SVID ttl %q: %w", c.DefaultJWTSVIDTTL, err)
+		}
+	} else {
+		// If not set using new field then use hard-coded default TTL
+		// Note, due to back-compat issues we cannot set this default inside defaultConfig() function
+		jwtSVIDTTL = credtemplate.DefaultJWTSVIDTTL
+	}
+
+	return agentTTL, x509SVIDTTL, jwtSVIDTTL, nil
+}
+
 func validateConfig(c *Config) error {
 	if c.Server == nil {
 		return errors.New("server section must be configured")
@@ -945,6 +1150,31 @@ func validateConfig(c *Config) error {
 		return errors.New("plugins section must be configured")
 	}
 
+	if sink := c.Server.AuditLogSink; sink != nil {
+		if !c.Server.AuditLogEnabled {
+			return errors.New("audit_log_sink requires audit_log_enabled to be set")
+		}
+
+		var outputs int
+		for _, set := range []bool{sink.File != nil, sink.Syslog != nil, sink.Webhook != nil} {
+			if set {
+				outputs++
+			}
+		}
+		if outputs != 1 {
+			return errors.New("audit_log_sink must configure exactly one of file, syslog, or webhook")
+		}
+		if sink.KeyFile == "" {
+			return errors.New("audit_log_sink.key_file must be configured")
+		}
+		if sink.File != nil && sink.File.Path == "" {
+			return errors.New("audit_log_sink.file.path must be configured")
+		}
+		if sink.Webhook != nil && sink.Webhook.URL == "" {
+			return errors.New("audit_log_sink.webhook.url must be configured")
+		}
+	}
+
 	if c.Server.Federation != nil {
 		if c.Server.Federation.BundleEndpoint != nil &&
 			c.Server.Federation.BundleEndpoint.ACME != nil {
@@ -1010,6 +1240,21 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 			detectedUnknown("ca_subject", cs.UnusedKeyPositions)
 		}
 
+		if sink := c.Server.AuditLogSink; sink != nil {
+			if len(sink.UnusedKeyPositions) != 0 {
+				detectedUnknown("audit_log_sink", sink.UnusedKeyPositions)
+			}
+			if sink.File != nil && len(sink.File.UnusedKeyPositions) != 0 {
+				detectedUnknown("audit_log_sink file", sink.File.UnusedKeyPositions)
+			}
+			if sink.Syslog != nil && len(sink.Syslog.UnusedKeyPositions) != 0 {
+				detectedUnknown("audit_log_sink syslog", sink.Syslog.UnusedKeyPositions)
+			}
+			if sink.Webhook != nil && len(sink.Webhook.UnusedKeyPositions) != 0 {
+				detectedUnknown("audit_log_sink webhook", sink.Webhook.UnusedKeyPositions)
+			}
+		}
+
 		if rl := c.Server.RateLimit; len(rl.UnusedKeyPositions) != 0 {
 			detectedUnknown("ratelimit", rl.UnusedKeyPositions)
 		}
@@ -1083,6 +1328,14 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		detectedUnknown("InMem", p.UnusedKeyPositions)
 	}
 
+	if p := c.Telemetry.OTLP; p != nil && len(p.UnusedKeyPositions) != 0 {
+		detectedUnknown("OTLP", p.UnusedKeyPositions)
+	}
+
+	if p := c.Telemetry.Tracing; p != nil && len(p.UnusedKeyPositions) != 0 {
+		detectedUnknown("Tracing", p.UnusedKeyPositions)
+	}
+
 	if len(c.HealthChecks.UnusedKeyPositions) != 0 {
 		detectedUnknown("health check", c.HealthChecks.UnusedKeyPositions)
 	}
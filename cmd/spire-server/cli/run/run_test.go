@@ -15,9 +15,11 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
@@ -1301,6 +1303,76 @@ func TestNewServerConfig(t *testing.T) {
 				require.False(t, c.AuditLogEnabled)
 			},
 		},
+		{
+			msg: "audit_log_sink is not set",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.AuditLogSink)
+			},
+		},
+		{
+			msg: "audit_log_sink file is set",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					KeyFile: "/etc/spire/audit.key",
+					File:    &auditLogFileSinkConfig{Path: "/var/log/spire/audit.log"},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, &audit.SinkConfig{
+					KeyFile: "/etc/spire/audit.key",
+					File:    &audit.FileSinkConfig{Path: "/var/log/spire/audit.log"},
+				}, c.AuditLogSink)
+			},
+		},
+		{
+			msg: "audit_log_sink syslog is set",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					KeyFile: "/etc/spire/audit.key",
+					Syslog:  &auditLogSyslogSinkConfig{Network: "udp", Address: "localhost:514"},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, &audit.SinkConfig{
+					KeyFile: "/etc/spire/audit.key",
+					Syslog:  &audit.SyslogSinkConfig{Network: "udp", Address: "localhost:514", Tag: "spire-server"},
+				}, c.AuditLogSink)
+			},
+		},
+		{
+			msg: "audit_log_sink webhook is set",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					KeyFile: "/etc/spire/audit.key",
+					Webhook: &auditLogWebhookSinkConfig{URL: "https://audit.example.org", Timeout: "10s", QueueSize: 10},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, &audit.SinkConfig{
+					KeyFile: "/etc/spire/audit.key",
+					Webhook: &audit.WebhookSinkConfig{URL: "https://audit.example.org", Timeout: 10 * time.Second, QueueSize: 10},
+				}, c.AuditLogSink)
+			},
+		},
+		{
+			msg:         "audit_log_sink webhook timeout is invalid",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					Webhook: &auditLogWebhookSinkConfig{URL: "https://audit.example.org", Timeout: "forever"},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "proxy_protocol_trusted_cidrs is set",
 			input: func(c *Config) {
@@ -1487,6 +1559,66 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectedErr: `federation.federates_with["domain.test"].bundle_endpoint_url must use the HTTPS protocol; URL found: "http://example.org/test"`,
 		},
+		{
+			name: "audit_log_sink requires audit_log_enabled",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					File: &auditLogFileSinkConfig{Path: "audit.log"},
+				}
+			},
+			expectedErr: "audit_log_sink requires audit_log_enabled to be set",
+		},
+		{
+			name: "audit_log_sink requires an output",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{}
+			},
+			expectedErr: "audit_log_sink must configure exactly one of file, syslog, or webhook",
+		},
+		{
+			name: "audit_log_sink requires a single output",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					File:   &auditLogFileSinkConfig{Path: "audit.log"},
+					Syslog: &auditLogSyslogSinkConfig{},
+				}
+			},
+			expectedErr: "audit_log_sink must configure exactly one of file, syslog, or webhook",
+		},
+		{
+			name: "audit_log_sink file path must be configured",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					KeyFile: "audit.key",
+					File:    &auditLogFileSinkConfig{},
+				}
+			},
+			expectedErr: "audit_log_sink.file.path must be configured",
+		},
+		{
+			name: "audit_log_sink webhook url must be configured",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					KeyFile: "audit.key",
+					Webhook: &auditLogWebhookSinkConfig{},
+				}
+			},
+			expectedErr: "audit_log_sink.webhook.url must be configured",
+		},
+		{
+			name: "audit_log_sink key file must be configured",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSink = &auditLogSinkConfig{
+					File: &auditLogFileSinkConfig{Path: "audit.log"},
+				}
+			},
+			expectedErr: "audit_log_sink.key_file must be configured",
+		},
 		{
 			name: "can't set both sql_transaction_timeout and event_timeout",
 			applyConf: func(c *Config) {
@@ -1697,6 +1829,26 @@ func TestWarnOnUnknownConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			msg:      "in nested OTLP block",
+			confFile: "server_and_agent_bad_nested_OTLP_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "OTLP",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in nested Tracing block",
+			confFile: "server_and_agent_bad_nested_Tracing_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "Tracing",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
 		{
 			msg:      "in nested health_checks block",
 			confFile: "server_and_agent_bad_nested_health_checks_block.conf",
@@ -2056,6 +2208,119 @@ func TestMinCATTL(t *testing.T) {
 	}
 }
 
+func TestLoadFeatureFlags(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	configPath := filepath.Join(dir, "server.conf")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`server {
+	experimental {
+		feature_flags = ["wit-svid"]
+	}
+}`), 0o600))
+	flags, err := loadFeatureFlags(configPath, false)
+	require.NoError(t, err)
+	require.Equal(t, fflag.RawConfig{"wit-svid"}, flags)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`plugins {}`), 0o600))
+	_, err = loadFeatureFlags(configPath, false)
+	require.EqualError(t, err, "server section must be configured")
+
+	_, err = loadFeatureFlags(filepath.Join(dir, "missing.conf"), false)
+	require.ErrorContains(t, err, "could not find config file")
+}
+
+func TestConfigLoader(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	configPath := filepath.Join(dir, "server.conf")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`server {
+	bind_address = "127.0.0.1"
+	bind_port = "8081"
+	trust_domain = "example.org"
+	data_dir = "`+dir+`"
+}
+plugins {
+	KeyManager "memory" {
+		plugin_data {}
+	}
+}`), 0o600))
+
+	cliInput := &serverConfig{ConfigPath: configPath}
+	fileInput, err := ParseFile(configPath, false)
+	require.NoError(t, err)
+	input, err := mergeInput(fileInput, cliInput)
+	require.NoError(t, err)
+	_, err = NewServerConfig(input, []log.Option{log.WithOutputFile(os.DevNull)}, false)
+	require.NoError(t, err)
+	loadConfig := newConfigLoader(input, cliInput)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`server {
+	bind_address = "127.0.0.1"
+	bind_port = "8082"
+	trust_domain = "example.org"
+	data_dir = "`+dir+`"
+	default_x509_svid_ttl = "2h"
+	ratelimit {
+		signing = false
+	}
+	federation {
+		federates_with "domain.test" {
+			bundle_endpoint_url = "https://domain.test/bundle"
+			bundle_endpoint_profile "https_web" {}
+		}
+	}
+}
+plugins {
+	KeyManager "memory" {
+		plugin_data {
+			foo = "bar"
+		}
+	}
+}
+telemetry {
+	MetricPrefix = "spire"
+}`), 0o600))
+
+	rc, err := loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"plugins",
+		"server.default_x509_svid_ttl",
+		"server.federation.federates_with.domain.test",
+		"server.ratelimit.signing",
+		"telemetry.MetricPrefix",
+	}, rc.Changed)
+	assert.Equal(t, []string{"server.bind_port"}, rc.RequiresRestart)
+	assert.Equal(t, 2*time.Hour, rc.X509SVIDTTL)
+	assert.Equal(t, credtemplate.DefaultJWTSVIDTTL, rc.JWTSVIDTTL)
+	assert.True(t, rc.RateLimit.Attestation)
+	assert.False(t, rc.RateLimit.Signing)
+	assert.Contains(t, rc.FederatesWith, spiffeid.RequireTrustDomainFromString("domain.test"))
+	assert.Equal(t, "spire", rc.Telemetry.MetricPrefix)
+	require.Len(t, rc.PluginConfigs, 1)
+	data, err := rc.PluginConfigs[0].DataSource.Load()
+	require.NoError(t, err)
+	assert.Contains(t, data, "foo")
+
+	// Reloading the same configuration again reports no changes, but the
+	// changes that require a restart are still reported.
+	rc, err = loadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, rc.Changed)
+	assert.Equal(t, []string{"server.bind_port"}, rc.RequiresRestart)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`server {
+	bind_address = "127.0.0.1"
+	bind_port = "8081"
+	trust_domain = "example.org"
+	data_dir = "`+dir+`"
+	default_x509_svid_ttl = "forever"
+}
+plugins {}`), 0o600))
+	_, err = loadConfig()
+	require.EqualError(t, err, `could not parse default X509 SVID ttl "forever": time: invalid duration "forever"`)
+}
+
 func TestExpandEnv(t *testing.T) {
 	require.NoError(t, os.Setenv("TEST_DATA_TRUST_DOMAIN", "example.org"))
 
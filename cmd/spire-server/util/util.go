@@ -20,6 +20,8 @@ import (
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/jwtutil"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -50,6 +52,8 @@ type ServerClient interface {
 	NewTrustDomainClient() trustdomainv1.TrustDomainClient
 	NewLocalAuthorityClient() localauthorityv1.LocalAuthorityClient
 	NewHealthClient() grpc_health_v1.HealthClient
+	NewFeatureFlagsClient() featureflagsv1.FeatureFlagsClient
+	NewEntryTemplatesClient() entrytemplatev1.EntryTemplatesClient
 }
 
 func NewServerClient(addr string) (ServerClient, error) {
@@ -100,6 +104,14 @@ func (c *serverClient) NewLocalAuthorityClient() localauthorityv1.LocalAuthority
 	return localauthorityv1.NewLocalAuthorityClient(c.conn)
 }
 
+func (c *serverClient) NewFeatureFlagsClient() featureflagsv1.FeatureFlagsClient {
+	return featureflagsv1.NewFeatureFlagsClient(c.conn)
+}
+
+func (c *serverClient) NewEntryTemplatesClient() entrytemplatev1.EntryTemplatesClient {
+	return entrytemplatev1.NewEntryTemplatesClient(c.conn)
+}
+
 // Pluralizer concatenates `singular` to `msg` when `val` is one, and
 // `plural` on all other occasions. It is meant to facilitate friendlier
 // CLI output.
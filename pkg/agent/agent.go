@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	_ "net/http/pprof" //nolint: gosec // import registers routes on DefaultServeMux
+	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent/storage"
 	"github.com/spiffe/spire/pkg/agent/svid/store"
 	"github.com/spiffe/spire/pkg/common/backoff"
+	"github.com/spiffe/spire/pkg/common/config"
 	"github.com/spiffe/spire/pkg/common/diskutil"
 	"github.com/spiffe/spire/pkg/common/errorutil"
 	"github.com/spiffe/spire/pkg/common/health"
@@ -49,6 +51,7 @@ const (
 	bootstrapBackoffMaxElapsedTime   = 1 * time.Minute
 	startHealthChecksTimeout         = 8 * time.Second
 	rebootstrapBackoffMaxElapsedTime = 24 * time.Hour
+	cacheSnapshotFileName            = "cache_snapshot.json"
 )
 
 type Agent struct {
@@ -296,6 +299,14 @@ func (a *Agent) Run(ctx context.Context) error {
 		tasks = append(tasks, a.c.LogReopener)
 	}
 
+	if a.c.ConfigLoader != nil {
+		tasks = append(tasks, func(ctx context.Context) error {
+			return config.ReloadOnSignal(ctx, a.c.Log.WithField(telemetry.SubsystemName, telemetry.ConfigReloader), func(ctx context.Context) {
+				a.reloadConfig(ctx, metrics, cat)
+			})
+		})
+	}
+
 	taskRunner.StartTasks(tasks...)
 	err = taskRunner.Wait()
 	if errors.Is(err, context.Canceled) || errorutil.IsSIGINTOrSIGTERMError(err) {
@@ -397,6 +408,10 @@ func (a *Agent) newManager(ctx context.Context, sto storage.Storage, cat catalog
 		RotationStrategy:         rotationutil.NewRotationStrategy(a.c.AvailabilityTarget),
 		TLSPolicy:                a.c.TLSPolicy,
 	}
+	if a.c.CacheSnapshotEnabled {
+		config.CacheSnapshotPath = filepath.Join(a.c.DataDir, cacheSnapshotFileName)
+		config.CacheSnapshotInterval = a.c.CacheSnapshotInterval
+	}
 
 	mgr := manager.New(config)
 	initBackoffClock := clock.New()
@@ -500,6 +515,7 @@ func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, att
 		LogSelectors:                  a.c.LogSelectors,
 		TrustDomain:                   a.c.TrustDomain,
 		WorkloadAPIRateLimit:          a.c.WorkloadAPIRateLimit,
+		IsWITSVIDsDisabled:            a.c.IsWITSVIDsDisabled,
 	})
 }
 
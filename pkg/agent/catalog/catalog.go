@@ -73,6 +73,12 @@ func (repo *Repository) Reconfigure(ctx context.Context) {
 	repo.catalog.Reconfigure(ctx)
 }
 
+// ReconfigureWith reconfigures the plugins with the data from the reloaded
+// plugin configurations.
+func (repo *Repository) ReconfigureWith(ctx context.Context, pluginConfigs catalog.PluginConfigs) catalog.PluginChanges {
+	return repo.catalog.ReconfigureWith(ctx, pluginConfigs)
+}
+
 func (repo *Repository) Close() {
 	repo.log.Debug("Closing catalog")
 	if err := repo.catalog.Close(); err == nil {
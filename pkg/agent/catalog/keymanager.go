@@ -6,6 +6,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager/disk"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager/memory"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager/pkcs11"
 )
 
 type keyManagerRepository struct {
@@ -28,6 +29,7 @@ func (repo *keyManagerRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
 		disk.BuiltIn(),
 		memory.BuiltIn(),
+		pkcs11.BuiltIn(),
 	}
 }
 
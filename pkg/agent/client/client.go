@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,12 +20,15 @@ import (
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -72,6 +76,12 @@ type JWTSVID struct {
 	ExpiresAt time.Time
 }
 
+type WITSVID struct {
+	Token     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 type SyncStats struct {
 	Entries SyncEntriesStats
 	Bundles SyncBundlesStats
@@ -94,6 +104,7 @@ type Client interface {
 	RenewSVID(ctx context.Context, csr []byte) (*X509SVID, error)
 	NewX509SVIDs(ctx context.Context, csrs map[string][]byte) (map[string]*X509SVID, error)
 	NewJWTSVID(ctx context.Context, entryID string, audience []string, hasCacheHit bool) (*JWTSVID, spiffeid.ID, error)
+	NewWITSVID(ctx context.Context, entryID string, publicKey []byte, signingAlgorithm string, hasCacheHit bool) (*WITSVID, spiffeid.ID, error)
 	PostStatus(ctx context.Context, agentVersion string) error
 
 	// Release releases any resources that were held by this Client, if any.
@@ -297,6 +308,9 @@ func (c *client) PostStatus(ctx context.Context, agentVersion string) error {
 	}
 	defer connection.Release()
 
+	// The feature flags enabled on the agent are reported through metadata
+	// so the server can report them alongside its own.
+	ctx = metadata.AppendToOutgoingContext(ctx, fflag.AgentMetadataKey, strings.Join(fflag.Enabled(), ","))
 	_, err = agentClient.PostStatus(ctx, &agentv1.PostStatusRequest{
 		AgentVersion: agentVersion,
 	})
@@ -402,6 +416,72 @@ func (c *client) NewJWTSVID(ctx context.Context, entryID string, audience []stri
 	}, spiffeId, nil
 }
 
+func (c *client) NewWITSVID(ctx context.Context, entryID string, publicKey []byte, signingAlgorithm string, hasCacheHit bool) (*WITSVID, spiffeid.ID, error) {
+	c.c.RotMtx.RLock()
+	defer c.c.RotMtx.RUnlock()
+
+	timeout := rpcTimeout
+	if hasCacheHit {
+		timeout = RPCTimeoutWithCacheHit
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	svidClient, connection, err := c.newSVIDClient()
+	if err != nil {
+		return nil, spiffeid.ID{}, err
+	}
+	defer connection.Release()
+
+	resp, err := svidClient.BatchNewWITSVID(ctx, &svidv1.BatchNewWITSVIDRequest{
+		Params: []*svidv1.NewWITSVIDParams{
+			{
+				EntryId:          entryID,
+				PublicKey:        publicKey,
+				SigningAlgorithm: signingAlgorithm,
+			},
+		},
+	})
+	if err != nil {
+		c.release(connection)
+		c.withErrorFields(err).Error("Failed to fetch WIT SVID")
+		return nil, spiffeid.ID{}, fmt.Errorf("failed to fetch WIT SVID: %w", err)
+	}
+
+	if len(resp.Results) != 1 {
+		return nil, spiffeid.ID{}, fmt.Errorf("expected 1 WITSVID result; got %d", len(resp.Results))
+	}
+
+	result := resp.Results[0]
+	if result.Status.GetCode() != int32(codes.OK) {
+		return nil, spiffeid.ID{}, fmt.Errorf("failed to mint WIT SVID: %s", result.Status.GetMessage())
+	}
+
+	svid := result.Svid
+	switch {
+	case svid == nil:
+		return nil, spiffeid.ID{}, errors.New("WITSVID response missing SVID")
+	case svid.IssuedAt == 0:
+		return nil, spiffeid.ID{}, errors.New("WITSVID missing issued at")
+	case svid.ExpiresAt == 0:
+		return nil, spiffeid.ID{}, errors.New("WITSVID missing expires at")
+	case svid.IssuedAt > svid.ExpiresAt:
+		return nil, spiffeid.ID{}, errors.New("WITSVID issued after it has expired")
+	}
+
+	spiffeID, err := idutil.IDFromProto(svid.Id)
+	if err != nil {
+		return nil, spiffeid.ID{}, fmt.Errorf("could not parse WIT-SVID SPIFFE ID: %w", err)
+	}
+
+	return &WITSVID{
+		Token:     svid.Token,
+		IssuedAt:  time.Unix(svid.IssuedAt, 0).UTC(),
+		ExpiresAt: time.Unix(svid.ExpiresAt, 0).UTC(),
+	}, spiffeID, nil
+}
+
 // Release the underlying connection.
 func (c *client) Release() {
 	c.release(nil)
@@ -446,6 +526,7 @@ func (c *client) fetchEntries(ctx context.Context) ([]*types.Entry, error) {
 	}
 	defer connection.Release()
 
+	ctx = withEntryTemplatesSupport(ctx)
 	resp, err := entryClient.GetAuthorizedEntries(ctx, &entryv1.GetAuthorizedEntriesRequest{
 		OutputMask: entryOutputMask,
 	})
@@ -458,6 +539,12 @@ func (c *client) fetchEntries(ctx context.Context) ([]*types.Entry, error) {
 	return resp.Entries, err
 }
 
+// withEntryTemplatesSupport advertises that the agent renders entry templates,
+// so that the server sends the template entries along with the other entries.
+func withEntryTemplatesSupport(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, entrytemplate.AgentMetadataKey, "true")
+}
+
 func (c *client) syncEntries(ctx context.Context, cachedEntries map[string]*common.RegistrationEntry) (SyncEntriesStats, error) {
 	entryClient, connection, err := c.newEntryClient()
 	if err != nil {
@@ -579,7 +666,7 @@ func (c *client) streamAndSyncEntries(ctx context.Context, entryClient entryv1.E
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	stream, err := entryClient.SyncAuthorizedEntries(ctx)
+	stream, err := entryClient.SyncAuthorizedEntries(withEntryTemplatesSupport(ctx))
 	if err != nil {
 		return SyncEntriesStats{}, err
 	}
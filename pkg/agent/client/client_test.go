@@ -21,6 +21,8 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/entry/v1"
@@ -31,6 +33,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/testing/protocmp"
@@ -149,6 +152,43 @@ func TestFetchUpdates(t *testing.T) {
 				Path:        "/id4",
 			},
 		},
+		// This entry is an entry template, rendered by the cache
+		{
+			Id:       "template:template1",
+			ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			SpiffeId: &types.SPIFFEID{
+				TrustDomain: "example.org",
+				Path:        "/ns/{{ .k8s.ns }}",
+			},
+			Selectors: []*types.Selector{
+				{Type: "k8s", Value: "sa:default"},
+			},
+		},
+		// This entry should be ignored since its entry template is malformed
+		{
+			Id:       "template:template2",
+			ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			SpiffeId: &types.SPIFFEID{
+				TrustDomain: "example.org",
+				Path:        "/ns/{{ .k8s }}",
+			},
+			Selectors: []*types.Selector{
+				{Type: "k8s", Value: "sa:default"},
+			},
+		},
+		// This entry should be ignored since only entry templates can have
+		// a templated SPIFFE ID
+		{
+			Id:       "ENTRYID7",
+			ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			SpiffeId: &types.SPIFFEID{
+				TrustDomain: "example.org",
+				Path:        "/ns/{{ .k8s.ns }}",
+			},
+			Selectors: []*types.Selector{
+				{Type: "k8s", Value: "sa:default"},
+			},
+		},
 	}
 
 	tc.svidServer.x509SVIDs = map[string]*types.X509SVID{
@@ -186,12 +226,15 @@ func TestFetchUpdates(t *testing.T) {
 	// Assert results
 	require.Nil(t, err)
 	assert.Equal(t, testBundles, update.Bundles)
-	// Only the first registration entry should be returned since the rest are
-	// invalid for one reason or another
-	if assert.Len(t, update.Entries, 1) {
+	// Only the first registration entry and the entry template should be
+	// returned since the rest are invalid for one reason or another
+	if assert.Len(t, update.Entries, 2) {
 		entry := testEntries[0]
 		assert.Equal(t, entry, update.Entries[entry.EntryId])
+		assert.Equal(t, "spiffe://example.org/ns/{{ .k8s.ns }}", update.Entries["template:template1"].SpiffeId)
 	}
+	// The agent advertises that it renders entry templates
+	assert.Equal(t, []string{"true"}, tc.entryServer.entryTemplatesSupport)
 	assertConnectionIsNotNil(t, client)
 }
 
@@ -283,6 +326,7 @@ func TestSyncUpdatesEntries(t *testing.T) {
 			Dropped: dropped,
 		}, stats.Entries)
 		assert.Equal(t, expected, cachedEntries)
+		assert.Equal(t, []string{"true"}, tc.entryServer.entryTemplatesSupport)
 	}
 
 	firstDate := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
@@ -990,6 +1034,153 @@ func TestFetchJWTSVID(t *testing.T) {
 	}
 }
 
+func TestNewWITSVID(t *testing.T) {
+	client, tc := createClient(t)
+
+	issuedAt := time.Now().Unix()
+	expiresAt := time.Now().Add(time.Minute).Unix()
+	for _, tt := range []struct {
+		name           string
+		setupTest      func(err error)
+		err            string
+		expectSVID     *WITSVID
+		expectSPIFFEID spiffeid.ID
+		fetchErr       error
+	}{
+		{
+			name: "success",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = &types.WITSVID{
+					Token: "token",
+					Id: &types.SPIFFEID{
+						TrustDomain: "example.org",
+						Path:        "/workload",
+					},
+					ExpiresAt: expiresAt,
+					IssuedAt:  issuedAt,
+				}
+				tc.svidServer.witSVIDStatus = nil
+				tc.svidServer.newWITSVID = err
+			},
+			expectSVID: &WITSVID{
+				Token:     "token",
+				ExpiresAt: time.Unix(expiresAt, 0).UTC(),
+				IssuedAt:  time.Unix(issuedAt, 0).UTC(),
+			},
+			expectSPIFFEID: spiffeid.RequireFromString("spiffe://example.org/workload"),
+		},
+		{
+			name: "client fails",
+			setupTest: func(err error) {
+				tc.svidServer.newWITSVID = err
+			},
+			err:      "failed to fetch WIT SVID: rpc error: code = Unknown desc = client fails",
+			fetchErr: errors.New("client fails"),
+		},
+		{
+			name: "result status not ok",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = nil
+				tc.svidServer.witSVIDStatus = &types.Status{
+					Code:    int32(codes.NotFound),
+					Message: "entry not found or not authorized",
+				}
+				tc.svidServer.newWITSVID = err
+			},
+			err: "failed to mint WIT SVID: entry not found or not authorized",
+		},
+		{
+			name: "empty response",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = nil
+				tc.svidServer.witSVIDStatus = nil
+				tc.svidServer.newWITSVID = err
+			},
+			err: "WITSVID response missing SVID",
+		},
+		{
+			name: "missing issuedAt",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = &types.WITSVID{
+					Token:     "token",
+					ExpiresAt: expiresAt,
+				}
+				tc.svidServer.newWITSVID = err
+			},
+			err: "WITSVID missing issued at",
+		},
+		{
+			name: "missing expiredAt",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = &types.WITSVID{
+					Token:    "token",
+					IssuedAt: issuedAt,
+				}
+				tc.svidServer.newWITSVID = err
+			},
+			err: "WITSVID missing expires at",
+		},
+		{
+			name: "issued after expired",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = &types.WITSVID{
+					Token:     "token",
+					ExpiresAt: issuedAt,
+					IssuedAt:  expiresAt,
+				}
+				tc.svidServer.newWITSVID = err
+			},
+			err: "WITSVID issued after it has expired",
+		},
+		{
+			name: "grpc call to BatchNewWITSVID fails",
+			setupTest: func(err error) {
+				tc.svidServer.witSVID = &types.WITSVID{
+					Token:     "token",
+					ExpiresAt: expiresAt,
+					IssuedAt:  issuedAt,
+				}
+				tc.svidServer.newWITSVID = err
+			},
+			err:      "failed to fetch WIT SVID: rpc error: code = Internal desc = BatchNewWITSVID fails",
+			fetchErr: status.Error(codes.Internal, "BatchNewWITSVID fails"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupTest(tt.fetchErr)
+			resp, spiffeID, err := client.NewWITSVID(ctx, "entry-id", []byte("public-key"), "ES256", false)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				require.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			require.Equal(t, tt.expectSVID, resp)
+			require.Equal(t, tt.expectSPIFFEID, spiffeID)
+		})
+	}
+}
+
+func TestPostStatus(t *testing.T) {
+	require.NoError(t, fflag.Load(fflag.RawConfig{"wit-svid"}))
+	t.Cleanup(func() {
+		require.NoError(t, fflag.Unload())
+	})
+
+	client, tc := createClient(t)
+
+	err := client.PostStatus(ctx, "1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3", tc.agentServer.postStatusVersion)
+	require.Equal(t, []string{"wit-svid"}, tc.agentServer.postStatusFlags)
+
+	tc.agentServer.err = status.Error(codes.Unimplemented, "not supported")
+	err = client.PostStatus(ctx, "1.2.3")
+	require.EqualError(t, err, "failed to post agent status: rpc error: code = Unimplemented desc = not supported")
+}
+
 // createClient creates a sample client with mocked components for testing purposes
 func createClient(t *testing.T) (*client, *testServer) {
 	tc := &testServer{
@@ -1045,13 +1236,18 @@ type fakeEntryServer struct {
 
 	entries []*types.Entry
 	err     error
+
+	// entryTemplatesSupport holds the entry templates support advertised
+	// by the last call
+	entryTemplatesSupport []string
 }
 
 func (c *fakeEntryServer) SetEntries(entries ...*types.Entry) {
 	c.entries = entries
 }
 
-func (c *fakeEntryServer) GetAuthorizedEntries(_ context.Context, in *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+func (c *fakeEntryServer) GetAuthorizedEntries(ctx context.Context, in *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+	c.entryTemplatesSupport = metadata.ValueFromIncomingContext(ctx, entrytemplate.AgentMetadataKey)
 	if c.err != nil {
 		return nil, c.err
 	}
@@ -1068,6 +1264,8 @@ func (c *fakeEntryServer) GetAuthorizedEntries(_ context.Context, in *entryv1.Ge
 func (c *fakeEntryServer) SyncAuthorizedEntries(stream entryv1.Entry_SyncAuthorizedEntriesServer) error {
 	const entryPageSize = 2
 
+	c.entryTemplatesSupport = metadata.ValueFromIncomingContext(stream.Context(), entrytemplate.AgentMetadataKey)
+
 	entries := []api.ReadOnlyEntry{}
 	for _, entry := range c.entries {
 		entries = append(entries, api.NewReadOnlyEntry(entry))
@@ -1116,8 +1314,11 @@ type fakeSVIDServer struct {
 
 	batchSVIDErr    error
 	newJWTSVID      error
+	newWITSVID      error
 	x509SVIDs       map[string]*types.X509SVID
 	jwtSVID         *types.JWTSVID
+	witSVID         *types.WITSVID
+	witSVIDStatus   *types.Status
 	simulateRelease func()
 }
 
@@ -1166,10 +1367,42 @@ func (c *fakeSVIDServer) NewJWTSVID(context.Context, *svidv1.NewJWTSVIDRequest)
 	}, nil
 }
 
+func (c *fakeSVIDServer) BatchNewWITSVID(context.Context, *svidv1.BatchNewWITSVIDRequest) (*svidv1.BatchNewWITSVIDResponse, error) {
+	if c.newWITSVID != nil {
+		return nil, c.newWITSVID
+	}
+
+	resultStatus := c.witSVIDStatus
+	if resultStatus == nil {
+		resultStatus = &types.Status{Code: int32(codes.OK)}
+	}
+	return &svidv1.BatchNewWITSVIDResponse{
+		Results: []*svidv1.BatchNewWITSVIDResponse_Result{
+			{
+				Status: resultStatus,
+				Svid:   c.witSVID,
+			},
+		},
+	}, nil
+}
+
 type fakeAgentServer struct {
 	agentv1.UnimplementedAgentServer
 	err  error
 	svid *types.X509SVID
+
+	postStatusVersion string
+	postStatusFlags   []string
+}
+
+func (c *fakeAgentServer) PostStatus(ctx context.Context, in *agentv1.PostStatusRequest) (*agentv1.PostStatusResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	c.postStatusVersion = in.AgentVersion
+	c.postStatusFlags = metadata.ValueFromIncomingContext(ctx, fflag.AgentMetadataKey)
+	return &agentv1.PostStatusResponse{}, nil
 }
 
 func (c *fakeAgentServer) RenewAgent(_ context.Context, in *agentv1.RenewAgentRequest) (*agentv1.RenewAgentResponse, error) {
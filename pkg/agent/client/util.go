@@ -7,6 +7,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
@@ -28,6 +29,26 @@ func spiffeIDFromProto(protoID *types.SPIFFEID) (string, error) {
 	return id.String(), nil
 }
 
+// spiffeIDTemplateFromProto parses the SPIFFE ID of an entry template, which
+// is rendered by the cache for each workload.
+func spiffeIDTemplateFromProto(protoID *types.SPIFFEID) (string, error) {
+	if protoID == nil {
+		return "", errors.New("response missing SPIFFE ID")
+	}
+
+	td, err := spiffeid.TrustDomainFromString(protoID.TrustDomain)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := entrytemplate.New(td, protoID.Path)
+	if err != nil {
+		return "", err
+	}
+
+	return tmpl.String(), nil
+}
+
 func additionalAttributesFromProto(in *types.Entry_AdditionalAttributes) *common.RegistrationEntry_AdditionalAttributes {
 	if in != nil {
 		return &common.RegistrationEntry_AdditionalAttributes{
@@ -46,7 +67,11 @@ func slicedEntryFromProto(e *types.Entry) (*common.RegistrationEntry, error) {
 		return nil, errors.New("missing entry ID")
 	}
 
-	spiffeID, err := spiffeIDFromProto(e.SpiffeId)
+	toSPIFFEID := spiffeIDFromProto
+	if entrytemplate.IsTemplateEntryID(e.Id) {
+		toSPIFFEID = spiffeIDTemplateFromProto
+	}
+	spiffeID, err := toSPIFFEID(e.SpiffeId)
 	if err != nil {
 		return nil, fmt.Errorf("invalid SPIFFE ID: %w", err)
 	}
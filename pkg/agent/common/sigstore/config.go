@@ -1,6 +1,10 @@
 package sigstore
 
-import "github.com/hashicorp/go-hclog"
+import (
+	"errors"
+
+	"github.com/hashicorp/go-hclog"
+)
 
 // Config holds configuration for the ImageVerifier.
 type Config struct {
@@ -13,6 +17,10 @@ type Config struct {
 	IgnoreTlog         bool
 	IgnoreAttestations bool
 
+	TrustedRootPath        string
+	Offline                bool
+	RequiredPredicateTypes []string
+
 	Logger hclog.Logger
 }
 
@@ -45,6 +53,35 @@ type HCLConfig struct {
 
 	// RegistryCredentials is a map of credentials keyed by registry URL
 	RegistryCredentials map[string]*RegistryCredential `hcl:"registry_credentials,omitempty" json:"registry_credentials,omitempty"`
+
+	// TrustedRootPath is the path to a Sigstore trusted root (trusted_root.json) holding the Fulcio certificates,
+	// and the Rekor, CT log and timestamp authority keys to verify against, instead of the public-good instance
+	// roots retrieved through TUF.
+	TrustedRootPath *string `hcl:"trusted_root_path,omitempty" json:"trusted_root_path,omitempty"`
+
+	// Offline specifies whether to verify the transparency log inclusion only with the bundles attached to the
+	// signatures and attestations, without querying Rekor. Requires TrustedRootPath.
+	Offline *bool `hcl:"offline,omitempty" json:"offline,omitempty"`
+
+	// RequiredPredicateTypes is a list of predicate types for which the image must have a verified attestation.
+	RequiredPredicateTypes []string `hcl:"required_predicate_types,omitempty" json:"required_predicate_types,omitempty"`
+}
+
+// Validate checks that the configured options can be used together.
+func (c *HCLConfig) Validate() error {
+	offline := c.Offline != nil && *c.Offline
+	ignoreAttestations := c.IgnoreAttestations != nil && *c.IgnoreAttestations
+
+	if offline && (c.TrustedRootPath == nil || *c.TrustedRootPath == "") {
+		return errors.New("trusted_root_path is required for offline verification")
+	}
+	if offline && c.RekorURL != nil {
+		return errors.New("rekor_url cannot be used with offline verification")
+	}
+	if ignoreAttestations && len(c.RequiredPredicateTypes) > 0 {
+		return errors.New("required_predicate_types cannot be used when ignoring attestations")
+	}
+	return nil
 }
 
 type RegistryCredential struct {
@@ -83,6 +120,16 @@ func NewConfigFromHCL(hclConfig *HCLConfig, log hclog.Logger) *Config {
 		config.IgnoreAttestations = *hclConfig.IgnoreAttestations
 	}
 
+	if hclConfig.TrustedRootPath != nil {
+		config.TrustedRootPath = *hclConfig.TrustedRootPath
+	}
+
+	if hclConfig.Offline != nil {
+		config.Offline = *hclConfig.Offline
+	}
+
+	config.RequiredPredicateTypes = hclConfig.RequiredPredicateTypes
+
 	if hclConfig.RegistryCredentials != nil {
 		m := make(map[string]*RegistryCredential)
 		for k, v := range hclConfig.RegistryCredentials {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewConfigFromHCL(t *testing.T) {
@@ -25,6 +26,11 @@ func TestNewConfigFromHCL(t *testing.T) {
 				IgnoreSCT:          new(true),
 				IgnoreTlog:         new(true),
 				IgnoreAttestations: new(true),
+				TrustedRootPath:    new("/trusted_root.json"),
+				Offline:            new(true),
+				RequiredPredicateTypes: []string{
+					"https://slsa.dev/provenance/v1",
+				},
 				RegistryCredentials: map[string]*RegistryCredential{
 					"registry": {
 						Username: "user",
@@ -42,6 +48,11 @@ func TestNewConfigFromHCL(t *testing.T) {
 				IgnoreSCT:          true,
 				IgnoreTlog:         true,
 				IgnoreAttestations: true,
+				TrustedRootPath:    "/trusted_root.json",
+				Offline:            true,
+				RequiredPredicateTypes: []string{
+					"https://slsa.dev/provenance/v1",
+				},
 				RegistryCredentials: map[string]*RegistryCredential{
 					"registry": {
 						Username: "user",
@@ -75,3 +86,58 @@ func TestNewConfigFromHCL(t *testing.T) {
 		})
 	}
 }
+
+func TestHCLConfigValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		hcl       *HCLConfig
+		expectErr string
+	}{
+		{
+			name: "empty",
+			hcl:  &HCLConfig{},
+		},
+		{
+			name: "offline with trusted root",
+			hcl: &HCLConfig{
+				TrustedRootPath: new("/trusted_root.json"),
+				Offline:         new(true),
+			},
+		},
+		{
+			name: "offline without trusted root",
+			hcl: &HCLConfig{
+				Offline: new(true),
+			},
+			expectErr: "trusted_root_path is required for offline verification",
+		},
+		{
+			name: "offline with rekor url",
+			hcl: &HCLConfig{
+				TrustedRootPath: new("/trusted_root.json"),
+				Offline:         new(true),
+				RekorURL:        new("https://rekor.example.org"),
+			},
+			expectErr: "rekor_url cannot be used with offline verification",
+		},
+		{
+			name: "required predicate types when ignoring attestations",
+			hcl: &HCLConfig{
+				IgnoreAttestations:     new(true),
+				RequiredPredicateTypes: []string{"https://slsa.dev/provenance/v1"},
+			},
+			expectErr: "required_predicate_types cannot be used when ignoring attestations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hcl.Validate()
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
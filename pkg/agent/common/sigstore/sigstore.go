@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -20,6 +21,7 @@ import (
 	cosignremote "github.com/sigstore/cosign/v3/pkg/oci/remote"
 	"github.com/sigstore/rekor/pkg/client"
 	rekorclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/sigstore-go/pkg/root"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/fulcioroots"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -29,6 +31,7 @@ const (
 	imageSignatureVerifiedSelector    = "image-signature:verified"
 	imageAttestationsVerifiedSelector = "image-attestations:verified"
 	publicRekorURL                    = "https://rekor.sigstore.dev"
+	inTotoPayloadType                 = "application/vnd.in-toto+json"
 )
 
 var (
@@ -53,6 +56,7 @@ type ImageVerifier struct {
 	fulcioIntermediates *x509.CertPool
 	rekorPublicKeys     *cosign.TrustedTransparencyLogPubKeys
 	ctLogPublicKeys     *cosign.TrustedTransparencyLogPubKeys
+	trustedMaterial     root.TrustedMaterial
 
 	sigstoreFunctions sigstoreFunctions
 }
@@ -65,6 +69,7 @@ type sigstoreFunctions struct {
 	getFulcioIntermediates  getCertPoolFn
 	getRekorPublicKeys      getTLogPublicKeysFn
 	getCTLogPublicKeys      getTLogPublicKeysFn
+	getTrustedRoot          getTrustedRootFn
 }
 
 type cosignVerifyImageSignaturesFn func(context.Context, name.Reference, *cosign.CheckOpts) ([]oci.Signature, bool, error)
@@ -72,6 +77,7 @@ type cosignVerifyImageAttestationsFn func(context.Context, name.Reference, *cosi
 type getRekorClientFn func(string, ...client.Option) (*rekorclient.Rekor, error)
 type getCertPoolFn func() (*x509.CertPool, error)
 type getTLogPublicKeysFn func(context.Context) (*cosign.TrustedTransparencyLogPubKeys, error)
+type getTrustedRootFn func(string) (root.TrustedMaterial, error)
 
 func NewVerifier(config *Config) *ImageVerifier {
 	verifier := &ImageVerifier{
@@ -85,6 +91,7 @@ func NewVerifier(config *Config) *ImageVerifier {
 			getFulcioIntermediates:  fulcioroots.GetIntermediates,
 			getRekorPublicKeys:      cosign.GetRekorPubs,
 			getCTLogPublicKeys:      cosign.GetCTLogPubs,
+			getTrustedRoot:          loadTrustedRoot,
 		},
 	}
 
@@ -102,7 +109,15 @@ func NewVerifier(config *Config) *ImageVerifier {
 }
 
 // Init prepares the verifier by retrieving the Fulcio certificates and Rekor and CT public keys.
+// If a trusted root is configured, they are loaded from it instead.
 func (v *ImageVerifier) Init(ctx context.Context) error {
+	if v.config.TrustedRootPath != "" {
+		return v.initFromTrustedRoot()
+	}
+	if v.config.Offline {
+		return errors.New("offline verification requires a trusted root")
+	}
+
 	var err error
 	v.fulcioRoots, err = v.sigstoreFunctions.getFulcioRoots()
 	if err != nil {
@@ -135,6 +150,25 @@ func (v *ImageVerifier) Init(ctx context.Context) error {
 	return nil
 }
 
+// initFromTrustedRoot prepares the verifier with the configured trusted root. The Rekor client is
+// only needed when the transparency log is verified online.
+func (v *ImageVerifier) initFromTrustedRoot() error {
+	var err error
+	v.trustedMaterial, err = v.sigstoreFunctions.getTrustedRoot(v.config.TrustedRootPath)
+	if err != nil {
+		return fmt.Errorf("failed to load trusted root: %w", err)
+	}
+
+	if !v.config.IgnoreTlog && !v.config.Offline {
+		v.rekorClient, err = v.sigstoreFunctions.getRekorClient(v.config.RekorURL, client.WithLogger(v.config.Logger))
+		if err != nil {
+			return fmt.Errorf("failed to get rekor client: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Verify validates image's signatures, attestations, and transparency logs using Cosign and Rekor.
 // The imageID parameter is expected to be in the format "repository@sha256:digest".
 // It returns selectors based on the image signature and rekor bundle details.
@@ -176,9 +210,11 @@ func (v *ImageVerifier) Verify(ctx context.Context, imageID string) ([]string, e
 		IntermediateCerts:  v.fulcioIntermediates,
 		RekorPubKeys:       v.rekorPublicKeys,
 		CTLogPubKeys:       v.ctLogPublicKeys,
+		TrustedMaterial:    v.trustedMaterial,
 		Identities:         v.allowedIdentities,
 		IgnoreSCT:          v.config.IgnoreSCT,
 		IgnoreTlog:         v.config.IgnoreTlog,
+		Offline:            v.config.Offline,
 		RegistryClientOpts: []cosignremote.Option{cosignremote.WithRemoteOptions(authOption)},
 	}
 
@@ -197,6 +233,16 @@ func (v *ImageVerifier) Verify(ctx context.Context, imageID string) ([]string, e
 		if len(attestations) > 0 {
 			selectors = append(selectors, imageAttestationsVerifiedSelector)
 		}
+
+		attestationDetailsList, err := extractDetailsFromAttestations(attestations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract details from attestations for image %q: %w", imageID, err)
+		}
+		if err := v.checkRequiredPredicateTypes(attestationDetailsList); err != nil {
+			return nil, fmt.Errorf("image %q does not satisfy the required attestations: %w", imageID, err)
+		}
+
+		selectors = append(selectors, formatAttestationDetailsAsSelectors(attestationDetailsList)...)
 	}
 
 	detailsList, err := v.extractDetailsFromSignatures(signatures)
@@ -256,6 +302,94 @@ func (v *ImageVerifier) extractDetailsFromSignatures(signatures []oci.Signature)
 	return detailsList, nil
 }
 
+func extractDetailsFromAttestations(attestations []oci.Signature) ([]*attestationDetails, error) {
+	var detailsList []*attestationDetails
+	for _, attestation := range attestations {
+		details, err := extractAttestationDetails(attestation)
+		if err != nil {
+			return nil, err
+		}
+		detailsList = append(detailsList, details)
+	}
+	return detailsList, nil
+}
+
+func extractAttestationDetails(attestation oci.Signature) (*attestationDetails, error) {
+	cert, err := getCertificate(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate from attestation: %w", err)
+	}
+
+	subject, err := extractSubject(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract subject from certificate: %w", err)
+	}
+
+	issuer, err := extractIssuer(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract issuer from certificate: %w", err)
+	}
+
+	predicateType, err := extractPredicateType(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract predicate type from attestation: %w", err)
+	}
+
+	return &attestationDetails{
+		PredicateType: predicateType,
+		Subject:       subject,
+		Issuer:        issuer,
+	}, nil
+}
+
+// extractPredicateType returns the predicate type of the in-toto statement
+// wrapped in the DSSE envelope of the attestation. An empty string is returned
+// if the attestation payload is not an in-toto statement.
+func extractPredicateType(attestation oci.Signature) (string, error) {
+	payload, err := attestation.Payload()
+	if err != nil {
+		return "", fmt.Errorf("failed to get attestation payload: %w", err)
+	}
+
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil || envelope.PayloadType != inTotoPayloadType {
+		return "", nil
+	}
+
+	statementBytes, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode in-toto statement: %w", err)
+	}
+
+	var statement struct {
+		PredicateType string `json:"predicateType"`
+	}
+	if err := json.Unmarshal(statementBytes, &statement); err != nil {
+		return "", fmt.Errorf("failed to parse in-toto statement: %w", err)
+	}
+
+	return statement.PredicateType, nil
+}
+
+func (v *ImageVerifier) checkRequiredPredicateTypes(detailsList []*attestationDetails) error {
+	for _, requiredType := range v.config.RequiredPredicateTypes {
+		found := false
+		for _, details := range detailsList {
+			if details.PredicateType == requiredType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no verified attestation with predicate type %q", requiredType)
+		}
+	}
+	return nil
+}
+
 func extractSignatureDetails(signature oci.Signature, ignoreTlog bool) (*signatureDetails, error) {
 	cert, err := getCertificate(signature)
 	if err != nil {
@@ -365,6 +499,12 @@ type signatureDetails struct {
 	SignedEntryTimestamp string
 }
 
+type attestationDetails struct {
+	PredicateType string
+	Subject       string
+	Issuer        string
+}
+
 func formatDetailsAsSelectors(detailsList []*signatureDetails) []string {
 	var selectors []string
 	for _, details := range detailsList {
@@ -399,6 +539,31 @@ func detailsToSelectors(details *signatureDetails) []string {
 	return selectors
 }
 
+// formatAttestationDetailsAsSelectors returns the selectors for the predicate types and signer identities of the
+// attestations. Attestations commonly share the same signer, so duplicated selectors are omitted.
+func formatAttestationDetailsAsSelectors(detailsList []*attestationDetails) []string {
+	var selectors []string
+	seen := make(map[string]bool)
+	add := func(selector string) {
+		if !seen[selector] {
+			seen[selector] = true
+			selectors = append(selectors, selector)
+		}
+	}
+	for _, details := range detailsList {
+		if details.PredicateType != "" {
+			add(fmt.Sprintf("image-attestation-predicate-type:%s", details.PredicateType))
+		}
+		if details.Subject != "" {
+			add(fmt.Sprintf("image-attestation-subject:%s", details.Subject))
+		}
+		if details.Issuer != "" {
+			add(fmt.Sprintf("image-attestation-issuer:%s", details.Issuer))
+		}
+	}
+	return selectors
+}
+
 func processRegistryCredentials(credentials map[string]*RegistryCredential, logger hclog.Logger) map[string]remote.Option {
 	authOptions := make(map[string]remote.Option)
 
@@ -452,3 +617,7 @@ func containsRegexChars(s string) bool {
 	// check for characters commonly used in regex.
 	return strings.ContainsAny(s, "*+?^${}[]|()")
 }
+
+func loadTrustedRoot(path string) (root.TrustedMaterial, error) {
+	return root.NewTrustedRootFromPath(path)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/sigstore/cosign/v3/pkg/oci"
 	"github.com/sigstore/rekor/pkg/client"
 	rekorclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/sigstore-go/pkg/root"
 	"github.com/sigstore/sigstore/pkg/signature/payload"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -92,6 +93,7 @@ func TestNewVerifier(t *testing.T) {
 	assert.NotNil(t, verifier.sigstoreFunctions.getFulcioIntermediates)
 	assert.NotNil(t, verifier.sigstoreFunctions.getRekorPublicKeys)
 	assert.NotNil(t, verifier.sigstoreFunctions.getCTLogPublicKeys)
+	assert.NotNil(t, verifier.sigstoreFunctions.getTrustedRoot)
 }
 
 func TestInitialize(t *testing.T) {
@@ -131,6 +133,78 @@ func TestInitialize(t *testing.T) {
 	assert.Equal(t, 1, verifierSetup.fakeGetRekorPubs.CallCount)
 	assert.Equal(t, 1, verifierSetup.fakeGetCTLogPubs.CallCount)
 	assert.Equal(t, 1, verifierSetup.fakeGetRekorClient.CallCount)
+	assert.Equal(t, 0, verifierSetup.fakeGetTrustedRoot.CallCount)
+}
+
+func TestInitializeWithTrustedRoot(t *testing.T) {
+	for _, tt := range []struct {
+		name                     string
+		trustedRootPath          string
+		offline                  bool
+		trustedRootErr           error
+		expectErr                string
+		expectTrustedRootCalls   int
+		expectRekorClientCalls   int
+		expectTrustedMaterialSet bool
+	}{
+		{
+			name:                     "online transparency log verification",
+			trustedRootPath:          "/trusted_root.json",
+			expectTrustedRootCalls:   1,
+			expectRekorClientCalls:   1,
+			expectTrustedMaterialSet: true,
+		},
+		{
+			name:                     "offline transparency log verification",
+			trustedRootPath:          "/trusted_root.json",
+			offline:                  true,
+			expectTrustedRootCalls:   1,
+			expectTrustedMaterialSet: true,
+		},
+		{
+			name:                   "fails to load trusted root",
+			trustedRootPath:        "/trusted_root.json",
+			trustedRootErr:         errors.New("oh no"),
+			expectErr:              "failed to load trusted root: oh no",
+			expectTrustedRootCalls: 1,
+		},
+		{
+			name:      "offline without trusted root",
+			offline:   true,
+			expectErr: "offline verification requires a trusted root",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			verifierSetup := setupVerifier()
+			verifierSetup.verifier.config.TrustedRootPath = tt.trustedRootPath
+			verifierSetup.verifier.config.Offline = tt.offline
+			if tt.trustedRootErr == nil {
+				verifierSetup.fakeGetTrustedRoot.Response.TrustedMaterial = &root.TrustedRoot{}
+			}
+			verifierSetup.fakeGetTrustedRoot.Response.Err = tt.trustedRootErr
+			verifierSetup.fakeGetRekorClient.Response.Client = &rekorclient.Rekor{}
+
+			err := verifierSetup.verifier.Init(context.Background())
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectTrustedMaterialSet, verifierSetup.verifier.trustedMaterial != nil)
+			assert.Equal(t, tt.expectTrustedRootCalls, verifierSetup.fakeGetTrustedRoot.CallCount)
+			assert.Equal(t, tt.expectRekorClientCalls, verifierSetup.fakeGetRekorClient.CallCount)
+			if tt.expectTrustedRootCalls > 0 {
+				assert.Equal(t, tt.trustedRootPath, verifierSetup.fakeGetTrustedRoot.Path)
+			}
+
+			// The public-good instance roots are never retrieved
+			assert.Equal(t, 0, verifierSetup.fakeGetFulcioRoots.CallCount)
+			assert.Equal(t, 0, verifierSetup.fakeGetFulcioIntermediates.CallCount)
+			assert.Equal(t, 0, verifierSetup.fakeGetRekorPubs.CallCount)
+			assert.Equal(t, 0, verifierSetup.fakeGetCTLogPubs.CallCount)
+		})
+	}
 }
 
 func TestVerify(t *testing.T) {
@@ -191,6 +265,8 @@ func TestVerify(t *testing.T) {
 				"image-signature-log-index:9876543210",
 				"image-signature-integrated-time:1234567890",
 				fmt.Sprintf("image-signature-signed-entry-timestamp:%s", base64.StdEncoding.EncodeToString([]byte("test-signed-timestamp"))),
+				"image-attestation-subject:test-subject-san",
+				"image-attestation-issuer:test-issuer",
 			},
 			expectedError:                 false,
 			expectedVerifyCallCount:       1,
@@ -269,6 +345,8 @@ func TestVerify(t *testing.T) {
 				"image-signature-subject:test-subject-san",
 				"image-signature-issuer:test-issuer",
 				"image-signature-value:base64signature",
+				"image-attestation-subject:test-subject-san",
+				"image-attestation-issuer:test-issuer",
 			},
 			expectedError:                 false,
 			expectedVerifyCallCount:       1,
@@ -353,6 +431,116 @@ func TestVerify(t *testing.T) {
 			expectedVerifyCallCount:       1,
 			expectedAttestationsCallCount: 1,
 		},
+		{
+			name: "generates selectors from attestation predicate types",
+			configureTest: func(ctx context.Context, verifier *ImageVerifier, signatureVerifyFake *fakeCosignVerifySignatureFn, attestationsVerifyFake *fakeCosignVerifyAttestationsFn) {
+				verifier.config.IgnoreTlog = true
+				verifier.config.RequiredPredicateTypes = []string{"https://slsa.dev/provenance/v1"}
+
+				signatureVerifyFake.Responses = append(signatureVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{&fakeSignature{
+						payload:         createFakePayload(),
+						base64Signature: "base64signature",
+						cert:            createTestCert(),
+					}},
+				})
+				attestationsVerifyFake.Responses = append(attestationsVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{
+						&fakeSignature{
+							payload: createFakeAttestationPayload("https://slsa.dev/provenance/v1"),
+							cert:    createTestCert(),
+						},
+						&fakeSignature{
+							payload: createFakeAttestationPayload("https://spdx.dev/Document"),
+							cert:    createTestCert(),
+						},
+					},
+				})
+			},
+			expectedSelectors: []string{
+				imageSignatureVerifiedSelector,
+				imageAttestationsVerifiedSelector,
+				"image-signature-subject:test-subject-san",
+				"image-signature-issuer:test-issuer",
+				"image-signature-value:base64signature",
+				"image-attestation-predicate-type:https://slsa.dev/provenance/v1",
+				"image-attestation-predicate-type:https://spdx.dev/Document",
+				"image-attestation-subject:test-subject-san",
+				"image-attestation-issuer:test-issuer",
+			},
+			expectedError:                 false,
+			expectedVerifyCallCount:       1,
+			expectedAttestationsCallCount: 1,
+		},
+		{
+			name: "missing required predicate type",
+			configureTest: func(ctx context.Context, verifier *ImageVerifier, signatureVerifyFake *fakeCosignVerifySignatureFn, attestationsVerifyFake *fakeCosignVerifyAttestationsFn) {
+				verifier.config.IgnoreTlog = true
+				verifier.config.RequiredPredicateTypes = []string{"https://slsa.dev/provenance/v1", "https://cyclonedx.org/bom"}
+
+				signatureVerifyFake.Responses = append(signatureVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{&fakeSignature{
+						payload:         createFakePayload(),
+						base64Signature: "base64signature",
+						cert:            createTestCert(),
+					}},
+				})
+				attestationsVerifyFake.Responses = append(attestationsVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{&fakeSignature{
+						payload: createFakeAttestationPayload("https://slsa.dev/provenance/v1"),
+						cert:    createTestCert(),
+					}},
+				})
+			},
+			expectedSelectors:             nil,
+			expectedError:                 true,
+			expectedVerifyCallCount:       1,
+			expectedAttestationsCallCount: 1,
+		},
+		{
+			name: "required predicate type without attestations",
+			configureTest: func(ctx context.Context, verifier *ImageVerifier, signatureVerifyFake *fakeCosignVerifySignatureFn, attestationsVerifyFake *fakeCosignVerifyAttestationsFn) {
+				verifier.config.IgnoreTlog = true
+				verifier.config.RequiredPredicateTypes = []string{"https://slsa.dev/provenance/v1"}
+
+				signatureVerifyFake.Responses = append(signatureVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{&fakeSignature{
+						payload:         createFakePayload(),
+						base64Signature: "base64signature",
+						cert:            createTestCert(),
+					}},
+				})
+				attestationsVerifyFake.Responses = append(attestationsVerifyFake.Responses, fakeResponse{})
+			},
+			expectedSelectors:             nil,
+			expectedError:                 true,
+			expectedVerifyCallCount:       1,
+			expectedAttestationsCallCount: 1,
+		},
+		{
+			name: "fails to parse in-toto statement",
+			configureTest: func(ctx context.Context, verifier *ImageVerifier, signatureVerifyFake *fakeCosignVerifySignatureFn, attestationsVerifyFake *fakeCosignVerifyAttestationsFn) {
+				verifier.config.IgnoreTlog = true
+
+				signatureVerifyFake.Responses = append(signatureVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{&fakeSignature{
+						payload:         createFakePayload(),
+						base64Signature: "base64signature",
+						cert:            createTestCert(),
+					}},
+				})
+				attestationsVerifyFake.Responses = append(attestationsVerifyFake.Responses, fakeResponse{
+					Signatures: []oci.Signature{&fakeSignature{
+						payload: []byte(`{"payloadType":"application/vnd.in-toto+json","payload":"not base64"}`),
+						cert:    createTestCert(),
+					}},
+				})
+			},
+			expectedSelectors:             nil,
+			expectedError:                 true,
+			expectedVerifyCallCount:       1,
+			expectedAttestationsCallCount: 1,
+		},
 		{
 			name: "cache hit",
 			configureTest: func(ctx context.Context, verifier *ImageVerifier, _ *fakeCosignVerifySignatureFn, _ *fakeCosignVerifyAttestationsFn) {
@@ -520,6 +708,42 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestVerifyWithTrustedRoot(t *testing.T) {
+	imageID := "test-id@sha256:" + hex.EncodeToString(make([]byte, sha256.Size))
+	trustedMaterial := &root.TrustedRoot{}
+
+	verifierSetup := setupVerifier()
+	verifierSetup.verifier.config.TrustedRootPath = "/trusted_root.json"
+	verifierSetup.verifier.config.Offline = true
+	verifierSetup.verifier.config.IgnoreAttestations = true
+	verifierSetup.fakeGetTrustedRoot.Response.TrustedMaterial = trustedMaterial
+	verifierSetup.fakeCosignVerifySignature.Responses = []fakeResponse{
+		{
+			Signatures: []oci.Signature{&fakeSignature{
+				payload:         createFakePayload(),
+				base64Signature: "base64signature",
+				cert:            createTestCert(),
+				bundle:          createFakeBundle(),
+			}},
+			BundleVerified: true,
+		},
+	}
+	require.NoError(t, verifierSetup.verifier.Init(context.Background()))
+
+	selectors, err := verifierSetup.verifier.Verify(context.Background(), imageID)
+	require.NoError(t, err)
+	assert.Contains(t, selectors, "image-signature-log-index:9876543210")
+
+	checkOpts := verifierSetup.fakeCosignVerifySignature.CheckOpts
+	require.NotNil(t, checkOpts)
+	assert.Same(t, trustedMaterial, checkOpts.TrustedMaterial)
+	assert.True(t, checkOpts.Offline)
+	assert.Nil(t, checkOpts.RekorClient)
+	assert.Nil(t, checkOpts.RootCerts)
+	assert.Nil(t, checkOpts.RekorPubKeys)
+	assert.Nil(t, checkOpts.CTLogPubKeys)
+}
+
 func TestProcessAllowedIdentities(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -600,6 +824,7 @@ func TestProcessAllowedIdentities(t *testing.T) {
 type fakeCosignVerifySignatureFn struct {
 	Responses []fakeResponse
 	CallCount int
+	CheckOpts *cosign.CheckOpts
 }
 
 type fakeResponse struct {
@@ -608,18 +833,15 @@ type fakeResponse struct {
 	Err            error
 }
 
-func (f *fakeCosignVerifySignatureFn) Verify(_ context.Context, _ name.Reference, _ *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+func (f *fakeCosignVerifySignatureFn) Verify(_ context.Context, _ name.Reference, checkOpts *cosign.CheckOpts) ([]oci.Signature, bool, error) {
 	resp := f.Responses[f.CallCount]
 	f.CallCount++
+	f.CheckOpts = checkOpts
 	return resp.Signatures, resp.BundleVerified, resp.Err
 }
 
 type fakeCosignVerifyAttestationsFn struct {
-	Responses []struct {
-		Signatures     []oci.Signature
-		BundleVerified bool
-		Err            error
-	}
+	Responses []fakeResponse
 	CallCount int
 }
 
@@ -681,6 +903,21 @@ func (f *fakeGetCTLogPubsFn) Get(_ context.Context) (*cosign.TrustedTransparency
 	return f.Response.PubKeys, f.Response.Err
 }
 
+type fakeGetTrustedRootFn struct {
+	Response struct {
+		TrustedMaterial root.TrustedMaterial
+		Err             error
+	}
+	Path      string
+	CallCount int
+}
+
+func (f *fakeGetTrustedRootFn) Get(path string) (root.TrustedMaterial, error) {
+	f.CallCount++
+	f.Path = path
+	return f.Response.TrustedMaterial, f.Response.Err
+}
+
 type fakeGetRekorClientFn struct {
 	Response struct {
 		Client *rekorclient.Rekor
@@ -766,6 +1003,7 @@ type verifierSetup struct {
 	fakeGetRekorPubs             *fakeGetRekorPubsFn
 	fakeGetCTLogPubs             *fakeGetCTLogPubsFn
 	fakeGetRekorClient           *fakeGetRekorClientFn
+	fakeGetTrustedRoot           *fakeGetTrustedRootFn
 }
 
 func setupVerifier() verifierSetup {
@@ -781,6 +1019,7 @@ func setupVerifier() verifierSetup {
 	fakeGetRekorPubsFn := &fakeGetRekorPubsFn{}
 	fakeGetCTLogPubsFn := &fakeGetCTLogPubsFn{}
 	fakeGetRekorClientFn := &fakeGetRekorClientFn{}
+	fakeGetTrustedRootFn := &fakeGetTrustedRootFn{}
 
 	verifier := &ImageVerifier{
 		config: config,
@@ -792,6 +1031,7 @@ func setupVerifier() verifierSetup {
 			getFulcioIntermediates:  fakeGetFulcioIntermediatesFn.Get,
 			getRekorPublicKeys:      fakeGetRekorPubsFn.Get,
 			getCTLogPublicKeys:      fakeGetCTLogPubsFn.Get,
+			getTrustedRoot:          fakeGetTrustedRootFn.Get,
 		},
 	}
 
@@ -804,6 +1044,7 @@ func setupVerifier() verifierSetup {
 		fakeGetRekorPubs:             fakeGetRekorPubsFn,
 		fakeGetCTLogPubs:             fakeGetCTLogPubsFn,
 		fakeGetRekorClient:           fakeGetRekorClientFn,
+		fakeGetTrustedRoot:           fakeGetTrustedRootFn,
 	}
 }
 
@@ -858,6 +1099,20 @@ func createFakePayload() []byte {
 	return payloadBytes
 }
 
+func createFakeAttestationPayload(predicateType string) []byte {
+	statement, _ := json.Marshal(map[string]any{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"predicateType": predicateType,
+		"predicate":     map[string]any{},
+	})
+	envelope, _ := json.Marshal(map[string]any{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []any{},
+	})
+	return envelope
+}
+
 func createFakeBundle() *bundle.RekorBundle {
 	signedTimestamp := "test-signed-timestamp"
 	return &bundle.RekorBundle{
@@ -67,6 +67,11 @@ type Config struct {
 	// LogReopener facilitates handling a signal to rotate log file.
 	LogReopener func(context.Context) error
 
+	// ConfigLoader, if set, loads the configuration again when the agent is
+	// signaled to reload it, so the settings that support it can be changed
+	// while the agent is running
+	ConfigLoader ConfigLoader
+
 	// Address of SPIRE server
 	ServerAddress string
 
@@ -86,6 +91,13 @@ type Config struct {
 	// JWTSVIDCacheMaxSize is a soft limit of max number of JWT-SVIDs that would be stored in cache
 	JWTSVIDCacheMaxSize int
 
+	// CacheSnapshotEnabled enables the sealed snapshot of the workload cache,
+	// used to serve workloads right after a restart
+	CacheSnapshotEnabled bool
+
+	// CacheSnapshotInterval controls how often the workload cache snapshot is saved
+	CacheSnapshotInterval time.Duration
+
 	// Trust domain and associated CA bundle
 	TrustDomain spiffeid.TrustDomain
 
@@ -125,6 +137,11 @@ type Config struct {
 
 	// WorkloadAPIRateLimit configures per-selector-set rate limiting for Workload API and SDS methods.
 	WorkloadAPIRateLimit WorkloadAPIRateLimitConfig
+
+	// IsWITSVIDsDisabled reports whether the WIT-SVID profile is disabled on
+	// the Workload API. It is consulted on every request so the profile can
+	// be toggled while the agent is running.
+	IsWITSVIDsDisabled func() bool
 }
 
 func New(c *Config) *Agent {
@@ -23,8 +23,12 @@ type WorkloadAPIRateLimitConfig struct {
 	FetchJWTSVID     int
 	FetchX509Bundles int
 	FetchJWTBundles  int
-	StreamSecrets    int
-	FetchSecrets     int
+	FetchWITSVID     int
+	FetchWITBundles  int
+	// StreamSecrets limits both the SDS StreamSecrets and DeltaSecrets
+	// methods, each of which has its own token bucket.
+	StreamSecrets int
+	FetchSecrets  int
 }
 
 type Config struct {
@@ -61,6 +65,10 @@ type Config struct {
 	// WorkloadAPIRateLimit configures per-selector-set rate limiting for Workload API and SDS methods.
 	WorkloadAPIRateLimit WorkloadAPIRateLimitConfig
 
+	// IsWITSVIDsDisabled reports whether the WIT-SVID profile is disabled on
+	// the Workload API
+	IsWITSVIDsDisabled func() bool
+
 	// Hooks used by the unit tests to assert that the configuration provided
 	// to each handler is correct and return fake handlers.
 	newWorkloadAPIServer func(workload.Config) workload_pb.SpiffeWorkloadAPIServer
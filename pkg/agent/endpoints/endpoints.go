@@ -76,6 +76,7 @@ func New(c Config) *Endpoints {
 		AllowedForeignJWTClaims:       allowedClaims,
 		LogSelectors:                  c.LogSelectors,
 		TrustDomain:                   c.TrustDomain,
+		IsWITSVIDsDisabled:            c.IsWITSVIDsDisabled,
 	})
 
 	sdsv3Server := c.newSDSv3Server(sdsv3.Config{
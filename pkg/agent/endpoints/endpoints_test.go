@@ -172,6 +172,7 @@ func TestEndpoints(t *testing.T) {
 				DisableSPIFFECertValidation: true,
 				AllowedForeignJWTClaims:     tt.allowedClaims,
 				LogSelectors:                []string{"k8s:ns"},
+				IsWITSVIDsDisabled:          func() bool { return true },
 
 				// Assert the provided config and return a fake Workload API server
 				newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
@@ -179,6 +180,8 @@ func TestEndpoints(t *testing.T) {
 					require.True(t, ok, "attestor was not a PeerTrackerAttestor wrapper")
 					assert.Equal(t, FakeManager{}, c.Manager)
 					assert.Equal(t, []string{"k8s:ns"}, c.LogSelectors)
+					require.NotNil(t, c.IsWITSVIDsDisabled)
+					assert.True(t, c.IsWITSVIDsDisabled())
 					if tt.expectClaims != nil {
 						assert.Equal(t, tt.expectClaims, c.AllowedForeignJWTClaims)
 					} else {
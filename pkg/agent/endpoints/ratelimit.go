@@ -107,7 +107,12 @@ func NewWorkloadRateLimiter(cfg WorkloadAPIRateLimitConfig, log logrus.FieldLogg
 		{workload.MethodFetchJWTSVID, cfg.FetchJWTSVID},
 		{workload.MethodFetchX509Bundles, cfg.FetchX509Bundles},
 		{workload.MethodFetchJWTBundles, cfg.FetchJWTBundles},
+		{workload.MethodFetchWITSVID, cfg.FetchWITSVID},
+		{workload.MethodFetchWITBundles, cfg.FetchWITBundles},
+		// DeltaSecrets shares the StreamSecrets limit, since both open SDS
+		// streams, but gets a bucket of its own like every other method.
 		{sdsv3.MethodStreamSecrets, cfg.StreamSecrets},
+		{sdsv3.MethodDeltaSecrets, cfg.StreamSecrets},
 		{sdsv3.MethodFetchSecrets, cfg.FetchSecrets},
 	}
 
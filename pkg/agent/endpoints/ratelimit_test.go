@@ -247,7 +247,7 @@ func TestNewWorkloadRateLimiterBothMethods(t *testing.T) {
 	assert.Contains(t, rl.limiters, workload.MethodFetchJWTSVID)
 }
 
-func TestNewWorkloadRateLimiterAllSixMethods(t *testing.T) {
+func TestNewWorkloadRateLimiterAllMethods(t *testing.T) {
 	log, _ := test.NewNullLogger()
 	metrics := telemetry.Blackhole{}
 	cfg := WorkloadAPIRateLimitConfig{
@@ -255,20 +255,47 @@ func TestNewWorkloadRateLimiterAllSixMethods(t *testing.T) {
 		FetchJWTSVID:     2,
 		FetchX509Bundles: 3,
 		FetchJWTBundles:  4,
+		FetchWITSVID:     7,
+		FetchWITBundles:  8,
 		StreamSecrets:    5,
 		FetchSecrets:     6,
 	}
 	rl := NewWorkloadRateLimiter(cfg, log, metrics)
 	require.NotNil(t, rl)
-	assert.Len(t, rl.limiters, 6)
+	assert.Len(t, rl.limiters, 9)
 	assert.Contains(t, rl.limiters, workload.MethodFetchX509SVID)
 	assert.Contains(t, rl.limiters, workload.MethodFetchJWTSVID)
 	assert.Contains(t, rl.limiters, workload.MethodFetchX509Bundles)
 	assert.Contains(t, rl.limiters, workload.MethodFetchJWTBundles)
+	assert.Contains(t, rl.limiters, workload.MethodFetchWITSVID)
+	assert.Contains(t, rl.limiters, workload.MethodFetchWITBundles)
 	assert.Contains(t, rl.limiters, sdsv3.MethodStreamSecrets)
+	assert.Contains(t, rl.limiters, sdsv3.MethodDeltaSecrets)
 	assert.Contains(t, rl.limiters, sdsv3.MethodFetchSecrets)
 }
 
+// TestWorkloadRateLimiterDeltaSecretsBucket verifies that DeltaSecrets uses
+// the StreamSecrets limit with a bucket of its own.
+func TestWorkloadRateLimiterDeltaSecretsBucket(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	metrics := telemetry.Blackhole{}
+	cfg := WorkloadAPIRateLimitConfig{
+		StreamSecrets: 1,
+	}
+	rl := NewWorkloadRateLimiter(cfg, log, metrics)
+	require.NotNil(t, rl)
+
+	sel := selectors("k8s", "pod:a")
+
+	// Exhaust the StreamSecrets bucket.
+	require.NoError(t, rl.RateLimit(sdsv3.MethodStreamSecrets, sel))
+	require.Error(t, rl.RateLimit(sdsv3.MethodStreamSecrets, sel))
+
+	// DeltaSecrets is still allowed once, then limited.
+	require.NoError(t, rl.RateLimit(sdsv3.MethodDeltaSecrets, sel))
+	require.Error(t, rl.RateLimit(sdsv3.MethodDeltaSecrets, sel))
+}
+
 // TestWorkloadRateLimiterSelectorSetIndependence verifies that two callers
 // with different selector sets each have independent token buckets.
 func TestWorkloadRateLimiterSelectorSetIndependence(t *testing.T) {
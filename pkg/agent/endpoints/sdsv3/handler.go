@@ -3,6 +3,7 @@ package sdsv3
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -35,6 +36,7 @@ const (
 
 	MethodStreamSecrets = "/envoy.service.secret.v3.SecretDiscoveryService/StreamSecrets"
 	MethodFetchSecrets  = "/envoy.service.secret.v3.SecretDiscoveryService/FetchSecrets"
+	MethodDeltaSecrets  = "/envoy.service.secret.v3.SecretDiscoveryService/DeltaSecrets"
 )
 
 // RateLimiter enforces per-selector-set rate limiting on SDS methods.
@@ -253,8 +255,295 @@ func subListChanged(oldSubs []string, newSubs []string) (b bool) {
 	return false
 }
 
-func (h *Handler) DeltaSecrets(secret_v3.SecretDiscoveryService_DeltaSecretsServer) error {
-	return status.Error(codes.Unimplemented, "Method is not implemented")
+func (h *Handler) DeltaSecrets(stream secret_v3.SecretDiscoveryService_DeltaSecretsServer) error {
+	log := rpccontext.Logger(stream.Context())
+
+	selectors, err := h.c.Attestor.Attest(stream.Context())
+	if err != nil {
+		log.WithError(err).Error("Failed to attest the workload")
+		return workloadAttestationFailedError(stream.Context())
+	}
+
+	if err := h.rateLimit(stream.Context(), MethodDeltaSecrets, selectors); err != nil {
+		return err
+	}
+
+	sub, err := h.c.Manager.SubscribeToCacheChanges(stream.Context(), selectors)
+	if err != nil {
+		log.WithError(err).Error("Subscribe to cache changes failed")
+		return err
+	}
+	defer sub.Finish()
+
+	updch := sub.Updates()
+	reqch := make(chan *discovery_v3.DeltaDiscoveryRequest, 1)
+	errch := make(chan error, 1)
+
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if status.Code(err) == codes.Canceled || errors.Is(err, io.EOF) {
+					err = nil
+				}
+				errch <- err
+				return
+			}
+			reqch <- req
+		}
+	}()
+
+	var versionCounter int64
+	versionInfo := strconv.FormatInt(versionCounter, 10)
+	var lastNonce string
+	var upd *cache.WorkloadUpdate
+	state := newDeltaState()
+	for {
+		select {
+		case newReq := <-reqch:
+			log.WithFields(logrus.Fields{
+				telemetry.ResourceNames:             newReq.ResourceNamesSubscribe,
+				telemetry.UnsubscribedResourceNames: newReq.ResourceNamesUnsubscribe,
+				telemetry.Nonce:                     newReq.ResponseNonce,
+			}).Debug("Received DeltaSecrets request")
+			h.triggerReceivedHook()
+
+			// The nonce only identifies the response being ACKed or NACKed.
+			// Unlike StreamSecrets, delta requests carry subscription changes
+			// that apply regardless of the nonce, so a stale nonce only means
+			// the request does not acknowledge the last response.
+			staleNonce := lastNonce != "" && newReq.ResponseNonce != "" && lastNonce != newReq.ResponseNonce
+			switch {
+			case newReq.ErrorDetail != nil:
+				log.WithFields(logrus.Fields{
+					telemetry.Nonce: newReq.ResponseNonce,
+					telemetry.Error: newReq.ErrorDetail.Message,
+				}).Error("Envoy reported errors applying secrets")
+			case staleNonce:
+				log.WithFields(logrus.Fields{
+					telemetry.Nonce:  newReq.ResponseNonce,
+					telemetry.Expect: lastNonce,
+				}).Debug("Received request with stale nonce")
+			}
+
+			// We need to send updates if the subscriptions have grown, either
+			// explicitly, or implicitly because this is the first request.
+			if !state.applyRequest(newReq) {
+				continue
+			}
+
+			if upd == nil {
+				// Workload update has not been received yet, defer sending updates until then
+				continue
+			}
+
+		case upd = <-updch:
+			versionCounter++
+			versionInfo = strconv.FormatInt(versionCounter, 10)
+			if !state.initialized {
+				// Nothing has been requested yet.
+				continue
+			}
+		case err := <-errch:
+			if err != nil {
+				log.WithError(err).Error("Received error from delta secrets server")
+			}
+			return err
+		}
+
+		resp, err := h.buildDeltaResponse(versionInfo, state, upd)
+		if err != nil {
+			log.WithError(err).Error("Error building delta secrets response")
+			return err
+		}
+		if resp == nil {
+			// Nothing changed for any of the subscribed resources
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			telemetry.VersionInfo:      resp.SystemVersionInfo,
+			telemetry.Nonce:            resp.Nonce,
+			telemetry.Count:            len(resp.Resources),
+			telemetry.RemovedResources: len(resp.RemovedResources),
+		}).Debug("Sending DeltaSecrets response")
+		if err := stream.Send(resp); err != nil {
+			log.WithError(err).Error("Error sending secrets over stream")
+			return err
+		}
+
+		// remember the last nonce
+		lastNonce = resp.Nonce
+	}
+}
+
+// deltaState tracks the resources a DeltaSecrets stream is subscribed to and
+// the version of each resource last sent to (or already held by) the client.
+type deltaState struct {
+	initialized bool
+	wildcard    bool
+	typeURL     string
+	node        *core_v3.Node
+
+	subscribed map[string]bool
+	versions   map[string]string
+
+	// absent holds the subscribed resources already reported as removed
+	// because they are not available to the workload.
+	absent map[string]bool
+}
+
+func newDeltaState() *deltaState {
+	return &deltaState{
+		subscribed: make(map[string]bool),
+		versions:   make(map[string]string),
+		absent:     make(map[string]bool),
+	}
+}
+
+// applyRequest updates the subscriptions with the given request. It returns
+// true if the subscriptions have grown and a response should be sent.
+func (s *deltaState) applyRequest(req *discovery_v3.DeltaDiscoveryRequest) bool {
+	grown := false
+	if !s.initialized {
+		s.initialized = true
+		s.typeURL = req.TypeUrl
+		// A first request without any resource names is a legacy wildcard
+		// subscription.
+		s.wildcard = len(req.ResourceNamesSubscribe) == 0
+		// The client is reconnecting and already has these resources, so
+		// they only need to be sent again if they have changed.
+		maps.Copy(s.versions, req.InitialResourceVersions)
+		grown = true
+	}
+	if req.Node != nil {
+		s.node = req.Node
+	}
+
+	for _, name := range req.ResourceNamesSubscribe {
+		switch {
+		case name == "*":
+			if !s.wildcard {
+				s.wildcard = true
+				grown = true
+			}
+		case name != "" && !s.subscribed[name]:
+			s.subscribed[name] = true
+			grown = true
+		}
+	}
+	for _, name := range req.ResourceNamesUnsubscribe {
+		if name == "*" {
+			s.wildcard = false
+			maps.DeleteFunc(s.versions, func(name string, _ string) bool {
+				return !s.subscribed[name]
+			})
+			continue
+		}
+		delete(s.subscribed, name)
+		delete(s.absent, name)
+		if !s.wildcard {
+			delete(s.versions, name)
+		}
+	}
+	return grown
+}
+
+// buildDeltaResponse builds a response containing the subscribed resources
+// that have changed since they were last sent, and the subscribed resources
+// that are not available anymore. It returns nil if there is nothing to send.
+func (h *Handler) buildDeltaResponse(versionInfo string, state *deltaState, upd *cache.WorkloadUpdate) (*discovery_v3.DeltaDiscoveryResponse, error) {
+	resources := make(map[string]*anypb.Any)
+	addResources := func(names []string) error {
+		built, _, err := h.buildResources(&discovery_v3.DiscoveryRequest{
+			Node:          state.node,
+			TypeUrl:       state.typeURL,
+			ResourceNames: names,
+		}, upd)
+		if err != nil {
+			return err
+		}
+		for _, resource := range built {
+			secret := new(tls_v3.Secret)
+			if err := resource.UnmarshalTo(secret); err != nil {
+				return err
+			}
+			resources[secret.Name] = resource
+		}
+		return nil
+	}
+
+	if state.wildcard {
+		if err := addResources(nil); err != nil {
+			return nil, err
+		}
+	}
+	// Explicitly subscribed resources are built one at a time, since some of
+	// the default resource names alias each other.
+	for _, name := range sortedKeys(state.subscribed) {
+		if _, ok := resources[name]; ok {
+			continue
+		}
+		if err := addResources([]string{name}); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &discovery_v3.DeltaDiscoveryResponse{
+		TypeUrl:           state.typeURL,
+		SystemVersionInfo: versionInfo,
+	}
+	for _, name := range sortedKeys(resources) {
+		delete(state.absent, name)
+		version := resourceVersion(resources[name])
+		if state.versions[name] == version {
+			continue
+		}
+		state.versions[name] = version
+		resp.Resources = append(resp.Resources, &discovery_v3.Resource{
+			Name:     name,
+			Version:  version,
+			Resource: resources[name],
+		})
+	}
+	// Resources held by the client that are no longer available are removed.
+	// Subscribed resources that are not available are reported as removed
+	// once, so the client does not wait for them.
+	removed := make(map[string]bool)
+	for name := range state.versions {
+		if _, ok := resources[name]; !ok {
+			delete(state.versions, name)
+			removed[name] = true
+		}
+	}
+	for name := range state.subscribed {
+		if _, ok := resources[name]; !ok && !state.absent[name] {
+			removed[name] = true
+		}
+	}
+	for _, name := range sortedNames(removed) {
+		if state.subscribed[name] {
+			state.absent[name] = true
+		}
+		resp.RemovedResources = append(resp.RemovedResources, name)
+	}
+
+	if len(resp.Resources) == 0 && len(resp.RemovedResources) == 0 {
+		return nil, nil
+	}
+
+	var err error
+	if resp.Nonce, err = nextNonce(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// resourceVersion returns a version for the resource derived from its content,
+// so that unchanged resources are not sent again.
+func resourceVersion(resource *anypb.Any) string {
+	sum := sha256.Sum256(resource.Value)
+	return hex.EncodeToString(sum[:8])
 }
 
 func workloadAttestationFailedError(ctx context.Context) error {
@@ -305,6 +594,22 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		}
 	}
 
+	var missing map[string]bool
+	resp.Resources, missing, err = h.buildResources(req, upd)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "workload is not authorized for the requested identities %q", sortedNames(missing))
+	}
+
+	return resp, nil
+}
+
+// buildResources builds the resources requested by name, or all of the
+// resources available to the workload when no names are requested. The
+// requested names that are not available are returned separately.
+func (h *Handler) buildResources(req *discovery_v3.DiscoveryRequest, upd *cache.WorkloadUpdate) (resources []*anypb.Any, missing map[string]bool, err error) {
 	// build a convenient set of names for lookups
 	names := make(map[string]bool)
 	for _, name := range req.ResourceNames {
@@ -316,7 +621,7 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 
 	builder, err := h.getValidationContextBuilder(req, upd)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// TODO: verify the type url
@@ -325,29 +630,29 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		case returnAllEntries || names[upd.Bundle.TrustDomain().IDString()]:
 			validationContext, err := builder.buildOne(upd.Bundle.TrustDomain().IDString(), upd.Bundle.TrustDomain().IDString())
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			delete(names, upd.Bundle.TrustDomain().IDString())
-			resp.Resources = append(resp.Resources, validationContext)
+			resources = append(resources, validationContext)
 
 		case names[h.c.DefaultBundleName]:
 			validationContext, err := builder.buildOne(h.c.DefaultBundleName, upd.Bundle.TrustDomain().IDString())
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			delete(names, h.c.DefaultBundleName)
-			resp.Resources = append(resp.Resources, validationContext)
+			resources = append(resources, validationContext)
 
 		case names[h.c.DefaultAllBundlesName]:
 			validationContext, err := builder.buildAll(h.c.DefaultAllBundlesName)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			delete(names, h.c.DefaultAllBundlesName)
-			resp.Resources = append(resp.Resources, validationContext)
+			resources = append(resources, validationContext)
 		}
 	}
 
@@ -355,10 +660,10 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		if returnAllEntries || names[federatedBundle.TrustDomain().IDString()] {
 			validationContext, err := builder.buildOne(td.IDString(), td.IDString())
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			delete(names, federatedBundle.TrustDomain().IDString())
-			resp.Resources = append(resp.Resources, validationContext)
+			resources = append(resources, validationContext)
 		}
 	}
 
@@ -367,25 +672,21 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		case returnAllEntries || names[identity.Entry.SpiffeId]:
 			tlsCertificate, err := buildTLSCertificate(identity, "")
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			delete(names, identity.Entry.SpiffeId)
-			resp.Resources = append(resp.Resources, tlsCertificate)
+			resources = append(resources, tlsCertificate)
 		case i == 0 && names[h.c.DefaultSVIDName]:
 			tlsCertificate, err := buildTLSCertificate(identity, h.c.DefaultSVIDName)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			delete(names, h.c.DefaultSVIDName)
-			resp.Resources = append(resp.Resources, tlsCertificate)
+			resources = append(resources, tlsCertificate)
 		}
 	}
 
-	if len(names) > 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "workload is not authorized for the requested identities %q", sortedNames(names))
-	}
-
-	return resp, nil
+	return resources, names, nil
 }
 
 func (h *Handler) triggerReceivedHook() {
@@ -637,3 +938,12 @@ func sortedNames(names map[string]bool) []string {
 	sort.Strings(out)
 	return out
 }
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for key := range m {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -868,6 +868,348 @@ func TestStreamSecretsErrInSubscribeToCacheChanges(t *testing.T) {
 	require.Nil(t, resp)
 }
 
+func TestDeltaSecretsStreaming(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload", "spiffe://domain.test"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.SystemVersionInfo)
+	require.NotEmpty(t, resp.Nonce)
+	require.Empty(t, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, tdValidationContext, workloadTLSCertificate1)
+
+	// Only the changed TLS certificate is sent
+	test.setWorkloadUpdate(workloadCert2)
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+}
+
+func TestDeltaSecretsUnchangedResourcesNotSent(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate1)
+
+	// An update that does not change the subscribed resources is not sent
+	test.setWorkloadUpdate(workloadCert1)
+
+	// Subscribing to another resource only sends the new resource
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResponseNonce:          resp.Nonce,
+		ResourceNamesSubscribe: []string{"spiffe://domain.test"},
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, tdValidationContext)
+}
+
+func TestDeltaSecretsUnsubscribe(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload", "spiffe://otherdomain.test"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate1, fedValidationContext)
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResponseNonce:            resp.Nonce,
+		ResourceNamesUnsubscribe: []string{"spiffe://otherdomain.test"},
+	})
+
+	// The federated bundle going away is not reported since it is no longer
+	// subscribed to.
+	test.manager.SetWorkloadUpdate(&cache.WorkloadUpdate{
+		Identities: []cache.Identity{
+			{
+				Entry: &common.RegistrationEntry{
+					SpiffeId: "spiffe://domain.test/workload",
+				},
+				SVID:       []*x509.Certificate{workloadCert2},
+				PrivateKey: workloadKey,
+			},
+		},
+		Bundle: tdBundle,
+	})
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+}
+
+func TestDeltaSecretsWildcard(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, tdValidationContext, workloadTLSCertificate1, fedValidationContext)
+
+	// Resources that are no longer available are removed
+	test.manager.SetWorkloadUpdate(&cache.WorkloadUpdate{
+		Identities: []cache.Identity{
+			{
+				Entry: &common.RegistrationEntry{
+					SpiffeId: "spiffe://domain.test/workload",
+				},
+				SVID:       []*x509.Certificate{workloadCert1},
+				PrivateKey: workloadKey,
+			},
+		},
+		Bundle: tdBundle,
+	})
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.Resources)
+	require.Equal(t, []string{"spiffe://otherdomain.test"}, resp.RemovedResources)
+}
+
+func TestDeltaSecretsInitialResourceVersions(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate1)
+	require.Len(t, resp.Resources, 1)
+	workloadVersion := resp.Resources[0].Version
+	require.NotEmpty(t, workloadVersion)
+	require.NoError(t, stream.CloseSend())
+
+	// Reconnect, letting the handler know which version is already held so
+	// that only the new resource is sent.
+	stream, err = test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload", "spiffe://domain.test"},
+		InitialResourceVersions: map[string]string{
+			"spiffe://domain.test/workload": workloadVersion,
+		},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, tdValidationContext)
+}
+
+func TestDeltaSecretsStaleNonce(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate1)
+
+	// The nonce doesn't match the last response, but the subscription
+	// changes still apply.
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResponseNonce:          "FOO",
+		ResourceNamesSubscribe: []string{"spiffe://otherdomain.test"},
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, fedValidationContext)
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResponseNonce:            "FOO",
+		ResourceNamesSubscribe:   []string{"spiffe://domain.test"},
+		ResourceNamesUnsubscribe: []string{"spiffe://otherdomain.test"},
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, tdValidationContext)
+
+	// The federated bundle going away is not reported since it was
+	// unsubscribed by the stale request.
+	test.manager.SetWorkloadUpdate(&cache.WorkloadUpdate{
+		Identities: []cache.Identity{
+			{
+				Entry: &common.RegistrationEntry{
+					SpiffeId: "spiffe://domain.test/workload",
+				},
+				SVID:       []*x509.Certificate{workloadCert2},
+				PrivateKey: workloadKey,
+			},
+		},
+		Bundle: tdBundle,
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+}
+
+func TestDeltaSecretsUnauthorizedResource(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	// Resources the workload is not authorized for are reported as removed
+	// instead of failing the stream.
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/other", "spiffe://domain.test/workload"},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, []string{"spiffe://domain.test/other"}, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate1)
+
+	// The unavailable resource is not reported again on updates
+	test.setWorkloadUpdate(workloadCert2)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+}
+
+func TestDeltaSecretsSubscribedResourceRemoved(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload", "spiffe://otherdomain.test"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate1, fedValidationContext)
+
+	// The subscribed federated bundle goes away. It is removed and the
+	// stream stays open.
+	test.manager.SetWorkloadUpdate(&cache.WorkloadUpdate{
+		Identities: []cache.Identity{
+			{
+				Entry: &common.RegistrationEntry{
+					SpiffeId: "spiffe://domain.test/workload",
+				},
+				SVID:       []*x509.Certificate{workloadCert1},
+				PrivateKey: workloadKey,
+			},
+		},
+		Bundle: tdBundle,
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.Resources)
+	require.Equal(t, []string{"spiffe://otherdomain.test"}, resp.RemovedResources)
+
+	// The federated bundle comes back
+	test.setWorkloadUpdate(workloadCert1)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.RemovedResources)
+	requireDeltaSecrets(t, resp, fedValidationContext)
+}
+
+func TestDeltaSecretsErrInSubscribeToCacheChanges(t *testing.T) {
+	test := setupErrTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	resp, err := stream.Recv()
+	require.Error(t, err)
+	require.Nil(t, resp)
+}
+
 func TestFetchSecrets(t *testing.T) {
 	for _, tt := range []struct {
 		name          string
@@ -1423,6 +1765,17 @@ func (h *handlerTest) sendAndWait(stream secret_v3.SecretDiscoveryService_Stream
 	}
 }
 
+func (h *handlerTest) sendDeltaAndWait(stream secret_v3.SecretDiscoveryService_DeltaSecretsClient, req *discovery_v3.DeltaDiscoveryRequest) {
+	require.NoError(h.t, stream.Send(req))
+	timer := time.NewTimer(time.Second)
+	defer timer.Stop()
+	select {
+	case <-h.received:
+	case <-timer.C:
+		assert.Fail(h.t, "timed out waiting for request to be received")
+	}
+}
+
 type FakeAttestor []*common.Selector
 
 func (a FakeAttestor) Attest(context.Context) ([]*common.Selector, error) {
@@ -1553,3 +1906,16 @@ func requireSecrets(t *testing.T, resp *discovery_v3.DiscoveryResponse, expected
 
 	spiretest.RequireProtoListEqual(t, expectedSecrets, actualSecrets)
 }
+
+func requireDeltaSecrets(t *testing.T, resp *discovery_v3.DeltaDiscoveryResponse, expectedSecrets ...*tls_v3.Secret) {
+	var actualSecrets []*tls_v3.Secret
+	for _, resource := range resp.Resources {
+		secret := new(tls_v3.Secret)
+		require.NoError(t, resource.Resource.UnmarshalTo(secret))
+		require.Equal(t, secret.Name, resource.Name)
+		require.NotEmpty(t, resource.Version)
+		actualSecrets = append(actualSecrets, secret)
+	}
+
+	spiretest.RequireProtoListEqual(t, expectedSecrets, actualSecrets)
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
@@ -35,6 +36,7 @@ type Manager interface {
 	SubscribeToCacheChanges(ctx context.Context, key cache.Selectors) (cache.Subscriber, error)
 	MatchingRegistrationEntries(selectors []*common.Selector) []*common.RegistrationEntry
 	FetchJWTSVID(ctx context.Context, entry *common.RegistrationEntry, audience []string) (*client.JWTSVID, error)
+	FetchWITSVID(ctx context.Context, entry *common.RegistrationEntry) (*cache.WITSVID, error)
 	FetchWorkloadUpdate([]*common.Selector) *cache.WorkloadUpdate
 }
 
@@ -52,8 +54,15 @@ const (
 	MethodFetchJWTSVID     = "/SpiffeWorkloadAPI/FetchJWTSVID"
 	MethodFetchX509Bundles = "/SpiffeWorkloadAPI/FetchX509Bundles"
 	MethodFetchJWTBundles  = "/SpiffeWorkloadAPI/FetchJWTBundles"
+	MethodFetchWITSVID     = "/SpiffeWorkloadAPI/FetchWITSVID"
+	MethodFetchWITBundles  = "/SpiffeWorkloadAPI/FetchWITBundles"
 )
 
+// witSVIDMinRefreshInterval is the minimum amount of time a FetchWITSVID
+// stream waits before checking again if any of the WIT-SVIDs it serves have
+// been rotated.
+const witSVIDMinRefreshInterval = 10 * time.Second
+
 type Config struct {
 	Manager                       Manager
 	Attestor                      Attestor
@@ -62,6 +71,7 @@ type Config struct {
 	AllowedForeignJWTClaims       map[string]struct{}
 	LogSelectors                  []string
 	TrustDomain                   spiffeid.TrustDomain
+	IsWITSVIDsDisabled            func() bool
 }
 
 // Handler implements the Workload API interface
@@ -320,12 +330,101 @@ func (h *Handler) FetchX509Bundles(_ *workload.X509BundlesRequest, stream worklo
 	}
 }
 
-func (h *Handler) FetchWITSVID(_ *workload.WITSVIDRequest, stream workload.SpiffeWorkloadAPI_FetchWITSVIDServer) error {
-	return status.Error(codes.Unimplemented, "fetching WIT-SVIDs is not implemented")
+// FetchWITSVID processes request for WIT-SVIDs. An updated response is sent
+// when the identities of the workload change or when any of the WIT-SVIDs
+// gets rotated.
+func (h *Handler) FetchWITSVID(req *workload.WITSVIDRequest, stream workload.SpiffeWorkloadAPI_FetchWITSVIDServer) error {
+	ctx := stream.Context()
+	start := time.Now()
+	log := rpccontext.Logger(ctx)
+
+	if h.witSVIDsDisabled() {
+		return status.Error(codes.Unimplemented, "WIT functionality is disabled")
+	}
+
+	if req.SpiffeId != "" {
+		if _, err := spiffeid.FromString(req.SpiffeId); err != nil {
+			log.WithField(telemetry.SPIFFEID, req.SpiffeId).WithError(err).Error("Invalid requested SPIFFE ID")
+			return status.Errorf(codes.InvalidArgument, "invalid requested SPIFFE ID: %v", err)
+		}
+	}
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		loggerWithContextInfo(ctx, log, start, err).Error("Workload attestation failed")
+		return workloadAttestationFailedError(ctx)
+	}
+
+	if err := h.rateLimit(ctx, MethodFetchWITSVID, selectors); err != nil {
+		return err
+	}
+
+	subscriber, err := h.c.Manager.SubscribeToCacheChanges(ctx, selectors)
+	if err != nil {
+		loggerWithContextInfo(ctx, log, start, err).Error("Subscribe to cache changes failed")
+		return err
+	}
+	defer subscriber.Finish()
+
+	var update *cache.WorkloadUpdate
+	var previousResp *workload.WITSVIDResponse
+	var refreshCh <-chan time.Time
+	for {
+		select {
+		case update = <-subscriber.Updates():
+			update.Identities = filterIdentities(update.Identities, log)
+		case <-refreshCh:
+		case <-ctx.Done():
+			return nil
+		}
+
+		var refreshAt time.Time
+		previousResp, refreshAt, err = h.sendWITSVIDResponse(update, stream, req.SpiffeId, selectors, log, previousResp, start)
+		if err != nil {
+			return err
+		}
+		refreshCh = time.After(time.Until(refreshAt))
+	}
 }
 
+// FetchWITBundles processes request for WIT bundles
 func (h *Handler) FetchWITBundles(_ *workload.WITBundlesRequest, stream workload.SpiffeWorkloadAPI_FetchWITBundlesServer) error {
-	return status.Error(codes.Unimplemented, "fetching WIT bundles is not implemented")
+	ctx := stream.Context()
+	start := time.Now()
+	log := rpccontext.Logger(ctx)
+
+	if h.witSVIDsDisabled() {
+		return status.Error(codes.Unimplemented, "WIT functionality is disabled")
+	}
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		loggerWithContextInfo(ctx, log, start, err).Error("Workload attestation failed")
+		return workloadAttestationFailedError(ctx)
+	}
+
+	if err := h.rateLimit(ctx, MethodFetchWITBundles, selectors); err != nil {
+		return err
+	}
+
+	subscriber, err := h.c.Manager.SubscribeToCacheChanges(ctx, selectors)
+	if err != nil {
+		loggerWithContextInfo(ctx, log, start, err).Error("Subscribe to cache changes failed")
+		return err
+	}
+	defer subscriber.Finish()
+
+	var previousResp *workload.WITBundlesResponse
+	for {
+		select {
+		case update := <-subscriber.Updates():
+			if previousResp, err = h.sendWITBundlesResponse(update, stream, selectors, log, previousResp, start); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
 }
 
 func workloadAttestationFailedError(ctx context.Context) error {
@@ -538,6 +637,155 @@ func composeJWTBundlesResponse(update *cache.WorkloadUpdate) (*workload.JWTBundl
 	}, nil
 }
 
+func (h *Handler) sendWITSVIDResponse(update *cache.WorkloadUpdate, stream workload.SpiffeWorkloadAPI_FetchWITSVIDServer, spiffeID string, selectors []*common.Selector, log logrus.FieldLogger, previousResponse *workload.WITSVIDResponse, start time.Time) (*workload.WITSVIDResponse, time.Time, error) {
+	ctx := stream.Context()
+	// The agent health check currently exercises the Workload API.
+	// Only log if it is not the agent itself.
+	quietLogging := isAgent(ctx)
+
+	log = log.WithField(telemetry.Registered, true)
+
+	resp := new(workload.WITSVIDResponse)
+	var svids []*cache.WITSVID
+	for _, identity := range update.Identities {
+		if spiffeID != "" && identity.Entry.SpiffeId != spiffeID {
+			continue
+		}
+		loopLog := log.WithField(telemetry.SPIFFEID, identity.Entry.SpiffeId)
+
+		svid, err := h.c.Manager.FetchWITSVID(ctx, identity.Entry)
+		if err != nil {
+			loggerWithContextInfo(ctx, loopLog, start, err).Error("Could not fetch WIT-SVID")
+			return nil, time.Time{}, status.Errorf(codes.Unavailable, "could not fetch WIT-SVID: %v", err)
+		}
+
+		witSVID, err := composeWITSVID(identity.Entry, svid)
+		if err != nil {
+			loopLog.WithError(err).Error("Could not serialize WIT-SVID response")
+			return nil, time.Time{}, status.Errorf(codes.Unavailable, "could not serialize response: %v", err)
+		}
+
+		resp.Svids = append(resp.Svids, witSVID)
+		svids = append(svids, svid)
+	}
+
+	if len(resp.Svids) == 0 {
+		if !quietLogging {
+			h.logNoIdentityIssued(ctx, log, selectors, start)
+		}
+		return nil, time.Time{}, status.Error(codes.PermissionDenied, "no identity issued")
+	}
+
+	refreshAt := witSVIDRefreshTime(svids)
+
+	if proto.Equal(resp, previousResponse) {
+		return previousResponse, refreshAt, nil
+	}
+
+	if err := stream.Send(resp); err != nil {
+		loggerWithContextInfo(ctx, log, start, err).Error("Failed to send WIT-SVID response")
+		return nil, time.Time{}, err
+	}
+
+	if !quietLogging {
+		for i, svid := range resp.Svids {
+			ttl := time.Until(svids[i].SVID.ExpiresAt)
+			log.WithFields(logrus.Fields{
+				telemetry.SPIFFEID: svid.SpiffeId,
+				telemetry.TTL:      ttl.Seconds(),
+			}).Debug("Fetched WIT-SVID")
+		}
+	}
+
+	return resp, refreshAt, nil
+}
+
+func composeWITSVID(entry *common.RegistrationEntry, svid *cache.WITSVID) (*workload.WITSVID, error) {
+	keyData, err := jose.JSONWebKey{Key: svid.PrivateKey}.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshal key for %v: %w", entry.SpiffeId, err)
+	}
+
+	return &workload.WITSVID{
+		SpiffeId:   entry.SpiffeId,
+		WitSvid:    svid.SVID.Token,
+		WitSvidKey: string(keyData),
+		Hint:       entry.Hint,
+	}, nil
+}
+
+// witSVIDRefreshTime returns the time at which the given WIT-SVIDs should be
+// fetched again from the manager to pick up any rotation. The manager starts
+// rotating WIT-SVIDs around their half-life.
+func witSVIDRefreshTime(svids []*cache.WITSVID) time.Time {
+	refreshAt := time.Now().Add(witSVIDMinRefreshInterval)
+	var earliest time.Time
+	for _, svid := range svids {
+		halfLife := svid.SVID.IssuedAt.Add(svid.SVID.ExpiresAt.Sub(svid.SVID.IssuedAt) / 2)
+		if earliest.IsZero() || halfLife.Before(earliest) {
+			earliest = halfLife
+		}
+	}
+	if earliest.After(refreshAt) {
+		return earliest
+	}
+	return refreshAt
+}
+
+func (h *Handler) sendWITBundlesResponse(update *cache.WorkloadUpdate, stream workload.SpiffeWorkloadAPI_FetchWITBundlesServer, selectors []*common.Selector, log logrus.FieldLogger, previousResponse *workload.WITBundlesResponse, start time.Time) (*workload.WITBundlesResponse, error) {
+	ctx := stream.Context()
+	if !h.c.AllowUnauthenticatedVerifiers && !update.HasIdentity() {
+		h.logNoIdentityIssued(ctx, log, selectors, start)
+		return nil, status.Error(codes.PermissionDenied, "no identity issued")
+	}
+
+	resp, err := composeWITBundlesResponse(update)
+	if err != nil {
+		log.WithError(err).Error("Could not serialize WIT bundle response")
+		return nil, status.Errorf(codes.Unavailable, "could not serialize response: %v", err)
+	}
+
+	if proto.Equal(resp, previousResponse) {
+		return previousResponse, nil
+	}
+
+	if err := stream.Send(resp); err != nil {
+		loggerWithContextInfo(ctx, log, start, err).Error("Failed to send WIT bundle response")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func composeWITBundlesResponse(update *cache.WorkloadUpdate) (*workload.WITBundlesResponse, error) {
+	if update.Bundle == nil {
+		// This should be purely defensive since the cache should always supply
+		// a bundle.
+		return nil, errors.New("bundle not available")
+	}
+
+	bundles := make(map[string]string)
+	jwksBytes, err := update.Bundle.WITBundle().Marshal()
+	if err != nil {
+		return nil, err
+	}
+	bundles[update.Bundle.TrustDomain().IDString()] = string(jwksBytes)
+
+	if update.HasIdentity() {
+		for _, federatedBundle := range update.FederatedBundles {
+			jwksBytes, err := federatedBundle.WITBundle().Marshal()
+			if err != nil {
+				return nil, err
+			}
+			bundles[federatedBundle.TrustDomain().IDString()] = string(jwksBytes)
+		}
+	}
+
+	return &workload.WITBundlesResponse{
+		Bundles: bundles,
+	}, nil
+}
+
 // isAgent returns true if the caller PID from the provided context is the
 // agent's process ID.
 func isAgent(ctx context.Context) bool {
@@ -635,6 +883,10 @@ func (h *Handler) getWorkloadBundles(selectors []*common.Selector) (bundles []*s
 	return bundles
 }
 
+func (h *Handler) witSVIDsDisabled() bool {
+	return h.c.IsWITSVIDsDisabled != nil && h.c.IsWITSVIDsDisabled()
+}
+
 func marshalBundle(certs []*x509.Certificate) []byte {
 	bundle := []byte{}
 	for _, c := range certs {
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
@@ -30,6 +31,7 @@ import (
 	"github.com/spiffe/spire/test/grpctest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testca"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -1509,6 +1511,340 @@ func TestFetchJWTBundles_SpuriousUpdates(t *testing.T) {
 		})
 }
 
+func TestFetchWITSVID(t *testing.T) {
+	ca := testca.New(t, td)
+
+	x509SVID1 := ca.CreateX509SVID(spiffeid.RequireFromPath(td, "/one"))
+	x509SVID1.Hint = "internal"
+	x509SVID2 := ca.CreateX509SVID(spiffeid.RequireFromPath(td, "/two"))
+
+	identities := []cache.Identity{
+		identityFromX509SVID(x509SVID1, "id1"),
+		identityFromX509SVID(x509SVID2, "id2"),
+	}
+
+	now := time.Now()
+	witSVID1 := &cache.WITSVID{
+		SVID:       &client.WITSVID{Token: "token-1", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+		PrivateKey: testkey.NewEC256(t),
+	}
+	witSVID2 := &cache.WITSVID{
+		SVID:       &client.WITSVID{Token: "token-2", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+		PrivateKey: testkey.NewEC256(t),
+	}
+	witSVIDs := map[string]*cache.WITSVID{
+		x509SVID1.ID.String(): witSVID1,
+		x509SVID2.ID.String(): witSVID2,
+	}
+
+	witSVIDKey := func(svid *cache.WITSVID) string {
+		keyData, err := jose.JSONWebKey{Key: svid.PrivateKey}.MarshalJSON()
+		require.NoError(t, err)
+		return string(keyData)
+	}
+
+	for _, tt := range []struct {
+		name            string
+		identities      []cache.Identity
+		spiffeID        string
+		attestErr       error
+		managerErr      error
+		disableWITSVIDs bool
+		expectCode      codes.Code
+		expectMsg       string
+		expectResp      *workloadPB.WITSVIDResponse
+		expectLogs      []spiretest.LogEntry
+	}{
+		{
+			name:            "WIT disabled",
+			disableWITSVIDs: true,
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "WIT functionality is disabled",
+		},
+		{
+			name:       "malformed SPIFFE ID",
+			spiffeID:   "not a SPIFFE ID",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid requested SPIFFE ID: scheme is missing or invalid",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid requested SPIFFE ID",
+					Data: logrus.Fields{
+						"service":       "WorkloadAPI",
+						"method":        "FetchWITSVID",
+						"spiffe_id":     "not a SPIFFE ID",
+						logrus.ErrorKey: "scheme is missing or invalid",
+					},
+				},
+			},
+		},
+		{
+			name:       "no identity issued",
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No identity issued",
+					Data: logrus.Fields{
+						"registered": "false",
+						"service":    "WorkloadAPI",
+						"method":     "FetchWITSVID",
+					},
+				},
+			},
+		},
+		{
+			name:       "attest error",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unavailable,
+			expectMsg:  "workload attestation failed",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"service":       "WorkloadAPI",
+						"method":        "FetchWITSVID",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name:       "subscribe to cache changes error",
+			managerErr: errors.New("err"),
+			expectCode: codes.Unknown,
+			expectMsg:  "err",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Subscribe to cache changes failed",
+					Data: logrus.Fields{
+						"service":       "WorkloadAPI",
+						"method":        "FetchWITSVID",
+						logrus.ErrorKey: "err",
+					},
+				},
+			},
+		},
+		{
+			name:       "success all",
+			identities: identities,
+			expectCode: codes.OK,
+			expectResp: &workloadPB.WITSVIDResponse{
+				Svids: []*workloadPB.WITSVID{
+					{
+						SpiffeId:   x509SVID1.ID.String(),
+						WitSvid:    "token-1",
+						WitSvidKey: witSVIDKey(witSVID1),
+						Hint:       "internal",
+					},
+					{
+						SpiffeId:   x509SVID2.ID.String(),
+						WitSvid:    "token-2",
+						WitSvidKey: witSVIDKey(witSVID2),
+					},
+				},
+			},
+		},
+		{
+			name:       "success specific",
+			identities: identities,
+			spiffeID:   x509SVID2.ID.String(),
+			expectCode: codes.OK,
+			expectResp: &workloadPB.WITSVIDResponse{
+				Svids: []*workloadPB.WITSVID{
+					{
+						SpiffeId:   x509SVID2.ID.String(),
+						WitSvid:    "token-2",
+						WitSvidKey: witSVIDKey(witSVID2),
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				CA:              ca,
+				Updates:         []*cache.WorkloadUpdate{{Identities: tt.identities}},
+				AttestErr:       tt.attestErr,
+				ManagerErr:      tt.managerErr,
+				ExpectLogs:      tt.expectLogs,
+				WITSVIDs:        witSVIDs,
+				DisableWITSVIDs: tt.disableWITSVIDs,
+			}
+			runTest(t, params,
+				func(ctx context.Context, client workloadPB.SpiffeWorkloadAPIClient) {
+					stream, err := client.FetchWITSVID(ctx, &workloadPB.WITSVIDRequest{
+						SpiffeId: tt.spiffeID,
+					})
+					require.NoError(t, err)
+
+					resp, err := stream.Recv()
+					spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+					spiretest.RequireProtoEqual(t, tt.expectResp, resp)
+				})
+		})
+	}
+}
+
+func TestFetchWITBundles(t *testing.T) {
+	ca := testca.New(t, td)
+
+	x509SVID := ca.CreateX509SVID(workloadID)
+
+	bundle := ca.Bundle()
+	bundle.SetWITAuthorities(map[string]crypto.PublicKey{
+		"wit-key": testkey.NewEC256(t).Public(),
+	})
+	bundleJWKS, err := bundle.WITBundle().Marshal()
+	require.NoError(t, err)
+
+	federatedBundle := testca.New(t, td2).Bundle()
+	federatedBundle.SetWITAuthorities(map[string]crypto.PublicKey{
+		"federated-wit-key": testkey.NewEC256(t).Public(),
+	})
+	federatedBundleJWKS, err := federatedBundle.WITBundle().Marshal()
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name                          string
+		updates                       []*cache.WorkloadUpdate
+		attestErr                     error
+		disableWITSVIDs               bool
+		allowUnauthenticatedVerifiers bool
+		expectCode                    codes.Code
+		expectMsg                     string
+		expectResp                    *workloadPB.WITBundlesResponse
+		expectLogs                    []spiretest.LogEntry
+	}{
+		{
+			name:            "WIT disabled",
+			disableWITSVIDs: true,
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "WIT functionality is disabled",
+		},
+		{
+			name:       "no identity issued",
+			updates:    []*cache.WorkloadUpdate{{}},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No identity issued",
+					Data: logrus.Fields{
+						"registered": "false",
+						"service":    "WorkloadAPI",
+						"method":     "FetchWITBundles",
+					},
+				},
+			},
+		},
+		{
+			name:       "attest error",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Unavailable,
+			expectMsg:  "workload attestation failed",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						"service":       "WorkloadAPI",
+						"method":        "FetchWITBundles",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
+		{
+			name: "cache update unexpectedly missing bundle",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID, "id1"),
+					},
+				},
+			},
+			expectCode: codes.Unavailable,
+			expectMsg:  "could not serialize response: bundle not available",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Could not serialize WIT bundle response",
+					Data: logrus.Fields{
+						"service":       "WorkloadAPI",
+						"method":        "FetchWITBundles",
+						logrus.ErrorKey: "bundle not available",
+					},
+				},
+			},
+		},
+		{
+			name: "success",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID, "id1"),
+					},
+					Bundle: bundle,
+					FederatedBundles: map[spiffeid.TrustDomain]*spiffebundle.Bundle{
+						federatedBundle.TrustDomain(): federatedBundle,
+					},
+				},
+			},
+			expectCode: codes.OK,
+			expectResp: &workloadPB.WITBundlesResponse{
+				Bundles: map[string]string{
+					bundle.TrustDomain().IDString():          string(bundleJWKS),
+					federatedBundle.TrustDomain().IDString(): string(federatedBundleJWKS),
+				},
+			},
+		},
+		{
+			name: "when allowed to fetch without identity",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Bundle: bundle,
+					FederatedBundles: map[spiffeid.TrustDomain]*spiffebundle.Bundle{
+						federatedBundle.TrustDomain(): federatedBundle,
+					},
+				},
+			},
+			allowUnauthenticatedVerifiers: true,
+			expectCode:                    codes.OK,
+			expectResp: &workloadPB.WITBundlesResponse{
+				Bundles: map[string]string{
+					bundle.TrustDomain().IDString(): string(bundleJWKS),
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams{
+				CA:                            ca,
+				Updates:                       tt.updates,
+				AttestErr:                     tt.attestErr,
+				ExpectLogs:                    tt.expectLogs,
+				AllowUnauthenticatedVerifiers: tt.allowUnauthenticatedVerifiers,
+				DisableWITSVIDs:               tt.disableWITSVIDs,
+			}
+			runTest(t, params,
+				func(ctx context.Context, client workloadPB.SpiffeWorkloadAPIClient) {
+					stream, err := client.FetchWITBundles(ctx, &workloadPB.WITBundlesRequest{})
+					require.NoError(t, err)
+
+					resp, err := stream.Recv()
+					spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+					spiretest.RequireProtoEqual(t, tt.expectResp, resp)
+				})
+		})
+	}
+}
+
 func TestValidateJWTSVID(t *testing.T) {
 	ca := testca.New(t, td)
 	ca2 := testca.New(t, td2)
@@ -1802,6 +2138,8 @@ type testParams struct {
 	AllowUnauthenticatedVerifiers bool
 	AllowedForeignJWTClaims       map[string]struct{}
 	RateLimiter                   workload.RateLimiter
+	WITSVIDs                      map[string]*cache.WITSVID
+	DisableWITSVIDs               bool
 }
 
 func runTest(t *testing.T, params testParams, fn func(ctx context.Context, client workloadPB.SpiffeWorkloadAPIClient)) {
@@ -1811,6 +2149,7 @@ func runTest(t *testing.T, params testParams, fn func(ctx context.Context, clien
 		ca:         params.CA,
 		identities: params.Identities,
 		updates:    params.Updates,
+		witSVIDs:   params.WITSVIDs,
 		err:        params.ManagerErr,
 	}
 
@@ -1822,6 +2161,9 @@ func runTest(t *testing.T, params testParams, fn func(ctx context.Context, clien
 		AllowedForeignJWTClaims:       params.AllowedForeignJWTClaims,
 		RateLimiter:                   params.RateLimiter,
 		LogSelectors:                  params.LogSelectors,
+		IsWITSVIDsDisabled: func() bool {
+			return params.DisableWITSVIDs
+		},
 	})
 
 	server := grpctest.StartServer(t, func(s grpc.ServiceRegistrar) {
@@ -1856,6 +2198,7 @@ type FakeManager struct {
 	ca          *testca.CA
 	identities  []cache.Identity
 	updates     []*cache.WorkloadUpdate
+	witSVIDs    map[string]*cache.WITSVID
 	subscribers atomic.Int32
 	err         error
 }
@@ -1883,6 +2226,17 @@ func (m *FakeManager) FetchJWTSVID(_ context.Context, entry *common.Registration
 	}, nil
 }
 
+func (m *FakeManager) FetchWITSVID(_ context.Context, entry *common.RegistrationEntry) (*cache.WITSVID, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	svid, ok := m.witSVIDs[entry.SpiffeId]
+	if !ok {
+		return nil, errors.New("no WIT-SVID for entry")
+	}
+	return svid, nil
+}
+
 func (m *FakeManager) SubscribeToCacheChanges(context.Context, cache.Selectors) (cache.Subscriber, error) {
 	if m.err != nil {
 		return nil, m.err
@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// derivedEntryIdleTimeout is how long the entries derived from entry templates
+// are kept once no workload subscribes to them or fetches them.
+const derivedEntryIdleTimeout = time.Hour
+
+// entryTemplate is a registration entry whose SPIFFE ID is a template over the
+// selectors of the workloads it matches. Entry templates are not cached as
+// records. Instead, an entry is derived from the template for each distinct
+// SPIFFE ID rendered for the workloads matching it.
+type entryTemplate struct {
+	entry *common.RegistrationEntry
+	tmpl  *entrytemplate.Template
+
+	// derived holds the IDs of the entries derived from the template
+	derived map[string]struct{}
+}
+
+func isEntryTemplate(entry *common.RegistrationEntry) bool {
+	return entrytemplate.IsTemplateEntryID(entry.EntryId)
+}
+
+// updateEntryTemplates updates the entry templates with the ones in the
+// update. The entries derived from templates that were removed or changed are
+// removed, and the selector sets of the affected subscribers are returned to be
+// notified. Callers must hold the write lock.
+func (c *LRUCache) updateEntryTemplates(update *UpdateEntries, bundleChanged map[spiffeid.TrustDomain]bool) []selectorSet {
+	var notifySets []selectorSet
+
+	for id, template := range c.templates {
+		newEntry, ok := update.RegistrationEntries[id]
+		if ok && isEntryTemplate(newEntry) && newEntry.RevisionNumber == template.entry.RevisionNumber {
+			if template.federatesWithChangedBundle(bundleChanged) {
+				notifySets = append(notifySets, c.derivedSelectorSets(template)...)
+			}
+			continue
+		}
+
+		c.log.WithFields(logrus.Fields{
+			telemetry.Entry:    id,
+			telemetry.SPIFFEID: template.entry.SpiffeId,
+		}).Debug("Entry template removed")
+		notifySets = append(notifySets, c.removeDerivedEntries(template)...)
+		delete(c.templates, id)
+	}
+
+	templatesAdded := false
+	for id, newEntry := range update.RegistrationEntries {
+		if !isEntryTemplate(newEntry) {
+			continue
+		}
+		if _, ok := c.templates[id]; ok {
+			continue
+		}
+
+		log := c.log.WithFields(logrus.Fields{
+			telemetry.Entry:    id,
+			telemetry.SPIFFEID: newEntry.SpiffeId,
+		})
+		tmpl, err := entrytemplate.Parse(newEntry.SpiffeId)
+		if err != nil {
+			log.WithError(err).Warn("Invalid entry template")
+			continue
+		}
+		c.templates[id] = &entryTemplate{
+			entry:   newEntry,
+			tmpl:    tmpl,
+			derived: make(map[string]struct{}),
+		}
+		templatesAdded = true
+		log.Debug("Entry template added")
+	}
+
+	// Derive entries for the existing subscribers from the new templates
+	if templatesAdded {
+		subs, subsDone := c.allSubscribers()
+		defer subsDone()
+		for sub := range subs {
+			for _, entry := range c.deriveEntries(sub.set) {
+				notifySet := make(selectorSet)
+				notifySet.Merge(entry.Selectors...)
+				notifySets = append(notifySets, notifySet)
+			}
+		}
+	}
+
+	return notifySets
+}
+
+// deriveEntriesForSelectors derives entries from the entry templates matching
+// the selectors. The write lock is only taken if there are entry templates.
+func (c *LRUCache) deriveEntriesForSelectors(set selectorSet) {
+	c.mu.RLock()
+	hasTemplates := len(c.templates) > 0
+	c.mu.RUnlock()
+	if !hasTemplates {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deriveEntries(set)
+}
+
+// deriveEntries derives entries from the entry templates matching the selector
+// set, creating records for the entries that were not derived yet, which are
+// returned. Derived entries are selected by the selectors of their template
+// and the selectors their SPIFFE ID was rendered from, so they only match the
+// workloads that render the same SPIFFE ID. Callers must hold the write lock.
+func (c *LRUCache) deriveEntries(set selectorSet) []*common.RegistrationEntry {
+	if len(c.templates) == 0 || len(set) == 0 {
+		return nil
+	}
+
+	selectors := make([]*common.Selector, 0, len(set))
+	for s := range set {
+		selectors = append(selectors, &common.Selector{Type: s.Type, Value: s.Value})
+	}
+
+	var derived []*common.RegistrationEntry
+	for templateID, template := range c.templates {
+		if !set.In(template.entry.Selectors...) {
+			continue
+		}
+
+		spiffeID, used, err := template.tmpl.Render(selectors)
+		if err != nil {
+			c.log.WithError(err).WithFields(logrus.Fields{
+				telemetry.Entry:    templateID,
+				telemetry.SPIFFEID: template.entry.SpiffeId,
+			}).Debug("Unable to render entry template for workload")
+			continue
+		}
+
+		entryID := entrytemplate.DerivedEntryID(templateID, used)
+		if record, ok := c.records[entryID]; ok {
+			record.lastAccessTimestamp = c.clk.Now().UnixMilli()
+			continue
+		}
+
+		entry := proto.Clone(template.entry).(*common.RegistrationEntry)
+		entry.EntryId = entryID
+		entry.SpiffeId = spiffeID.String()
+		for _, s := range used {
+			if !containsSelector(entry.Selectors, s) {
+				entry.Selectors = append(entry.Selectors, s)
+			}
+		}
+
+		record := newLRUCacheRecord()
+		record.entry = entry
+		record.template = templateID
+		record.lastAccessTimestamp = c.clk.Now().UnixMilli()
+		c.records[entryID] = record
+		for _, s := range entry.Selectors {
+			c.addSelectorIndexRecord(makeSelector(s), record)
+		}
+		template.derived[entryID] = struct{}{}
+		derived = append(derived, entry)
+
+		c.log.WithFields(logrus.Fields{
+			telemetry.Entry:    entryID,
+			telemetry.SPIFFEID: entry.SpiffeId,
+		}).Debug("Entry derived from entry template")
+	}
+	return derived
+}
+
+// removeDerivedEntries removes the records of the entries derived from the
+// template, returning the selector sets of the removed entries. Callers must
+// hold the write lock.
+func (c *LRUCache) removeDerivedEntries(template *entryTemplate) []selectorSet {
+	notifySets := make([]selectorSet, 0, len(template.derived))
+	for id := range template.derived {
+		record, ok := c.records[id]
+		if !ok {
+			continue
+		}
+		notifySet := make(selectorSet)
+		notifySet.Merge(record.entry.Selectors...)
+		c.delSelectorIndicesRecord(notifySet, record)
+		notifySets = append(notifySets, notifySet)
+		delete(c.records, id)
+		delete(c.svids, id)
+		delete(c.staleEntries, id)
+	}
+	return notifySets
+}
+
+// pruneDerivedEntries removes the records of the entries derived from entry
+// templates that have no subscribers and were not accessed for
+// derivedEntryIdleTimeout, since the workloads they were derived for are gone.
+// The number of removed records is returned. Callers must hold the write lock.
+func (c *LRUCache) pruneDerivedEntries() int {
+	idleSince := c.clk.Now().Add(-derivedEntryIdleTimeout).UnixMilli()
+
+	pruned := 0
+	for id, record := range c.records {
+		if record.template == "" || record.lastAccessTimestamp > idleSince || c.hasSubscribers(record) {
+			continue
+		}
+
+		set, setDone := allocSelectorSet(record.entry.Selectors...)
+		c.delSelectorIndicesRecord(set, record)
+		setDone()
+		delete(c.records, id)
+		delete(c.svids, id)
+		delete(c.staleEntries, id)
+		if template, ok := c.templates[record.template]; ok {
+			delete(template.derived, id)
+		}
+		pruned++
+
+		c.log.WithFields(logrus.Fields{
+			telemetry.Entry:    id,
+			telemetry.SPIFFEID: record.entry.SpiffeId,
+		}).Debug("Idle entry derived from entry template removed")
+	}
+	return pruned
+}
+
+// hasSubscribers returns whether any subscriber matches the record. Callers
+// must hold the read or write lock.
+func (c *LRUCache) hasSubscribers(record *lruCacheRecord) bool {
+	set, setDone := allocSelectorSet(record.entry.Selectors...)
+	defer setDone()
+
+	subs, subsDone := c.getSubscribers(set)
+	defer subsDone()
+	for sub := range subs {
+		if sub.set.SuperSetOf(set) {
+			return true
+		}
+	}
+	return false
+}
+
+// touchDerivedEntries updates the last access time of the entries derived
+// from entry templates matching the selector set, so they are kept for
+// derivedEntryIdleTimeout after the last subscriber goes away. Callers must
+// hold the write lock.
+func (c *LRUCache) touchDerivedEntries(set selectorSet) {
+	records, recordsDone := c.getRecordsForSelectors(set)
+	defer recordsDone()
+
+	now := c.clk.Now().UnixMilli()
+	for record := range records {
+		if record.template != "" {
+			record.lastAccessTimestamp = now
+		}
+	}
+}
+
+// derivedSelectorSets returns the selector sets of the entries derived from
+// the template. Callers must hold the read or write lock.
+func (c *LRUCache) derivedSelectorSets(template *entryTemplate) []selectorSet {
+	sets := make([]selectorSet, 0, len(template.derived))
+	for id := range template.derived {
+		if record, ok := c.records[id]; ok {
+			set := make(selectorSet)
+			set.Merge(record.entry.Selectors...)
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+func (t *entryTemplate) federatesWithChangedBundle(bundleChanged map[spiffeid.TrustDomain]bool) bool {
+	for _, id := range t.entry.FederatesWith {
+		td, err := spiffeid.TrustDomainFromString(id)
+		if err == nil && bundleChanged[td] {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSelector(selectors []*common.Selector, s *common.Selector) bool {
+	for _, existing := range selectors {
+		if existing.Type == s.Type && existing.Value == s.Value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryTemplateDerivesEntries(t *testing.T) {
+	cache := newTestLRUCache(t)
+
+	tmpl := makeEntryTemplate("template:TMPL", "spiffe://domain.test/ns/{{ .k8s.ns }}", "sa:default")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(tmpl),
+	}, nil)
+	require.Zero(t, cache.CountRecords(), "entry templates should not be cached as records")
+
+	// A workload matching the template gets a derived entry
+	sub := cache.NewSubscriber(makeK8sSelectors("ns:web", "sa:default", "pod-uid:1"))
+	defer sub.Finish()
+
+	webEntry := &common.RegistrationEntry{
+		EntryId:   entrytemplate.DerivedEntryID("template:TMPL", makeK8sSelectors("ns:web")),
+		SpiffeId:  "spiffe://domain.test/ns/web",
+		Selectors: makeK8sSelectors("sa:default", "ns:web"),
+	}
+	entries := cache.Entries()
+	spiretest.RequireProtoListEqual(t, []*common.RegistrationEntry{webEntry}, entries)
+
+	// The derived entry needs an SVID since it has a subscriber
+	cache.SyncSVIDsWithSubscribers()
+	staleEntries := cache.GetStaleEntries()
+	require.Len(t, staleEntries, 1)
+	spiretest.AssertProtoEqual(t, webEntry, staleEntries[0].Entry)
+
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(webEntry),
+	})
+	assertWorkloadUpdateEqual(t, sub, &WorkloadUpdate{
+		Bundle:     bundleV1,
+		Identities: []Identity{{Entry: entries[0]}},
+	})
+
+	// Workloads rendering the same SPIFFE ID share the derived entry
+	spiretest.AssertProtoListEqual(t, []*common.RegistrationEntry{webEntry}, cache.MatchingRegistrationEntries(makeK8sSelectors("ns:web", "sa:default", "pod-uid:2")))
+	assert.Equal(t, 1, cache.CountRecords())
+
+	// Workloads rendering another SPIFFE ID get their own derived entry
+	dbEntry := &common.RegistrationEntry{
+		EntryId:   entrytemplate.DerivedEntryID("template:TMPL", makeK8sSelectors("ns:db")),
+		SpiffeId:  "spiffe://domain.test/ns/db",
+		Selectors: makeK8sSelectors("sa:default", "ns:db"),
+	}
+	spiretest.AssertProtoListEqual(t, []*common.RegistrationEntry{dbEntry}, cache.MatchingRegistrationEntries(makeK8sSelectors("ns:db", "sa:default")))
+	assert.Equal(t, 2, cache.CountRecords())
+
+	// Workloads that do not match the template selectors, or cannot render
+	// the template, do not get derived entries
+	assert.Empty(t, cache.MatchingRegistrationEntries(makeK8sSelectors("ns:web", "sa:other")))
+	assert.Empty(t, cache.MatchingRegistrationEntries(makeK8sSelectors("sa:default")))
+	assert.Equal(t, 2, cache.CountRecords())
+}
+
+func TestEntryTemplateAddedForExistingSubscriber(t *testing.T) {
+	cache := newTestLRUCache(t)
+
+	sub := cache.NewSubscriber(makeK8sSelectors("ns:web", "sa:default"))
+	defer sub.Finish()
+
+	tmpl := makeEntryTemplate("template:TMPL", "spiffe://domain.test/ns/{{ .k8s.ns }}", "sa:default")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(tmpl),
+	}, nil)
+
+	staleEntries := cache.GetStaleEntries()
+	require.Len(t, staleEntries, 1)
+	assert.Equal(t, "spiffe://domain.test/ns/web", staleEntries[0].Entry.SpiffeId)
+}
+
+func TestEntryTemplateRemoved(t *testing.T) {
+	cache := newTestLRUCache(t)
+
+	foo := makeRegistrationEntry("FOO", "A")
+	tmpl := makeEntryTemplate("template:TMPL", "spiffe://domain.test/ns/{{ .k8s.ns }}", "sa:default")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, tmpl),
+	}, nil)
+
+	sub := cache.NewSubscriber(makeK8sSelectors("ns:web", "sa:default"))
+	defer sub.Finish()
+	derived := cache.MatchingRegistrationEntries(makeK8sSelectors("ns:web", "sa:default"))
+	require.Len(t, derived, 1)
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(foo, derived[0]),
+	})
+	assertWorkloadUpdateEqual(t, sub, &WorkloadUpdate{
+		Bundle:     bundleV1,
+		Identities: []Identity{{Entry: derived[0]}},
+	})
+
+	// Entries derived from a template are kept while the template exists
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, tmpl),
+	}, nil)
+	assert.Equal(t, 2, cache.CountRecords())
+	assertNoWorkloadUpdate(t, sub)
+
+	// Entries derived from a template are removed along with the template
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo),
+	}, nil)
+	assert.Equal(t, []*common.RegistrationEntry{foo}, cache.Entries())
+	assert.Equal(t, 1, cache.CountX509SVIDs())
+	assertWorkloadUpdateEqual(t, sub, &WorkloadUpdate{
+		Bundle: bundleV1,
+	})
+}
+
+func TestEntryTemplateDerivedEntriesPruned(t *testing.T) {
+	clk := clock.NewMock(t)
+	cache := newTestLRUCacheWithConfig(10, clk)
+	update := &UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(makeEntryTemplate("template:TMPL", "spiffe://domain.test/ns/{{ .k8s.ns }}", "sa:default")),
+	}
+	cache.UpdateEntries(update, nil)
+
+	// Derive an entry for a workload that subscribes, and for one that only
+	// fetches its identities
+	sub := cache.NewSubscriber(makeK8sSelectors("ns:web", "sa:default"))
+	require.Len(t, cache.MatchingRegistrationEntries(makeK8sSelectors("ns:db", "sa:default")), 1)
+	require.Equal(t, 2, cache.CountRecords())
+
+	// Derived entries are kept while they are in use
+	clk.Add(derivedEntryIdleTimeout / 2)
+	cache.UpdateEntries(update, nil)
+	require.Equal(t, 2, cache.CountRecords())
+
+	// Fetching the identities again keeps the derived entry
+	require.Len(t, cache.MatchingRegistrationEntries(makeK8sSelectors("ns:db", "sa:default")), 1)
+	sub.Finish()
+
+	// Idle derived entries are pruned, while the ones still in use are kept
+	clk.Add(derivedEntryIdleTimeout/2 + time.Millisecond)
+	cache.UpdateEntries(update, nil)
+	require.Equal(t, 2, cache.CountRecords())
+
+	clk.Add(derivedEntryIdleTimeout / 2)
+	cache.UpdateEntries(update, nil)
+	require.Empty(t, cache.Entries())
+
+	// A workload matching the template again gets a new derived entry
+	require.Len(t, cache.MatchingRegistrationEntries(makeK8sSelectors("ns:web", "sa:default")), 1)
+	require.Equal(t, 1, cache.CountRecords())
+}
+
+func makeEntryTemplate(id, spiffeID string, selectors ...string) *common.RegistrationEntry {
+	return &common.RegistrationEntry{
+		EntryId:   id,
+		SpiffeId:  spiffeID,
+		Selectors: makeK8sSelectors(selectors...),
+	}
+}
+
+func makeK8sSelectors(values ...string) []*common.Selector {
+	var out []*common.Selector
+	for _, value := range values {
+		out = append(out, &common.Selector{Type: "k8s", Value: value})
+	}
+	return out
+}
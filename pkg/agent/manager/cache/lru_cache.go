@@ -44,6 +44,9 @@ type UpdateEntries struct {
 	// TaintedJWTAuthorities is a set of all tainted JWT authorities notified by the server.
 	TaintedJWTAuthorities map[string]struct{}
 
+	// TaintedWITAuthorities is a set of all tainted WIT authorities notified by the server.
+	TaintedWITAuthorities map[string]struct{}
+
 	// RegistrationEntries is a set of all registration entries available to the
 	// agent, keyed by registration entry id.
 	RegistrationEntries map[string]*common.RegistrationEntry
@@ -115,6 +118,7 @@ type StaleEntry struct {
 type LRUCache struct {
 	*BundleCache
 	*JWTSVIDCache
+	*WITSVIDCache
 
 	log         logrus.FieldLogger
 	trustDomain spiffeid.TrustDomain
@@ -127,6 +131,9 @@ type LRUCache struct {
 	// records holds the records for registration entries, keyed by registration entry ID
 	records map[string]*lruCacheRecord
 
+	// templates holds the entry templates, keyed by registration entry ID
+	templates map[string]*entryTemplate
+
 	// selectors holds the selector indices, keyed by a selector key
 	selectors map[selector]*selectorsMapIndex
 
@@ -157,11 +164,15 @@ func NewLRUCache(log logrus.FieldLogger, trustDomain spiffeid.TrustDomain, bundl
 	return &LRUCache{
 		BundleCache:  NewBundleCache(trustDomain, bundle),
 		JWTSVIDCache: NewJWTSVIDCache(log, metrics, jwtSvidCacheMaxSize),
+		// WIT-SVIDs are minted one per identity, like X509-SVIDs, so they
+		// share the same size limit.
+		WITSVIDCache: NewWITSVIDCache(log, metrics, x509SvidCacheMaxSize),
 
 		log:          log,
 		metrics:      metrics,
 		trustDomain:  trustDomain,
 		records:      make(map[string]*lruCacheRecord),
+		templates:    make(map[string]*entryTemplate),
 		selectors:    make(map[selector]*selectorsMapIndex),
 		staleEntries: make(map[string]bool),
 		bundles: map[spiffeid.TrustDomain]*spiffebundle.Bundle{
@@ -231,6 +242,8 @@ func (c *LRUCache) MatchingRegistrationEntries(selectors []*common.Selector) []*
 	set, setDone := allocSelectorSet(selectors...)
 	defer setDone()
 
+	c.deriveEntriesForSelectors(set)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.matchingEntries(set)
@@ -240,6 +253,8 @@ func (c *LRUCache) FetchWorkloadUpdate(selectors []*common.Selector) *WorkloadUp
 	set, setDone := allocSelectorSet(selectors...)
 	defer setDone()
 
+	c.deriveEntriesForSelectors(set)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.buildWorkloadUpdate(set)
@@ -255,6 +270,7 @@ func (c *LRUCache) NewSubscriber(selectors []*common.Selector) Subscriber {
 	for s := range sub.set {
 		c.addSelectorIndexSub(s, sub)
 	}
+	c.deriveEntries(sub.set)
 	// update lastAccessTimestamp of records containing provided selectors
 	c.updateLastAccessTimestamp(selectors)
 	return sub
@@ -317,9 +333,14 @@ func (c *LRUCache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.R
 	defer fedRemDone()
 
 	entriesRemoved := 0
-	// Remove records for registration entries that no longer exist
+	// Remove records for registration entries that no longer exist. Records
+	// for entries derived from entry templates are removed once idle, or
+	// along with their template.
 	for id, record := range c.records {
-		if _, ok := update.RegistrationEntries[id]; !ok {
+		if record.template != "" {
+			continue
+		}
+		if newEntry, ok := update.RegistrationEntries[id]; !ok || isEntryTemplate(newEntry) {
 			c.log.WithFields(logrus.Fields{
 				telemetry.Entry:    id,
 				telemetry.SPIFFEID: record.entry.SpiffeId,
@@ -339,14 +360,21 @@ func (c *LRUCache) UpdateEntries(update *UpdateEntries, checkSVID func(*common.R
 			delete(c.staleEntries, id)
 		}
 	}
+	entriesRemoved += c.pruneDerivedEntries()
 	agentmetrics.IncrementEntriesRemoved(c.metrics, entriesRemoved)
 
 	outdatedEntries := make(map[string]struct{})
 	entriesUpdated := 0
 	entriesCreated := 0
 
+	notifySets = append(notifySets, c.updateEntryTemplates(update, bundleChanged)...)
+
 	// Add/update records for registration entries in the update
 	for _, newEntry := range update.RegistrationEntries {
+		if isEntryTemplate(newEntry) {
+			continue
+		}
+
 		clearSelectorSet(selAdd)
 		clearSelectorSet(selRem)
 		clearStringSet(fedAdd)
@@ -899,6 +927,7 @@ func (c *LRUCache) delSelectorIndexSub(s selector, sub *lruCacheSubscriber) {
 func (c *LRUCache) unsubscribe(sub *lruCacheSubscriber) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.touchDerivedEntries(sub.set)
 	for selector := range sub.set {
 		c.delSelectorIndexSub(selector, sub)
 	}
@@ -1079,6 +1108,10 @@ type lruCacheRecord struct {
 	entry               *common.RegistrationEntry
 	subs                map[*lruCacheSubscriber]struct{}
 	lastAccessTimestamp int64
+
+	// template is the ID of the entry template the entry was derived from,
+	// if any
+	template string
 }
 
 func newLRUCacheRecord() *lruCacheRecord {
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/telemetry/agent"
+)
+
+type WITSVIDCache struct {
+	log     logrus.FieldLogger
+	metrics telemetry.Metrics
+	mu      sync.RWMutex
+
+	witSVIDs   map[string]*list.Element
+	witLRUList *list.List
+
+	// witSVIDCacheMaxSize is a hard limit of max number of SVIDs that would be stored in cache
+	witSVIDCacheMaxSize int
+}
+
+type witSvidElement struct {
+	key  string
+	svid *WITSVID
+}
+
+func (c *WITSVIDCache) CountWITSVIDs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.witSVIDs)
+}
+
+func NewWITSVIDCache(log logrus.FieldLogger, metrics telemetry.Metrics, witSVIDCacheMaxSize int) *WITSVIDCache {
+	if witSVIDCacheMaxSize <= 0 {
+		witSVIDCacheMaxSize = DefaultSVIDCacheMaxSize
+	}
+	return &WITSVIDCache{
+		log:                 log,
+		metrics:             metrics,
+		witSVIDs:            make(map[string]*list.Element),
+		witLRUList:          list.New(),
+		witSVIDCacheMaxSize: witSVIDCacheMaxSize,
+	}
+}
+
+func (c *WITSVIDCache) GetWITSVID(spiffeID spiffeid.ID) (*WITSVID, bool) {
+	key := spiffeID.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	svidElement, ok := c.witSVIDs[key]
+	if !ok {
+		return nil, ok
+	}
+	c.witLRUList.MoveToFront(svidElement)
+
+	return svidElement.Value.(witSvidElement).svid, ok
+}
+
+func (c *WITSVIDCache) SetWITSVID(spiffeID spiffeid.ID, svid *WITSVID) {
+	key := spiffeID.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	svidElement, ok := c.witSVIDs[key]
+	if ok {
+		svidElement.Value = witSvidElement{
+			key:  key,
+			svid: svid,
+		}
+		c.witLRUList.MoveToFront(svidElement)
+		return
+	}
+
+	if len(c.witSVIDs) >= c.witSVIDCacheMaxSize {
+		element := c.witLRUList.Back()
+		witSvidWithKey := element.Value.(witSvidElement)
+		delete(c.witSVIDs, witSvidWithKey.key)
+		c.witLRUList.Remove(element)
+	}
+
+	c.witSVIDs[key] = c.witLRUList.PushFront(witSvidElement{
+		key:  key,
+		svid: svid,
+	})
+}
+
+func (c *WITSVIDCache) TaintWITSVIDs(ctx context.Context, taintedWITAuthorities map[string]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counter := telemetry.StartCall(c.metrics, telemetry.CacheManager, agent.CacheTypeWorkload, telemetry.ProcessTaintedWITSVIDs)
+	defer counter.Done(nil)
+
+	removedKeyIDs := make(map[string]int)
+	totalCount := 0
+	for key, element := range c.witSVIDs {
+		witSvidElement := element.Value.(witSvidElement)
+		keyID, err := getKeyIDFromSVIDToken(witSvidElement.svid.SVID.Token)
+		if err != nil {
+			c.log.WithError(err).Error("Could not get key ID from cached WIT-SVID")
+			continue
+		}
+
+		if _, tainted := taintedWITAuthorities[keyID]; tainted {
+			delete(c.witSVIDs, key)
+			c.witLRUList.Remove(element)
+
+			removedKeyIDs[keyID]++
+			totalCount++
+		}
+		select {
+		case <-ctx.Done():
+			c.log.WithError(ctx.Err()).Warn("Context cancelled, exiting process of tainting WIT-SVIDs in cache")
+			return
+		default:
+		}
+	}
+	for keyID, count := range removedKeyIDs {
+		c.log.WithField(telemetry.WITAuthorityKeyIDs, keyID).
+			WithField(telemetry.TaintedWITSVIDs, count).
+			Info("WIT-SVIDs were removed from the WIT cache because they were issued by a tainted authority")
+	}
+	agent.AddCacheManagerTaintedWITSVIDsSample(c.metrics, agent.CacheTypeWorkload, float32(totalCount))
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"crypto"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/hashicorp/go-metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/telemetry/agent"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWITSVIDCache(t *testing.T) {
+	now := time.Now()
+	witSVID1 := &WITSVID{
+		SVID:       &client.WITSVID{Token: "token-1", IssuedAt: now, ExpiresAt: now.Add(time.Minute)},
+		PrivateKey: testkey.NewEC256(t),
+	}
+	witSVID2 := &WITSVID{
+		SVID:       &client.WITSVID{Token: "token-2", IssuedAt: now, ExpiresAt: now.Add(time.Minute)},
+		PrivateKey: testkey.NewEC256(t),
+	}
+
+	log, _ := test.NewNullLogger()
+	cache := NewWITSVIDCache(log, fakemetrics.New(), 2)
+
+	spiffeID1 := spiffeid.RequireFromString("spiffe://example.org/blog")
+	spiffeID2 := spiffeid.RequireFromString("spiffe://example.org/db")
+	spiffeID3 := spiffeid.RequireFromString("spiffe://example.org/web")
+
+	// WIT is not cached
+	actual, ok := cache.GetWITSVID(spiffeID1)
+	assert.False(t, ok)
+	assert.Nil(t, actual)
+
+	// WIT is cached
+	cache.SetWITSVID(spiffeID1, witSVID1)
+	actual, ok = cache.GetWITSVID(spiffeID1)
+	assert.True(t, ok)
+	assert.Equal(t, witSVID1, actual)
+
+	// WIT is replaced
+	cache.SetWITSVID(spiffeID1, witSVID2)
+	actual, ok = cache.GetWITSVID(spiffeID1)
+	assert.True(t, ok)
+	assert.Equal(t, witSVID2, actual)
+	assert.Equal(t, 1, cache.CountWITSVIDs())
+
+	// Least recently used WIT is evicted once the cache is full
+	cache.SetWITSVID(spiffeID2, witSVID1)
+	_, ok = cache.GetWITSVID(spiffeID1)
+	assert.True(t, ok)
+	cache.SetWITSVID(spiffeID3, witSVID1)
+	assert.Equal(t, 2, cache.CountWITSVIDs())
+
+	_, ok = cache.GetWITSVID(spiffeID1)
+	assert.True(t, ok)
+	_, ok = cache.GetWITSVID(spiffeID2)
+	assert.False(t, ok)
+	_, ok = cache.GetWITSVID(spiffeID3)
+	assert.True(t, ok)
+}
+
+func TestWITSVIDCacheTaint(t *testing.T) {
+	now := time.Now()
+	authorityKey := testkey.NewEC256(t)
+	witSVID := func(keyID string) *WITSVID {
+		return &WITSVID{
+			SVID:       &client.WITSVID{Token: signWIT(t, authorityKey, keyID), IssuedAt: now, ExpiresAt: now.Add(time.Minute)},
+			PrivateKey: testkey.NewEC256(t),
+		}
+	}
+
+	fakeMetrics := fakemetrics.New()
+	log, logHook := test.NewNullLogger()
+	cache := NewWITSVIDCache(log, fakeMetrics, 8)
+
+	spiffeID1 := spiffeid.RequireFromString("spiffe://example.org/blog")
+	spiffeID2 := spiffeid.RequireFromString("spiffe://example.org/db")
+	spiffeID3 := spiffeid.RequireFromString("spiffe://example.org/web")
+	cache.SetWITSVID(spiffeID1, witSVID("key-1"))
+	cache.SetWITSVID(spiffeID2, witSVID("key-1"))
+	cache.SetWITSVID(spiffeID3, witSVID("key-2"))
+
+	cache.TaintWITSVIDs(context.Background(), map[string]struct{}{"key-1": {}, "not-cached": {}})
+
+	// Only the WIT-SVIDs signed by the tainted authority are removed
+	_, ok := cache.GetWITSVID(spiffeID1)
+	assert.False(t, ok)
+	_, ok = cache.GetWITSVID(spiffeID2)
+	assert.False(t, ok)
+	_, ok = cache.GetWITSVID(spiffeID3)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.CountWITSVIDs())
+
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "WIT-SVIDs were removed from the WIT cache because they were issued by a tainted authority",
+			Data: logrus.Fields{
+				telemetry.TaintedWITSVIDs:    "2",
+				telemetry.WITAuthorityKeyIDs: "key-1",
+			},
+		},
+	})
+	assert.Equal(t, []fakemetrics.MetricItem{
+		{
+			Type: fakemetrics.AddSampleType,
+			Key:  []string{telemetry.CacheManager, telemetry.TaintedWITSVIDs, agent.CacheTypeWorkload},
+			Val:  2,
+		},
+		{
+			Type:   fakemetrics.IncrCounterWithLabelsType,
+			Key:    []string{telemetry.CacheManager, agent.CacheTypeWorkload, telemetry.ProcessTaintedWITSVIDs},
+			Val:    1,
+			Labels: []metrics.Label{{Name: "status", Value: "OK"}},
+		},
+		{
+			Type:   fakemetrics.MeasureSinceWithLabelsType,
+			Key:    []string{telemetry.CacheManager, agent.CacheTypeWorkload, telemetry.ProcessTaintedWITSVIDs, telemetry.ElapsedTime},
+			Val:    0,
+			Labels: []metrics.Label{{Name: "status", Value: "OK"}},
+		},
+	}, fakeMetrics.AllMetrics())
+}
+
+func signWIT(t *testing.T, key crypto.Signer, keyID string) string {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{
+			Algorithm: jose.ES256,
+			Key:       jose.JSONWebKey{Key: key, KeyID: keyID},
+		},
+		new(jose.SignerOptions).WithType("wit+jwt"),
+	)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "spiffe://example.org/workload"}).Serialize()
+	require.NoError(t, err)
+	return token
+}
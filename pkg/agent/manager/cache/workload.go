@@ -6,6 +6,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
@@ -36,6 +37,12 @@ func (u *WorkloadUpdate) HasIdentity() bool {
 	return len(u.Identities) > 0
 }
 
+// WITSVID holds onto a WIT-SVID and the private key bound to it.
+type WITSVID struct {
+	SVID       *client.WITSVID
+	PrivateKey crypto.Signer
+}
+
 // X509SVID holds onto the SVID certificate chain and private key.
 type X509SVID struct {
 	Chain      []*x509.Certificate
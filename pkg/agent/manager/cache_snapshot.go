@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/manager/cachesnapshot"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// loadCacheSnapshot restores the workload cache from the snapshot on disk, if
+// any, so that workloads can be served before the first synchronization with
+// the server. It returns true if the cache was restored.
+func (m *manager) loadCacheSnapshot(ctx context.Context) bool {
+	if m.cacheSnapshot == nil {
+		return false
+	}
+	log := m.c.Log.WithField(telemetry.Path, m.c.CacheSnapshotPath)
+
+	agentID, err := m.agentID()
+	if err != nil {
+		log.WithError(err).Warn("Unable to load cache snapshot")
+		return false
+	}
+
+	snapshot, err := m.cacheSnapshot.Load(ctx, m.clk.Now())
+	switch {
+	case errors.Is(err, cachesnapshot.ErrNotFound):
+		return false
+	case err != nil:
+		log.WithError(err).Warn("Unable to load cache snapshot")
+		return false
+	case snapshot.AgentID != agentID:
+		log.WithField(telemetry.AgentID, snapshot.AgentID).Warn("Discarding cache snapshot taken by another agent")
+		return false
+	}
+
+	bundles, err := parseBundles(snapshot.Bundles)
+	if err != nil {
+		log.WithError(err).Warn("Unable to load cache snapshot")
+		return false
+	}
+	// The bundle obtained when the agent was attested is at least as recent
+	// as the one in the snapshot.
+	bundles[m.c.TrustDomain] = m.cache.Bundle()
+
+	// Entries for SVIDs stored by SVIDStore plugins are not served to
+	// workloads, so they are left out of the workload cache.
+	cacheEntries := make(map[string]*common.RegistrationEntry)
+	for entryID, entry := range snapshot.Entries {
+		if !entry.StoreSvid {
+			cacheEntries[entryID] = entry
+		}
+	}
+	svids := make(map[string]*cache.X509SVID)
+	for entryID, svid := range snapshot.X509SVIDs {
+		if _, ok := cacheEntries[entryID]; ok {
+			svids[entryID] = svid
+		}
+	}
+
+	m.cache.UpdateEntries(&cache.UpdateEntries{
+		Bundles:             bundles,
+		RegistrationEntries: cacheEntries,
+	}, nil)
+	m.cache.UpdateSVIDs(&cache.UpdateSVIDs{
+		X509SVIDs: svids,
+	})
+
+	// Seed the synced entries so that the first synchronization only
+	// fetches the entries that changed since the snapshot was taken.
+	if m.c.UseSyncAuthorizedEntries {
+		m.syncedEntries = snapshot.Entries
+		m.syncedBundles = snapshot.Bundles
+	}
+	m.lastEntries = snapshot.Entries
+	m.lastBundles = snapshot.Bundles
+
+	log.WithFields(logrus.Fields{
+		telemetry.CachedEntries:   len(cacheEntries),
+		telemetry.CachedX509SVIDs: len(svids),
+	}).Info("Workload cache restored from snapshot")
+	return true
+}
+
+// saveCacheSnapshot saves the entries and bundles of the last update, along
+// with the cached X509-SVIDs, to the snapshot on disk. Failures are logged.
+func (m *manager) saveCacheSnapshot(ctx context.Context) {
+	if m.cacheSnapshot == nil || m.lastEntries == nil {
+		return
+	}
+	log := m.c.Log.WithField(telemetry.Path, m.c.CacheSnapshotPath)
+
+	agentID, err := m.agentID()
+	if err != nil {
+		log.WithError(err).Warn("Unable to save cache snapshot")
+		return
+	}
+
+	svids := make(map[string]*cache.X509SVID)
+	for _, identity := range m.cache.Identities() {
+		// Entries derived from entry templates are derived again when
+		// workloads connect, so their SVIDs are not saved.
+		if _, ok := m.lastEntries[identity.Entry.EntryId]; ok {
+			svids[identity.Entry.EntryId] = &cache.X509SVID{
+				Chain:      identity.SVID,
+				PrivateKey: identity.PrivateKey,
+			}
+		}
+	}
+
+	now := m.clk.Now()
+	if err := m.cacheSnapshot.Save(ctx, &cachesnapshot.Snapshot{
+		CreatedAt: now,
+		AgentID:   agentID,
+		Entries:   m.lastEntries,
+		Bundles:   m.lastBundles,
+		X509SVIDs: svids,
+	}); err != nil {
+		log.WithError(err).Warn("Unable to save cache snapshot")
+		return
+	}
+	m.lastCacheSnapshot = now
+}
+
+func (m *manager) agentID() (spiffeid.ID, error) {
+	state := m.svid.State()
+	if len(state.SVID) == 0 {
+		return spiffeid.ID{}, errors.New("agent SVID is not available")
+	}
+	return x509svid.IDFromCert(state.SVID[0])
+}
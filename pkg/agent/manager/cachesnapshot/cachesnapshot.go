@@ -0,0 +1,345 @@
+// Package cachesnapshot persists the workload cache of the agent to disk so
+// that the agent can serve the Workload API right after a restart, while the
+// cache is revalidated against the server.
+//
+// Snapshots hold the private keys of the cached X509-SVIDs, so they are sealed
+// with AES-256-GCM. The sealing key is derived from a signature made by a key
+// held in the agent KeyManager, which means that a snapshot can only be opened
+// by an agent that has access to the same KeyManager key. A KeyManager that
+// persists keys across restarts (e.g. disk) is required for snapshots to be of
+// any use.
+package cachesnapshot
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// SealingKeyID is the ID of the KeyManager key used to seal snapshots.
+	SealingKeyID = "agent-cache-snapshot"
+
+	envelopeVersion = 1
+	sealingKeyInfo  = "spire-agent-cache-snapshot"
+	saltSize        = 32
+)
+
+var (
+	// ErrNotFound is returned by Load when there is no snapshot on disk.
+	ErrNotFound = errors.New("cache snapshot not found")
+
+	// sealingDigest is the digest signed by the sealing key. The signature is
+	// the input keying material of the key that seals the snapshot, so it
+	// must be deterministic, which is the case of RSA PKCS #1 v1.5 signatures.
+	sealingDigest = sha256.Sum256([]byte(sealingKeyInfo))
+)
+
+// Snapshot is the state of the workload cache of the agent.
+type Snapshot struct {
+	// CreatedAt is the time the snapshot was taken.
+	CreatedAt time.Time
+
+	// AgentID is the SPIFFE ID of the agent that took the snapshot.
+	AgentID spiffeid.ID
+
+	// Entries are the registration entries, keyed by entry ID.
+	Entries map[string]*common.RegistrationEntry
+
+	// Bundles are the trust bundles, keyed by trust domain ID.
+	Bundles map[string]*common.Bundle
+
+	// X509SVIDs are the cached X509-SVIDs, keyed by entry ID.
+	X509SVIDs map[string]*cache.X509SVID
+}
+
+// Store saves and loads sealed snapshots of the workload cache.
+type Store struct {
+	path string
+	km   keymanager.KeyManager
+}
+
+// New returns a store that keeps the snapshot at the given path, sealed with
+// a key held in the given KeyManager.
+func New(path string, km keymanager.KeyManager) *Store {
+	return &Store{
+		path: path,
+		km:   km,
+	}
+}
+
+// envelope is the sealed snapshot, as written to disk.
+type envelope struct {
+	Version    int    `json:"version"`
+	KeyID      string `json:"key_id"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// snapshotData is the plaintext of a sealed snapshot. Entries and bundles are
+// protobuf-encoded.
+type snapshotData struct {
+	CreatedAt time.Time      `json:"created_at"`
+	AgentID   string         `json:"agent_id"`
+	Entries   [][]byte       `json:"entries"`
+	Bundles   [][]byte       `json:"bundles"`
+	X509SVIDs []x509SVIDData `json:"x509_svids"`
+}
+
+type x509SVIDData struct {
+	EntryID    string   `json:"entry_id"`
+	CertChain  [][]byte `json:"cert_chain"`
+	PrivateKey []byte   `json:"private_key"`
+}
+
+// Save seals the snapshot and atomically replaces the one on disk. The
+// sealing key is generated in the KeyManager if it does not exist yet.
+func (s *Store) Save(ctx context.Context, snapshot *Snapshot) error {
+	data := snapshotData{
+		CreatedAt: snapshot.CreatedAt,
+		AgentID:   snapshot.AgentID.String(),
+	}
+	for _, entry := range snapshot.Entries {
+		entryBytes, err := proto.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal registration entry %q: %w", entry.EntryId, err)
+		}
+		data.Entries = append(data.Entries, entryBytes)
+	}
+	for _, bundle := range snapshot.Bundles {
+		bundleBytes, err := proto.Marshal(bundle)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bundle %q: %w", bundle.TrustDomainId, err)
+		}
+		data.Bundles = append(data.Bundles, bundleBytes)
+	}
+	for entryID, svid := range snapshot.X509SVIDs {
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to marshal private key of X509-SVID for entry %q: %w", entryID, err)
+		}
+		svidData := x509SVIDData{
+			EntryID:    entryID,
+			PrivateKey: keyBytes,
+		}
+		for _, cert := range svid.Chain {
+			svidData.CertChain = append(svidData.CertChain, cert.Raw)
+		}
+		data.X509SVIDs = append(data.X509SVIDs, svidData)
+	}
+
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache snapshot: %w", err)
+	}
+
+	key, err := s.getOrGenerateSealingKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := newAEAD(key, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	env := envelope{
+		Version: envelopeVersion,
+		KeyID:   key.ID(),
+		Salt:    salt,
+		Nonce:   nonce,
+	}
+	env.Ciphertext = aead.Seal(nil, nonce, plaintext, additionalData(env))
+
+	envBytes, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache snapshot envelope: %w", err)
+	}
+	if err := diskutil.AtomicWritePrivateFile(s.path, envBytes); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load opens the snapshot on disk. ErrNotFound is returned if there is no
+// snapshot. X509-SVIDs that are expired as of now are not returned.
+func (s *Store) Load(ctx context.Context, now time.Time) (*Snapshot, error) {
+	envBytes, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to read cache snapshot: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(envBytes, &env); err != nil {
+		return nil, fmt.Errorf("malformed cache snapshot: %w", err)
+	}
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("unsupported cache snapshot version %d", env.Version)
+	}
+
+	key, err := s.km.GetKey(ctx, env.KeyID)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		return nil, fmt.Errorf("cache snapshot sealing key %q not found in the key manager", env.KeyID)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get cache snapshot sealing key: %w", err)
+	}
+
+	aead, err := newAEAD(key, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, errors.New("malformed cache snapshot: invalid nonce size")
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, additionalData(env))
+	if err != nil {
+		return nil, errors.New("failed to open cache snapshot: message authentication failed")
+	}
+
+	var data snapshotData
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, fmt.Errorf("malformed cache snapshot: %w", err)
+	}
+
+	agentID, err := spiffeid.FromString(data.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cache snapshot: invalid agent ID: %w", err)
+	}
+	snapshot := &Snapshot{
+		CreatedAt: data.CreatedAt,
+		AgentID:   agentID,
+		Entries:   make(map[string]*common.RegistrationEntry, len(data.Entries)),
+		Bundles:   make(map[string]*common.Bundle, len(data.Bundles)),
+		X509SVIDs: make(map[string]*cache.X509SVID, len(data.X509SVIDs)),
+	}
+	for _, entryBytes := range data.Entries {
+		entry := new(common.RegistrationEntry)
+		if err := proto.Unmarshal(entryBytes, entry); err != nil {
+			return nil, fmt.Errorf("malformed cache snapshot: invalid registration entry: %w", err)
+		}
+		snapshot.Entries[entry.EntryId] = entry
+	}
+	for _, bundleBytes := range data.Bundles {
+		bundle := new(common.Bundle)
+		if err := proto.Unmarshal(bundleBytes, bundle); err != nil {
+			return nil, fmt.Errorf("malformed cache snapshot: invalid bundle: %w", err)
+		}
+		snapshot.Bundles[bundle.TrustDomainId] = bundle
+	}
+	for _, svidData := range data.X509SVIDs {
+		svid, err := parseX509SVID(svidData)
+		if err != nil {
+			return nil, fmt.Errorf("malformed cache snapshot: invalid X509-SVID for entry %q: %w", svidData.EntryID, err)
+		}
+		if !now.Before(svid.Chain[0].NotAfter) {
+			continue
+		}
+		snapshot.X509SVIDs[svidData.EntryID] = svid
+	}
+	return snapshot, nil
+}
+
+// Remove removes the snapshot from disk, if any.
+func (s *Store) Remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove cache snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) getOrGenerateSealingKey(ctx context.Context) (keymanager.Key, error) {
+	key, err := s.km.GetKey(ctx, SealingKeyID)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		key, err = s.km.GenerateKey(ctx, SealingKeyID, keymanager.RSA2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate cache snapshot sealing key: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get cache snapshot sealing key: %w", err)
+	}
+	return key, nil
+}
+
+// newAEAD returns the AEAD that seals snapshots, keyed with a key derived
+// from a signature of the sealing key and the salt.
+func newAEAD(key keymanager.Key, salt []byte) (cipher.AEAD, error) {
+	if _, ok := key.Public().(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("cache snapshot sealing key %q is not an RSA key", key.ID())
+	}
+	signature, err := key.Sign(rand.Reader, sealingDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive cache snapshot sealing key: %w", err)
+	}
+	aesKey, err := hkdf.Key(sha256.New, signature, salt, sealingKeyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive cache snapshot sealing key: %w", err)
+	}
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the envelope metadata to the ciphertext.
+func additionalData(env envelope) []byte {
+	return fmt.Appendf(nil, "%d|%s|%x|%x", env.Version, env.KeyID, env.Salt, env.Nonce)
+}
+
+func parseX509SVID(data x509SVIDData) (*cache.X509SVID, error) {
+	if len(data.CertChain) == 0 {
+		return nil, errors.New("empty certificate chain")
+	}
+	svid := new(cache.X509SVID)
+	for _, certBytes := range data.CertChain {
+		cert, err := x509.ParseCertificate(certBytes)
+		if err != nil {
+			return nil, err
+		}
+		svid.Chain = append(svid.Chain, cert)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(data.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key is not a signer")
+	}
+	svid.PrivateKey = signer
+	return svid, nil
+}
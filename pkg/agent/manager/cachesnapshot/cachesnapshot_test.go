@@ -0,0 +1,173 @@
+package cachesnapshot_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/manager/cachesnapshot"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakeagentkeymanager"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ctx         = context.Background()
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+	agentID     = spiffeid.RequireFromPath(trustDomain, "/spire/agent/test")
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	now := time.Now()
+	km := fakeagentkeymanager.New(t, "")
+	path := filepath.Join(t.TempDir(), "cache_snapshot.json")
+	store := cachesnapshot.New(path, km)
+
+	_, err := store.Load(ctx, now)
+	require.ErrorIs(t, err, cachesnapshot.ErrNotFound)
+
+	ca := testca.New(t, trustDomain)
+	valid := newX509SVID(ca, "/valid", now.Add(time.Hour))
+	expired := newX509SVID(ca, "/expired", now)
+	snapshot := &cachesnapshot.Snapshot{
+		CreatedAt: now,
+		AgentID:   agentID,
+		Entries: map[string]*common.RegistrationEntry{
+			"VALID":   {EntryId: "VALID", SpiffeId: "spiffe://example.org/valid", RevisionNumber: 1},
+			"EXPIRED": {EntryId: "EXPIRED", SpiffeId: "spiffe://example.org/expired", RevisionNumber: 2},
+		},
+		Bundles: map[string]*common.Bundle{
+			"spiffe://example.org": {TrustDomainId: "spiffe://example.org", RootCas: []*common.Certificate{{DerBytes: ca.X509Authorities()[0].Raw}}},
+		},
+		X509SVIDs: map[string]*cache.X509SVID{
+			"VALID":   valid,
+			"EXPIRED": expired,
+		},
+	}
+	require.NoError(t, store.Save(ctx, snapshot))
+
+	// The sealing key is generated on the first save
+	key, err := km.GetKey(ctx, cachesnapshot.SealingKeyID)
+	require.NoError(t, err)
+	require.NotNil(t, key)
+
+	// The snapshot is not stored in the clear
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "spiffe://example.org/valid")
+
+	loaded, err := store.Load(ctx, now)
+	require.NoError(t, err)
+	require.True(t, now.Equal(loaded.CreatedAt))
+	require.Equal(t, agentID, loaded.AgentID)
+	spiretest.AssertProtoEqual(t, snapshot.Entries["VALID"], loaded.Entries["VALID"])
+	spiretest.AssertProtoEqual(t, snapshot.Entries["EXPIRED"], loaded.Entries["EXPIRED"])
+	spiretest.AssertProtoEqual(t, snapshot.Bundles["spiffe://example.org"], loaded.Bundles["spiffe://example.org"])
+
+	// Expired X509-SVIDs are dropped
+	require.Len(t, loaded.X509SVIDs, 1)
+	require.Equal(t, valid.Chain, loaded.X509SVIDs["VALID"].Chain)
+	require.Equal(t, valid.PrivateKey.Public(), loaded.X509SVIDs["VALID"].PrivateKey.Public())
+
+	// Saving again reuses the sealing key
+	require.NoError(t, store.Save(ctx, snapshot))
+	_, err = store.Load(ctx, now)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Remove())
+	_, err = store.Load(ctx, now)
+	require.ErrorIs(t, err, cachesnapshot.ErrNotFound)
+	require.NoError(t, store.Remove())
+}
+
+func TestLoadFailures(t *testing.T) {
+	now := time.Now()
+	km := fakeagentkeymanager.New(t, "")
+	path := filepath.Join(t.TempDir(), "cache_snapshot.json")
+	store := cachesnapshot.New(path, km)
+	require.NoError(t, store.Save(ctx, &cachesnapshot.Snapshot{CreatedAt: now, AgentID: agentID}))
+
+	sealed, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name   string
+		km     keymanager.KeyManager
+		modify func(env map[string]any)
+		expErr string
+	}{
+		{
+			name:   "sealing key not found",
+			km:     fakeagentkeymanager.New(t, ""),
+			expErr: `cache snapshot sealing key "agent-cache-snapshot" not found in the key manager`,
+		},
+		{
+			name: "sealing key is not an RSA key",
+			km: func() keymanager.KeyManager {
+				other := fakeagentkeymanager.New(t, "")
+				_, err := other.GenerateKey(ctx, cachesnapshot.SealingKeyID, keymanager.ECP256)
+				require.NoError(t, err)
+				return other
+			}(),
+			expErr: `cache snapshot sealing key "agent-cache-snapshot" is not an RSA key`,
+		},
+		{
+			name: "ciphertext tampered",
+			km:   km,
+			modify: func(env map[string]any) {
+				env["ciphertext"] = "AAAA" + env["ciphertext"].(string)[4:]
+			},
+			expErr: "failed to open cache snapshot: message authentication failed",
+		},
+		{
+			name: "salt tampered",
+			km:   km,
+			modify: func(env map[string]any) {
+				env["salt"] = "AAAA" + env["salt"].(string)[4:]
+			},
+			expErr: "failed to open cache snapshot: message authentication failed",
+		},
+		{
+			name: "unsupported version",
+			km:   km,
+			modify: func(env map[string]any) {
+				env["version"] = 2
+			},
+			expErr: "unsupported cache snapshot version 2",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data := sealed
+			if tt.modify != nil {
+				env := make(map[string]any)
+				require.NoError(t, json.Unmarshal(sealed, &env))
+				tt.modify(env)
+				var err error
+				data, err = json.Marshal(env)
+				require.NoError(t, err)
+			}
+			path := filepath.Join(t.TempDir(), "cache_snapshot.json")
+			require.NoError(t, os.WriteFile(path, data, 0600))
+
+			snapshot, err := cachesnapshot.New(path, tt.km).Load(ctx, now)
+			require.EqualError(t, err, tt.expErr)
+			require.Nil(t, snapshot)
+		})
+	}
+}
+
+func newX509SVID(ca *testca.CA, path string, notAfter time.Time) *cache.X509SVID {
+	svid := ca.CreateX509SVID(spiffeid.RequireFromPath(trustDomain, path), testca.WithLifetime(notAfter.Add(-time.Hour), notAfter))
+	return &cache.X509SVID{
+		Chain:      svid.Certificates,
+		PrivateKey: svid.PrivateKey,
+	}
+}
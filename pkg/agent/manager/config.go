@@ -10,6 +10,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent/catalog"
 	managerCache "github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/manager/cachesnapshot"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
@@ -50,6 +51,13 @@ type Config struct {
 	RotationStrategy         *rotationutil.RotationStrategy
 	TLSPolicy                tlspolicy.Policy
 
+	// CacheSnapshotPath is the path to the sealed snapshot of the workload
+	// cache. If empty, the cache is not persisted.
+	CacheSnapshotPath string
+
+	// CacheSnapshotInterval is how often the workload cache snapshot is saved.
+	CacheSnapshotInterval time.Duration
+
 	// Clk is the clock the manager will use to get time
 	Clk clock.Clock
 }
@@ -72,6 +80,10 @@ func newManager(c *Config) *manager {
 		c.Clk = clock.New()
 	}
 
+	if c.CacheSnapshotInterval == 0 {
+		c.CacheSnapshotInterval = defaultCacheSnapshotInterval
+	}
+
 	cache := managerCache.NewLRUCache(c.Log.WithField(telemetry.SubsystemName, telemetry.CacheManager), c.TrustDomain, c.Bundle,
 		c.Metrics, c.X509SVIDCacheMaxSize, c.JWTSVIDCacheMaxSize, c.Clk)
 
@@ -105,6 +117,11 @@ func newManager(c *Config) *manager {
 
 		processedTaintedX509Authorities: make(map[string]struct{}),
 		processedTaintedJWTAuthorities:  make(map[string]struct{}),
+		processedTaintedWITAuthorities:  make(map[string]struct{}),
+	}
+
+	if c.CacheSnapshotPath != "" {
+		m.cacheSnapshot = cachesnapshot.New(c.CacheSnapshotPath, c.Catalog.GetKeyManager())
 	}
 
 	return m
@@ -14,11 +14,13 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/manager/cachesnapshot"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
 	"github.com/spiffe/spire/pkg/agent/storage"
 	"github.com/spiffe/spire/pkg/agent/svid"
 	"github.com/spiffe/spire/pkg/common/backoff"
 	"github.com/spiffe/spire/pkg/common/errorutil"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/rotationutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -37,6 +39,10 @@ const (
 	synchronizeMaxInterval = 8 * time.Minute
 	// default sync interval is used between retries of initial sync
 	defaultSyncInterval = 5 * time.Second
+	// default interval between saves of the cache snapshot
+	defaultCacheSnapshotInterval = time.Minute
+	// timeout to save the cache snapshot when the manager is stopped
+	cacheSnapshotSaveTimeout = 10 * time.Second
 )
 
 // Manager provides cache management functionalities for agents.
@@ -80,6 +86,10 @@ type Manager interface {
 	// is no JWT cached, the manager will get one signed upstream.
 	FetchJWTSVID(ctx context.Context, entry *common.RegistrationEntry, audience []string) (*client.JWTSVID, error)
 
+	// FetchWITSVID returns a WIT SVID for the specified registration entry. If there
+	// is no WIT cached, the manager will get one signed upstream.
+	FetchWITSVID(ctx context.Context, entry *common.RegistrationEntry) (*cache.WITSVID, error)
+
 	// CountX509SVIDs returns the amount of X509 SVIDs on memory
 	CountX509SVIDs() int
 
@@ -137,6 +147,17 @@ type Cache interface {
 	// SetJWTSVID adds JWT-SVID to cache
 	SetJWTSVID(id spiffeid.ID, audience []string, svid *client.JWTSVID)
 
+	// GetWITSVID provides WIT-SVID
+	GetWITSVID(id spiffeid.ID) (*cache.WITSVID, bool)
+
+	// SetWITSVID adds WIT-SVID to cache
+	SetWITSVID(id spiffeid.ID, svid *cache.WITSVID)
+
+	// TaintWITSVIDs removes WIT-SVIDs with tainted authorities from the cache,
+	// forcing the server to issue a new WIT-SVID when one with a tainted
+	// authority is requested.
+	TaintWITSVIDs(ctx context.Context, taintedWITAuthorities map[string]struct{})
+
 	// Entries get all registration entries
 	Entries() []*common.RegistrationEntry
 
@@ -188,6 +209,20 @@ type manager struct {
 	// processedTaintedJWTAuthorities holds all the already processed tainted JWT Authorities
 	// to prevent processing them again.
 	processedTaintedJWTAuthorities map[string]struct{}
+
+	// processedTaintedWITAuthorities holds all the already processed tainted WIT Authorities
+	// to prevent processing them again.
+	processedTaintedWITAuthorities map[string]struct{}
+
+	// cacheSnapshot, if set, persists the workload cache to disk so it can
+	// be restored when the agent is restarted.
+	cacheSnapshot     *cachesnapshot.Store
+	lastCacheSnapshot time.Time
+
+	// These two maps hold onto the entries and bundles of the last update to
+	// include them in the cache snapshot.
+	lastEntries map[string]*common.RegistrationEntry
+	lastBundles map[string]*common.Bundle
 }
 
 func (m *manager) Initialize(ctx context.Context) error {
@@ -202,12 +237,11 @@ func (m *manager) Initialize(ctx context.Context) error {
 	m.csrSizeLimitedBackoff = backoff.NewSizeLimitedBackOff(limits.SignLimitPerIP)
 	m.syncedEntries = make(map[string]*common.RegistrationEntry)
 	m.syncedBundles = make(map[string]*common.Bundle)
+	warmStarted := m.loadCacheSnapshot(ctx)
 
-	// Post agent status with version information to the server
-	if err := m.client.PostStatus(ctx, version.Version()); err != nil {
-		// Log the error but don't fail initialization - the server may not support this yet
-		m.c.Log.WithField(telemetry.AgentVersion, version.Version()).WithError(err).Error("Failed to post agent status")
-	}
+	// Post agent status with version information to the server. Errors are
+	// logged but don't fail initialization - the server may not support this yet
+	m.postStatus(ctx)
 
 	err := m.synchronize(ctx)
 	if nodeutil.ShouldAgentReattest(err) {
@@ -218,6 +252,15 @@ func (m *manager) Initialize(ctx context.Context) error {
 		m.c.Log.WithError(err).Error("Agent is banned: removing SVID and shutting down")
 		m.deleteSVID()
 	}
+	switch {
+	case err == nil:
+		m.saveCacheSnapshot(ctx)
+	case warmStarted && !nodeutil.ShouldAgentReattest(err) && !nodeutil.ShouldAgentShutdown(err) && !x509util.IsUnknownAuthorityError(err):
+		// Serve the Workload API from the cache restored from the snapshot
+		// while the synchronizer keeps trying to reach the server.
+		m.c.Log.WithError(err).Warn("Initial synchronization failed; serving workloads from the cache snapshot")
+		return nil
+	}
 	return err
 }
 
@@ -230,6 +273,7 @@ func (m *manager) Run(ctx context.Context) error {
 			m.runSyncSVIDs,
 			m.runSVIDObserver,
 			m.runBundleObserver,
+			m.runFeatureFlagsObserver,
 			m.svid.Run)
 
 		switch {
@@ -343,17 +387,85 @@ func (m *manager) FetchJWTSVID(ctx context.Context, entry *common.RegistrationEn
 	return newSVID, nil
 }
 
+func (m *manager) FetchWITSVID(ctx context.Context, entry *common.RegistrationEntry) (*cache.WITSVID, error) {
+	spiffeID, err := spiffeid.FromString(entry.SpiffeId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID: %w", err)
+	}
+
+	now := m.clk.Now()
+	cachedSVID, ok := m.cache.GetWITSVID(spiffeID)
+	if ok && !m.c.RotationStrategy.WITSVIDExpiresSoon(cachedSVID.SVID, now) {
+		return cachedSVID, nil
+	}
+
+	// Determine if an unexpired WIT-SVID exists in the cache to pass
+	// to NewWITSVID method. If this is true, we'll fall back to the
+	// cache hit more quickly rather than wait longer for the Server
+	isCacheHit := ok && !rotationutil.WITSVIDExpired(cachedSVID.SVID, now)
+
+	newSVID, err := m.newWITSVID(ctx, entry, isCacheHit)
+	switch {
+	case err == nil:
+	case cachedSVID == nil:
+		return nil, err
+	case rotationutil.WITSVIDExpired(cachedSVID.SVID, now):
+		return nil, fmt.Errorf("unable to renew WIT for %q (err=%w)", spiffeID, err)
+	default:
+		m.c.Log.WithError(err).WithField(telemetry.SPIFFEID, spiffeID).Warn("Unable to renew WIT; returning cached copy")
+		return cachedSVID, nil
+	}
+
+	m.cache.SetWITSVID(spiffeID, newSVID)
+	return newSVID, nil
+}
+
+func (m *manager) newWITSVID(ctx context.Context, entry *common.RegistrationEntry, isCacheHit bool) (*cache.WITSVID, error) {
+	signingAlgorithm, err := m.c.WorkloadKeyType.WITSigningAlgorithm()
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := m.c.WorkloadKeyType.GenerateSigner()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate WIT-SVID key: %w", err)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal WIT-SVID public key: %w", err)
+	}
+
+	svid, _, err := m.client.NewWITSVID(ctx, entry.EntryId, publicKey, signingAlgorithm, isCacheHit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache.WITSVID{
+		SVID:       svid,
+		PrivateKey: privateKey,
+	}, nil
+}
+
 func (m *manager) runSynchronizer(ctx context.Context) error {
 	syncInterval := min(m.synchronizeBackoff.NextBackOff(), defaultSyncInterval)
 	for {
 		select {
 		case <-m.clk.After(syncInterval):
 		case <-ctx.Done():
+			// Save the latest state of the cache on the way out, so the
+			// agent can be restarted with it.
+			saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheSnapshotSaveTimeout)
+			defer cancel()
+			m.saveCacheSnapshot(saveCtx)
 			return nil
 		}
 
 		err := m.synchronize(ctx)
 		if err == nil {
+			if m.clk.Now().Sub(m.lastCacheSnapshot) >= m.c.CacheSnapshotInterval {
+				m.saveCacheSnapshot(ctx)
+			}
 			err = m.c.TrustBundleSources.SetSuccessIfRunning()
 			if err != nil {
 				return err
@@ -479,6 +591,28 @@ func (m *manager) runBundleObserver(ctx context.Context) error {
 	}
 }
 
+// runFeatureFlagsObserver posts the agent status again when the feature flags
+// are reloaded, so the server reports the flags currently enabled.
+func (m *manager) runFeatureFlagsObserver(ctx context.Context) error {
+	changed, unsubscribe := fflag.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+			m.postStatus(ctx)
+		}
+	}
+}
+
+func (m *manager) postStatus(ctx context.Context) {
+	if err := m.client.PostStatus(ctx, version.Version()); err != nil {
+		m.c.Log.WithField(telemetry.AgentVersion, version.Version()).WithError(err).Error("Failed to post agent status")
+	}
+}
+
 func (m *manager) storeSVID(svidChain []*x509.Certificate, reattestable bool) {
 	if err := m.storage.StoreSVID(svidChain, reattestable); err != nil {
 		m.c.Log.WithError(err).Warn("Could not store SVID")
@@ -499,4 +633,10 @@ func (m *manager) deleteSVID() {
 	if err := m.storage.DeleteSVID(); err != nil {
 		m.c.Log.WithError(err).Error("Failed to remove SVID")
 	}
+	// The cached SVIDs were issued to the agent, so they go along with it.
+	if m.cacheSnapshot != nil {
+		if err := m.cacheSnapshot.Remove(); err != nil {
+			m.c.Log.WithError(err).Error("Failed to remove cache snapshot")
+		}
+	}
 }
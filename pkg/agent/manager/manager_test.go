@@ -9,12 +9,15 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
 	"github.com/sirupsen/logrus"
 	testlog "github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
@@ -297,6 +300,90 @@ func TestHappyPathWithoutSyncNorRotation(t *testing.T) {
 	})
 }
 
+func TestCacheSnapshotWarmStart(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	var serverDown atomic.Bool
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			if serverDown.Load() {
+				return nil, errors.New("server is down")
+			}
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	snapshotPath := filepath.Join(dir, "cache_snapshot.json")
+	newConfig := func() *Config {
+		return &Config{
+			ServerAddr:        api.addr,
+			SVID:              baseSVID,
+			SVIDKey:           baseSVIDKey,
+			Log:               testLogger,
+			TrustDomain:       trustDomain,
+			Storage:           openStorage(t, dir),
+			WorkloadKeyType:   workloadkey.ECP256,
+			Bundle:            api.bundle,
+			Metrics:           &telemetry.Blackhole{},
+			Clk:               clk,
+			Catalog:           cat,
+			SVIDStoreCache:    storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+			RotationStrategy:  rotationutil.NewRotationStrategy(0),
+			CacheSnapshotPath: snapshotPath,
+		}
+	}
+
+	// The snapshot is saved once the cache is synchronized
+	m := initializeNewManager(t, newConfig())
+	require.FileExists(t, snapshotPath)
+	identities := m.cache.Identities()
+	require.Len(t, identities, 3)
+	batchNewX509SVIDCount := api.batchNewX509SVIDCount.Load()
+
+	// A restarted manager serves the cached SVIDs while the server is down
+	serverDown.Store(true)
+	m = initializeNewManager(t, newConfig())
+	requireSameIdentities(t, identities, m.cache.Identities())
+	require.Equal(t, batchNewX509SVIDCount, api.batchNewX509SVIDCount.Load())
+
+	// The cached SVIDs are not minted again once the server is back
+	serverDown.Store(false)
+	require.NoError(t, m.synchronize(context.Background()))
+	requireSameIdentities(t, identities, m.cache.Identities())
+	require.Equal(t, batchNewX509SVIDCount, api.batchNewX509SVIDCount.Load())
+
+	// The snapshot is removed along with the agent SVID
+	m.deleteSVID()
+	require.NoFileExists(t, snapshotPath)
+
+	// Without a snapshot, the manager cannot be initialized while the server
+	// is down
+	serverDown.Store(true)
+	require.Error(t, newManager(newConfig()).Initialize(context.Background()))
+}
+
+func requireSameIdentities(t *testing.T, expected, actual []cache.Identity) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].Entry.EntryId, actual[i].Entry.EntryId)
+		require.Equal(t, expected[i].SVID, actual[i].SVID)
+		require.Equal(t, expected[i].PrivateKey.Public(), actual[i].PrivateKey.Public())
+	}
+}
+
 func TestX509PrefetchDisabled(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	km := fakeagentkeymanager.New(t, dir)
@@ -1701,6 +1788,118 @@ func TestFetchJWTSVID(t *testing.T) {
 	require.Nil(t, svid)
 }
 
+func TestFetchWITSVID(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	var fetchResult *svidv1.BatchNewWITSVIDResponse_Result
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		batchNewWITSVID: func(_ *mockAPI, req *svidv1.BatchNewWITSVIDRequest) (*svidv1.BatchNewWITSVIDResponse, error) {
+			if fetchResult == nil {
+				return nil, errors.New("no WIT-SVID available")
+			}
+			require.Len(t, req.Params, 1)
+			require.Equal(t, "ES256", req.Params[0].SigningAlgorithm)
+			return &svidv1.BatchNewWITSVIDResponse{
+				Results: []*svidv1.BatchNewWITSVIDResponse_Result{fetchResult},
+			}, nil
+		},
+		clk:     clk,
+		svidTTL: 200,
+	})
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		Storage:          openStorage(t, dir),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		Catalog:          cat,
+		Clk:              clk,
+		WorkloadKeyType:  workloadkey.ECP256,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		RotationStrategy: rotationutil.NewRotationStrategy(0),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	testEntry := regEntriesMap["resp2"][0]
+	testEntrySPIFFEId, err := idutil.IDProtoFromString(testEntry.SpiffeId)
+	require.NoError(t, err)
+
+	newResult := func(token string, now time.Time) *svidv1.BatchNewWITSVIDResponse_Result {
+		return &svidv1.BatchNewWITSVIDResponse_Result{
+			Status: &types.Status{Code: int32(codes.OK)},
+			Svid: &types.WITSVID{
+				Token:     token,
+				Id:        testEntrySPIFFEId,
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(time.Minute).Unix(),
+			},
+		}
+	}
+
+	// nothing in cache, fetch fails
+	svid, err := m.FetchWITSVID(context.Background(), testEntry)
+	require.Error(t, err)
+	require.Nil(t, svid)
+
+	// fetch succeeds
+	fetchResult = newResult("A", clk.Now())
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, "A", svid.SVID.Token)
+	require.NotNil(t, svid.PrivateKey)
+	keyA := svid.PrivateKey
+
+	// assert cached WIT is returned w/o trying to fetch (since cached version does not expire soon)
+	fetchResult = newResult("B", clk.Now())
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, "A", svid.SVID.Token)
+	require.Equal(t, keyA, svid.PrivateKey)
+
+	// expire the cached WIT soon and make sure a new WIT is fetched with a new key
+	clk.Add(time.Second * 45)
+	fetchResult = newResult("C", clk.Now())
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, "C", svid.SVID.Token)
+	require.NotEqual(t, keyA, svid.PrivateKey)
+
+	// expire the WIT soon, fail the fetch, and make sure cached WIT is returned
+	clk.Add(time.Second * 30)
+	fetchResult = nil
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, "C", svid.SVID.Token)
+
+	// now completely expire the WIT and make sure an error is returned, since
+	// the fetch fails and the cached version is expired.
+	clk.Add(time.Second * 30)
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.Error(t, err)
+	require.Nil(t, svid)
+}
+
 func TestFetchJWTSVIDWithJTIBypassesCache(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	km := fakeagentkeymanager.New(t, dir)
@@ -1879,6 +2078,115 @@ func TestStorableSVIDsSync(t *testing.T) {
 	validateResponse(records, entries)
 }
 
+func TestTaintWITSVIDs(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	witKey := testkey.NewEC256(t)
+	var token string
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		batchNewWITSVID: func(_ *mockAPI, req *svidv1.BatchNewWITSVIDRequest) (*svidv1.BatchNewWITSVIDResponse, error) {
+			entry := regEntriesMap["resp2"][0]
+			id, err := idutil.IDProtoFromString(entry.SpiffeId)
+			require.NoError(t, err)
+			return &svidv1.BatchNewWITSVIDResponse{
+				Results: []*svidv1.BatchNewWITSVIDResponse_Result{
+					{
+						Status: &types.Status{Code: int32(codes.OK)},
+						Svid: &types.WITSVID{
+							Token:     token,
+							Id:        id,
+							IssuedAt:  clk.Now().Unix(),
+							ExpiresAt: clk.Now().Add(time.Hour).Unix(),
+						},
+					},
+				},
+			}, nil
+		},
+		clk:     clk,
+		svidTTL: 200,
+	})
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		Storage:          openStorage(t, dir),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		Catalog:          cat,
+		Clk:              clk,
+		WorkloadKeyType:  workloadkey.ECP256,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		RotationStrategy: rotationutil.NewRotationStrategy(0),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	testEntry := regEntriesMap["resp2"][0]
+
+	taintedToken := signWIT(t, witKey, "wit-key-1")
+	token = taintedToken
+	svid, err := m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, taintedToken, svid.SVID.Token)
+
+	// The cached WIT-SVID is returned while its authority is not tainted
+	token = signWIT(t, witKey, "wit-key-2")
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, taintedToken, svid.SVID.Token)
+
+	// Taint the authority and synchronize. The cached WIT-SVID is evicted, so
+	// a new one is fetched.
+	witKeyBytes, err := x509.MarshalPKIXPublicKey(witKey.Public())
+	require.NoError(t, err)
+	api.taintedWITAuthority = &common.PublicKey{
+		PkixBytes:  witKeyBytes,
+		Kid:        "wit-key-1",
+		NotAfter:   clk.Now().Add(time.Hour).Unix(),
+		TaintedKey: true,
+	}
+	require.NoError(t, m.synchronize(context.Background()))
+	require.Equal(t, map[string]struct{}{"wit-key-1": {}}, m.processedTaintedWITAuthorities)
+
+	svid, err = m.FetchWITSVID(context.Background(), testEntry)
+	require.NoError(t, err)
+	require.Equal(t, token, svid.SVID.Token)
+}
+
+func signWIT(t *testing.T, key crypto.Signer, keyID string) string {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{
+			Algorithm: jose.ES256,
+			Key:       jose.JSONWebKey{Key: key, KeyID: keyID},
+		},
+		new(jose.SignerOptions).WithType("wit+jwt"),
+	)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "spiffe://example.org/workload"}).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
 func makeGetAuthorizedEntriesResponse(t *testing.T, respKeys ...string) *entryv1.GetAuthorizedEntriesResponse {
 	var entries []*types.Entry
 	for _, respKey := range respKeys {
@@ -1953,6 +2261,7 @@ type mockAPIConfig struct {
 	getAuthorizedEntries    func(api *mockAPI, count int32, req *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error)
 	batchNewX509SVIDEntries func(api *mockAPI, count int32) []*common.RegistrationEntry
 	newJWTSVID              func(api *mockAPI, req *svidv1.NewJWTSVIDRequest) (*svidv1.NewJWTSVIDResponse, error)
+	batchNewWITSVID         func(api *mockAPI, req *svidv1.BatchNewWITSVIDRequest) (*svidv1.BatchNewWITSVIDResponse, error)
 
 	svidTTL int
 	clk     clock.Clock
@@ -1979,6 +2288,9 @@ type mockAPI struct {
 
 	taintedX509Authority *x509.Certificate
 
+	// WIT authority included in the bundle as tainted, if set
+	taintedWITAuthority *common.PublicKey
+
 	clk clock.Clock
 
 	// Add latest's SVIDs per entry, to verify returned SVIDs are valid
@@ -2102,6 +2414,13 @@ func (h *mockAPI) NewJWTSVID(_ context.Context, req *svidv1.NewJWTSVIDRequest) (
 	return nil, errors.New("no FetchJWTSVID implementation for test")
 }
 
+func (h *mockAPI) BatchNewWITSVID(_ context.Context, req *svidv1.BatchNewWITSVIDRequest) (*svidv1.BatchNewWITSVIDResponse, error) {
+	if h.c.batchNewWITSVID != nil {
+		return h.c.batchNewWITSVID(h, req)
+	}
+	return nil, errors.New("no BatchNewWITSVID implementation for test")
+}
+
 func (h *mockAPI) GetBundle(context.Context, *bundlev1.GetBundleRequest) (*types.Bundle, error) {
 	bundle := bundleutil.BundleProtoFromRootCAs(h.bundle.TrustDomain().IDString(), h.bundle.X509Authorities())
 	if h.taintedX509Authority != nil {
@@ -2111,6 +2430,9 @@ func (h *mockAPI) GetBundle(context.Context, *bundlev1.GetBundleRequest) (*types
 			}
 		}
 	}
+	if h.taintedWITAuthority != nil {
+		bundle.WitSigningKeys = append(bundle.WitSigningKeys, h.taintedWITAuthority)
+	}
 
 	return api.BundleToProto(bundle)
 }
@@ -54,7 +54,7 @@ func (m *manager) syncSVIDs(ctx context.Context) (err error) {
 }
 
 // processTaintedAuthorities verifies if a new authority is tainted and forces rotation in all caches if required.
-func (m *manager) processTaintedAuthorities(ctx context.Context, bundle *spiffebundle.Bundle, x509Authorities []string, jwtAuthorities map[string]struct{}) error {
+func (m *manager) processTaintedAuthorities(ctx context.Context, bundle *spiffebundle.Bundle, x509Authorities []string, jwtAuthorities, witAuthorities map[string]struct{}) error {
 	newTaintedX509Authorities := getNewItemsFromSlice(m.processedTaintedX509Authorities, x509Authorities)
 	if len(newTaintedX509Authorities) > 0 {
 		m.c.Log.WithField(telemetry.SubjectKeyIDs, strings.Join(newTaintedX509Authorities, ",")).
@@ -94,19 +94,35 @@ func (m *manager) processTaintedAuthorities(ctx context.Context, bundle *spiffeb
 		}
 	}
 
+	newTaintedWITAuthorities := getNewItemsFromMap(m.processedTaintedWITAuthorities, witAuthorities)
+	if len(newTaintedWITAuthorities) > 0 {
+		m.c.Log.WithField(telemetry.WITAuthorityKeyIDs, strings.Join(newTaintedWITAuthorities, ",")).
+			Debug("New tainted WIT authorities found")
+
+		// Taint WIT-SVIDs in the cache
+		m.cache.TaintWITSVIDs(ctx, witAuthorities)
+
+		for _, keyID := range newTaintedWITAuthorities {
+			m.processedTaintedWITAuthorities[keyID] = struct{}{}
+		}
+	}
+
 	return nil
 }
 
 // synchronize fetches the authorized entries from the server, updates the
 // cache, and fetches missing/expiring SVIDs.
 func (m *manager) synchronize(ctx context.Context) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "agent.sync")
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	cacheUpdate, storeUpdate, err := m.fetchEntries(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Process all tainted authorities. The bundle is shared between both caches using regular cache data.
-	if err := m.processTaintedAuthorities(ctx, cacheUpdate.Bundles[m.c.TrustDomain], cacheUpdate.TaintedX509Authorities, cacheUpdate.TaintedJWTAuthorities); err != nil {
+	if err := m.processTaintedAuthorities(ctx, cacheUpdate.Bundles[m.c.TrustDomain], cacheUpdate.TaintedX509Authorities, cacheUpdate.TaintedJWTAuthorities, cacheUpdate.TaintedWITAuthorities); err != nil {
 		return err
 	}
 
@@ -315,10 +331,13 @@ func (m *manager) fetchEntries(ctx context.Context) (_ *cache.UpdateEntries, _ *
 	if err != nil {
 		return nil, nil, err
 	}
+	m.lastEntries = update.Entries
+	m.lastBundles = update.Bundles
 
 	// Get all Subject Key IDs and KeyIDs of tainted authorities
 	var taintedX509Authorities []string
 	taintedJWTAuthorities := make(map[string]struct{})
+	taintedWITAuthorities := make(map[string]struct{})
 	if b, ok := update.Bundles[m.c.TrustDomain.IDString()]; ok {
 		for _, rootCA := range b.RootCas {
 			if rootCA.TaintedKey {
@@ -335,6 +354,11 @@ func (m *manager) fetchEntries(ctx context.Context) (_ *cache.UpdateEntries, _ *
 				taintedJWTAuthorities[jwtKey.Kid] = struct{}{}
 			}
 		}
+		for _, witKey := range b.WitSigningKeys {
+			if witKey.TaintedKey {
+				taintedWITAuthorities[witKey.Kid] = struct{}{}
+			}
+		}
 	}
 
 	cacheEntries := make(map[string]*common.RegistrationEntry)
@@ -353,11 +377,13 @@ func (m *manager) fetchEntries(ctx context.Context) (_ *cache.UpdateEntries, _ *
 			Bundles:                bundles,
 			RegistrationEntries:    cacheEntries,
 			TaintedJWTAuthorities:  taintedJWTAuthorities,
+			TaintedWITAuthorities:  taintedWITAuthorities,
 			TaintedX509Authorities: taintedX509Authorities,
 		}, &cache.UpdateEntries{
 			Bundles:                bundles,
 			RegistrationEntries:    storeEntries,
 			TaintedJWTAuthorities:  taintedJWTAuthorities,
+			TaintedWITAuthorities:  taintedWITAuthorities,
 			TaintedX509Authorities: taintedX509Authorities,
 		}, nil
 }
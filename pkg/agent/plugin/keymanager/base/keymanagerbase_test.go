@@ -1,9 +1,15 @@
 package keymanagerbase
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
 	"testing"
 
+	keymanagerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/keymanager/v1"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSetsConfigDefaults(t *testing.T) {
@@ -12,3 +18,39 @@ func TestNewSetsConfigDefaults(t *testing.T) {
 	assert.Equal(t, defaultGenerator{}, b.config.Generator)
 	assert.Nil(t, b.config.WriteEntries)
 }
+
+func TestMakeKeyEntryFromSigner(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		signer     crypto.Signer
+		expectType keymanagerv1.KeyType
+		expectErr  string
+	}{
+		{name: "EC P-256", signer: testkey.NewEC256(t), expectType: keymanagerv1.KeyType_EC_P256},
+		{name: "EC P-384", signer: testkey.NewEC384(t), expectType: keymanagerv1.KeyType_EC_P384},
+		{name: "RSA 2048", signer: testkey.NewRSA2048(t), expectType: keymanagerv1.KeyType_RSA_2048},
+		{name: "RSA 4096", signer: testkey.NewRSA4096(t), expectType: keymanagerv1.KeyType_RSA_4096},
+		{
+			name:      "unsupported key type",
+			signer:    ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+			expectErr: `unexpected public key type ed25519.PublicKey for key "id"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := MakeKeyEntryFromSigner("id", tt.signer)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			pkixData, err := x509.MarshalPKIXPublicKey(tt.signer.Public())
+			require.NoError(t, err)
+			require.Equal(t, tt.signer, entry.PrivateKey)
+			require.Equal(t, "id", entry.Id)
+			require.Equal(t, tt.expectType, entry.Type)
+			require.Equal(t, pkixData, entry.PkixData)
+			require.Equal(t, makeFingerprint(pkixData), entry.Fingerprint)
+		})
+	}
+}
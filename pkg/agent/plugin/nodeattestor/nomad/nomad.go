@@ -0,0 +1,171 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/nomad"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = nomad.PluginName
+
+	// defaultTokenFile is the file that Nomad writes the workload identity
+	// named "spire" to, in the secrets directory of the task.
+	defaultTokenFile = "nomad_spire.jwt" //nolint: gosec // false positive
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *AttestorPlugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// New creates a new Nomad attestor plugin
+func New() *AttestorPlugin {
+	return &AttestorPlugin{
+		getenv: os.Getenv,
+	}
+}
+
+// AttestorPlugin is a Nomad workload identity attestor plugin
+type AttestorPlugin struct {
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	// Used by tests to fake the task environment
+	getenv func(string) string
+
+	mu     sync.RWMutex
+	config *attestorConfig
+}
+
+// AttestorConfig holds configuration for AttestorPlugin
+type AttestorConfig struct {
+	// Cluster name where the agent lives
+	Cluster string `hcl:"cluster"`
+	// File path of the workload identity
+	TokenPath string `hcl:"token_path"`
+}
+
+type attestorConfig struct {
+	cluster   string
+	tokenPath string
+}
+
+func (p *AttestorPlugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *attestorConfig {
+	hclConfig := new(AttestorConfig)
+	if err := hcl.Decode(hclConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if hclConfig.Cluster == "" {
+		status.ReportError("missing required cluster block")
+	}
+
+	newConfig := &attestorConfig{
+		cluster:   hclConfig.Cluster,
+		tokenPath: hclConfig.TokenPath,
+	}
+
+	if newConfig.tokenPath == "" {
+		// Nomad exposes the secrets directory of the task through the
+		// NOMAD_SECRETS_DIR environment variable.
+		secretsDir := p.getenv("NOMAD_SECRETS_DIR")
+		if secretsDir == "" {
+			status.ReportError("token_path is required when not running as a Nomad task")
+		}
+		newConfig.tokenPath = filepath.Join(secretsDir, defaultTokenFile)
+	}
+
+	return newConfig
+}
+
+// AidAttestation loads the workload identity from the configured path
+func (p *AttestorPlugin) AidAttestation(stream nodeattestorv1.NodeAttestor_AidAttestationServer) error {
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	// The token is read on every attestation since Nomad renews the
+	// workload identity before it expires.
+	token, err := loadTokenFromFile(config.tokenPath)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to load token from %s: %v", config.tokenPath, err)
+	}
+
+	payload, err := json.Marshal(nomad.AttestationData{
+		Cluster: config.cluster,
+		Token:   token,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to marshal attestation data: %v", err)
+	}
+
+	return stream.Send(&nodeattestorv1.PayloadOrChallengeResponse{
+		Data: &nodeattestorv1.PayloadOrChallengeResponse_Payload{
+			Payload: payload,
+		},
+	})
+}
+
+// Configure decodes JSON config from request and populates AttestorPlugin with it
+func (p *AttestorPlugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, p.buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *AttestorPlugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, p.buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *AttestorPlugin) getConfig() (*attestorConfig, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func loadTokenFromFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("%q is empty", path)
+	}
+	return string(data), nil
+}
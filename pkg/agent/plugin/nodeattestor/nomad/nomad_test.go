@@ -0,0 +1,180 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
+	nodeattestortest "github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/test"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testToken = "header.payload.signature"
+)
+
+var (
+	streamBuilder = nodeattestortest.ServerStream(pluginName)
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		config        func(dir string) string
+		secretsDir    bool
+		token         *string
+		expectPayload []byte
+		expectCode    codes.Code
+		expectMsg     string
+	}{
+		{
+			name: "token from configured path",
+			config: func(dir string) string {
+				return fmt.Sprintf(`
+					cluster = "production"
+					token_path = %q
+				`, filepath.Join(dir, "token"))
+			},
+			token:         ptr(testToken),
+			expectPayload: fmt.Appendf(nil, `{"cluster":"production","token":%q}`, testToken),
+		},
+		{
+			name: "token from secrets directory",
+			config: func(string) string {
+				return `cluster = "production"`
+			},
+			secretsDir:    true,
+			token:         ptr(testToken),
+			expectPayload: fmt.Appendf(nil, `{"cluster":"production","token":%q}`, testToken),
+		},
+		{
+			name: "missing token",
+			config: func(dir string) string {
+				return fmt.Sprintf(`
+					cluster = "production"
+					token_path = %q
+				`, filepath.Join(dir, "token"))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): unable to load token from",
+		},
+		{
+			name: "empty token",
+			config: func(dir string) string {
+				return fmt.Sprintf(`
+					cluster = "production"
+					token_path = %q
+				`, filepath.Join(dir, "token"))
+			},
+			token:      ptr(""),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): unable to load token from",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := spiretest.TempDir(t)
+			tokenPath := filepath.Join(dir, "token")
+			env := map[string]string{}
+			if tt.secretsDir {
+				env["NOMAD_SECRETS_DIR"] = dir
+				tokenPath = filepath.Join(dir, defaultTokenFile)
+			}
+			if tt.token != nil {
+				require.NoError(t, os.WriteFile(tokenPath, []byte(*tt.token), 0o600))
+			}
+
+			na := loadPlugin(t, env, plugintest.Configure(tt.config(dir)))
+
+			stream := streamBuilder.Build()
+			if tt.expectPayload != nil {
+				stream = streamBuilder.ExpectAndBuild(tt.expectPayload)
+			}
+			err := na.Attest(context.Background(), stream)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func TestAttestNotConfigured(t *testing.T) {
+	na := new(nodeattestor.V1)
+	plugintest.Load(t, BuiltIn(), na)
+	err := na.Attest(context.Background(), streamBuilder.Build())
+	spiretest.RequireGRPCStatusContains(t, err, codes.FailedPrecondition, "nodeattestor(nomad): not configured")
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		config     string
+		env        map[string]string
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			config:     "malformed",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name:       "missing cluster",
+			config:     `token_path = "/secrets/token"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "missing required cluster block",
+		},
+		{
+			name:       "missing token path outside of a Nomad task",
+			config:     `cluster = "production"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "token_path is required when not running as a Nomad task",
+		},
+		{
+			name:   "token path from secrets directory",
+			config: `cluster = "production"`,
+			env:    map[string]string{"NOMAD_SECRETS_DIR": "/secrets"},
+		},
+		{
+			name: "success",
+			config: `
+				cluster = "production"
+				token_path = "/secrets/token"
+			`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			loadPlugin(t, tt.env,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.Configure(tt.config),
+			)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, env map[string]string, options ...plugintest.Option) nodeattestor.NodeAttestor {
+	p := New()
+	p.getenv = func(key string) string {
+		return env[key]
+	}
+
+	na := new(nodeattestor.V1)
+	plugintest.Load(t, builtin(p), na, append([]plugintest.Option{
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+	}, options...)...)
+	return na
+}
+
+func ptr(s string) *string {
+	return &s
+}
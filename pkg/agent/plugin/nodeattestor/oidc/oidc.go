@@ -0,0 +1,175 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/oidc"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = oidc.PluginName
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// New creates a new OIDC attestor plugin
+func New() *Plugin {
+	return &Plugin{
+		getenv: os.Getenv,
+	}
+}
+
+// Plugin is an agent plugin that attests using an OIDC ID token
+type Plugin struct {
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	// Used by tests to fake the environment
+	getenv func(string) string
+
+	mu     sync.RWMutex
+	config *pluginConfig
+}
+
+// Config holds configuration for Plugin
+type Config struct {
+	// File path of the token
+	TokenPath string `hcl:"token_path"`
+	// Environment variable holding the token
+	TokenEnv string `hcl:"token_env"`
+}
+
+type pluginConfig struct {
+	tokenPath string
+	tokenEnv  string
+}
+
+func (p *Plugin) buildConfig(_ catalog.CoreConfig, hclText string, status *pluginconf.Status) *pluginConfig {
+	hclConfig := new(Config)
+	if err := hcl.Decode(hclConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	switch {
+	case hclConfig.TokenPath == "" && hclConfig.TokenEnv == "":
+		status.ReportError("either token_path or token_env must be configured")
+	case hclConfig.TokenPath != "" && hclConfig.TokenEnv != "":
+		status.ReportError("token_path and token_env are mutually exclusive")
+	}
+
+	return &pluginConfig{
+		tokenPath: hclConfig.TokenPath,
+		tokenEnv:  hclConfig.TokenEnv,
+	}
+}
+
+// AidAttestation loads the token and sends it to the server
+func (p *Plugin) AidAttestation(stream nodeattestorv1.NodeAttestor_AidAttestationServer) error {
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	// The token is loaded on every attestation since issuers usually hand
+	// out short-lived tokens that are renewed by the platform.
+	token, err := p.loadToken(config)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(oidc.AttestationData{
+		Token: token,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to marshal attestation data: %v", err)
+	}
+
+	return stream.Send(&nodeattestorv1.PayloadOrChallengeResponse{
+		Data: &nodeattestorv1.PayloadOrChallengeResponse_Payload{
+			Payload: payload,
+		},
+	})
+}
+
+// Configure decodes JSON config from request and populates Plugin with it
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, p.buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, p.buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *Plugin) getConfig() (*pluginConfig, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (p *Plugin) loadToken(config *pluginConfig) (string, error) {
+	if config.tokenEnv != "" {
+		token := strings.TrimSpace(p.getenv(config.tokenEnv))
+		if token == "" {
+			return "", status.Errorf(codes.InvalidArgument, "environment variable %s is empty or not set", config.tokenEnv)
+		}
+		return token, nil
+	}
+
+	token, err := loadTokenFromFile(config.tokenPath)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "unable to load token from %s: %v", config.tokenPath, err)
+	}
+	return token, nil
+}
+
+func loadTokenFromFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	// Tokens are commonly written with a trailing newline
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%q is empty", path)
+	}
+	return token, nil
+}
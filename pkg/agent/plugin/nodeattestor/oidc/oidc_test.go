@@ -0,0 +1,165 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
+	nodeattestortest "github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/test"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testToken = "header.payload.signature"
+)
+
+var (
+	streamBuilder = nodeattestortest.ServerStream(pluginName)
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		config        func(dir string) string
+		env           map[string]string
+		token         *string
+		expectPayload []byte
+		expectCode    codes.Code
+		expectMsg     string
+	}{
+		{
+			name: "token from file",
+			config: func(dir string) string {
+				return fmt.Sprintf(`token_path = %q`, filepath.Join(dir, "token"))
+			},
+			token:         ptr(testToken + "\n"),
+			expectPayload: fmt.Appendf(nil, `{"token":%q}`, testToken),
+		},
+		{
+			name: "missing token file",
+			config: func(dir string) string {
+				return fmt.Sprintf(`token_path = %q`, filepath.Join(dir, "token"))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): unable to load token from",
+		},
+		{
+			name: "empty token file",
+			config: func(dir string) string {
+				return fmt.Sprintf(`token_path = %q`, filepath.Join(dir, "token"))
+			},
+			token:      ptr("\n"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): unable to load token from",
+		},
+		{
+			name: "token from environment",
+			config: func(string) string {
+				return `token_env = "CI_JOB_JWT"`
+			},
+			env:           map[string]string{"CI_JOB_JWT": testToken},
+			expectPayload: fmt.Appendf(nil, `{"token":%q}`, testToken),
+		},
+		{
+			name: "environment variable not set",
+			config: func(string) string {
+				return `token_env = "CI_JOB_JWT"`
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): environment variable CI_JOB_JWT is empty or not set",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := spiretest.TempDir(t)
+			if tt.token != nil {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte(*tt.token), 0o600))
+			}
+
+			na := loadPlugin(t, tt.env, plugintest.Configure(tt.config(dir)))
+
+			stream := streamBuilder.Build()
+			if tt.expectPayload != nil {
+				stream = streamBuilder.ExpectAndBuild(tt.expectPayload)
+			}
+			err := na.Attest(context.Background(), stream)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func TestAttestNotConfigured(t *testing.T) {
+	na := new(nodeattestor.V1)
+	plugintest.Load(t, BuiltIn(), na)
+	err := na.Attest(context.Background(), streamBuilder.Build())
+	spiretest.RequireGRPCStatusContains(t, err, codes.FailedPrecondition, "nodeattestor(oidc): not configured")
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		config     string
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			config:     "malformed",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name:       "missing token source",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "either token_path or token_env must be configured",
+		},
+		{
+			name: "both token sources",
+			config: `
+				token_path = "/run/secrets/token"
+				token_env = "CI_JOB_JWT"
+			`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "token_path and token_env are mutually exclusive",
+		},
+		{
+			name:   "success",
+			config: `token_path = "/run/secrets/token"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			loadPlugin(t, nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.Configure(tt.config),
+			)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, env map[string]string, options ...plugintest.Option) nodeattestor.NodeAttestor {
+	p := New()
+	p.getenv = func(key string) string {
+		return env[key]
+	}
+
+	na := new(nodeattestor.V1)
+	plugintest.Load(t, builtin(p), na, append([]plugintest.Option{
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+	}, options...)...)
+	return na
+}
+
+func ptr(s string) *string {
+	return &s
+}
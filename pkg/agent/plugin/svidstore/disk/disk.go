@@ -0,0 +1,11 @@
+package disk
+
+import "github.com/spiffe/spire/pkg/common/catalog"
+
+const (
+	pluginName = "disk"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
@@ -0,0 +1,668 @@
+//go:build !windows
+
+package disk
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	svidstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/svidstore/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	formatPEM    = "pem"
+	formatPKCS12 = "pkcs12"
+
+	defaultMode   = 0600
+	defaultSignal = "SIGHUP"
+
+	// File names match the ones used by spiffe-helper, so workloads can be
+	// moved to the plugin without changing their configuration.
+	svidFileName              = "svid.pem"
+	svidKeyFileName           = "svid_key.pem"
+	bundleFileName            = "svid_bundle.pem"
+	federatedBundleFilePrefix = "federated_bundle_"
+	pkcs12SVIDFileName        = "svid.p12"
+	pkcs12BundleFileName      = "svid_bundle.p12"
+
+	hookTimeout = 30 * time.Second
+)
+
+// nameRE restricts names to a single path element that cannot collide with
+// the hidden directories and links that the plugin creates.
+var nameRE = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		svidstorev1.SVIDStorePluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+type HookConfig struct {
+	// Command is executed, without a shell, after the files are written.
+	Command []string `hcl:"command" json:"command"`
+
+	// PIDFile is the path to a file that holds the PID of the process that
+	// is signaled after the files are written.
+	PIDFile string `hcl:"pid_file" json:"pid_file"`
+
+	// Signal is the name of the signal sent to the process. Defaults to SIGHUP.
+	Signal string `hcl:"signal" json:"signal"`
+}
+
+type Configuration struct {
+	Directory          string                 `hcl:"directory" json:"directory"`
+	Format             string                 `hcl:"format" json:"format"`
+	UID                *int                   `hcl:"uid" json:"uid"`
+	GID                *int                   `hcl:"gid" json:"gid"`
+	Mode               string                 `hcl:"mode" json:"mode"`
+	PKCS12Password     string                 `hcl:"pkcs12_password" json:"pkcs12_password"`
+	Hooks              map[string]HookConfig  `hcl:"hook" json:"hook"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions" json:",omitempty"`
+}
+
+type diskConfig struct {
+	directory      string
+	defaults       fileOptions
+	pkcs12Password string
+	hooks          map[string]*hook
+}
+
+type fileOptions struct {
+	format string
+	uid    int
+	gid    int
+	mode   os.FileMode
+}
+
+type hook struct {
+	command []string
+	pidFile string
+	signal  syscall.Signal
+}
+
+func buildConfig(_ catalog.CoreConfig, hclText string, status *pluginconf.Status) *diskConfig {
+	hclConfig := new(Configuration)
+	if err := hcl.Decode(hclConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	pluginconf.ReportUnusedKeys(status, hclConfig.UnusedKeyPositions)
+
+	newConfig := &diskConfig{
+		directory:      hclConfig.Directory,
+		pkcs12Password: hclConfig.PKCS12Password,
+		defaults: fileOptions{
+			format: formatPEM,
+			uid:    -1,
+			gid:    -1,
+			mode:   defaultMode,
+		},
+		hooks: make(map[string]*hook, len(hclConfig.Hooks)),
+	}
+
+	switch {
+	case newConfig.directory == "":
+		status.ReportError("directory is required")
+	case !filepath.IsAbs(newConfig.directory):
+		status.ReportErrorf("directory %q must be an absolute path", newConfig.directory)
+	}
+
+	if hclConfig.Format != "" {
+		if err := validateFormat(hclConfig.Format); err != nil {
+			status.ReportError(err.Error())
+		}
+		newConfig.defaults.format = hclConfig.Format
+	}
+	if hclConfig.UID != nil {
+		if *hclConfig.UID < 0 {
+			status.ReportErrorf("invalid uid %d", *hclConfig.UID)
+		}
+		newConfig.defaults.uid = *hclConfig.UID
+	}
+	if hclConfig.GID != nil {
+		if *hclConfig.GID < 0 {
+			status.ReportErrorf("invalid gid %d", *hclConfig.GID)
+		}
+		newConfig.defaults.gid = *hclConfig.GID
+	}
+	if hclConfig.Mode != "" {
+		mode, err := parseMode(hclConfig.Mode)
+		if err != nil {
+			status.ReportError(err.Error())
+		}
+		newConfig.defaults.mode = mode
+	}
+
+	for name, hookConfig := range hclConfig.Hooks {
+		h, err := buildHook(hookConfig)
+		if err != nil {
+			status.ReportErrorf("invalid hook %q: %v", name, err)
+			continue
+		}
+		newConfig.hooks[name] = h
+	}
+
+	return newConfig
+}
+
+func buildHook(hookConfig HookConfig) (*hook, error) {
+	switch {
+	case len(hookConfig.Command) == 0 && hookConfig.PIDFile == "":
+		return nil, errors.New("either command or pid_file is required")
+	case len(hookConfig.Command) > 0 && hookConfig.PIDFile != "":
+		return nil, errors.New("command and pid_file are mutually exclusive")
+	case len(hookConfig.Command) > 0:
+		if hookConfig.Signal != "" {
+			return nil, errors.New("signal can only be set with pid_file")
+		}
+		return &hook{command: hookConfig.Command}, nil
+	}
+
+	signalName := hookConfig.Signal
+	if signalName == "" {
+		signalName = defaultSignal
+	}
+	signal := unix.SignalNum(signalName)
+	if signal == 0 {
+		return nil, fmt.Errorf("unknown signal %q", signalName)
+	}
+
+	return &hook{
+		pidFile: hookConfig.PIDFile,
+		signal:  signal,
+	}, nil
+}
+
+type Plugin struct {
+	svidstorev1.UnsafeSVIDStoreServer
+	configv1.UnsafeConfigServer
+
+	log    hclog.Logger
+	mtx    sync.RWMutex
+	config *diskConfig
+
+	// fileMtx serializes writes, so the version directories of a name are
+	// not swapped concurrently.
+	fileMtx sync.Mutex
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+// Configure configures the disk plugin.
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+// PutX509SVID writes the X509-SVID, its bundle and the federated bundles to
+// a new directory and atomically swaps the link of the entry to it, so
+// readers never observe a partially written set of files. The hook of the
+// entry, if any, runs after the swap.
+func (p *Plugin) PutX509SVID(ctx context.Context, req *svidstorev1.PutX509SVIDRequest) (*svidstorev1.PutX509SVIDResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := optionsFromMetadata(req.Metadata, config)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filesFromRequest(req, opt.format, config.pkcs12Password)
+	if err != nil {
+		return nil, err
+	}
+
+	p.fileMtx.Lock()
+	defer p.fileMtx.Unlock()
+
+	log := p.log.With("name", opt.name)
+
+	if err := os.MkdirAll(config.directory, 0755); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create directory: %v", err)
+	}
+
+	linkPath := filepath.Join(config.directory, opt.name)
+	oldVersion, found, err := readLink(linkPath, opt.name)
+	if err != nil {
+		return nil, err
+	}
+
+	versionDir, err := os.MkdirTemp(config.directory, versionPrefix(opt.name))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create version directory: %v", err)
+	}
+	swapped := false
+	defer func() {
+		if !swapped {
+			_ = os.RemoveAll(versionDir)
+		}
+	}()
+
+	if err := writeFiles(versionDir, files, opt); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write files: %v", err)
+	}
+
+	// The link is created under a temporary name and renamed over the
+	// current one, which atomically replaces it.
+	tmpLinkPath := filepath.Join(config.directory, "."+opt.name+".tmp")
+	if err := os.Remove(tmpLinkPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, status.Errorf(codes.Internal, "failed to remove temporary link: %v", err)
+	}
+	if err := os.Symlink(filepath.Base(versionDir), tmpLinkPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create link: %v", err)
+	}
+	if err := os.Rename(tmpLinkPath, linkPath); err != nil {
+		_ = os.Remove(tmpLinkPath)
+		return nil, status.Errorf(codes.Internal, "failed to swap link: %v", err)
+	}
+	swapped = true
+
+	if err := syncDir(config.directory); err != nil {
+		log.Warn("Failed to sync directory", "error", err)
+	}
+
+	if found {
+		if err := os.RemoveAll(filepath.Join(config.directory, oldVersion)); err != nil {
+			log.Warn("Failed to remove previous version", "error", err)
+		}
+	}
+
+	log.Debug("SVID stored", "path", linkPath)
+
+	if opt.hook != "" {
+		if err := config.hooks[opt.hook].run(ctx); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to run hook %q: %v", opt.hook, err)
+		}
+		log.Debug("Hook executed", "hook", opt.hook)
+	}
+
+	return &svidstorev1.PutX509SVIDResponse{}, nil
+}
+
+// DeleteX509SVID removes the link of the entry and the directory it points
+// to. Paths that were not created by the plugin are not removed.
+func (p *Plugin) DeleteX509SVID(_ context.Context, req *svidstorev1.DeleteX509SVIDRequest) (*svidstorev1.DeleteX509SVIDResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := optionsFromMetadata(req.Metadata, config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.fileMtx.Lock()
+	defer p.fileMtx.Unlock()
+
+	log := p.log.With("name", opt.name)
+
+	linkPath := filepath.Join(config.directory, opt.name)
+	version, found, err := readLink(linkPath, opt.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		log.Debug("SVID to delete not found")
+		return &svidstorev1.DeleteX509SVIDResponse{}, nil
+	}
+
+	if err := os.Remove(linkPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove link: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(config.directory, version)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove version directory: %v", err)
+	}
+
+	if err := syncDir(config.directory); err != nil {
+		log.Warn("Failed to sync directory", "error", err)
+	}
+
+	log.Debug("SVID deleted")
+	return &svidstorev1.DeleteX509SVIDResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*diskConfig, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (h *hook) run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	if len(h.command) > 0 {
+		out, err := exec.CommandContext(ctx, h.command[0], h.command[1:]...).CombinedOutput() //nolint: gosec // the command comes from the plugin configuration
+		if err != nil {
+			return fmt.Errorf("command failed: %w: %s", err, bytes.TrimSpace(out))
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(h.pidFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PID file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return fmt.Errorf("invalid PID in %q", h.pidFile)
+	}
+	if err := syscall.Kill(pid, h.signal); err != nil {
+		return fmt.Errorf("failed to signal process %d: %w", pid, err)
+	}
+	return nil
+}
+
+type entryOptions struct {
+	fileOptions
+	name string
+	hook string
+}
+
+func optionsFromMetadata(metadata []string, config *diskConfig) (*entryOptions, error) {
+	data, err := svidstore.ParseMetadata(metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	opt := &entryOptions{
+		fileOptions: config.defaults,
+		name:        data["name"],
+		hook:        data["hook"],
+	}
+
+	switch {
+	case opt.name == "":
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	case !nameRE.MatchString(opt.name):
+		return nil, status.Errorf(codes.InvalidArgument, "invalid name %q: only letters, digits, '_' and '-' are allowed", opt.name)
+	}
+
+	if format, ok := data["format"]; ok {
+		if err := validateFormat(format); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		opt.format = format
+	}
+	if uid, ok := data["uid"]; ok {
+		if opt.uid, err = parseID(uid); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid uid: %v", err)
+		}
+	}
+	if gid, ok := data["gid"]; ok {
+		if opt.gid, err = parseID(gid); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid gid: %v", err)
+		}
+	}
+	if mode, ok := data["mode"]; ok {
+		if opt.mode, err = parseMode(mode); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if opt.hook != "" {
+		if _, ok := config.hooks[opt.hook]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "hook %q is not configured", opt.hook)
+		}
+	}
+
+	return opt, nil
+}
+
+// filesFromRequest returns the content of the files to write, keyed by file
+// name.
+func filesFromRequest(req *svidstorev1.PutX509SVIDRequest, format, pkcs12Password string) (map[string][]byte, error) {
+	federatedBundles := make(map[string][]*x509.Certificate, len(req.FederatedBundles))
+	for federatedID, rawBundle := range req.FederatedBundles {
+		td, err := spiffeid.TrustDomainFromString(federatedID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid federated trust domain %q: %v", federatedID, err)
+		}
+		bundle, err := x509.ParseCertificates(rawBundle)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to parse federated bundle %q: %v", federatedID, err)
+		}
+		federatedBundles[td.Name()] = bundle
+	}
+
+	if format == formatPEM {
+		secretData, err := svidstore.SecretFromProto(req)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: %v", err)
+		}
+
+		files := map[string][]byte{
+			svidFileName:    []byte(secretData.X509SVID),
+			svidKeyFileName: []byte(secretData.X509SVIDKey),
+			bundleFileName:  []byte(secretData.Bundle),
+		}
+		for td, bundle := range federatedBundles {
+			files[federatedBundleFilePrefix+td+".pem"] = pemutil.EncodeCertificates(bundle)
+		}
+		return files, nil
+	}
+
+	certChain, err := x509util.RawCertsToCertificates(req.Svid.CertChain)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse CertChain: %v", err)
+	}
+	if len(certChain) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "CertChain is empty")
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(req.Svid.PrivateKey)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse key: %v", err)
+	}
+	bundle, err := x509util.RawCertsToCertificates(req.Svid.Bundle)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse Bundle: %v", err)
+	}
+
+	// The trust store holds the bundle of the trust domain followed by the
+	// federated bundles, sorted by trust domain.
+	tds := make([]string, 0, len(federatedBundles))
+	for td := range federatedBundles {
+		tds = append(tds, td)
+	}
+	sort.Strings(tds)
+	for _, td := range tds {
+		bundle = append(bundle, federatedBundles[td]...)
+	}
+
+	svidData, err := pkcs12.Modern.Encode(privateKey, certChain[0], certChain[1:], pkcs12Password)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode PKCS#12 SVID: %v", err)
+	}
+	bundleData, err := pkcs12.Modern.EncodeTrustStore(bundle, pkcs12Password)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode PKCS#12 bundle: %v", err)
+	}
+
+	return map[string][]byte{
+		pkcs12SVIDFileName:   svidData,
+		pkcs12BundleFileName: bundleData,
+	}, nil
+}
+
+func writeFiles(dir string, files map[string][]byte, opt *entryOptions) error {
+	for name, data := range files {
+		if err := writeFile(filepath.Join(dir, name), data, opt); err != nil {
+			return err
+		}
+	}
+
+	// Directories are traversable by whoever can read the files.
+	dirMode := opt.mode | (opt.mode&0444)>>2 | 0700
+	if err := os.Chmod(dir, dirMode); err != nil {
+		return err
+	}
+	if err := chown(dir, opt); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+func writeFile(path string, data []byte, opt *entryOptions) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, opt.mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	// The mode is set explicitly since the one used to create the file is
+	// masked by the umask of the agent.
+	if err := file.Chmod(opt.mode); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return chown(path, opt)
+}
+
+func chown(path string, opt *entryOptions) error {
+	if opt.uid == -1 && opt.gid == -1 {
+		return nil
+	}
+	return os.Chown(path, opt.uid, opt.gid)
+}
+
+// readLink returns the version directory that the link of the entry points
+// to. It fails if the path exists but was not created by the plugin.
+func readLink(linkPath, name string) (string, bool, error) {
+	target, err := os.Readlink(linkPath)
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		return "", false, nil
+	case errors.Is(err, syscall.EINVAL):
+		// Not a symbolic link
+		return "", false, status.Errorf(codes.InvalidArgument, "path %q is not managed by this plugin", linkPath)
+	default:
+		return "", false, status.Errorf(codes.Internal, "failed to read link: %v", err)
+	}
+
+	if target != filepath.Base(target) || !strings.HasPrefix(target, versionPrefix(name)) {
+		return "", false, status.Errorf(codes.InvalidArgument, "path %q is not managed by this plugin", linkPath)
+	}
+
+	return target, true, nil
+}
+
+// versionPrefix returns the prefix of the version directories of an entry.
+// Names cannot contain dots, so the prefixes of different names never match.
+func versionPrefix(name string) string {
+	return "." + name + "."
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+
+	return dir.Close()
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case formatPEM, formatPKCS12:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q: expected %q or %q", format, formatPEM, formatPKCS12)
+	}
+}
+
+func parseMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid mode %q: expected permission bits in octal notation", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("%q is not a non-negative integer", s)
+	}
+	return id, nil
+}
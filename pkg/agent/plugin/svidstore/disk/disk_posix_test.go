@@ -0,0 +1,482 @@
+//go:build !windows
+
+package disk
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+	workloadID  = spiffeid.RequireFromString("spiffe://example.org/workload")
+	ctx         = context.Background()
+)
+
+func TestConfigure(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tt := range []struct {
+		name            string
+		config          string
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectConfig    *diskConfig
+	}{
+		{
+			name:   "defaults",
+			config: fmt.Sprintf(`directory = %q`, dir),
+			expectConfig: &diskConfig{
+				directory: dir,
+				defaults:  fileOptions{format: formatPEM, uid: -1, gid: -1, mode: 0600},
+				hooks:     map[string]*hook{},
+			},
+		},
+		{
+			name: "all options",
+			config: fmt.Sprintf(`
+				directory = %q
+				format = "pkcs12"
+				uid = 0
+				gid = 70
+				mode = "0640"
+				pkcs12_password = "changeit"
+				hook "nginx" {
+					command = ["nginx", "-s", "reload"]
+				}
+				hook "postgres" {
+					pid_file = "/run/postgresql/main.pid"
+				}
+				hook "envoy" {
+					pid_file = "/run/envoy.pid"
+					signal = "SIGUSR1"
+				}`, dir),
+			expectConfig: &diskConfig{
+				directory:      dir,
+				defaults:       fileOptions{format: formatPKCS12, uid: 0, gid: 70, mode: 0640},
+				pkcs12Password: "changeit",
+				hooks: map[string]*hook{
+					"nginx":    {command: []string{"nginx", "-s", "reload"}},
+					"postgres": {pidFile: "/run/postgresql/main.pid", signal: syscall.SIGHUP},
+					"envoy":    {pidFile: "/run/envoy.pid", signal: syscall.SIGUSR1},
+				},
+			},
+		},
+		{
+			name:            "malformed configuration",
+			config:          "{no a config}",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unable to decode configuration:",
+		},
+		{
+			name:            "missing directory",
+			config:          "",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "directory is required",
+		},
+		{
+			name:            "relative directory",
+			config:          `directory = "svids"`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `directory "svids" must be an absolute path`,
+		},
+		{
+			name:            "invalid format",
+			config:          fmt.Sprintf(`directory = %q format = "der"`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid format "der": expected "pem" or "pkcs12"`,
+		},
+		{
+			name:            "invalid mode",
+			config:          fmt.Sprintf(`directory = %q mode = "0999"`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid mode "0999": expected permission bits in octal notation`,
+		},
+		{
+			name:            "invalid uid",
+			config:          fmt.Sprintf(`directory = %q uid = -2`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "invalid uid -2",
+		},
+		{
+			name:            "hook without action",
+			config:          fmt.Sprintf(`directory = %q hook "nginx" {}`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid hook "nginx": either command or pid_file is required`,
+		},
+		{
+			name:            "hook with command and pid file",
+			config:          fmt.Sprintf(`directory = %q hook "nginx" { command = ["true"] pid_file = "/run/nginx.pid" }`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid hook "nginx": command and pid_file are mutually exclusive`,
+		},
+		{
+			name:            "hook with command and signal",
+			config:          fmt.Sprintf(`directory = %q hook "nginx" { command = ["true"] signal = "SIGHUP" }`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid hook "nginx": signal can only be set with pid_file`,
+		},
+		{
+			name:            "hook with unknown signal",
+			config:          fmt.Sprintf(`directory = %q hook "nginx" { pid_file = "/run/nginx.pid" signal = "HUP" }`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid hook "nginx": unknown signal "HUP"`,
+		},
+		{
+			name:            "contains unused keys",
+			config:          fmt.Sprintf(`directory = %q invalid1 = "something"`, dir),
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unknown configurations detected: invalid1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+
+			var err error
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+				plugintest.Configure(tt.config),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, p.config)
+				return
+			}
+			require.Equal(t, tt.expectConfig, p.config)
+		})
+	}
+}
+
+func TestPutX509SVID(t *testing.T) {
+	ca := testca.New(t, trustDomain)
+	federatedCA := testca.New(t, spiffeid.RequireTrustDomainFromString("federated.test"))
+	svid := ca.CreateX509SVID(workloadID)
+
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+
+	newRequest := func(metadata ...string) *svidstore.X509SVID {
+		return &svidstore.X509SVID{
+			SVID: &svidstore.SVID{
+				SPIFFEID:   workloadID,
+				CertChain:  svid.Certificates,
+				PrivateKey: svid.PrivateKey,
+				Bundle:     ca.X509Authorities(),
+				ExpiresAt:  time.Now().Add(time.Hour),
+			},
+			Metadata: metadata,
+			FederatedBundles: map[string][]*x509.Certificate{
+				"spiffe://federated.test": federatedCA.X509Authorities(),
+			},
+		}
+	}
+
+	t.Run("PEM", func(t *testing.T) {
+		dir := t.TempDir()
+		ss := loadPlugin(t, fmt.Sprintf(`directory = %q`, dir))
+
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:nginx")))
+
+		path := filepath.Join(dir, "nginx")
+		requireFile(t, filepath.Join(path, "svid.pem"), pemutil.EncodeCertificates(svid.Certificates), 0600)
+		requireFile(t, filepath.Join(path, "svid_key.pem"), keyPEM, 0600)
+		requireFile(t, filepath.Join(path, "svid_bundle.pem"), pemutil.EncodeCertificates(ca.X509Authorities()), 0600)
+		requireFile(t, filepath.Join(path, "federated_bundle_federated.test.pem"), pemutil.EncodeCertificates(federatedCA.X509Authorities()), 0600)
+		requireVersions(t, dir, "nginx", 1)
+	})
+
+	t.Run("PKCS#12", func(t *testing.T) {
+		dir := t.TempDir()
+		ss := loadPlugin(t, fmt.Sprintf(`directory = %q pkcs12_password = "changeit"`, dir))
+
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:kafka", "format:pkcs12")))
+
+		svidData, err := os.ReadFile(filepath.Join(dir, "kafka", "svid.p12"))
+		require.NoError(t, err)
+		key, cert, caCerts, err := pkcs12.DecodeChain(svidData, "changeit")
+		require.NoError(t, err)
+		require.Equal(t, svid.PrivateKey, key)
+		require.Equal(t, svid.Certificates[0], cert)
+		require.Empty(t, caCerts)
+
+		bundleData, err := os.ReadFile(filepath.Join(dir, "kafka", "svid_bundle.p12"))
+		require.NoError(t, err)
+		bundle, err := pkcs12.DecodeTrustStore(bundleData, "changeit")
+		require.NoError(t, err)
+		require.Equal(t, append(ca.X509Authorities(), federatedCA.X509Authorities()...), bundle)
+
+		_, err = os.Stat(filepath.Join(dir, "kafka", "svid.pem"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		dir := t.TempDir()
+		ss := loadPlugin(t, fmt.Sprintf(`directory = %q`, dir))
+
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:nginx")))
+		oldTarget, err := os.Readlink(filepath.Join(dir, "nginx"))
+		require.NoError(t, err)
+
+		rotated := ca.CreateX509SVID(workloadID)
+		req := newRequest("name:nginx")
+		req.SVID.CertChain = rotated.Certificates
+		req.SVID.PrivateKey = rotated.PrivateKey
+		require.NoError(t, ss.PutX509SVID(ctx, req))
+
+		newTarget, err := os.Readlink(filepath.Join(dir, "nginx"))
+		require.NoError(t, err)
+		require.NotEqual(t, oldTarget, newTarget)
+		requireFile(t, filepath.Join(dir, "nginx", "svid.pem"), pemutil.EncodeCertificates(rotated.Certificates), 0600)
+		requireVersions(t, dir, "nginx", 1)
+	})
+
+	t.Run("ownership and mode", func(t *testing.T) {
+		dir := t.TempDir()
+		ss := loadPlugin(t, fmt.Sprintf(`directory = %q mode = "0640"`, dir))
+
+		uid, gid := os.Getuid(), os.Getgid()
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:postgres", fmt.Sprintf("uid:%d", uid), fmt.Sprintf("gid:%d", gid))))
+		requireFile(t, filepath.Join(dir, "postgres", "svid_key.pem"), keyPEM, 0640)
+
+		info, err := os.Stat(filepath.Join(dir, "postgres"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0750), info.Mode().Perm())
+		stat := info.Sys().(*syscall.Stat_t)
+		require.Equal(t, uint32(uid), stat.Uid)
+		require.Equal(t, uint32(gid), stat.Gid)
+
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:postgres", "mode:0400")))
+		requireFile(t, filepath.Join(dir, "postgres", "svid_key.pem"), keyPEM, 0400)
+	})
+
+	t.Run("command hook", func(t *testing.T) {
+		dir := t.TempDir()
+		out := filepath.Join(t.TempDir(), "reloaded")
+		ss := loadPlugin(t, fmt.Sprintf(`
+			directory = %q
+			hook "reload" {
+				command = ["sh", "-c", "cat %s/nginx/svid.pem > %s"]
+			}
+			hook "fail" {
+				command = ["sh", "-c", "echo oh no; exit 1"]
+			}`, dir, dir, out))
+
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:nginx", "hook:reload")))
+		reloaded, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, string(pemutil.EncodeCertificates(svid.Certificates)), string(reloaded))
+
+		err = ss.PutX509SVID(ctx, newRequest("name:nginx", "hook:fail"))
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, `svidstore(disk): failed to run hook "fail": command failed: exit status 1: oh no`)
+	})
+
+	t.Run("signal hook", func(t *testing.T) {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGUSR1)
+		defer signal.Stop(signals)
+
+		dir := t.TempDir()
+		pidFile := filepath.Join(t.TempDir(), "workload.pid")
+		require.NoError(t, os.WriteFile(pidFile, fmt.Appendf(nil, "%d\n", os.Getpid()), 0600))
+		ss := loadPlugin(t, fmt.Sprintf(`
+			directory = %q
+			hook "workload" {
+				pid_file = %q
+				signal = "SIGUSR1"
+			}
+			hook "missing" {
+				pid_file = "%s/missing.pid"
+			}`, dir, pidFile, dir))
+
+		require.NoError(t, ss.PutX509SVID(ctx, newRequest("name:workload", "hook:workload")))
+		select {
+		case sig := <-signals:
+			require.Equal(t, syscall.SIGUSR1, sig)
+		case <-time.After(time.Minute):
+			require.Fail(t, "signal not received")
+		}
+
+		err := ss.PutX509SVID(ctx, newRequest("name:workload", "hook:missing"))
+		spiretest.RequireGRPCStatusHasPrefix(t, err, codes.Internal, `svidstore(disk): failed to run hook "missing": failed to read PID file:`)
+	})
+
+	t.Run("path not managed by the plugin", func(t *testing.T) {
+		dir := t.TempDir()
+		ss := loadPlugin(t, fmt.Sprintf(`directory = %q`, dir))
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "nginx"), 0755))
+		err := ss.PutX509SVID(ctx, newRequest("name:nginx"))
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, fmt.Sprintf("svidstore(disk): path %q is not managed by this plugin", filepath.Join(dir, "nginx")))
+
+		require.NoError(t, os.Symlink("/etc", filepath.Join(dir, "postgres")))
+		err = ss.PutX509SVID(ctx, newRequest("name:postgres"))
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, fmt.Sprintf("svidstore(disk): path %q is not managed by this plugin", filepath.Join(dir, "postgres")))
+
+		requireVersions(t, dir, "nginx", 0)
+		requireVersions(t, dir, "postgres", 0)
+	})
+
+	for _, tt := range []struct {
+		name      string
+		metadata  []string
+		expectMsg string
+	}{
+		{
+			name:      "missing name",
+			metadata:  []string{"format:pem"},
+			expectMsg: "svidstore(disk): name is required",
+		},
+		{
+			name:      "invalid name",
+			metadata:  []string{"name:../etc"},
+			expectMsg: `svidstore(disk): invalid name "../etc": only letters, digits, '_' and '-' are allowed`,
+		},
+		{
+			name:      "invalid format",
+			metadata:  []string{"name:nginx", "format:der"},
+			expectMsg: `svidstore(disk): invalid format "der": expected "pem" or "pkcs12"`,
+		},
+		{
+			name:      "invalid uid",
+			metadata:  []string{"name:nginx", "uid:nginx"},
+			expectMsg: `svidstore(disk): invalid uid: "nginx" is not a non-negative integer`,
+		},
+		{
+			name:      "invalid gid",
+			metadata:  []string{"name:nginx", "gid:-1"},
+			expectMsg: `svidstore(disk): invalid gid: "-1" is not a non-negative integer`,
+		},
+		{
+			name:      "invalid mode",
+			metadata:  []string{"name:nginx", "mode:rw"},
+			expectMsg: `svidstore(disk): invalid mode "rw": expected permission bits in octal notation`,
+		},
+		{
+			name:      "unknown hook",
+			metadata:  []string{"name:nginx", "hook:nginx"},
+			expectMsg: `svidstore(disk): hook "nginx" is not configured`,
+		},
+		{
+			name:      "invalid metadata",
+			metadata:  []string{"name"},
+			expectMsg: `svidstore(disk): invalid metadata: metadata does not contain a colon: "name"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := loadPlugin(t, fmt.Sprintf(`directory = %q`, t.TempDir()))
+
+			err := ss.PutX509SVID(ctx, newRequest(tt.metadata...))
+			spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, tt.expectMsg)
+		})
+	}
+}
+
+func TestDeleteX509SVID(t *testing.T) {
+	ca := testca.New(t, trustDomain)
+	svid := ca.CreateX509SVID(workloadID)
+
+	dir := t.TempDir()
+	ss := loadPlugin(t, fmt.Sprintf(`directory = %q`, dir))
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, ss.PutX509SVID(ctx, &svidstore.X509SVID{
+			SVID: &svidstore.SVID{
+				SPIFFEID:   workloadID,
+				CertChain:  svid.Certificates,
+				PrivateKey: svid.PrivateKey,
+				Bundle:     ca.X509Authorities(),
+			},
+			Metadata: []string{"name:nginx"},
+		}))
+
+		require.NoError(t, ss.DeleteX509SVID(ctx, []string{"name:nginx"}))
+
+		_, err := os.Lstat(filepath.Join(dir, "nginx"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+		requireVersions(t, dir, "nginx", 0)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		require.NoError(t, ss.DeleteX509SVID(ctx, []string{"name:missing"}))
+	})
+
+	t.Run("path not managed by the plugin", func(t *testing.T) {
+		path := filepath.Join(dir, "postgres")
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+		err := ss.DeleteX509SVID(ctx, []string{"name:postgres"})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, fmt.Sprintf("svidstore(disk): path %q is not managed by this plugin", path))
+		require.FileExists(t, path)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		err := ss.DeleteX509SVID(ctx, []string{"format:pem"})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "svidstore(disk): name is required")
+	})
+}
+
+func TestNotConfigured(t *testing.T) {
+	ss := new(svidstore.V1)
+	plugintest.Load(t, BuiltIn(), ss)
+
+	err := ss.DeleteX509SVID(ctx, []string{"name:nginx"})
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "svidstore(disk): not configured")
+}
+
+func loadPlugin(t *testing.T, config string) svidstore.SVIDStore {
+	ss := new(svidstore.V1)
+	plugintest.Load(t, BuiltIn(), ss,
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: trustDomain,
+		}),
+		plugintest.Configure(config),
+	)
+	return ss
+}
+
+func requireFile(t *testing.T, path string, expectData []byte, expectMode os.FileMode) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expectData), string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, expectMode, info.Mode().Perm())
+}
+
+// requireVersions asserts the number of version directories of a name.
+func requireVersions(t *testing.T, dir, name string, expected int) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	versions := 0
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), versionPrefix(name)) {
+			require.True(t, entry.IsDir(), "unexpected file %q", entry.Name())
+			versions++
+		}
+	}
+	require.Equal(t, expected, versions)
+}
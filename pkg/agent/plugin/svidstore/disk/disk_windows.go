@@ -0,0 +1,37 @@
+//go:build windows
+
+package disk
+
+import (
+	"context"
+
+	svidstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/svidstore/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Plugin struct {
+	svidstorev1.UnimplementedSVIDStoreServer
+	configv1.UnsafeConfigServer
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		svidstorev1.SVIDStorePluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Configure(context.Context, *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "plugin not supported in this platform")
+}
+
+func (p *Plugin) Validate(context.Context, *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "plugin not supported in this platform")
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package disk
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"google.golang.org/grpc/codes"
+)
+
+func TestConfigure(t *testing.T) {
+	var err error
+	plugintest.Load(t, BuiltIn(), new(svidstore.V1),
+		plugintest.CaptureConfigureError(&err),
+		plugintest.Configure(""),
+	)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Unimplemented, "plugin not supported in this platform")
+}
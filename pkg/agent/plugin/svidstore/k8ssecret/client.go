@@ -0,0 +1,30 @@
+package k8ssecret
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// newK8sClient creates a new Kubernetes client. If the kubeconfig path is
+// empty, the in-cluster configuration is used.
+func newK8sClient(kubeConfigPath string) (kubernetes.Interface, error) {
+	var kubeConfig *rest.Config
+	var err error
+	if kubeConfigPath != "" {
+		kubeConfig, err = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	} else {
+		kubeConfig, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Kubernetes client: %w", err)
+	}
+	return clientset, nil
+}
@@ -0,0 +1,327 @@
+package k8ssecret
+
+import (
+	"context"
+	"crypto/sha1" //nolint: gosec // We use sha1 to hash trust domain names to fit them in a label value
+	"encoding/hex"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	svidstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/svidstore/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	pluginName = "k8s_secret"
+
+	// managedByLabel marks the secrets created by the plugin. Its value is
+	// the hash of the trust domain of the agent, so secrets created by other
+	// SPIRE deployments are not modified or removed.
+	managedByLabel = "spiffe.io/spire-svid"
+
+	// spiffeIDAnnotation holds the SPIFFE ID of the stored SVID.
+	spiffeIDAnnotation = "spiffe.io/spiffe-id"
+
+	// caCertKey is the key of the trust domain bundle in the secret data.
+	caCertKey = "ca.crt"
+
+	// federatedBundleKeyPrefix prefixes the keys of federated bundles in the
+	// secret data, which are followed by the trust domain name and ".crt".
+	federatedBundleKeyPrefix = "federated-"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *SecretPlugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		svidstorev1.SVIDStorePluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+func New() *SecretPlugin {
+	return newPlugin(newK8sClient)
+}
+
+func newPlugin(newK8sClient func(string) (kubernetes.Interface, error)) *SecretPlugin {
+	p := &SecretPlugin{}
+	p.hooks.newK8sClient = newK8sClient
+
+	return p
+}
+
+type Configuration struct {
+	KubeConfigPath     string                 `hcl:"kubeconfig_path" json:"kubeconfig_path"`
+	Namespace          string                 `hcl:"namespace" json:"namespace"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions" json:",omitempty"`
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Configuration {
+	newConfig := &Configuration{}
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	pluginconf.ReportUnusedKeys(status, newConfig.UnusedKeyPositions)
+
+	return newConfig
+}
+
+type SecretPlugin struct {
+	svidstorev1.UnsafeSVIDStoreServer
+	configv1.UnsafeConfigServer
+
+	log       hclog.Logger
+	mtx       sync.RWMutex
+	k8sClient kubernetes.Interface
+	namespace string
+	tdHash    string
+
+	hooks struct {
+		newK8sClient func(string) (kubernetes.Interface, error)
+	}
+}
+
+func (p *SecretPlugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+// Configure configures the SecretPlugin.
+func (p *SecretPlugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	k8sClient, err := p.hooks.newK8sClient(newConfig.KubeConfigPath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create Kubernetes client: %v", err)
+	}
+
+	// Label values are limited to 63 characters, hash td as label
+	tdHash := sha1.Sum([]byte(req.CoreConfiguration.TrustDomain)) //nolint: gosec // We use sha1 to hash trust domain names to fit them in a label value
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.k8sClient = k8sClient
+	p.namespace = newConfig.Namespace
+	p.tdHash = hex.EncodeToString(tdHash[:])
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *SecretPlugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+// PutX509SVID puts the specified X509-SVID in a Kubernetes secret of type
+// kubernetes.io/tls, creating the secret if it does not exist.
+func (p *SecretPlugin) PutX509SVID(ctx context.Context, req *svidstorev1.PutX509SVIDRequest) (*svidstorev1.PutX509SVIDResponse, error) {
+	k8sClient, defaultNamespace, tdHash, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := optionsFromSecretData(req.Metadata, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	secretData, err := svidstore.SecretFromProto(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: %v", err)
+	}
+
+	data := map[string][]byte{
+		corev1.TLSCertKey:       []byte(secretData.X509SVID),
+		corev1.TLSPrivateKeyKey: []byte(secretData.X509SVIDKey),
+		caCertKey:               []byte(secretData.Bundle),
+	}
+	for federatedID, bundle := range secretData.FederatedBundles {
+		td, err := spiffeid.TrustDomainFromString(federatedID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid federated trust domain %q: %v", federatedID, err)
+		}
+		data[federatedBundleKeyPrefix+td.Name()+".crt"] = []byte(bundle)
+	}
+
+	log := p.log.With("namespace", opt.namespace, "secret_name", opt.name)
+	secrets := k8sClient.CoreV1().Secrets(opt.namespace)
+
+	secret, found, err := getSecret(ctx, secrets, opt.name, tdHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secret not found, create it
+	if !found {
+		_, err := secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      opt.name,
+				Namespace: opt.namespace,
+				Labels: map[string]string{
+					managedByLabel: tdHash,
+				},
+				Annotations: map[string]string{
+					spiffeIDAnnotation: secretData.SPIFFEID,
+				},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create secret: %v", err)
+		}
+		log.Debug("Secret created")
+		return &svidstorev1.PutX509SVIDResponse{}, nil
+	}
+
+	if secret.Type != corev1.SecretTypeTLS {
+		return nil, status.Errorf(codes.InvalidArgument, "secret has type %q instead of %q", secret.Type, corev1.SecretTypeTLS)
+	}
+
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[spiffeIDAnnotation] = secretData.SPIFFEID
+	secret.Data = data
+
+	// The resource version of the secret that was read is kept, so the update
+	// fails if the secret was modified in the meantime.
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update secret: %v", err)
+	}
+
+	log.Debug("Secret updated")
+	return &svidstorev1.PutX509SVIDResponse{}, nil
+}
+
+// DeleteX509SVID deletes the Kubernetes secret, if it was created by this
+// SPIRE deployment.
+func (p *SecretPlugin) DeleteX509SVID(ctx context.Context, req *svidstorev1.DeleteX509SVIDRequest) (*svidstorev1.DeleteX509SVIDResponse, error) {
+	k8sClient, defaultNamespace, tdHash, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := optionsFromSecretData(req.Metadata, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	log := p.log.With("namespace", opt.namespace, "secret_name", opt.name)
+	secrets := k8sClient.CoreV1().Secrets(opt.namespace)
+
+	secret, found, err := getSecret(ctx, secrets, opt.name, tdHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		log.Debug("Secret to delete not found")
+		return &svidstorev1.DeleteX509SVIDResponse{}, nil
+	}
+
+	// Preconditions make sure that the secret that was verified is the one
+	// that is deleted.
+	err = secrets.Delete(ctx, opt.name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &secret.UID,
+			ResourceVersion: &secret.ResourceVersion,
+		},
+	})
+	switch {
+	case err == nil:
+	case k8serrors.IsNotFound(err):
+		log.Debug("Secret to delete not found")
+		return &svidstorev1.DeleteX509SVIDResponse{}, nil
+	default:
+		return nil, status.Errorf(codes.Internal, "failed to delete secret: %v", err)
+	}
+
+	log.Debug("Secret deleted")
+	return &svidstorev1.DeleteX509SVIDResponse{}, nil
+}
+
+func (p *SecretPlugin) getConfig() (kubernetes.Interface, string, string, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.k8sClient == nil {
+		return nil, "", "", status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.k8sClient, p.namespace, p.tdHash, nil
+}
+
+type secretGetter interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error)
+}
+
+// getSecret gets the secret and validates that it has the `spiffe.io/spire-svid`
+// label with the hashed trust domain as value.
+func getSecret(ctx context.Context, secrets secretGetter, name string, tdHash string) (*corev1.Secret, bool, error) {
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if secret.Labels[managedByLabel] != tdHash {
+			return nil, false, status.Error(codes.InvalidArgument, "secret is not managed by this SPIRE deployment")
+		}
+	case k8serrors.IsNotFound(err):
+		return nil, false, nil
+	default:
+		return nil, false, status.Errorf(codes.Internal, "failed to get secret: %v", err)
+	}
+
+	return secret, true, nil
+}
+
+type secretOptions struct {
+	namespace string
+	name      string
+}
+
+func optionsFromSecretData(selectorData []string, defaultNamespace string) (*secretOptions, error) {
+	data, err := svidstore.ParseMetadata(selectorData)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	name, ok := data["secretname"]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "secretname is required")
+	}
+
+	namespace, ok := data["namespace"]
+	if !ok {
+		namespace = defaultNamespace
+	}
+	if namespace == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace is required")
+	}
+
+	return &secretOptions{
+		namespace: namespace,
+		name:      name,
+	}, nil
+}
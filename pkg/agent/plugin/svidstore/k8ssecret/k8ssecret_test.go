@@ -0,0 +1,422 @@
+package k8ssecret
+
+import (
+	"context"
+	"crypto/sha1" //nolint: gosec // We use sha1 to hash trust domain names to fit them in a label value
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+	tdSum       = sha1.Sum([]byte("example.org")) //nolint: gosec // We use sha1 to hash trust domain names to fit them in a label value
+	tdHash      = hex.EncodeToString(tdSum[:])
+	workloadID  = spiffeid.RequireFromString("spiffe://example.org/workload")
+)
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		customConfig       string
+		newClientErr       error
+		expectCode         codes.Code
+		expectMsgPrefix    string
+		expectKubeConfig   string
+		expectNamespace    string
+		expectClientLoaded bool
+	}{
+		{
+			name:               "success",
+			customConfig:       `kubeconfig_path = "/some/kubeconfig" namespace = "workloads"`,
+			expectKubeConfig:   "/some/kubeconfig",
+			expectNamespace:    "workloads",
+			expectClientLoaded: true,
+		},
+		{
+			name:               "in-cluster",
+			customConfig:       ``,
+			expectClientLoaded: true,
+		},
+		{
+			name:            "malformed configuration",
+			customConfig:    "{no a config}",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unable to decode configuration:",
+		},
+		{
+			name:            "failed to create client",
+			newClientErr:    errors.New("oh! no"),
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "failed to create Kubernetes client: oh! no",
+		},
+		{
+			name:            "contains unused keys",
+			customConfig:    `invalid1 = "something"`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unknown configurations detected: invalid1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			newClient := func(kubeConfigPath string) (kubernetes.Interface, error) {
+				require.Equal(t, tt.expectKubeConfig, kubeConfigPath)
+				if tt.newClientErr != nil {
+					return nil, tt.newClientErr
+				}
+				return fake.NewClientset(), nil
+			}
+
+			p := newPlugin(newClient)
+
+			var err error
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+				plugintest.Configure(tt.customConfig),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+
+			if !tt.expectClientLoaded {
+				require.Nil(t, p.k8sClient)
+				return
+			}
+			require.NotNil(t, p.k8sClient)
+			require.Equal(t, tdHash, p.tdHash)
+			require.Equal(t, tt.expectNamespace, p.namespace)
+		})
+	}
+}
+
+func TestPutX509SVID(t *testing.T) {
+	ca := testca.New(t, trustDomain)
+	svid := ca.CreateX509SVID(workloadID)
+	federatedCA := testca.New(t, spiffeid.RequireTrustDomainFromString("federated.test"))
+
+	svidPEM := string(pemutil.EncodeCertificates(svid.Certificates))
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+	bundlePEM := string(pemutil.EncodeCertificates(ca.X509Authorities()))
+	federatedBundlePEM := string(pemutil.EncodeCertificates(federatedCA.X509Authorities()))
+
+	req := &svidstore.X509SVID{
+		SVID: &svidstore.SVID{
+			SPIFFEID:   workloadID,
+			CertChain:  svid.Certificates,
+			PrivateKey: svid.PrivateKey,
+			Bundle:     ca.X509Authorities(),
+			ExpiresAt:  time.Now().Add(time.Hour),
+		},
+		Metadata: []string{
+			"secretname:workload-tls",
+			"namespace:workloads",
+		},
+		FederatedBundles: map[string][]*x509.Certificate{
+			"spiffe://federated.test": federatedCA.X509Authorities(),
+		},
+	}
+
+	expectData := map[string][]byte{
+		"tls.crt":                      []byte(svidPEM),
+		"tls.key":                      keyPEM,
+		"ca.crt":                       []byte(bundlePEM),
+		"federated-federated.test.crt": []byte(federatedBundlePEM),
+	}
+
+	expectSecret := func(resourceVersion string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "workload-tls",
+				Namespace:       "workloads",
+				Labels:          map[string]string{managedByLabel: tdHash},
+				Annotations:     map[string]string{spiffeIDAnnotation: workloadID.String()},
+				ResourceVersion: resourceVersion,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: expectData,
+		}
+	}
+
+	for _, tt := range []struct {
+		name            string
+		metadata        []string
+		namespace       string
+		existing        []runtime.Object
+		reactor         k8stesting.ReactionFunc
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectSecret    *corev1.Secret
+	}{
+		{
+			name:         "create secret",
+			expectSecret: expectSecret(""),
+		},
+		{
+			name:         "create secret in default namespace",
+			metadata:     []string{"secretname:workload-tls"},
+			namespace:    "workloads",
+			expectSecret: expectSecret(""),
+		},
+		{
+			name: "update secret",
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "workload-tls",
+					Namespace:       "workloads",
+					Labels:          map[string]string{managedByLabel: tdHash},
+					ResourceVersion: "1",
+				},
+				Type: corev1.SecretTypeTLS,
+				Data: map[string][]byte{"tls.crt": []byte("OLD"), "stale": []byte("STALE")},
+			}},
+			expectSecret: expectSecret("1"),
+		},
+		{
+			name: "secret not managed by SPIRE",
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workload-tls",
+					Namespace: "workloads",
+				},
+				Type: corev1.SecretTypeTLS,
+			}},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(k8s_secret): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name: "secret managed by another trust domain",
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workload-tls",
+					Namespace: "workloads",
+					Labels:    map[string]string{managedByLabel: "another"},
+				},
+				Type: corev1.SecretTypeTLS,
+			}},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(k8s_secret): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name: "secret with unexpected type",
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workload-tls",
+					Namespace: "workloads",
+					Labels:    map[string]string{managedByLabel: tdHash},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `svidstore(k8s_secret): secret has type "Opaque" instead of "kubernetes.io/tls"`,
+		},
+		{
+			name:            "missing secret name",
+			metadata:        []string{"namespace:workloads"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(k8s_secret): secretname is required",
+		},
+		{
+			name:            "missing namespace",
+			metadata:        []string{"secretname:workload-tls"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(k8s_secret): namespace is required",
+		},
+		{
+			name:            "invalid metadata",
+			metadata:        []string{"secretname"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `svidstore(k8s_secret): invalid metadata: metadata does not contain a colon: "secretname"`,
+		},
+		{
+			name: "failed to get secret",
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetVerb() == "get" {
+					return true, nil, errors.New("oh no")
+				}
+				return false, nil, nil
+			},
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(k8s_secret): failed to get secret: oh no",
+		},
+		{
+			name: "failed to create secret",
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetVerb() == "create" {
+					return true, nil, errors.New("oh no")
+				}
+				return false, nil, nil
+			},
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(k8s_secret): failed to create secret: oh no",
+		},
+		{
+			name: "failed to update secret",
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workload-tls",
+					Namespace: "workloads",
+					Labels:    map[string]string{managedByLabel: tdHash},
+				},
+				Type: corev1.SecretTypeTLS,
+			}},
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetVerb() == "update" {
+					return true, nil, k8serrors.NewConflict(corev1.Resource("secrets"), "workload-tls", errors.New("modified"))
+				}
+				return false, nil, nil
+			},
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(k8s_secret): failed to update secret:",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewClientset(tt.existing...)
+			if tt.reactor != nil {
+				client.PrependReactor("*", "secrets", tt.reactor)
+			}
+
+			ss := loadPlugin(t, client, tt.namespace)
+
+			putReq := *req
+			if tt.metadata != nil {
+				putReq.Metadata = tt.metadata
+			}
+			err := ss.PutX509SVID(context.Background(), &putReq)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+			if tt.expectCode != codes.OK {
+				return
+			}
+
+			secret, err := client.CoreV1().Secrets("workloads").Get(context.Background(), "workload-tls", metav1.GetOptions{})
+			require.NoError(t, err)
+			// Type meta and managed fields are populated by the fake clientset
+			secret.TypeMeta = metav1.TypeMeta{}
+			secret.ManagedFields = nil
+			require.Equal(t, tt.expectSecret, secret)
+		})
+	}
+}
+
+func TestDeleteX509SVID(t *testing.T) {
+	managedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workload-tls",
+			Namespace: "workloads",
+			Labels:    map[string]string{managedByLabel: tdHash},
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+
+	for _, tt := range []struct {
+		name            string
+		metadata        []string
+		existing        []runtime.Object
+		reactor         k8stesting.ReactionFunc
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectDeleted   bool
+	}{
+		{
+			name:          "delete secret",
+			existing:      []runtime.Object{managedSecret},
+			expectDeleted: true,
+		},
+		{
+			name: "secret not found",
+		},
+		{
+			name: "secret not managed by SPIRE",
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workload-tls",
+					Namespace: "workloads",
+				},
+			}},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(k8s_secret): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name:            "missing secret name",
+			metadata:        []string{"namespace:workloads"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(k8s_secret): secretname is required",
+		},
+		{
+			name:     "secret removed concurrently",
+			existing: []runtime.Object{managedSecret},
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetVerb() == "delete" {
+					return true, nil, k8serrors.NewNotFound(corev1.Resource("secrets"), "workload-tls")
+				}
+				return false, nil, nil
+			},
+		},
+		{
+			name:     "failed to delete secret",
+			existing: []runtime.Object{managedSecret},
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetVerb() == "delete" {
+					return true, nil, errors.New("oh no")
+				}
+				return false, nil, nil
+			},
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(k8s_secret): failed to delete secret: oh no",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewClientset(tt.existing...)
+			if tt.reactor != nil {
+				client.PrependReactor("*", "secrets", tt.reactor)
+			}
+
+			ss := loadPlugin(t, client, "")
+
+			metadata := tt.metadata
+			if metadata == nil {
+				metadata = []string{"secretname:workload-tls", "namespace:workloads"}
+			}
+			err := ss.DeleteX509SVID(context.Background(), metadata)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+
+			_, err = client.CoreV1().Secrets("workloads").Get(context.Background(), "workload-tls", metav1.GetOptions{})
+			require.Equal(t, tt.expectDeleted, k8serrors.IsNotFound(err) && len(tt.existing) > 0)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, client kubernetes.Interface, namespace string) svidstore.SVIDStore {
+	p := newPlugin(func(string) (kubernetes.Interface, error) {
+		return client, nil
+	})
+
+	ss := new(svidstore.V1)
+	plugintest.Load(t, builtin(p), ss,
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: trustDomain,
+		}),
+		plugintest.ConfigureJSON(&Configuration{Namespace: namespace}),
+	)
+	return ss
+}
@@ -0,0 +1,85 @@
+package vaultkv
+
+import (
+	"context"
+	"errors"
+
+	vapi "github.com/hashicorp/vault/api"
+)
+
+// kvStore reads and writes secrets in a KV secrets engine.
+type kvStore interface {
+	// Get returns the data of the secret at the given path, or nil if the
+	// secret does not exist. For KV v2, it also returns the current version
+	// of the secret, which is used for check-and-set writes.
+	Get(ctx context.Context, path string) (map[string]any, int, error)
+
+	// Put writes the secret at the given path. For KV v2, the write only
+	// succeeds if the current version of the secret matches the given one.
+	Put(ctx context.Context, path string, data map[string]any, version int) error
+
+	// Delete removes the secret at the given path. For KV v2, all the
+	// versions of the secret are removed.
+	Delete(ctx context.Context, path string) error
+}
+
+func newKVStore(client *vapi.Client, mountPoint string, kvVersion int) kvStore {
+	if kvVersion == 1 {
+		return kvV1{kv: client.KVv1(mountPoint)}
+	}
+	return kvV2{kv: client.KVv2(mountPoint)}
+}
+
+type kvV1 struct {
+	kv *vapi.KVv1
+}
+
+func (s kvV1) Get(ctx context.Context, path string) (map[string]any, int, error) {
+	secret, err := s.kv.Get(ctx, path)
+	switch {
+	case errors.Is(err, vapi.ErrSecretNotFound):
+		return nil, 0, nil
+	case err != nil:
+		return nil, 0, err
+	}
+	return secret.Data, 0, nil
+}
+
+func (s kvV1) Put(ctx context.Context, path string, data map[string]any, _ int) error {
+	return s.kv.Put(ctx, path, data)
+}
+
+func (s kvV1) Delete(ctx context.Context, path string) error {
+	return s.kv.Delete(ctx, path)
+}
+
+type kvV2 struct {
+	kv *vapi.KVv2
+}
+
+func (s kvV2) Get(ctx context.Context, path string) (map[string]any, int, error) {
+	secret, err := s.kv.Get(ctx, path)
+	switch {
+	case errors.Is(err, vapi.ErrSecretNotFound):
+		return nil, 0, nil
+	case err != nil:
+		return nil, 0, err
+	}
+
+	// Data is nil when the current version was deleted, but the version is
+	// still needed to write a new one.
+	version := 0
+	if secret.VersionMetadata != nil {
+		version = secret.VersionMetadata.Version
+	}
+	return secret.Data, version, nil
+}
+
+func (s kvV2) Put(ctx context.Context, path string, data map[string]any, version int) error {
+	_, err := s.kv.Put(ctx, path, data, vapi.WithCheckAndSet(version))
+	return err
+}
+
+func (s kvV2) Delete(ctx context.Context, path string) error {
+	return s.kv.DeleteMetadata(ctx, path)
+}
@@ -0,0 +1,339 @@
+package vaultkv
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testServerCert = "testdata/server-cert.pem"
+	testServerKey  = "testdata/server-key.pem"
+	testK8sToken   = "testdata/k8s/token"
+
+	testToken         = "test-token"
+	testAppRoleID     = "test-approle-id"
+	testAppRoleSecret = "test-approle-secret-id" // #nosec G101
+	testK8sRole       = "my-role"
+)
+
+// fakeVault is an in-memory stand-in for a Vault server in dev mode. It
+// serves the token, cert, AppRole and Kubernetes auth methods on their
+// default mount points, a KV v1 engine mounted at "kv" and a KV v2 engine
+// mounted at "secret".
+type fakeVault struct {
+	mtx    sync.Mutex
+	kvV1   map[string]map[string]any
+	kvV2   map[string]*fakeSecretV2
+	logins int
+
+	// failKV makes every KV request fail with an internal error.
+	failKV bool
+}
+
+type fakeSecretV2 struct {
+	// data is nil if the current version was deleted.
+	data    map[string]any
+	version int
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, string) {
+	v := &fakeVault{
+		kvV1: make(map[string]map[string]any),
+		kvV2: make(map[string]*fakeSecretV2),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/auth/token/lookup-self", v.authenticated(v.lookupSelf))
+	mux.HandleFunc("PUT /v1/auth/cert/login", v.certLogin)
+	mux.HandleFunc("PUT /v1/auth/approle/login", v.appRoleLogin)
+	mux.HandleFunc("PUT /v1/auth/kubernetes/login", v.k8sLogin)
+	mux.HandleFunc("GET /v1/kv/{path...}", v.authenticated(v.getV1))
+	mux.HandleFunc("PUT /v1/kv/{path...}", v.authenticated(v.putV1))
+	mux.HandleFunc("DELETE /v1/kv/{path...}", v.authenticated(v.deleteV1))
+	mux.HandleFunc("GET /v1/secret/data/{path...}", v.authenticated(v.getV2))
+	mux.HandleFunc("PUT /v1/secret/data/{path...}", v.authenticated(v.putV2))
+	mux.HandleFunc("DELETE /v1/secret/metadata/{path...}", v.authenticated(v.deleteV2))
+
+	cert, err := tls.LoadX509KeyPair(testServerCert, testServerKey)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequestClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return v, srv.URL
+}
+
+func (v *fakeVault) authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testToken {
+			writeResponse(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (v *fakeVault) lookupSelf(w http.ResponseWriter, _ *http.Request) {
+	writeResponse(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"id":        testToken,
+			"renewable": false,
+			"ttl":       0,
+		},
+	})
+}
+
+func (v *fakeVault) certLogin(w http.ResponseWriter, r *http.Request) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		writeResponse(w, http.StatusBadRequest, map[string]any{"errors": []string{"client certificate must be supplied"}})
+		return
+	}
+	v.login(w)
+}
+
+func (v *fakeVault) appRoleLogin(w http.ResponseWriter, r *http.Request) {
+	body := readBody(w, r)
+	if body == nil {
+		return
+	}
+	if body["role_id"] != testAppRoleID || body["secret_id"] != testAppRoleSecret {
+		writeResponse(w, http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret ID"}})
+		return
+	}
+	v.login(w)
+}
+
+func (v *fakeVault) k8sLogin(w http.ResponseWriter, r *http.Request) {
+	body := readBody(w, r)
+	if body == nil {
+		return
+	}
+	token, err := os.ReadFile(testK8sToken)
+	if err != nil || body["role"] != testK8sRole || body["jwt"] != string(token) {
+		writeResponse(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+	v.login(w)
+}
+
+func (v *fakeVault) login(w http.ResponseWriter) {
+	v.mtx.Lock()
+	v.logins++
+	v.mtx.Unlock()
+
+	writeResponse(w, http.StatusOK, map[string]any{
+		"auth": map[string]any{
+			"client_token":   testToken,
+			"lease_duration": 0,
+			"renewable":      false,
+		},
+	})
+}
+
+func (v *fakeVault) getV1(w http.ResponseWriter, r *http.Request) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if v.kvFailed(w) {
+		return
+	}
+
+	data, ok := v.kvV1[r.PathValue("path")]
+	if !ok {
+		writeResponse(w, http.StatusNotFound, map[string]any{"errors": []string{}})
+		return
+	}
+	writeResponse(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (v *fakeVault) putV1(w http.ResponseWriter, r *http.Request) {
+	body := readBody(w, r)
+	if body == nil {
+		return
+	}
+
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if v.kvFailed(w) {
+		return
+	}
+
+	v.kvV1[r.PathValue("path")] = body
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (v *fakeVault) deleteV1(w http.ResponseWriter, r *http.Request) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if v.kvFailed(w) {
+		return
+	}
+
+	delete(v.kvV1, r.PathValue("path"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (v *fakeVault) getV2(w http.ResponseWriter, r *http.Request) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if v.kvFailed(w) {
+		return
+	}
+
+	secret, ok := v.kvV2[r.PathValue("path")]
+	if !ok {
+		writeResponse(w, http.StatusNotFound, map[string]any{"errors": []string{}})
+		return
+	}
+
+	code := http.StatusOK
+	deletionTime := ""
+	if secret.data == nil {
+		code = http.StatusNotFound
+		deletionTime = time.Now().Format(time.RFC3339)
+	}
+	writeResponse(w, code, map[string]any{
+		"data": map[string]any{
+			"data":     secret.data,
+			"metadata": versionMetadata(secret.version, deletionTime),
+		},
+	})
+}
+
+func (v *fakeVault) putV2(w http.ResponseWriter, r *http.Request) {
+	body := readBody(w, r)
+	if body == nil {
+		return
+	}
+
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if v.kvFailed(w) {
+		return
+	}
+
+	path := r.PathValue("path")
+	current := 0
+	if secret, ok := v.kvV2[path]; ok {
+		current = secret.version
+	}
+
+	if options, ok := body["options"].(map[string]any); ok {
+		if cas, ok := options["cas"].(float64); ok && int(cas) != current {
+			writeResponse(w, http.StatusBadRequest, map[string]any{"errors": []string{"check-and-set parameter did not match the current version"}})
+			return
+		}
+	}
+
+	data, ok := body["data"].(map[string]any)
+	if !ok {
+		writeResponse(w, http.StatusBadRequest, map[string]any{"errors": []string{"no data provided"}})
+		return
+	}
+
+	v.kvV2[path] = &fakeSecretV2{data: data, version: current + 1}
+	writeResponse(w, http.StatusOK, map[string]any{"data": versionMetadata(current+1, "")})
+}
+
+func (v *fakeVault) deleteV2(w http.ResponseWriter, r *http.Request) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if v.kvFailed(w) {
+		return
+	}
+
+	delete(v.kvV2, r.PathValue("path"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (v *fakeVault) kvFailed(w http.ResponseWriter) bool {
+	if v.failKV {
+		writeResponse(w, http.StatusInternalServerError, map[string]any{"errors": []string{"internal error"}})
+	}
+	return v.failKV
+}
+
+func (v *fakeVault) setFailKV(fail bool) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	v.failKV = fail
+}
+
+func (v *fakeVault) getLogins() int {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	return v.logins
+}
+
+// putSecretV1 stores a secret in the KV v1 engine.
+func (v *fakeVault) putSecretV1(path string, data map[string]any) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	v.kvV1[path] = data
+}
+
+// putSecretV2 stores a secret in the KV v2 engine. A nil data simulates a
+// secret whose current version was deleted.
+func (v *fakeVault) putSecretV2(path string, data map[string]any, version int) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	v.kvV2[path] = &fakeSecretV2{data: data, version: version}
+}
+
+func (v *fakeVault) getSecretV1(path string) (map[string]any, bool) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	data, ok := v.kvV1[path]
+	return data, ok
+}
+
+func (v *fakeVault) getSecretV2(path string) (*fakeSecretV2, bool) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	secret, ok := v.kvV2[path]
+	return secret, ok
+}
+
+func versionMetadata(version int, deletionTime string) map[string]any {
+	return map[string]any{
+		"created_time":  time.Now().Format(time.RFC3339),
+		"deletion_time": deletionTime,
+		"destroyed":     false,
+		"version":       version,
+	}
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) map[string]any {
+	body := make(map[string]any)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeResponse(w, http.StatusBadRequest, map[string]any{"errors": []string{fmt.Sprintf("failed to parse JSON input: %v", err)}})
+		return nil
+	}
+	return body
+}
+
+func writeResponse(w http.ResponseWriter, code int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,317 @@
+package vaultkv
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/token"
+	svidstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/svidstore/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/server/common/vault"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "vault_kv"
+
+	defaultKVMountPoint = "secret"
+	defaultKVVersion    = 2
+
+	// managedByKey is added to the data of the secrets created by the plugin.
+	// Its value is the trust domain of the agent, so secrets created by other
+	// SPIRE deployments are not modified or removed.
+	managedByKey = "spire-svid"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		svidstorev1.SVIDStorePluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.lookupEnv = os.LookupEnv
+
+	return p
+}
+
+type Configuration struct {
+	vault.BaseConfiguration `hcl:",squash"`
+
+	// Name of the mount point where the KV secrets engine is mounted.
+	KVMountPoint string `hcl:"kv_mount_point" json:"kv_mount_point"`
+	// Version of the KV secrets engine, 1 or 2.
+	KVVersion int `hcl:"kv_version" json:"kv_version"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions" json:",omitempty"`
+}
+
+type kvConfig struct {
+	trustDomain  string
+	kvMountPoint string
+	kvVersion    int
+	authMethod   vault.AuthMethod
+	cc           *vault.ClientConfig
+}
+
+type Plugin struct {
+	svidstorev1.UnsafeSVIDStoreServer
+	configv1.UnsafeConfigServer
+
+	log    hclog.Logger
+	mtx    sync.Mutex
+	config *kvConfig
+	vc     *vault.Client
+
+	hooks struct {
+		lookupEnv func(string) (string, bool)
+	}
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Configuration {
+	newConfig := new(Configuration)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	pluginconf.ReportUnusedKeys(status, newConfig.UnusedKeyPositions)
+
+	if newConfig.KVMountPoint == "" {
+		newConfig.KVMountPoint = defaultKVMountPoint
+	}
+	switch newConfig.KVVersion {
+	case 0:
+		newConfig.KVVersion = defaultKVVersion
+	case 1, 2:
+	default:
+		status.ReportErrorf("invalid kv_version %d: expected 1 or 2", newConfig.KVVersion)
+	}
+
+	return newConfig
+}
+
+// Configure configures the plugin. Authentication to Vault happens on the
+// first operation, so the agent can start while Vault is unavailable.
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	am, err := vault.ParseAuthMethod(&newConfig.BaseConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := vault.GenClientParams(am, &newConfig.BaseConfiguration, p.hooks.lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	vcConfig, err := vault.NewClientConfig(cp, p.log)
+	if err != nil {
+		return nil, err
+	}
+
+	if newConfig.InsecureSkipVerify {
+		p.log.Warn("TLS verification of Vault certificates is skipped. This is only recommended for test environments.")
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = &kvConfig{
+		trustDomain:  req.CoreConfiguration.TrustDomain,
+		kvMountPoint: newConfig.KVMountPoint,
+		kvVersion:    newConfig.KVVersion,
+		authMethod:   am,
+		cc:           vcConfig,
+	}
+	p.vc = nil // force re-authentication with the new config
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+// PutX509SVID writes the specified X509-SVID to the KV path of the entry,
+// creating the secret if it does not exist.
+func (p *Plugin) PutX509SVID(ctx context.Context, req *svidstorev1.PutX509SVIDRequest) (*svidstorev1.PutX509SVIDResponse, error) {
+	path, err := pathFromMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	secretData, err := svidstore.SecretFromProto(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: %v", err)
+	}
+
+	kv, trustDomain, err := p.getKVStore()
+	if err != nil {
+		return nil, err
+	}
+
+	version, found, err := getSecret(ctx, kv, path, trustDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	federatedBundles := make(map[string]any, len(secretData.FederatedBundles))
+	for td, bundle := range secretData.FederatedBundles {
+		federatedBundles[td] = bundle
+	}
+
+	// Keys match the JSON format used by the other SVIDStore plugins.
+	data := map[string]any{
+		"spiffeID":         secretData.SPIFFEID,
+		"x509SVID":         secretData.X509SVID,
+		"x509SVIDKey":      secretData.X509SVIDKey,
+		"bundle":           secretData.Bundle,
+		"federatedBundles": federatedBundles,
+		managedByKey:       trustDomain,
+	}
+
+	if err := kv.Put(ctx, path, data, version); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write secret: %v", err)
+	}
+
+	if found {
+		p.log.With("path", path).Debug("Secret updated")
+	} else {
+		p.log.With("path", path).Debug("Secret created")
+	}
+	return &svidstorev1.PutX509SVIDResponse{}, nil
+}
+
+// DeleteX509SVID deletes the secret at the KV path of the entry, if it was
+// created by this SPIRE deployment.
+func (p *Plugin) DeleteX509SVID(ctx context.Context, req *svidstorev1.DeleteX509SVIDRequest) (*svidstorev1.DeleteX509SVIDResponse, error) {
+	path, err := pathFromMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, trustDomain, err := p.getKVStore()
+	if err != nil {
+		return nil, err
+	}
+
+	log := p.log.With("path", path)
+
+	_, found, err := getSecret(ctx, kv, path, trustDomain)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		log.Debug("Secret to delete not found")
+		return &svidstorev1.DeleteX509SVIDResponse{}, nil
+	}
+
+	if err := kv.Delete(ctx, path); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete secret: %v", err)
+	}
+
+	log.Debug("Secret deleted")
+	return &svidstorev1.DeleteX509SVIDResponse{}, nil
+}
+
+// getKVStore returns a store backed by an authenticated client, and the trust
+// domain of the agent.
+func (p *Plugin) getKVStore() (kvStore, string, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.config == nil {
+		return nil, "", status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	if p.vc == nil {
+		if err := p.genVaultClient(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return newKVStore(p.vc.VaultClient(), p.config.kvMountPoint, p.config.kvVersion), p.config.trustDomain, nil
+}
+
+func (p *Plugin) genVaultClient() error {
+	renewCh := make(chan struct{})
+	vc, err := p.config.cc.NewAuthenticatedClient(p.config.authMethod, renewCh)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to prepare authenticated client: %v", err)
+	}
+	p.vc = vc
+
+	// If renewCh is closed, the token has expired and cannot be renewed.
+	// Capture vc so that a re-configured plugin with a new client does not get
+	// nil'd out by a stale goroutine from a previous Configure call.
+	go func(myVC *vault.Client) {
+		<-renewCh
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+		if p.vc == myVC {
+			p.vc = nil
+			p.log.Debug("Going to re-authenticate to the Vault during the next operation")
+		}
+	}(vc)
+
+	return nil
+}
+
+// getSecret returns the version of the secret at the given path, and
+// validates that it was created by this SPIRE deployment.
+func getSecret(ctx context.Context, kv kvStore, path, trustDomain string) (int, bool, error) {
+	data, version, err := kv.Get(ctx, path)
+	if err != nil {
+		return 0, false, status.Errorf(codes.Internal, "failed to read secret: %v", err)
+	}
+	if data == nil {
+		return version, false, nil
+	}
+
+	if managedBy, _ := data[managedByKey].(string); managedBy != trustDomain {
+		return 0, false, status.Error(codes.InvalidArgument, "secret is not managed by this SPIRE deployment")
+	}
+
+	return version, true, nil
+}
+
+func pathFromMetadata(metadata []string) (string, error) {
+	data, err := svidstore.ParseMetadata(metadata)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	path := strings.Trim(data["path"], "/")
+	if path == "" {
+		return "", status.Error(codes.InvalidArgument, "path is required")
+	}
+
+	return path, nil
+}
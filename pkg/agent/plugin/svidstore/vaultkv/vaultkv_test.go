@@ -0,0 +1,478 @@
+package vaultkv
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/common/vault"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+	workloadID  = spiffeid.RequireFromString("spiffe://example.org/workload")
+)
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		customConfig       string
+		expectCode         codes.Code
+		expectMsgPrefix    string
+		expectAuthMethod   vault.AuthMethod
+		expectKVMountPoint string
+		expectKVVersion    int
+	}{
+		{
+			name: "defaults",
+			customConfig: `
+				vault_addr = "https://127.0.0.1:8200"
+				token_auth { token = "test-token" }
+			`,
+			expectAuthMethod:   vault.TOKEN,
+			expectKVMountPoint: "secret",
+			expectKVVersion:    2,
+		},
+		{
+			name: "kv v1",
+			customConfig: `
+				vault_addr = "https://127.0.0.1:8200"
+				kv_mount_point = "kv"
+				kv_version = 1
+				approle_auth {
+					approle_id = "test-approle-id"
+					approle_secret_id = "test-approle-secret-id"
+				}
+			`,
+			expectAuthMethod:   vault.APPROLE,
+			expectKVMountPoint: "kv",
+			expectKVVersion:    1,
+		},
+		{
+			name: "invalid kv version",
+			customConfig: `
+				vault_addr = "https://127.0.0.1:8200"
+				kv_version = 3
+				token_auth { token = "test-token" }
+			`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "invalid kv_version 3: expected 1 or 2",
+		},
+		{
+			name:            "no authentication method",
+			customConfig:    `vault_addr = "https://127.0.0.1:8200"`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "must be configured one of these authentication method",
+		},
+		{
+			name: "multiple authentication methods",
+			customConfig: `
+				vault_addr = "https://127.0.0.1:8200"
+				token_auth { token = "test-token" }
+				k8s_auth {
+					k8s_auth_role_name = "my-role"
+					token_path = "testdata/k8s/token"
+				}
+			`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "only one authentication method can be configured",
+		},
+		{
+			name:            "malformed configuration",
+			customConfig:    "{no a config}",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unable to decode configuration:",
+		},
+		{
+			name: "contains unused keys",
+			customConfig: `
+				vault_addr = "https://127.0.0.1:8200"
+				token_auth { token = "test-token" }
+				invalid1 = "something"
+			`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unknown configurations detected: invalid1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.hooks.lookupEnv = func(string) (string, bool) { return "", false }
+
+			var err error
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+				plugintest.Configure(tt.customConfig),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, p.config)
+				return
+			}
+
+			require.NotNil(t, p.config)
+			require.Equal(t, trustDomain.Name(), p.config.trustDomain)
+			require.Equal(t, tt.expectAuthMethod, p.config.authMethod)
+			require.Equal(t, tt.expectKVMountPoint, p.config.kvMountPoint)
+			require.Equal(t, tt.expectKVVersion, p.config.kvVersion)
+		})
+	}
+}
+
+func TestPutX509SVID(t *testing.T) {
+	ca := testca.New(t, trustDomain)
+	svid := ca.CreateX509SVID(workloadID)
+	federatedCA := testca.New(t, spiffeid.RequireTrustDomainFromString("federated.test"))
+
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+
+	req := &svidstore.X509SVID{
+		SVID: &svidstore.SVID{
+			SPIFFEID:   workloadID,
+			CertChain:  svid.Certificates,
+			PrivateKey: svid.PrivateKey,
+			Bundle:     ca.X509Authorities(),
+			ExpiresAt:  time.Now().Add(time.Hour),
+		},
+		Metadata: []string{"path:/workloads/workload-tls/"},
+		FederatedBundles: map[string][]*x509.Certificate{
+			"federated.test": federatedCA.X509Authorities(),
+		},
+	}
+
+	// Values go through a JSON round trip in the fake server
+	expectData := map[string]any{
+		"spiffeID":    workloadID.String(),
+		"x509SVID":    string(pemutil.EncodeCertificates(svid.Certificates)),
+		"x509SVIDKey": string(keyPEM),
+		"bundle":      string(pemutil.EncodeCertificates(ca.X509Authorities())),
+		"federatedBundles": map[string]any{
+			"federated.test": string(pemutil.EncodeCertificates(federatedCA.X509Authorities())),
+		},
+		"spire-svid": "example.org",
+	}
+
+	for _, tt := range []struct {
+		name            string
+		config          string
+		metadata        []string
+		existingV1      map[string]any
+		existingV2      *fakeSecretV2
+		failKV          bool
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectVersion   int
+	}{
+		{
+			name:          "create secret in kv v2",
+			config:        tokenAuthConfig,
+			expectVersion: 1,
+		},
+		{
+			name:   "create secret in kv v1",
+			config: tokenAuthConfig + kvV1Config,
+		},
+		{
+			name:          "create secret with cert authentication",
+			config:        certAuthConfig,
+			expectVersion: 1,
+		},
+		{
+			name:          "create secret with AppRole authentication",
+			config:        appRoleAuthConfig,
+			expectVersion: 1,
+		},
+		{
+			name:          "create secret with Kubernetes authentication",
+			config:        k8sAuthConfig,
+			expectVersion: 1,
+		},
+		{
+			name:   "update secret in kv v2",
+			config: tokenAuthConfig,
+			existingV2: &fakeSecretV2{
+				data:    map[string]any{"x509SVID": "OLD", "stale": "STALE", "spire-svid": "example.org"},
+				version: 3,
+			},
+			expectVersion: 4,
+		},
+		{
+			name:       "update secret in kv v1",
+			config:     tokenAuthConfig + kvV1Config,
+			existingV1: map[string]any{"x509SVID": "OLD", "stale": "STALE", "spire-svid": "example.org"},
+		},
+		{
+			name:          "current version of the secret was deleted",
+			config:        tokenAuthConfig,
+			existingV2:    &fakeSecretV2{version: 2},
+			expectVersion: 3,
+		},
+		{
+			name:   "secret not managed by SPIRE",
+			config: tokenAuthConfig,
+			existingV2: &fakeSecretV2{
+				data:    map[string]any{"x509SVID": "OTHER"},
+				version: 1,
+			},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(vault_kv): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name:            "secret managed by another trust domain",
+			config:          tokenAuthConfig + kvV1Config,
+			existingV1:      map[string]any{"spire-svid": "another.org"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(vault_kv): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name:            "missing path",
+			config:          tokenAuthConfig,
+			metadata:        []string{"path:/"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(vault_kv): path is required",
+		},
+		{
+			name:            "invalid metadata",
+			config:          tokenAuthConfig,
+			metadata:        []string{"path"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `svidstore(vault_kv): invalid metadata: metadata does not contain a colon: "path"`,
+		},
+		{
+			name:            "failed to authenticate",
+			config:          `approle_auth { approle_id = "unknown" approle_secret_id = "unknown" }`,
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(vault_kv): failed to prepare authenticated client:",
+		},
+		{
+			name:            "failed to read secret",
+			config:          tokenAuthConfig,
+			failKV:          true,
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(vault_kv): failed to read secret:",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fv, addr := newFakeVault(t)
+			fv.setFailKV(tt.failKV)
+			if tt.existingV1 != nil {
+				fv.putSecretV1("workloads/workload-tls", tt.existingV1)
+			}
+			if tt.existingV2 != nil {
+				fv.putSecretV2("workloads/workload-tls", tt.existingV2.data, tt.existingV2.version)
+			}
+
+			ss := loadPlugin(t, addr, tt.config)
+
+			putReq := *req
+			if tt.metadata != nil {
+				putReq.Metadata = tt.metadata
+			}
+			err := ss.PutX509SVID(context.Background(), &putReq)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+			if tt.expectCode != codes.OK {
+				return
+			}
+
+			if tt.expectVersion == 0 {
+				data, ok := fv.getSecretV1("workloads/workload-tls")
+				require.True(t, ok)
+				require.Equal(t, expectData, data)
+				return
+			}
+
+			secret, ok := fv.getSecretV2("workloads/workload-tls")
+			require.True(t, ok)
+			require.Equal(t, expectData, secret.data)
+			require.Equal(t, tt.expectVersion, secret.version)
+		})
+	}
+}
+
+func TestPutX509SVIDReusesClient(t *testing.T) {
+	ca := testca.New(t, trustDomain)
+	svid := ca.CreateX509SVID(workloadID)
+
+	fv, addr := newFakeVault(t)
+	ss := loadPlugin(t, addr, appRoleAuthConfig)
+
+	req := &svidstore.X509SVID{
+		SVID: &svidstore.SVID{
+			SPIFFEID:   workloadID,
+			CertChain:  svid.Certificates,
+			PrivateKey: svid.PrivateKey,
+			Bundle:     ca.X509Authorities(),
+			ExpiresAt:  time.Now().Add(time.Hour),
+		},
+		Metadata: []string{"path:workloads/workload-tls"},
+	}
+
+	// The plugin authenticates on the first operation only
+	require.Equal(t, 0, fv.getLogins())
+	require.NoError(t, ss.PutX509SVID(context.Background(), req))
+	require.NoError(t, ss.PutX509SVID(context.Background(), req))
+	require.Equal(t, 1, fv.getLogins())
+
+	secret, ok := fv.getSecretV2("workloads/workload-tls")
+	require.True(t, ok)
+	require.Equal(t, 2, secret.version)
+}
+
+func TestDeleteX509SVID(t *testing.T) {
+	managedData := map[string]any{"spire-svid": "example.org"}
+
+	for _, tt := range []struct {
+		name            string
+		config          string
+		metadata        []string
+		existingV1      map[string]any
+		existingV2      *fakeSecretV2
+		failKV          bool
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectDeleted   bool
+	}{
+		{
+			name:          "delete secret in kv v2",
+			config:        tokenAuthConfig,
+			existingV2:    &fakeSecretV2{data: managedData, version: 2},
+			expectDeleted: true,
+		},
+		{
+			name:          "delete secret in kv v1",
+			config:        tokenAuthConfig + kvV1Config,
+			existingV1:    managedData,
+			expectDeleted: true,
+		},
+		{
+			name:   "secret not found",
+			config: tokenAuthConfig,
+		},
+		{
+			name:       "current version of the secret was deleted",
+			config:     tokenAuthConfig,
+			existingV2: &fakeSecretV2{version: 2},
+		},
+		{
+			name:   "secret not managed by SPIRE",
+			config: tokenAuthConfig,
+			existingV2: &fakeSecretV2{
+				data:    map[string]any{"x509SVID": "OTHER"},
+				version: 1,
+			},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(vault_kv): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name:            "missing path",
+			config:          tokenAuthConfig,
+			metadata:        []string{"other:value"},
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "svidstore(vault_kv): path is required",
+		},
+		{
+			name:            "failed to read secret",
+			config:          tokenAuthConfig,
+			existingV2:      &fakeSecretV2{data: managedData, version: 2},
+			failKV:          true,
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "svidstore(vault_kv): failed to read secret:",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fv, addr := newFakeVault(t)
+			fv.setFailKV(tt.failKV)
+			if tt.existingV1 != nil {
+				fv.putSecretV1("workloads/workload-tls", tt.existingV1)
+			}
+			if tt.existingV2 != nil {
+				fv.putSecretV2("workloads/workload-tls", tt.existingV2.data, tt.existingV2.version)
+			}
+
+			ss := loadPlugin(t, addr, tt.config)
+
+			metadata := tt.metadata
+			if metadata == nil {
+				metadata = []string{"path:workloads/workload-tls"}
+			}
+			err := ss.DeleteX509SVID(context.Background(), metadata)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+
+			_, foundV1 := fv.getSecretV1("workloads/workload-tls")
+			_, foundV2 := fv.getSecretV2("workloads/workload-tls")
+			existed := tt.existingV1 != nil || tt.existingV2 != nil
+			require.Equal(t, tt.expectDeleted, existed && !foundV1 && !foundV2)
+		})
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	ss := new(svidstore.V1)
+	plugintest.Load(t, BuiltIn(), ss)
+
+	err := ss.DeleteX509SVID(context.Background(), []string{"path:workloads/workload-tls"})
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "svidstore(vault_kv): not configured")
+}
+
+const (
+	tokenAuthConfig = `token_auth { token = "test-token" }`
+
+	certAuthConfig = `
+		cert_auth {
+			client_cert_path = "testdata/client-cert.pem"
+			client_key_path = "testdata/client-key.pem"
+		}
+	`
+
+	appRoleAuthConfig = `
+		approle_auth {
+			approle_id = "test-approle-id"
+			approle_secret_id = "test-approle-secret-id"
+		}
+	`
+
+	k8sAuthConfig = `
+		k8s_auth {
+			k8s_auth_role_name = "my-role"
+			token_path = "testdata/k8s/token"
+		}
+	`
+
+	kvV1Config = `
+		kv_mount_point = "kv"
+		kv_version = 1
+	`
+)
+
+func loadPlugin(t *testing.T, addr, config string) svidstore.SVIDStore {
+	p := New()
+	p.hooks.lookupEnv = func(string) (string, bool) { return "", false }
+
+	ss := new(svidstore.V1)
+	plugintest.Load(t, builtin(p), ss,
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: trustDomain,
+		}),
+		plugintest.Configure(fmt.Sprintf(`
+			vault_addr = %q
+			ca_cert_path = "testdata/root-cert.pem"
+			%s
+		`, addr, config)),
+	)
+	return ss
+}
@@ -0,0 +1,11 @@
+package cri
+
+import "github.com/spiffe/spire/pkg/common/catalog"
+
+const (
+	pluginName = "cri"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
@@ -0,0 +1,282 @@
+//go:build !windows
+
+package cri
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/token"
+	workloadattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/workloadattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/common/sigstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/containerinfo"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+const (
+	defaultRuntimeSocketPath = "/run/containerd/containerd.sock"
+
+	selectorContainerID       = "container-id"
+	selectorContainerName     = "container-name"
+	selectorContainerLabel    = "container-label"
+	selectorContainerImage    = "container-image"
+	selectorContainerImageID  = "container-image-id"
+	selectorContainerImageRef = "container-image-ref"
+	selectorSandboxName       = "sandbox-name"
+	selectorSandboxNamespace  = "sandbox-namespace"
+	selectorSandboxUID        = "sandbox-uid"
+	selectorSandboxLabel      = "sandbox-label"
+)
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		workloadattestorv1.WorkloadAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Configuration struct {
+	// RuntimeSocketPath is the path to the CRI runtime socket (default:
+	// "/run/containerd/containerd.sock").
+	RuntimeSocketPath string `hcl:"runtime_socket_path" json:"runtime_socket_path"`
+
+	// VerboseContainerLocatorLogs, if true, dumps extra information to the log
+	// about mountinfo and cgroup information used to locate the container.
+	VerboseContainerLocatorLogs bool `hcl:"verbose_container_locator_logs" json:"verbose_container_locator_logs"`
+
+	// Sigstore contains sigstore specific configs.
+	Sigstore *sigstore.HCLConfig `hcl:"sigstore,omitempty" json:"sigstore"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+
+	sigstoreConfig *sigstore.Config
+}
+
+func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Configuration {
+	newConfig := new(Configuration)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	pluginconf.ReportUnusedKeys(status, newConfig.UnusedKeyPositions)
+
+	if newConfig.RuntimeSocketPath == "" {
+		newConfig.RuntimeSocketPath = defaultRuntimeSocketPath
+	}
+	if !filepath.IsAbs(newConfig.RuntimeSocketPath) {
+		status.ReportErrorf("runtime_socket_path must be an absolute path: %q", newConfig.RuntimeSocketPath)
+	}
+
+	if newConfig.Sigstore != nil {
+		if err := newConfig.Sigstore.Validate(); err != nil {
+			status.ReportErrorf("invalid sigstore configuration: %v", err)
+		}
+		newConfig.sigstoreConfig = sigstore.NewConfigFromHCL(newConfig.Sigstore, p.log)
+	}
+
+	return newConfig
+}
+
+type Plugin struct {
+	workloadattestorv1.UnsafeWorkloadAttestorServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+
+	// Used by tests to use a fake /proc directory instead of the real one
+	rootDir string
+
+	mtx                         sync.RWMutex
+	conn                        *grpc.ClientConn
+	runtime                     runtimeapi.RuntimeServiceClient
+	verboseContainerLocatorLogs bool
+	sigstoreVerifier            sigstore.Verifier
+}
+
+func New() *Plugin {
+	return &Plugin{
+		rootDir: "/",
+	}
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestRequest) (*workloadattestorv1.AttestResponse, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.runtime == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	extractor := containerinfo.Extractor{RootDir: p.rootDir, VerboseLogging: p.verboseContainerLocatorLogs}
+	containerID, err := extractor.GetContainerID(req.Pid, p.log)
+	switch {
+	case err != nil:
+		return nil, err
+	case containerID == "":
+		// Not a containerized workload. Nothing more to do.
+		return &workloadattestorv1.AttestResponse{}, nil
+	}
+	log := p.log.With(telemetry.ContainerID, containerID)
+
+	// The container returned by ListContainers holds the sandbox ID, which
+	// is not part of the container status.
+	listResp, err := p.runtime.ListContainers(ctx, &runtimeapi.ListContainersRequest{
+		Filter: &runtimeapi.ContainerFilter{Id: containerID},
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to list containers: %v", err)
+	}
+	if len(listResp.Containers) == 0 {
+		// The container is not managed by this CRI runtime.
+		log.Debug("Container not found in the CRI runtime")
+		return &workloadattestorv1.AttestResponse{}, nil
+	}
+	container := listResp.Containers[0]
+
+	statusResp, err := p.runtime.ContainerStatus(ctx, &runtimeapi.ContainerStatusRequest{
+		ContainerId: containerID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get container status: %v", err)
+	}
+	containerStatus := statusResp.Status
+	if containerStatus == nil {
+		return nil, status.Error(codes.Internal, "runtime returned no container status")
+	}
+
+	sandboxResp, err := p.runtime.PodSandboxStatus(ctx, &runtimeapi.PodSandboxStatusRequest{
+		PodSandboxId: container.PodSandboxId,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get sandbox status: %v", err)
+	}
+
+	selectorValues := getSelectorValuesFromContainerStatus(containerID, containerStatus)
+	selectorValues = append(selectorValues, getSelectorValuesFromSandboxStatus(sandboxResp.Status)...)
+
+	if p.sigstoreVerifier != nil {
+		// The image reference holds the repository digest of the image when
+		// the runtime knows it.
+		imageRef := containerStatus.ImageRef
+		log.Debug("Attempting to verify sigstore image signature", telemetry.ImageID, imageRef)
+		sigstoreSelectors, err := p.sigstoreVerifier.Verify(ctx, imageRef)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error verifying sigstore image signature for image %s: %v", imageRef, err)
+		}
+		selectorValues = append(selectorValues, sigstoreSelectors...)
+	}
+
+	return &workloadattestorv1.AttestResponse{
+		SelectorValues: selectorValues,
+	}, nil
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, p.buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient("unix://"+newConfig.RuntimeSocketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to create CRI runtime client: %v", err)
+	}
+
+	var sigstoreVerifier sigstore.Verifier
+	if newConfig.sigstoreConfig != nil {
+		verifier := sigstore.NewVerifier(newConfig.sigstoreConfig)
+		if err := verifier.Init(ctx); err != nil {
+			conn.Close()
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing sigstore verifier: %v", err)
+		}
+		sigstoreVerifier = verifier
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn = conn
+	p.runtime = runtimeapi.NewRuntimeServiceClient(conn)
+	p.verboseContainerLocatorLogs = newConfig.VerboseContainerLocatorLogs
+	p.sigstoreVerifier = sigstoreVerifier
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, p.buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *Plugin) Close() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.conn != nil {
+		return p.conn.Close()
+	}
+	return nil
+}
+
+func getSelectorValuesFromContainerStatus(containerID string, containerStatus *runtimeapi.ContainerStatus) []string {
+	selectorValues := []string{
+		fmt.Sprintf("%s:%s", selectorContainerID, containerID),
+	}
+	if name := containerStatus.Metadata.GetName(); name != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorContainerName, name))
+	}
+	for label, value := range containerStatus.Labels {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s:%s", selectorContainerLabel, label, value))
+	}
+	if image := containerStatus.Image.GetImage(); image != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorContainerImage, image))
+	}
+	if containerStatus.ImageId != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorContainerImageID, containerStatus.ImageId))
+	}
+	if containerStatus.ImageRef != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorContainerImageRef, containerStatus.ImageRef))
+	}
+	return selectorValues
+}
+
+func getSelectorValuesFromSandboxStatus(sandboxStatus *runtimeapi.PodSandboxStatus) []string {
+	var selectorValues []string
+	if metadata := sandboxStatus.GetMetadata(); metadata != nil {
+		if metadata.Name != "" {
+			selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorSandboxName, metadata.Name))
+		}
+		if metadata.Namespace != "" {
+			selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorSandboxNamespace, metadata.Namespace))
+		}
+		if metadata.Uid != "" {
+			selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorSandboxUID, metadata.Uid))
+		}
+	}
+	for label, value := range sandboxStatus.GetLabels() {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s:%s", selectorSandboxLabel, label, value))
+	}
+	return selectorValues
+}
@@ -0,0 +1,349 @@
+//go:build !windows
+
+package cri
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+const (
+	testContainerID = "6469646e742065787065637420616e796f6e6520746f20726561642074686973"
+	testSandboxID   = "73616e64626f782d69642d6f662d7468652d746573742d636f6e7461696e6572"
+	testImageRef    = "docker.io/library/nginx@sha256:2a1d6b0ab1d6e0e46d0b3f7b6e7f1d9c42b1c1e3a04f0f6fc58a7bd0e1f6f7a5"
+
+	testContainerdCgroupEntries = "0::/system.slice/containerd.service/6469646e742065787065637420616e796f6e6520746f20726561642074686973"
+	testHostCgroupEntries       = "0::/user.slice/user-1000.slice/session-1.scope"
+)
+
+var (
+	ctx = context.Background()
+
+	testContainerSelectors = []string{
+		"container-id:" + testContainerID,
+		"container-image-id:sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"container-image-ref:" + testImageRef,
+		"container-image:docker.io/library/nginx:latest",
+		"container-label:app:web",
+		"container-label:tier:frontend",
+		"container-name:nginx",
+		"sandbox-label:app:web",
+		"sandbox-name:web",
+		"sandbox-namespace:default",
+		"sandbox-uid:d1c6e2a4-4b3f-4c5e-9a6f-0e2b7d8c9f10",
+	}
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		cgroups         string
+		runtime         *fakeRuntime
+		expectSelectors []string
+		expectCode      codes.Code
+		expectMsg       string
+	}{
+		{
+			name:            "containerd container",
+			cgroups:         testContainerdCgroupEntries,
+			runtime:         newFakeRuntime(),
+			expectSelectors: testContainerSelectors,
+		},
+		{
+			name:    "not a container",
+			cgroups: testHostCgroupEntries,
+			runtime: newFakeRuntime(),
+		},
+		{
+			name:    "container not managed by the runtime",
+			cgroups: testContainerdCgroupEntries,
+			runtime: &fakeRuntime{},
+		},
+		{
+			name:    "sandbox without metadata",
+			cgroups: testContainerdCgroupEntries,
+			runtime: func() *fakeRuntime {
+				r := newFakeRuntime()
+				r.sandbox = &runtimeapi.PodSandboxStatus{Id: testSandboxID}
+				return r
+			}(),
+			expectSelectors: testContainerSelectors[:7],
+		},
+		{
+			name:    "fails to list containers",
+			cgroups: testContainerdCgroupEntries,
+			runtime: func() *fakeRuntime {
+				r := newFakeRuntime()
+				r.listErr = errors.New("oh no")
+				return r
+			}(),
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(cri): unable to list containers: rpc error: code = Unknown desc = oh no",
+		},
+		{
+			name:    "fails to get container status",
+			cgroups: testContainerdCgroupEntries,
+			runtime: func() *fakeRuntime {
+				r := newFakeRuntime()
+				r.containerStatus = nil
+				return r
+			}(),
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(cri): unable to get container status: rpc error: code = NotFound desc = container not found",
+		},
+		{
+			name:    "fails to get sandbox status",
+			cgroups: testContainerdCgroupEntries,
+			runtime: func() *fakeRuntime {
+				r := newFakeRuntime()
+				r.sandbox = nil
+				return r
+			}(),
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(cri): unable to get sandbox status: rpc error: code = NotFound desc = sandbox not found",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlugin(t, tt.runtime, tt.cgroups)
+
+			selectors, err := doAttest(t, p)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			require.Equal(t, tt.expectSelectors, selectors)
+		})
+	}
+}
+
+func TestAttestWithSigstore(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		verifier        *fakeSigstoreVerifier
+		expectSelectors []string
+		expectCode      codes.Code
+		expectMsg       string
+	}{
+		{
+			name: "signature verified",
+			verifier: &fakeSigstoreVerifier{
+				expectedImageID: testImageRef,
+				selectors:       []string{"image-signature:verified"},
+			},
+			expectSelectors: append(append(append([]string{}, testContainerSelectors[:7]...), "image-signature:verified"), testContainerSelectors[7:]...),
+		},
+		{
+			name: "signature verification fails",
+			verifier: &fakeSigstoreVerifier{
+				expectedImageID: testImageRef,
+				err:             errors.New("no signatures found"),
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(cri): error verifying sigstore image signature for image " + testImageRef + ": no signatures found",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlugin(t, newFakeRuntime(), testContainerdCgroupEntries)
+			p.sigstoreVerifier = tt.verifier
+
+			selectors, err := doAttest(t, p)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			require.Equal(t, tt.expectSelectors, selectors)
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		config           string
+		expectSocketPath string
+		expectCode       codes.Code
+		expectMsg        string
+	}{
+		{
+			name:             "defaults",
+			expectSocketPath: "unix://" + defaultRuntimeSocketPath,
+		},
+		{
+			name:             "custom runtime socket",
+			config:           `runtime_socket_path = "/var/run/crio/crio.sock"`,
+			expectSocketPath: "unix:///var/run/crio/crio.sock",
+		},
+		{
+			name:       "relative runtime socket",
+			config:     `runtime_socket_path = "crio.sock"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `runtime_socket_path must be an absolute path: "crio.sock"`,
+		},
+		{
+			name: "invalid sigstore configuration",
+			config: `
+				sigstore {
+					ignore_attestations = true
+					required_predicate_types = ["https://slsa.dev/provenance/v1"]
+				}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid sigstore configuration: required_predicate_types cannot be used when ignoring attestations",
+		},
+		{
+			name:       "malformed configuration",
+			config:     "{ not a config }",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			err := doConfigure(t, p, tt.config)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, p.runtime)
+				return
+			}
+			require.Equal(t, tt.expectSocketPath, p.conn.Target())
+		})
+	}
+}
+
+func doAttest(t *testing.T, p *Plugin) ([]string, error) {
+	wp := new(workloadattestor.V1)
+	plugintest.Load(t, builtin(p), wp)
+	selectors, err := wp.Attest(ctx, 123)
+	if err != nil {
+		return nil, err
+	}
+	var selectorValues []string
+	for _, selector := range selectors {
+		require.Equal(t, pluginName, selector.Type)
+		selectorValues = append(selectorValues, selector.Value)
+	}
+	sort.Strings(selectorValues)
+	return selectorValues, nil
+}
+
+func doConfigure(t *testing.T, p *Plugin, cfg string) error {
+	var err error
+	plugintest.Load(t, builtin(p), new(workloadattestor.V1),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(cfg),
+		plugintest.CaptureConfigureError(&err))
+	return err
+}
+
+// newTestPlugin returns a plugin configured to talk to the given fake
+// runtime, with a fake /proc holding the cgroups of the workload.
+func newTestPlugin(t *testing.T, runtime *fakeRuntime, cgroups string) *Plugin {
+	socketPath := startFakeRuntime(t, runtime)
+
+	p := New()
+	require.NoError(t, doConfigure(t, p, fmt.Sprintf("runtime_socket_path = %q", socketPath)))
+
+	p.rootDir = spiretest.TempDir(t)
+	procPidPath := filepath.Join(p.rootDir, "proc", "123")
+	require.NoError(t, os.MkdirAll(procPidPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procPidPath, "cgroup"), []byte(cgroups), 0600))
+	return p
+}
+
+func startFakeRuntime(t *testing.T, runtime *fakeRuntime) string {
+	socketPath := filepath.Join(spiretest.TempDir(t), "cri.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	runtimeapi.RegisterRuntimeServiceServer(server, runtime)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return socketPath
+}
+
+type fakeRuntime struct {
+	runtimeapi.UnimplementedRuntimeServiceServer
+
+	container       *runtimeapi.Container
+	containerStatus *runtimeapi.ContainerStatus
+	sandbox         *runtimeapi.PodSandboxStatus
+	listErr         error
+}
+
+func newFakeRuntime() *fakeRuntime {
+	labels := map[string]string{"app": "web", "tier": "frontend"}
+	return &fakeRuntime{
+		container: &runtimeapi.Container{
+			Id:           testContainerID,
+			PodSandboxId: testSandboxID,
+		},
+		containerStatus: &runtimeapi.ContainerStatus{
+			Id:       testContainerID,
+			Metadata: &runtimeapi.ContainerMetadata{Name: "nginx"},
+			Image:    &runtimeapi.ImageSpec{Image: "docker.io/library/nginx:latest"},
+			ImageRef: testImageRef,
+			ImageId:  "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			Labels:   labels,
+		},
+		sandbox: &runtimeapi.PodSandboxStatus{
+			Id: testSandboxID,
+			Metadata: &runtimeapi.PodSandboxMetadata{
+				Name:      "web",
+				Namespace: "default",
+				Uid:       "d1c6e2a4-4b3f-4c5e-9a6f-0e2b7d8c9f10",
+			},
+			Labels: map[string]string{"app": "web"},
+		},
+	}
+}
+
+func (r *fakeRuntime) ListContainers(_ context.Context, req *runtimeapi.ListContainersRequest) (*runtimeapi.ListContainersResponse, error) {
+	if r.listErr != nil {
+		return nil, r.listErr
+	}
+	resp := new(runtimeapi.ListContainersResponse)
+	if r.container != nil && r.container.Id == req.GetFilter().GetId() {
+		resp.Containers = append(resp.Containers, r.container)
+	}
+	return resp, nil
+}
+
+func (r *fakeRuntime) ContainerStatus(_ context.Context, req *runtimeapi.ContainerStatusRequest) (*runtimeapi.ContainerStatusResponse, error) {
+	if r.containerStatus == nil || r.containerStatus.Id != req.ContainerId {
+		return nil, status.Error(codes.NotFound, "container not found")
+	}
+	return &runtimeapi.ContainerStatusResponse{Status: r.containerStatus}, nil
+}
+
+func (r *fakeRuntime) PodSandboxStatus(_ context.Context, req *runtimeapi.PodSandboxStatusRequest) (*runtimeapi.PodSandboxStatusResponse, error) {
+	if r.sandbox == nil || r.sandbox.Id != req.PodSandboxId {
+		return nil, status.Error(codes.NotFound, "sandbox not found")
+	}
+	return &runtimeapi.PodSandboxStatusResponse{Status: r.sandbox}, nil
+}
+
+type fakeSigstoreVerifier struct {
+	expectedImageID string
+	selectors       []string
+	err             error
+}
+
+func (f *fakeSigstoreVerifier) Verify(_ context.Context, imageID string) ([]string, error) {
+	if imageID != f.expectedImageID {
+		return nil, fmt.Errorf("unexpected image ID: %s", imageID)
+	}
+	return f.selectors, f.err
+}
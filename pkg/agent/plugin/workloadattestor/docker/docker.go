@@ -119,6 +119,9 @@ func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, stat
 	}
 
 	if newConfig.Sigstore != nil {
+		if err := newConfig.Sigstore.Validate(); err != nil {
+			status.ReportErrorf("invalid sigstore configuration: %v", err)
+		}
 		newConfig.sigstoreConfig = sigstore.NewConfigFromHCL(newConfig.Sigstore, p.log)
 	}
 
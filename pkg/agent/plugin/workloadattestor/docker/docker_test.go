@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -211,6 +213,17 @@ invalid2 = "/no/"`,
 			expectCode: codes.InvalidArgument,
 			expectMsg:  "unknown configurations detected: invalid1,invalid2",
 		},
+		{
+			name:        "invalid sigstore configuration",
+			trustDomain: "example.org",
+			config: `
+					sigstore {
+						ignore_attestations = true
+						required_predicate_types = ["https://slsa.dev/provenance/v1"]
+					}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid sigstore configuration: required_predicate_types cannot be used when ignoring attestations",
+		},
 		{
 			name:        "stale experimental block is rejected",
 			trustDomain: "example.org",
@@ -246,6 +259,27 @@ invalid2 = "/no/"`,
 	}
 }
 
+func TestDockerConfigOfflineSigstore(t *testing.T) {
+	trustedRootPath := filepath.Join(t.TempDir(), "trusted_root.json")
+	require.NoError(t, os.WriteFile(trustedRootPath, []byte(`{"mediaType": "application/vnd.dev.sigstore.trustedroot+json;version=0.1"}`), 0o600))
+
+	p := New()
+	var err error
+	plugintest.Load(t, builtin(p), new(workloadattestor.V1),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(fmt.Sprintf(`
+			sigstore {
+				trusted_root_path = %q
+				offline = true
+				required_predicate_types = ["https://slsa.dev/provenance/v1"]
+			}`, trustedRootPath)),
+		plugintest.CaptureConfigureError(&err))
+	require.NoError(t, err)
+	require.NotNil(t, p.sigstoreVerifier)
+}
+
 func TestDockerConfigDefault(t *testing.T) {
 	p := newTestPlugin(t)
 
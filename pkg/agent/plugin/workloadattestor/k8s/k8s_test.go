@@ -680,6 +680,17 @@ func (s *Suite) TestConfigureWithSigstore() {
 			`,
 			expectedError: "unable to decode configuration",
 		},
+		{
+			name:        "offline without trusted root",
+			trustDomain: "example.org",
+			hcl: `
+				    skip_kubelet_verification = true
+					sigstore {
+						offline = true
+					}
+			`,
+			expectedError: "invalid sigstore configuration: trusted_root_path is required for offline verification",
+		},
 		{
 			name:        "stale experimental block is rejected",
 			trustDomain: "example.org",
@@ -0,0 +1,11 @@
+package nomad
+
+import "github.com/spiffe/spire/pkg/common/catalog"
+
+const (
+	pluginName = "nomad"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
@@ -0,0 +1,222 @@
+//go:build !windows
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/token"
+	workloadattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/workloadattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/common/cgroups"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/nomad"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultNomadAddress = "http://127.0.0.1:4646"
+
+	selectorAllocID   = "alloc_id"
+	selectorAllocName = "alloc_name"
+	selectorNamespace = "namespace"
+	selectorJobID     = "job_id"
+	selectorTaskGroup = "task_group"
+	selectorTask      = "task"
+	selectorNodeID    = "node_id"
+)
+
+// reTaskCgroup matches the cgroups that Nomad creates for the tasks under the
+// "nomad" (cgroup v1) or "nomad.slice" (cgroup v2) parent, e.g.
+// "/nomad.slice/share.slice/<alloc ID>.<task>.scope", and captures the
+// allocation ID and the task name.
+var reTaskCgroup = regexp.MustCompile(`(?:^|/)nomad(?:\.slice)?/(?:[^/]+/)*([[:xdigit:]]{8}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{12})\.([^/]+?)(?:\.scope)?$`)
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		workloadattestorv1.WorkloadAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Configuration struct {
+	// NomadAddress is the address of the HTTP API of the local Nomad agent
+	// (default: "http://127.0.0.1:4646").
+	NomadAddress string `hcl:"nomad_address" json:"nomad_address"`
+
+	// Token is the ACL token used to query the Nomad HTTP API.
+	Token string `hcl:"token" json:"token"`
+
+	// CACertPath is the path to the CA certificates used to verify the
+	// Nomad HTTP API certificate. If empty, the system roots are used.
+	CACertPath string `hcl:"ca_cert_path" json:"ca_cert_path"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type pluginConfig struct {
+	client nomad.Client
+}
+
+func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *pluginConfig {
+	newConfig := new(Configuration)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	pluginconf.ReportUnusedKeys(status, newConfig.UnusedKeyPositions)
+
+	if newConfig.NomadAddress == "" {
+		newConfig.NomadAddress = defaultNomadAddress
+	}
+
+	client, err := p.newClient(nomad.ClientConfig{
+		Address:    newConfig.NomadAddress,
+		Token:      newConfig.Token,
+		CACertPath: newConfig.CACertPath,
+	})
+	if err != nil {
+		status.ReportErrorf("unable to create Nomad client: %v", err)
+	}
+
+	return &pluginConfig{
+		client: client,
+	}
+}
+
+type Plugin struct {
+	workloadattestorv1.UnsafeWorkloadAttestorServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+
+	// Used by tests to use a fake /proc directory instead of the real one
+	rootDir string
+
+	newClient func(nomad.ClientConfig) (nomad.Client, error)
+
+	mtx    sync.RWMutex
+	config *pluginConfig
+}
+
+func New() *Plugin {
+	return &Plugin{
+		rootDir:   "/",
+		newClient: nomad.NewClient,
+	}
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestRequest) (*workloadattestorv1.AttestResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cgroupList, err := cgroups.GetCgroups(req.Pid, dirFS(p.rootDir))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to read cgroups: %v", err)
+	}
+
+	allocID, task, ok := findTask(cgroupList)
+	if !ok {
+		// Not a Nomad task. Nothing more to do.
+		return &workloadattestorv1.AttestResponse{}, nil
+	}
+	log := p.log.With("alloc_id", allocID, "task", task)
+
+	alloc, err := config.client.GetAllocation(ctx, allocID)
+	switch {
+	case errors.Is(err, nomad.ErrNotFound):
+		// The allocation is gone, e.g. garbage collected after the task
+		// stopped. Nothing more to do.
+		log.Debug("Allocation not found")
+		return &workloadattestorv1.AttestResponse{}, nil
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "unable to get allocation %q: %v", allocID, err)
+	}
+
+	return &workloadattestorv1.AttestResponse{
+		SelectorValues: getSelectorValues(alloc, task),
+	}, nil
+}
+
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, p.buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, p.buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *Plugin) getConfig() (*pluginConfig, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+// findTask inspects the cgroups of a process to determine whether it runs in
+// a Nomad task and, if so, the allocation ID and the name of the task.
+func findTask(cgroupList []cgroups.Cgroup) (string, string, bool) {
+	for _, cg := range cgroupList {
+		if m := reTaskCgroup.FindStringSubmatch(cg.GroupPath); m != nil {
+			return m[1], m[2], true
+		}
+	}
+	return "", "", false
+}
+
+func getSelectorValues(alloc *nomad.Allocation, task string) []string {
+	selectorValues := []string{
+		fmt.Sprintf("%s:%s", selectorAllocID, alloc.ID),
+		fmt.Sprintf("%s:%s", selectorNamespace, alloc.Namespace),
+		fmt.Sprintf("%s:%s", selectorJobID, alloc.JobID),
+		fmt.Sprintf("%s:%s", selectorTaskGroup, alloc.TaskGroup),
+		fmt.Sprintf("%s:%s", selectorTask, task),
+	}
+	if alloc.Name != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorAllocName, alloc.Name))
+	}
+	if alloc.NodeID != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorNodeID, alloc.NodeID))
+	}
+	return selectorValues
+}
+
+type dirFS string
+
+func (d dirFS) Open(p string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), p))
+}
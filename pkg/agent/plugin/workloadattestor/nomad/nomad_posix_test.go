@@ -0,0 +1,209 @@
+//go:build !windows
+
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/nomad"
+	"github.com/spiffe/spire/test/fakes/fakenomad"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testAllocID  = "5b2d7ac1-4f6a-9c3e-63b9-02ba2e46b3e2"
+	testNodeID   = "f7a13b36-6f71-4c2a-93b0-6d2b1c9e2a41"
+	testACLToken = "ACLTOKEN"
+)
+
+var (
+	ctx = context.Background()
+
+	testAlloc = &nomad.Allocation{
+		ID:           testAllocID,
+		Name:         "billing.reports[0]",
+		Namespace:    "finance",
+		NodeID:       testNodeID,
+		JobID:        "billing",
+		TaskGroup:    "reports",
+		ClientStatus: "running",
+	}
+
+	testSelectors = []string{
+		"alloc_id:" + testAllocID,
+		"alloc_name:billing.reports[0]",
+		"job_id:billing",
+		"namespace:finance",
+		"node_id:" + testNodeID,
+		"task:generate.pdf",
+		"task_group:reports",
+	}
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		cgroups         string
+		token           string
+		expectSelectors []string
+		expectCode      codes.Code
+		expectMsg       string
+	}{
+		{
+			name:            "cgroup v2 task",
+			cgroups:         "0::/nomad.slice/share.slice/" + testAllocID + ".generate.pdf.scope",
+			token:           testACLToken,
+			expectSelectors: testSelectors,
+		},
+		{
+			name:            "cgroup v2 task with reserved cores",
+			cgroups:         "0::/nomad.slice/reserve.slice/" + testAllocID + ".generate.pdf.scope",
+			token:           testACLToken,
+			expectSelectors: testSelectors,
+		},
+		{
+			name: "cgroup v1 task",
+			cgroups: "12:pids:/nomad/shared/" + testAllocID + ".generate.pdf\n" +
+				"11:cpu,cpuacct:/nomad/shared/" + testAllocID + ".generate.pdf\n",
+			token:           testACLToken,
+			expectSelectors: testSelectors,
+		},
+		{
+			name:    "not a Nomad task",
+			cgroups: "0::/user.slice/user-1000.slice/session-1.scope",
+			token:   testACLToken,
+		},
+		{
+			name:    "allocation not found",
+			cgroups: "0::/nomad.slice/share.slice/00000000-0000-0000-0000-000000000000.web.scope",
+			token:   testACLToken,
+		},
+		{
+			name:       "unauthorized",
+			cgroups:    "0::/nomad.slice/share.slice/" + testAllocID + ".generate.pdf.scope",
+			token:      "WRONG",
+			expectCode: codes.Internal,
+			expectMsg:  fmt.Sprintf("nomad): unable to get allocation %q: unexpected status 403: Permission denied", testAllocID),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakenomad.New(t, testACLToken)
+			server.AddAllocation(testAlloc)
+
+			p := newTestPlugin(t, tt.cgroups, fmt.Sprintf(`
+				nomad_address = %q
+				token = %q
+			`, server.URL, tt.token))
+
+			selectors, err := doAttest(t, p)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			require.Equal(t, tt.expectSelectors, selectors)
+		})
+	}
+}
+
+func TestAttestNotConfigured(t *testing.T) {
+	wp := new(workloadattestor.V1)
+	plugintest.Load(t, BuiltIn(), wp)
+	_, err := wp.Attest(ctx, 123)
+	spiretest.RequireGRPCStatusContains(t, err, codes.FailedPrecondition, "not configured")
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		config        string
+		expectAddress string
+		expectCode    codes.Code
+		expectMsg     string
+	}{
+		{
+			name:          "defaults",
+			expectAddress: defaultNomadAddress,
+		},
+		{
+			name:          "custom address",
+			config:        `nomad_address = "https://nomad.example.org:4646"`,
+			expectAddress: "https://nomad.example.org:4646",
+		},
+		{
+			name:       "invalid address",
+			config:     `nomad_address = "unix:///run/nomad.sock"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `unable to create Nomad client: invalid address "unix:///run/nomad.sock": must be an http or https URL`,
+		},
+		{
+			name:       "malformed configuration",
+			config:     "{ not a config }",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var address string
+			p := New()
+			p.newClient = func(config nomad.ClientConfig) (nomad.Client, error) {
+				address = config.Address
+				return nomad.NewClient(config)
+			}
+			err := doConfigure(t, p, tt.config)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, p.config)
+				return
+			}
+			require.Equal(t, tt.expectAddress, address)
+		})
+	}
+}
+
+func doAttest(t *testing.T, p *Plugin) ([]string, error) {
+	wp := new(workloadattestor.V1)
+	plugintest.Load(t, builtin(p), wp)
+	selectors, err := wp.Attest(ctx, 123)
+	if err != nil {
+		return nil, err
+	}
+	var selectorValues []string
+	for _, selector := range selectors {
+		require.Equal(t, pluginName, selector.Type)
+		selectorValues = append(selectorValues, selector.Value)
+	}
+	sort.Strings(selectorValues)
+	return selectorValues, nil
+}
+
+func doConfigure(t *testing.T, p *Plugin, cfg string) error {
+	var err error
+	plugintest.Load(t, builtin(p), new(workloadattestor.V1),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(cfg),
+		plugintest.CaptureConfigureError(&err))
+	return err
+}
+
+// newTestPlugin returns a configured plugin with a fake /proc holding the
+// cgroups of the workload.
+func newTestPlugin(t *testing.T, cgroups string, cfg string) *Plugin {
+	p := New()
+	require.NoError(t, doConfigure(t, p, cfg))
+
+	p.rootDir = spiretest.TempDir(t)
+	procPidPath := filepath.Join(p.rootDir, "proc", "123")
+	require.NoError(t, os.MkdirAll(procPidPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procPidPath, "cgroup"), []byte(cgroups), 0600))
+	return p
+}
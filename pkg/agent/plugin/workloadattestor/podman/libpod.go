@@ -0,0 +1,127 @@
+//go:build !windows
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// libpodAPIPrefix is the prefix of the libpod REST API endpoints. Podman
+	// 4.0.0 and later serve this version of the API.
+	libpodAPIPrefix = "http://d/v4.0.0/libpod"
+)
+
+// errNotFound is returned when the libpod API does not know the container or
+// pod.
+var errNotFound = errors.New("not found")
+
+// Container holds the fields of the libpod container inspect response used by
+// the plugin.
+type Container struct {
+	ID          string `json:"Id"`
+	Name        string `json:"Name"`
+	Image       string `json:"Image"`
+	ImageName   string `json:"ImageName"`
+	ImageDigest string `json:"ImageDigest"`
+	Pod         string `json:"Pod"`
+	Config      struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		UsernsMode string `json:"UsernsMode"`
+	} `json:"HostConfig"`
+}
+
+// Pod holds the fields of the libpod pod inspect response used by the plugin.
+type Pod struct {
+	ID     string            `json:"Id"`
+	Name   string            `json:"Name"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// Client is a subset of the libpod REST API, useful for mocking.
+type Client interface {
+	InspectContainer(ctx context.Context, containerID string) (*Container, error)
+	InspectPod(ctx context.Context, podID string) (*Pod, error)
+}
+
+type libpodClient struct {
+	client *http.Client
+}
+
+// newLibpodClient returns a client for the libpod REST API served on the given
+// socket (e.g. "unix:///run/podman/podman.sock").
+func newLibpodClient(socketPath string) (Client, error) {
+	path, ok := strings.CutPrefix(socketPath, "unix://")
+	if !ok {
+		return nil, fmt.Errorf("unsupported Podman socket %q: only unix sockets are supported", socketPath)
+	}
+	return &libpodClient{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}, nil
+}
+
+func (c *libpodClient) InspectContainer(ctx context.Context, containerID string) (*Container, error) {
+	container := new(Container)
+	if err := c.get(ctx, "/containers/"+url.PathEscape(containerID)+"/json", container); err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+func (c *libpodClient) InspectPod(ctx context.Context, podID string) (*Pod, error) {
+	pod := new(Pod)
+	if err := c.get(ctx, "/pods/"+url.PathEscape(podID)+"/json", pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (c *libpodClient) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, libpodAPIPrefix+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errNotFound
+	default:
+		// libpod returns the reason of the failure in the "message" field
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+	return nil
+}
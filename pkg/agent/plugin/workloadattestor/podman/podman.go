@@ -0,0 +1,11 @@
+package podman
+
+import "github.com/spiffe/spire/pkg/common/catalog"
+
+const (
+	pluginName = "podman"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
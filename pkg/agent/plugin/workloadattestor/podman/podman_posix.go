@@ -0,0 +1,353 @@
+//go:build !windows
+
+package podman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/token"
+	workloadattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/workloadattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/common/cgroups"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/containerinfo"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPodmanSocketPath         = "unix:///run/podman/podman.sock"
+	defaultPodmanSocketPathTemplate = "unix:///run/user/%d/podman/podman.sock"
+
+	selectorLabel         = "label"
+	selectorImageName     = "image_name"
+	selectorImageID       = "image_id"
+	selectorImageDigest   = "image_digest"
+	selectorPodID         = "pod_id"
+	selectorPodName       = "pod_name"
+	selectorPodLabel      = "pod_label"
+	selectorRootless      = "rootless"
+	selectorRootlessUID   = "rootless_uid"
+	selectorUsernsMode    = "userns_mode"
+	selectorContainerName = "container_name"
+)
+
+var (
+	// rePodmanCgroup matches the cgroups of the containers created by
+	// Podman, with both the systemd ("libpod-<id>.scope") and cgroupfs
+	// ("/libpod/<id>") cgroup managers.
+	rePodmanCgroup = regexp.MustCompile(`(?:libpod-|/libpod/)`)
+
+	// reUserSliceUID matches the user slice that rootless containers are
+	// created under and captures the UID of the user owning them.
+	reUserSliceUID = regexp.MustCompile(`/user-(\d+)\.slice/`)
+)
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		workloadattestorv1.WorkloadAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Configuration struct {
+	// PodmanSocketPath is the socket path for rootful Podman (default:
+	// "unix:///run/podman/podman.sock").
+	PodmanSocketPath string `hcl:"podman_socket_path" json:"podman_socket_path"`
+
+	// PodmanSocketPathTemplate is the socket path template for rootless
+	// Podman. The %d placeholder is replaced with the UID of the user owning
+	// the container (default: "unix:///run/user/%d/podman/podman.sock").
+	PodmanSocketPathTemplate string `hcl:"podman_socket_path_template" json:"podman_socket_path_template"`
+
+	// VerboseContainerLocatorLogs, if true, dumps extra information to the log
+	// about mountinfo and cgroup information used to locate the container.
+	VerboseContainerLocatorLogs bool `hcl:"verbose_container_locator_logs" json:"verbose_container_locator_logs"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Configuration {
+	newConfig := new(Configuration)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	pluginconf.ReportUnusedKeys(status, newConfig.UnusedKeyPositions)
+
+	if newConfig.PodmanSocketPath == "" {
+		newConfig.PodmanSocketPath = defaultPodmanSocketPath
+	}
+	if newConfig.PodmanSocketPathTemplate == "" {
+		newConfig.PodmanSocketPathTemplate = defaultPodmanSocketPathTemplate
+	}
+	if err := validatePodmanSocketPathTemplate(newConfig.PodmanSocketPathTemplate); err != nil {
+		status.ReportErrorf("invalid podman_socket_path_template: %v", err)
+	}
+
+	return newConfig
+}
+
+type Plugin struct {
+	workloadattestorv1.UnsafeWorkloadAttestorServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+
+	// Used by tests to use a fake /proc directory instead of the real one
+	rootDir string
+
+	// Used by tests to fake the libpod API
+	newClient func(socketPath string) (Client, error)
+
+	mtx     sync.RWMutex
+	config  *Configuration
+	clients map[string]Client
+}
+
+func New() *Plugin {
+	return &Plugin{
+		rootDir:   "/",
+		newClient: newLibpodClient,
+	}
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestRequest) (*workloadattestorv1.AttestResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	extractor := containerinfo.Extractor{RootDir: p.rootDir, VerboseLogging: config.VerboseContainerLocatorLogs}
+	containerID, err := extractor.GetContainerID(req.Pid, p.log)
+	switch {
+	case err != nil:
+		return nil, err
+	case containerID == "":
+		// Not a containerized workload. Nothing more to do.
+		return &workloadattestorv1.AttestResponse{}, nil
+	}
+	log := p.log.With(telemetry.ContainerID, containerID)
+
+	cgroupList, err := cgroups.GetCgroups(req.Pid, dirFS(p.rootDir))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to read cgroups: %v", err)
+	}
+	podman, ok := findPodmanInfo(cgroupList, log)
+	if !ok {
+		// Not a Podman container. Nothing more to do.
+		return &workloadattestorv1.AttestResponse{}, nil
+	}
+
+	socketPath := config.PodmanSocketPath
+	if podman.rootless {
+		socketPath = fmt.Sprintf(config.PodmanSocketPathTemplate, podman.uid)
+	}
+	client, err := p.getClient(socketPath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to create Podman client for socket %q: %v", socketPath, err)
+	}
+
+	container, err := client.InspectContainer(ctx, containerID)
+	switch {
+	case errors.Is(err, errNotFound):
+		// The container is unknown to the Podman service owning the cgroup,
+		// e.g. because it was removed in the meantime.
+		log.Debug("Container not found by the Podman service", "socket_path", socketPath)
+		return &workloadattestorv1.AttestResponse{}, nil
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "unable to inspect container on socket %q: %v", socketPath, err)
+	}
+
+	var pod *Pod
+	if container.Pod != "" {
+		pod, err = client.InspectPod(ctx, container.Pod)
+		switch {
+		case errors.Is(err, errNotFound):
+			log.Debug("Pod not found by the Podman service", "pod_id", container.Pod, "socket_path", socketPath)
+			return &workloadattestorv1.AttestResponse{}, nil
+		case err != nil:
+			return nil, status.Errorf(codes.Internal, "unable to inspect pod %q on socket %q: %v", container.Pod, socketPath, err)
+		}
+	}
+
+	return &workloadattestorv1.AttestResponse{
+		SelectorValues: getSelectorValues(container, pod, podman),
+	}, nil
+}
+
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.config = newConfig
+	p.clients = make(map[string]Client)
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *Plugin) getConfig() (*Configuration, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+// getClient returns the client for the libpod API served on the given socket.
+// Clients are reused across attestations.
+func (p *Plugin) getClient(socketPath string) (Client, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if client, ok := p.clients[socketPath]; ok {
+		return client, nil
+	}
+	client, err := p.newClient(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[socketPath] = client
+	return client, nil
+}
+
+// podmanInfo describes how a container is run by Podman.
+type podmanInfo struct {
+	rootless bool
+	uid      uint64
+}
+
+// findPodmanInfo inspects the cgroups of a process to determine whether it runs
+// in a Podman container and, if so, whether the container is rootless and
+// which user owns it.
+func findPodmanInfo(cgroupList []cgroups.Cgroup, log hclog.Logger) (podmanInfo, bool) {
+	for _, cg := range cgroupList {
+		if !rePodmanCgroup.MatchString(cg.GroupPath) {
+			continue
+		}
+		if m := reUserSliceUID.FindStringSubmatch(cg.GroupPath); m != nil {
+			if uid, err := strconv.ParseUint(m[1], 10, 32); err == nil {
+				return podmanInfo{rootless: true, uid: uid}, true
+			}
+			log.Warn("Failed to parse rootless Podman UID from cgroup path, falling back to rootful Podman socket", "uid", m[1], "cgroup_path", cg.GroupPath)
+		}
+		return podmanInfo{}, true
+	}
+	return podmanInfo{}, false
+}
+
+func getSelectorValues(container *Container, pod *Pod, podman podmanInfo) []string {
+	selectorValues := getLibpodSelectorValues(container, pod)
+	if podman.rootless {
+		// The libpod API of a rootless Podman service is served by the user
+		// running it, who can report any container information. Those
+		// selectors are namespaced under the UID of the user, which is taken
+		// from the cgroup of the workload, so that a user cannot produce the
+		// selectors of the containers of another user or of rootful Podman.
+		for i, selectorValue := range selectorValues {
+			selectorValues[i] = fmt.Sprintf("%s:%d:%s", selectorRootlessUID, podman.uid, selectorValue)
+		}
+	}
+
+	selectorValues = append(selectorValues, fmt.Sprintf("%s:%t", selectorRootless, podman.rootless))
+	if podman.rootless {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%d", selectorRootlessUID, podman.uid))
+	}
+	return selectorValues
+}
+
+// getLibpodSelectorValues returns the selectors built from the information
+// reported by the libpod API.
+func getLibpodSelectorValues(container *Container, pod *Pod) []string {
+	var selectorValues []string
+	if container.Name != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorContainerName, container.Name))
+	}
+	for label, value := range container.Config.Labels {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s:%s", selectorLabel, label, value))
+	}
+	if container.ImageName != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorImageName, container.ImageName))
+	}
+	if container.Image != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorImageID, container.Image))
+	}
+	if container.ImageDigest != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorImageDigest, container.ImageDigest))
+	}
+	if pod != nil {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorPodID, pod.ID))
+		if pod.Name != "" {
+			selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorPodName, pod.Name))
+		}
+		for label, value := range pod.Labels {
+			selectorValues = append(selectorValues, fmt.Sprintf("%s:%s:%s", selectorPodLabel, label, value))
+		}
+	}
+	if container.HostConfig.UsernsMode != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", selectorUsernsMode, container.HostConfig.UsernsMode))
+	}
+	return selectorValues
+}
+
+func validatePodmanSocketPathTemplate(template string) error {
+	var placeholders int
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		if i+1 >= len(template) {
+			return errors.New("trailing % at end of template")
+		}
+		switch template[i+1] {
+		case '%':
+			i++
+		case 'd':
+			placeholders++
+			i++
+		default:
+			return errors.New("template only supports escaped %% or the %d UID placeholder")
+		}
+	}
+
+	if placeholders != 1 {
+		return errors.New("template must contain exactly one %d UID placeholder")
+	}
+	return nil
+}
+
+type dirFS string
+
+func (d dirFS) Open(p string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), p))
+}
@@ -0,0 +1,360 @@
+//go:build !windows
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testContainerID = "6469646e742065787065637420616e796f6e6520746f20726561642074686973"
+	testPodID       = "706f642d69642d6f662d7468652d746573742d636f6e7461696e65722d2d2d2d"
+
+	// brokenContainerName is the name of a container that the fake libpod
+	// API fails to inspect.
+	brokenContainerName = "broken"
+
+	testRootfulCgroupEntries  = "0::/machine.slice/libpod-6469646e742065787065637420616e796f6e6520746f20726561642074686973.scope"
+	testRootlessCgroupEntries = "0::/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-6469646e742065787065637420616e796f6e6520746f20726561642074686973.scope"
+	testCgroupfsCgroupEntries = "0::/user.slice/user-2000.slice/user@2000.service/user.slice/libpod/6469646e742065787065637420616e796f6e6520746f20726561642074686973"
+	testDockerCgroupEntries   = "0::/system.slice/docker-6469646e742065787065637420616e796f6e6520746f20726561642074686973.scope"
+	testHostCgroupEntries     = "0::/user.slice/user-1000.slice/session-1.scope"
+)
+
+var (
+	ctx = context.Background()
+
+	testContainer = &Container{
+		ID:          testContainerID,
+		Name:        "web",
+		Image:       "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		ImageName:   "docker.io/library/nginx:latest",
+		ImageDigest: "sha256:2a1d6b0ab1d6e0e46d0b3f7b6e7f1d9c42b1c1e3a04f0f6fc58a7bd0e1f6f7a5",
+	}
+
+	testContainerSelectors = []string{
+		"container_name:web",
+		"image_digest:sha256:2a1d6b0ab1d6e0e46d0b3f7b6e7f1d9c42b1c1e3a04f0f6fc58a7bd0e1f6f7a5",
+		"image_id:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"image_name:docker.io/library/nginx:latest",
+		"label:app:web",
+	}
+)
+
+// rootlessSelectors namespaces the selectors under the UID of the user
+// running rootless Podman.
+func rootlessSelectors(uid string, selectorValues ...string) []string {
+	var out []string
+	for _, selectorValue := range selectorValues {
+		out = append(out, "rootless_uid:"+uid+":"+selectorValue)
+	}
+	return out
+}
+
+func init() {
+	testContainer.Config.Labels = map[string]string{"app": "web"}
+}
+
+func TestAttest(t *testing.T) {
+	podContainer := *testContainer
+	podContainer.Pod = testPodID
+	podContainer.HostConfig.UsernsMode = "keep-id"
+	brokenContainer := *testContainer
+	brokenContainer.Name = brokenContainerName
+
+	for _, tt := range []struct {
+		name            string
+		cgroups         string
+		socketUID       string
+		container       *Container
+		pod             *Pod
+		expectSelectors []string
+		expectCode      codes.Code
+		expectMsg       string
+	}{
+		{
+			name:            "rootful container",
+			cgroups:         testRootfulCgroupEntries,
+			socketUID:       "rootful",
+			container:       testContainer,
+			expectSelectors: append(append([]string{}, testContainerSelectors...), "rootless:false"),
+		},
+		{
+			name:            "rootless container",
+			cgroups:         testRootlessCgroupEntries,
+			socketUID:       "1000",
+			container:       testContainer,
+			expectSelectors: append(rootlessSelectors("1000", testContainerSelectors...), "rootless:true", "rootless_uid:1000"),
+		},
+		{
+			name:            "rootless container with the cgroupfs manager",
+			cgroups:         testCgroupfsCgroupEntries,
+			socketUID:       "2000",
+			container:       testContainer,
+			expectSelectors: append(rootlessSelectors("2000", testContainerSelectors...), "rootless:true", "rootless_uid:2000"),
+		},
+		{
+			name:      "container in a pod with a user namespace",
+			cgroups:   testRootlessCgroupEntries,
+			socketUID: "1000",
+			container: &podContainer,
+			pod: &Pod{
+				ID:     testPodID,
+				Name:   "frontend",
+				Labels: map[string]string{"tier": "frontend"},
+			},
+			expectSelectors: append(rootlessSelectors("1000", append(append([]string{}, testContainerSelectors...),
+				"pod_id:"+testPodID,
+				"pod_label:tier:frontend",
+				"pod_name:frontend",
+				"userns_mode:keep-id",
+			)...),
+				"rootless:true",
+				"rootless_uid:1000",
+			),
+		},
+		{
+			name:      "rootful container in a pod",
+			cgroups:   testRootfulCgroupEntries,
+			socketUID: "rootful",
+			container: &podContainer,
+			pod: &Pod{
+				ID:   testPodID,
+				Name: "frontend",
+			},
+			expectSelectors: append(append([]string{}, testContainerSelectors...),
+				"pod_id:"+testPodID,
+				"pod_name:frontend",
+				"rootless:false",
+				"userns_mode:keep-id",
+			),
+		},
+		{
+			name:    "docker container",
+			cgroups: testDockerCgroupEntries,
+		},
+		{
+			name:    "not a container",
+			cgroups: testHostCgroupEntries,
+		},
+		{
+			name:      "container not found",
+			cgroups:   testRootlessCgroupEntries,
+			socketUID: "1000",
+		},
+		{
+			name:      "pod not found",
+			cgroups:   testRootlessCgroupEntries,
+			socketUID: "1000",
+			container: &podContainer,
+		},
+		{
+			name:       "libpod API failure",
+			cgroups:    testRootlessCgroupEntries,
+			socketUID:  "1000",
+			container:  &brokenContainer,
+			expectCode: codes.Internal,
+			expectMsg:  "unable to inspect container on socket",
+		},
+		{
+			name:       "socket not available",
+			cgroups:    testRootlessCgroupEntries,
+			socketUID:  "3000",
+			expectCode: codes.Internal,
+			expectMsg:  "unable to inspect container on socket",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			socketDir := spiretest.TempDir(t)
+			if tt.socketUID != "" {
+				startFakeLibpod(t, filepath.Join(socketDir, tt.socketUID+".sock"), tt.container, tt.pod)
+			}
+			p := newTestPlugin(t, tt.cgroups, fmt.Sprintf(`
+				podman_socket_path = "unix://%s/rootful.sock"
+				podman_socket_path_template = "unix://%s/%%d.sock"
+			`, socketDir, socketDir))
+
+			selectors, err := doAttest(t, p)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			sort.Strings(tt.expectSelectors)
+			require.Equal(t, tt.expectSelectors, selectors)
+		})
+	}
+}
+
+func TestAttestReusesClients(t *testing.T) {
+	socketDir := spiretest.TempDir(t)
+	startFakeLibpod(t, filepath.Join(socketDir, "1000.sock"), testContainer, nil)
+	p := newTestPlugin(t, testRootlessCgroupEntries, fmt.Sprintf(`podman_socket_path_template = "unix://%s/%%d.sock"`, socketDir))
+
+	var created []string
+	p.newClient = func(socketPath string) (Client, error) {
+		created = append(created, socketPath)
+		return newLibpodClient(socketPath)
+	}
+
+	for range 2 {
+		_, err := doAttest(t, p)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"unix://" + socketDir + "/1000.sock"}, created)
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		config       string
+		expectConfig *Configuration
+		expectCode   codes.Code
+		expectMsg    string
+	}{
+		{
+			name: "defaults",
+			expectConfig: &Configuration{
+				PodmanSocketPath:         defaultPodmanSocketPath,
+				PodmanSocketPathTemplate: defaultPodmanSocketPathTemplate,
+			},
+		},
+		{
+			name: "custom sockets",
+			config: `
+				podman_socket_path = "unix:///custom/podman.sock"
+				podman_socket_path_template = "unix:///custom/user/%d/podman.sock"
+				verbose_container_locator_logs = true
+			`,
+			expectConfig: &Configuration{
+				PodmanSocketPath:            "unix:///custom/podman.sock",
+				PodmanSocketPathTemplate:    "unix:///custom/user/%d/podman.sock",
+				VerboseContainerLocatorLogs: true,
+			},
+		},
+		{
+			name:       "template without placeholder",
+			config:     `podman_socket_path_template = "unix:///custom/podman.sock"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid podman_socket_path_template: template must contain exactly one %d UID placeholder",
+		},
+		{
+			name:       "template with unsupported verb",
+			config:     `podman_socket_path_template = "unix:///run/user/%s/podman.sock"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid podman_socket_path_template: template only supports escaped %% or the %d UID placeholder",
+		},
+		{
+			name:       "malformed configuration",
+			config:     "{ not a config }",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			err := doConfigure(t, p, tt.config)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, p.config)
+				return
+			}
+			p.config.UnusedKeyPositions = nil
+			require.Equal(t, tt.expectConfig, p.config)
+		})
+	}
+}
+
+func TestLibpodClientRejectsNonUnixSockets(t *testing.T) {
+	_, err := newLibpodClient("tcp://127.0.0.1:8080")
+	require.EqualError(t, err, `unsupported Podman socket "tcp://127.0.0.1:8080": only unix sockets are supported`)
+}
+
+func doAttest(t *testing.T, p *Plugin) ([]string, error) {
+	wp := new(workloadattestor.V1)
+	plugintest.Load(t, builtin(p), wp)
+	selectors, err := wp.Attest(ctx, 123)
+	if err != nil {
+		return nil, err
+	}
+	var selectorValues []string
+	for _, selector := range selectors {
+		require.Equal(t, pluginName, selector.Type)
+		selectorValues = append(selectorValues, selector.Value)
+	}
+	sort.Strings(selectorValues)
+	return selectorValues, nil
+}
+
+func doConfigure(t *testing.T, p *Plugin, cfg string) error {
+	var err error
+	plugintest.Load(t, builtin(p), new(workloadattestor.V1),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(cfg),
+		plugintest.CaptureConfigureError(&err))
+	return err
+}
+
+// newTestPlugin returns a configured plugin with a fake /proc holding the
+// cgroups of the workload.
+func newTestPlugin(t *testing.T, cgroups string, cfg string) *Plugin {
+	p := New()
+	require.NoError(t, doConfigure(t, p, cfg))
+
+	p.rootDir = spiretest.TempDir(t)
+	procPidPath := filepath.Join(p.rootDir, "proc", "123")
+	require.NoError(t, os.MkdirAll(procPidPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procPidPath, "cgroup"), []byte(cgroups), 0600))
+	return p
+}
+
+// startFakeLibpod serves the libpod container and pod inspect endpoints on the
+// given unix socket.
+func startFakeLibpod(t *testing.T, socketPath string, container *Container, pod *Pod) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v4.0.0/libpod/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case container == nil || r.PathValue("id") != container.ID:
+			writeLibpodError(w, http.StatusNotFound, "no such container")
+			return
+		case container.Name == brokenContainerName:
+			writeLibpodError(w, http.StatusInternalServerError, "ohno")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(container)
+	})
+	mux.HandleFunc("GET /v4.0.0/libpod/pods/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		if pod == nil || r.PathValue("id") != pod.ID {
+			writeLibpodError(w, http.StatusNotFound, "no such pod")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(pod)
+	})
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(mux)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+}
+
+func writeLibpodError(w http.ResponseWriter, code int, message string) {
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"cause": message, "message": message, "response": code})
+}
@@ -0,0 +1,222 @@
+//go:build !windows
+
+package unix
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// interpreter describes how the script run by an interpreter is found in its
+// command line arguments.
+type interpreter struct {
+	// inlineFlags are the options that make the interpreter run something
+	// other than a script file (e.g. an inline command or a module).
+	inlineFlags []string
+	// valueFlags are the options that take a value as the next argument.
+	valueFlags []string
+}
+
+var (
+	pythonInterpreter = interpreter{
+		inlineFlags: []string{"-c", "-m"},
+		valueFlags:  []string{"-W", "-X"},
+	}
+	nodeInterpreter = interpreter{
+		inlineFlags: []string{"-e", "-p", "--eval", "--print"},
+		valueFlags:  []string{"-r", "--require", "--import", "--loader"},
+	}
+	shellInterpreter = interpreter{
+		inlineFlags: []string{"-c"},
+		valueFlags:  []string{"-o", "-O", "+O"},
+	}
+	rubyInterpreter = interpreter{
+		inlineFlags: []string{"-e"},
+		valueFlags:  []string{"-r", "-I"},
+	}
+	perlInterpreter = interpreter{
+		inlineFlags: []string{"-e", "-E"},
+		valueFlags:  []string{"-I", "-M", "-m"},
+	}
+
+	pythonRE = regexp.MustCompile(`^python[0-9.]*$`)
+
+	interpreters = map[string]interpreter{
+		"node":   nodeInterpreter,
+		"nodejs": nodeInterpreter,
+		"bash":   shellInterpreter,
+		"sh":     shellInterpreter,
+		"dash":   shellInterpreter,
+		"zsh":    shellInterpreter,
+		"ksh":    shellInterpreter,
+		"ruby":   rubyInterpreter,
+		"perl":   perlInterpreter,
+	}
+)
+
+// lookupInterpreter returns the interpreter for the given executable path,
+// if it is a known one.
+func lookupInterpreter(path string) (interpreter, bool) {
+	name := filepath.Base(path)
+	if pythonRE.MatchString(name) {
+		return pythonInterpreter, true
+	}
+	interp, ok := interpreters[name]
+	return interp, ok
+}
+
+// scriptArg returns the script argument from the command line arguments of
+// the interpreter, i.e. the first argument that is not an option.
+func (i interpreter) scriptArg(args []string) (string, bool) {
+	if len(args) < 2 {
+		return "", false
+	}
+
+	args = args[1:]
+	for n := 0; n < len(args); n++ {
+		arg := args[n]
+		switch {
+		case arg == "--":
+			if n+1 < len(args) {
+				return args[n+1], true
+			}
+			return "", false
+		case arg == "-" || arg == "":
+			// Script read from stdin
+			return "", false
+		case slices.Contains(i.inlineFlags, arg):
+			return "", false
+		case slices.Contains(i.valueFlags, arg):
+			// Skip the value of the option
+			n++
+		case strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+"):
+			continue
+		default:
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// capabilityNames are the names of the Linux capabilities, indexed by their
+// bit number (see capabilities(7)).
+var capabilityNames = []string{
+	"cap_chown",
+	"cap_dac_override",
+	"cap_dac_read_search",
+	"cap_fowner",
+	"cap_fsetid",
+	"cap_kill",
+	"cap_setgid",
+	"cap_setuid",
+	"cap_setpcap",
+	"cap_linux_immutable",
+	"cap_net_bind_service",
+	"cap_net_broadcast",
+	"cap_net_admin",
+	"cap_net_raw",
+	"cap_ipc_lock",
+	"cap_ipc_owner",
+	"cap_sys_module",
+	"cap_sys_rawio",
+	"cap_sys_chroot",
+	"cap_sys_ptrace",
+	"cap_sys_pacct",
+	"cap_sys_admin",
+	"cap_sys_boot",
+	"cap_sys_nice",
+	"cap_sys_resource",
+	"cap_sys_time",
+	"cap_sys_tty_config",
+	"cap_mknod",
+	"cap_lease",
+	"cap_audit_write",
+	"cap_audit_control",
+	"cap_setfcap",
+	"cap_mac_override",
+	"cap_mac_admin",
+	"cap_syslog",
+	"cap_wake_alarm",
+	"cap_block_suspend",
+	"cap_audit_read",
+	"cap_perfmon",
+	"cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+// parseCapabilities parses a capability set as found in /proc/<pid>/status
+// (i.e. a hexadecimal bitmask) into the names of the capabilities.
+func parseCapabilities(value string) ([]string, error) {
+	mask, err := strconv.ParseUint(value, 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed capability set %q: %w", value, err)
+	}
+
+	capabilities := []string{}
+	for bit := range 64 {
+		if mask&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(capabilityNames) {
+			capabilities = append(capabilities, capabilityNames[bit])
+		} else {
+			capabilities = append(capabilities, fmt.Sprintf("cap_%d", bit))
+		}
+	}
+	return capabilities, nil
+}
+
+// parseCgroups parses the content of /proc/<pid>/cgroup. Each line has the
+// format "<hierarchy-id>:<controllers>:<path>". The unified hierarchy (v2)
+// has no controllers, so only its path is returned.
+func parseCgroups(content string) ([]string, error) {
+	cgroups := []string{}
+	for line := range strings.Lines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed cgroup entry %q", line)
+		}
+
+		controllers, path := parts[1], parts[2]
+		if controllers == "" {
+			cgroups = append(cgroups, path)
+		} else {
+			cgroups = append(cgroups, controllers+":"+path)
+		}
+	}
+	return cgroups, nil
+}
+
+// namespaceTypes are the namespaces found in /proc/<pid>/ns.
+var namespaceTypes = []string{
+	"cgroup",
+	"ipc",
+	"mnt",
+	"net",
+	"pid",
+	"time",
+	"user",
+	"uts",
+}
+
+// parseNamespaceLink parses the target of a /proc/<pid>/ns/<type> link,
+// which has the format "<type>:[<inode>]", and returns the inode.
+func parseNamespaceLink(nsType, link string) (string, error) {
+	inode, ok := strings.CutPrefix(link, nsType+":[")
+	if ok {
+		inode, ok = strings.CutSuffix(inode, "]")
+	}
+	if !ok || inode == "" {
+		return "", fmt.Errorf("malformed %s namespace link %q", nsType, link)
+	}
+	return inode, nil
+}
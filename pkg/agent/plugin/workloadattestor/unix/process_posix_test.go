@@ -0,0 +1,147 @@
+//go:build !windows
+
+package unix
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptArg(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		path         string
+		args         []string
+		expectScript string
+	}{
+		{
+			name:         "python script",
+			path:         "/usr/bin/python3",
+			args:         []string{"python3", "app.py", "--port", "8080"},
+			expectScript: "app.py",
+		},
+		{
+			name:         "versioned python with options",
+			path:         "/usr/local/bin/python3.12",
+			args:         []string{"python3.12", "-u", "-X", "dev", "/srv/app.py"},
+			expectScript: "/srv/app.py",
+		},
+		{
+			name: "python module",
+			path: "/usr/bin/python3",
+			args: []string{"python3", "-m", "http.server"},
+		},
+		{
+			name:         "node script with preloaded module",
+			path:         "/usr/bin/node",
+			args:         []string{"node", "--require", "dotenv/config", "server.js"},
+			expectScript: "server.js",
+		},
+		{
+			name: "node eval",
+			path: "/usr/bin/node",
+			args: []string{"node", "-e", "console.log(1)"},
+		},
+		{
+			name:         "bash script after end of options",
+			path:         "/bin/bash",
+			args:         []string{"bash", "-o", "errexit", "--", "run.sh"},
+			expectScript: "run.sh",
+		},
+		{
+			name: "bash inline command",
+			path: "/bin/bash",
+			args: []string{"bash", "-c", "run.sh"},
+		},
+		{
+			name: "script from stdin",
+			path: "/bin/sh",
+			args: []string{"sh", "-"},
+		},
+		{
+			name: "interactive interpreter",
+			path: "/bin/sh",
+			args: []string{"sh"},
+		},
+		{
+			name: "not an interpreter",
+			path: "/usr/bin/pythonista",
+			args: []string{"pythonista", "app.py"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var script string
+			if interp, ok := lookupInterpreter(tt.path); ok {
+				script, _ = interp.scriptArg(tt.args)
+			}
+			require.Equal(t, tt.expectScript, script)
+		})
+	}
+}
+
+func TestParseCapabilities(t *testing.T) {
+	capabilities, err := parseCapabilities("0000000000000000")
+	require.NoError(t, err)
+	require.Empty(t, capabilities)
+
+	capabilities, err = parseCapabilities("0000c00000003001")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cap_chown", "cap_net_admin", "cap_net_raw", "cap_46", "cap_47"}, capabilities)
+
+	_, err = parseCapabilities("not-hex")
+	require.EqualError(t, err, `malformed capability set "not-hex": strconv.ParseUint: parsing "not-hex": invalid syntax`)
+}
+
+func TestParseCgroups(t *testing.T) {
+	cgroups, err := parseCgroups("12:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n0::/system.slice/app.service\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"cpu,cpuacct:/docker/abc",
+		"name=systemd:/docker/abc",
+		"/system.slice/app.service",
+	}, cgroups)
+
+	_, err = parseCgroups("malformed\n")
+	require.EqualError(t, err, `malformed cgroup entry "malformed"`)
+}
+
+func TestPSProcessInfo(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("capabilities, cgroups and namespaces are only supported on Linux")
+	}
+
+	procDir := t.TempDir()
+	t.Setenv("HOST_PROC", procDir)
+
+	pidDir := filepath.Join(procDir, "42")
+	require.NoError(t, os.MkdirAll(filepath.Join(pidDir, "ns"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "status"), []byte("Name:\tapp\nCapInh:\t0000000000000000\nCapEff:\t0000000000000400\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "cgroup"), []byte("0::/kubepods/pod1234\n"), 0o600))
+	require.NoError(t, os.Symlink("mnt:[4026531841]", filepath.Join(pidDir, "ns", "mnt")))
+	require.NoError(t, os.Symlink("net:[4026531840]", filepath.Join(pidDir, "ns", "net")))
+
+	ps := PSProcessInfo{Process: &process.Process{Pid: 42}}
+
+	capabilities, err := ps.Capabilities()
+	require.NoError(t, err)
+	require.Equal(t, []string{"cap_net_bind_service"}, capabilities)
+
+	cgroups, err := ps.Cgroups()
+	require.NoError(t, err)
+	require.Equal(t, []string{"/kubepods/pod1234"}, cgroups)
+
+	namespaces, err := ps.Namespaces()
+	require.NoError(t, err)
+	require.Equal(t, []string{"mnt:4026531841", "net:4026531840"}, namespaces)
+
+	require.Equal(t, filepath.Join(pidDir, "root", "srv", "app.py"), ps.NamespacedPath("/srv/app.py"))
+
+	require.NoError(t, os.Symlink("garbage", filepath.Join(pidDir, "ns", "pid")))
+	_, err = ps.Namespaces()
+	require.EqualError(t, err, `malformed pid namespace link "garbage"`)
+}
@@ -5,6 +5,7 @@ package unix
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/user"
@@ -21,6 +22,7 @@ import (
 	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -39,6 +41,14 @@ type processInfo interface {
 	Groups() ([]string, error)
 	Exe() (string, error)
 	NamespacedExe() string
+	Ppid() (int32, error)
+	CreateTime() (int64, error)
+	CmdlineSlice() ([]string, error)
+	Cwd() (string, error)
+	NamespacedPath(path string) string
+	Capabilities() ([]string, error)
+	Cgroups() ([]string, error)
+	Namespaces() ([]string, error)
 }
 
 type PSProcessInfo struct {
@@ -49,6 +59,83 @@ func (ps PSProcessInfo) NamespacedExe() string {
 	return getProcPath(ps.Pid, "exe")
 }
 
+// NamespacedPath returns the given path as seen from the root directory of
+// the process on Linux, so that it is resolved in its mount namespace.
+func (ps PSProcessInfo) NamespacedPath(path string) string {
+	if runtime.GOOS != "linux" {
+		return path
+	}
+	return filepath.Join(getProcPath(ps.Pid, "root"), path)
+}
+
+// Capabilities returns the names of the effective capabilities of the process.
+func (ps PSProcessInfo) Capabilities() ([]string, error) {
+	if runtime.GOOS != "linux" {
+		return []string{}, nil
+	}
+
+	f, err := os.Open(getProcPath(ps.Pid, "status"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scnr := bufio.NewScanner(f)
+	for scnr.Scan() {
+		key, value, ok := strings.Cut(scnr.Text(), ":")
+		if ok && key == "CapEff" {
+			return parseCapabilities(strings.TrimSpace(value))
+		}
+	}
+
+	if err := scnr.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, errors.New("no effective capabilities found in process status")
+}
+
+// Cgroups returns the cgroups of the process. Cgroups of the unified (v2)
+// hierarchy are returned as their path, while cgroups of v1 hierarchies are
+// prefixed by the controllers of the hierarchy.
+func (ps PSProcessInfo) Cgroups() ([]string, error) {
+	if runtime.GOOS != "linux" {
+		return []string{}, nil
+	}
+
+	data, err := os.ReadFile(getProcPath(ps.Pid, "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	return parseCgroups(string(data))
+}
+
+// Namespaces returns the namespaces of the process, as "<type>:<inode>".
+func (ps PSProcessInfo) Namespaces() ([]string, error) {
+	if runtime.GOOS != "linux" {
+		return []string{}, nil
+	}
+
+	var namespaces []string
+	for _, nsType := range namespaceTypes {
+		link, err := os.Readlink(getProcPath(ps.Pid, filepath.Join("ns", nsType)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// Not supported by the kernel
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		inode, err := parseNamespaceLink(nsType, link)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, fmt.Sprintf("%s:%s", nsType, inode))
+	}
+	return namespaces, nil
+}
+
 // Groups returns the supplementary group IDs
 // This is a custom implementation that only works for linux until the next issue is fixed
 // https://github.com/shirou/gopsutil/issues/913
@@ -90,6 +177,23 @@ func (ps PSProcessInfo) Groups() ([]string, error) {
 type Configuration struct {
 	DiscoverWorkloadPath bool  `hcl:"discover_workload_path"`
 	WorkloadSizeLimit    int64 `hcl:"workload_size_limit"`
+
+	// ParentProcessDepth is the number of ancestors of the workload process
+	// for which path and digest selectors are provided.
+	ParentProcessDepth int `hcl:"parent_process_depth"`
+
+	// DiscoverScriptPath enables the selectors for the script run by
+	// workloads that are known interpreters (e.g. python, node or bash).
+	DiscoverScriptPath bool `hcl:"discover_script_path"`
+
+	// DiscoverCapabilities enables the effective Linux capabilities selectors.
+	DiscoverCapabilities bool `hcl:"discover_capabilities"`
+
+	// DiscoverCgroups enables the cgroup path selectors.
+	DiscoverCgroups bool `hcl:"discover_cgroups"`
+
+	// DiscoverNamespaces enables the Linux namespace inode selectors.
+	DiscoverNamespaces bool `hcl:"discover_namespaces"`
 }
 
 func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Configuration {
@@ -99,6 +203,14 @@ func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginco
 		return nil
 	}
 
+	if newConfig.ParentProcessDepth < 0 {
+		status.ReportError("parent_process_depth cannot be negative")
+	}
+
+	if runtime.GOOS != "linux" && (newConfig.DiscoverCapabilities || newConfig.DiscoverCgroups || newConfig.DiscoverNamespaces) {
+		status.ReportInfo("Capabilities, cgroups and namespaces selectors are only supported on Linux")
+	}
+
 	return newConfig
 }
 
@@ -200,6 +312,52 @@ func (p *Plugin) Attest(_ context.Context, req *workloadattestorv1.AttestRequest
 		}
 	}
 
+	if config.ParentProcessDepth > 0 {
+		parentSelectorValues, err := p.getParentSelectorValues(proc, config)
+		if err != nil {
+			return nil, err
+		}
+		selectorValues = append(selectorValues, parentSelectorValues...)
+	}
+
+	if config.DiscoverScriptPath {
+		scriptSelectorValues, err := p.getScriptSelectorValues(proc, config)
+		if err != nil {
+			return nil, err
+		}
+		selectorValues = append(selectorValues, scriptSelectorValues...)
+	}
+
+	if config.DiscoverCapabilities {
+		capabilities, err := proc.Capabilities()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "capabilities lookup: %v", err)
+		}
+		for _, capability := range capabilities {
+			selectorValues = append(selectorValues, makeSelectorValue("capability", capability))
+		}
+	}
+
+	if config.DiscoverCgroups {
+		cgroups, err := proc.Cgroups()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cgroups lookup: %v", err)
+		}
+		for _, cgroup := range cgroups {
+			selectorValues = append(selectorValues, makeSelectorValue("cgroup", cgroup))
+		}
+	}
+
+	if config.DiscoverNamespaces {
+		namespaces, err := proc.Namespaces()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "namespaces lookup: %v", err)
+		}
+		for _, namespace := range namespaces {
+			selectorValues = append(selectorValues, makeSelectorValue("namespace", namespace))
+		}
+	}
+
 	return &workloadattestorv1.AttestResponse{
 		SelectorValues: selectorValues,
 	}, nil
@@ -301,6 +459,138 @@ func (p *Plugin) getNamespacedPath(proc processInfo) (string, error) {
 	return proc.Exe()
 }
 
+// getParentSelectorValues returns the path and digest selectors of the
+// ancestors of the process, up to the configured depth. The level of the
+// ancestor is part of the selector value, starting at 1 for the parent.
+//
+// The PID of a parent that exits can be reused by an unrelated process. The
+// walk stops at a parent that was started after its child, or that exited
+// while its selectors were read, since the selectors could describe the
+// unrelated process.
+func (p *Plugin) getParentSelectorValues(proc processInfo, config *Configuration) ([]string, error) {
+	var selectorValues []string
+	for level := 1; level <= config.ParentProcessDepth; level++ {
+		ppid, err := proc.Ppid()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "parent process lookup: %v", err)
+		}
+		if ppid <= 0 {
+			// The process has no parent (e.g. it is the init process)
+			break
+		}
+		createTime, err := proc.CreateTime()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "process start time lookup: %v", err)
+		}
+
+		parent, err := p.hooks.newProcess(ppid)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get parent process: %v", err)
+		}
+		parentCreateTime, err := parent.CreateTime()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "parent process start time lookup: %v", err)
+		}
+		if parentCreateTime > createTime {
+			p.log.Debug("Parent process started after its child, its PID was reused", telemetry.PID, ppid, "level", level)
+			break
+		}
+
+		levelSelectorValues, err := p.getAncestorSelectorValues(parent, level, config)
+		if err != nil {
+			return nil, err
+		}
+
+		// If the parent exited while its selectors were read, the process
+		// has been reparented.
+		if currentPpid, err := proc.Ppid(); err != nil || currentPpid != ppid {
+			p.log.Debug("Parent process exited during attestation", telemetry.PID, ppid, "level", level)
+			break
+		}
+
+		selectorValues = append(selectorValues, levelSelectorValues...)
+		proc = parent
+	}
+	return selectorValues, nil
+}
+
+// getAncestorSelectorValues returns the path and digest selectors of the
+// ancestor of the workload process at the given level.
+func (p *Plugin) getAncestorSelectorValues(proc processInfo, level int, config *Configuration) ([]string, error) {
+	parentPath, err := p.getPath(proc)
+	if err != nil {
+		return nil, err
+	}
+	selectorValues := []string{makeSelectorValue("parent_path", fmt.Sprintf("%d:%s", level, parentPath))}
+
+	if config.WorkloadSizeLimit >= 0 {
+		exePath, err := p.getNamespacedPath(proc)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		sha256Digest, err := util.GetSHA256Digest(exePath, config.WorkloadSizeLimit)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		selectorValues = append(selectorValues, makeSelectorValue("parent_sha256", fmt.Sprintf("%d:%s", level, sha256Digest)))
+	}
+	return selectorValues, nil
+}
+
+// getScriptSelectorValues returns the path and digest selectors of the script
+// run by the process, when the process is a known interpreter. No selectors
+// are returned if the interpreter is not running a script file (e.g. it runs
+// an inline command or a module).
+func (p *Plugin) getScriptSelectorValues(proc processInfo, config *Configuration) ([]string, error) {
+	processPath, err := p.getPath(proc)
+	if err != nil {
+		return nil, err
+	}
+
+	interp, ok := lookupInterpreter(processPath)
+	if !ok {
+		return nil, nil
+	}
+
+	args, err := proc.CmdlineSlice()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "command line lookup: %v", err)
+	}
+
+	scriptPath, ok := interp.scriptArg(args)
+	if !ok {
+		return nil, nil
+	}
+	if !filepath.IsAbs(scriptPath) {
+		cwd, err := proc.Cwd()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "working directory lookup: %v", err)
+		}
+		scriptPath = filepath.Join(cwd, scriptPath)
+	}
+	scriptPath = filepath.Clean(scriptPath)
+
+	// The script is read through the root directory of the process so
+	// that it is resolved in the mount namespace of the workload.
+	namespacedScriptPath := proc.NamespacedPath(scriptPath)
+	info, err := os.Stat(namespacedScriptPath)
+	if err != nil || !info.Mode().IsRegular() {
+		// The argument is not a script file
+		return nil, nil
+	}
+
+	selectorValues := []string{makeSelectorValue("script_path", scriptPath)}
+	if config.WorkloadSizeLimit >= 0 {
+		sha256Digest, err := util.GetSHA256Digest(namespacedScriptPath, config.WorkloadSizeLimit)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		selectorValues = append(selectorValues, makeSelectorValue("script_sha256", sha256Digest))
+	}
+	return selectorValues, nil
+}
+
 func makeSelectorValue(kind, value string) string {
 	return fmt.Sprintf("%s:%s", kind, value)
 }
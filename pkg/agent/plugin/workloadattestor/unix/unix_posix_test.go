@@ -227,10 +227,110 @@ func (s *Suite) TestAttest() {
 			expectCode:  codes.Internal,
 			expectMsg:   "workloadattestor(unix): supplementary GIDs lookup: some error for PID 14",
 		},
+		{
+			name:        "parent process selectors",
+			trustDomain: "example.org",
+			pid:         15,
+			config:      "parent_process_depth = 1",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				fmt.Sprintf("parent_path:1:%s", filepath.Join(s.dir, "exe")),
+				"parent_sha256:1:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7",
+			},
+		},
+		{
+			name:        "parent process selectors stop at the root of the process tree",
+			trustDomain: "example.org",
+			pid:         15,
+			config:      "parent_process_depth = 5\nworkload_size_limit = -1",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				fmt.Sprintf("parent_path:1:%s", filepath.Join(s.dir, "exe")),
+				fmt.Sprintf("parent_path:2:%s", filepath.Join(s.dir, "parent-exe")),
+			},
+		},
+		{
+			name:        "parent process selectors stop at a reused parent PID",
+			trustDomain: "example.org",
+			pid:         23,
+			config:      "parent_process_depth = 1",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+			},
+		},
+		{
+			name:        "fail to get parent process",
+			trustDomain: "example.org",
+			pid:         18,
+			config:      "parent_process_depth = 1",
+			expectCode:  codes.Internal,
+			expectMsg:   "workloadattestor(unix): parent process lookup: unable to get PPID for PID 18",
+		},
+		{
+			name:        "script selectors",
+			trustDomain: "example.org",
+			pid:         19,
+			config:      "discover_script_path = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				fmt.Sprintf("script_path:%s", filepath.Join(s.dir, "script.py")),
+				"script_sha256:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7",
+			},
+		},
+		{
+			name:        "no script selectors for inline commands",
+			trustDomain: "example.org",
+			pid:         20,
+			config:      "discover_script_path = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+			},
+		},
+		{
+			name:        "capabilities, cgroups and namespaces selectors",
+			trustDomain: "example.org",
+			pid:         21,
+			config:      "discover_capabilities = true\ndiscover_cgroups = true\ndiscover_namespaces = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				"capability:cap_net_bind_service",
+				"cgroup:/system.slice/workload.service",
+				"namespace:mnt:4026531841",
+				"namespace:pid:4026531836",
+			},
+		},
+		{
+			name:        "fail to get capabilities",
+			trustDomain: "example.org",
+			pid:         22,
+			config:      "discover_capabilities = true",
+			expectCode:  codes.Internal,
+			expectMsg:   "workloadattestor(unix): capabilities lookup: unable to get capabilities for PID 22",
+		},
 	}
 
 	// prepare the "exe" for hashing
 	s.writeFile("exe", []byte("data"))
+	s.writeFile("parent-exe", []byte("parent"))
+	s.writeFile("script.py", []byte("data"))
 
 	for _, testCase := range testCases {
 		s.T().Run(testCase.name, func(t *testing.T) {
@@ -257,6 +357,17 @@ func (s *Suite) TestAttest() {
 	}
 }
 
+func (s *Suite) TestConfigureNegativeParentProcessDepth() {
+	var err error
+	plugintest.Load(s.T(), builtin(s.newPlugin()), new(workloadattestor.V1),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.CaptureConfigureError(&err),
+		plugintest.Configure("parent_process_depth = -1"))
+	spiretest.RequireGRPCStatusContains(s.T(), err, codes.InvalidArgument, "parent_process_depth cannot be negative")
+}
+
 func (s *Suite) writeFile(path string, data []byte) {
 	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, path), data, 0o600))
 }
@@ -297,7 +408,7 @@ func (p fakeProcess) Uids() ([]uint32, error) {
 		return nil, fmt.Errorf("unable to get UIDs for PID %d", p.pid)
 	case 3:
 		return []uint32{1999}, nil
-	case 4, 5, 6, 7, 9, 10, 11, 12, 13, 14:
+	case 4, 5, 6, 7, 9, 10, 11, 12, 13, 14, 15, 18, 19, 20, 21, 22, 23:
 		return []uint32{1000}, nil
 	case 8:
 		return []uint32{1000, 1100}, nil
@@ -314,7 +425,7 @@ func (p fakeProcess) Gids() ([]uint32, error) {
 		return nil, fmt.Errorf("unable to get GIDs for PID %d", p.pid)
 	case 6:
 		return []uint32{2999}, nil
-	case 3, 7, 9, 10, 11, 12, 13, 14:
+	case 3, 7, 9, 10, 11, 12, 13, 14, 15, 18, 19, 20, 21, 22, 23:
 		return []uint32{2000}, nil
 	case 8:
 		return []uint32{2000, 2100}, nil
@@ -340,8 +451,14 @@ func (p fakeProcess) Exe() (string, error) {
 		return "", fmt.Errorf("unable to get EXE for PID %d", p.pid)
 	case 10:
 		return filepath.Join(p.dir, "unreadable-exe"), nil
-	case 11, 12:
+	case 11, 12, 16:
 		return filepath.Join(p.dir, "exe"), nil
+	case 17:
+		return filepath.Join(p.dir, "parent-exe"), nil
+	case 19:
+		return "/usr/bin/python3.11", nil
+	case 20:
+		return "/bin/bash", nil
 	default:
 		return "", fmt.Errorf("unhandled exe test case %d", p.pid)
 	}
@@ -349,13 +466,91 @@ func (p fakeProcess) Exe() (string, error) {
 
 func (p fakeProcess) NamespacedExe() string {
 	switch p.pid {
-	case 11, 12:
+	case 11, 12, 16:
 		return filepath.Join(p.dir, "exe")
+	case 17:
+		return filepath.Join(p.dir, "parent-exe")
 	default:
 		return filepath.Join("/proc", strconv.Itoa(int(p.pid)), "unreadable-exe")
 	}
 }
 
+func (p fakeProcess) Ppid() (int32, error) {
+	switch p.pid {
+	case 15:
+		return 16, nil
+	case 16:
+		return 17, nil
+	case 17:
+		return 0, nil
+	case 23:
+		return 24, nil
+	default:
+		return 0, fmt.Errorf("unable to get PPID for PID %d", p.pid)
+	}
+}
+
+func (p fakeProcess) CreateTime() (int64, error) {
+	switch p.pid {
+	case 24:
+		// Started after its child (PID 23), i.e. a reused PID
+		return 2000, nil
+	default:
+		return 1000, nil
+	}
+}
+
+func (p fakeProcess) CmdlineSlice() ([]string, error) {
+	switch p.pid {
+	case 19:
+		return []string{"python3.11", "-W", "ignore", "script.py", "--verbose"}, nil
+	case 20:
+		return []string{"bash", "-c", "script.py"}, nil
+	default:
+		return nil, fmt.Errorf("unhandled cmdline test case %d", p.pid)
+	}
+}
+
+func (p fakeProcess) Cwd() (string, error) {
+	switch p.pid {
+	case 19:
+		return p.dir, nil
+	default:
+		return "", fmt.Errorf("unhandled cwd test case %d", p.pid)
+	}
+}
+
+func (p fakeProcess) NamespacedPath(path string) string {
+	return path
+}
+
+func (p fakeProcess) Capabilities() ([]string, error) {
+	switch p.pid {
+	case 21:
+		return []string{"cap_net_bind_service"}, nil
+	default:
+		return nil, fmt.Errorf("unable to get capabilities for PID %d", p.pid)
+	}
+}
+
+func (p fakeProcess) Cgroups() ([]string, error) {
+	switch p.pid {
+	case 21:
+		return []string{"/system.slice/workload.service"}, nil
+	default:
+		return nil, fmt.Errorf("unable to get cgroups for PID %d", p.pid)
+	}
+}
+
+func (p fakeProcess) Namespaces() ([]string, error) {
+	switch p.pid {
+	case 21:
+		return []string{"mnt:4026531841", "pid:4026531836"}, nil
+	default:
+		return nil, fmt.Errorf("unable to get namespaces for PID %d", p.pid)
+	}
+}
+
 func newFakeProcess(pid int32, dir string) processInfo {
 	return fakeProcess{pid: pid, dir: dir}
 }
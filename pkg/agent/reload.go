@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/config"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// ReloadableConfig holds the settings that can be changed while the agent is
+// running, as loaded from the configuration file when the agent is signaled
+// to reload it.
+type ReloadableConfig struct {
+	// Telemetry provides the configuration for metrics exporting
+	Telemetry telemetry.FileConfig
+
+	// PluginConfigs holds the configurations for agent plugins
+	PluginConfigs catalog.PluginConfigs
+
+	// FeatureFlags holds the raw feature flag configuration
+	FeatureFlags fflag.RawConfig
+
+	// Changed holds the settings, named after their configuration keys, that
+	// changed since the configuration was last loaded and can be applied
+	// while the agent is running.
+	Changed []string
+
+	// RequiresRestart holds the settings, named after their configuration
+	// keys, that changed since the agent started but can only be applied by
+	// restarting it.
+	RequiresRestart []string
+}
+
+// ConfigLoader loads the reloadable configuration, e.g. by parsing the
+// configuration file again.
+type ConfigLoader func() (*ReloadableConfig, error)
+
+type pluginReconfigurer interface {
+	ReconfigureWith(ctx context.Context, pluginConfigs catalog.PluginConfigs) catalog.PluginChanges
+}
+
+type metricsReloader interface {
+	Reload(ctx context.Context, fileConfig telemetry.FileConfig) error
+}
+
+// reloadConfig loads the configuration again and applies the settings that
+// can be changed while the agent is running. Settings that require a restart
+// are only reported.
+func (a *Agent) reloadConfig(ctx context.Context, metrics metricsReloader, cat pluginReconfigurer) {
+	log := a.c.Log.WithField(telemetry.SubsystemName, telemetry.ConfigReloader)
+
+	rc, err := a.c.ConfigLoader()
+	if err != nil {
+		log.WithError(err).Error("Failed to reload configuration")
+		return
+	}
+
+	changedFlags, err := fflag.Reload(rc.FeatureFlags)
+	if err != nil {
+		log.WithError(err).Error("Failed to reload feature flags")
+	} else {
+		fflag.LogReloaded(a.c.Log.WithField(telemetry.SubsystemName, telemetry.FeatureFlags), changedFlags)
+	}
+
+	if slices.ContainsFunc(rc.Changed, func(path string) bool {
+		return config.HasPathPrefix(path, "telemetry")
+	}) {
+		if err := metrics.Reload(ctx, rc.Telemetry); err != nil {
+			log.WithError(err).Error("Failed to reload telemetry")
+		}
+	}
+
+	logReloaded(log, rc.Changed, rc.RequiresRestart)
+	cat.ReconfigureWith(ctx, rc.PluginConfigs).Log(log)
+}
+
+func logReloaded(log logrus.FieldLogger, changed, requiresRestart []string) {
+	if len(changed) == 0 {
+		log.Info("Configuration reloaded; no changes")
+	} else {
+		log.WithField(telemetry.Settings, changed).Info("Configuration reloaded")
+	}
+	if len(requiresRestart) > 0 {
+		log.WithField(telemetry.Settings, requiresRestart).Warn("Configuration changes require a restart to take effect")
+	}
+}
@@ -50,3 +50,45 @@ func TestKeyTypeFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestWITSigningAlgorithm(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		keyType   workloadkey.KeyType
+		expectAlg string
+		errMsg    string
+	}{
+		{
+			name:      "RSA 2048",
+			keyType:   workloadkey.RSA2048,
+			expectAlg: "RS256",
+		},
+		{
+			name:      "EC 256",
+			keyType:   workloadkey.ECP256,
+			expectAlg: "ES256",
+		},
+		{
+			name:      "EC 384",
+			keyType:   workloadkey.ECP384,
+			expectAlg: "ES384",
+		},
+		{
+			name:    "unset type",
+			keyType: workloadkey.KeyTypeUnset,
+			errMsg:  "unknown key type \"UNSET\"",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, err := tt.keyType.WITSigningAlgorithm()
+
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAlg, alg)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+	"text/template/parse"
 
 	sprig "github.com/Masterminds/sprig/v3"
 )
@@ -177,3 +178,65 @@ func (t *Template) Execute(args any) (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// Fields returns the chains of fields referenced by the template, in the
+// order they appear. For example, "{{ .k8s.ns }}" references the chain
+// ["k8s", "ns"]. Fields referenced through the root variable (i.e. "$.k8s.ns")
+// are reported the same way.
+func (t *Template) Fields() [][]string {
+	var fields [][]string
+	for _, tmpl := range t.tmpl.Templates() {
+		if tmpl.Tree != nil {
+			fields = appendNodeFields(fields, tmpl.Tree.Root)
+		}
+	}
+	return fields
+}
+
+func appendNodeFields(fields [][]string, node parse.Node) [][]string {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return fields
+		}
+		for _, n := range node.Nodes {
+			fields = appendNodeFields(fields, n)
+		}
+	case *parse.ActionNode:
+		fields = appendNodeFields(fields, node.Pipe)
+	case *parse.PipeNode:
+		if node == nil {
+			return fields
+		}
+		for _, cmd := range node.Cmds {
+			fields = appendNodeFields(fields, cmd)
+		}
+	case *parse.CommandNode:
+		for _, arg := range node.Args {
+			fields = appendNodeFields(fields, arg)
+		}
+	case *parse.ChainNode:
+		fields = appendNodeFields(fields, node.Node)
+	case *parse.FieldNode:
+		fields = append(fields, node.Ident)
+	case *parse.VariableNode:
+		if len(node.Ident) > 1 && node.Ident[0] == "$" {
+			fields = append(fields, node.Ident[1:])
+		}
+	case *parse.IfNode:
+		fields = appendBranchFields(fields, &node.BranchNode)
+	case *parse.RangeNode:
+		fields = appendBranchFields(fields, &node.BranchNode)
+	case *parse.WithNode:
+		fields = appendBranchFields(fields, &node.BranchNode)
+	case *parse.TemplateNode:
+		fields = appendNodeFields(fields, node.Pipe)
+	}
+	return fields
+}
+
+func appendBranchFields(fields [][]string, node *parse.BranchNode) [][]string {
+	fields = appendNodeFields(fields, node.Pipe)
+	fields = appendNodeFields(fields, node.List)
+	return appendNodeFields(fields, node.ElseList)
+}
@@ -38,3 +38,14 @@ func TestMustParse(t *testing.T) {
 		})
 	})
 }
+
+func TestFields(t *testing.T) {
+	tmpl, err := agentpathtemplate.Parse(`/ns/{{ .k8s.ns }}/sa/{{ lower $.k8s.sa }}{{ if .unix.uid }}/{{ .unix.uid | quote }}{{ end }}`)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"k8s", "ns"},
+		{"k8s", "sa"},
+		{"unix", "uid"},
+		{"unix", "uid"},
+	}, tmpl.Fields())
+}
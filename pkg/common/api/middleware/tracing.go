@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WithTracing starts a server span for each RPC call. If the caller
+// propagated a trace context in the request metadata, the span is created as
+// a child of the caller's span. The span is carried in the handler context so
+// that work done on behalf of the call (e.g. datastore or plugin calls) is
+// recorded as part of the same trace.
+func WithTracing() Middleware {
+	return tracingMiddleware{}
+}
+
+type tracingMiddleware struct{}
+
+func (tracingMiddleware) Preprocess(ctx context.Context, fullMethod string, _ any) (context.Context, error) {
+	ctx, names := withNames(ctx, fullMethod)
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+
+	ctx, _ = telemetry.StartSpan(ctx, names.RawService+"/"+names.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", names.RawService),
+			attribute.String("rpc.method", names.Method),
+		),
+	)
+	return ctx, nil
+}
+
+func (tracingMiddleware) Postprocess(ctx context.Context, _ string, _ bool, rpcErr error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(rpcErr).String()))
+	telemetry.EndSpan(span, rpcErr)
+}
+
+// metadataCarrier adapts gRPC metadata for use with OpenTelemetry
+// propagators. Unlike HTTP headers, metadata keys are always lowercase.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := setupTracing(t)
+
+	t.Run("success", func(t *testing.T) {
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(context.Background(), fakeFullMethod, nil)
+		require.NoError(t, err)
+
+		// The span is available to the handler
+		spanContext := trace.SpanContextFromContext(ctx)
+		assert.True(t, spanContext.IsValid())
+
+		m.Postprocess(ctx, fakeFullMethod, true, nil)
+
+		span := lastEndedSpan(t, recorder)
+		assert.Equal(t, "spire.api.server.foo.v1.Foo/SomeMethod", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, spanContext.SpanID(), span.SpanContext().SpanID())
+		assert.False(t, span.Parent().IsValid())
+		assert.Equal(t, otelcodes.Unset, span.Status().Code)
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", "spire.api.server.foo.v1.Foo"),
+			attribute.String("rpc.method", "SomeMethod"),
+			attribute.String("rpc.grpc.status_code", "OK"),
+		}, span.Attributes())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(context.Background(), fakeFullMethod, nil)
+		require.NoError(t, err)
+		m.Postprocess(ctx, fakeFullMethod, true, status.Error(codes.PermissionDenied, "ohno"))
+
+		span := lastEndedSpan(t, recorder)
+		assert.Equal(t, otelcodes.Error, span.Status().Code)
+		assert.Equal(t, "rpc error: code = PermissionDenied desc = ohno", span.Status().Description)
+		assert.Contains(t, span.Attributes(), attribute.String("rpc.grpc.status_code", "PermissionDenied"))
+	})
+
+	t.Run("continues propagated trace", func(t *testing.T) {
+		parentCtx, parent := otel.Tracer("test").Start(context.Background(), "caller")
+		defer parent.End()
+
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(parentCtx, carrier)
+		md := metadata.New(carrier)
+		require.NotEmpty(t, md.Get("traceparent"))
+
+		m := middleware.WithTracing()
+		ctx, err := m.Preprocess(metadata.NewIncomingContext(context.Background(), md), fakeFullMethod, nil)
+		require.NoError(t, err)
+		m.Postprocess(ctx, fakeFullMethod, true, nil)
+
+		span := lastEndedSpan(t, recorder)
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.True(t, span.Parent().IsRemote())
+	})
+}
+
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func lastEndedSpan(t *testing.T, recorder *tracetest.SpanRecorder) sdktrace.ReadOnlySpan {
+	spans := recorder.Ended()
+	require.NotEmpty(t, spans)
+	return spans[len(spans)-1]
+}
@@ -287,6 +287,8 @@ func TestSPIFFEBundleToProto(t *testing.T) {
 	bundle := spiffebundle.FromX509Authorities(td, ca.X509Authorities())
 	err = bundle.AddJWTAuthority("key-id-1", ca.X509Authorities()[0].PublicKey)
 	require.NoError(t, err)
+	err = bundle.AddWITAuthority("key-id-2", ca.X509Authorities()[0].PublicKey)
+	require.NoError(t, err)
 	bundle.SetRefreshHint(time.Second * 10)
 	bundle.SetSequenceNumber(42)
 	bundleNoRefreshHint := spiffebundle.FromX509Authorities(td, ca.X509Authorities())
@@ -299,7 +301,7 @@ func TestSPIFFEBundleToProto(t *testing.T) {
 		expErr   error
 	}{
 		{
-			name:   "success with jwt and x509 authorities",
+			name:   "success with jwt, wit and x509 authorities",
 			bundle: bundle,
 			expProto: &common.Bundle{
 				TrustDomainId:  td.IDString(),
@@ -312,6 +314,12 @@ func TestSPIFFEBundleToProto(t *testing.T) {
 						Kid:       "key-id-1",
 					},
 				},
+				WitSigningKeys: []*common.PublicKey{
+					{
+						PkixBytes: pkixBytes,
+						Kid:       "key-id-2",
+					},
+				},
 			},
 		},
 		{
@@ -353,6 +361,8 @@ func TestSPIFFEBundleFromProto(t *testing.T) {
 	bundle := spiffebundle.FromX509Authorities(td, ca.X509Authorities())
 	err = bundle.AddJWTAuthority("key-id-1", ca.X509Authorities()[0].PublicKey)
 	require.NoError(t, err)
+	err = bundle.AddWITAuthority("key-id-2", ca.X509Authorities()[0].PublicKey)
+	require.NoError(t, err)
 	bundle.SetRefreshHint(time.Second * 10)
 	bundle.SetSequenceNumber(42)
 	bundleZeroedRefreshHint := spiffebundle.FromX509Authorities(td, ca.X509Authorities())
@@ -366,7 +376,7 @@ func TestSPIFFEBundleFromProto(t *testing.T) {
 		expErr    error
 	}{
 		{
-			name: "success with jwt and x509 authorities",
+			name: "success with jwt, wit and x509 authorities",
 			proto: &common.Bundle{
 				TrustDomainId:  td.IDString(),
 				RootCas:        []*common.Certificate{{DerBytes: rootCA.Raw}},
@@ -378,6 +388,12 @@ func TestSPIFFEBundleFromProto(t *testing.T) {
 						Kid:       "key-id-1",
 					},
 				},
+				WitSigningKeys: []*common.PublicKey{
+					{
+						PkixBytes: pkixBytes,
+						Kid:       "key-id-2",
+					},
+				},
 			},
 			expBundle: bundle,
 		},
@@ -429,6 +445,22 @@ func TestSPIFFEBundleFromProto(t *testing.T) {
 				"private:false defaultValue:<nil> tag:<nil> stringType:0 timeType:0 set:false omitEmpty:false} " +
 				"publicKeyInfo @2"),
 		},
+		{
+			name: "fail with error parsing wit authority",
+			proto: &common.Bundle{
+				TrustDomainId: td.IDString(),
+				RootCas:       []*common.Certificate{{DerBytes: rootCA.Raw}},
+				WitSigningKeys: []*common.PublicKey{
+					{
+						PkixBytes: []byte("invalid"),
+					},
+				},
+			},
+			expErr: errors.New("unable to parse WIT signing key 0: asn1: structure error: tags don't match (16 vs" +
+				" {class:1 tag:9 length:110 isCompound:true}) {optional:false explicit:false application:false " +
+				"private:false defaultValue:<nil> tag:<nil> stringType:0 timeType:0 set:false omitEmpty:false} " +
+				"publicKeyInfo @2"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -68,7 +68,7 @@ func loadBuiltIn(ctx context.Context, builtIn BuiltIn, config BuiltInConfig) (_
 
 	private.Register(builtinServer, pluginServers, logger, dialer)
 
-	builtinConn, err := startPipeServer(builtinServer, config.Log)
+	builtinConn, err := startPipeServer(builtinServer, config.Log, pluginTracingDialOption(builtIn.Name, builtIn.Plugin.Type()))
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +85,7 @@ func loadBuiltIn(ctx context.Context, builtIn BuiltIn, config BuiltInConfig) (_
 func newBuiltInServer(log logrus.FieldLogger) (*grpc.Server, io.Closer) {
 	drain := &drainHandlers{}
 	return grpc.NewServer(
+		tracingServerOption(),
 		grpc.ChainStreamInterceptor(drain.StreamServerInterceptor, streamPanicInterceptor(log)),
 		grpc.ChainUnaryInterceptor(drain.UnaryServerInterceptor, unaryPanicInterceptor(log)),
 	), closerFunc(drain.Wait)
@@ -102,7 +103,7 @@ func (d *builtinDialer) DialHost(context.Context) (grpc.ClientConnInterface, err
 		return d.conn, nil
 	}
 	server := newHostServer(d.log, d.pluginName, d.hostServices)
-	conn, err := startPipeServer(server, d.log)
+	conn, err := startPipeServer(server, d.log, hostServiceTracingDialOption())
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +123,7 @@ type pipeConn struct {
 	io.Closer
 }
 
-func startPipeServer(server *grpc.Server, log logrus.FieldLogger) (_ *pipeConn, err error) {
+func startPipeServer(server *grpc.Server, log logrus.FieldLogger, dialOpts ...grpc.DialOption) (_ *pipeConn, err error) {
 	var closers closerGroup
 
 	pipeNet := newPipeNet()
@@ -142,11 +143,11 @@ func startPipeServer(server *grpc.Server, log logrus.FieldLogger) (_ *pipeConn,
 	})
 
 	// Dial the server
-	conn, err := grpc.NewClient(
-		"passthrough:IGNORED",
+	dialOpts = append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(pipeNet.DialContext),
-	)
+	}, dialOpts...)
+	conn, err := grpc.NewClient("passthrough:IGNORED", dialOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
@@ -112,12 +114,121 @@ type Config struct {
 }
 
 type Catalog struct {
+	log           logrus.FieldLogger
 	closers       io.Closer
-	reconfigurers Reconfigurers
+	pluginConfigs PluginConfigs
+
+	mtx     sync.Mutex
+	plugins []loadedPlugin
+}
+
+type loadedPlugin struct {
+	config PluginConfig
+
+	// reconfigurable is nil if the plugin does not support configuration.
+	reconfigurable *Reconfigurable
 }
 
+// PluginChanges describes the outcome of reconfiguring the plugins with a
+// reloaded configuration. Plugins are identified by their type and name
+// (e.g. `KeyManager "disk"`).
+type PluginChanges struct {
+	// Reconfigured holds the plugins that were configured with new data.
+	Reconfigured []string
+
+	// Failed holds the plugins that could not be configured with new data.
+	// They keep running with their previous configuration.
+	Failed []string
+
+	// RequiresRestart holds the plugins that were added, removed, enabled or
+	// disabled, or whose command, arguments or checksum changed.
+	RequiresRestart []string
+}
+
+// Log logs the plugins that were reconfigured, failed to be reconfigured or
+// require a restart.
+func (c PluginChanges) Log(log logrus.FieldLogger) {
+	if len(c.Reconfigured) > 0 {
+		log.WithField(telemetry.Plugins, c.Reconfigured).Info("Plugins reconfigured")
+	}
+	if len(c.Failed) > 0 {
+		log.WithField(telemetry.Plugins, c.Failed).Error("Failed to reconfigure plugins; they keep their previous configuration")
+	}
+	if len(c.RequiresRestart) > 0 {
+		log.WithField(telemetry.Plugins, c.RequiresRestart).Warn("Plugin changes require a restart to take effect")
+	}
+}
+
+// Reconfigure reconfigures the plugins that use a dynamic data source (i.e.
+// plugin_data_file) if their data changed.
 func (c *Catalog) Reconfigure(ctx context.Context) {
-	c.reconfigurers.Reconfigure(ctx)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, plugin := range c.plugins {
+		if plugin.reconfigurable != nil && plugin.reconfigurable.DataSource.IsDynamic() {
+			plugin.reconfigurable.Reconfigure(ctx)
+		}
+	}
+}
+
+// ReconfigureWith reconfigures the loaded plugins with the data sources of
+// the given plugin configurations, e.g. after the configuration file has been
+// reloaded. Plugins whose data changed are configured again through the same
+// Configure RPC used when they were loaded. Changes that cannot be applied
+// to running plugins are only reported.
+func (c *Catalog) ReconfigureWith(ctx context.Context, pluginConfigs PluginConfigs) PluginChanges {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var changes PluginChanges
+	for _, loaded := range c.pluginConfigs {
+		if _, ok := pluginConfigs.Find(loaded.Type, loaded.Name); !ok {
+			changes.RequiresRestart = append(changes.RequiresRestart, pluginID(loaded))
+		}
+	}
+
+	for _, pluginConfig := range pluginConfigs {
+		loaded, ok := c.pluginConfigs.Find(pluginConfig.Type, pluginConfig.Name)
+		switch {
+		case !ok, loaded.Path != pluginConfig.Path, !slices.Equal(loaded.Args, pluginConfig.Args),
+			loaded.Checksum != pluginConfig.Checksum, loaded.Disabled != pluginConfig.Disabled:
+			changes.RequiresRestart = append(changes.RequiresRestart, pluginID(pluginConfig))
+			continue
+		case pluginConfig.Disabled:
+			continue
+		}
+
+		i := slices.IndexFunc(c.plugins, func(plugin loadedPlugin) bool {
+			return plugin.config.Type == pluginConfig.Type && plugin.config.Name == pluginConfig.Name
+		})
+		if i < 0 {
+			continue
+		}
+
+		plugin := c.plugins[i]
+		dataSource := pluginConfig.DataSource
+		if plugin.reconfigurable == nil {
+			if dataSource != nil {
+				makePluginLog(c.log, pluginConfig).Error("Failed to reconfigure plugin: no supported configuration interface found")
+				changes.Failed = append(changes.Failed, pluginID(pluginConfig))
+			}
+			continue
+		}
+		if dataSource == nil {
+			dataSource = FixedData("")
+		}
+
+		reconfigured, err := plugin.reconfigurable.reconfigure(ctx, dataSource)
+		switch {
+		case err != nil:
+			changes.Failed = append(changes.Failed, pluginID(pluginConfig))
+		case reconfigured:
+			changes.Reconfigured = append(changes.Reconfigured, pluginID(pluginConfig))
+		}
+	}
+
+	return changes
 }
 
 func (c *Catalog) Close() error {
@@ -157,7 +268,7 @@ func Load(ctx context.Context, config Config, repo Repository) (_ *Catalog, err
 	}
 
 	pluginCounts := make(map[string]int)
-	var reconfigurers Reconfigurers
+	var plugins []loadedPlugin
 
 	for _, pluginConfig := range config.PluginConfigs {
 		pluginLog := makePluginLog(config.Log, pluginConfig)
@@ -191,14 +302,15 @@ func Load(ctx context.Context, config Config, repo Repository) (_ *Catalog, err
 			return nil, fmt.Errorf("failed to bind plugin %q: %w", pluginConfig.Name, err)
 		}
 
-		reconfigurer, err := configurePlugin(ctx, pluginLog, config.CoreConfig, configurer, pluginConfig.DataSource)
+		reconfigurable, err := configurePlugin(ctx, pluginLog, config.CoreConfig, configurer, pluginConfig.DataSource)
 		if err != nil {
 			pluginLog.WithError(err).Error("Failed to configure plugin")
 			return nil, fmt.Errorf("failed to configure plugin %q: %w", pluginConfig.Name, err)
 		}
-		if reconfigurer != nil {
-			reconfigurers = append(reconfigurers, reconfigurer)
-		}
+		plugins = append(plugins, loadedPlugin{
+			config:         pluginConfig,
+			reconfigurable: reconfigurable,
+		})
 
 		pluginLog.Info("Plugin loaded")
 		pluginCounts[pluginConfig.Type]++
@@ -212,8 +324,10 @@ func Load(ctx context.Context, config Config, repo Repository) (_ *Catalog, err
 	}
 
 	return &Catalog{
+		log:           config.Log,
 		closers:       closers,
-		reconfigurers: reconfigurers,
+		pluginConfigs: config.PluginConfigs,
+		plugins:       plugins,
 	}, nil
 }
 
@@ -308,6 +422,10 @@ func GetPluginConfigString(c PluginConfig) (string, error) {
 	return c.DataSource.Load()
 }
 
+func pluginID(c PluginConfig) string {
+	return fmt.Sprintf("%s %q", c.Type, c.Name)
+}
+
 func makePluginLog(log logrus.FieldLogger, pluginConfig PluginConfig) logrus.FieldLogger {
 	return log.WithFields(logrus.Fields{
 		telemetry.PluginName: pluginConfig.Name,
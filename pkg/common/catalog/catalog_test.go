@@ -108,6 +108,8 @@ type loadTest struct {
 	expectServiceClient   bool
 	expectLogEntries      []spiretest.LogEntry
 	epilogue              func(t *testing.T, cat *catalog.Catalog)
+	reconfigureWith       func(catalog.PluginConfigs) catalog.PluginConfigs
+	expectPluginChanges   catalog.PluginChanges
 }
 
 func testPlugin(t *testing.T, pluginPath string) {
@@ -280,6 +282,108 @@ func testPlugin(t *testing.T, pluginPath string) {
 			},
 		})
 	})
+	t.Run("reconfigure with reloaded plugin configs", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			registerConfigService: true,
+			mutateConfig: func(config *catalog.Config) {
+				config.PluginConfigs[0].DataSource = catalog.FixedData("GOOD1")
+			},
+			expectPluginClient:  true,
+			expectServiceClient: true,
+			expectLogEntries: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "CONFIGURED",
+					Data: logrus.Fields{
+						"config": "GOOD1",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "CONFIGURED",
+					Data: logrus.Fields{
+						"config": "GOOD2",
+					},
+				},
+			},
+			reconfigureWith: func(pluginConfigs catalog.PluginConfigs) catalog.PluginConfigs {
+				pluginConfigs[0].DataSource = catalog.FixedData("GOOD2")
+				return append(pluginConfigs, catalog.PluginConfig{Name: "other", Type: "SomePlugin"})
+			},
+			expectPluginChanges: catalog.PluginChanges{
+				Reconfigured:    []string{`SomePlugin "test"`},
+				RequiresRestart: []string{`SomePlugin "other"`},
+			},
+		})
+	})
+	t.Run("reconfigure with unchanged plugin data", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			registerConfigService: true,
+			mutateConfig: func(config *catalog.Config) {
+				config.PluginConfigs[0].DataSource = catalog.FixedData("GOOD")
+			},
+			expectPluginClient:  true,
+			expectServiceClient: true,
+			reconfigureWith: func(pluginConfigs catalog.PluginConfigs) catalog.PluginConfigs {
+				return pluginConfigs
+			},
+		})
+	})
+	t.Run("reconfigure with bad plugin data", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			registerConfigService: true,
+			mutateConfig: func(config *catalog.Config) {
+				config.PluginConfigs[0].DataSource = catalog.FixedData("GOOD")
+			},
+			expectPluginClient:  true,
+			expectServiceClient: true,
+			reconfigureWith: func(pluginConfigs catalog.PluginConfigs) catalog.PluginConfigs {
+				pluginConfigs[0].DataSource = catalog.FixedData("BAD")
+				return pluginConfigs
+			},
+			expectPluginChanges: catalog.PluginChanges{
+				Failed: []string{`SomePlugin "test"`},
+			},
+		})
+	})
+	t.Run("reconfigure with changed launch configuration", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			expectPluginClient:  true,
+			expectServiceClient: true,
+			reconfigureWith: func(pluginConfigs catalog.PluginConfigs) catalog.PluginConfigs {
+				pluginConfigs[0].Disabled = true
+				return pluginConfigs
+			},
+			expectPluginChanges: catalog.PluginChanges{
+				RequiresRestart: []string{`SomePlugin "test"`},
+			},
+		})
+	})
+	t.Run("reconfigure with removed plugin", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			expectPluginClient:  true,
+			expectServiceClient: true,
+			reconfigureWith: func(catalog.PluginConfigs) catalog.PluginConfigs {
+				return nil
+			},
+			expectPluginChanges: catalog.PluginChanges{
+				RequiresRestart: []string{`SomePlugin "test"`},
+			},
+		})
+	})
+	t.Run("reconfigure unconfigurable plugin with plugin data", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			expectPluginClient:  true,
+			expectServiceClient: true,
+			reconfigureWith: func(pluginConfigs catalog.PluginConfigs) catalog.PluginConfigs {
+				pluginConfigs[0].DataSource = catalog.FixedData("GOOD")
+				return pluginConfigs
+			},
+			expectPluginChanges: catalog.PluginChanges{
+				Failed: []string{`SomePlugin "test"`},
+			},
+		})
+	})
 	t.Run("configure failure", func(t *testing.T) {
 		testLoad(t, pluginPath, loadTest{
 			registerConfigService: true,
@@ -498,6 +602,12 @@ func testLoad(t *testing.T, pluginPath string, tt loadTest) {
 	if tt.epilogue != nil {
 		tt.epilogue(t, cat)
 	}
+
+	if tt.reconfigureWith != nil {
+		pluginConfigs := tt.reconfigureWith(slices.Clone(config.PluginConfigs))
+		changes := cat.ReconfigureWith(context.Background(), pluginConfigs)
+		assert.Equal(t, tt.expectPluginChanges, changes)
+	}
 }
 
 func buildTestPlugin(t *testing.T, srcPath string) string {
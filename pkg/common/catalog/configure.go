@@ -83,17 +83,34 @@ type Reconfigurable struct {
 }
 
 func (r *Reconfigurable) Reconfigure(ctx context.Context) {
-	if dataHash, err := ConfigurePlugin(ctx, r.CoreConfig, r.Configurer, r.DataSource, r.LastHash); err != nil {
+	r.reconfigure(ctx, r.DataSource)
+}
+
+// reconfigure configures the plugin with the data from the given data source
+// if it differs from the data last configured. On success, the data source
+// replaces the current one. It returns true if the plugin was reconfigured.
+func (r *Reconfigurable) reconfigure(ctx context.Context, dataSource DataSource) (bool, error) {
+	dataHash, err := ConfigurePlugin(ctx, r.CoreConfig, r.Configurer, dataSource, r.LastHash)
+	switch {
+	case err != nil:
 		r.Log.WithError(err).Error("Failed to reconfigure plugin")
-	} else if dataHash == r.LastHash {
+		return false, err
+	case dataHash == r.LastHash:
 		r.Log.WithField(telemetry.Hash, r.LastHash).Info("Plugin not reconfigured since the config is unchanged")
-	} else {
+		r.DataSource = dataSource
+		return false, nil
+	default:
 		r.Log.WithField(telemetry.OldHash, r.LastHash).WithField(telemetry.NewHash, dataHash).Info("Plugin reconfigured")
 		r.LastHash = dataHash
+		r.DataSource = dataSource
+		return true, nil
 	}
 }
 
-func configurePlugin(ctx context.Context, pluginLog logrus.FieldLogger, coreConfig CoreConfig, configurer Configurer, dataSource DataSource) (Reconfigurer, error) {
+// configurePlugin configures the plugin with the data from the data source.
+// It returns a Reconfigurable for the plugin, or nil if the plugin does not
+// support configuration.
+func configurePlugin(ctx context.Context, pluginLog logrus.FieldLogger, coreConfig CoreConfig, configurer Configurer, dataSource DataSource) (*Reconfigurable, error) {
 	switch {
 	case configurer == nil && dataSource == nil:
 		// The plugin doesn't support configuration and no data source was configured. Nothing to do.
@@ -113,12 +130,12 @@ func configurePlugin(ctx context.Context, pluginLog logrus.FieldLogger, coreConf
 		return nil, err
 	}
 
-	if !dataSource.IsDynamic() {
+	if dataSource.IsDynamic() {
+		pluginLog.WithField(telemetry.Reconfigurable, true).WithField(telemetry.Hash, dataHash).Info("Configured plugin")
+	} else {
 		pluginLog.WithField(telemetry.Reconfigurable, false).Info("Configured plugin")
-		return nil, nil
 	}
 
-	pluginLog.WithField(telemetry.Reconfigurable, true).WithField(telemetry.Hash, dataHash).Info("Configured plugin")
 	return &Reconfigurable{
 		Log:        pluginLog,
 		CoreConfig: coreConfig,
@@ -85,8 +85,9 @@ func loadExternal(ctx context.Context, config externalConfig) (*pluginImpl, erro
 		Plugins: map[string]goplugin.Plugin{
 			config.Name: &hcClientPlugin{config: config},
 		},
-		Logger:       logger,
-		SecureConfig: secureConfig,
+		Logger:          logger,
+		SecureConfig:    secureConfig,
+		GRPCDialOptions: []grpc.DialOption{pluginTracingDialOption(config.Name, config.Type)},
 	})
 
 	// Ensure the loaded plugin is killed if there is a failure.
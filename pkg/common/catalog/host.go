@@ -14,6 +14,7 @@ import (
 
 func newHostServer(log logrus.FieldLogger, pluginName string, hostServices []pluginsdk.ServiceServer) *grpc.Server {
 	s := grpc.NewServer(
+		tracingServerOption(),
 		grpc.ChainStreamInterceptor(
 			streamPanicInterceptor(log),
 			streamPluginInterceptor(pluginName),
@@ -0,0 +1,34 @@
+package catalog
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+)
+
+// pluginTracingDialOption returns a dial option that records a client span
+// for each call made to a plugin. The trace context is propagated to the
+// plugin so that spans it creates join the trace of the calling RPC.
+func pluginTracingDialOption(name, typ string) grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(
+		otelgrpc.WithTracerProvider(telemetry.TracerProvider()),
+		otelgrpc.WithSpanAttributes(
+			attribute.String("spire.plugin.name", name),
+			attribute.String("spire.plugin.type", typ),
+		),
+	))
+}
+
+// hostServiceTracingDialOption returns a dial option that records a client
+// span for each call a built-in plugin makes to the host services.
+func hostServiceTracingDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(telemetry.TracerProvider())))
+}
+
+// tracingServerOption returns a server option that continues the trace
+// propagated by the caller, for use by servers hosting plugins and host
+// services.
+func tracingServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(telemetry.TracerProvider())))
+}
@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestPluginCallsAreTraced(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	log, _ := test.NewNullLogger()
+	server, serverCloser := newBuiltInServer(log)
+	defer serverCloser.Close()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	conn, err := startPipeServer(server, log, pluginTracingDialOption("test", "SomePlugin"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "caller")
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	parent.End()
+	require.NoError(t, err)
+
+	var clientSpan, serverSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case trace.SpanKindClient:
+			clientSpan = span
+		case trace.SpanKindServer:
+			serverSpan = span
+		}
+	}
+	require.NotNil(t, clientSpan, "plugin client span was not recorded")
+	require.NotNil(t, serverSpan, "plugin server span was not recorded")
+
+	// The call to the plugin is part of the caller's trace
+	assert.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Contains(t, clientSpan.Attributes(), attribute.String("spire.plugin.name", "test"))
+	assert.Contains(t, clientSpan.Attributes(), attribute.String("spire.plugin.type", "SomePlugin"))
+
+	// The trace context is propagated to the plugin
+	assert.Equal(t, parent.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+}
+
+func TestPluginCallsAreTracedAfterReload(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	oldProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tracetest.NewSpanRecorder()))
+	otel.SetTracerProvider(oldProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	log, _ := test.NewNullLogger()
+	server, serverCloser := newBuiltInServer(log)
+	defer serverCloser.Close()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	conn, err := startPipeServer(server, log, pluginTracingDialOption("test", "SomePlugin"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Reloading the telemetry configuration replaces the tracer provider and
+	// shuts down the previous one, while the plugin connections are kept.
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	require.NoError(t, oldProvider.Shutdown(context.Background()))
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	var kinds []trace.SpanKind
+	for _, span := range recorder.Ended() {
+		kinds = append(kinds, span.SpanKind())
+	}
+	assert.ElementsMatch(t, []trace.SpanKind{trace.SpanKindClient, trace.SpanKindServer}, kinds)
+}
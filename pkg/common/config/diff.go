@@ -0,0 +1,160 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/printer"
+)
+
+var astNodeType = reflect.TypeFor[ast.Node]()
+
+// Diff compares two configurations of the same type, as decoded from HCL,
+// and returns the dotted paths of the settings that differ, named after
+// their HCL keys (e.g. "server.ratelimit.signing"). Fields without an HCL
+// key are not compared. Raw HCL nodes are compared by their printed form so
+// that moving them around in the file is not considered a change.
+func Diff(a, b any) []string {
+	paths := make(map[string]struct{})
+	diffValues(paths, "", reflect.ValueOf(a), reflect.ValueOf(b))
+	return slices.Sorted(maps.Keys(paths))
+}
+
+// HasPathPrefix returns true if the given path is the prefix path or one of
+// the settings nested under it.
+func HasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
+
+func diffValues(paths map[string]struct{}, path string, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			paths[path] = struct{}{}
+		}
+		return
+	}
+
+	if a.Type() == astNodeType {
+		if printNode(a) != printNode(b) {
+			paths[path] = struct{}{}
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				paths[path] = struct{}{}
+			}
+			return
+		}
+		diffValues(paths, path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := range a.NumField() {
+			field := a.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("hcl"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			diffValues(paths, joinPath(path, name), a.Field(i), b.Field(i))
+		}
+	case reflect.Map:
+		for _, key := range a.MapKeys() {
+			keyPath := joinPath(path, fmt.Sprint(key.Interface()))
+			diffValues(paths, keyPath, a.MapIndex(key), b.MapIndex(key))
+		}
+		for _, key := range b.MapKeys() {
+			if !a.MapIndex(key).IsValid() {
+				paths[joinPath(path, fmt.Sprint(key.Interface()))] = struct{}{}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			paths[path] = struct{}{}
+			return
+		}
+		for i := range a.Len() {
+			diffValues(paths, path, a.Index(i), b.Index(i))
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			paths[path] = struct{}{}
+		}
+	}
+}
+
+func printNode(v reflect.Value) string {
+	if v.IsNil() {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.DefaultConfig.Fprint(&buf, v.Interface().(ast.Node)); err != nil {
+		// Nodes that cannot be printed are compared by their error, which is
+		// good enough to detect most changes.
+		return err.Error()
+	}
+	return buf.String()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// ChangeTracker keeps track of the changes made to a configuration while the
+// process is running, so that reloading it can report which settings were
+// changed and which of them still require a restart to take effect.
+type ChangeTracker[T any] struct {
+	mtx        sync.Mutex
+	startup    T
+	last       T
+	reloadable []string
+}
+
+// NewChangeTracker returns a tracker for the configuration the process was
+// started with. The reloadable paths are the settings (and the settings
+// nested under them) that can be applied without a restart.
+func NewChangeTracker[T any](startup T, reloadable ...string) *ChangeTracker[T] {
+	return &ChangeTracker[T]{
+		startup:    startup,
+		last:       startup,
+		reloadable: reloadable,
+	}
+}
+
+// Track records a reloaded configuration. It returns the reloadable settings
+// that changed since the configuration was last tracked and the settings
+// that differ from the startup configuration but cannot be applied without a
+// restart.
+func (t *ChangeTracker[T]) Track(c T) (changed []string, requiresRestart []string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, path := range Diff(t.last, c) {
+		if t.isReloadable(path) {
+			changed = append(changed, path)
+		}
+	}
+	for _, path := range Diff(t.startup, c) {
+		if !t.isReloadable(path) {
+			requiresRestart = append(requiresRestart, path)
+		}
+	}
+	t.last = c
+	return changed, requiresRestart
+}
+
+func (t *ChangeTracker[T]) isReloadable(path string) bool {
+	return slices.ContainsFunc(t.reloadable, func(prefix string) bool {
+		return HasPathPrefix(path, prefix)
+	})
+}
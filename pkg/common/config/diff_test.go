@@ -0,0 +1,176 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Server  *testServerConfig `hcl:"server"`
+	Plugins ast.Node          `hcl:"plugins"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type testServerConfig struct {
+	BindPort      int                          `hcl:"bind_port"`
+	RateLimit     testRateLimitConfig          `hcl:"ratelimit"`
+	FederatesWith map[string]testFederatesWith `hcl:"federates_with"`
+	AdminIDs      []string                     `hcl:"admin_ids"`
+
+	ConfigPath string
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type testRateLimitConfig struct {
+	Attestation *bool `hcl:"attestation"`
+	Signing     *bool `hcl:"signing"`
+}
+
+type testFederatesWith struct {
+	Address string `hcl:"address"`
+}
+
+func TestDiff(t *testing.T) {
+	base := `
+server {
+	bind_port = 8081
+	ratelimit {
+		attestation = true
+	}
+	federates_with "domain1.test" {
+		address = "https://domain1.test"
+	}
+	admin_ids = ["spiffe://example.org/admin"]
+}
+
+plugins {
+	KeyManager "memory" {
+		plugin_data {}
+	}
+}
+`
+
+	for _, tt := range []struct {
+		name         string
+		config       string
+		expectChange []string
+	}{
+		{
+			name:   "no changes",
+			config: base,
+		},
+		{
+			name: "only positions and formatting changed",
+			config: `
+
+server {
+	admin_ids = ["spiffe://example.org/admin"]
+	federates_with "domain1.test" { address = "https://domain1.test" }
+	ratelimit { attestation = true }
+	bind_port = 8081
+}
+plugins {
+	KeyManager "memory" {
+		plugin_data {}
+	}
+}
+`,
+		},
+		{
+			name: "scalars, pointers, maps and slices changed",
+			config: `
+server {
+	bind_port = 8082
+	ratelimit {
+		attestation = false
+		signing = false
+	}
+	federates_with "domain1.test" {
+		address = "https://domain1.test:8443"
+	}
+	federates_with "domain2.test" {
+		address = "https://domain2.test"
+	}
+	admin_ids = ["spiffe://example.org/admin", "spiffe://example.org/admin2"]
+}
+
+plugins {
+	KeyManager "disk" {
+		plugin_data {}
+	}
+}
+`,
+			expectChange: []string{
+				"plugins",
+				"server.admin_ids",
+				"server.bind_port",
+				"server.federates_with.domain1.test.address",
+				"server.federates_with.domain2.test",
+				"server.ratelimit.attestation",
+				"server.ratelimit.signing",
+			},
+		},
+		{
+			name:         "section removed",
+			config:       `plugins { KeyManager "memory" { plugin_data {} } }`,
+			expectChange: []string{"server"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			a := decodeTestConfig(t, base)
+			b := decodeTestConfig(t, tt.config)
+			require.Equal(t, tt.expectChange, nilIfEmpty(Diff(a, b)))
+		})
+	}
+}
+
+func TestChangeTracker(t *testing.T) {
+	startup := decodeTestConfig(t, `server { bind_port = 8081 }`)
+	tracker := NewChangeTracker(startup, "server.ratelimit", "plugins")
+
+	changed, requiresRestart := tracker.Track(decodeTestConfig(t, `server {
+		bind_port = 8082
+		ratelimit { signing = false }
+	}`))
+	require.Equal(t, []string{"server.ratelimit.signing"}, changed)
+	require.Equal(t, []string{"server.bind_port"}, requiresRestart)
+
+	// Reloadable settings are only reported when they change again, while
+	// settings that require a restart keep being reported until then.
+	changed, requiresRestart = tracker.Track(decodeTestConfig(t, `server {
+		bind_port = 8082
+		ratelimit { signing = false }
+	}`))
+	require.Empty(t, changed)
+	require.Equal(t, []string{"server.bind_port"}, requiresRestart)
+
+	changed, requiresRestart = tracker.Track(decodeTestConfig(t, `server { bind_port = 8081 }`))
+	require.Equal(t, []string{"server.ratelimit.signing"}, changed)
+	require.Empty(t, requiresRestart)
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	require.True(t, HasPathPrefix("server.ratelimit", "server.ratelimit"))
+	require.True(t, HasPathPrefix("server.ratelimit.signing", "server.ratelimit"))
+	require.False(t, HasPathPrefix("server.ratelimits", "server.ratelimit"))
+	require.False(t, HasPathPrefix("server", "server.ratelimit"))
+}
+
+func decodeTestConfig(t *testing.T, data string) *testConfig {
+	c := new(testConfig)
+	require.NoError(t, hcl.Decode(c, data))
+	return c
+}
+
+func nilIfEmpty(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	return paths
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// ReloadOnSignal calls reload every time SIGHUP is received, until the
+// context is canceled.
+func ReloadOnSignal(ctx context.Context, log logrus.FieldLogger, reload func(context.Context)) error {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, unix.SIGHUP)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+			log.Info("Reload signal received")
+			reload(ctx)
+		}
+	}
+}
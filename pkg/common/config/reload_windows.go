@@ -0,0 +1,13 @@
+package config
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReloadOnSignal is a no-op on Windows, which has no SIGHUP.
+func ReloadOnSignal(ctx context.Context, _ logrus.FieldLogger, _ func(context.Context)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -3,6 +3,7 @@ package witkey
 import (
 	apitypes "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
 )
 
 func ToAPIProto(witKey WITKey) (*apitypes.WITKey, error) {
@@ -31,3 +32,16 @@ func ToAPIFromPluginProto(pb *plugintypes.WITKey) (*apitypes.WITKey, error) {
 
 	return ToAPIProto(witKey)
 }
+
+func ToAPIFromPublisherProto(pb *witkeypublisher.WITKey) (*apitypes.WITKey, error) {
+	if pb == nil {
+		return nil, nil
+	}
+
+	witKey, err := fromProtoFields(pb.KeyId, pb.PublicKey, pb.ExpiresAt, pb.Tainted)
+	if err != nil {
+		return nil, err
+	}
+
+	return ToAPIProto(witKey)
+}
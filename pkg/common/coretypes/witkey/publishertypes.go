@@ -0,0 +1,119 @@
+package witkey
+
+import (
+	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+func FromPublisherProto(pb *witkeypublisher.WITKey) (WITKey, error) {
+	return fromProtoFields(pb.KeyId, pb.PublicKey, pb.ExpiresAt, pb.Tainted)
+}
+
+func FromPublisherProtos(pbs []*witkeypublisher.WITKey) ([]WITKey, error) {
+	if pbs == nil {
+		return nil, nil
+	}
+	witKeys := make([]WITKey, 0, len(pbs))
+	for _, pb := range pbs {
+		witKey, err := FromPublisherProto(pb)
+		if err != nil {
+			return nil, err
+		}
+		witKeys = append(witKeys, witKey)
+	}
+	return witKeys, nil
+}
+
+func ToPublisherProto(witKey WITKey) (*witkeypublisher.WITKey, error) {
+	id, publicKey, expiresAt, tainted, err := toProtoFields(witKey)
+	if err != nil {
+		return nil, err
+	}
+	return &witkeypublisher.WITKey{
+		KeyId:     id,
+		PublicKey: publicKey,
+		ExpiresAt: expiresAt,
+		Tainted:   tainted,
+	}, nil
+}
+
+func ToPublisherProtos(witKeys []WITKey) ([]*witkeypublisher.WITKey, error) {
+	if witKeys == nil {
+		return nil, nil
+	}
+	pbs := make([]*witkeypublisher.WITKey, 0, len(witKeys))
+	for _, witKey := range witKeys {
+		pb, err := ToPublisherProto(witKey)
+		if err != nil {
+			return nil, err
+		}
+		pbs = append(pbs, pb)
+	}
+	return pbs, nil
+}
+
+func ToPublisherFromCommonProto(pb *common.PublicKey) (*witkeypublisher.WITKey, error) {
+	witKey, err := FromCommonProto(pb)
+	if err != nil {
+		return nil, err
+	}
+	return ToPublisherProto(witKey)
+}
+
+func ToPublisherFromCommonProtos(pbs []*common.PublicKey) ([]*witkeypublisher.WITKey, error) {
+	if pbs == nil {
+		return nil, nil
+	}
+	witKeys := make([]*witkeypublisher.WITKey, 0, len(pbs))
+	for _, pb := range pbs {
+		witKey, err := ToPublisherFromCommonProto(pb)
+		if err != nil {
+			return nil, err
+		}
+		witKeys = append(witKeys, witKey)
+	}
+	return witKeys, nil
+}
+
+func ToCommonFromPublisherProto(pb *witkeypublisher.WITKey) (*common.PublicKey, error) {
+	witKey, err := FromPublisherProto(pb)
+	if err != nil {
+		return nil, err
+	}
+	return ToCommonProto(witKey)
+}
+
+func ToCommonFromPublisherProtos(pbs []*witkeypublisher.WITKey) ([]*common.PublicKey, error) {
+	if pbs == nil {
+		return nil, nil
+	}
+	witKeys := make([]*common.PublicKey, 0, len(pbs))
+	for _, pb := range pbs {
+		witKey, err := ToCommonFromPublisherProto(pb)
+		if err != nil {
+			return nil, err
+		}
+		witKeys = append(witKeys, witKey)
+	}
+	return witKeys, nil
+}
+
+func ToPublisherFromPluginProtos(pbs []*plugintypes.WITKey) ([]*witkeypublisher.WITKey, error) {
+	if pbs == nil {
+		return nil, nil
+	}
+	witKeys := make([]*witkeypublisher.WITKey, 0, len(pbs))
+	for _, pb := range pbs {
+		witKey, err := FromPluginProto(pb)
+		if err != nil {
+			return nil, err
+		}
+		publisherWITKey, err := ToPublisherProto(witKey)
+		if err != nil {
+			return nil, err
+		}
+		witKeys = append(witKeys, publisherWITKey)
+	}
+	return witKeys, nil
+}
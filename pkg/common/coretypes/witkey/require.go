@@ -2,6 +2,7 @@ package witkey
 
 import (
 	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
@@ -77,6 +78,30 @@ func RequireToPluginProtos(witKeys []WITKey) []*plugintypes.WITKey {
 	return out
 }
 
+func RequireToCommonFromPublisherProto(pb *witkeypublisher.WITKey) *common.PublicKey {
+	out, err := ToCommonFromPublisherProto(pb)
+	panicOnError(err)
+	return out
+}
+
+func RequireToCommonFromPublisherProtos(pbs []*witkeypublisher.WITKey) []*common.PublicKey {
+	out, err := ToCommonFromPublisherProtos(pbs)
+	panicOnError(err)
+	return out
+}
+
+func RequireToPublisherFromCommonProto(pb *common.PublicKey) *witkeypublisher.WITKey {
+	out, err := ToPublisherFromCommonProto(pb)
+	panicOnError(err)
+	return out
+}
+
+func RequireToPublisherFromCommonProtos(pbs []*common.PublicKey) []*witkeypublisher.WITKey {
+	out, err := ToPublisherFromCommonProtos(pbs)
+	panicOnError(err)
+	return out
+}
+
 func panicOnError(err error) {
 	if err != nil {
 		panic(err)
@@ -9,6 +9,7 @@ import (
 	apitypes "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
 	"github.com/spiffe/spire/pkg/common/coretypes/witkey"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testkey"
@@ -45,6 +46,12 @@ var (
 	apiNoPublicKey     = &apitypes.WITKey{KeyId: "ID", ExpiresAt: expiresAt.Unix()}
 	apiBadPublicKey    = &apitypes.WITKey{KeyId: "ID", PublicKey: junk, ExpiresAt: expiresAt.Unix()}
 	apiNoExpiresAt     = &apitypes.WITKey{KeyId: "ID", PublicKey: pkixBytes}
+	publisherGood      = &witkeypublisher.WITKey{KeyId: "ID", PublicKey: pkixBytes, ExpiresAt: expiresAt.Unix()}
+	publisherTainted   = &witkeypublisher.WITKey{KeyId: "ID", PublicKey: pkixBytes, ExpiresAt: expiresAt.Unix(), Tainted: true}
+	publisherNoKeyID   = &witkeypublisher.WITKey{PublicKey: pkixBytes, ExpiresAt: expiresAt.Unix()}
+	publisherNoPubKey  = &witkeypublisher.WITKey{KeyId: "ID", ExpiresAt: expiresAt.Unix()}
+	publisherBadPubKey = &witkeypublisher.WITKey{KeyId: "ID", PublicKey: junk, ExpiresAt: expiresAt.Unix()}
+	publisherNoExpires = &witkeypublisher.WITKey{KeyId: "ID", PublicKey: pkixBytes}
 )
 
 func TestFromCommonProto(t *testing.T) {
@@ -394,6 +401,130 @@ func TestToAPIFromPluginProto(t *testing.T) {
 	assertOK(t, nil, nil)
 }
 
+func TestFromPublisherProtos(t *testing.T) {
+	assertOK := func(t *testing.T, in []*witkeypublisher.WITKey, expectOut []witkey.WITKey) {
+		actualOut, err := witkey.FromPublisherProtos(in)
+		require.NoError(t, err)
+		assertWITKeysEqual(t, expectOut, actualOut)
+	}
+
+	assertFail := func(t *testing.T, in []*witkeypublisher.WITKey, expectErr string) {
+		actualOut, err := witkey.FromPublisherProtos(in)
+		spiretest.RequireErrorPrefix(t, err, expectErr)
+		assert.Nil(t, actualOut)
+	}
+
+	assertOK(t, []*witkeypublisher.WITKey{publisherGood, publisherTainted, publisherNoExpires},
+		[]witkey.WITKey{witKeyGood, witKeyTaintedGood, witKeyNoExpiresAt})
+	assertFail(t, []*witkeypublisher.WITKey{publisherNoKeyID}, "missing key ID for WIT key")
+	assertFail(t, []*witkeypublisher.WITKey{publisherNoPubKey}, `missing public key for WIT key "ID"`)
+	assertFail(t, []*witkeypublisher.WITKey{publisherBadPubKey}, `failed to unmarshal public key for WIT key "ID": `)
+	assertOK(t, nil, nil)
+}
+
+func TestToPublisherProtos(t *testing.T) {
+	assertOK := func(t *testing.T, in []witkey.WITKey, expectOut []*witkeypublisher.WITKey) {
+		actualOut, err := witkey.ToPublisherProtos(in)
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, expectOut, actualOut)
+	}
+
+	assertFail := func(t *testing.T, in []witkey.WITKey, expectErr string) {
+		actualOut, err := witkey.ToPublisherProtos(in)
+		spiretest.RequireErrorPrefix(t, err, expectErr)
+		assert.Empty(t, actualOut)
+	}
+
+	assertOK(t, []witkey.WITKey{witKeyGood, witKeyTaintedGood, witKeyNoExpiresAt},
+		[]*witkeypublisher.WITKey{publisherGood, publisherTainted, publisherNoExpires})
+	assertFail(t, []witkey.WITKey{witKeyNoKeyID}, "missing key ID for WIT key")
+	assertFail(t, []witkey.WITKey{witKeyBadPublicKey}, `failed to marshal public key for WIT key "ID": `)
+	assertOK(t, nil, nil)
+}
+
+func TestToCommonFromPublisherProtos(t *testing.T) {
+	assertOK := func(t *testing.T, in []*witkeypublisher.WITKey, expectOut []*common.PublicKey) {
+		actualOut, err := witkey.ToCommonFromPublisherProtos(in)
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, expectOut, actualOut)
+		assert.NotPanics(t, func() { spiretest.AssertProtoListEqual(t, expectOut, witkey.RequireToCommonFromPublisherProtos(in)) })
+	}
+
+	assertFail := func(t *testing.T, in []*witkeypublisher.WITKey, expectErr string) {
+		actualOut, err := witkey.ToCommonFromPublisherProtos(in)
+		spiretest.RequireErrorPrefix(t, err, expectErr)
+		assert.Empty(t, actualOut)
+		assert.Panics(t, func() { witkey.RequireToCommonFromPublisherProtos(in) })
+	}
+
+	assertOK(t, []*witkeypublisher.WITKey{publisherGood, publisherTainted},
+		[]*common.PublicKey{commonGood, commonTaintedGood})
+	assertFail(t, []*witkeypublisher.WITKey{publisherNoKeyID}, "missing key ID for WIT key")
+	assertOK(t, nil, nil)
+}
+
+func TestToPublisherFromCommonProtos(t *testing.T) {
+	assertOK := func(t *testing.T, in []*common.PublicKey, expectOut []*witkeypublisher.WITKey) {
+		actualOut, err := witkey.ToPublisherFromCommonProtos(in)
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, expectOut, actualOut)
+		assert.NotPanics(t, func() { spiretest.AssertProtoListEqual(t, expectOut, witkey.RequireToPublisherFromCommonProtos(in)) })
+	}
+
+	assertFail := func(t *testing.T, in []*common.PublicKey, expectErr string) {
+		actualOut, err := witkey.ToPublisherFromCommonProtos(in)
+		spiretest.RequireErrorPrefix(t, err, expectErr)
+		assert.Empty(t, actualOut)
+		assert.Panics(t, func() { witkey.RequireToPublisherFromCommonProtos(in) })
+	}
+
+	assertOK(t, []*common.PublicKey{commonGood, commonTaintedGood},
+		[]*witkeypublisher.WITKey{publisherGood, publisherTainted})
+	assertFail(t, []*common.PublicKey{commonNoKeyID}, "missing key ID for WIT key")
+	assertOK(t, nil, nil)
+}
+
+func TestToPublisherFromPluginProtos(t *testing.T) {
+	assertOK := func(t *testing.T, in []*plugintypes.WITKey, expectOut []*witkeypublisher.WITKey) {
+		actualOut, err := witkey.ToPublisherFromPluginProtos(in)
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, expectOut, actualOut)
+	}
+
+	assertFail := func(t *testing.T, in []*plugintypes.WITKey, expectErr string) {
+		actualOut, err := witkey.ToPublisherFromPluginProtos(in)
+		spiretest.RequireErrorPrefix(t, err, expectErr)
+		assert.Empty(t, actualOut)
+	}
+
+	assertOK(t, []*plugintypes.WITKey{pluginGood, pluginTaintedGood},
+		[]*witkeypublisher.WITKey{publisherGood, publisherTainted})
+	assertFail(t, []*plugintypes.WITKey{pluginNoKeyID}, "missing key ID for WIT key")
+	assertOK(t, nil, nil)
+}
+
+func TestToAPIFromPublisherProto(t *testing.T) {
+	assertOK := func(t *testing.T, in *witkeypublisher.WITKey, expectOut *apitypes.WITKey) {
+		actualOut, err := witkey.ToAPIFromPublisherProto(in)
+		require.NoError(t, err)
+		spiretest.AssertProtoEqual(t, expectOut, actualOut)
+	}
+
+	assertFail := func(t *testing.T, in *witkeypublisher.WITKey, expectErr string) {
+		actualOut, err := witkey.ToAPIFromPublisherProto(in)
+		spiretest.RequireErrorPrefix(t, err, expectErr)
+		assert.Empty(t, actualOut)
+	}
+
+	assertOK(t, publisherGood, apiGood)
+	assertOK(t, publisherTainted, apiTaintedGood)
+	assertFail(t, publisherNoKeyID, "missing key ID for WIT key")
+	assertFail(t, publisherNoPubKey, `missing public key for WIT key "ID"`)
+	assertFail(t, publisherBadPubKey, `failed to unmarshal public key for WIT key "ID": `)
+	assertOK(t, publisherNoExpires, apiNoExpiresAt)
+	assertOK(t, nil, nil)
+}
+
 func assertWITKeysEqual(t *testing.T, expected, actual []witkey.WITKey) {
 	assert.Empty(t, cmp.Diff(expected, actual))
 }
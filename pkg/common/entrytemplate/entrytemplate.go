@@ -0,0 +1,269 @@
+// Package entrytemplate implements registration entry templates. An entry
+// template is stored in the datastore next to the registration entries, and
+// its SPIFFE ID path is a template over the selectors of the workloads it
+// matches, e.g. "spiffe://example.org/ns/{{ .k8s.ns }}/sa/{{ .k8s.sa }}".
+//
+// Selector values are referenced as ".<type>.<key>", where the selector value
+// is split into key and value on the first colon, i.e. the "k8s:ns:web"
+// selector is referenced as ".k8s.ns" and renders as "web".
+//
+// Entry templates are sent to the agents as registration entries whose ID is
+// in a namespace reserved for entry templates (see EntryID). When a workload
+// matches a template, the agent renders the SPIFFE ID and derives an entry for
+// it, whose ID encodes the template entry ID and the selectors the SPIFFE ID
+// was rendered from. This allows the server to render the same SPIFFE ID when
+// minting SVIDs for the derived entry.
+package entrytemplate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/agentpathtemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+const (
+	// templateEntryIDPrefix prefixes the entry template ID in the ID of the
+	// entry an entry template is sent to the agents as. The colon is not a
+	// valid entry ID character, so template entry IDs can never collide with
+	// entry IDs.
+	templateEntryIDPrefix = "template:"
+
+	// derivedEntryIDSeparator separates the template entry ID from the
+	// encoded selectors in a derived entry ID. It is not a valid entry ID
+	// character, so derived entry IDs can never collide with entry IDs.
+	derivedEntryIDSeparator = "#"
+
+	spiffeScheme = "spiffe://"
+
+	// MaxTemplateIDLength is the maximum length of an entry template ID. The
+	// template entry ID is recorded in the registration entry events, whose
+	// entry IDs are limited to 255 characters.
+	MaxTemplateIDLength = 255 - len(templateEntryIDPrefix)
+
+	// AgentMetadataKey is the gRPC metadata key agents set to "true" when
+	// fetching their authorized entries to advertise that they render entry
+	// templates. Template entries are only sent to agents that set it.
+	AgentMetadataKey = "spire-agent-entry-templates"
+)
+
+// EntryID returns the ID of the registration entry the entry template with
+// the given ID is sent to the agents as.
+func EntryID(templateID string) string {
+	return templateEntryIDPrefix + templateID
+}
+
+// IsTemplateEntryID returns true if the entry ID is the ID of the registration
+// entry an entry template is sent to the agents as.
+func IsTemplateEntryID(entryID string) bool {
+	_, ok := ParseEntryID(entryID)
+	return ok
+}
+
+// ParseEntryID returns the ID of the entry template the given entry ID is the
+// template entry ID of. It returns false if it is not a template entry ID.
+func ParseEntryID(entryID string) (string, bool) {
+	templateID, ok := strings.CutPrefix(entryID, templateEntryIDPrefix)
+	if !ok || templateID == "" || strings.Contains(templateID, derivedEntryIDSeparator) {
+		return "", false
+	}
+	return templateID, true
+}
+
+// Template is a parsed entry template SPIFFE ID.
+type Template struct {
+	td   spiffeid.TrustDomain
+	path string
+	tmpl *agentpathtemplate.Template
+	refs []reference
+}
+
+type reference struct {
+	typ string
+	key string
+}
+
+// Parse parses the SPIFFE ID of an entry template.
+func Parse(spiffeID string) (*Template, error) {
+	rest, ok := strings.CutPrefix(spiffeID, spiffeScheme)
+	if !ok {
+		return nil, errors.New("scheme is missing or invalid")
+	}
+
+	tdName, path, ok := strings.Cut(rest, "/")
+	if !ok {
+		return nil, errors.New("path is missing")
+	}
+	td, err := spiffeid.TrustDomainFromString(tdName)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(td, "/"+path)
+}
+
+// New creates an entry template for the given trust domain and path template.
+func New(td spiffeid.TrustDomain, path string) (*Template, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("path must have a leading slash")
+	}
+
+	tmpl, err := agentpathtemplate.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template: %w", err)
+	}
+
+	seen := make(map[reference]bool)
+	var refs []reference
+	for _, field := range tmpl.Fields() {
+		if len(field) != 2 {
+			return nil, fmt.Errorf("invalid path template: selectors must be referenced as .<type>.<key>, got %q", "."+strings.Join(field, "."))
+		}
+		ref := reference{typ: field[0], key: field[1]}
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, errors.New("invalid path template: no selectors are referenced")
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].typ != refs[j].typ {
+			return refs[i].typ < refs[j].typ
+		}
+		return refs[i].key < refs[j].key
+	})
+
+	return &Template{
+		td:   td,
+		path: path,
+		tmpl: tmpl,
+		refs: refs,
+	}, nil
+}
+
+// TrustDomain returns the trust domain of the template.
+func (t *Template) TrustDomain() spiffeid.TrustDomain {
+	return t.td
+}
+
+// Path returns the path template.
+func (t *Template) Path() string {
+	return t.path
+}
+
+// String returns the SPIFFE ID of the template.
+func (t *Template) String() string {
+	return spiffeScheme + t.td.Name() + t.path
+}
+
+// Render renders the SPIFFE ID for a workload with the given selectors. It
+// also returns the selectors referenced by the template, in a stable order,
+// which are the only selectors the SPIFFE ID depends on. Selector values must
+// render as a single path segment, so values that are empty or contain a
+// slash are rejected instead of being allowed to change the path structure.
+func (t *Template) Render(selectors []*common.Selector) (spiffeid.ID, []*common.Selector, error) {
+	values := make(map[reference]string, len(t.refs))
+	for _, s := range selectors {
+		key, value, ok := strings.Cut(s.Value, ":")
+		if !ok {
+			continue
+		}
+		ref := reference{typ: s.Type, key: key}
+		if !t.references(ref) {
+			continue
+		}
+		if existing, ok := values[ref]; ok && existing != value {
+			return spiffeid.ID{}, nil, fmt.Errorf("selector \"%s:%s\" has more than one value", ref.typ, ref.key)
+		}
+		values[ref] = value
+	}
+
+	data := make(map[string]map[string]string)
+	used := make([]*common.Selector, 0, len(t.refs))
+	for _, ref := range t.refs {
+		value, ok := values[ref]
+		switch {
+		case !ok:
+			return spiffeid.ID{}, nil, fmt.Errorf("selector \"%s:%s\" is missing", ref.typ, ref.key)
+		case value == "":
+			return spiffeid.ID{}, nil, fmt.Errorf("selector \"%s:%s\" value is empty", ref.typ, ref.key)
+		case strings.Contains(value, "/"):
+			return spiffeid.ID{}, nil, fmt.Errorf("selector \"%s:%s\" value %q contains \"/\"", ref.typ, ref.key, value)
+		}
+		if data[ref.typ] == nil {
+			data[ref.typ] = make(map[string]string)
+		}
+		data[ref.typ][ref.key] = value
+		used = append(used, &common.Selector{Type: ref.typ, Value: ref.key + ":" + value})
+	}
+
+	path, err := t.tmpl.Execute(data)
+	if err != nil {
+		return spiffeid.ID{}, nil, fmt.Errorf("failed to render path template: %w", err)
+	}
+
+	id, err := spiffeid.FromPath(t.td, path)
+	if err != nil {
+		return spiffeid.ID{}, nil, fmt.Errorf("rendered path %q is invalid: %w", path, err)
+	}
+	return id, used, nil
+}
+
+func (t *Template) references(ref reference) bool {
+	for _, r := range t.refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// DerivedEntryID returns the ID of the entry derived from the template entry
+// with the given ID (see EntryID), for the selectors returned by Render.
+func DerivedEntryID(templateEntryID string, selectors []*common.Selector) string {
+	values := make([]string, 0, len(selectors))
+	for _, s := range selectors {
+		values = append(values, s.Type+":"+s.Value)
+	}
+	// Marshaling a slice of strings cannot fail.
+	encoded, _ := json.Marshal(values)
+	return templateEntryID + derivedEntryIDSeparator + base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// ParseDerivedEntryID parses the ID of an entry derived from an entry
+// template, returning the template entry ID and the selectors the SPIFFE ID of
+// the derived entry was rendered from. It returns false if the ID is not the
+// ID of a derived entry.
+func ParseDerivedEntryID(entryID string) (string, []*common.Selector, bool) {
+	templateEntryID, encoded, ok := strings.Cut(entryID, derivedEntryIDSeparator)
+	if !ok || !IsTemplateEntryID(templateEntryID) {
+		return "", nil, false
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, false
+	}
+	var values []string
+	if err := json.Unmarshal(decoded, &values); err != nil {
+		return "", nil, false
+	}
+
+	selectors := make([]*common.Selector, 0, len(values))
+	for _, value := range values {
+		typ, value, ok := strings.Cut(value, ":")
+		if !ok {
+			return "", nil, false
+		}
+		selectors = append(selectors, &common.Selector{Type: typ, Value: value})
+	}
+	return templateEntryID, selectors, true
+}
@@ -0,0 +1,191 @@
+package entrytemplate_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryID(t *testing.T) {
+	entryID := entrytemplate.EntryID("template-id")
+	assert.Equal(t, "template:template-id", entryID)
+	assert.True(t, entrytemplate.IsTemplateEntryID(entryID))
+
+	assert.False(t, entrytemplate.IsTemplateEntryID("template-id"))
+	assert.False(t, entrytemplate.IsTemplateEntryID("template:"))
+	assert.False(t, entrytemplate.IsTemplateEntryID("template:template-id#e30"))
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		id     string
+		expErr string
+	}{
+		{
+			name: "valid",
+			id:   "spiffe://example.org/ns/{{ .k8s.ns }}/sa/{{ .k8s.sa }}",
+		},
+		{
+			name:   "missing scheme",
+			id:     "example.org/ns/{{ .k8s.ns }}",
+			expErr: "scheme is missing or invalid",
+		},
+		{
+			name:   "missing path",
+			id:     "spiffe://example.org",
+			expErr: "path is missing",
+		},
+		{
+			name:   "invalid trust domain",
+			id:     "spiffe://EXAMPLE.org/{{ .k8s.ns }}",
+			expErr: "trust domain characters are limited to lowercase letters, numbers, dots, dashes, and underscores",
+		},
+		{
+			name:   "invalid template",
+			id:     "spiffe://example.org/{{ .k8s.ns ",
+			expErr: "invalid path template: template: agent-path:1: unclosed action",
+		},
+		{
+			name:   "invalid reference",
+			id:     "spiffe://example.org/{{ .k8s }}",
+			expErr: "invalid path template: selectors must be referenced as .<type>.<key>, got \".k8s\"",
+		},
+		{
+			name:   "no references",
+			id:     "spiffe://example.org/{{ \"static\" }}",
+			expErr: "invalid path template: no selectors are referenced",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := entrytemplate.Parse(tt.id)
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "example.org", tmpl.TrustDomain().Name())
+			assert.Equal(t, "/ns/{{ .k8s.ns }}/sa/{{ .k8s.sa }}", tmpl.Path())
+			assert.Equal(t, tt.id, tmpl.String())
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl, err := entrytemplate.Parse("spiffe://example.org/ns/{{ .k8s.ns }}/sa/{{ .k8s.sa | lower }}")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		selectors []*common.Selector
+		expID     string
+		expUsed   []*common.Selector
+		expErr    string
+	}{
+		{
+			name: "success",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "sa:Default"},
+				{Type: "k8s", Value: "ns:web"},
+				{Type: "k8s", Value: "pod-uid:1234"},
+				{Type: "unix", Value: "uid:1000"},
+			},
+			expID: "spiffe://example.org/ns/web/sa/default",
+			expUsed: []*common.Selector{
+				{Type: "k8s", Value: "ns:web"},
+				{Type: "k8s", Value: "sa:Default"},
+			},
+		},
+		{
+			name: "missing selector",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:web"},
+			},
+			expErr: "selector \"k8s:sa\" is missing",
+		},
+		{
+			name: "ambiguous selector",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:web"},
+				{Type: "k8s", Value: "ns:db"},
+				{Type: "k8s", Value: "sa:default"},
+			},
+			expErr: "selector \"k8s:ns\" has more than one value",
+		},
+		{
+			name: "value with slash",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:prod/sa/admin/x"},
+				{Type: "k8s", Value: "sa:y"},
+			},
+			expErr: "selector \"k8s:ns\" value \"prod/sa/admin/x\" contains \"/\"",
+		},
+		{
+			name: "empty value",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:"},
+				{Type: "k8s", Value: "sa:default"},
+			},
+			expErr: "selector \"k8s:ns\" value is empty",
+		},
+		{
+			name: "invalid rendered path",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:.."},
+				{Type: "k8s", Value: "sa:default"},
+			},
+			expErr: "rendered path \"/ns/../sa/default\" is invalid: path cannot contain dot segments",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id, used, err := tmpl.Render(tt.selectors)
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expID, id.String())
+			assert.Equal(t, tt.expUsed, used)
+		})
+	}
+}
+
+func TestDerivedEntryID(t *testing.T) {
+	selectors := []*common.Selector{
+		{Type: "k8s", Value: "ns:web"},
+		{Type: "k8s", Value: "sa:default"},
+	}
+	derivedID := entrytemplate.DerivedEntryID("template:template-id", selectors)
+
+	templateEntryID, parsed, ok := entrytemplate.ParseDerivedEntryID(derivedID)
+	require.True(t, ok)
+	assert.Equal(t, "template:template-id", templateEntryID)
+	assert.Equal(t, selectors, parsed)
+
+	for _, id := range []string{
+		"entry-id",
+		"#e30",
+		"template:#e30",
+		"entry-id#e30",
+		"template:template-id#not-base64!",
+		"template:template-id#bm90LWpzb24",
+	} {
+		_, _, ok := entrytemplate.ParseDerivedEntryID(id)
+		assert.False(t, ok, "%q should not be a derived entry ID", id)
+	}
+}
+
+func TestNew(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+
+	_, err := entrytemplate.New(td, "ns/{{ .k8s.ns }}")
+	require.EqualError(t, err, "path must have a leading slash")
+
+	tmpl, err := entrytemplate.New(td, "/ns/{{ $.k8s.ns }}")
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/ns/{{ $.k8s.ns }}", tmpl.String())
+}
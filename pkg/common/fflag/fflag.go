@@ -2,6 +2,10 @@
 // providing SPIRE with a system-wide feature flagging facility. Feature flags
 // can be easily added here, in a single central location, and be consumed
 // throughout the codebase.
+//
+// Feature flags are loaded once at startup and can later be reloaded (e.g.
+// on SIGHUP). Subsystems that need to react to flags being toggled at
+// runtime can subscribe to be notified of changes.
 package fflag
 
 import (
@@ -14,6 +18,10 @@ import (
 // Flag represents a feature flag and its configuration name
 type Flag string
 
+// AgentMetadataKey is the gRPC metadata key used by agents to report the
+// feature flags they have enabled when posting their status to the server.
+const AgentMetadataKey = "spire-agent-feature-flags"
+
 // RawConfig is a list of feature flags that should be flipped on, in their string
 // representations. It is loaded directly from the config file.
 type RawConfig []string
@@ -34,19 +42,27 @@ const (
 
 var (
 	singleton = struct {
-		flags  map[Flag]bool
-		loaded bool
-		mtx    *sync.RWMutex
+		flags       map[Flag]bool
+		loaded      bool
+		subscribers map[chan struct{}]struct{}
+		mtx         *sync.RWMutex
 	}{
 		flags: map[Flag]bool{
 			FlagTestFlag: false,
 			FlagWITSVID:  false,
 		},
-		loaded: false,
-		mtx:    new(sync.RWMutex),
+		loaded:      false,
+		subscribers: make(map[chan struct{}]struct{}),
+		mtx:         new(sync.RWMutex),
 	}
 )
 
+// Status is the state of a feature flag.
+type Status struct {
+	Flag    Flag
+	Enabled bool
+}
+
 // Load initializes the fflag package and configures its feature flag state
 // based on the configuration input. Feature flags are designed to be
 // Write-Once-Read-Many, and as such, Load can be called only once (except when Using Unload function
@@ -61,20 +77,9 @@ func Load(rc RawConfig) error {
 		return errors.New("feature flags have already been loaded")
 	}
 
-	badFlags := []string{}
-	goodFlags := []Flag{}
-	for _, rawFlag := range rc {
-		if _, ok := singleton.flags[Flag(rawFlag)]; !ok {
-			badFlags = append(badFlags, rawFlag)
-			continue
-		}
-
-		goodFlags = append(goodFlags, Flag(rawFlag))
-	}
-
-	if len(badFlags) > 0 {
-		sort.Strings(badFlags)
-		return fmt.Errorf("unknown feature flag(s): %v", badFlags)
+	goodFlags, err := parse(rc)
+	if err != nil {
+		return err
 	}
 
 	for _, f := range goodFlags {
@@ -85,6 +90,50 @@ func Load(rc RawConfig) error {
 	return nil
 }
 
+// Reload replaces the feature flag state with the configuration input. Flags
+// that are not present in the input are turned off. It returns the flags whose
+// state changed, and notifies the subscribers if there are any. The state is
+// left untouched if the configuration input cannot be parsed or if an
+// unrecognized flag is set. Reload can only be called after Load.
+func Reload(rc RawConfig) ([]Flag, error) {
+	singleton.mtx.Lock()
+	defer singleton.mtx.Unlock()
+
+	if !singleton.loaded {
+		return nil, errors.New("feature flags have not been loaded")
+	}
+
+	goodFlags, err := parse(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[Flag]bool, len(goodFlags))
+	for _, f := range goodFlags {
+		enabled[f] = true
+	}
+
+	var changed []Flag
+	for f, isSet := range singleton.flags {
+		if enabled[f] != isSet {
+			singleton.flags[f] = enabled[f]
+			changed = append(changed, f)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i] < changed[j] })
+
+	if len(changed) > 0 {
+		for ch := range singleton.subscribers {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	return changed, nil
+}
+
 // Unload resets the feature flags states to its default values. This function is intended to be used for testing
 // purposes only, it is not expected to be called by the normal execution of SPIRE.
 func Unload() error {
@@ -111,3 +160,70 @@ func IsSet(f Flag) bool {
 
 	return singleton.flags[f]
 }
+
+// List returns the state of all the known feature flags, sorted by name. The
+// flag reserved for testing is not included.
+func List() []Status {
+	singleton.mtx.RLock()
+	defer singleton.mtx.RUnlock()
+
+	statuses := make([]Status, 0, len(singleton.flags))
+	for f, isSet := range singleton.flags {
+		if f == FlagTestFlag {
+			continue
+		}
+		statuses = append(statuses, Status{Flag: f, Enabled: isSet})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Flag < statuses[j].Flag })
+	return statuses
+}
+
+// Enabled returns the names of the feature flags that are set, sorted by
+// name.
+func Enabled() []string {
+	var enabled []string
+	for _, status := range List() {
+		if status.Enabled {
+			enabled = append(enabled, string(status.Flag))
+		}
+	}
+	return enabled
+}
+
+// Subscribe returns a channel that receives a notification every time the
+// state of any feature flag is changed by Reload. Notifications are not queued
+// while the subscriber is busy, so subscribers are expected to check the
+// state of the flags they care about when notified. The returned function
+// must be called to unsubscribe.
+func Subscribe() (<-chan struct{}, func()) {
+	singleton.mtx.Lock()
+	defer singleton.mtx.Unlock()
+
+	ch := make(chan struct{}, 1)
+	singleton.subscribers[ch] = struct{}{}
+	return ch, func() {
+		singleton.mtx.Lock()
+		defer singleton.mtx.Unlock()
+		delete(singleton.subscribers, ch)
+	}
+}
+
+func parse(rc RawConfig) ([]Flag, error) {
+	badFlags := []string{}
+	goodFlags := []Flag{}
+	for _, rawFlag := range rc {
+		if _, ok := singleton.flags[Flag(rawFlag)]; !ok {
+			badFlags = append(badFlags, rawFlag)
+			continue
+		}
+
+		goodFlags = append(goodFlags, Flag(rawFlag))
+	}
+
+	if len(badFlags) > 0 {
+		sort.Strings(badFlags)
+		return nil, fmt.Errorf("unknown feature flag(s): %v", badFlags)
+	}
+
+	return goodFlags, nil
+}
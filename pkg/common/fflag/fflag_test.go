@@ -1,6 +1,7 @@
 package fflag
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/spiffe/spire/test/spiretest"
@@ -147,6 +148,113 @@ func TestUnload(t *testing.T) {
 	}
 }
 
+func TestReload(t *testing.T) {
+	reset()
+	defer reset()
+
+	_, err := Reload(RawConfig{"i_am_a_test_flag"})
+	assert.EqualError(t, err, "feature flags have not been loaded")
+
+	assert.NoError(t, Load(RawConfig{"wit-svid"}))
+
+	changed, err := Reload(RawConfig{"i_am_a_test_flag", "wit-svid"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Flag{FlagTestFlag}, changed)
+	assert.True(t, IsSet(FlagTestFlag))
+	assert.True(t, IsSet(FlagWITSVID))
+
+	changed, err = Reload(RawConfig{"i_am_a_test_flag", "wit-svid"})
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	// Flags absent from the configuration are turned off
+	changed, err = Reload(RawConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Flag{FlagTestFlag, FlagWITSVID}, changed)
+	assert.False(t, IsSet(FlagTestFlag))
+	assert.False(t, IsSet(FlagWITSVID))
+
+	// The state is untouched if there are unknown flags
+	changed, err = Reload(RawConfig{"wit-svid", "non_existent_flag"})
+	assert.EqualError(t, err, "unknown feature flag(s): [non_existent_flag]")
+	assert.Nil(t, changed)
+	assert.False(t, IsSet(FlagWITSVID))
+}
+
+func TestReloadFrom(t *testing.T) {
+	reset()
+	defer reset()
+
+	assert.NoError(t, Load(RawConfig{}))
+
+	changed, err := ReloadFrom(func() (RawConfig, error) {
+		return RawConfig{"wit-svid"}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Flag{FlagWITSVID}, changed)
+
+	_, err = ReloadFrom(func() (RawConfig, error) {
+		return nil, errors.New("oh no")
+	})
+	assert.EqualError(t, err, "unable to load feature flags: oh no")
+	assert.True(t, IsSet(FlagWITSVID))
+}
+
+func TestListAndEnabled(t *testing.T) {
+	reset()
+	defer reset()
+
+	assert.Equal(t, []Status{{Flag: FlagWITSVID, Enabled: false}}, List())
+	assert.Empty(t, Enabled())
+
+	assert.NoError(t, Load(RawConfig{"i_am_a_test_flag", "wit-svid"}))
+	assert.Equal(t, []Status{{Flag: FlagWITSVID, Enabled: true}}, List())
+	assert.Equal(t, []string{"wit-svid"}, Enabled())
+}
+
+func TestSubscribe(t *testing.T) {
+	reset()
+	defer reset()
+
+	assert.NoError(t, Load(RawConfig{}))
+
+	changed, unsubscribe := Subscribe()
+
+	// No notification is sent when nothing changes
+	_, err := Reload(RawConfig{})
+	assert.NoError(t, err)
+	assertNotNotified(t, changed)
+
+	// Notifications are coalesced while the subscriber is busy
+	_, err = Reload(RawConfig{"wit-svid"})
+	assert.NoError(t, err)
+	_, err = Reload(RawConfig{})
+	assert.NoError(t, err)
+	assertNotified(t, changed)
+	assertNotNotified(t, changed)
+
+	unsubscribe()
+	_, err = Reload(RawConfig{"wit-svid"})
+	assert.NoError(t, err)
+	assertNotNotified(t, changed)
+}
+
+func assertNotified(t *testing.T, ch <-chan struct{}) {
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected a notification")
+	}
+}
+
+func assertNotNotified(t *testing.T, ch <-chan struct{}) {
+	select {
+	case <-ch:
+		t.Fatal("unexpected notification")
+	default:
+	}
+}
+
 func reset() {
 	singleton.mtx.Lock()
 	defer singleton.mtx.Unlock()
@@ -0,0 +1,36 @@
+package fflag
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Loader loads the raw feature flag configuration, e.g. by parsing the
+// configuration file again.
+type Loader func() (RawConfig, error)
+
+// ReloadFrom loads the raw configuration using the given loader and reloads
+// the feature flags with it. It returns the flags whose state changed.
+func ReloadFrom(loader Loader) ([]Flag, error) {
+	rc, err := loader()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load feature flags: %w", err)
+	}
+	return Reload(rc)
+}
+
+// LogReloaded logs the result of reloading the feature flags.
+func LogReloaded(log logrus.FieldLogger, changed []Flag) {
+	if len(changed) == 0 {
+		log.Info("Feature flags reloaded; no changes")
+		return
+	}
+	for _, f := range changed {
+		log.WithFields(logrus.Fields{
+			telemetry.FeatureFlag: f,
+			telemetry.Enabled:     IsSet(f),
+		}).Info("Feature flag toggled")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 
+	"github.com/go-jose/go-jose/v4/jwt"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 )
 
@@ -39,3 +40,14 @@ func ProtoFromJWTKeys(keys map[string]crypto.PublicKey) ([]*types.JWTKey, error)
 
 	return resp, nil
 }
+
+// GetTokenKeyID returns the key ID of the key that signed the token, if the
+// token has one in its headers.
+func GetTokenKeyID(token *jwt.JSONWebToken) (string, bool) {
+	for _, h := range token.Headers {
+		if h.KeyID != "" {
+			return h.KeyID, true
+		}
+	}
+	return "", false
+}
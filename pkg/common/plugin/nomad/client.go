@@ -0,0 +1,154 @@
+package nomad
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spiffe/spire/pkg/common/util"
+)
+
+// ErrNotFound is returned when the Nomad API does not know the requested
+// object.
+var ErrNotFound = errors.New("not found")
+
+// Allocation holds the fields of the Nomad allocation used by the plugins.
+type Allocation struct {
+	ID           string `json:"ID"`
+	Name         string `json:"Name"`
+	Namespace    string `json:"Namespace"`
+	NodeID       string `json:"NodeID"`
+	JobID        string `json:"JobID"`
+	TaskGroup    string `json:"TaskGroup"`
+	ClientStatus string `json:"ClientStatus"`
+}
+
+// Node holds the fields of the Nomad client node used by the plugins.
+type Node struct {
+	ID         string            `json:"ID"`
+	Name       string            `json:"Name"`
+	Datacenter string            `json:"Datacenter"`
+	NodeClass  string            `json:"NodeClass"`
+	NodePool   string            `json:"NodePool"`
+	Meta       map[string]string `json:"Meta"`
+}
+
+// Client is a subset of the Nomad HTTP API, useful for mocking.
+type Client interface {
+	GetKeySet(ctx context.Context) (*jose.JSONWebKeySet, error)
+	GetAllocation(ctx context.Context, allocID string) (*Allocation, error)
+	GetNode(ctx context.Context, nodeID string) (*Node, error)
+}
+
+// ClientConfig configures the access to the Nomad HTTP API.
+type ClientConfig struct {
+	// Address of the Nomad HTTP API (e.g. "https://127.0.0.1:4646")
+	Address string
+	// Token is the ACL token used to authenticate with the API. Optional.
+	Token string
+	// CACertPath is the path to the CA certificates used to verify the API
+	// server certificate. Optional.
+	CACertPath string
+}
+
+type client struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// NewClient returns a client for the Nomad HTTP API.
+func NewClient(config ClientConfig) (Client, error) {
+	u, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", config.Address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid address %q: must be an http or https URL", config.Address)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CACertPath != "" {
+		pool, err := util.LoadCertPool(config.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load CA certificates: %w", err)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return &client{
+		address: strings.TrimSuffix(config.Address, "/"),
+		token:   config.Token,
+		client:  &http.Client{Transport: transport},
+	}, nil
+}
+
+func (c *client) GetKeySet(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	jwks := new(jose.JSONWebKeySet)
+	if err := c.get(ctx, "/.well-known/jwks.json", jwks); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+func (c *client) GetAllocation(ctx context.Context, allocID string) (*Allocation, error) {
+	alloc := new(Allocation)
+	// The wildcard namespace allows looking up the allocation without
+	// knowing its namespace beforehand.
+	if err := c.get(ctx, "/v1/allocation/"+url.PathEscape(allocID)+"?namespace=*", alloc); err != nil {
+		return nil, err
+	}
+	return alloc, nil
+}
+
+func (c *client) GetNode(ctx context.Context, nodeID string) (*Node, error) {
+	node := new(Node)
+	if err := c.get(ctx, "/v1/node/"+url.PathEscape(nodeID), node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (c *client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.address+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Nomad-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		// Nomad returns the reason of the failure as plain text
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+	return nil
+}
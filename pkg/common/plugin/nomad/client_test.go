@@ -0,0 +1,100 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		config    ClientConfig
+		expectErr string
+	}{
+		{
+			name:   "http address",
+			config: ClientConfig{Address: "http://127.0.0.1:4646"},
+		},
+		{
+			name:   "https address",
+			config: ClientConfig{Address: "https://nomad.example.org:4646/"},
+		},
+		{
+			name:      "unsupported scheme",
+			config:    ClientConfig{Address: "unix:///run/nomad.sock"},
+			expectErr: `invalid address "unix:///run/nomad.sock": must be an http or https URL`,
+		},
+		{
+			name:      "missing host",
+			config:    ClientConfig{Address: "http://"},
+			expectErr: `invalid address "http://": must be an http or https URL`,
+		},
+		{
+			name:      "missing CA certificates",
+			config:    ClientConfig{Address: "https://nomad.example.org:4646", CACertPath: "/does/not/exist"},
+			expectErr: "unable to load CA certificates",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.config)
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				require.Nil(t, client)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, client)
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	var gotToken string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"keys":[]}`))
+	})
+	mux.HandleFunc("GET /v1/allocation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("X-Nomad-Token")
+		require.Equal(t, "*", r.URL.Query().Get("namespace"))
+		if r.PathValue("id") != "alloc" {
+			http.Error(w, "alloc not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ID":"alloc","Namespace":"default","NodeID":"node","JobID":"job","TaskGroup":"group","ClientStatus":"running"}`))
+	})
+	mux.HandleFunc("GET /v1/node/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rpc error: Permission denied", http.StatusForbidden)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(ClientConfig{Address: server.URL, Token: "TOKEN"})
+	require.NoError(t, err)
+
+	jwks, err := client.GetKeySet(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, jwks.Keys)
+
+	alloc, err := client.GetAllocation(context.Background(), "alloc")
+	require.NoError(t, err)
+	require.Equal(t, &Allocation{
+		ID:           "alloc",
+		Namespace:    "default",
+		NodeID:       "node",
+		JobID:        "job",
+		TaskGroup:    "group",
+		ClientStatus: "running",
+	}, alloc)
+	require.Equal(t, "TOKEN", gotToken)
+
+	_, err = client.GetAllocation(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.GetNode(context.Background(), "node")
+	require.EqualError(t, err, "unexpected status 403: rpc error: Permission denied")
+}
@@ -0,0 +1,58 @@
+package nomad
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	PluginName = "nomad"
+)
+
+// AttestationData is the payload sent by the agent to attest with the server.
+type AttestationData struct {
+	// Cluster is the name of the Nomad cluster the agent runs in
+	Cluster string `json:"cluster"`
+	// Token is the workload identity JWT of the agent allocation
+	Token string `json:"token"`
+}
+
+// IdentityClaims represents the claims in a Nomad workload identity, for
+// example:
+//
+//	{
+//	  "aud": ["spire-server"],
+//	  "exp": 1719232302,
+//	  "iat": 1719228702,
+//	  "jti": "dc6a5a41-11c1-4b23-b0b3-8b64c1f9e1f2",
+//	  "nbf": 1719228702,
+//	  "nomad_allocation_id": "5b2d7ac1-4f6a-9c3e-63b9-02ba2e46b3e2",
+//	  "nomad_job_id": "spire-agent",
+//	  "nomad_namespace": "spire",
+//	  "nomad_task": "agent",
+//	  "sub": "global:spire:spire-agent:agent:agent:spire-server"
+//	}
+type IdentityClaims struct {
+	jwt.Claims
+	Namespace    string `json:"nomad_namespace"`
+	JobID        string `json:"nomad_job_id"`
+	AllocationID string `json:"nomad_allocation_id"`
+	Task         string `json:"nomad_task"`
+}
+
+func AgentID(pluginName, trustDomain, cluster, nodeID string) string {
+	u := url.URL{
+		Scheme: "spiffe",
+		Host:   trustDomain,
+		Path:   path.Join("spire", "agent", pluginName, cluster, nodeID),
+	}
+	return u.String()
+}
+
+func MakeSelectorValue(kind string, values ...string) string {
+	return fmt.Sprintf("%s:%s", kind, strings.Join(values, ":"))
+}
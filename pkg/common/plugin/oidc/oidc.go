@@ -0,0 +1,44 @@
+package oidc
+
+import (
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/agentpathtemplate"
+	"github.com/spiffe/spire/pkg/common/idutil"
+)
+
+const (
+	PluginName = "oidc"
+)
+
+// AttestationData is the payload sent by the agent to attest with the server.
+type AttestationData struct {
+	// Token is the OIDC ID token (or any JWT) issued to the agent
+	Token string `json:"token"`
+}
+
+// TemplateData is the data available to the agent path and selector
+// templates.
+type TemplateData struct {
+	// PluginName is the name of the plugin, i.e. "oidc"
+	PluginName string
+	// IssuerName is the name of the issuer in the plugin configuration
+	IssuerName string
+	// Issuer is the "iss" claim of the token
+	Issuer string
+	// Subject is the "sub" claim of the token
+	Subject string
+	// Claims holds all the claims of the token
+	Claims map[string]any
+}
+
+// MakeAgentID makes an agent SPIFFE ID. The ID always has a host value equal
+// to the given trust domain, the path is created using the given
+// agentPathTemplate which is given access to the claims of the token.
+func MakeAgentID(td spiffeid.TrustDomain, agentPathTemplate *agentpathtemplate.Template, data TemplateData) (spiffeid.ID, error) {
+	agentPath, err := agentPathTemplate.Execute(data)
+	if err != nil {
+		return spiffeid.ID{}, err
+	}
+
+	return idutil.AgentID(td, agentPath)
+}
@@ -0,0 +1,42 @@
+package pkcs11
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+)
+
+// GetOrCreateKeyIdentifier returns the key identifier persisted in the file
+// at the given path. If the file does not exist, a new random key identifier
+// is generated and persisted, so each instance gets its own identifier.
+func GetOrCreateKeyIdentifier(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return createKeyIdentifier(path)
+	case err != nil:
+		return "", fmt.Errorf("unable to read key identifier from path: %w", err)
+	}
+
+	keyIdentifier, err := uuid.FromString(string(data))
+	if err != nil {
+		return "", fmt.Errorf("unable to parse key identifier from path: %w", err)
+	}
+	return keyIdentifier.String(), nil
+}
+
+func createKeyIdentifier(path string) (string, error) {
+	u, err := uuid.NewV4()
+	if err != nil {
+		return "", fmt.Errorf("unable to generate key identifier: %w", err)
+	}
+	keyIdentifier := u.String()
+
+	if err := diskutil.WritePrivateFile(path, []byte(keyIdentifier)); err != nil {
+		return "", fmt.Errorf("unable to persist key identifier on path: %w", err)
+	}
+	return keyIdentifier, nil
+}
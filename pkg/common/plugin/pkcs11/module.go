@@ -0,0 +1,81 @@
+package pkcs11
+
+import "errors"
+
+// ErrSessionLost is matched by the errors of session operations that failed
+// because the session is no longer usable, e.g. because the token was removed
+// or the login state was lost. A new session has to be opened to recover.
+var ErrSessionLost = errors.New("session lost")
+
+// Module is a loaded PKCS#11 module. It exposes the subset of the PKCS#11 API
+// that is needed to manage keys on a token, so it can be replaced in tests.
+type Module interface {
+	// Slots returns the slots that have a token present.
+	Slots() ([]SlotInfo, error)
+
+	// OpenSession opens a read/write session on the token in the given slot
+	// and logs in as the normal user.
+	OpenSession(slotID uint, pin string) (Session, error)
+
+	// Close finalizes the module.
+	Close() error
+}
+
+// Session is a logged in read/write session on a token. Sessions are not safe
+// for concurrent use. Errors caused by the session no longer being usable
+// match ErrSessionLost.
+type Session interface {
+	// FindObjects returns the objects that match the given template.
+	FindObjects(template []Attribute) ([]ObjectHandle, error)
+
+	// GetAttributes returns the raw values of the given attribute types of an
+	// object, in the same order.
+	GetAttributes(object ObjectHandle, types []uint) ([][]byte, error)
+
+	// SetAttributes modifies the attributes of an object.
+	SetAttributes(object ObjectHandle, attributes []Attribute) error
+
+	// GenerateKeyPair generates a key pair, returning the handles of the
+	// public and private keys.
+	GenerateKeyPair(mechanism Mechanism, publicTemplate, privateTemplate []Attribute) (ObjectHandle, ObjectHandle, error)
+
+	// Sign signs data with the given private key.
+	Sign(mechanism Mechanism, key ObjectHandle, data []byte) ([]byte, error)
+
+	// DestroyObject destroys an object.
+	DestroyObject(object ObjectHandle) error
+
+	// Close closes the session.
+	Close() error
+}
+
+// SlotInfo describes a slot with a token present.
+type SlotInfo struct {
+	ID         uint
+	TokenLabel string
+}
+
+// ObjectHandle is the handle of an object on a token.
+type ObjectHandle uint
+
+// Attribute is an attribute of an object. The value is a bool, uint, string
+// or []byte, and is encoded as required by the attribute type.
+type Attribute struct {
+	Type  uint
+	Value any
+}
+
+// Mechanism is a PKCS#11 mechanism.
+type Mechanism struct {
+	Type uint
+
+	// PSSParams holds the parameters of the CKM_RSA_PKCS_PSS mechanism.
+	PSSParams *PSSParams
+}
+
+// PSSParams are the parameters of the CKM_RSA_PKCS_PSS mechanism.
+type PSSParams struct {
+	HashAlg    uint
+	MGF        uint
+	SaltLength uint
+}
@@ -0,0 +1,196 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"errors"
+	"fmt"
+
+	p11 "github.com/miekg/pkcs11"
+)
+
+// OpenModule loads and initializes the PKCS#11 module at the given path.
+func OpenModule(path string) (Module, error) {
+	ctx := p11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("unable to load PKCS#11 module %q", path)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("unable to initialize PKCS#11 module %q: %w", path, err)
+	}
+	return &module{ctx: ctx}, nil
+}
+
+type module struct {
+	ctx *p11.Ctx
+}
+
+func (m *module) Slots() ([]SlotInfo, error) {
+	slotIDs, err := m.ctx.GetSlotList(true)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make([]SlotInfo, 0, len(slotIDs))
+	for _, slotID := range slotIDs {
+		tokenInfo, err := m.ctx.GetTokenInfo(slotID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get token info of slot %d: %w", slotID, err)
+		}
+		slots = append(slots, SlotInfo{ID: slotID, TokenLabel: tokenInfo.Label})
+	}
+	return slots, nil
+}
+
+func (m *module) OpenSession(slotID uint, pin string) (Session, error) {
+	sh, err := m.ctx.OpenSession(slotID, p11.CKF_SERIAL_SESSION|p11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, err
+	}
+
+	// The login state is shared by all the sessions of the application.
+	if err := m.ctx.Login(sh, p11.CKU_USER, pin); err != nil && !errors.Is(err, p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN)) {
+		_ = m.ctx.CloseSession(sh)
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	return &session{ctx: m.ctx, sh: sh}, nil
+}
+
+func (m *module) Close() error {
+	err := m.ctx.Finalize()
+	m.ctx.Destroy()
+	return err
+}
+
+type session struct {
+	ctx *p11.Ctx
+	sh  p11.SessionHandle
+}
+
+func (s *session) FindObjects(template []Attribute) ([]ObjectHandle, error) {
+	if err := s.ctx.FindObjectsInit(s.sh, toP11Attributes(template)); err != nil {
+		return nil, sessionError(err)
+	}
+
+	var objects []ObjectHandle
+	for {
+		found, _, err := s.ctx.FindObjects(s.sh, 100)
+		if err != nil {
+			_ = s.ctx.FindObjectsFinal(s.sh)
+			return nil, sessionError(err)
+		}
+		if len(found) == 0 {
+			break
+		}
+		for _, object := range found {
+			objects = append(objects, ObjectHandle(object))
+		}
+	}
+
+	if err := s.ctx.FindObjectsFinal(s.sh); err != nil {
+		return nil, sessionError(err)
+	}
+	return objects, nil
+}
+
+func (s *session) GetAttributes(object ObjectHandle, types []uint) ([][]byte, error) {
+	template := make([]*p11.Attribute, 0, len(types))
+	for _, typ := range types {
+		template = append(template, p11.NewAttribute(typ, nil))
+	}
+
+	attributes, err := s.ctx.GetAttributeValue(s.sh, p11.ObjectHandle(object), template)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	values := make([][]byte, 0, len(attributes))
+	for _, attribute := range attributes {
+		values = append(values, attribute.Value)
+	}
+	return values, nil
+}
+
+func (s *session) SetAttributes(object ObjectHandle, attributes []Attribute) error {
+	return sessionError(s.ctx.SetAttributeValue(s.sh, p11.ObjectHandle(object), toP11Attributes(attributes)))
+}
+
+func (s *session) GenerateKeyPair(mechanism Mechanism, publicTemplate, privateTemplate []Attribute) (ObjectHandle, ObjectHandle, error) {
+	publicKey, privateKey, err := s.ctx.GenerateKeyPair(s.sh,
+		toP11Mechanisms(mechanism),
+		toP11Attributes(publicTemplate),
+		toP11Attributes(privateTemplate))
+	if err != nil {
+		return 0, 0, sessionError(err)
+	}
+	return ObjectHandle(publicKey), ObjectHandle(privateKey), nil
+}
+
+func (s *session) Sign(mechanism Mechanism, key ObjectHandle, data []byte) ([]byte, error) {
+	if err := s.ctx.SignInit(s.sh, toP11Mechanisms(mechanism), p11.ObjectHandle(key)); err != nil {
+		return nil, sessionError(err)
+	}
+	signature, err := s.ctx.Sign(s.sh, data)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	return signature, nil
+}
+
+func (s *session) DestroyObject(object ObjectHandle) error {
+	return sessionError(s.ctx.DestroyObject(s.sh, p11.ObjectHandle(object)))
+}
+
+func (s *session) Close() error {
+	return s.ctx.CloseSession(s.sh)
+}
+
+// sessionLostReturnValues are the return values of the operations that fail
+// because the session is no longer usable.
+var sessionLostReturnValues = map[p11.Error]bool{
+	p11.CKR_DEVICE_REMOVED:         true,
+	p11.CKR_SESSION_CLOSED:         true,
+	p11.CKR_SESSION_HANDLE_INVALID: true,
+	p11.CKR_TOKEN_NOT_PRESENT:      true,
+	p11.CKR_USER_NOT_LOGGED_IN:     true,
+}
+
+// sessionLostError is a return value that invalidates the session. It keeps
+// the message of the return value and matches ErrSessionLost.
+type sessionLostError struct {
+	rv p11.Error
+}
+
+func (e sessionLostError) Error() string {
+	return e.rv.Error()
+}
+
+func (e sessionLostError) Unwrap() []error {
+	return []error{e.rv, ErrSessionLost}
+}
+
+func sessionError(err error) error {
+	var rv p11.Error
+	if errors.As(err, &rv) && sessionLostReturnValues[rv] {
+		return sessionLostError{rv: rv}
+	}
+	return err
+}
+
+func toP11Attributes(attributes []Attribute) []*p11.Attribute {
+	p11Attributes := make([]*p11.Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		p11Attributes = append(p11Attributes, p11.NewAttribute(attribute.Type, attribute.Value))
+	}
+	return p11Attributes
+}
+
+func toP11Mechanisms(mechanism Mechanism) []*p11.Mechanism {
+	var params any
+	if mechanism.PSSParams != nil {
+		params = p11.NewPSSParams(mechanism.PSSParams.HashAlg, mechanism.PSSParams.MGF, mechanism.PSSParams.SaltLength)
+	}
+	return []*p11.Mechanism{p11.NewMechanism(mechanism.Type, params)}
+}
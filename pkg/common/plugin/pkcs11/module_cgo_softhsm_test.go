@@ -0,0 +1,137 @@
+//go:build cgo && softhsm
+
+package pkcs11_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/plugin/pkcs11"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests in this file exercise the cgo module against SoftHSM. They are
+// run with:
+//
+//	go test -tags softhsm ./pkg/common/plugin/pkcs11/
+//
+// The SOFTHSM2_MODULE environment variable overrides the path of the SoftHSM
+// module, and softhsm2-util must be in the PATH.
+const defaultSoftHSMModule = "/usr/lib/softhsm/libsofthsm2.so"
+
+func TestSoftHSM(t *testing.T) {
+	modulePath := initSoftHSM(t)
+	config := pkcs11.TokenConfig{
+		TokenLabel:    "spire",
+		Pin:           "1234",
+		KeyIdentifier: "spire",
+	}
+
+	module, err := pkcs11.OpenModule(modulePath)
+	require.NoError(t, err)
+	token, err := pkcs11.OpenToken(module, config)
+	require.NoError(t, err)
+
+	var keys []pkcs11.Key
+	for _, generate := range []struct {
+		id string
+		fn func() (crypto.Signer, error)
+	}{
+		{id: "ec256", fn: token.GenerateEC256Key},
+		{id: "ec384", fn: token.GenerateEC384Key},
+		{id: "rsa2048", fn: token.GenerateRSA2048Key},
+		{id: "rsa4096", fn: token.GenerateRSA4096Key},
+	} {
+		signer, err := generate.fn()
+		require.NoError(t, err, generate.id)
+		requireSignatures(t, signer)
+		keys = append(keys, pkcs11.Key{ID: generate.id, Signer: signer})
+	}
+	require.NoError(t, token.StoreKeys(keys))
+	require.NoError(t, token.Close())
+
+	// The keys are persisted on the token and can be used once loaded again
+	module, err = pkcs11.OpenModule(modulePath)
+	require.NoError(t, err)
+	token, err = pkcs11.OpenToken(module, config)
+	require.NoError(t, err)
+	defer token.Close()
+
+	loadedKeys, err := token.LoadKeys()
+	require.NoError(t, err)
+	require.Len(t, loadedKeys, len(keys))
+	for i, key := range loadedKeys {
+		require.Equal(t, keys[i].ID, key.ID)
+		require.Equal(t, keys[i].Signer.Public(), key.Signer.Public())
+		requireSignatures(t, key.Signer)
+	}
+}
+
+func TestSoftHSMSessionLost(t *testing.T) {
+	modulePath := initSoftHSM(t)
+
+	module, err := pkcs11.OpenModule(modulePath)
+	require.NoError(t, err)
+	defer module.Close()
+
+	slots, err := module.Slots()
+	require.NoError(t, err)
+	require.Len(t, slots, 1)
+
+	session, err := module.OpenSession(slots[0].ID, "1234")
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+
+	_, err = session.FindObjects(nil)
+	require.ErrorIs(t, err, pkcs11.ErrSessionLost)
+	require.EqualError(t, err, "pkcs11: 0xB3: CKR_SESSION_HANDLE_INVALID")
+}
+
+// initSoftHSM initializes a SoftHSM token labeled "spire" in a temporary
+// directory and returns the path of the SoftHSM module.
+func initSoftHSM(t *testing.T) string {
+	modulePath := os.Getenv("SOFTHSM2_MODULE")
+	if modulePath == "" {
+		modulePath = defaultSoftHSMModule
+	}
+
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "softhsm2.conf")
+	tokenDir := filepath.Join(dir, "tokens")
+	require.NoError(t, os.Mkdir(tokenDir, 0700))
+	require.NoError(t, os.WriteFile(confPath, fmt.Appendf(nil, "directories.tokendir = %s\n", tokenDir), 0600))
+	t.Setenv("SOFTHSM2_CONF", confPath)
+
+	out, err := exec.Command("softhsm2-util", "--init-token", "--free", "--label", "spire", "--pin", "1234", "--so-pin", "5678").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return modulePath
+}
+
+func requireSignatures(t *testing.T, signer crypto.Signer) {
+	digest := sha256.Sum256([]byte("DATA"))
+
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	switch publicKey := signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		require.True(t, ecdsa.VerifyASN1(publicKey, digest[:], signature))
+	case *rsa.PublicKey:
+		require.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature))
+
+		pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+		signature, err = signer.Sign(rand.Reader, digest[:], pssOpts)
+		require.NoError(t, err)
+		require.NoError(t, rsa.VerifyPSS(publicKey, crypto.SHA256, digest[:], signature, pssOpts))
+	default:
+		t.Fatalf("unexpected public key type %T", publicKey)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !cgo
+
+package pkcs11
+
+import "errors"
+
+// OpenModule loads and initializes the PKCS#11 module at the given path.
+func OpenModule(string) (Module, error) {
+	return nil, errors.New("PKCS#11 modules can only be loaded by binaries built with cgo")
+}
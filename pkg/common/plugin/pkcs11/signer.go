@@ -0,0 +1,126 @@
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	p11 "github.com/miekg/pkcs11"
+)
+
+var (
+	// digestInfoPrefixes are the DER encoded DigestInfo prefixes that the
+	// CKM_RSA_PKCS mechanism expects in front of the digest.
+	digestInfoPrefixes = map[crypto.Hash][]byte{
+		crypto.SHA224: {0x30, 0x2d, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x04, 0x05, 0x00, 0x04, 0x1c},
+		crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+		crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+		crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+	}
+
+	// pssHashes are the hash and MGF1 mechanisms of the CKM_RSA_PKCS_PSS
+	// mechanism for each hash.
+	pssHashes = map[crypto.Hash]struct{ hashAlg, mgf uint }{
+		crypto.SHA224: {p11.CKM_SHA224, p11.CKG_MGF1_SHA224},
+		crypto.SHA256: {p11.CKM_SHA256, p11.CKG_MGF1_SHA256},
+		crypto.SHA384: {p11.CKM_SHA384, p11.CKG_MGF1_SHA384},
+		crypto.SHA512: {p11.CKM_SHA512, p11.CKG_MGF1_SHA512},
+	}
+)
+
+// signer is a crypto.Signer backed by a private key stored on a token.
+type signer struct {
+	token    *Token
+	objectID []byte
+	public   crypto.PublicKey
+
+	// The handles of the key pair objects, the session generation they were
+	// found in and the current label of the key pair are guarded by the
+	// token mutex.
+	privateKey ObjectHandle
+	publicKey  ObjectHandle
+	generation uint64
+	label      string
+}
+
+func (s *signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	if len(digest) != hash.Size() {
+		return nil, fmt.Errorf("digest length %d does not match hash %s", len(digest), hash)
+	}
+
+	switch s.public.(type) {
+	case *ecdsa.PublicKey:
+		return s.signECDSA(digest)
+	case *rsa.PublicKey:
+		if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+			return s.signRSAPSS(digest, pssOpts)
+		}
+		return s.signRSAPKCS1v15(digest, hash)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", s.public)
+	}
+}
+
+func (s *signer) signECDSA(digest []byte) ([]byte, error) {
+	signature, err := s.token.sign(s, Mechanism{Type: p11.CKM_ECDSA}, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature is the concatenation of r and s, which is converted to
+	// the ASN.1 encoding used by the standard library.
+	if len(signature) == 0 || len(signature)%2 != 0 {
+		return nil, errors.New("malformed ECDSA signature")
+	}
+	half := len(signature) / 2
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(signature[:half]),
+		S: new(big.Int).SetBytes(signature[half:]),
+	})
+}
+
+func (s *signer) signRSAPKCS1v15(digest []byte, hash crypto.Hash) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash %s", hash)
+	}
+
+	data := make([]byte, 0, len(prefix)+len(digest))
+	data = append(data, prefix...)
+	data = append(data, digest...)
+	return s.token.sign(s, Mechanism{Type: p11.CKM_RSA_PKCS}, data)
+}
+
+func (s *signer) signRSAPSS(digest []byte, opts *rsa.PSSOptions) ([]byte, error) {
+	pssHash, ok := pssHashes[opts.Hash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash %s", opts.Hash)
+	}
+
+	saltLength := opts.SaltLength
+	if saltLength == rsa.PSSSaltLengthAuto || saltLength == rsa.PSSSaltLengthEqualsHash {
+		saltLength = opts.Hash.Size()
+	}
+	if saltLength < 0 {
+		return nil, fmt.Errorf("invalid PSS salt length %d", opts.SaltLength)
+	}
+
+	return s.token.sign(s, Mechanism{
+		Type: p11.CKM_RSA_PKCS_PSS,
+		PSSParams: &PSSParams{
+			HashAlg:    pssHash.hashAlg,
+			MGF:        pssHash.mgf,
+			SaltLength: uint(saltLength),
+		},
+	}, digest)
+}
@@ -0,0 +1,581 @@
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	p11 "github.com/miekg/pkcs11"
+)
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+
+	rsaPublicExponent = []byte{0x01, 0x00, 0x01}
+)
+
+// TokenConfig selects a token and the namespace of the keys on it.
+type TokenConfig struct {
+	// SlotID is the slot of the token. Either SlotID or TokenLabel must be
+	// set.
+	SlotID *uint
+
+	// TokenLabel is the label of the token.
+	TokenLabel string
+
+	// Pin is the user PIN of the token.
+	Pin string
+
+	// KeyIdentifier identifies the keys of a SPIRE instance on the token. It
+	// must be unique among the instances sharing the token, since the token
+	// takes ownership of every key pair labeled with it.
+	KeyIdentifier string
+}
+
+// Key is a key pair stored on the token.
+type Key struct {
+	ID     string
+	Signer crypto.Signer
+}
+
+// Token manages the keys of a key manager on a PKCS#11 token. Keys are
+// generated on the token as non-extractable key pairs. Each key pair is
+// labeled "<key identifier>/<key ID>", so the mapping between key manager key
+// IDs and token objects is persisted on the token itself.
+//
+// If the session is lost, e.g. because the token was removed and inserted
+// again, a new session is opened and the failed operation is retried.
+type Token struct {
+	module Module
+	config TokenConfig
+	prefix string
+
+	// mtx serializes the operations on the session.
+	mtx     sync.Mutex
+	session Session
+
+	// generation is incremented every time the session is reopened, since
+	// the object handles may not survive the session.
+	generation uint64
+}
+
+// OpenToken opens a session on the selected token of the module. The token
+// takes ownership of the module, which is closed with the token.
+func OpenToken(module Module, config TokenConfig) (*Token, error) {
+	slotID, err := selectSlot(module, config)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := module.OpenSession(slotID, config.Pin)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open session on slot %d: %w", slotID, err)
+	}
+
+	return &Token{
+		module:  module,
+		config:  config,
+		prefix:  config.KeyIdentifier + "/",
+		session: session,
+	}, nil
+}
+
+// Close closes the session and the module.
+func (t *Token) Close() error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var err error
+	if t.session != nil {
+		err = t.session.Close()
+		t.session = nil
+	}
+	return errors.Join(err, t.module.Close())
+}
+
+// LoadKeys returns the keys of the key manager stored on the token, sorted by
+// ID. If a key pair was replaced but not removed, the newest one is returned.
+// Key pairs that were generated but never stored, e.g. because the process
+// stopped in between, are destroyed. LoadKeys must therefore not be called
+// while keys are being generated.
+func (t *Token) LoadKeys() ([]Key, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var keys []Key
+	err := t.withSession(func() (err error) {
+		keys, err = t.loadKeys()
+		return err
+	})
+	return keys, err
+}
+
+func (t *Token) loadKeys() ([]Key, error) {
+	signers := make(map[string]*signer)
+	for _, keyType := range []uint{p11.CKK_EC, p11.CKK_RSA} {
+		privateKeys, err := t.session.FindObjects([]Attribute{
+			{Type: p11.CKA_TOKEN, Value: true},
+			{Type: p11.CKA_CLASS, Value: uint(p11.CKO_PRIVATE_KEY)},
+			{Type: p11.CKA_KEY_TYPE, Value: uint(keyType)},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to find private keys: %w", err)
+		}
+
+		for _, privateKey := range privateKeys {
+			values, err := t.session.GetAttributes(privateKey, []uint{p11.CKA_LABEL, p11.CKA_ID})
+			if err != nil {
+				return nil, fmt.Errorf("unable to get private key attributes: %w", err)
+			}
+			label, objectID := string(values[0]), values[1]
+
+			keyID, ok := strings.CutPrefix(label, t.prefix)
+			if !ok || keyID == "" {
+				continue
+			}
+			if current, ok := signers[keyID]; ok && bytes.Compare(current.objectID, objectID) > 0 {
+				continue
+			}
+
+			publicKey, err := t.findKey(p11.CKO_PUBLIC_KEY, objectID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load key %q: %w", keyID, err)
+			}
+			public, err := t.getPublicKey(keyType, publicKey)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load key %q: %w", keyID, err)
+			}
+
+			signers[keyID] = &signer{
+				token:      t,
+				privateKey: privateKey,
+				publicKey:  publicKey,
+				generation: t.generation,
+				objectID:   objectID,
+				label:      label,
+				public:     public,
+			}
+		}
+	}
+
+	if err := t.destroyUnreferenced("pending", t.prefix, nil); err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(signers))
+	for keyID, signer := range signers {
+		keys = append(keys, Key{ID: keyID, Signer: signer})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// StoreKeys persists the given set of keys on the token, which must have been
+// loaded or generated by the token. New keys are labeled with their key ID,
+// and the key pairs they replace are destroyed. If a new key cannot be
+// labeled, its key pair is destroyed, since the key is not going to be used.
+func (t *Token) StoreKeys(keys []Key) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	signers := make([]*signer, 0, len(keys))
+	for _, key := range keys {
+		s, ok := key.Signer.(*signer)
+		if !ok || s.token != t {
+			return fmt.Errorf("key %q is not stored on the token", key.ID)
+		}
+		signers = append(signers, s)
+	}
+
+	return t.withSession(func() error {
+		return t.storeKeys(keys, signers)
+	})
+}
+
+func (t *Token) storeKeys(keys []Key, signers []*signer) error {
+	referenced := make(map[string]bool, len(keys))
+	var labels []string
+	for i, key := range keys {
+		s := signers[i]
+		label := t.prefix + key.ID
+		if s.label != label {
+			if err := t.resolve(s); err != nil {
+				return fmt.Errorf("unable to label key %q: %w", key.ID, err)
+			}
+			if err := t.labelKeyPair(s, label); err != nil {
+				if s.label == t.prefix && !errors.Is(err, ErrSessionLost) {
+					t.destroyKeyPair(s)
+				}
+				return fmt.Errorf("unable to label key %q: %w", key.ID, err)
+			}
+		}
+
+		referenced[string(s.objectID)] = true
+		labels = append(labels, label)
+	}
+
+	for _, label := range labels {
+		if err := t.destroyUnreferenced("replaced", label, referenced); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Token) labelKeyPair(s *signer, label string) error {
+	for _, object := range []ObjectHandle{s.privateKey, s.publicKey} {
+		if err := t.session.SetAttributes(object, []Attribute{{Type: p11.CKA_LABEL, Value: label}}); err != nil {
+			return err
+		}
+	}
+	s.label = label
+	return nil
+}
+
+// destroyKeyPair destroys the key pair of a signer on a best effort basis.
+// Key pairs left pending on the token are destroyed once the keys are loaded
+// again.
+func (t *Token) destroyKeyPair(s *signer) {
+	for _, object := range []ObjectHandle{s.privateKey, s.publicKey} {
+		_ = t.session.DestroyObject(object)
+	}
+}
+
+// destroyUnreferenced destroys the objects with the given label whose CKA_ID
+// is not referenced.
+func (t *Token) destroyUnreferenced(kind, label string, referenced map[string]bool) error {
+	objects, err := t.session.FindObjects([]Attribute{
+		{Type: p11.CKA_TOKEN, Value: true},
+		{Type: p11.CKA_LABEL, Value: label},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to find objects labeled %q: %w", label, err)
+	}
+
+	for _, object := range objects {
+		values, err := t.session.GetAttributes(object, []uint{p11.CKA_ID})
+		if err != nil {
+			return fmt.Errorf("unable to get object attributes: %w", err)
+		}
+		if referenced[string(values[0])] {
+			continue
+		}
+		if err := t.session.DestroyObject(object); err != nil {
+			return fmt.Errorf("unable to destroy %s key pair labeled %q: %w", kind, label, err)
+		}
+	}
+	return nil
+}
+
+// GenerateRSA2048Key generates an RSA 2048 key pair on the token.
+func (t *Token) GenerateRSA2048Key() (crypto.Signer, error) {
+	return t.generateRSAKey(2048)
+}
+
+// GenerateRSA4096Key generates an RSA 4096 key pair on the token.
+func (t *Token) GenerateRSA4096Key() (crypto.Signer, error) {
+	return t.generateRSAKey(4096)
+}
+
+// GenerateEC256Key generates an EC P-256 key pair on the token.
+func (t *Token) GenerateEC256Key() (crypto.Signer, error) {
+	return t.generateECKey(oidNamedCurveP256)
+}
+
+// GenerateEC384Key generates an EC P-384 key pair on the token.
+func (t *Token) GenerateEC384Key() (crypto.Signer, error) {
+	return t.generateECKey(oidNamedCurveP384)
+}
+
+func (t *Token) generateRSAKey(bits uint) (crypto.Signer, error) {
+	return t.generateKeyPair(p11.CKK_RSA,
+		Mechanism{Type: p11.CKM_RSA_PKCS_KEY_PAIR_GEN},
+		[]Attribute{
+			{Type: p11.CKA_MODULUS_BITS, Value: bits},
+			{Type: p11.CKA_PUBLIC_EXPONENT, Value: rsaPublicExponent},
+		})
+}
+
+func (t *Token) generateECKey(curve asn1.ObjectIdentifier) (crypto.Signer, error) {
+	ecParams, err := asn1.Marshal(curve)
+	if err != nil {
+		return nil, err
+	}
+	return t.generateKeyPair(p11.CKK_EC,
+		Mechanism{Type: p11.CKM_EC_KEY_PAIR_GEN},
+		[]Attribute{
+			{Type: p11.CKA_EC_PARAMS, Value: ecParams},
+		})
+}
+
+func (t *Token) generateKeyPair(keyType uint, mechanism Mechanism, publicAttributes []Attribute) (crypto.Signer, error) {
+	objectID, err := newObjectID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Key pairs are labeled with their key ID once stored. Until then, they
+	// only carry the prefix.
+	common := []Attribute{
+		{Type: p11.CKA_TOKEN, Value: true},
+		{Type: p11.CKA_KEY_TYPE, Value: keyType},
+		{Type: p11.CKA_ID, Value: objectID},
+		{Type: p11.CKA_LABEL, Value: t.prefix},
+	}
+	publicTemplate := append([]Attribute{
+		{Type: p11.CKA_CLASS, Value: uint(p11.CKO_PUBLIC_KEY)},
+		{Type: p11.CKA_VERIFY, Value: true},
+	}, append(common, publicAttributes...)...)
+	privateTemplate := append([]Attribute{
+		{Type: p11.CKA_CLASS, Value: uint(p11.CKO_PRIVATE_KEY)},
+		{Type: p11.CKA_SIGN, Value: true},
+		{Type: p11.CKA_PRIVATE, Value: true},
+		{Type: p11.CKA_SENSITIVE, Value: true},
+		{Type: p11.CKA_EXTRACTABLE, Value: false},
+	}, common...)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var s *signer
+	err = t.withSession(func() error {
+		publicKey, privateKey, err := t.session.GenerateKeyPair(mechanism, publicTemplate, privateTemplate)
+		if err != nil {
+			return fmt.Errorf("unable to generate key pair: %w", err)
+		}
+
+		public, err := t.getPublicKey(keyType, publicKey)
+		if err != nil {
+			return err
+		}
+
+		s = &signer{
+			token:      t,
+			privateKey: privateKey,
+			publicKey:  publicKey,
+			generation: t.generation,
+			objectID:   objectID,
+			label:      t.prefix,
+			public:     public,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// withSession runs op on the session. If the session was lost, a new session
+// is opened and op is run again, so op must be safe to retry.
+func (t *Token) withSession(op func() error) error {
+	if t.session == nil {
+		if err := t.reopenSession(); err != nil {
+			return err
+		}
+	}
+
+	err := op()
+	if !errors.Is(err, ErrSessionLost) {
+		return err
+	}
+	if err := t.reopenSession(); err != nil {
+		return err
+	}
+	return op()
+}
+
+// reopenSession replaces the session with a new one. The slot is selected
+// again, since the token may be in another slot once inserted again.
+func (t *Token) reopenSession() error {
+	if t.session != nil {
+		_ = t.session.Close()
+		t.session = nil
+	}
+
+	slotID, err := selectSlot(t.module, t.config)
+	if err != nil {
+		return fmt.Errorf("unable to reopen session: %w", err)
+	}
+	session, err := t.module.OpenSession(slotID, t.config.Pin)
+	if err != nil {
+		return fmt.Errorf("unable to reopen session on slot %d: %w", slotID, err)
+	}
+
+	t.session = session
+	t.generation++
+	return nil
+}
+
+// resolve finds the objects of the key pair of a signer again if the session
+// was reopened since they were found.
+func (t *Token) resolve(s *signer) error {
+	if s.generation == t.generation {
+		return nil
+	}
+
+	privateKey, err := t.findKey(p11.CKO_PRIVATE_KEY, s.objectID)
+	if err != nil {
+		return err
+	}
+	publicKey, err := t.findKey(p11.CKO_PUBLIC_KEY, s.objectID)
+	if err != nil {
+		return err
+	}
+
+	s.privateKey, s.publicKey, s.generation = privateKey, publicKey, t.generation
+	return nil
+}
+
+func (t *Token) findKey(class uint, objectID []byte) (ObjectHandle, error) {
+	kind := "public"
+	if class == p11.CKO_PRIVATE_KEY {
+		kind = "private"
+	}
+
+	keys, err := t.session.FindObjects([]Attribute{
+		{Type: p11.CKA_TOKEN, Value: true},
+		{Type: p11.CKA_CLASS, Value: class},
+		{Type: p11.CKA_ID, Value: objectID},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to find %s key: %w", kind, err)
+	}
+	if len(keys) != 1 {
+		return 0, fmt.Errorf("expected one %s key with ID %x but found %d", kind, objectID, len(keys))
+	}
+	return keys[0], nil
+}
+
+func (t *Token) getPublicKey(keyType uint, publicKey ObjectHandle) (crypto.PublicKey, error) {
+	switch keyType {
+	case p11.CKK_EC:
+		values, err := t.session.GetAttributes(publicKey, []uint{p11.CKA_EC_PARAMS, p11.CKA_EC_POINT})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get public key attributes: %w", err)
+		}
+		return parseECPublicKey(values[0], values[1])
+	case p11.CKK_RSA:
+		values, err := t.session.GetAttributes(publicKey, []uint{p11.CKA_MODULUS, p11.CKA_PUBLIC_EXPONENT})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get public key attributes: %w", err)
+		}
+		return parseRSAPublicKey(values[0], values[1])
+	default:
+		return nil, fmt.Errorf("unsupported key type %d", keyType)
+	}
+}
+
+func (t *Token) sign(s *signer, mechanism Mechanism, data []byte) ([]byte, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var signature []byte
+	err := t.withSession(func() (err error) {
+		if err := t.resolve(s); err != nil {
+			return err
+		}
+		signature, err = t.session.Sign(mechanism, s.privateKey, data)
+		return err
+	})
+	return signature, err
+}
+
+func selectSlot(module Module, config TokenConfig) (uint, error) {
+	slots, err := module.Slots()
+	if err != nil {
+		return 0, fmt.Errorf("unable to list slots: %w", err)
+	}
+
+	if config.SlotID != nil {
+		for _, slot := range slots {
+			if slot.ID == *config.SlotID {
+				return slot.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("no token found in slot %d", *config.SlotID)
+	}
+
+	var found []SlotInfo
+	for _, slot := range slots {
+		if slot.TokenLabel == config.TokenLabel {
+			found = append(found, slot)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("no token found with label %q", config.TokenLabel)
+	case 1:
+		return found[0].ID, nil
+	default:
+		return 0, fmt.Errorf("multiple tokens found with label %q", config.TokenLabel)
+	}
+}
+
+// newObjectID returns a new value for the CKA_ID attribute of a key pair. The
+// creation time comes first, so newer key pairs have greater IDs.
+func newObjectID() ([]byte, error) {
+	objectID := make([]byte, 16)
+	binary.BigEndian.PutUint64(objectID, uint64(time.Now().UnixNano()))
+	if _, err := rand.Read(objectID[8:]); err != nil {
+		return nil, fmt.Errorf("unable to generate object ID: %w", err)
+	}
+	return objectID, nil
+}
+
+func parseECPublicKey(ecParams, ecPoint []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(ecParams, &oid); err != nil || len(rest) > 0 {
+		return nil, errors.New("malformed EC parameters")
+	}
+
+	var curve elliptic.Curve
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		curve = elliptic.P256()
+	case oid.Equal(oidNamedCurveP384):
+		curve = elliptic.P384()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %s", oid)
+	}
+
+	// The point is a DER encoded OCTET STRING, though some modules return the
+	// raw point instead.
+	var point []byte
+	if rest, err := asn1.Unmarshal(ecPoint, &point); err != nil || len(rest) > 0 {
+		point = ecPoint
+	}
+
+	publicKey, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+	if err != nil {
+		return nil, fmt.Errorf("malformed EC point: %w", err)
+	}
+	return publicKey, nil
+}
+
+func parseRSAPublicKey(modulus, publicExponent []byte) (*rsa.PublicKey, error) {
+	e := new(big.Int).SetBytes(publicExponent)
+	if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+		return nil, errors.New("unsupported RSA public exponent")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(e.Int64()),
+	}, nil
+}
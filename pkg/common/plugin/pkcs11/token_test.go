@@ -0,0 +1,337 @@
+package pkcs11_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/plugin/pkcs11"
+	"github.com/spiffe/spire/test/fakes/fakepkcs11"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenToken(t *testing.T) {
+	slot := func(id uint) *uint { return &id }
+
+	for _, tt := range []struct {
+		name      string
+		config    pkcs11.TokenConfig
+		expectErr string
+	}{
+		{
+			name:   "select by slot",
+			config: pkcs11.TokenConfig{SlotID: slot(2), Pin: "5678", KeyIdentifier: "spire"},
+		},
+		{
+			name:   "select by token label",
+			config: pkcs11.TokenConfig{TokenLabel: "second", Pin: "5678", KeyIdentifier: "spire"},
+		},
+		{
+			name:      "no token in slot",
+			config:    pkcs11.TokenConfig{SlotID: slot(4), Pin: "1234", KeyIdentifier: "spire"},
+			expectErr: "no token found in slot 4",
+		},
+		{
+			name:      "no token with label",
+			config:    pkcs11.TokenConfig{TokenLabel: "unknown", Pin: "1234", KeyIdentifier: "spire"},
+			expectErr: `no token found with label "unknown"`,
+		},
+		{
+			name:      "multiple tokens with label",
+			config:    pkcs11.TokenConfig{TokenLabel: "duplicated", Pin: "1234", KeyIdentifier: "spire"},
+			expectErr: `multiple tokens found with label "duplicated"`,
+		},
+		{
+			name:      "incorrect pin",
+			config:    pkcs11.TokenConfig{SlotID: slot(2), Pin: "1234", KeyIdentifier: "spire"},
+			expectErr: "unable to open session on slot 2: pkcs11: 0xA0: CKR_PIN_INCORRECT",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			module := fakepkcs11.New(
+				fakepkcs11.Token{SlotID: 1, Label: "first", Pin: "1234"},
+				fakepkcs11.Token{SlotID: 2, Label: "second", Pin: "5678"},
+				fakepkcs11.Token{SlotID: 3, Label: "duplicated", Pin: "1234"},
+				fakepkcs11.Token{SlotID: 5, Label: "duplicated", Pin: "1234"},
+			)
+
+			token, err := pkcs11.OpenToken(module, tt.config)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// Keys are generated on the selected token
+			_, err = token.GenerateEC256Key()
+			require.NoError(t, err)
+			require.Equal(t, []string{"spire/", "spire/"}, module.Labels(2))
+
+			require.NoError(t, token.Close())
+			require.True(t, module.Closed())
+		})
+	}
+}
+
+func TestGenerateAndSign(t *testing.T) {
+	token := openToken(t, fakepkcs11.New(testToken), "spire")
+
+	digest256 := sha256.Sum256([]byte("DATA"))
+	digest384 := sha512.Sum384([]byte("DATA"))
+
+	t.Run("EC P-256", func(t *testing.T) {
+		signer, err := token.GenerateEC256Key()
+		require.NoError(t, err)
+		publicKey, ok := signer.Public().(*ecdsa.PublicKey)
+		require.True(t, ok)
+		require.Equal(t, elliptic.P256(), publicKey.Curve)
+
+		signature, err := signer.Sign(rand.Reader, digest256[:], crypto.SHA256)
+		require.NoError(t, err)
+		require.True(t, ecdsa.VerifyASN1(publicKey, digest256[:], signature))
+	})
+
+	t.Run("EC P-384", func(t *testing.T) {
+		signer, err := token.GenerateEC384Key()
+		require.NoError(t, err)
+		publicKey, ok := signer.Public().(*ecdsa.PublicKey)
+		require.True(t, ok)
+		require.Equal(t, elliptic.P384(), publicKey.Curve)
+
+		signature, err := signer.Sign(rand.Reader, digest384[:], crypto.SHA384)
+		require.NoError(t, err)
+		require.True(t, ecdsa.VerifyASN1(publicKey, digest384[:], signature))
+	})
+
+	for _, tt := range []struct {
+		name     string
+		generate func() (crypto.Signer, error)
+		bits     int
+	}{
+		{name: "RSA 2048", generate: token.GenerateRSA2048Key, bits: 2048},
+		{name: "RSA 4096", generate: token.GenerateRSA4096Key, bits: 4096},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := tt.generate()
+			require.NoError(t, err)
+			publicKey, ok := signer.Public().(*rsa.PublicKey)
+			require.True(t, ok)
+			require.Equal(t, tt.bits, publicKey.N.BitLen())
+			require.Equal(t, 65537, publicKey.E)
+
+			signature, err := signer.Sign(rand.Reader, digest256[:], crypto.SHA256)
+			require.NoError(t, err)
+			require.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest256[:], signature))
+
+			signature, err = signer.Sign(rand.Reader, digest384[:], crypto.SHA384)
+			require.NoError(t, err)
+			require.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA384, digest384[:], signature))
+
+			pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+			signature, err = signer.Sign(rand.Reader, digest256[:], pssOpts)
+			require.NoError(t, err)
+			require.NoError(t, rsa.VerifyPSS(publicKey, crypto.SHA256, digest256[:], signature, pssOpts))
+
+			pssOpts = &rsa.PSSOptions{SaltLength: 10, Hash: crypto.SHA384}
+			signature, err = signer.Sign(rand.Reader, digest384[:], pssOpts)
+			require.NoError(t, err)
+			require.NoError(t, rsa.VerifyPSS(publicKey, crypto.SHA384, digest384[:], signature, pssOpts))
+		})
+	}
+
+	t.Run("digest does not match hash", func(t *testing.T) {
+		signer, err := token.GenerateEC256Key()
+		require.NoError(t, err)
+
+		_, err = signer.Sign(rand.Reader, digest256[:], crypto.SHA384)
+		require.EqualError(t, err, "digest length 32 does not match hash SHA-384")
+	})
+
+	t.Run("unsupported hash", func(t *testing.T) {
+		signer, err := token.GenerateRSA2048Key()
+		require.NoError(t, err)
+
+		digest := make([]byte, crypto.SHA1.Size())
+		_, err = signer.Sign(rand.Reader, digest, crypto.SHA1)
+		require.EqualError(t, err, "unsupported hash SHA-1")
+	})
+
+	t.Run("signing fails", func(t *testing.T) {
+		module := fakepkcs11.New(testToken)
+		token := openToken(t, module, "spire")
+
+		signer, err := token.GenerateEC256Key()
+		require.NoError(t, err)
+
+		module.SetError("Sign", errors.New("oh no"))
+		_, err = signer.Sign(rand.Reader, digest256[:], crypto.SHA256)
+		require.EqualError(t, err, "oh no")
+	})
+}
+
+func TestStoreAndLoadKeys(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	token := openToken(t, module, "spire")
+
+	// Keys of another SPIRE instance are kept as is
+	otherToken := openToken(t, module, "other")
+	otherKey := generateKey(t, otherToken)
+	require.NoError(t, otherToken.StoreKeys([]pkcs11.Key{{ID: "a", Signer: otherKey}}))
+
+	keyA := generateKey(t, token)
+	keyB := generateKey(t, token)
+	require.Equal(t, []string{"other/a", "other/a", "spire/", "spire/", "spire/", "spire/"}, module.Labels(testToken.SlotID))
+
+	// Storing the keys labels them with their ID
+	require.NoError(t, token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: keyA}, {ID: "b", Signer: keyB}}))
+	require.Equal(t, []string{"other/a", "other/a", "spire/a", "spire/a", "spire/b", "spire/b"}, module.Labels(testToken.SlotID))
+	requireKeys(t, module, "spire", map[string]crypto.Signer{"a": keyA, "b": keyB})
+
+	// Storing a new key for an existing ID destroys the replaced key pair
+	newKeyA := generateKey(t, token)
+	require.NoError(t, token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: newKeyA}, {ID: "b", Signer: keyB}}))
+	require.Equal(t, []string{"other/a", "other/a", "spire/a", "spire/a", "spire/b", "spire/b"}, module.Labels(testToken.SlotID))
+	requireKeys(t, module, "spire", map[string]crypto.Signer{"a": newKeyA, "b": keyB})
+	requireKeys(t, module, "other", map[string]crypto.Signer{"a": otherKey})
+
+	// Key pairs that were never stored are destroyed when the keys are loaded
+	generateKey(t, token)
+	require.Equal(t, []string{"other/a", "other/a", "spire/", "spire/", "spire/a", "spire/a", "spire/b", "spire/b"}, module.Labels(testToken.SlotID))
+
+	// Loaded keys can be stored again
+	loadedToken := openToken(t, module, "spire")
+	loadedKeys, err := loadedToken.LoadKeys()
+	require.NoError(t, err)
+	require.NoError(t, loadedToken.StoreKeys(loadedKeys))
+	require.Equal(t, []string{"other/a", "other/a", "spire/a", "spire/a", "spire/b", "spire/b"}, module.Labels(testToken.SlotID))
+
+	// Keys generated by another token cannot be stored
+	err = token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: loadedKeys[0].Signer}})
+	require.EqualError(t, err, `key "a" is not stored on the token`)
+	err = token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: testkey.NewEC256(t)}})
+	require.EqualError(t, err, `key "a" is not stored on the token`)
+}
+
+func TestStoreKeysFailure(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	token := openToken(t, module, "spire")
+
+	keyA := generateKey(t, token)
+	require.NoError(t, token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: keyA}}))
+
+	t.Run("labeling fails", func(t *testing.T) {
+		module.SetError("SetAttributes", errors.New("oh no"))
+		defer module.SetError("SetAttributes", nil)
+
+		// The key pair of the new key is destroyed rather than left pending
+		err := token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: generateKey(t, token)}})
+		require.EqualError(t, err, `unable to label key "a": oh no`)
+		require.Equal(t, []string{"spire/a", "spire/a"}, module.Labels(testToken.SlotID))
+		requireKeys(t, module, "spire", map[string]crypto.Signer{"a": keyA})
+	})
+
+	t.Run("destroying the replaced key pair fails", func(t *testing.T) {
+		module.SetError("DestroyObject", errors.New("oh no"))
+		defer module.SetError("DestroyObject", nil)
+
+		// The newest key pair is loaded when the replaced one is left behind
+		newKeyA := generateKey(t, token)
+		err := token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: newKeyA}})
+		require.EqualError(t, err, `unable to destroy replaced key pair labeled "spire/a": oh no`)
+		requireKeys(t, module, "spire", map[string]crypto.Signer{"a": newKeyA})
+	})
+}
+
+func TestLoadKeysFailure(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	token := openToken(t, module, "spire")
+	require.NoError(t, token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: generateKey(t, token)}}))
+
+	module.SetError("FindObjects", errors.New("oh no"))
+	_, err := token.LoadKeys()
+	require.EqualError(t, err, "unable to find private keys: oh no")
+}
+
+func TestSessionRecovery(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	token := openToken(t, module, "spire")
+
+	keyA := generateKey(t, token)
+	require.NoError(t, token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: keyA}}))
+
+	digest := sha256.Sum256([]byte("DATA"))
+	requireSign := func(signer crypto.Signer) {
+		signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+		require.NoError(t, err)
+		require.True(t, ecdsa.VerifyASN1(signer.Public().(*ecdsa.PublicKey), digest[:], signature))
+	}
+
+	// The session is reopened and the objects are found again
+	module.ReinsertTokens()
+	requireSign(keyA)
+
+	module.ReinsertTokens()
+	keyB := generateKey(t, token)
+	module.ReinsertTokens()
+	require.NoError(t, token.StoreKeys([]pkcs11.Key{{ID: "a", Signer: keyA}, {ID: "b", Signer: keyB}}))
+	requireSign(keyB)
+
+	module.ReinsertTokens()
+	keys, err := token.LoadKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	requireKeys(t, module, "spire", map[string]crypto.Signer{"a": keyA, "b": keyB})
+
+	// The session is reopened by the next operation if reopening it fails
+	module.ReinsertTokens()
+	module.SetError("OpenSession", errors.New("oh no"))
+	_, err = keyA.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.EqualError(t, err, "unable to reopen session on slot 1: oh no")
+	module.SetError("OpenSession", nil)
+	requireSign(keyA)
+
+	require.NoError(t, token.Close())
+	require.True(t, module.Closed())
+}
+
+var testToken = fakepkcs11.Token{SlotID: 1, Label: "spire", Pin: "1234"}
+
+func openToken(t *testing.T, module pkcs11.Module, keyIdentifier string) *pkcs11.Token {
+	token, err := pkcs11.OpenToken(module, pkcs11.TokenConfig{
+		TokenLabel:    testToken.Label,
+		Pin:           testToken.Pin,
+		KeyIdentifier: keyIdentifier,
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func generateKey(t *testing.T, token *pkcs11.Token) crypto.Signer {
+	signer, err := token.GenerateEC256Key()
+	require.NoError(t, err)
+	return signer
+}
+
+func requireKeys(t *testing.T, module pkcs11.Module, keyIdentifier string, expected map[string]crypto.Signer) {
+	token := openToken(t, module, keyIdentifier)
+	keys, err := token.LoadKeys()
+	require.NoError(t, err)
+
+	actual := make(map[string]crypto.PublicKey)
+	for _, key := range keys {
+		actual[key.ID] = key.Signer.Public()
+	}
+	expectedPublic := make(map[string]crypto.PublicKey)
+	for id, signer := range expected {
+		expectedPublic[id] = signer.Public()
+	}
+	assert.Equal(t, expectedPublic, actual)
+}
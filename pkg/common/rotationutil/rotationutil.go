@@ -60,6 +60,24 @@ func JWTSVIDExpired(svid *client.JWTSVID, now time.Time) bool {
 	return !now.Before(svid.ExpiresAt)
 }
 
+// WITSVIDExpiresSoon determines if the given WIT SVID should be rotated
+// based on presented current time, the WIT's expiration.
+// Also returns true if the WIT is already expired.
+func (rs *RotationStrategy) WITSVIDExpiresSoon(svid *client.WITSVID, now time.Time) bool {
+	if WITSVIDExpired(svid, now) {
+		return true
+	}
+
+	// if the SVID has less than half of its lifetime left, consider it as
+	// expiring soon
+	return shouldRotateJWT(now, svid.IssuedAt, svid.ExpiresAt)
+}
+
+// WITSVIDExpired returns true if the given SVID is expired.
+func WITSVIDExpired(svid *client.WITSVID, now time.Time) bool {
+	return !now.Before(svid.ExpiresAt)
+}
+
 func shouldRotateX509(now, beginTime, expiryTime time.Time, availabilityTarget time.Duration) bool {
 	ttl := expiryTime.Sub(now)
 	// return true quickly if the expiry is already met.
@@ -71,6 +71,16 @@ func AddCacheManagerTaintedJWTSVIDsSample(m telemetry.Metrics, cacheType string,
 	m.AddSample(key, count)
 }
 
+// AddCacheManagerTaintedWITSVIDsSample count of tainted WIT-SVIDs according to
+// agent cache manager
+func AddCacheManagerTaintedWITSVIDsSample(m telemetry.Metrics, cacheType string, count float32) {
+	key := []string{telemetry.CacheManager, telemetry.TaintedWITSVIDs}
+	if cacheType != "" {
+		key = append(key, cacheType)
+	}
+	m.AddSample(key, count)
+}
+
 // End Add Samples
 
 func SetSyncStats(m telemetry.Metrics, stats client.SyncStats) {
@@ -27,6 +27,9 @@ type FileConfig struct {
 	Statsd     []StatsdConfig    `hcl:"Statsd"`
 	M3         []M3Config        `hcl:"M3"`
 	InMem      *InMem            `hcl:"InMem"`
+	OTLP       *OTLPConfig       `hcl:"OTLP"`
+
+	Tracing *TracingConfig `hcl:"Tracing"`
 
 	MetricPrefix           string   `hcl:"MetricPrefix"`
 	EnableTrustDomainLabel *bool    `hcl:"EnableTrustDomainLabel"`
@@ -70,6 +73,36 @@ type M3Config struct {
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
 
+type OTLPConfig struct {
+	Endpoint           string                 `hcl:"endpoint"`
+	Protocol           string                 `hcl:"protocol"` // "grpc" (default) or "http"
+	URLPath            string                 `hcl:"url_path"` // optional, http only
+	Headers            map[string]string      `hcl:"headers"`
+	Insecure           bool                   `hcl:"insecure"`
+	ExportInterval     string                 `hcl:"export_interval"`
+	TLS                *OTLPTLSConfig         `hcl:"tls"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type OTLPTLSConfig struct {
+	CAFile              string   `hcl:"ca_file"`   // optional
+	CertFile            string   `hcl:"cert_file"` // optional
+	KeyFile             string   `hcl:"key_file"`  // optional
+	UseSPIRESVID        bool     `hcl:"use_spire_svid"`
+	AuthorizedSPIFFEIDs []string `hcl:"authorized_spiffe_ids"`
+}
+
+type TracingConfig struct {
+	Endpoint           string                 `hcl:"endpoint"`
+	Protocol           string                 `hcl:"protocol"` // "grpc" (default) or "http"
+	URLPath            string                 `hcl:"url_path"` // optional, http only
+	Headers            map[string]string      `hcl:"headers"`
+	Insecure           bool                   `hcl:"insecure"`
+	SampleRatio        *float64               `hcl:"sample_ratio"` // defaults to 1
+	TLS                *OTLPTLSConfig         `hcl:"tls"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
 type InMem struct {
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
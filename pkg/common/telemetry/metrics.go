@@ -3,13 +3,21 @@ package telemetry
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spiffe/spire/pkg/common/util"
 )
 
-const timerGranularity = time.Millisecond
+const (
+	timerGranularity = time.Millisecond
+
+	// runtimeMetricsInterval is how often the runtime metrics are emitted
+	runtimeMetricsInterval = time.Second
+)
 
 // Label is a label/tag for a metric
 type Label = metrics.Label
@@ -44,14 +52,27 @@ type Metrics interface {
 type MetricsImpl struct {
 	*metrics.Metrics
 
-	c       *MetricsConfig
-	runners []sinkRunner
+	c        *MetricsConfig
+	sinks    atomic.Pointer[metricsSinks]
+	reloadCh chan reloadRequest
+}
+
+var _ Metrics = (*MetricsImpl)(nil)
+
+// metricsSinks holds the sinks built from a telemetry file configuration.
+type metricsSinks struct {
+	fileConfig FileConfig
+	runners    []sinkRunner
 	// Each instance of metrics.Metrics in the slice corresponds to one metrics sink type
 	metricsSinks           []*metrics.Metrics
+	closers                []*closableSink
 	enableTrustDomainLabel bool
 }
 
-var _ Metrics = (*MetricsImpl)(nil)
+type reloadRequest struct {
+	fileConfig FileConfig
+	errCh      chan error
+}
 
 // NewMetrics returns a Metric implementation
 func NewMetrics(c *MetricsConfig) (*MetricsImpl, error) {
@@ -59,11 +80,32 @@ func NewMetrics(c *MetricsConfig) (*MetricsImpl, error) {
 		return nil, errors.New("logger must be configured")
 	}
 
-	impl := &MetricsImpl{c: c}
+	sinks, err := newMetricsSinks(c, c.FileConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	impl := &MetricsImpl{
+		c:        c,
+		reloadCh: make(chan reloadRequest),
+	}
+	impl.sinks.Store(sinks)
+	return impl, nil
+}
+
+func newMetricsSinks(c *MetricsConfig, fileConfig FileConfig) (*metricsSinks, error) {
+	// The runners are built from the given file configuration, which may be
+	// a reloaded one.
+	cc := *c
+	cc.FileConfig = fileConfig
+	c = &cc
+
+	impl := &metricsSinks{fileConfig: fileConfig}
 
 	for _, f := range sinkRunnerFactories {
 		runner, err := f(c)
 		if err != nil {
+			impl.close()
 			return nil, err
 		}
 
@@ -71,8 +113,8 @@ func NewMetrics(c *MetricsConfig) (*MetricsImpl, error) {
 			continue
 		}
 
-		fanout := metrics.FanoutSink{}
-		fanout = append(fanout, runner.sinks()...)
+		fanout := &closableSink{}
+		fanout.sinks = append(fanout.sinks, runner.sinks()...)
 
 		metricsPrefix := c.ServiceName
 		if c.FileConfig.MetricPrefix != "" {
@@ -87,6 +129,11 @@ func NewMetrics(c *MetricsConfig) (*MetricsImpl, error) {
 			conf.EnableHostnameLabel = true
 		}
 
+		// go-metrics starts a goroutine that emits the runtime metrics for
+		// each instance and never stops it, which would leak one on every
+		// reload. They are emitted by ListenAndServe to the current sinks
+		// instead.
+		conf.EnableRuntimeMetrics = false
 		conf.EnableTypePrefix = runner.requiresTypePrefix()
 		conf.AllowedLabels = c.FileConfig.AllowedLabels
 		conf.BlockedLabels = c.FileConfig.BlockedLabels
@@ -98,40 +145,247 @@ func NewMetrics(c *MetricsConfig) (*MetricsImpl, error) {
 			impl.enableTrustDomainLabel = *c.FileConfig.EnableTrustDomainLabel
 		}
 
+		// The runner is tracked before creating the go-metrics instance so
+		// that its sinks are released if anything fails from here on.
+		impl.runners = append(impl.runners, runner)
+
 		metricsSink, err := metrics.New(conf, fanout)
 		if err != nil {
+			impl.close()
 			return nil, err
 		}
 
 		impl.metricsSinks = append(impl.metricsSinks, metricsSink)
-		impl.runners = append(impl.runners, runner)
+		impl.closers = append(impl.closers, fanout)
+	}
+
+	// Tracing shares the lifecycle of the metrics sinks but does not emit
+	// metrics, so it is run without a go-metrics instance.
+	tracing, err := newTracingRunner(c)
+	if err != nil {
+		impl.close()
+		return nil, err
+	}
+	if tracing.isConfigured() {
+		impl.runners = append(impl.runners, tracing)
 	}
 
 	return impl, nil
 }
 
-// ListenAndServe starts the metrics process
-func (m *MetricsImpl) ListenAndServe(ctx context.Context) error {
+func (s *metricsSinks) run(ctx context.Context) error {
 	var tasks []func(context.Context) error
-	for _, runner := range m.runners {
+	for _, runner := range s.runners {
 		tasks = append(tasks, runner.run)
 	}
 
 	return util.RunTasks(ctx, tasks...)
 }
 
+// close releases the resources held by the sinks that outlive their runner,
+// so the sinks can be built again from a reloaded configuration.
+func (s *metricsSinks) close() {
+	for _, closer := range s.closers {
+		closer.Shutdown()
+	}
+	for _, runner := range s.runners {
+		for _, sink := range runner.sinks() {
+			if collector, ok := sink.(prometheus.Collector); ok {
+				prometheus.Unregister(collector)
+			}
+		}
+	}
+}
+
+// ListenAndServe starts the metrics process
+func (m *MetricsImpl) ListenAndServe(ctx context.Context) error {
+	runtimeCtx, stopRuntimeMetrics := context.WithCancel(ctx)
+	runtimeDone := make(chan struct{})
+	go func() {
+		defer close(runtimeDone)
+		m.emitRuntimeMetrics(runtimeCtx)
+	}()
+	defer func() {
+		stopRuntimeMetrics()
+		<-runtimeDone
+	}()
+
+	for {
+		sinks := m.sinks.Load()
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- sinks.run(runCtx)
+		}()
+
+		var req reloadRequest
+	wait:
+		for {
+			select {
+			case err := <-done:
+				if err != nil {
+					cancel()
+					return err
+				}
+				// Some runners have nothing to do once started; keep
+				// waiting for reloads until the context is canceled.
+				done = nil
+			case <-ctx.Done():
+				cancel()
+				if done != nil {
+					<-done
+				}
+				return ctx.Err()
+			case req = <-m.reloadCh:
+				break wait
+			}
+		}
+
+		sinks.close()
+		cancel()
+		if done != nil {
+			<-done
+		}
+		req.errCh <- m.replaceSinks(sinks, req.fileConfig)
+	}
+}
+
+// emitRuntimeMetrics periodically emits the runtime metrics to the current
+// sinks until the context is canceled.
+func (m *MetricsImpl) emitRuntimeMetrics(ctx context.Context) {
+	ticker := time.NewTicker(runtimeMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, s := range m.sinks.Load().metricsSinks {
+				s.EmitRuntimeStats()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reload replaces the telemetry sinks with the ones built from the given
+// file configuration. The current sinks are stopped first, since some of
+// them (e.g. Prometheus) hold resources the new sinks may need. If the new
+// sinks cannot be built, the previous ones are restored and an error is
+// returned. Reload only works while ListenAndServe is running.
+func (m *MetricsImpl) Reload(ctx context.Context, fileConfig FileConfig) error {
+	req := reloadRequest{
+		fileConfig: fileConfig,
+		errCh:      make(chan error, 1),
+	}
+
+	select {
+	case m.reloadCh <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *MetricsImpl) replaceSinks(previous *metricsSinks, fileConfig FileConfig) error {
+	sinks, err := newMetricsSinks(m.c, fileConfig)
+	if err == nil {
+		m.sinks.Store(sinks)
+		return nil
+	}
+
+	restored, restoreErr := newMetricsSinks(m.c, previous.fileConfig)
+	if restoreErr != nil {
+		m.c.Logger.WithError(restoreErr).Error("Failed to restore the previous telemetry sinks; telemetry is disabled until it is reloaded")
+		restored = &metricsSinks{}
+	}
+	m.sinks.Store(restored)
+	return err
+}
+
+// closableSink forwards metrics to the wrapped sinks until it is shut down.
+// Callers may still hold the go-metrics instances of replaced sinks while
+// they are reloaded, so the wrapped sinks can only be shut down safely once
+// nothing is forwarded to them anymore.
+type closableSink struct {
+	mtx    sync.RWMutex
+	closed bool
+	sinks  metrics.FanoutSink
+}
+
+func (s *closableSink) Shutdown() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.sinks.Shutdown()
+	}
+}
+
+func (s *closableSink) forward(fn func(metrics.FanoutSink)) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if !s.closed {
+		fn(s.sinks)
+	}
+}
+
+func (s *closableSink) SetGauge(key []string, val float32) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.SetGauge(key, val) })
+}
+
+func (s *closableSink) SetGaugeWithLabels(key []string, val float32, labels []Label) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.SetGaugeWithLabels(key, val, labels) })
+}
+
+func (s *closableSink) SetPrecisionGauge(key []string, val float64) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.SetPrecisionGauge(key, val) })
+}
+
+func (s *closableSink) SetPrecisionGaugeWithLabels(key []string, val float64, labels []Label) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.SetPrecisionGaugeWithLabels(key, val, labels) })
+}
+
+func (s *closableSink) EmitKey(key []string, val float32) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.EmitKey(key, val) })
+}
+
+func (s *closableSink) IncrCounter(key []string, val float32) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.IncrCounter(key, val) })
+}
+
+func (s *closableSink) IncrCounterWithLabels(key []string, val float32, labels []Label) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.IncrCounterWithLabels(key, val, labels) })
+}
+
+func (s *closableSink) AddSample(key []string, val float32) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.AddSample(key, val) })
+}
+
+func (s *closableSink) AddSampleWithLabels(key []string, val float32, labels []Label) {
+	s.forward(func(sinks metrics.FanoutSink) { sinks.AddSampleWithLabels(key, val, labels) })
+}
+
 func (m *MetricsImpl) SetGauge(key []string, val float32) {
 	m.SetGaugeWithLabels(key, val, nil)
 }
 
 // SetGaugeWithLabels delegates to embedded metrics, sanitizing labels
 func (m *MetricsImpl) SetGaugeWithLabels(key []string, val float32, labels []Label) {
-	if m.enableTrustDomainLabel {
+	sinks := m.sinks.Load()
+	if sinks.enableTrustDomainLabel {
 		labels = append(labels, Label{Name: TrustDomain, Value: m.c.TrustDomain})
 	}
 
 	sanitizedLabels := SanitizeLabels(labels)
-	for _, s := range m.metricsSinks {
+	for _, s := range sinks.metricsSinks {
 		s.SetGaugeWithLabels(key, val, sanitizedLabels)
 	}
 }
@@ -142,18 +396,19 @@ func (m *MetricsImpl) SetPrecisionGauge(key []string, val float64) {
 
 // SetPrecisionGaugeWithLabels delegates to embedded metrics, sanitizing labels
 func (m *MetricsImpl) SetPrecisionGaugeWithLabels(key []string, val float64, labels []Label) {
-	if m.enableTrustDomainLabel {
+	sinks := m.sinks.Load()
+	if sinks.enableTrustDomainLabel {
 		labels = append(labels, Label{Name: TrustDomain, Value: m.c.TrustDomain})
 	}
 
 	sanitizedLabels := SanitizeLabels(labels)
-	for _, s := range m.metricsSinks {
+	for _, s := range sinks.metricsSinks {
 		s.SetPrecisionGaugeWithLabels(key, val, sanitizedLabels)
 	}
 }
 
 func (m *MetricsImpl) EmitKey(key []string, val float32) {
-	for _, s := range m.metricsSinks {
+	for _, s := range m.sinks.Load().metricsSinks {
 		s.EmitKey(key, val)
 	}
 }
@@ -164,12 +419,13 @@ func (m *MetricsImpl) IncrCounter(key []string, val float32) {
 
 // IncrCounterWithLabels delegates to embedded metrics, sanitizing labels
 func (m *MetricsImpl) IncrCounterWithLabels(key []string, val float32, labels []Label) {
-	if m.enableTrustDomainLabel {
+	sinks := m.sinks.Load()
+	if sinks.enableTrustDomainLabel {
 		labels = append(labels, Label{Name: TrustDomain, Value: m.c.TrustDomain})
 	}
 
 	sanitizedLabels := SanitizeLabels(labels)
-	for _, s := range m.metricsSinks {
+	for _, s := range sinks.metricsSinks {
 		s.IncrCounterWithLabels(key, val, sanitizedLabels)
 	}
 }
@@ -180,12 +436,13 @@ func (m *MetricsImpl) AddSample(key []string, val float32) {
 
 // AddSampleWithLabels delegates to embedded metrics, sanitizing labels
 func (m *MetricsImpl) AddSampleWithLabels(key []string, val float32, labels []Label) {
-	if m.enableTrustDomainLabel {
+	sinks := m.sinks.Load()
+	if sinks.enableTrustDomainLabel {
 		labels = append(labels, Label{Name: TrustDomain, Value: m.c.TrustDomain})
 	}
 
 	sanitizedLabels := SanitizeLabels(labels)
-	for _, s := range m.metricsSinks {
+	for _, s := range sinks.metricsSinks {
 		s.AddSampleWithLabels(key, val, sanitizedLabels)
 	}
 }
@@ -196,12 +453,13 @@ func (m *MetricsImpl) MeasureSince(key []string, start time.Time) {
 
 // MeasureSinceWithLabels delegates to embedded metrics, sanitizing labels
 func (m *MetricsImpl) MeasureSinceWithLabels(key []string, start time.Time, labels []Label) {
-	if m.enableTrustDomainLabel {
+	sinks := m.sinks.Load()
+	if sinks.enableTrustDomainLabel {
 		labels = append(labels, Label{Name: TrustDomain, Value: m.c.TrustDomain})
 	}
 
 	sanitizedLabels := SanitizeLabels(labels)
-	for _, s := range m.metricsSinks {
+	for _, s := range sinks.metricsSinks {
 		s.MeasureSinceWithLabels(key, start, sanitizedLabels)
 	}
 }
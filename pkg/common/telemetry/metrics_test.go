@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-metrics"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	m, err := NewMetrics(&MetricsConfig{
+		Logger:      log,
+		ServiceName: "foo",
+	})
+	require.NoError(t, err)
+	require.Empty(t, m.sinks.Load().metricsSinks)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- m.ListenAndServe(ctx)
+	}()
+
+	statsdConfig := FileConfig{
+		Statsd: []StatsdConfig{{Address: "127.0.0.1:8125"}},
+	}
+	require.NoError(t, m.Reload(ctx, statsdConfig))
+	require.Len(t, m.sinks.Load().metricsSinks, 1)
+	m.IncrCounter([]string{"counter"}, 1)
+
+	// Sinks that fail to build are rejected and the previous sinks restored
+	sampleRatio := 2.0
+	err = m.Reload(ctx, FileConfig{
+		Tracing: &TracingConfig{Endpoint: "localhost:4317", SampleRatio: &sampleRatio},
+	})
+	require.EqualError(t, err, "invalid tracing sample_ratio 2: must be between 0 and 1")
+	require.Len(t, m.sinks.Load().metricsSinks, 1)
+	require.Equal(t, statsdConfig, m.sinks.Load().fileConfig)
+
+	require.NoError(t, m.Reload(ctx, FileConfig{}))
+	require.Empty(t, m.sinks.Load().metricsSinks)
+
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	// Reloading does not block once the metrics are no longer served
+	require.ErrorIs(t, m.Reload(ctx, statsdConfig), context.Canceled)
+}
+
+func TestRuntimeMetricsFollowReloads(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	m, err := NewMetrics(&MetricsConfig{
+		Logger:      log,
+		ServiceName: "foo",
+		FileConfig:  FileConfig{InMem: &InMem{}},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- m.ListenAndServe(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	requireRuntimeMetrics := func() {
+		sinks := m.sinks.Load()
+		require.Len(t, sinks.metricsSinks, 1)
+		// The go-metrics instances do not collect the runtime metrics
+		// themselves, since their collector cannot be stopped on reload
+		require.False(t, sinks.metricsSinks[0].EnableRuntimeMetrics)
+
+		inmem, ok := sinks.runners[0].sinks()[0].(*metrics.InmemSink)
+		require.True(t, ok)
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			var gauges []string
+			for _, interval := range inmem.Data() {
+				interval.RLock()
+				for _, gauge := range interval.Gauges {
+					gauges = append(gauges, gauge.Name)
+				}
+				interval.RUnlock()
+			}
+			assert.Contains(c, gauges, "foo.runtime.num_goroutines")
+		}, 10*time.Second, 100*time.Millisecond)
+	}
+
+	requireRuntimeMetrics()
+
+	// The runtime metrics are emitted to the reloaded sinks
+	require.NoError(t, m.Reload(ctx, FileConfig{InMem: &InMem{}}))
+	requireRuntimeMetrics()
+}
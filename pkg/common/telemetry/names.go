@@ -293,6 +293,9 @@ const (
 	// ElapsedTime tags some duration of time.
 	ElapsedTime = "elapsed_time"
 
+	// Enabled tags whether something is enabled
+	Enabled = "enabled"
+
 	// EntryAdded is the counter key for when an entry is added to LRU cache
 	EntryAdded = "lru_cache_entry_add"
 
@@ -341,6 +344,12 @@ const (
 	// Failures amount of concatenated errors
 	Failures = "failures"
 
+	// FeatureFlag tags a feature flag name
+	FeatureFlag = "feature_flag"
+
+	// FeatureFlags tags a list of feature flag names
+	FeatureFlags = "feature_flags"
+
 	// FederatedAdded labels some count of federated bundles that have been added to an entity
 	FederatedAdded = "fed_add"
 
@@ -450,6 +459,9 @@ const (
 	// PluginType tags type of some plugin
 	PluginType = "plugin_type"
 
+	// Plugins tags a list of plugins, identified by their type and name
+	Plugins = "plugins"
+
 	// PodUID tags some pod UID, most likely for use in attestation
 	PodUID = "pod_uid"
 
@@ -509,6 +521,9 @@ const (
 	// RequestID tags a request identifier
 	RequestID = "request_id"
 
+	// RemovedResources tags some count of resources that have been removed
+	RemovedResources = "removed_resources"
+
 	// ResourceNames tags some group of resources by name
 	ResourceNames = "resource_names"
 
@@ -540,6 +555,9 @@ const (
 	// SelectorsRemoved labels some count of selectors that have been removed from an entity
 	SelectorsRemoved = "selectors_removed"
 
+	// Settings tags a list of configuration settings
+	Settings = "settings"
+
 	// SelfSigned tags whether some entity is self-signed
 	SelfSigned = "self_signed"
 
@@ -549,6 +567,9 @@ const (
 	// SerialNumber tags a certificate serial number
 	SerialNumber = "serial_num"
 
+	// ServerID tags the ID of a server among the servers sharing a datastore
+	ServerID = "server_id"
+
 	// Slot X509 CA Slot ID
 	Slot = "slot"
 
@@ -631,6 +652,10 @@ const (
 	// Unknown tags some unknown caller, entity, or status
 	Unknown = "unknown"
 
+	// UnsubscribedResourceNames tags some group of resources by name that are
+	// no longer subscribed to
+	UnsubscribedResourceNames = "unsubscribed_resource_names"
+
 	// Updated tags some entity as updated; should be used
 	// with other tags to add clarity
 	Updated = "updated"
@@ -647,6 +672,18 @@ const (
 	// VersionInfo tags some version information
 	VersionInfo = "version_info"
 
+	// WITAuthorityExpiresAt tags a WIT Authority expiration
+	WITAuthorityExpiresAt = "wit_authority_expires_at"
+
+	// WITAuthorityKeyID tags a WIT authority key ID
+	WITAuthorityKeyID = "wit_authority_key_id"
+
+	// WITAuthorityKeyIDs tags a list of WIT authority key IDs
+	WITAuthorityKeyIDs = "wit_authority_key_ids"
+
+	// WITAuthorityPublicKeySHA256 tags a WIT Authority public key
+	WITAuthorityPublicKeySHA256 = "wit_authority_public_key_sha256"
+
 	// WITKeys tags some count or list of WIT Keys. Should NEVER provide the actual keys, use
 	// Key IDs instead.
 	WITKeys = "wit_keys"
@@ -677,6 +714,9 @@ const (
 	// Attestor tags an attestor plugin/type (eg. gcp, aws...)
 	Attestor = "attestor"
 
+	// AuditLogSink functionality related to the audit log sink
+	AuditLogSink = "audit_log_sink"
+
 	// Bundle functionality related to a bundle; should be used with other tags
 	// to add clarity
 	Bundle = "bundle"
@@ -721,6 +761,9 @@ const (
 	// Catalog functionality related to plugin catalog
 	Catalog = "catalog"
 
+	// ConfigReloader functionality related to reloading the configuration
+	ConfigReloader = "config_reloader"
+
 	// Datastore functionality related to datastore plugin
 	Datastore = "datastore"
 
@@ -733,15 +776,31 @@ const (
 	// Entry tag for some stored entry
 	Entry = "entry"
 
+	// EntryTemplate functionality related to registration entry templates
+	EntryTemplate = "entry_template"
+
+	// EntryTemplateID tags some entry template ID
+	EntryTemplateID = "entry_template_id"
+
 	// Event tag some event that has occurred, for a notifier, watcher, listener, etc.
 	Event = "event"
 
+	// FeatureFlagStatus functionality related to the feature flags reported
+	// by servers and agents
+	FeatureFlagStatus = "feature_flag_status"
+
 	// ExpiringSVIDs tags expiring SVID count/list
 	ExpiringSVIDs = "expiring_svids"
 
 	// OutdatedSVIDs tags SVID with outdated attributes count/list
 	OutdatedSVIDs = "outdated_svids"
 
+	// CachedEntries tags cached registration entry count
+	CachedEntries = "cached_entries"
+
+	// CachedX509SVIDs tags cached X509-SVID count
+	CachedX509SVIDs = "cached_x509_svids"
+
 	// FederatedBundle functionality related to a federated bundle; should be used
 	// with other tags to add clarity
 	FederatedBundle = "federated_bundle"
@@ -814,6 +873,9 @@ const (
 	// TaintedJWTSVIDs tags tainted JWT SVID count/list
 	TaintedJWTSVIDs = "tainted_jwt_svids"
 
+	// TaintedWITSVIDs tags tainted WIT SVID count/list
+	TaintedWITSVIDs = "tainted_wit_svids"
+
 	// TaintedX509SVIDs tags tainted X.509 SVID count/list
 	TaintedX509SVIDs = "tainted_x509_svids"
 
@@ -968,6 +1030,9 @@ const (
 	// ProcessTaintedJWTSVIDs functionality related to processing tainted JWT SVIDs.
 	ProcessTaintedJWTSVIDs = "process_tainted_jwt_svids"
 
+	// ProcessTaintedWITSVIDs functionality related to processing tainted WIT SVIDs.
+	ProcessTaintedWITSVIDs = "process_tainted_wit_svids"
+
 	// SDSAPI functionality related to SDS; should be used with other tags
 	// to add clarity
 	SDSAPI = "sds_api"
@@ -0,0 +1,417 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"github.com/spiffe/spire/pkg/common/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	otlpProtocolGRPC = "grpc"
+	otlpProtocolHTTP = "http"
+
+	// otlpShutdownTimeout bounds how long the final flush of metrics may take
+	// when the exporter is shut down.
+	otlpShutdownTimeout = 5 * time.Second
+)
+
+type otlpRunner struct {
+	c                        *OTLPConfig
+	log                      logrus.FieldLogger
+	provider                 *sdkmetric.MeterProvider
+	sink                     *otlpSink
+	tlsPolicy                tlspolicy.Policy
+	getX509SVID              func() (*x509svid.SVID, error)
+	getX509BundleAuthorities func(spiffeid.TrustDomain) ([]*x509.Certificate, error)
+}
+
+func newOTLPRunner(c *MetricsConfig) (sinkRunner, error) {
+	runner := &otlpRunner{
+		c:                        c.FileConfig.OTLP,
+		log:                      c.Logger,
+		tlsPolicy:                c.TLSPolicy,
+		getX509SVID:              c.GetX509SVID,
+		getX509BundleAuthorities: c.GetX509BundleAuthorities,
+	}
+
+	if runner.c == nil {
+		return runner, nil
+	}
+
+	exporter, err := runner.newExporter()
+	if err != nil {
+		return runner, err
+	}
+
+	var readerOpts []sdkmetric.PeriodicReaderOption
+	if runner.c.ExportInterval != "" {
+		interval, err := time.ParseDuration(runner.c.ExportInterval)
+		if err != nil {
+			return runner, fmt.Errorf("invalid OTLP export_interval: %w", err)
+		}
+		readerOpts = append(readerOpts, sdkmetric.WithInterval(interval))
+	}
+
+	runner.provider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, readerOpts...)),
+		sdkmetric.WithResource(resource.NewSchemaless(attribute.String("service.name", c.ServiceName))),
+	)
+	runner.sink = newOTLPSink(runner.provider.Meter(c.ServiceName), runner.log)
+
+	runner.log.WithFields(logrus.Fields{
+		"endpoint": runner.c.Endpoint,
+		"protocol": runner.protocol(),
+	}).Info("Starting OTLP metrics exporter")
+
+	return runner, nil
+}
+
+func (r *otlpRunner) isConfigured() bool {
+	return r.c != nil
+}
+
+func (r *otlpRunner) sinks() []Sink {
+	if !r.isConfigured() {
+		return []Sink{}
+	}
+
+	return []Sink{r.sink}
+}
+
+func (r *otlpRunner) run(ctx context.Context) error {
+	if !r.isConfigured() {
+		return nil
+	}
+
+	<-ctx.Done()
+
+	// Flush any pending metrics on the way out
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), otlpShutdownTimeout)
+	defer cancel()
+	if err := r.provider.Shutdown(shutdownCtx); err != nil {
+		r.log.WithError(err).Warn("Failed to shut down OTLP metrics exporter")
+	}
+
+	return ctx.Err()
+}
+
+func (r *otlpRunner) requiresTypePrefix() bool {
+	return false
+}
+
+func (r *otlpRunner) protocol() string {
+	if r.c.Protocol == "" {
+		return otlpProtocolGRPC
+	}
+	return r.c.Protocol
+}
+
+func (r *otlpRunner) newExporter() (sdkmetric.Exporter, error) {
+	if r.c.Endpoint == "" {
+		return nil, errors.New("OTLP endpoint must be configured")
+	}
+
+	tlsCfg, err := r.clientTLS().config(r.c.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	// The exporters connect lazily, so creating them does not block on the
+	// collector being available.
+	ctx := context.Background()
+	switch r.protocol() {
+	case otlpProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(r.c.Endpoint),
+			otlpmetricgrpc.WithHeaders(r.c.Headers),
+		}
+		switch {
+		case r.c.Insecure:
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		case tlsCfg != nil:
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case otlpProtocolHTTP:
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(r.c.Endpoint),
+			otlpmetrichttp.WithHeaders(r.c.Headers),
+		}
+		if r.c.URLPath != "" {
+			opts = append(opts, otlpmetrichttp.WithURLPath(r.c.URLPath))
+		}
+		switch {
+		case r.c.Insecure:
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		case tlsCfg != nil:
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q: expected %q or %q", r.c.Protocol, otlpProtocolGRPC, otlpProtocolHTTP)
+	}
+}
+
+func (r *otlpRunner) clientTLS() otlpClientTLS {
+	return otlpClientTLS{
+		c:                        r.c.TLS,
+		tlsPolicy:                r.tlsPolicy,
+		getX509SVID:              r.getX509SVID,
+		getX509BundleAuthorities: r.getX509BundleAuthorities,
+	}
+}
+
+// otlpClientTLS builds the client TLS configuration used by the OTLP
+// exporters to connect to the collector.
+type otlpClientTLS struct {
+	c                        *OTLPTLSConfig
+	tlsPolicy                tlspolicy.Policy
+	getX509SVID              func() (*x509svid.SVID, error)
+	getX509BundleAuthorities func(spiffeid.TrustDomain) ([]*x509.Certificate, error)
+}
+
+// config returns the TLS configuration for the exporter, or nil if the
+// exporter should use the system defaults (or no TLS at all when insecure).
+func (t otlpClientTLS) config(insecure bool) (*tls.Config, error) {
+	switch {
+	case t.c == nil:
+		return nil, nil
+	case insecure:
+		return nil, errors.New("OTLP tls cannot be configured when insecure is enabled")
+	}
+
+	tlsCfg, err := t.newTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TLS config for OTLP: %w", err)
+	}
+	if err := tlspolicy.ApplyPolicy(tlsCfg, t.tlsPolicy); err != nil {
+		return nil, fmt.Errorf("failed to apply TLS policy for OTLP: %w", err)
+	}
+	return tlsCfg, nil
+}
+
+func (t otlpClientTLS) newTLSConfig() (*tls.Config, error) {
+	if err := t.validateTLSConfig(); err != nil {
+		return nil, err
+	}
+
+	authorizedSPIFFEIDs := make([]spiffeid.ID, 0, len(t.c.AuthorizedSPIFFEIDs))
+	for _, idString := range t.c.AuthorizedSPIFFEIDs {
+		id, err := spiffeid.FromString(idString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid authorized SPIFFE ID %q: %w", idString, err)
+		}
+		authorizedSPIFFEIDs = append(authorizedSPIFFEIDs, id)
+	}
+
+	var roots *x509.CertPool
+	if t.c.CAFile != "" {
+		var err error
+		roots, err = util.LoadCertPool(t.c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tlsCfg *tls.Config
+	switch {
+	case t.c.UseSPIRESVID && len(authorizedSPIFFEIDs) > 0:
+		// Both ends authenticate with SPIFFE
+		svidSource := &telemetryX509SVIDSource{getter: t.getX509SVID}
+		bundleSource := &telemetryBundleSource{getter: t.getX509BundleAuthorities}
+		tlsCfg = tlsconfig.MTLSClientConfig(svidSource, bundleSource, tlsconfig.AuthorizeOneOf(authorizedSPIFFEIDs...))
+	case t.c.UseSPIRESVID:
+		// The collector has a web certificate
+		svidSource := &telemetryX509SVIDSource{getter: t.getX509SVID}
+		tlsCfg = tlsconfig.MTLSWebClientConfig(svidSource, roots)
+	case len(authorizedSPIFFEIDs) > 0:
+		bundleSource := &telemetryBundleSource{getter: t.getX509BundleAuthorities}
+		tlsCfg = tlsconfig.TLSClientConfig(bundleSource, tlsconfig.AuthorizeOneOf(authorizedSPIFFEIDs...))
+		if err := t.loadClientCertificate(tlsCfg); err != nil {
+			return nil, err
+		}
+	default:
+		tlsCfg = &tls.Config{
+			RootCAs:    roots,
+			MinVersion: tls.VersionTLS12,
+		}
+		if err := t.loadClientCertificate(tlsCfg); err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCfg.MinVersion = tls.VersionTLS12
+	return tlsCfg, nil
+}
+
+func (t otlpClientTLS) loadClientCertificate(tlsCfg *tls.Config) error {
+	if t.c.CertFile == "" {
+		return nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(t.c.CertFile, t.c.KeyFile)
+	if err != nil {
+		return err
+	}
+	tlsCfg.Certificates = []tls.Certificate{certificate}
+	return nil
+}
+
+func (t otlpClientTLS) validateTLSConfig() error {
+	switch {
+	case t.c.UseSPIRESVID && (t.c.CertFile != "" || t.c.KeyFile != ""):
+		return errors.New("cert_file and key_file cannot be configured when use_spire_svid is enabled")
+	case (t.c.CertFile == "") != (t.c.KeyFile == ""):
+		return errors.New("cert_file and key_file must both be configured")
+	case len(t.c.AuthorizedSPIFFEIDs) > 0 && t.c.CAFile != "":
+		return errors.New("ca_file cannot be configured with authorized_spiffe_ids")
+	case t.c.UseSPIRESVID && t.getX509SVID == nil:
+		return errors.New("use_spire_svid requires access to the current SPIRE SVID")
+	case len(t.c.AuthorizedSPIFFEIDs) > 0 && t.getX509BundleAuthorities == nil:
+		return errors.New("authorized_spiffe_ids requires access to SPIRE trust bundles")
+	default:
+		return nil
+	}
+}
+
+// otlpSink records go-metrics calls on OpenTelemetry instruments. Instruments
+// are created lazily, the first time a metric name is seen. Instruments that
+// fail to be created are not retried, so the failure is only logged once.
+type otlpSink struct {
+	meter metric.Meter
+	log   logrus.FieldLogger
+
+	mu         sync.Mutex
+	gauges     map[string]metric.Float64Gauge
+	counters   map[string]metric.Float64Counter
+	histograms map[string]metric.Float64Histogram
+	failed     map[otlpInstrumentKey]struct{}
+}
+
+// otlpInstrumentKey identifies an instrument. The same metric name can be
+// used by instruments of different kinds.
+type otlpInstrumentKey struct {
+	kind string
+	name string
+}
+
+func newOTLPSink(meter metric.Meter, log logrus.FieldLogger) *otlpSink {
+	return &otlpSink{
+		meter:      meter,
+		log:        log,
+		gauges:     make(map[string]metric.Float64Gauge),
+		counters:   make(map[string]metric.Float64Counter),
+		histograms: make(map[string]metric.Float64Histogram),
+		failed:     make(map[otlpInstrumentKey]struct{}),
+	}
+}
+
+func (s *otlpSink) SetGauge(key []string, val float32) {
+	s.SetPrecisionGaugeWithLabels(key, float64(val), nil)
+}
+
+func (s *otlpSink) SetGaugeWithLabels(key []string, val float32, labels []Label) {
+	s.SetPrecisionGaugeWithLabels(key, float64(val), labels)
+}
+
+func (s *otlpSink) SetPrecisionGauge(key []string, val float64) {
+	s.SetPrecisionGaugeWithLabels(key, val, nil)
+}
+
+func (s *otlpSink) SetPrecisionGaugeWithLabels(key []string, val float64, labels []Label) {
+	name := s.flattenKey(key)
+	gauge, ok := getInstrument(s, s.gauges, "gauge", name, s.meter.Float64Gauge)
+	if !ok {
+		return
+	}
+	gauge.Record(context.Background(), val, metric.WithAttributes(labelsToAttributes(labels)...))
+}
+
+// Not implemented for OTLP
+func (s *otlpSink) EmitKey([]string, float32) {}
+
+func (s *otlpSink) IncrCounter(key []string, val float32) {
+	s.IncrCounterWithLabels(key, val, nil)
+}
+
+func (s *otlpSink) IncrCounterWithLabels(key []string, val float32, labels []Label) {
+	name := s.flattenKey(key)
+	counter, ok := getInstrument(s, s.counters, "counter", name, s.meter.Float64Counter)
+	if !ok {
+		return
+	}
+	counter.Add(context.Background(), float64(val), metric.WithAttributes(labelsToAttributes(labels)...))
+}
+
+func (s *otlpSink) AddSample(key []string, val float32) {
+	s.AddSampleWithLabels(key, val, nil)
+}
+
+func (s *otlpSink) AddSampleWithLabels(key []string, val float32, labels []Label) {
+	name := s.flattenKey(key)
+	histogram, ok := getInstrument(s, s.histograms, "histogram", name, s.meter.Float64Histogram)
+	if !ok {
+		return
+	}
+	histogram.Record(context.Background(), float64(val), metric.WithAttributes(labelsToAttributes(labels)...))
+}
+
+// flattenKey joins the key parts the same way the Prometheus sink does, so
+// metric names are consistent regardless of the sink they flow through.
+func (s *otlpSink) flattenKey(parts []string) string {
+	return strings.Join(parts, "_")
+}
+
+// getInstrument returns the instrument of the given kind and name, creating
+// it if needed. It returns false if the instrument could not be created. The
+// failure is logged the first time only.
+func getInstrument[I any, O any](s *otlpSink, instruments map[string]I, kind, name string, create func(string, ...O) (I, error)) (I, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if instrument, ok := instruments[name]; ok {
+		return instrument, true
+	}
+	key := otlpInstrumentKey{kind: kind, name: name}
+	if _, ok := s.failed[key]; ok {
+		var zero I
+		return zero, false
+	}
+	instrument, err := create(name)
+	if err != nil {
+		s.failed[key] = struct{}{}
+		s.log.WithError(err).WithField("metric", name).Warnf("Failed to create OTLP %s", kind)
+		return instrument, false
+	}
+	instruments[name] = instrument
+	return instrument, true
+}
+
+func labelsToAttributes(labels []Label) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		attrs = append(attrs, attribute.String(l.Name, l.Value))
+	}
+	return attrs
+}
+
+var _ Sink = (*otlpSink)(nil)
@@ -0,0 +1,398 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewOTLPRunner(t *testing.T) {
+	config := testOTLPConfig()
+	runner, err := newOTLPRunner(config)
+	require.NoError(t, err)
+	assert.True(t, runner.isConfigured())
+	assert.Len(t, runner.sinks(), 1)
+
+	config.FileConfig.OTLP.Protocol = "http"
+	runner, err = newOTLPRunner(config)
+	require.NoError(t, err)
+	assert.True(t, runner.isConfigured())
+
+	// It works when not configured
+	config.FileConfig.OTLP = nil
+	runner, err = newOTLPRunner(config)
+	require.NoError(t, err)
+	assert.False(t, runner.isConfigured())
+	assert.Empty(t, runner.sinks())
+}
+
+func TestNewOTLPRunnerValidation(t *testing.T) {
+	tests := []struct {
+		name             string
+		setupConfig      func(*OTLPConfig)
+		errorMsgContains string
+	}{
+		{
+			name: "missing endpoint",
+			setupConfig: func(c *OTLPConfig) {
+				c.Endpoint = ""
+			},
+			errorMsgContains: "OTLP endpoint must be configured",
+		},
+		{
+			name: "unsupported protocol",
+			setupConfig: func(c *OTLPConfig) {
+				c.Protocol = "udp"
+			},
+			errorMsgContains: `unsupported OTLP protocol "udp"`,
+		},
+		{
+			name: "invalid export interval",
+			setupConfig: func(c *OTLPConfig) {
+				c.ExportInterval = "often"
+			},
+			errorMsgContains: "invalid OTLP export_interval",
+		},
+		{
+			name: "insecure with TLS",
+			setupConfig: func(c *OTLPConfig) {
+				c.TLS = &OTLPTLSConfig{}
+			},
+			errorMsgContains: "OTLP tls cannot be configured when insecure is enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testOTLPConfig()
+			tt.setupConfig(config.FileConfig.OTLP)
+
+			_, err := newOTLPRunner(config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsgContains)
+		})
+	}
+}
+
+func TestRunOTLP(t *testing.T) {
+	config := testOTLPConfig()
+
+	runner, err := newOTLPRunner(config)
+	require.NoError(t, err)
+
+	errCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		errCh <- runner.run(ctx)
+	}()
+
+	// It stops when it's supposed to
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Minute):
+		t.Fatal("timeout waiting for shutdown")
+	}
+
+	config.FileConfig.OTLP = nil
+	runner, err = newOTLPRunner(config)
+	require.NoError(t, err)
+
+	go func() {
+		errCh <- runner.run(context.Background())
+	}()
+
+	// It doesn't run if it's not configured
+	select {
+	case err := <-errCh:
+		assert.Nil(t, err, "should be nil if not configured")
+	case <-time.After(time.Minute):
+		t.Fatal("OTLP running but not configured")
+	}
+}
+
+func TestOTLPTLSConfig(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	foreignTD := spiffeid.RequireTrustDomainFromString("foreign.example.org")
+
+	clientCA := testca.New(t, td)
+	foreignCA := testca.New(t, foreignTD)
+
+	clientSVID := clientCA.CreateX509SVID(spiffeid.RequireFromPath(td, "/spire/server"))
+	allowedCollectorSVID := foreignCA.CreateX509SVID(spiffeid.RequireFromPath(foreignTD, "/collector"))
+	disallowedCollectorSVID := clientCA.CreateX509SVID(spiffeid.RequireFromPath(td, "/other"))
+
+	getX509BundleAuthorities := func(td spiffeid.TrustDomain) ([]*x509.Certificate, error) {
+		switch td {
+		case clientCA.X509Bundle().TrustDomain():
+			return clientCA.X509Authorities(), nil
+		case foreignCA.X509Bundle().TrustDomain():
+			return foreignCA.X509Authorities(), nil
+		default:
+			return nil, nil
+		}
+	}
+
+	tests := []struct {
+		name             string
+		setupConfig      func(*MetricsConfig)
+		validateTLS      func(t *testing.T, tlsCfg *tls.Config)
+		errorMsgContains string
+	}{
+		{
+			name: "CA file and client certificate",
+			setupConfig: func(config *MetricsConfig) {
+				certFile, keyFile := createTestCertAndKey(t)
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					CAFile:   createTestCA(t),
+					CertFile: certFile,
+					KeyFile:  keyFile,
+				}
+			},
+			validateTLS: func(t *testing.T, tlsCfg *tls.Config) {
+				assert.NotNil(t, tlsCfg.RootCAs)
+				assert.Len(t, tlsCfg.Certificates, 1)
+			},
+		},
+		{
+			name: "use SPIRE SVID with web collector",
+			setupConfig: func(config *MetricsConfig) {
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					UseSPIRESVID: true,
+				}
+				config.GetX509SVID = func() (*x509svid.SVID, error) {
+					return clientSVID, nil
+				}
+			},
+			validateTLS: func(t *testing.T, tlsCfg *tls.Config) {
+				certificate, err := tlsCfg.GetClientCertificate(&tls.CertificateRequestInfo{})
+				require.NoError(t, err)
+				assert.Equal(t, clientSVID.Certificates[0].Raw, certificate.Certificate[0])
+			},
+		},
+		{
+			name: "use SPIRE SVID with authorized SPIFFE IDs",
+			setupConfig: func(config *MetricsConfig) {
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					UseSPIRESVID:        true,
+					AuthorizedSPIFFEIDs: []string{allowedCollectorSVID.ID.String()},
+				}
+				config.GetX509SVID = func() (*x509svid.SVID, error) {
+					return clientSVID, nil
+				}
+				config.GetX509BundleAuthorities = getX509BundleAuthorities
+			},
+			validateTLS: func(t *testing.T, tlsCfg *tls.Config) {
+				certificate, err := tlsCfg.GetClientCertificate(&tls.CertificateRequestInfo{})
+				require.NoError(t, err)
+				assert.Equal(t, clientSVID.Certificates[0].Raw, certificate.Certificate[0])
+				require.NoError(t, tlsCfg.VerifyPeerCertificate(rawCerts(allowedCollectorSVID.Certificates), nil))
+				require.Error(t, tlsCfg.VerifyPeerCertificate(rawCerts(disallowedCollectorSVID.Certificates), nil))
+			},
+		},
+		{
+			name: "use SPIRE SVID with cert file",
+			setupConfig: func(config *MetricsConfig) {
+				certFile, keyFile := createTestCertAndKey(t)
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					UseSPIRESVID: true,
+					CertFile:     certFile,
+					KeyFile:      keyFile,
+				}
+				config.GetX509SVID = func() (*x509svid.SVID, error) {
+					return clientSVID, nil
+				}
+			},
+			errorMsgContains: "cert_file and key_file cannot be configured when use_spire_svid is enabled",
+		},
+		{
+			name: "cert file without key file",
+			setupConfig: func(config *MetricsConfig) {
+				certFile, _ := createTestCertAndKey(t)
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					CertFile: certFile,
+				}
+			},
+			errorMsgContains: "cert_file and key_file must both be configured",
+		},
+		{
+			name: "authorized SPIFFE IDs with CA file",
+			setupConfig: func(config *MetricsConfig) {
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					CAFile:              createTestCA(t),
+					AuthorizedSPIFFEIDs: []string{"spiffe://example.org/collector"},
+				}
+				config.GetX509BundleAuthorities = getX509BundleAuthorities
+			},
+			errorMsgContains: "ca_file cannot be configured with authorized_spiffe_ids",
+		},
+		{
+			name: "invalid authorized SPIFFE ID",
+			setupConfig: func(config *MetricsConfig) {
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					AuthorizedSPIFFEIDs: []string{"not-a-spiffe-id"},
+				}
+				config.GetX509BundleAuthorities = getX509BundleAuthorities
+			},
+			errorMsgContains: `invalid authorized SPIFFE ID "not-a-spiffe-id"`,
+		},
+		{
+			name: "use SPIRE SVID without SVID source",
+			setupConfig: func(config *MetricsConfig) {
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					UseSPIRESVID: true,
+				}
+			},
+			errorMsgContains: "use_spire_svid requires access to the current SPIRE SVID",
+		},
+		{
+			name: "authorized SPIFFE IDs without bundle source",
+			setupConfig: func(config *MetricsConfig) {
+				config.FileConfig.OTLP.TLS = &OTLPTLSConfig{
+					AuthorizedSPIFFEIDs: []string{"spiffe://example.org/collector"},
+				}
+			},
+			errorMsgContains: "authorized_spiffe_ids requires access to SPIRE trust bundles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testOTLPConfig()
+			config.FileConfig.OTLP.Insecure = false
+			tt.setupConfig(config)
+
+			clientTLS := otlpClientTLS{
+				c:                        config.FileConfig.OTLP.TLS,
+				getX509SVID:              config.GetX509SVID,
+				getX509BundleAuthorities: config.GetX509BundleAuthorities,
+			}
+			tlsCfg, err := clientTLS.newTLSConfig()
+			if tt.errorMsgContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsgContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint16(tls.VersionTLS12), tlsCfg.MinVersion)
+			tt.validateTLS(t, tlsCfg)
+
+			// The full exporter can be built with the TLS configuration
+			_, err = newOTLPRunner(config)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOTLPSink(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sink := newOTLPSink(provider.Meter("test"), log)
+
+	labels := []Label{{Name: "method", Value: "FetchX509SVID"}}
+	sink.SetGaugeWithLabels([]string{"spire_agent", "gauge"}, 2, labels)
+	sink.SetPrecisionGauge([]string{"spire_agent", "precision"}, 3.5)
+	sink.IncrCounterWithLabels([]string{"spire_agent", "counter"}, 1, labels)
+	sink.IncrCounterWithLabels([]string{"spire_agent", "counter"}, 2, labels)
+	sink.AddSampleWithLabels([]string{"spire_agent", "elapsed"}, 10, labels)
+	sink.AddSample([]string{"spire_agent", "elapsed"}, 20)
+	sink.EmitKey([]string{"spire_agent", "ignored"}, 1)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	got := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		got[m.Name] = m.Data
+	}
+	require.Len(t, got, 4)
+
+	attrs := attribute.NewSet(attribute.String("method", "FetchX509SVID"))
+
+	gauge, ok := got["spire_agent_gauge"].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, 2.0, gauge.DataPoints[0].Value)
+	assert.Equal(t, attrs, gauge.DataPoints[0].Attributes)
+
+	precision, ok := got["spire_agent_precision"].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, precision.DataPoints, 1)
+	assert.Equal(t, 3.5, precision.DataPoints[0].Value)
+
+	counter, ok := got["spire_agent_counter"].(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.Len(t, counter.DataPoints, 1)
+	assert.Equal(t, 3.0, counter.DataPoints[0].Value)
+	assert.Equal(t, attrs, counter.DataPoints[0].Attributes)
+
+	histogram, ok := got["spire_agent_elapsed"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 2)
+	var total uint64
+	for _, dp := range histogram.DataPoints {
+		total += dp.Count
+	}
+	assert.Equal(t, uint64(2), total)
+}
+
+func TestOTLPSinkLogsInstrumentFailuresOnce(t *testing.T) {
+	log, logHook := test.NewNullLogger()
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sink := newOTLPSink(provider.Meter("test"), log)
+
+	// Instrument names cannot start with a digit
+	invalid := []string{"1", "invalid"}
+	for range 3 {
+		sink.IncrCounter(invalid, 1)
+		sink.AddSample(invalid, 1)
+	}
+	sink.IncrCounter([]string{"spire_agent", "counter"}, 1)
+
+	entries := logHook.AllEntries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Equal(t, "Failed to create OTLP counter", entries[0].Message)
+	assert.Equal(t, "1_invalid", entries[0].Data["metric"])
+	assert.Equal(t, "Failed to create OTLP histogram", entries[1].Message)
+	assert.Equal(t, "1_invalid", entries[1].Data["metric"])
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	assert.Equal(t, "spire_agent_counter", rm.ScopeMetrics[0].Metrics[0].Name)
+}
+
+func testOTLPConfig() *MetricsConfig {
+	l, _ := test.NewNullLogger()
+
+	return &MetricsConfig{
+		Logger:      l,
+		ServiceName: "foo",
+		TrustDomain: "test.org",
+		FileConfig: FileConfig{
+			OTLP: &OTLPConfig{
+				Endpoint: "localhost:4317",
+				Insecure: true,
+			},
+		},
+	}
+}
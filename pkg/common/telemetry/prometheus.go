@@ -45,11 +45,11 @@ func newPrometheusRunner(c *MetricsConfig) (sinkRunner, error) {
 		return runner, nil
 	}
 
-	var err error
-	runner.sink, err = prommetrics.NewPrometheusSinkFrom(prommetrics.PrometheusOpts{})
+	sink, err := prommetrics.NewPrometheusSinkFrom(prommetrics.PrometheusOpts{})
 	if err != nil {
 		return runner, err
 	}
+	runner.sink = sink
 
 	handlerOpts := promhttp.HandlerOpts{
 		ErrorLog: runner.log,
@@ -78,9 +78,11 @@ func newPrometheusRunner(c *MetricsConfig) (sinkRunner, error) {
 	if runner.c.TLS != nil {
 		tlsCfg, tlsCfgErr := runner.newTLSConfig()
 		if tlsCfgErr != nil {
+			prometheus.Unregister(sink)
 			return runner, fmt.Errorf("failed to create TLS config for Prometheus: %w", tlsCfgErr)
 		}
 		if err := tlspolicy.ApplyPolicy(tlsCfg, runner.tlsPolicy); err != nil {
+			prometheus.Unregister(sink)
 			return runner, fmt.Errorf("failed to apply TLS policy for Prometheus: %w", err)
 		}
 		runner.server.TLSConfig = tlsCfg
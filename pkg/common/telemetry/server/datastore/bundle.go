@@ -73,6 +73,12 @@ func StartTaintJWTKeyCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.JWT, telemetry.Taint)
 }
 
+// StartTaintWITKeyCall return metric
+// for server's datastore, on tainting a WIT public key.
+func StartTaintWITKeyCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.WIT, telemetry.Taint)
+}
+
 // StartRevokeX509CACall return metric
 // for server's datastore, on revoking an X.509 CA from bundle.
 func StartRevokeX509CACall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -85,4 +91,10 @@ func StartRevokeJWTKeyCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.JWT, telemetry.Revoke)
 }
 
+// StartRevokeWITKeyCall return metric
+// for server's datastore, on revoking a WIT Signing Key from bundle.
+func StartRevokeWITKeyCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.WIT, telemetry.Revoke)
+}
+
 // End Call Counters
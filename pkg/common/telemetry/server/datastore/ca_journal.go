@@ -22,8 +22,8 @@ func StartPruneCAJournalsCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.CAJournal, telemetry.Prune)
 }
 
-// StartListCAJournalsForTesting return metric
+// StartListCAJournalsCall return metric
 // for server's datastore, on listing CA journals for testing.
-func StartListCAJournalsForTesting(m telemetry.Metrics) *telemetry.CallCounter {
+func StartListCAJournalsCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.CAJournal, telemetry.List)
 }
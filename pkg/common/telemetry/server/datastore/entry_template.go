@@ -0,0 +1,29 @@
+package datastore
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// StartCreateEntryTemplateCall return metric for server's datastore, on
+// creating an entry template.
+func StartCreateEntryTemplateCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.EntryTemplate, telemetry.Create)
+}
+
+// StartDeleteEntryTemplateCall return metric for server's datastore, on
+// deleting an entry template.
+func StartDeleteEntryTemplateCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.EntryTemplate, telemetry.Delete)
+}
+
+// StartFetchEntryTemplateCall return metric for server's datastore, on
+// fetching an entry template.
+func StartFetchEntryTemplateCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.EntryTemplate, telemetry.Fetch)
+}
+
+// StartListEntryTemplatesCall return metric for server's datastore, on
+// listing entry templates.
+func StartListEntryTemplatesCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.EntryTemplate, telemetry.List)
+}
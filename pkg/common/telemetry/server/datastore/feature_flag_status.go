@@ -0,0 +1,23 @@
+package datastore
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// StartListFeatureFlagStatusesCall return metric for server's datastore, on
+// listing feature flag statuses.
+func StartListFeatureFlagStatusesCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.FeatureFlagStatus, telemetry.List)
+}
+
+// StartPruneServerFeatureFlagStatusesCall return metric for server's
+// datastore, on pruning the feature flag statuses of servers.
+func StartPruneServerFeatureFlagStatusesCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.FeatureFlagStatus, telemetry.Prune)
+}
+
+// StartSetFeatureFlagStatusCall return metric for server's datastore, on
+// setting a feature flag status.
+func StartSetFeatureFlagStatusCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.FeatureFlagStatus, telemetry.Set)
+}
@@ -0,0 +1,417 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing wraps a datastore interface and records a span for each call,
+// as a child of the span in the context of the call, if any.
+func WithTracing(ds datastore.DataStore) datastore.DataStore {
+	return tracingWrapper{ds: ds}
+}
+
+type tracingWrapper struct {
+	ds datastore.DataStore
+}
+
+func (w tracingWrapper) AppendBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
+	ctx, span := startSpan(ctx, "AppendBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.AppendBundle(ctx, bundle)
+}
+
+func (w tracingWrapper) CountAttestedNodes(ctx context.Context, req *datastore.CountAttestedNodesRequest) (_ int32, err error) {
+	ctx, span := startSpan(ctx, "CountAttestedNodes")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CountAttestedNodes(ctx, req)
+}
+
+func (w tracingWrapper) CountBundles(ctx context.Context) (_ int32, err error) {
+	ctx, span := startSpan(ctx, "CountBundles")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CountBundles(ctx)
+}
+
+func (w tracingWrapper) CountRegistrationEntries(ctx context.Context, req *datastore.CountRegistrationEntriesRequest) (_ int32, err error) {
+	ctx, span := startSpan(ctx, "CountRegistrationEntries")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CountRegistrationEntries(ctx, req)
+}
+
+func (w tracingWrapper) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (_ *common.AttestedNode, err error) {
+	ctx, span := startSpan(ctx, "CreateAttestedNode")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateAttestedNode(ctx, node)
+}
+
+func (w tracingWrapper) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) (err error) {
+	ctx, span := startSpan(ctx, "CreateAttestedNodeEventForTesting")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateAttestedNodeEventForTesting(ctx, event)
+}
+
+func (w tracingWrapper) CreateBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
+	ctx, span := startSpan(ctx, "CreateBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateBundle(ctx, bundle)
+}
+
+func (w tracingWrapper) CreateEntryTemplate(ctx context.Context, template *common.EntryTemplate) (_ *common.EntryTemplate, err error) {
+	ctx, span := startSpan(ctx, "CreateEntryTemplate")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateEntryTemplate(ctx, template)
+}
+
+func (w tracingWrapper) CreateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship) (_ *datastore.FederationRelationship, err error) {
+	ctx, span := startSpan(ctx, "CreateFederationRelationship")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateFederationRelationship(ctx, fr)
+}
+
+func (w tracingWrapper) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
+	ctx, span := startSpan(ctx, "CreateJoinToken")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateJoinToken(ctx, token)
+}
+
+func (w tracingWrapper) CreateOrReturnRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, _ bool, err error) {
+	ctx, span := startSpan(ctx, "CreateOrReturnRegistrationEntry")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateOrReturnRegistrationEntry(ctx, entry)
+}
+
+func (w tracingWrapper) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, err error) {
+	ctx, span := startSpan(ctx, "CreateRegistrationEntry")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateRegistrationEntry(ctx, entry)
+}
+
+func (w tracingWrapper) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) (err error) {
+	ctx, span := startSpan(ctx, "CreateRegistrationEntryEventForTesting")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.CreateRegistrationEntryEventForTesting(ctx, event)
+}
+
+func (w tracingWrapper) DeleteAttestedNode(ctx context.Context, spiffeID string) (_ *common.AttestedNode, err error) {
+	ctx, span := startSpan(ctx, "DeleteAttestedNode")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteAttestedNode(ctx, spiffeID)
+}
+
+func (w tracingWrapper) DeleteAttestedNodeEventForTesting(ctx context.Context, eventID uint) (err error) {
+	ctx, span := startSpan(ctx, "DeleteAttestedNodeEventForTesting")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteAttestedNodeEventForTesting(ctx, eventID)
+}
+
+func (w tracingWrapper) DeleteBundle(ctx context.Context, trustDomain string, mode datastore.DeleteMode) (err error) {
+	ctx, span := startSpan(ctx, "DeleteBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteBundle(ctx, trustDomain, mode)
+}
+
+func (w tracingWrapper) DeleteEntryTemplate(ctx context.Context, templateID string) (_ *common.EntryTemplate, err error) {
+	ctx, span := startSpan(ctx, "DeleteEntryTemplate")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteEntryTemplate(ctx, templateID)
+}
+
+func (w tracingWrapper) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (err error) {
+	ctx, span := startSpan(ctx, "DeleteFederationRelationship")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteFederationRelationship(ctx, trustDomain)
+}
+
+func (w tracingWrapper) DeleteJoinToken(ctx context.Context, token string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteJoinToken")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteJoinToken(ctx, token)
+}
+
+func (w tracingWrapper) DeleteRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	ctx, span := startSpan(ctx, "DeleteRegistrationEntry")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteRegistrationEntry(ctx, entryID)
+}
+
+func (w tracingWrapper) DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) (err error) {
+	ctx, span := startSpan(ctx, "DeleteRegistrationEntryEventForTesting")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.DeleteRegistrationEntryEventForTesting(ctx, eventID)
+}
+
+func (w tracingWrapper) FetchAttestedNode(ctx context.Context, spiffeID string) (_ *common.AttestedNode, err error) {
+	ctx, span := startSpan(ctx, "FetchAttestedNode")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchAttestedNode(ctx, spiffeID)
+}
+
+func (w tracingWrapper) FetchAttestedNodeEvent(ctx context.Context, eventID uint) (_ *datastore.AttestedNodeEvent, err error) {
+	ctx, span := startSpan(ctx, "FetchAttestedNodeEvent")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchAttestedNodeEvent(ctx, eventID)
+}
+
+func (w tracingWrapper) FetchBundle(ctx context.Context, trustDomain string) (_ *common.Bundle, err error) {
+	ctx, span := startSpan(ctx, "FetchBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchBundle(ctx, trustDomain)
+}
+
+func (w tracingWrapper) FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (_ *datastore.CAJournal, err error) {
+	ctx, span := startSpan(ctx, "FetchCAJournal")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchCAJournal(ctx, activeX509AuthorityID)
+}
+
+func (w tracingWrapper) FetchFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (_ *datastore.FederationRelationship, err error) {
+	ctx, span := startSpan(ctx, "FetchFederationRelationship")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchFederationRelationship(ctx, trustDomain)
+}
+
+func (w tracingWrapper) FetchEntryTemplate(ctx context.Context, templateID string) (_ *common.EntryTemplate, err error) {
+	ctx, span := startSpan(ctx, "FetchEntryTemplate")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchEntryTemplate(ctx, templateID)
+}
+
+func (w tracingWrapper) FetchJoinToken(ctx context.Context, token string) (_ *datastore.JoinToken, err error) {
+	ctx, span := startSpan(ctx, "FetchJoinToken")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchJoinToken(ctx, token)
+}
+
+func (w tracingWrapper) FetchRegistrationEntries(ctx context.Context, entryIDs []string) (_ map[string]*common.RegistrationEntry, err error) {
+	ctx, span := startSpan(ctx, "FetchRegistrationEntries")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchRegistrationEntries(ctx, entryIDs)
+}
+
+func (w tracingWrapper) FetchRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	ctx, span := startSpan(ctx, "FetchRegistrationEntry")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchRegistrationEntry(ctx, entryID)
+}
+
+func (w tracingWrapper) FetchRegistrationEntryEvent(ctx context.Context, eventID uint) (_ *datastore.RegistrationEntryEvent, err error) {
+	ctx, span := startSpan(ctx, "FetchRegistrationEntryEvent")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.FetchRegistrationEntryEvent(ctx, eventID)
+}
+
+func (w tracingWrapper) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency) (_ []*common.Selector, err error) {
+	ctx, span := startSpan(ctx, "GetNodeSelectors")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency)
+}
+
+func (w tracingWrapper) ListAttestedNodeEvents(ctx context.Context, req *datastore.ListAttestedNodeEventsRequest) (_ *datastore.ListAttestedNodeEventsResponse, err error) {
+	ctx, span := startSpan(ctx, "ListAttestedNodeEvents")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListAttestedNodeEvents(ctx, req)
+}
+
+func (w tracingWrapper) ListAttestedNodes(ctx context.Context, req *datastore.ListAttestedNodesRequest) (_ *datastore.ListAttestedNodesResponse, err error) {
+	ctx, span := startSpan(ctx, "ListAttestedNodes")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListAttestedNodes(ctx, req)
+}
+
+func (w tracingWrapper) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (_ *datastore.ListBundlesResponse, err error) {
+	ctx, span := startSpan(ctx, "ListBundles")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListBundles(ctx, req)
+}
+
+func (w tracingWrapper) ListCAJournals(ctx context.Context) (_ []*datastore.CAJournal, err error) {
+	ctx, span := startSpan(ctx, "ListCAJournals")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListCAJournals(ctx)
+}
+
+func (w tracingWrapper) ListFederationRelationships(ctx context.Context, req *datastore.ListFederationRelationshipsRequest) (_ *datastore.ListFederationRelationshipsResponse, err error) {
+	ctx, span := startSpan(ctx, "ListFederationRelationships")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListFederationRelationships(ctx, req)
+}
+
+func (w tracingWrapper) ListEntryTemplates(ctx context.Context) (_ []*common.EntryTemplate, err error) {
+	ctx, span := startSpan(ctx, "ListEntryTemplates")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListEntryTemplates(ctx)
+}
+
+func (w tracingWrapper) ListFeatureFlagStatuses(ctx context.Context) (_ []*datastore.FeatureFlagStatus, err error) {
+	ctx, span := startSpan(ctx, "ListFeatureFlagStatuses")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListFeatureFlagStatuses(ctx)
+}
+
+func (w tracingWrapper) ListJoinTokens(ctx context.Context) (_ []*datastore.JoinToken, err error) {
+	ctx, span := startSpan(ctx, "ListJoinTokens")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListJoinTokens(ctx)
+}
+
+func (w tracingWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
+	ctx, span := startSpan(ctx, "ListNodeSelectors")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListNodeSelectors(ctx, req)
+}
+
+func (w tracingWrapper) ListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (_ *datastore.ListRegistrationEntriesResponse, err error) {
+	ctx, span := startSpan(ctx, "ListRegistrationEntries")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListRegistrationEntries(ctx, req)
+}
+
+func (w tracingWrapper) ListRegistrationEntryEvents(ctx context.Context, req *datastore.ListRegistrationEntryEventsRequest) (_ *datastore.ListRegistrationEntryEventsResponse, err error) {
+	ctx, span := startSpan(ctx, "ListRegistrationEntryEvents")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.ListRegistrationEntryEvents(ctx, req)
+}
+
+func (w tracingWrapper) PruneAttestedExpiredNodes(ctx context.Context, expiredBefore time.Time, includeNonReattestable bool) (err error) {
+	ctx, span := startSpan(ctx, "PruneAttestedExpiredNodes")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneAttestedExpiredNodes(ctx, expiredBefore, includeNonReattestable)
+}
+
+func (w tracingWrapper) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "PruneAttestedNodeEvents")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneAttestedNodeEvents(ctx, olderThan)
+}
+
+func (w tracingWrapper) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "PruneBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneBundle(ctx, trustDomainID, expiresBefore)
+}
+
+func (w tracingWrapper) PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) (err error) {
+	ctx, span := startSpan(ctx, "PruneCAJournals")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneCAJournals(ctx, allCAsExpireBefore)
+}
+
+func (w tracingWrapper) PruneServerFeatureFlagStatuses(ctx context.Context, reportedBefore time.Time) (err error) {
+	ctx, span := startSpan(ctx, "PruneServerFeatureFlagStatuses")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneServerFeatureFlagStatuses(ctx, reportedBefore)
+}
+
+func (w tracingWrapper) PruneJoinTokens(ctx context.Context, expiresBefore time.Time) (err error) {
+	ctx, span := startSpan(ctx, "PruneJoinTokens")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneJoinTokens(ctx, expiresBefore)
+}
+
+func (w tracingWrapper) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) (err error) {
+	ctx, span := startSpan(ctx, "PruneRegistrationEntries")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneRegistrationEntries(ctx, expiresBefore)
+}
+
+func (w tracingWrapper) PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "PruneRegistrationEntryEvents")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.PruneRegistrationEntryEvents(ctx, olderThan)
+}
+
+func (w tracingWrapper) RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
+	ctx, span := startSpan(ctx, "RevokeJWTKey")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.RevokeJWTKey(ctx, trustDomainID, authorityID)
+}
+
+func (w tracingWrapper) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
+	ctx, span := startSpan(ctx, "RevokeWITKey")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.RevokeWITKey(ctx, trustDomainID, authorityID)
+}
+
+func (w tracingWrapper) RevokeX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToRevoke string) (err error) {
+	ctx, span := startSpan(ctx, "RevokeX509CA")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.RevokeX509CA(ctx, trustDomainID, subjectKeyIDToRevoke)
+}
+
+func (w tracingWrapper) SetBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
+	ctx, span := startSpan(ctx, "SetBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.SetBundle(ctx, bundle)
+}
+
+func (w tracingWrapper) SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (_ *datastore.CAJournal, err error) {
+	ctx, span := startSpan(ctx, "SetCAJournal")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.SetCAJournal(ctx, caJournal)
+}
+
+func (w tracingWrapper) SetFeatureFlagStatus(ctx context.Context, status *datastore.FeatureFlagStatus) (err error) {
+	ctx, span := startSpan(ctx, "SetFeatureFlagStatus")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.SetFeatureFlagStatus(ctx, status)
+}
+
+func (w tracingWrapper) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) (err error) {
+	ctx, span := startSpan(ctx, "SetNodeSelectors")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.SetNodeSelectors(ctx, spiffeID, selectors)
+}
+
+func (w tracingWrapper) TaintJWTKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
+	ctx, span := startSpan(ctx, "TaintJWTKey")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.TaintJWTKey(ctx, trustDomainID, authorityID)
+}
+
+func (w tracingWrapper) TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
+	ctx, span := startSpan(ctx, "TaintWITKey")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.TaintWITKey(ctx, trustDomainID, authorityID)
+}
+
+func (w tracingWrapper) TaintX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToTaint string) (err error) {
+	ctx, span := startSpan(ctx, "TaintX509CA")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.TaintX509CA(ctx, trustDomainID, subjectKeyIDToTaint)
+}
+
+func (w tracingWrapper) UpdateAttestedNode(ctx context.Context, node *common.AttestedNode, mask *common.AttestedNodeMask) (_ *common.AttestedNode, err error) {
+	ctx, span := startSpan(ctx, "UpdateAttestedNode")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.UpdateAttestedNode(ctx, node, mask)
+}
+
+func (w tracingWrapper) UpdateBundle(ctx context.Context, bundle *common.Bundle, mask *common.BundleMask) (_ *common.Bundle, err error) {
+	ctx, span := startSpan(ctx, "UpdateBundle")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.UpdateBundle(ctx, bundle, mask)
+}
+
+func (w tracingWrapper) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (_ *datastore.FederationRelationship, err error) {
+	ctx, span := startSpan(ctx, "UpdateFederationRelationship")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.UpdateFederationRelationship(ctx, fr, mask)
+}
+
+func (w tracingWrapper) UpdateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) (_ *common.RegistrationEntry, err error) {
+	ctx, span := startSpan(ctx, "UpdateRegistrationEntry")
+	defer func() { telemetry.EndSpan(span, err) }()
+	return w.ds.UpdateRegistrationEntry(ctx, entry, mask)
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return telemetry.StartSpan(ctx, "datastore."+method, trace.WithSpanKind(trace.SpanKindClient))
+}
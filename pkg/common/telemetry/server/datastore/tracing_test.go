@@ -0,0 +1,65 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ds := &fakeDataStore{}
+	w := WithTracing(ds)
+
+	wv := reflect.ValueOf(w)
+	wt := reflect.TypeOf(w)
+	for method := range wt.Methods() {
+		methodValue := wv.Method(method.Index)
+
+		doCall := func(ctx context.Context, err error) (any, sdktrace.ReadOnlySpan) {
+			ds.SetError(err)
+			numIn := methodValue.Type().NumIn()
+			args := []reflect.Value{reflect.ValueOf(ctx)}
+			for i := 1; i < numIn; i++ {
+				args = append(args, reflect.New(methodValue.Type().In(i)).Elem())
+			}
+			out := methodValue.Call(args)
+			spans := recorder.Ended()
+			require.NotEmpty(t, spans)
+			return out[len(out)-1].Interface(), spans[len(spans)-1]
+		}
+
+		t.Run(method.Name+"(success)", func(t *testing.T) {
+			parentCtx, parent := otel.Tracer("test").Start(context.Background(), "caller")
+			defer parent.End()
+
+			err, span := doCall(parentCtx, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "datastore."+method.Name, span.Name())
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Equal(t, otelcodes.Unset, span.Status().Code)
+		})
+
+		t.Run(method.Name+"(failure)", func(t *testing.T) {
+			err, span := doCall(context.Background(), errors.New("ohno"))
+			assert.NotNil(t, err)
+			assert.Equal(t, "datastore."+method.Name, span.Name())
+			assert.Equal(t, otelcodes.Error, span.Status().Code)
+			assert.Equal(t, "ohno", span.Status().Description)
+		})
+	}
+}
@@ -47,6 +47,12 @@ func (w metricsWrapper) CreateBundle(ctx context.Context, bundle *common.Bundle)
 	return w.ds.CreateBundle(ctx, bundle)
 }
 
+func (w metricsWrapper) CreateEntryTemplate(ctx context.Context, template *common.EntryTemplate) (_ *common.EntryTemplate, err error) {
+	callCounter := StartCreateEntryTemplateCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.CreateEntryTemplate(ctx, template)
+}
+
 func (w metricsWrapper) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
 	callCounter := StartCreateJoinTokenCall(w.m)
 	defer callCounter.Done(&err)
@@ -101,6 +107,12 @@ func (w metricsWrapper) DeleteBundle(ctx context.Context, trustDomain string, mo
 	return w.ds.DeleteBundle(ctx, trustDomain, mode)
 }
 
+func (w metricsWrapper) DeleteEntryTemplate(ctx context.Context, templateID string) (_ *common.EntryTemplate, err error) {
+	callCounter := StartDeleteEntryTemplateCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.DeleteEntryTemplate(ctx, templateID)
+}
+
 func (w metricsWrapper) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (err error) {
 	callCounter := StartDeleteFederationRelationshipCall(w.m)
 	defer callCounter.Done(&err)
@@ -143,6 +155,12 @@ func (w metricsWrapper) FetchBundle(ctx context.Context, trustDomain string) (_
 	return w.ds.FetchBundle(ctx, trustDomain)
 }
 
+func (w metricsWrapper) FetchEntryTemplate(ctx context.Context, templateID string) (_ *common.EntryTemplate, err error) {
+	callCounter := StartFetchEntryTemplateCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.FetchEntryTemplate(ctx, templateID)
+}
+
 func (w metricsWrapper) FetchJoinToken(ctx context.Context, token string) (_ *datastore.JoinToken, err error) {
 	callCounter := StartFetchJoinTokenCall(w.m)
 	defer callCounter.Done(&err)
@@ -197,6 +215,24 @@ func (w metricsWrapper) ListBundles(ctx context.Context, req *datastore.ListBund
 	return w.ds.ListBundles(ctx, req)
 }
 
+func (w metricsWrapper) ListEntryTemplates(ctx context.Context) (_ []*common.EntryTemplate, err error) {
+	callCounter := StartListEntryTemplatesCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListEntryTemplates(ctx)
+}
+
+func (w metricsWrapper) ListFeatureFlagStatuses(ctx context.Context) (_ []*datastore.FeatureFlagStatus, err error) {
+	callCounter := StartListFeatureFlagStatusesCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListFeatureFlagStatuses(ctx)
+}
+
+func (w metricsWrapper) ListJoinTokens(ctx context.Context) (_ []*datastore.JoinToken, err error) {
+	callCounter := StartListJoinTokenCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListJoinTokens(ctx)
+}
+
 func (w metricsWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
 	callCounter := StartListNodeSelectorsCall(w.m)
 	defer callCounter.Done(&err)
@@ -245,6 +281,12 @@ func (w metricsWrapper) PruneBundle(ctx context.Context, trustDomainID string, e
 	return w.ds.PruneBundle(ctx, trustDomainID, expiresBefore)
 }
 
+func (w metricsWrapper) PruneServerFeatureFlagStatuses(ctx context.Context, reportedBefore time.Time) (err error) {
+	callCounter := StartPruneServerFeatureFlagStatusesCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneServerFeatureFlagStatuses(ctx, reportedBefore)
+}
+
 func (w metricsWrapper) PruneJoinTokens(ctx context.Context, expiresBefore time.Time) (err error) {
 	callCounter := StartPruneJoinTokenCall(w.m)
 	defer callCounter.Done(&err)
@@ -293,12 +335,30 @@ func (w metricsWrapper) TaintJWTKey(ctx context.Context, trustDomainID string, a
 	return w.ds.TaintJWTKey(ctx, trustDomainID, authorityID)
 }
 
+func (w metricsWrapper) TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
+	callCounter := StartTaintWITKeyCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.TaintWITKey(ctx, trustDomainID, authorityID)
+}
+
 func (w metricsWrapper) RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
 	callCounter := StartRevokeJWTKeyCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.RevokeJWTKey(ctx, trustDomainID, authorityID)
 }
 
+func (w metricsWrapper) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (_ *common.PublicKey, err error) {
+	callCounter := StartRevokeWITKeyCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.RevokeWITKey(ctx, trustDomainID, authorityID)
+}
+
+func (w metricsWrapper) SetFeatureFlagStatus(ctx context.Context, status *datastore.FeatureFlagStatus) (err error) {
+	callCounter := StartSetFeatureFlagStatusCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SetFeatureFlagStatus(ctx, status)
+}
+
 func (w metricsWrapper) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) (err error) {
 	callCounter := StartSetNodeSelectorsCall(w.m)
 	defer callCounter.Done(&err)
@@ -341,10 +401,10 @@ func (w metricsWrapper) FetchCAJournal(ctx context.Context, activeX509AuthorityI
 	return w.ds.FetchCAJournal(ctx, activeX509AuthorityID)
 }
 
-func (w metricsWrapper) ListCAJournalsForTesting(ctx context.Context) (_ []*datastore.CAJournal, err error) {
-	callCounter := StartListCAJournalsForTesting(w.m)
+func (w metricsWrapper) ListCAJournals(ctx context.Context) (_ []*datastore.CAJournal, err error) {
+	callCounter := StartListCAJournalsCall(w.m)
 	defer callCounter.Done(&err)
-	return w.ds.ListCAJournalsForTesting(ctx)
+	return w.ds.ListCAJournals(ctx)
 }
 
 func (w metricsWrapper) PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) (err error) {
@@ -177,6 +177,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.federation_relationship.list",
 			methodName: "ListFederationRelationships",
 		},
+		{
+			key:        "datastore.join_token.list",
+			methodName: "ListJoinTokens",
+		},
 		{
 			key:        "datastore.node.prune",
 			methodName: "PruneAttestedExpiredNodes",
@@ -221,6 +225,14 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.jwt.taint",
 			methodName: "TaintJWTKey",
 		},
+		{
+			key:        "datastore.bundle.wit.revoke",
+			methodName: "RevokeWITKey",
+		},
+		{
+			key:        "datastore.bundle.wit.taint",
+			methodName: "TaintWITKey",
+		},
 		{
 			key:        "datastore.node.selectors.set",
 			methodName: "SetNodeSelectors",
@@ -241,6 +253,34 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.registration_entry.update",
 			methodName: "UpdateRegistrationEntry",
 		},
+		{
+			key:        "datastore.entry_template.create",
+			methodName: "CreateEntryTemplate",
+		},
+		{
+			key:        "datastore.entry_template.delete",
+			methodName: "DeleteEntryTemplate",
+		},
+		{
+			key:        "datastore.entry_template.fetch",
+			methodName: "FetchEntryTemplate",
+		},
+		{
+			key:        "datastore.entry_template.list",
+			methodName: "ListEntryTemplates",
+		},
+		{
+			key:        "datastore.feature_flag_status.list",
+			methodName: "ListFeatureFlagStatuses",
+		},
+		{
+			key:        "datastore.feature_flag_status.prune",
+			methodName: "PruneServerFeatureFlagStatuses",
+		},
+		{
+			key:        "datastore.feature_flag_status.set",
+			methodName: "SetFeatureFlagStatus",
+		},
 		{
 			key:        "datastore.ca_journal.set",
 			methodName: "SetCAJournal",
@@ -255,7 +295,7 @@ func TestWithMetrics(t *testing.T) {
 		},
 		{
 			key:        "datastore.ca_journal.list",
-			methodName: "ListCAJournalsForTesting",
+			methodName: "ListCAJournals",
 		},
 	} {
 		methodType, ok := wt.MethodByName(tt.methodName)
@@ -376,6 +416,10 @@ func (ds *fakeDataStore) CreateJoinToken(context.Context, *datastore.JoinToken)
 	return ds.err
 }
 
+func (ds *fakeDataStore) ListJoinTokens(context.Context) ([]*datastore.JoinToken, error) {
+	return []*datastore.JoinToken{}, ds.err
+}
+
 func (ds *fakeDataStore) CreateRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	return &common.RegistrationEntry{}, ds.err
 }
@@ -520,6 +564,14 @@ func (ds *fakeDataStore) RevokeJWTKey(context.Context, string, string) (*common.
 	return &common.PublicKey{}, ds.err
 }
 
+func (ds *fakeDataStore) TaintWITKey(context.Context, string, string) (*common.PublicKey, error) {
+	return &common.PublicKey{}, ds.err
+}
+
+func (ds *fakeDataStore) RevokeWITKey(context.Context, string, string) (*common.PublicKey, error) {
+	return &common.PublicKey{}, ds.err
+}
+
 func (ds *fakeDataStore) SetNodeSelectors(context.Context, string, []*common.Selector) error {
 	return ds.err
 }
@@ -540,6 +592,34 @@ func (ds *fakeDataStore) UpdateFederationRelationship(context.Context, *datastor
 	return &datastore.FederationRelationship{}, ds.err
 }
 
+func (ds *fakeDataStore) CreateEntryTemplate(context.Context, *common.EntryTemplate) (*common.EntryTemplate, error) {
+	return &common.EntryTemplate{}, ds.err
+}
+
+func (ds *fakeDataStore) DeleteEntryTemplate(context.Context, string) (*common.EntryTemplate, error) {
+	return &common.EntryTemplate{}, ds.err
+}
+
+func (ds *fakeDataStore) FetchEntryTemplate(context.Context, string) (*common.EntryTemplate, error) {
+	return &common.EntryTemplate{}, ds.err
+}
+
+func (ds *fakeDataStore) ListEntryTemplates(context.Context) ([]*common.EntryTemplate, error) {
+	return []*common.EntryTemplate{}, ds.err
+}
+
+func (ds *fakeDataStore) ListFeatureFlagStatuses(context.Context) ([]*datastore.FeatureFlagStatus, error) {
+	return []*datastore.FeatureFlagStatus{}, ds.err
+}
+
+func (ds *fakeDataStore) PruneServerFeatureFlagStatuses(context.Context, time.Time) error {
+	return ds.err
+}
+
+func (ds *fakeDataStore) SetFeatureFlagStatus(context.Context, *datastore.FeatureFlagStatus) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) SetCAJournal(context.Context, *datastore.CAJournal) (*datastore.CAJournal, error) {
 	return &datastore.CAJournal{}, ds.err
 }
@@ -548,7 +628,7 @@ func (ds *fakeDataStore) FetchCAJournal(context.Context, string) (*datastore.CAJ
 	return &datastore.CAJournal{}, ds.err
 }
 
-func (ds *fakeDataStore) ListCAJournalsForTesting(context.Context) ([]*datastore.CAJournal, error) {
+func (ds *fakeDataStore) ListCAJournals(context.Context) ([]*datastore.CAJournal, error) {
 	return []*datastore.CAJournal{}, ds.err
 }
 
@@ -0,0 +1,215 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"google.golang.org/grpc/credentials"
+)
+
+// tracerName is the instrumentation scope used for spans created by SPIRE.
+const tracerName = "github.com/spiffe/spire"
+
+// StartSpan starts a span as a child of the span in the given context, if
+// any. Spans are created against the global tracer provider, which discards
+// them unless tracing has been configured.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// TracerProvider returns a tracer provider that starts spans against the
+// global tracer provider current at the time. The global provider is replaced
+// when the telemetry configuration is reloaded, and the previous one is shut
+// down, so instrumentation that holds on to its tracers, such as the otelgrpc
+// handlers, must use this provider to keep exporting spans.
+func TracerProvider() trace.TracerProvider {
+	return globalTracerProvider{}
+}
+
+type globalTracerProvider struct {
+	embedded.TracerProvider
+}
+
+func (globalTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return &globalTracer{name: name, opts: opts}
+}
+
+type globalTracer struct {
+	embedded.Tracer
+
+	name string
+	opts []trace.TracerOption
+}
+
+func (t *globalTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.GetTracerProvider().Tracer(t.name, t.opts...).Start(ctx, spanName, opts...)
+}
+
+// EndSpan records the outcome of the traced operation and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type tracingRunner struct {
+	c                        *TracingConfig
+	log                      logrus.FieldLogger
+	provider                 *sdktrace.TracerProvider
+	tlsPolicy                tlspolicy.Policy
+	getX509SVID              func() (*x509svid.SVID, error)
+	getX509BundleAuthorities func(spiffeid.TrustDomain) ([]*x509.Certificate, error)
+}
+
+func newTracingRunner(c *MetricsConfig) (sinkRunner, error) {
+	runner := &tracingRunner{
+		c:                        c.FileConfig.Tracing,
+		log:                      c.Logger,
+		tlsPolicy:                c.TLSPolicy,
+		getX509SVID:              c.GetX509SVID,
+		getX509BundleAuthorities: c.GetX509BundleAuthorities,
+	}
+
+	if runner.c == nil {
+		return runner, nil
+	}
+
+	sampleRatio := 1.0
+	if runner.c.SampleRatio != nil {
+		sampleRatio = *runner.c.SampleRatio
+	}
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return runner, fmt.Errorf("invalid tracing sample_ratio %v: must be between 0 and 1", sampleRatio)
+	}
+
+	exporter, err := runner.newExporter()
+	if err != nil {
+		return runner, err
+	}
+
+	runner.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", c.ServiceName))),
+		// Spans continue the sampling decision made by the caller, so a trace
+		// that starts at the agent is kept in full by the server.
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+
+	otel.SetTracerProvider(runner.provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	runner.log.WithFields(logrus.Fields{
+		"endpoint":     runner.c.Endpoint,
+		"protocol":     runner.protocol(),
+		"sample_ratio": sampleRatio,
+	}).Info("Starting OTLP trace exporter")
+
+	return runner, nil
+}
+
+func (r *tracingRunner) isConfigured() bool {
+	return r.c != nil
+}
+
+// Tracing does not emit metrics
+func (r *tracingRunner) sinks() []Sink {
+	return []Sink{}
+}
+
+func (r *tracingRunner) run(ctx context.Context) error {
+	if !r.isConfigured() {
+		return nil
+	}
+
+	<-ctx.Done()
+
+	// Flush any pending spans on the way out
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), otlpShutdownTimeout)
+	defer cancel()
+	if err := r.provider.Shutdown(shutdownCtx); err != nil {
+		r.log.WithError(err).Warn("Failed to shut down OTLP trace exporter")
+	}
+
+	return ctx.Err()
+}
+
+func (r *tracingRunner) requiresTypePrefix() bool {
+	return false
+}
+
+func (r *tracingRunner) protocol() string {
+	if r.c.Protocol == "" {
+		return otlpProtocolGRPC
+	}
+	return r.c.Protocol
+}
+
+func (r *tracingRunner) newExporter() (sdktrace.SpanExporter, error) {
+	if r.c.Endpoint == "" {
+		return nil, errors.New("tracing endpoint must be configured")
+	}
+
+	clientTLS := otlpClientTLS{
+		c:                        r.c.TLS,
+		tlsPolicy:                r.tlsPolicy,
+		getX509SVID:              r.getX509SVID,
+		getX509BundleAuthorities: r.getX509BundleAuthorities,
+	}
+	tlsCfg, err := clientTLS.config(r.c.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	// The exporters connect lazily, so creating them does not block on the
+	// collector being available.
+	ctx := context.Background()
+	switch r.protocol() {
+	case otlpProtocolGRPC:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(r.c.Endpoint),
+			otlptracegrpc.WithHeaders(r.c.Headers),
+		}
+		switch {
+		case r.c.Insecure:
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		case tlsCfg != nil:
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case otlpProtocolHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(r.c.Endpoint),
+			otlptracehttp.WithHeaders(r.c.Headers),
+		}
+		if r.c.URLPath != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(r.c.URLPath))
+		}
+		switch {
+		case r.c.Insecure:
+			opts = append(opts, otlptracehttp.WithInsecure())
+		case tlsCfg != nil:
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing protocol %q: expected %q or %q", r.c.Protocol, otlpProtocolGRPC, otlpProtocolHTTP)
+	}
+}
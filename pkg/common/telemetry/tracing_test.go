@@ -0,0 +1,226 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracingRunner(t *testing.T) {
+	restoreGlobalTracing(t)
+
+	config := testTracingConfig()
+	runner, err := newTracingRunner(config)
+	require.NoError(t, err)
+	assert.True(t, runner.isConfigured())
+	assert.Empty(t, runner.sinks())
+
+	// The tracer provider and W3C trace context propagator are installed
+	assert.Equal(t, runner.(*tracingRunner).provider, otel.GetTracerProvider())
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate"}, otel.GetTextMapPropagator().Fields())
+
+	config.FileConfig.Tracing.Protocol = "http"
+	runner, err = newTracingRunner(config)
+	require.NoError(t, err)
+	assert.True(t, runner.isConfigured())
+
+	// It works when not configured
+	config.FileConfig.Tracing = nil
+	runner, err = newTracingRunner(config)
+	require.NoError(t, err)
+	assert.False(t, runner.isConfigured())
+}
+
+func TestNewTracingRunnerValidation(t *testing.T) {
+	restoreGlobalTracing(t)
+
+	badRatio := 1.5
+
+	tests := []struct {
+		name             string
+		setupConfig      func(*TracingConfig)
+		errorMsgContains string
+	}{
+		{
+			name: "missing endpoint",
+			setupConfig: func(c *TracingConfig) {
+				c.Endpoint = ""
+			},
+			errorMsgContains: "tracing endpoint must be configured",
+		},
+		{
+			name: "unsupported protocol",
+			setupConfig: func(c *TracingConfig) {
+				c.Protocol = "udp"
+			},
+			errorMsgContains: `unsupported tracing protocol "udp"`,
+		},
+		{
+			name: "invalid sample ratio",
+			setupConfig: func(c *TracingConfig) {
+				c.SampleRatio = &badRatio
+			},
+			errorMsgContains: "invalid tracing sample_ratio 1.5: must be between 0 and 1",
+		},
+		{
+			name: "insecure with TLS",
+			setupConfig: func(c *TracingConfig) {
+				c.TLS = &OTLPTLSConfig{}
+			},
+			errorMsgContains: "OTLP tls cannot be configured when insecure is enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testTracingConfig()
+			tt.setupConfig(config.FileConfig.Tracing)
+
+			_, err := newTracingRunner(config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsgContains)
+		})
+	}
+}
+
+func TestRunTracing(t *testing.T) {
+	restoreGlobalTracing(t)
+
+	config := testTracingConfig()
+
+	runner, err := newTracingRunner(config)
+	require.NoError(t, err)
+
+	errCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		errCh <- runner.run(ctx)
+	}()
+
+	// It stops when it's supposed to
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Minute):
+		t.Fatal("timeout waiting for shutdown")
+	}
+
+	config.FileConfig.Tracing = nil
+	runner, err = newTracingRunner(config)
+	require.NoError(t, err)
+
+	go func() {
+		errCh <- runner.run(context.Background())
+	}()
+
+	// It doesn't run if it's not configured
+	select {
+	case err := <-errCh:
+		assert.Nil(t, err, "unexpected error if not configured")
+	case <-time.After(time.Minute):
+		t.Fatal("run should return immediately when not configured")
+	}
+}
+
+func TestNewMetricsWithTracing(t *testing.T) {
+	restoreGlobalTracing(t)
+
+	config := testTracingConfig()
+	metrics, err := NewMetrics(config)
+	require.NoError(t, err)
+
+	// Tracing is run alongside the metrics sinks but does not add one
+	sinks := metrics.sinks.Load()
+	assert.Empty(t, sinks.metricsSinks)
+	require.Len(t, sinks.runners, 1)
+	assert.IsType(t, &tracingRunner{}, sinks.runners[0])
+}
+
+func TestSpans(t *testing.T) {
+	restoreGlobalTracing(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := StartSpan(context.Background(), "parent")
+	_, child := StartSpan(ctx, "child")
+	EndSpan(child, errors.New("oh no"))
+	EndSpan(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "oh no", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+
+	assert.Equal(t, "parent", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestTracerProviderFollowsReloads(t *testing.T) {
+	restoreGlobalTracing(t)
+
+	// The tracer is obtained once, as instrumentation does
+	tracer := TracerProvider().Tracer("test")
+
+	oldRecorder := tracetest.NewSpanRecorder()
+	oldProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(oldRecorder))
+	otel.SetTracerProvider(oldProvider)
+	_, span := tracer.Start(context.Background(), "before")
+	span.End()
+
+	// A reload installs a new provider and shuts down the old one
+	newRecorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newRecorder)))
+	require.NoError(t, oldProvider.Shutdown(context.Background()))
+	_, span = tracer.Start(context.Background(), "after")
+	span.End()
+
+	require.Len(t, oldRecorder.Ended(), 1)
+	assert.Equal(t, "before", oldRecorder.Ended()[0].Name())
+	require.Len(t, newRecorder.Ended(), 1)
+	assert.Equal(t, "after", newRecorder.Ended()[0].Name())
+}
+
+func testTracingConfig() *MetricsConfig {
+	l, _ := test.NewNullLogger()
+
+	return &MetricsConfig{
+		Logger:      l,
+		ServiceName: "foo",
+		TrustDomain: "test.org",
+		FileConfig: FileConfig{
+			Tracing: &TracingConfig{
+				Endpoint: "localhost:4317",
+				Insecure: true,
+			},
+		},
+	}
+}
+
+// restoreGlobalTracing restores the global tracer provider and propagator,
+// which are replaced when tracing is configured, at the end of the test.
+func restoreGlobalTracing(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+}
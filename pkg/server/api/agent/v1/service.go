@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -14,6 +15,7 @@ import (
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/errorutil"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/selector"
@@ -28,11 +30,17 @@ import (
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// FeatureFlagsRecorder records the feature flags reported by agents
+type FeatureFlagsRecorder interface {
+	RecordAgentFeatureFlags(ctx context.Context, agentID spiffeid.ID, enabled []string) error
+}
+
 // Config is the service configuration
 type Config struct {
 	Catalog                 catalog.Catalog
@@ -41,6 +49,10 @@ type Config struct {
 	ServerCA                ca.ServerCA
 	TrustDomain             spiffeid.TrustDomain
 	AgentSpiffeIdAsSelector bool
+
+	// FeatureFlags, if set, records the feature flags reported by agents
+	// when posting their status.
+	FeatureFlags FeatureFlagsRecorder
 }
 
 // Service implements the v1 agent service
@@ -53,6 +65,7 @@ type Service struct {
 	ca                      ca.ServerCA
 	td                      spiffeid.TrustDomain
 	AgentSpiffeIdAsSelector bool
+	featureFlags            FeatureFlagsRecorder
 }
 
 // New creates a new agent service
@@ -64,6 +77,7 @@ func New(config Config) *Service {
 		ca:                      config.ServerCA,
 		td:                      config.TrustDomain,
 		AgentSpiffeIdAsSelector: config.AgentSpiffeIdAsSelector,
+		featureFlags:            config.FeatureFlags,
 	}
 }
 
@@ -506,6 +520,31 @@ func (s *Service) PostStatus(ctx context.Context, req *agentv1.PostStatusRequest
 		telemetry.SPIFFEID: callerID.String(),
 	})
 
+	// Agents report their feature flags through the request metadata. Agents
+	// that predate this do not send it, so nothing is recorded for them.
+	if md, ok := metadata.FromIncomingContext(ctx); ok && s.featureFlags != nil {
+		if values := md.Get(fflag.AgentMetadataKey); len(values) > 0 {
+			rawFlags := strings.Join(values, ",")
+			if len(rawFlags) > 1024 {
+				return nil, api.MakeErr(log, codes.InvalidArgument, "agent feature flags are too long (max 1024 characters)", nil)
+			}
+
+			enabled := []string{}
+			for flag := range strings.SplitSeq(rawFlags, ",") {
+				if flag = strings.TrimSpace(flag); flag != "" {
+					enabled = append(enabled, flag)
+				}
+			}
+
+			rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+				telemetry.FeatureFlags: strings.Join(enabled, ","),
+			})
+			if err := s.featureFlags.RecordAgentFeatureFlags(ctx, callerID, enabled); err != nil {
+				return nil, api.MakeErr(log, codes.Internal, "failed to record agent feature flags", err)
+			}
+		}
+	}
+
 	if agentVersion != "" {
 		if len(agentVersion) > 255 {
 			return nil, api.MakeErr(log, codes.InvalidArgument, "agent version is too long (max 255 characters)", nil)
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
@@ -37,6 +39,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -2068,6 +2071,9 @@ func TestPostStatus(t *testing.T) {
 		expectMsg      string
 		expectVersion  string
 		rateLimiterErr error
+		featureFlags   []string
+		recordFlagsErr error
+		expectFlags    []string
 	}{
 		{
 			name:         "missing caller ID",
@@ -2131,6 +2137,45 @@ func TestPostStatus(t *testing.T) {
 			expectCode:   codes.Internal,
 			expectMsg:    "grpc: error while marshaling: string field contains invalid UTF-8",
 		},
+		{
+			name:          "success with feature flags",
+			request:       &agentv1.PostStatusRequest{AgentVersion: "1.0.0"},
+			createAgent:   true,
+			withCallerID:  true,
+			featureFlags:  []string{"wit-svid, some-future-flag"},
+			expectCode:    codes.OK,
+			expectVersion: "1.0.0",
+			expectFlags:   []string{"wit-svid", "some-future-flag"},
+		},
+		{
+			name:          "success with no feature flags enabled",
+			request:       &agentv1.PostStatusRequest{AgentVersion: "1.0.0"},
+			createAgent:   true,
+			withCallerID:  true,
+			featureFlags:  []string{""},
+			expectCode:    codes.OK,
+			expectVersion: "1.0.0",
+			expectFlags:   []string{},
+		},
+		{
+			name:         "feature flags too long",
+			request:      &agentv1.PostStatusRequest{AgentVersion: "1.0.0"},
+			createAgent:  true,
+			withCallerID: true,
+			featureFlags: []string{strings.Repeat("a", 1025)},
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    "agent feature flags are too long",
+		},
+		{
+			name:           "recording feature flags fails",
+			request:        &agentv1.PostStatusRequest{AgentVersion: "1.0.0"},
+			createAgent:    true,
+			withCallerID:   true,
+			featureFlags:   []string{"wit-svid"},
+			recordFlagsErr: errors.New("oh no"),
+			expectCode:     codes.Internal,
+			expectMsg:      "failed to record agent feature flags: oh no",
+		},
 		{
 			name:           "rate limit fails",
 			request:        &agentv1.PostStatusRequest{AgentVersion: "1.0.0", CurrentBundleSerial: 123},
@@ -2147,6 +2192,7 @@ func TestPostStatus(t *testing.T) {
 
 				test.rateLimiter.count = 1
 				test.rateLimiter.err = tt.rateLimiterErr
+				test.featureFlags.err = tt.recordFlagsErr
 
 				if tt.createAgent {
 					_, err := test.ds.CreateAttestedNode(context.Background(), &common.AttestedNode{
@@ -2160,7 +2206,11 @@ func TestPostStatus(t *testing.T) {
 
 				test.withCallerID = tt.withCallerID
 
-				resp, err := test.client.PostStatus(context.Background(), tt.request)
+				ctx := context.Background()
+				for _, value := range tt.featureFlags {
+					ctx = metadata.AppendToOutgoingContext(ctx, fflag.AgentMetadataKey, value)
+				}
+				resp, err := test.client.PostStatus(ctx, tt.request)
 
 				if tt.expectCode != codes.OK {
 					require.Nil(t, resp)
@@ -2175,6 +2225,7 @@ func TestPostStatus(t *testing.T) {
 					require.NotNil(t, node)
 					require.Equal(t, tt.expectVersion, node.AgentVersion)
 				}
+				require.Equal(t, tt.expectFlags, test.featureFlags.flags[agentID])
 			})
 		}
 	}
@@ -3330,6 +3381,7 @@ type serviceTest struct {
 	rateLimiter  *fakeRateLimiter
 	withCallerID bool
 	pluginCloser func()
+	featureFlags *fakeFeatureFlagsRecorder
 }
 
 func (s *serviceTest) Cleanup() {
@@ -3346,6 +3398,7 @@ func setupServiceTest(t *testing.T, agentSVIDTTL time.Duration, agentSpiffeIdAsS
 	ds := fakedatastore.New(t)
 	cat := fakeservercatalog.New()
 	clk := clock.NewMock(t)
+	featureFlags := &fakeFeatureFlagsRecorder{flags: make(map[spiffeid.ID][]string)}
 
 	service := agent.New(agent.Config{
 		ServerCA:                ca,
@@ -3354,6 +3407,7 @@ func setupServiceTest(t *testing.T, agentSVIDTTL time.Duration, agentSpiffeIdAsS
 		Clock:                   clk,
 		Catalog:                 cat,
 		AgentSpiffeIdAsSelector: agentSpiffeIdAsSelector,
+		FeatureFlags:            featureFlags,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -3362,12 +3416,13 @@ func setupServiceTest(t *testing.T, agentSVIDTTL time.Duration, agentSpiffeIdAsS
 	rateLimiter := &fakeRateLimiter{}
 
 	test := &serviceTest{
-		ca:          ca,
-		ds:          ds,
-		cat:         cat,
-		clk:         clk,
-		logHook:     logHook,
-		rateLimiter: rateLimiter,
+		ca:           ca,
+		ds:           ds,
+		cat:          cat,
+		clk:          clk,
+		logHook:      logHook,
+		rateLimiter:  rateLimiter,
+		featureFlags: featureFlags,
 	}
 
 	overrideContext := func(ctx context.Context) context.Context {
@@ -3582,3 +3637,16 @@ func attest(t *testing.T, stream agentv1.Agent_AttestAgentClient, request *agent
 		return result, err
 	}
 }
+
+type fakeFeatureFlagsRecorder struct {
+	flags map[spiffeid.ID][]string
+	err   error
+}
+
+func (r *fakeFeatureFlagsRecorder) RecordAgentFeatureFlags(_ context.Context, agentID spiffeid.ID, enabled []string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.flags[agentID] = enabled
+	return nil
+}
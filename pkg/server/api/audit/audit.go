@@ -26,9 +26,16 @@ type Logger interface {
 type logger struct {
 	fields logrus.Fields
 	log    logrus.FieldLogger
+	sink   Sink
 }
 
 func New(l logrus.FieldLogger) Logger {
+	return NewWithSink(l, nil)
+}
+
+// NewWithSink returns a Logger that also appends the audit records to the
+// given sink, if not nil.
+func NewWithSink(l logrus.FieldLogger, sink Sink) Logger {
 	return &logger{
 		log: l.WithFields(logrus.Fields{
 			telemetry.Type: "audit",
@@ -36,6 +43,7 @@ func New(l logrus.FieldLogger) Logger {
 			telemetry.Status: "success",
 		}),
 		fields: logrus.Fields{},
+		sink:   sink,
 	}
 }
 
@@ -44,21 +52,28 @@ func (l *logger) AddFields(fields logrus.Fields) {
 }
 
 func (l *logger) Audit() {
-	l.log.WithFields(l.fields).Info(message)
+	l.emit(l.log.WithFields(l.fields))
 }
 
 func (l *logger) AuditWithFields(fields logrus.Fields) {
-	l.log.WithFields(l.fields).WithFields(fields).Info(message)
+	l.emit(l.log.WithFields(l.fields).WithFields(fields))
 }
 
 func (l *logger) AuditWithError(err error) {
 	fields := fieldsFromError(err)
-	l.log.WithFields(l.fields).WithFields(fields).Info(message)
+	l.emit(l.log.WithFields(l.fields).WithFields(fields))
 }
 
 func (l *logger) AuditWithTypesStatus(fields logrus.Fields, s *types.Status) {
 	statusFields := fieldsFromStatus(s)
-	l.log.WithFields(statusFields).WithFields(fields).Info(message)
+	l.emit(l.log.WithFields(statusFields).WithFields(fields))
+}
+
+func (l *logger) emit(entry *logrus.Entry) {
+	entry.Info(message)
+	if l.sink != nil {
+		l.sink.Append(entry.Data)
+	}
 }
 
 func fieldsFromStatus(s *types.Status) logrus.Fields {
@@ -0,0 +1,246 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// MinKeySize is the minimum size of the key the records are hashed with.
+const MinKeySize = 32
+
+// linkPrefix is the prefix of every chain link written to a sink. Lines that
+// do not contain it (e.g. messages from other programs in a syslog file) are
+// ignored by Verify.
+var linkPrefix = []byte(`{"record":`)
+
+// Record is an audit record written to a sink.
+type Record struct {
+	// Seq is the sequence number of the record in the chain, starting at 1.
+	Seq uint64 `json:"seq"`
+
+	// Time is the time the record was written.
+	Time time.Time `json:"time"`
+
+	// PrevHash is the keyed hash of the previous record in the chain. It is
+	// empty for the first record of a chain.
+	PrevHash string `json:"prev_hash"`
+
+	// Fields are the audit log fields.
+	Fields map[string]string `json:"fields"`
+}
+
+// link is a record as written to a sink, along with its keyed hash. The
+// record is kept raw so that the hash is computed over the exact bytes
+// written.
+type link struct {
+	Record json.RawMessage `json:"record"`
+	Hash   string          `json:"hash"`
+}
+
+// LoadKey loads the key the records are hashed with from a file. The key is
+// the content of the file, which must be at least MinKeySize bytes long.
+func LoadKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit log key: %w", err)
+	}
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("audit log key must be at least %d bytes long, got %d", MinKeySize, len(key))
+	}
+	return key, nil
+}
+
+// newLink encodes the record and computes its hash.
+func newLink(key []byte, record *Record) ([]byte, string, error) {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := hashRecord(key, recordBytes)
+	line, err := json.Marshal(link{
+		Record: recordBytes,
+		Hash:   hash,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return line, hash, nil
+}
+
+// parseLink parses a chain link, verifying its hash.
+func parseLink(key []byte, line []byte) (*Record, string, error) {
+	var l link
+	if err := json.Unmarshal(line, &l); err != nil {
+		return nil, "", fmt.Errorf("malformed record: %w", err)
+	}
+	if !hmac.Equal([]byte(hashRecord(key, l.Record)), []byte(l.Hash)) {
+		return nil, "", errors.New("record hash does not match")
+	}
+	record := new(Record)
+	if err := json.Unmarshal(l.Record, record); err != nil {
+		return nil, "", fmt.Errorf("malformed record: %w", err)
+	}
+	return record, l.Hash, nil
+}
+
+// hashRecord returns the HMAC-SHA256 of the record. Since the hash is keyed,
+// records cannot be rewritten, nor the chain rehashed, without the key.
+func hashRecord(key, recordBytes []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(recordBytes)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Checkpoint identifies the last record of a chain. Sinks log a checkpoint,
+// outside of their output, when they are closed.
+type Checkpoint struct {
+	// Seq is the sequence number of the record.
+	Seq uint64
+
+	// Hash is the hash of the record.
+	Hash string
+}
+
+// VerifyOptions configures Verify.
+type VerifyOptions struct {
+	// Key is the key the records were hashed with.
+	Key []byte
+
+	// Checkpoint, if set, is a record the chain must contain. Removing the
+	// last records of a chain does not break it, so the records up to the
+	// last checkpoint logged by the sink are only known to be complete when
+	// the checkpoint is verified.
+	Checkpoint *Checkpoint
+
+	// AllowNewChains allows records to start new chains after the first one.
+	// The syslog and webhook outputs start a new chain every time the server
+	// starts, but removing the records up to the start of a chain leaves the
+	// same trace, so each chain start must be accounted for.
+	AllowNewChains bool
+}
+
+// Chain describes a chain of records found by Verify.
+type Chain struct {
+	// Line is the line of the first record of the chain.
+	Line int
+
+	// Start is the time the first record of the chain was written.
+	Start time.Time
+
+	// Records is the number of records in the chain.
+	Records int
+}
+
+// VerifyResult is the result of a successful chain verification.
+type VerifyResult struct {
+	// Records is the number of verified records.
+	Records int
+
+	// Chains are the chains the records belong to, in order. A new chain is
+	// started when a sink cannot resume the chain it was writing to, e.g.
+	// when a server writing to syslog or a webhook is restarted.
+	Chains []Chain
+}
+
+// Verify reads the records written by a sink and verifies that they form
+// an unbroken chain, i.e. that no record was edited, reordered, or removed,
+// except at the end of the chain, which is only detected up to the checkpoint
+// if one is set. Records starting a new chain after the first one are
+// rejected unless AllowNewChains is set.
+func Verify(r io.Reader, opts VerifyOptions) (*VerifyResult, error) {
+	if len(opts.Key) == 0 {
+		return nil, errors.New("a key is required to verify the audit log")
+	}
+
+	result := new(VerifyResult)
+	checkpointFound := false
+
+	var prev *Record
+	var prevHash string
+	br := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if i := bytes.Index(line, linkPrefix); i >= 0 {
+			record, hash, err := parseLink(opts.Key, bytes.TrimSpace(line[i:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+
+			switch {
+			case record.Seq == 1 && record.PrevHash == "":
+				if prev != nil && !opts.AllowNewChains {
+					return nil, fmt.Errorf("line %d: unexpected start of a new chain", lineNum)
+				}
+				result.Chains = append(result.Chains, Chain{
+					Line:  lineNum,
+					Start: record.Time,
+				})
+			case prev == nil:
+				return nil, fmt.Errorf("line %d: first record does not start a chain", lineNum)
+			case record.Seq != prev.Seq+1:
+				return nil, fmt.Errorf("line %d: expected sequence number %d, got %d", lineNum, prev.Seq+1, record.Seq)
+			case record.PrevHash != prevHash:
+				return nil, fmt.Errorf("line %d: previous hash does not match the hash of the preceding record", lineNum)
+			}
+
+			result.Records++
+			result.Chains[len(result.Chains)-1].Records++
+			prev, prevHash = record, hash
+			if opts.Checkpoint != nil && record.Seq == opts.Checkpoint.Seq && hash == opts.Checkpoint.Hash {
+				checkpointFound = true
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			if opts.Checkpoint != nil && !checkpointFound {
+				return nil, fmt.Errorf("checkpoint record %d not found: records were removed from the end of the chain", opts.Checkpoint.Seq)
+			}
+			return result, nil
+		}
+	}
+}
+
+// lastLink returns the last record read from r, along with its hash, or nil
+// if there are no records. It also returns the size of the complete lines
+// read, which is less than the size of r when the last line is torn, i.e.
+// not terminated by a newline because a write was interrupted. The torn line
+// is ignored.
+func lastLink(key []byte, r io.Reader) (*Record, string, int64, error) {
+	var last []byte
+	var size int64
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", 0, err
+		}
+		size += int64(len(line))
+		if i := bytes.Index(line, linkPrefix); i >= 0 {
+			last = bytes.TrimSpace(line[i:])
+		}
+	}
+	if last == nil {
+		return nil, "", size, nil
+	}
+	record, hash, err := parseLink(key, last)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return record, hash, size, nil
+}
@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Sink receives the audit records emitted by a Logger, in addition to the
+// log.
+type Sink interface {
+	Append(fields logrus.Fields)
+}
+
+// SinkConfig configures the output of a ChainSink. Exactly one output must be
+// configured.
+type SinkConfig struct {
+	// KeyFile is the path to the key the records are hashed with. See
+	// LoadKey.
+	KeyFile string
+
+	File    *FileSinkConfig
+	Syslog  *SyslogSinkConfig
+	Webhook *WebhookSinkConfig
+}
+
+// FileSinkConfig configures a sink that appends records to a file.
+type FileSinkConfig struct {
+	// Path is the path to the file. The chain is resumed from the last record
+	// in the file, if any.
+	Path string
+}
+
+// SyslogSinkConfig configures a sink that writes records to syslog.
+type SyslogSinkConfig struct {
+	// Network and Address of the syslog server. If both are empty, the local
+	// syslog server is used.
+	Network string
+	Address string
+
+	// Tag of the syslog messages.
+	Tag string
+}
+
+// WebhookSinkConfig configures a sink that POSTs each record to a URL.
+type WebhookSinkConfig struct {
+	// URL the records are posted to.
+	URL string
+
+	// Timeout of each request.
+	Timeout time.Duration
+
+	// QueueSize is the number of records that can be queued while waiting to
+	// be posted. Records are dropped when the queue is full.
+	QueueSize int
+}
+
+// sinkOutput is the output of a ChainSink.
+type sinkOutput interface {
+	Write(line []byte) error
+	Close() error
+}
+
+// ChainSink writes audit records to a dedicated output. Each record includes
+// the keyed hash of the previous one, so that edited, reordered, or removed
+// records are detected by Verify. The checkpoint of the chain is logged when
+// the sink is closed, so that records removed from the end of the chain are
+// detected too.
+type ChainSink struct {
+	log logrus.FieldLogger
+	out sinkOutput
+	key []byte
+
+	mu       sync.Mutex
+	seq      uint64
+	prevHash string
+}
+
+// NewChainSink creates a sink writing to the configured output. Records that
+// fail to be written are logged and break the chain, so the loss is detected
+// when the chain is verified.
+func NewChainSink(config SinkConfig, log logrus.FieldLogger) (*ChainSink, error) {
+	var outputs int
+	for _, set := range []bool{config.File != nil, config.Syslog != nil, config.Webhook != nil} {
+		if set {
+			outputs++
+		}
+	}
+	if outputs != 1 {
+		return nil, errors.New("exactly one audit log sink output must be configured")
+	}
+	if config.KeyFile == "" {
+		return nil, errors.New("audit log key file is required")
+	}
+	key, err := LoadKey(config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &ChainSink{
+		log: log,
+		key: key,
+	}
+
+	switch {
+	case config.File != nil:
+		out, last, lastHash, err := openFileOutput(config.File, key, log)
+		if err != nil {
+			return nil, err
+		}
+		sink.out = out
+		if last != nil {
+			sink.seq = last.Seq
+			sink.prevHash = lastHash
+		}
+	case config.Syslog != nil:
+		out, err := openSyslogOutput(config.Syslog)
+		if err != nil {
+			return nil, err
+		}
+		sink.out = out
+	case config.Webhook != nil:
+		out, err := newWebhookOutput(config.Webhook, log)
+		if err != nil {
+			return nil, err
+		}
+		sink.out = out
+	}
+
+	return sink, nil
+}
+
+// Append writes an audit record with the given fields.
+func (s *ChainSink) Append(fields logrus.Fields) {
+	record := &Record{
+		Time:   time.Now().UTC(),
+		Fields: make(map[string]string, len(fields)),
+	}
+	for k, v := range fields {
+		switch v := v.(type) {
+		case string:
+			record.Fields[k] = v
+		case error:
+			record.Fields[k] = v.Error()
+		default:
+			record.Fields[k] = fmt.Sprint(v)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Seq = s.seq + 1
+	record.PrevHash = s.prevHash
+	line, hash, err := newLink(s.key, record)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to encode audit record")
+		return
+	}
+	s.seq = record.Seq
+	s.prevHash = hash
+
+	if err := s.out.Write(line); err != nil {
+		s.log.WithError(err).Error("Failed to write audit record")
+	}
+}
+
+// Close closes the sink output and logs the checkpoint of the chain, which
+// can be passed to Verify.
+func (s *ChainSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.out.Close()
+	if s.seq > 0 {
+		s.log.WithFields(logrus.Fields{
+			telemetry.SequenceNumber: s.seq,
+			telemetry.Hash:           s.prevHash,
+		}).Info("Audit log chain checkpoint")
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+type fileOutput struct {
+	f *os.File
+}
+
+// openFileOutput opens the file for appending, returning the last record in
+// the file, if any, so the chain can be resumed. A torn last line, left by a
+// write interrupted by a crash, is truncated.
+func openFileOutput(config *FileSinkConfig, key []byte, log logrus.FieldLogger) (*fileOutput, *Record, string, error) {
+	if config.Path == "" {
+		return nil, nil, "", errors.New("audit log file path is required")
+	}
+
+	f, err := os.OpenFile(config.Path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to open audit log file: %w", err)
+	}
+
+	last, lastHash, size, err := lastLink(key, f)
+	if err != nil {
+		f.Close()
+		return nil, nil, "", fmt.Errorf("failed to resume audit log chain from %q: %w", config.Path, err)
+	}
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, nil, "", err
+	}
+	if end > size {
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, nil, "", fmt.Errorf("failed to truncate torn record from audit log file: %w", err)
+		}
+		log.WithField(telemetry.Path, config.Path).Warn("Truncated torn record at the end of the audit log file")
+	}
+
+	return &fileOutput{f: f}, last, lastHash, nil
+}
+
+func (o *fileOutput) Write(line []byte) error {
+	_, err := o.f.Write(append(line, '\n'))
+	return err
+}
+
+func (o *fileOutput) Close() error {
+	return o.f.Close()
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package audit
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+type syslogOutput struct {
+	w *syslog.Writer
+}
+
+func openSyslogOutput(config *SyslogSinkConfig) (*syslogOutput, error) {
+	w, err := syslog.Dial(config.Network, config.Address, syslog.LOG_INFO|syslog.LOG_AUTH, config.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogOutput{w: w}, nil
+}
+
+func (o *syslogOutput) Write(line []byte) error {
+	return o.w.Info(string(line))
+}
+
+func (o *syslogOutput) Close() error {
+	return o.w.Close()
+}
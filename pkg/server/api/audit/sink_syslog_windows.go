@@ -0,0 +1,11 @@
+//go:build windows
+
+package audit
+
+import (
+	"errors"
+)
+
+func openSyslogOutput(*SyslogSinkConfig) (sinkOutput, error) {
+	return nil, errors.New("syslog audit log sink is not supported on this platform")
+}
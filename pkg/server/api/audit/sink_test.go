@@ -0,0 +1,443 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewChainSink(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	keyPath := writeKey(t)
+	shortKeyPath := filepath.Join(dir, "short.key")
+	require.NoError(t, os.WriteFile(shortKeyPath, []byte("short"), 0600))
+
+	for _, tt := range []struct {
+		name   string
+		config audit.SinkConfig
+		expErr string
+	}{
+		{
+			name:   "no output",
+			expErr: "exactly one audit log sink output must be configured",
+		},
+		{
+			name: "more than one output",
+			config: audit.SinkConfig{
+				KeyFile: keyPath,
+				File:    &audit.FileSinkConfig{Path: path},
+				Webhook: &audit.WebhookSinkConfig{URL: "https://audit.example.org"},
+			},
+			expErr: "exactly one audit log sink output must be configured",
+		},
+		{
+			name: "no key file",
+			config: audit.SinkConfig{
+				File: &audit.FileSinkConfig{Path: path},
+			},
+			expErr: "audit log key file is required",
+		},
+		{
+			name: "key too short",
+			config: audit.SinkConfig{
+				KeyFile: shortKeyPath,
+				File:    &audit.FileSinkConfig{Path: path},
+			},
+			expErr: "audit log key must be at least 32 bytes long, got 5",
+		},
+		{
+			name: "file without path",
+			config: audit.SinkConfig{
+				KeyFile: keyPath,
+				File:    &audit.FileSinkConfig{},
+			},
+			expErr: "audit log file path is required",
+		},
+		{
+			name: "webhook with invalid scheme",
+			config: audit.SinkConfig{
+				KeyFile: keyPath,
+				Webhook: &audit.WebhookSinkConfig{URL: "ftp://audit.example.org"},
+			},
+			expErr: "invalid audit log webhook URL: scheme must be http or https",
+		},
+		{
+			name: "webhook without host",
+			config: audit.SinkConfig{
+				KeyFile: keyPath,
+				Webhook: &audit.WebhookSinkConfig{URL: "https:///audit"},
+			},
+			expErr: "invalid audit log webhook URL: host is required",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := audit.NewChainSink(tt.config, log)
+			require.EqualError(t, err, tt.expErr)
+			require.Nil(t, sink)
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	log, logHook := test.NewNullLogger()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	config := audit.SinkConfig{
+		KeyFile: writeKey(t),
+		File:    &audit.FileSinkConfig{Path: path},
+	}
+
+	sink, err := audit.NewChainSink(config, log)
+	require.NoError(t, err)
+	auditLog := audit.NewWithSink(log.WithField(telemetry.Method, "MintX509SVID"), sink)
+	auditLog.AddFields(logrus.Fields{telemetry.SPIFFEID: "spiffe://example.org/workload"})
+	auditLog.Audit()
+	auditLog.AuditWithError(status.Error(codes.PermissionDenied, "denied"))
+	require.NoError(t, sink.Close())
+
+	// The chain is resumed when the file is reopened
+	sink, err = audit.NewChainSink(config, log)
+	require.NoError(t, err)
+	sink.Append(logrus.Fields{"a": "1"})
+	require.NoError(t, sink.Close())
+
+	// Records are also logged, along with the checkpoint of the chain when
+	// the sink is closed
+	entries := logHook.AllEntries()
+	require.Len(t, entries, 4)
+	assert.Equal(t, "Audit log chain checkpoint", entries[2].Message)
+	assert.Equal(t, uint64(2), entries[2].Data[telemetry.SequenceNumber])
+	assert.Equal(t, "Audit log chain checkpoint", entries[3].Message)
+	assert.Equal(t, uint64(3), entries[3].Data[telemetry.SequenceNumber])
+
+	records := readRecords(t, path)
+	require.Len(t, records, 3)
+	assert.Contains(t, records[0], `"method":"MintX509SVID"`)
+	assert.Contains(t, records[0], `"spiffe_id":"spiffe://example.org/workload"`)
+	assert.Contains(t, records[0], `"status":"success"`)
+	assert.Contains(t, records[0], `"type":"audit"`)
+	assert.Contains(t, records[1], `"status":"error"`)
+	assert.Contains(t, records[1], `"status_code":"PermissionDenied"`)
+	assert.Contains(t, records[1], `"status_message":"denied"`)
+
+	result, err := audit.Verify(strings.NewReader(strings.Join(records, "\n")), audit.VerifyOptions{
+		Key: testKey,
+		Checkpoint: &audit.Checkpoint{
+			Seq:  3,
+			Hash: entries[3].Data[telemetry.Hash].(string),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &audit.VerifyResult{Records: 3, Chains: []audit.Chain{{Line: 1, Records: 3}}}, withoutStartTimes(t, result))
+}
+
+func TestFileSinkCannotResumeCorruptedChain(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte(`{"record":{"seq":1},"hash":"bad"}`+"\n"), 0600))
+
+	_, err := audit.NewChainSink(audit.SinkConfig{KeyFile: writeKey(t), File: &audit.FileSinkConfig{Path: path}}, log)
+	require.EqualError(t, err, `failed to resume audit log chain from "`+path+`": record hash does not match`)
+}
+
+func TestFileSinkTruncatesTornRecord(t *testing.T) {
+	log, logHook := test.NewNullLogger()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	records := appendRecords(t, 2)
+	torn := records[1][:len(records[1])/2]
+	require.NoError(t, os.WriteFile(path, []byte(records[0]+"\n"+torn), 0600))
+
+	sink, err := audit.NewChainSink(audit.SinkConfig{KeyFile: writeKey(t), File: &audit.FileSinkConfig{Path: path}}, log)
+	require.NoError(t, err)
+	require.Len(t, logHook.AllEntries(), 1)
+	assert.Equal(t, logrus.WarnLevel, logHook.LastEntry().Level)
+	assert.Equal(t, "Truncated torn record at the end of the audit log file", logHook.LastEntry().Message)
+
+	// The chain is resumed from the last complete record
+	sink.Append(logrus.Fields{"a": "x"})
+	require.NoError(t, sink.Close())
+
+	records = readRecords(t, path)
+	require.Len(t, records, 2)
+	result, err := audit.Verify(strings.NewReader(strings.Join(records, "\n")), audit.VerifyOptions{Key: testKey})
+	require.NoError(t, err)
+	assert.Equal(t, &audit.VerifyResult{Records: 2, Chains: []audit.Chain{{Line: 1, Records: 2}}}, withoutStartTimes(t, result))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, string(body))
+		if len(received) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	log, logHook := test.NewNullLogger()
+	sink, err := audit.NewChainSink(audit.SinkConfig{
+		KeyFile: writeKey(t),
+		Webhook: &audit.WebhookSinkConfig{URL: server.URL},
+	}, log)
+	require.NoError(t, err)
+
+	sink.Append(logrus.Fields{"a": "1"})
+	sink.Append(logrus.Fields{"a": "2"})
+
+	// Closing the sink waits for the queued records to be posted
+	require.NoError(t, sink.Close())
+	entries := logHook.AllEntries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Failed to write audit record", entries[0].Message)
+	assert.EqualError(t, entries[0].Data[logrus.ErrorKey].(error), "webhook returned unexpected status: 503 Service Unavailable")
+	assert.Equal(t, "Audit log chain checkpoint", entries[1].Message)
+
+	mu.Lock()
+	defer mu.Unlock()
+	result, err := audit.Verify(strings.NewReader(strings.Join(received, "\n")), audit.VerifyOptions{Key: testKey})
+	require.NoError(t, err)
+	assert.Equal(t, &audit.VerifyResult{Records: 2, Chains: []audit.Chain{{Line: 1, Records: 2}}}, withoutStartTimes(t, result))
+}
+
+func TestWebhookSinkQueueFull(t *testing.T) {
+	requested := make(chan string, 3)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requested <- string(body)
+		<-release
+	}))
+	defer server.Close()
+
+	log, logHook := test.NewNullLogger()
+	sink, err := audit.NewChainSink(audit.SinkConfig{
+		KeyFile: writeKey(t),
+		Webhook: &audit.WebhookSinkConfig{URL: server.URL, QueueSize: 1},
+	}, log)
+	require.NoError(t, err)
+
+	// The first record is being posted, which blocks the queue
+	sink.Append(logrus.Fields{"a": "1"})
+	first := <-requested
+
+	// Appending does not block once the queue is full. The record is dropped.
+	sink.Append(logrus.Fields{"a": "2"})
+	sink.Append(logrus.Fields{"a": "3"})
+	require.Len(t, logHook.AllEntries(), 1)
+	assert.Equal(t, "Failed to write audit record", logHook.LastEntry().Message)
+	assert.EqualError(t, logHook.LastEntry().Data[logrus.ErrorKey].(error), "webhook queue is full; 1 record(s) dropped")
+
+	close(release)
+	require.NoError(t, sink.Close())
+	second := <-requested
+
+	result, err := audit.Verify(strings.NewReader(first+"\n"+second), audit.VerifyOptions{Key: testKey})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Records)
+}
+
+func TestVerify(t *testing.T) {
+	records := appendRecords(t, 4)
+	restarted := appendRecords(t, 2)
+	checkpoint := &audit.Checkpoint{Seq: 4, Hash: linkHash(t, records[3])}
+
+	for _, tt := range []struct {
+		name      string
+		lines     []string
+		opts      audit.VerifyOptions
+		expResult *audit.VerifyResult
+		expErr    string
+	}{
+		{
+			name:      "empty",
+			expResult: &audit.VerifyResult{},
+		},
+		{
+			name:      "valid chain",
+			lines:     records,
+			expResult: &audit.VerifyResult{Records: 4, Chains: []audit.Chain{{Line: 1, Records: 4}}},
+		},
+		{
+			name:   "no key",
+			lines:  records,
+			opts:   audit.VerifyOptions{Key: []byte{}},
+			expErr: "a key is required to verify the audit log",
+		},
+		{
+			name:   "wrong key",
+			lines:  records,
+			opts:   audit.VerifyOptions{Key: bytes.Repeat([]byte("x"), audit.MinKeySize)},
+			expErr: "line 1: record hash does not match",
+		},
+		{
+			name:      "last records removed",
+			lines:     records[:2],
+			expResult: &audit.VerifyResult{Records: 2, Chains: []audit.Chain{{Line: 1, Records: 2}}},
+		},
+		{
+			name:      "checkpoint found",
+			lines:     records,
+			opts:      audit.VerifyOptions{Checkpoint: checkpoint},
+			expResult: &audit.VerifyResult{Records: 4, Chains: []audit.Chain{{Line: 1, Records: 4}}},
+		},
+		{
+			name:   "last records removed up to the checkpoint",
+			lines:  records[:2],
+			opts:   audit.VerifyOptions{Checkpoint: checkpoint},
+			expErr: "checkpoint record 4 not found: records were removed from the end of the chain",
+		},
+		{
+			name:   "checkpoint of another chain",
+			lines:  restarted,
+			opts:   audit.VerifyOptions{Checkpoint: &audit.Checkpoint{Seq: 2, Hash: linkHash(t, records[1])}},
+			expErr: "checkpoint record 2 not found: records were removed from the end of the chain",
+		},
+		{
+			name:   "new chain started",
+			lines:  append(append([]string{}, records...), restarted...),
+			expErr: "line 5: unexpected start of a new chain",
+		},
+		{
+			name:  "new chain started when allowed",
+			lines: append(append([]string{}, records...), restarted...),
+			opts:  audit.VerifyOptions{AllowNewChains: true},
+			expResult: &audit.VerifyResult{Records: 6, Chains: []audit.Chain{
+				{Line: 1, Records: 4},
+				{Line: 5, Records: 2},
+			}},
+		},
+		{
+			name:   "records removed up to the start of a new chain",
+			lines:  append([]string{records[0]}, restarted...),
+			expErr: "line 2: unexpected start of a new chain",
+		},
+		{
+			name: "other lines are ignored",
+			lines: []string{
+				"Oct 17 00:00:00 host spire-server[1]: " + records[0],
+				"Oct 17 00:00:01 host sshd[2]: Accepted publickey",
+				"",
+				"Oct 17 00:00:02 host spire-server[1]: " + records[1],
+			},
+			expResult: &audit.VerifyResult{Records: 2, Chains: []audit.Chain{{Line: 1, Records: 2}}},
+		},
+		{
+			name:   "record edited",
+			lines:  []string{records[0], strings.Replace(records[1], `"a":"2"`, `"a":"x"`, 1), records[2]},
+			expErr: "line 2: record hash does not match",
+		},
+		{
+			name:   "record removed",
+			lines:  []string{records[0], records[2], records[3]},
+			expErr: "line 2: expected sequence number 2, got 3",
+		},
+		{
+			name:   "records reordered",
+			lines:  []string{records[0], records[2], records[1], records[3]},
+			expErr: "line 2: expected sequence number 2, got 3",
+		},
+		{
+			name:   "first records removed",
+			lines:  records[1:],
+			expErr: "line 1: first record does not start a chain",
+		},
+		{
+			name:   "record replaced with record from another chain",
+			lines:  []string{records[0], restarted[1]},
+			expErr: "line 2: previous hash does not match the hash of the preceding record",
+		},
+		{
+			name:   "malformed record",
+			lines:  []string{records[0], `{"record":`},
+			expErr: "line 2: malformed record: unexpected end of JSON input",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.Key == nil {
+				tt.opts.Key = testKey
+			}
+			result, err := audit.Verify(strings.NewReader(strings.Join(tt.lines, "\n")), tt.opts)
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				require.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expResult, withoutStartTimes(t, result))
+		})
+	}
+}
+
+// appendRecords appends count records to a new file sink and returns them.
+func appendRecords(t *testing.T, count int) []string {
+	log, _ := test.NewNullLogger()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewChainSink(audit.SinkConfig{KeyFile: writeKey(t), File: &audit.FileSinkConfig{Path: path}}, log)
+	require.NoError(t, err)
+	for i := 1; i <= count; i++ {
+		sink.Append(logrus.Fields{"a": string(rune('0' + i))})
+	}
+	require.NoError(t, sink.Close())
+	return readRecords(t, path)
+}
+
+// linkHash returns the hash of a record written by a sink.
+func linkHash(t *testing.T, line string) string {
+	var link struct {
+		Hash string `json:"hash"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(line), &link))
+	return link.Hash
+}
+
+// testKey is the key written by writeKey.
+var testKey = bytes.Repeat([]byte("k"), audit.MinKeySize)
+
+// writeKey writes the test key to a file and returns its path.
+func writeKey(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "audit.key")
+	require.NoError(t, os.WriteFile(path, testKey, 0600))
+	return path
+}
+
+// withoutStartTimes clears the start times of the chains, which depend on the
+// time the records were written.
+func withoutStartTimes(t *testing.T, result *audit.VerifyResult) *audit.VerifyResult {
+	for i := range result.Chains {
+		assert.False(t, result.Chains[i].Start.IsZero())
+		result.Chains[i].Start = time.Time{}
+	}
+	return result
+}
+
+func readRecords(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(string(bytes.TrimSpace(data)), "\n")
+}
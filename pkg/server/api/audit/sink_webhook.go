@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+const (
+	defaultWebhookTimeout   = 5 * time.Second
+	defaultWebhookQueueSize = 1024
+)
+
+// webhookOutput posts the records from a bounded queue, so slow or
+// unavailable webhooks do not block the audited API calls. Records are
+// dropped when the queue is full, which breaks the chain.
+type webhookOutput struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+	log     logrus.FieldLogger
+
+	queue   chan []byte
+	dropped atomic.Uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newWebhookOutput(config *WebhookSinkConfig, log logrus.FieldLogger) (*webhookOutput, error) {
+	u, err := url.Parse(config.URL)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("invalid audit log webhook URL: %w", err)
+	case u.Scheme != "https" && u.Scheme != "http":
+		return nil, errors.New("invalid audit log webhook URL: scheme must be http or https")
+	case u.Host == "":
+		return nil, errors.New("invalid audit log webhook URL: host is required")
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	o := &webhookOutput{
+		url:     config.URL,
+		timeout: timeout,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		log:    log,
+		queue:  make(chan []byte, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	o.wg.Add(1)
+	go o.run()
+	return o, nil
+}
+
+// Write queues the record to be posted. It fails without blocking if the
+// queue is full.
+func (o *webhookOutput) Write(line []byte) error {
+	select {
+	case o.queue <- line:
+		return nil
+	default:
+		return fmt.Errorf("webhook queue is full; %d record(s) dropped", o.dropped.Add(1))
+	}
+}
+
+// Close stops accepting records and waits for the queued ones to be posted,
+// for up to the request timeout. Records still queued after that are
+// dropped.
+func (o *webhookOutput) Close() error {
+	close(o.queue)
+	timer := time.AfterFunc(o.timeout, o.cancel)
+	o.wg.Wait()
+	timer.Stop()
+	o.cancel()
+	o.client.CloseIdleConnections()
+	return nil
+}
+
+func (o *webhookOutput) run() {
+	defer o.wg.Done()
+	var unsent int
+	for line := range o.queue {
+		if o.ctx.Err() != nil {
+			unsent++
+			continue
+		}
+		if err := o.post(line); err != nil {
+			o.log.WithError(err).Error("Failed to write audit record")
+		}
+	}
+	if unsent > 0 {
+		o.log.WithField(telemetry.Count, unsent).Error("Failed to write queued audit records before closing the webhook output")
+	}
+}
+
+func (o *webhookOutput) post(line []byte) error {
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodPost, o.url, bytes.NewReader(line))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned unexpected status: %s", resp.Status)
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -159,6 +161,124 @@ func RegistrationEntriesToProto(es []*common.RegistrationEntry) ([]*types.Entry,
 	return pbs, nil
 }
 
+// EntryTemplateToProto converts an entry template into the types Entry the
+// entry template is sent to the agents as
+func EntryTemplateToProto(t *common.EntryTemplate) (*types.Entry, error) {
+	if t == nil {
+		return nil, errors.New("missing entry template")
+	}
+
+	tmpl, err := entrytemplate.Parse(t.SpiffeIdTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID template: %w", err)
+	}
+
+	parentID, err := spiffeid.FromString(t.ParentId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent ID: %w", err)
+	}
+
+	var federatesWith []string
+	if len(t.FederatesWith) > 0 {
+		federatesWith = make([]string, 0, len(t.FederatesWith))
+		for _, trustDomainID := range t.FederatesWith {
+			td, err := spiffeid.TrustDomainFromString(trustDomainID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid federated trust domain: %w", err)
+			}
+			federatesWith = append(federatesWith, td.Name())
+		}
+	}
+
+	return &types.Entry{
+		Id: entrytemplate.EntryID(t.TemplateId),
+		SpiffeId: &types.SPIFFEID{
+			TrustDomain: tmpl.TrustDomain().Name(),
+			Path:        tmpl.Path(),
+		},
+		ParentId:      ProtoFromID(parentID),
+		Selectors:     ProtoFromSelectors(t.Selectors),
+		X509SvidTtl:   t.X509SvidTtl,
+		FederatesWith: federatesWith,
+		DnsNames:      slices.Clone(t.DnsNames),
+		JwtSvidTtl:    t.JwtSvidTtl,
+		Hint:          t.Hint,
+		CreatedAt:     t.CreatedAt,
+	}, nil
+}
+
+// ValidateEntryTemplate validates an entry template to be created, returning
+// a normalized copy of it. Entry templates are rendered by the agents for the
+// workloads they attest, so they cannot be used for node entries.
+func ValidateEntryTemplate(ctx context.Context, td spiffeid.TrustDomain, t *common.EntryTemplate) (*common.EntryTemplate, error) {
+	if t == nil {
+		return nil, errors.New("missing entry template")
+	}
+
+	parentID, err := spiffeid.FromString(t.ParentId)
+	if err == nil {
+		parentID, err = TrustDomainMemberIDFromProto(ctx, td, ProtoFromID(parentID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent ID: %w", err)
+	}
+	if parentID.Path() == idutil.ServerIDPath {
+		return nil, errors.New("invalid parent ID: entry templates cannot be used for node entries")
+	}
+
+	tmpl, err := entrytemplate.Parse(t.SpiffeIdTemplate)
+	if err == nil {
+		tmpl, err = TrustDomainWorkloadTemplateFromProto(td, &types.SPIFFEID{
+			TrustDomain: tmpl.TrustDomain().Name(),
+			Path:        tmpl.Path(),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID template: %w", err)
+	}
+
+	if len(t.Selectors) == 0 {
+		return nil, errors.New("selector list is empty")
+	}
+	selectors, err := SelectorsFromProto(ProtoFromSelectors(t.Selectors))
+	if err != nil {
+		return nil, err
+	}
+
+	dnsNames := make([]string, 0, len(t.DnsNames))
+	for _, dnsName := range t.DnsNames {
+		if err := x509util.ValidateLabel(dnsName); err != nil {
+			return nil, fmt.Errorf("invalid DNS name: %w", err)
+		}
+		dnsNames = append(dnsNames, dnsName)
+	}
+
+	federatesWith := make([]string, 0, len(t.FederatesWith))
+	for _, trustDomainName := range t.FederatesWith {
+		td, err := spiffeid.TrustDomainFromString(trustDomainName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid federated trust domain: %w", err)
+		}
+		federatesWith = append(federatesWith, td.IDString())
+	}
+
+	if len(t.Hint) > hintMaximumLength {
+		return nil, fmt.Errorf("hint is too long, max length is %d characters", hintMaximumLength)
+	}
+
+	return &common.EntryTemplate{
+		TemplateId:       t.TemplateId,
+		Selectors:        selectors,
+		ParentId:         parentID.String(),
+		SpiffeIdTemplate: tmpl.String(),
+		X509SvidTtl:      t.X509SvidTtl,
+		JwtSvidTtl:       t.JwtSvidTtl,
+		FederatesWith:    federatesWith,
+		DnsNames:         dnsNames,
+		Hint:             t.Hint,
+	}, nil
+}
+
 // RegistrationEntryToProto converts RegistrationEntry into types Entry
 func RegistrationEntryToProto(e *common.RegistrationEntry) (*types.Entry, error) {
 	if e == nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
@@ -19,6 +20,7 @@ import (
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -568,9 +570,28 @@ func (s *Service) fetchEntries(ctx context.Context, log logrus.FieldLogger) ([]a
 		return nil, api.MakeErr(log, codes.Internal, "failed to fetch entries", err)
 	}
 
+	// Agents that don't render entry templates would use the template
+	// entries as regular entries, so they are kept on the server.
+	if !supportsEntryTemplates(ctx) {
+		supported := make([]api.ReadOnlyEntry, 0, len(entries))
+		for _, entry := range entries {
+			if !entrytemplate.IsTemplateEntryID(entry.GetId()) {
+				supported = append(supported, entry)
+			}
+		}
+		entries = supported
+	}
+
 	return entries, nil
 }
 
+// supportsEntryTemplates returns whether the caller advertised that it renders
+// entry templates.
+func supportsEntryTemplates(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && slices.Contains(md.Get(entrytemplate.AgentMetadataKey), "true")
+}
+
 func applyMask(e *types.Entry, mask *types.EntryMask) {
 	if mask == nil {
 		return
@@ -17,6 +17,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/entry/v1"
@@ -31,6 +32,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -2909,6 +2911,33 @@ func TestGetAuthorizedEntries(t *testing.T) {
 	}
 }
 
+func TestGetAuthorizedEntriesTemplateSupport(t *testing.T) {
+	entry := &types.Entry{
+		Id:       "entry-1",
+		ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/bar"},
+	}
+	templateEntry := &types.Entry{
+		Id:       "template:template-1",
+		ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{ .k8s.ns }}"},
+	}
+
+	test := setupServiceTest(t, fakedatastore.New(t))
+	defer test.Cleanup()
+	test.ef.entries = []*types.Entry{entry, templateEntry}
+
+	// Template entries are kept from agents that don't render them
+	resp, err := test.client.GetAuthorizedEntries(ctx, &entryv1.GetAuthorizedEntriesRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, []*types.Entry{entry}, resp.Entries)
+
+	supportCtx := metadata.AppendToOutgoingContext(ctx, entrytemplate.AgentMetadataKey, "true")
+	resp, err = test.client.GetAuthorizedEntries(supportCtx, &entryv1.GetAuthorizedEntriesRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, []*types.Entry{entry, templateEntry}, resp.Entries)
+}
+
 func TestSyncAuthorizedEntries(t *testing.T) {
 	entry1 := &types.Entry{
 		Id:          "entry-1",
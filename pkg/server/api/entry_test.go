@@ -113,6 +113,228 @@ func TestRegistrationEntryToProto(t *testing.T) {
 	}
 }
 
+func TestEntryTemplateToProto(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		template    *common.EntryTemplate
+		err         string
+		expectEntry *types.Entry
+	}{
+		{
+			name: "success",
+			template: &common.EntryTemplate{
+				TemplateId:       "template1",
+				ParentId:         "spiffe://example.org/foo",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+				X509SvidTtl:      70,
+				JwtSvidTtl:       80,
+				Selectors: []*common.Selector{
+					{Type: "k8s", Value: "sa:default"},
+				},
+				FederatesWith: []string{"spiffe://domain1.com", "domain2.com"},
+				DnsNames:      []string{"dns1"},
+				Hint:          "external",
+				CreatedAt:     1678731397,
+			},
+			expectEntry: &types.Entry{
+				Id:          "template:template1",
+				ParentId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{ .k8s.ns }}"},
+				X509SvidTtl: 70,
+				JwtSvidTtl:  80,
+				Selectors: []*types.Selector{
+					{Type: "k8s", Value: "sa:default"},
+				},
+				FederatesWith: []string{"domain1.com", "domain2.com"},
+				DnsNames:      []string{"dns1"},
+				Hint:          "external",
+				CreatedAt:     1678731397,
+			},
+		},
+		{
+			name: "missing entry template",
+			err:  "missing entry template",
+		},
+		{
+			name: "malformed SPIFFE ID template",
+			template: &common.EntryTemplate{
+				ParentId:         "spiffe://example.org/foo",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s }}",
+			},
+			err: `invalid SPIFFE ID template: invalid path template: selectors must be referenced as .<type>.<key>, got ".k8s"`,
+		},
+		{
+			name: "malformed ParentId",
+			template: &common.EntryTemplate{
+				ParentId:         "malformed ParentID",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+			},
+			err: "invalid parent ID: scheme is missing or invalid",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := api.EntryTemplateToProto(tt.template)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				require.Nil(t, entry)
+				return
+			}
+
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectEntry, entry)
+		})
+	}
+}
+
+func TestValidateEntryTemplate(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+
+	valid := func() *common.EntryTemplate {
+		return &common.EntryTemplate{
+			TemplateId:       "template1",
+			ParentId:         "spiffe://example.org/foo",
+			SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+			X509SvidTtl:      70,
+			JwtSvidTtl:       80,
+			Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+			FederatesWith:    []string{"domain1.com"},
+			DnsNames:         []string{"dns1"},
+			Hint:             "external",
+		}
+	}
+
+	for _, tt := range []struct {
+		name           string
+		modify         func(*common.EntryTemplate) *common.EntryTemplate
+		err            string
+		expectTemplate *common.EntryTemplate
+	}{
+		{
+			name: "success",
+			expectTemplate: &common.EntryTemplate{
+				TemplateId:       "template1",
+				ParentId:         "spiffe://example.org/foo",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+				X509SvidTtl:      70,
+				JwtSvidTtl:       80,
+				Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+				FederatesWith:    []string{"spiffe://domain1.com"},
+				DnsNames:         []string{"dns1"},
+				Hint:             "external",
+			},
+		},
+		{
+			name:   "missing entry template",
+			modify: func(*common.EntryTemplate) *common.EntryTemplate { return nil },
+			err:    "missing entry template",
+		},
+		{
+			name: "malformed parent ID",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.ParentId = "malformed"
+				return t
+			},
+			err: "invalid parent ID: scheme is missing or invalid",
+		},
+		{
+			name: "parent ID in another trust domain",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.ParentId = "spiffe://otherdomain.org/foo"
+				return t
+			},
+			err: `invalid parent ID: "spiffe://otherdomain.org/foo" is not a member of trust domain "example.org"`,
+		},
+		{
+			name: "parent ID is the server",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.ParentId = "spiffe://example.org/spire/server"
+				return t
+			},
+			err: "invalid parent ID: entry templates cannot be used for node entries",
+		},
+		{
+			name: "malformed SPIFFE ID template",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.SpiffeIdTemplate = "spiffe://example.org/ns/{{ .k8s }}"
+				return t
+			},
+			err: `invalid SPIFFE ID template: invalid path template: selectors must be referenced as .<type>.<key>, got ".k8s"`,
+		},
+		{
+			name: "SPIFFE ID template in another trust domain",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.SpiffeIdTemplate = "spiffe://otherdomain.org/ns/{{ .k8s.ns }}"
+				return t
+			},
+			err: `invalid SPIFFE ID template: "spiffe://otherdomain.org/ns/{{ .k8s.ns }}" is not a member of trust domain "example.org"`,
+		},
+		{
+			name: "SPIFFE ID template in the reserved namespace",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.SpiffeIdTemplate = "spiffe://example.org/spire/{{ .k8s.ns }}"
+				return t
+			},
+			err: `invalid SPIFFE ID template: "spiffe://example.org/spire/{{ .k8s.ns }}" is not a workload in trust domain "example.org"; path is in the reserved namespace`,
+		},
+		{
+			name: "missing selectors",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.Selectors = nil
+				return t
+			},
+			err: "selector list is empty",
+		},
+		{
+			name: "malformed selector",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.Selectors = []*common.Selector{{Value: "sa:default"}}
+				return t
+			},
+			err: "missing selector type",
+		},
+		{
+			name: "malformed DNS name",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.DnsNames = []string{"abc-"}
+				return t
+			},
+			err: "invalid DNS name: idna error\nidna: invalid label \"abc-\"",
+		},
+		{
+			name: "malformed federated trust domain",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.FederatesWith = []string{"malformed td"}
+				return t
+			},
+			err: "invalid federated trust domain: trust domain characters are limited to lowercase letters, numbers, dots, dashes, and underscores",
+		},
+		{
+			name: "hint too long",
+			modify: func(t *common.EntryTemplate) *common.EntryTemplate {
+				t.Hint = strings.Repeat("a", 1025)
+				return t
+			},
+			err: "hint is too long, max length is 1024 characters",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			template := valid()
+			if tt.modify != nil {
+				template = tt.modify(template)
+			}
+			validated, err := api.ValidateEntryTemplate(context.Background(), td, template)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				require.Nil(t, validated)
+				return
+			}
+
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectTemplate, validated)
+		})
+	}
+}
+
 func TestProtoToRegistrationEntryWithMask(t *testing.T) {
 	td := spiffeid.RequireTrustDomainFromString("example.org")
 	expiresAt := time.Now().Unix()
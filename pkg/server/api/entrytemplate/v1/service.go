@@ -0,0 +1,112 @@
+package entrytemplate
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers the entry template service on the provided server
+func RegisterService(s grpc.ServiceRegistrar, service *Service) {
+	entrytemplatev1.RegisterEntryTemplatesServer(s, service)
+}
+
+// Config is the entry template service configuration
+type Config struct {
+	TrustDomain spiffeid.TrustDomain
+	DataStore   datastore.DataStore
+}
+
+// New creates a new entry template service
+func New(config Config) *Service {
+	return &Service{
+		td: config.TrustDomain,
+		ds: config.DataStore,
+	}
+}
+
+// Service implements the entry template server
+type Service struct {
+	entrytemplatev1.UnsafeEntryTemplatesServer
+
+	td spiffeid.TrustDomain
+	ds datastore.DataStore
+}
+
+// CreateEntryTemplate creates an entry template
+func (s *Service) CreateEntryTemplate(ctx context.Context, req *entrytemplatev1.CreateEntryTemplateRequest) (*entrytemplatev1.CreateEntryTemplateResponse, error) {
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.EntryTemplateID: req.GetTemplate().GetTemplateId(),
+		telemetry.SPIFFEID:        req.GetTemplate().GetSpiffeIdTemplate(),
+		telemetry.ParentID:        req.GetTemplate().GetParentId(),
+	})
+	log := rpccontext.Logger(ctx)
+
+	template, err := api.ValidateEntryTemplate(ctx, s.td, req.Template)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid entry template", err)
+	}
+
+	template, err = s.ds.CreateEntryTemplate(ctx, template)
+	if err != nil {
+		statusCode := status.Code(err)
+		if statusCode == codes.Unknown {
+			statusCode = codes.Internal
+		}
+		return nil, api.MakeErr(log, statusCode, "failed to create entry template", err)
+	}
+
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.EntryTemplateID: template.TemplateId})
+	rpccontext.AuditRPC(ctx)
+	return &entrytemplatev1.CreateEntryTemplateResponse{
+		Template: template,
+	}, nil
+}
+
+// ListEntryTemplates lists the entry templates
+func (s *Service) ListEntryTemplates(ctx context.Context, _ *entrytemplatev1.ListEntryTemplatesRequest) (*entrytemplatev1.ListEntryTemplatesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	templates, err := s.ds.ListEntryTemplates(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list entry templates", err)
+	}
+
+	rpccontext.AuditRPC(ctx)
+	return &entrytemplatev1.ListEntryTemplatesResponse{
+		Templates: templates,
+	}, nil
+}
+
+// DeleteEntryTemplate deletes an entry template
+func (s *Service) DeleteEntryTemplate(ctx context.Context, req *entrytemplatev1.DeleteEntryTemplateRequest) (*entrytemplatev1.DeleteEntryTemplateResponse, error) {
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.EntryTemplateID: req.TemplateId})
+	log := rpccontext.Logger(ctx)
+
+	if req.TemplateId == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing template ID", nil)
+	}
+
+	template, err := s.ds.DeleteEntryTemplate(ctx, req.TemplateId)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		return nil, api.MakeErr(log, codes.NotFound, "entry template not found", err)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to delete entry template", err)
+	}
+
+	rpccontext.AuditRPC(ctx)
+	return &entrytemplatev1.DeleteEntryTemplateResponse{
+		Template: template,
+	}, nil
+}
@@ -0,0 +1,239 @@
+package entrytemplate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	entrytemplate "github.com/spiffe/spire/pkg/server/api/entrytemplate/v1"
+	"github.com/spiffe/spire/pkg/server/api/middleware"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/grpctest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var td = spiffeid.RequireTrustDomainFromString("example.org")
+
+func TestCreateEntryTemplate(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		template       *common.EntryTemplate
+		dsError        error
+		expectCode     codes.Code
+		expectMsg      string
+		expectTemplate *common.EntryTemplate
+		expectLogs     []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			template: &common.EntryTemplate{
+				TemplateId:       "template1",
+				ParentId:         "spiffe://example.org/node",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+				Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+				FederatesWith:    []string{"domain1.org"},
+			},
+			expectTemplate: &common.EntryTemplate{
+				TemplateId:       "template1",
+				ParentId:         "spiffe://example.org/node",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+				Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+				FederatesWith:    []string{"spiffe://domain1.org"},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.EntryTemplateID: "template1",
+						telemetry.ParentID:        "spiffe://example.org/node",
+						telemetry.SPIFFEID:        "spiffe://example.org/ns/{{ .k8s.ns }}",
+						telemetry.Status:          "success",
+						telemetry.Type:            "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "invalid template",
+			template: &common.EntryTemplate{
+				TemplateId:       "template1",
+				ParentId:         "spiffe://example.org/node",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid entry template: selector list is empty",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid entry template",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "selector list is empty",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.EntryTemplateID: "template1",
+						telemetry.ParentID:        "spiffe://example.org/node",
+						telemetry.SPIFFEID:        "spiffe://example.org/ns/{{ .k8s.ns }}",
+						telemetry.Status:          "error",
+						telemetry.StatusCode:      "InvalidArgument",
+						telemetry.StatusMessage:   "invalid entry template: selector list is empty",
+						telemetry.Type:            "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "datastore failure",
+			template: &common.EntryTemplate{
+				TemplateId:       "template1",
+				ParentId:         "spiffe://example.org/node",
+				SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+				Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+			},
+			dsError:    errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to create entry template: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to create entry template",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.EntryTemplateID: "template1",
+						telemetry.ParentID:        "spiffe://example.org/node",
+						telemetry.SPIFFEID:        "spiffe://example.org/ns/{{ .k8s.ns }}",
+						telemetry.Status:          "error",
+						telemetry.StatusCode:      "Internal",
+						telemetry.StatusMessage:   "failed to create entry template: oh no",
+						telemetry.Type:            "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.client.CreateEntryTemplate(context.Background(), &entrytemplatev1.CreateEntryTemplateRequest{
+				Template: tt.template,
+			})
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			require.NotZero(t, resp.Template.CreatedAt)
+			resp.Template.CreatedAt = 0
+			spiretest.AssertProtoEqual(t, tt.expectTemplate, resp.Template)
+		})
+	}
+}
+
+func TestListEntryTemplates(t *testing.T) {
+	test := setupServiceTest(t)
+
+	resp, err := test.client.ListEntryTemplates(context.Background(), &entrytemplatev1.ListEntryTemplatesRequest{})
+	require.NoError(t, err)
+	require.Empty(t, resp.Templates)
+
+	created := test.createTemplate(t, "template1")
+
+	resp, err = test.client.ListEntryTemplates(context.Background(), &entrytemplatev1.ListEntryTemplatesRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &entrytemplatev1.ListEntryTemplatesResponse{
+		Templates: []*common.EntryTemplate{created},
+	}, resp)
+
+	test.ds.SetNextError(errors.New("oh no"))
+	resp, err = test.client.ListEntryTemplates(context.Background(), &entrytemplatev1.ListEntryTemplatesRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list entry templates: oh no")
+	require.Nil(t, resp)
+}
+
+func TestDeleteEntryTemplate(t *testing.T) {
+	test := setupServiceTest(t)
+	created := test.createTemplate(t, "template1")
+
+	resp, err := test.client.DeleteEntryTemplate(context.Background(), &entrytemplatev1.DeleteEntryTemplateRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing template ID")
+	require.Nil(t, resp)
+
+	test.ds.SetNextError(errors.New("oh no"))
+	resp, err = test.client.DeleteEntryTemplate(context.Background(), &entrytemplatev1.DeleteEntryTemplateRequest{TemplateId: "template1"})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to delete entry template: oh no")
+	require.Nil(t, resp)
+
+	resp, err = test.client.DeleteEntryTemplate(context.Background(), &entrytemplatev1.DeleteEntryTemplateRequest{TemplateId: "template1"})
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, created, resp.Template)
+
+	resp, err = test.client.DeleteEntryTemplate(context.Background(), &entrytemplatev1.DeleteEntryTemplateRequest{TemplateId: "template1"})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry template not found")
+	require.Nil(t, resp)
+}
+
+type serviceTest struct {
+	client  entrytemplatev1.EntryTemplatesClient
+	ds      *fakedatastore.DataStore
+	logHook *test.Hook
+}
+
+func (s *serviceTest) createTemplate(t *testing.T, templateID string) *common.EntryTemplate {
+	template, err := s.ds.CreateEntryTemplate(context.Background(), &common.EntryTemplate{
+		TemplateId:       templateID,
+		ParentId:         "spiffe://example.org/node",
+		SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+		Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+	})
+	require.NoError(t, err)
+	return template
+}
+
+func setupServiceTest(t *testing.T) *serviceTest {
+	ds := fakedatastore.New(t)
+	log, logHook := test.NewNullLogger()
+	service := entrytemplate.New(entrytemplate.Config{
+		TrustDomain: td,
+		DataStore:   ds,
+	})
+
+	registerFn := func(s grpc.ServiceRegistrar) {
+		entrytemplate.RegisterService(s, service)
+	}
+	overrideContext := func(ctx context.Context) context.Context {
+		return rpccontext.WithLogger(ctx, log)
+	}
+	server := grpctest.StartServer(t, registerFn,
+		grpctest.OverrideContext(overrideContext),
+		grpctest.Middleware(middleware.WithAuditLog(false)))
+	conn := server.NewGRPCClient(t)
+
+	return &serviceTest{
+		client:  entrytemplatev1.NewEntryTemplatesClient(conn),
+		ds:      ds,
+		logHook: logHook,
+	}
+}
@@ -0,0 +1,207 @@
+package featureflags
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// serverReportInterval is how often the server records its feature
+	// flags in the datastore, besides whenever they are reloaded.
+	serverReportInterval = time.Minute
+
+	// serverStatusTTL is how long the feature flags of a server are kept
+	// after it last recorded them. Servers that stop recording them (e.g.
+	// because they were shut down) are pruned once it elapses.
+	serverStatusTTL = 5 * time.Minute
+)
+
+// RegisterService registers the feature flags service on the provided server
+func RegisterService(s grpc.ServiceRegistrar, service *Service) {
+	featureflagsv1.RegisterFeatureFlagsServer(s, service)
+}
+
+// Config is the feature flags service configuration
+type Config struct {
+	// Loader loads the feature flags from the server configuration. If
+	// not set, reloading the feature flags is not supported.
+	Loader fflag.Loader
+
+	DataStore datastore.DataStore
+
+	// ServerID identifies this server among the servers sharing the
+	// datastore.
+	ServerID string
+
+	Log   logrus.FieldLogger
+	Clock clock.Clock
+}
+
+// New creates a new feature flags service
+func New(config Config) *Service {
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
+	return &Service{
+		loader:   config.Loader,
+		ds:       config.DataStore,
+		serverID: config.ServerID,
+		log:      config.Log,
+		clock:    config.Clock,
+	}
+}
+
+// Service implements the feature flags server. The feature flags of the
+// servers and agents are kept in the datastore, so that every server sharing
+// it reports the same statuses.
+type Service struct {
+	featureflagsv1.UnsafeFeatureFlagsServer
+
+	loader   fflag.Loader
+	ds       datastore.DataStore
+	serverID string
+	log      logrus.FieldLogger
+	clock    clock.Clock
+}
+
+// RecordAgentFeatureFlags records the feature flags reported by an agent.
+// The recorded flags are deleted along with the attested node of the agent.
+func (s *Service) RecordAgentFeatureFlags(ctx context.Context, agentID spiffeid.ID, enabled []string) error {
+	enabled = slices.Clone(enabled)
+	sort.Strings(enabled)
+
+	return s.ds.SetFeatureFlagStatus(ctx, &datastore.FeatureFlagStatus{
+		ReporterID: agentID.String(),
+		Enabled:    enabled,
+		ReportedAt: s.clock.Now().Unix(),
+	})
+}
+
+// ReportServerFeatureFlags records the feature flags of this server in the
+// datastore whenever they are reloaded, and periodically so that they are not
+// pruned by the other servers, until the context is canceled. It also prunes
+// the feature flags of the servers that stopped recording them.
+func (s *Service) ReportServerFeatureFlags(ctx context.Context) error {
+	changed, unsubscribe := fflag.Subscribe()
+	defer unsubscribe()
+
+	ticker := s.clock.Ticker(serverReportInterval)
+	defer ticker.Stop()
+
+	for {
+		s.reportServerFeatureFlags(ctx)
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *Service) reportServerFeatureFlags(ctx context.Context) {
+	now := s.clock.Now()
+
+	if err := s.ds.SetFeatureFlagStatus(ctx, &datastore.FeatureFlagStatus{
+		ReporterID: s.serverID,
+		IsServer:   true,
+		Enabled:    fflag.Enabled(),
+		ReportedAt: now.Unix(),
+	}); err != nil {
+		s.log.WithError(err).WithField(telemetry.ServerID, s.serverID).Warn("Failed to record the server feature flags")
+	}
+
+	if err := s.ds.PruneServerFeatureFlagStatuses(ctx, now.Add(-serverStatusTTL)); err != nil {
+		s.log.WithError(err).Warn("Failed to prune the feature flags of stale servers")
+	}
+}
+
+// ListFeatureFlags lists the feature flags of the server along with the
+// feature flags reported by each server and agent
+func (s *Service) ListFeatureFlags(ctx context.Context, _ *featureflagsv1.ListFeatureFlagsRequest) (*featureflagsv1.ListFeatureFlagsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	statuses, err := s.ds.ListFeatureFlagStatuses(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list feature flag statuses", err)
+	}
+
+	servers := []*featureflagsv1.ServerFeatureFlags{}
+	agents := []*featureflagsv1.AgentFeatureFlags{}
+	for _, status := range statuses {
+		if status.IsServer {
+			servers = append(servers, &featureflagsv1.ServerFeatureFlags{
+				ServerId:   status.ReporterID,
+				Enabled:    status.Enabled,
+				ReportedAt: status.ReportedAt,
+			})
+			continue
+		}
+		agents = append(agents, &featureflagsv1.AgentFeatureFlags{
+			SpiffeId:   status.ReporterID,
+			Enabled:    status.Enabled,
+			ReportedAt: status.ReportedAt,
+		})
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].ServerId < servers[j].ServerId
+	})
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].SpiffeId < agents[j].SpiffeId
+	})
+
+	rpccontext.AuditRPC(ctx)
+	return &featureflagsv1.ListFeatureFlagsResponse{
+		Server:  serverFeatureFlags(),
+		Agents:  agents,
+		Servers: servers,
+	}, nil
+}
+
+// ReloadFeatureFlags reloads the feature flags from the server configuration
+func (s *Service) ReloadFeatureFlags(ctx context.Context, _ *featureflagsv1.ReloadFeatureFlagsRequest) (*featureflagsv1.ReloadFeatureFlagsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if s.loader == nil {
+		return nil, api.MakeErr(log, codes.Unimplemented, "reloading feature flags is not supported", nil)
+	}
+
+	changed, err := fflag.ReloadFrom(s.loader)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.FailedPrecondition, "failed to reload feature flags", err)
+	}
+
+	fflag.LogReloaded(log, changed)
+
+	rpccontext.AuditRPC(ctx)
+	return &featureflagsv1.ReloadFeatureFlagsResponse{
+		Server: serverFeatureFlags(),
+	}, nil
+}
+
+func serverFeatureFlags() []*featureflagsv1.FeatureFlag {
+	var flags []*featureflagsv1.FeatureFlag
+	for _, status := range fflag.List() {
+		flags = append(flags, &featureflagsv1.FeatureFlag{
+			Name:    string(status.Flag),
+			Enabled: status.Enabled,
+		})
+	}
+	return flags
+}
@@ -0,0 +1,407 @@
+package featureflags_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	featureflags "github.com/spiffe/spire/pkg/server/api/featureflags/v1"
+	"github.com/spiffe/spire/pkg/server/api/middleware"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/grpctest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	serverID = "server-1"
+)
+
+var (
+	agentA = spiffeid.RequireFromString("spiffe://example.org/spire/agent/a")
+	agentB = spiffeid.RequireFromString("spiffe://example.org/spire/agent/b")
+)
+
+func TestListFeatureFlags(t *testing.T) {
+	loadFeatureFlags(t, "wit-svid")
+	test := setupServiceTest(t, nil)
+	ctx := context.Background()
+
+	test.createAgent(t, agentA)
+	test.createAgent(t, agentB)
+
+	require.NoError(t, test.service.RecordAgentFeatureFlags(ctx, agentB, []string{"wit-svid"}))
+	test.clk.Add(time.Minute)
+	require.NoError(t, test.service.RecordAgentFeatureFlags(ctx, agentA, []string{"wit-svid", "another-flag"}))
+	test.clk.Add(time.Minute)
+	// The latest report of an agent replaces the previous one
+	require.NoError(t, test.service.RecordAgentFeatureFlags(ctx, agentB, []string{}))
+
+	// Statuses recorded by other servers sharing the datastore are listed
+	require.NoError(t, test.ds.SetFeatureFlagStatus(ctx, &datastore.FeatureFlagStatus{
+		ReporterID: "server-b",
+		IsServer:   true,
+		Enabled:    []string{},
+		ReportedAt: test.clk.Now().Unix(),
+	}))
+	require.NoError(t, test.ds.SetFeatureFlagStatus(ctx, &datastore.FeatureFlagStatus{
+		ReporterID: "server-a",
+		IsServer:   true,
+		Enabled:    []string{"wit-svid"},
+		ReportedAt: test.clk.Now().Unix(),
+	}))
+
+	resp, err := test.client.ListFeatureFlags(ctx, &featureflagsv1.ListFeatureFlagsRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &featureflagsv1.ListFeatureFlagsResponse{
+		Server: []*featureflagsv1.FeatureFlag{
+			{Name: "wit-svid", Enabled: true},
+		},
+		Agents: []*featureflagsv1.AgentFeatureFlags{
+			{
+				SpiffeId:   agentA.String(),
+				Enabled:    []string{"another-flag", "wit-svid"},
+				ReportedAt: test.clk.Now().Add(-time.Minute).Unix(),
+			},
+			{
+				SpiffeId:   agentB.String(),
+				Enabled:    []string{},
+				ReportedAt: test.clk.Now().Unix(),
+			},
+		},
+		Servers: []*featureflagsv1.ServerFeatureFlags{
+			{
+				ServerId:   "server-a",
+				Enabled:    []string{"wit-svid"},
+				ReportedAt: test.clk.Now().Unix(),
+			},
+			{
+				ServerId:   "server-b",
+				Enabled:    []string{},
+				ReportedAt: test.clk.Now().Unix(),
+			},
+		},
+	}, resp)
+
+	// The status of an evicted agent is no longer listed
+	_, err = test.ds.DeleteAttestedNode(ctx, agentA.String())
+	require.NoError(t, err)
+
+	resp, err = test.client.ListFeatureFlags(ctx, &featureflagsv1.ListFeatureFlagsRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, []*featureflagsv1.AgentFeatureFlags{
+		{
+			SpiffeId:   agentB.String(),
+			Enabled:    []string{},
+			ReportedAt: test.clk.Now().Unix(),
+		},
+	}, resp.Agents)
+}
+
+func TestListFeatureFlagsFailsToListStatuses(t *testing.T) {
+	loadFeatureFlags(t)
+	test := setupServiceTest(t, nil)
+
+	test.ds.SetNextError(errors.New("oh no"))
+
+	resp, err := test.client.ListFeatureFlags(context.Background(), &featureflagsv1.ListFeatureFlagsRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list feature flag statuses: oh no")
+	require.Nil(t, resp)
+}
+
+func TestRecordAgentFeatureFlagsRequiresAttestedAgent(t *testing.T) {
+	loadFeatureFlags(t)
+	test := setupServiceTest(t, nil)
+
+	err := test.service.RecordAgentFeatureFlags(context.Background(), agentA, []string{"wit-svid"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestReportServerFeatureFlags(t *testing.T) {
+	loadFeatureFlags(t)
+	test := setupServiceTest(t, nil)
+	ctx := context.Background()
+
+	staleServer := &datastore.FeatureFlagStatus{
+		ReporterID: "stale-server",
+		IsServer:   true,
+		Enabled:    []string{},
+		ReportedAt: test.clk.Now().Add(-10 * time.Minute).Unix(),
+	}
+	otherServer := &datastore.FeatureFlagStatus{
+		ReporterID: "other-server",
+		IsServer:   true,
+		Enabled:    []string{"wit-svid"},
+		ReportedAt: test.clk.Now().Add(-time.Minute).Unix(),
+	}
+	require.NoError(t, test.ds.SetFeatureFlagStatus(ctx, staleServer))
+	require.NoError(t, test.ds.SetFeatureFlagStatus(ctx, otherServer))
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- test.service.ReportServerFeatureFlags(taskCtx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	thisServer := func(enabled []string, reportedAt time.Time) *datastore.FeatureFlagStatus {
+		return &datastore.FeatureFlagStatus{
+			ReporterID: serverID,
+			IsServer:   true,
+			Enabled:    enabled,
+			ReportedAt: reportedAt.Unix(),
+		}
+	}
+	requireStatuses := func(expected ...*datastore.FeatureFlagStatus) {
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			statuses, err := test.ds.ListFeatureFlagStatuses(ctx)
+			require.NoError(c, err)
+			assert.ElementsMatch(c, expected, statuses)
+		}, time.Minute, 10*time.Millisecond)
+	}
+
+	// The server records its flags when started and prunes the stale servers
+	requireStatuses(thisServer([]string{}, test.clk.Now()), otherServer)
+	test.clk.WaitForTicker(time.Minute, "waiting for the report ticker")
+
+	// The server records its flags when they are reloaded
+	_, err := fflag.Reload(fflag.RawConfig{"wit-svid"})
+	require.NoError(t, err)
+	requireStatuses(thisServer([]string{"wit-svid"}, test.clk.Now()), otherServer)
+
+	// The server records its flags periodically, pruning the servers that
+	// stopped recording them
+	test.clk.Add(5 * time.Minute)
+	requireStatuses(thisServer([]string{"wit-svid"}, test.clk.Now()))
+}
+
+func TestReloadFeatureFlags(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		loader       fflag.Loader
+		expectCode   codes.Code
+		expectMsg    string
+		expectServer []*featureflagsv1.FeatureFlag
+		expectLogs   []spiretest.LogEntry
+	}{
+		{
+			name: "flag enabled",
+			loader: func() (fflag.RawConfig, error) {
+				return fflag.RawConfig{"wit-svid"}, nil
+			},
+			expectServer: []*featureflagsv1.FeatureFlag{
+				{Name: "wit-svid", Enabled: true},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Feature flag toggled",
+					Data: logrus.Fields{
+						telemetry.FeatureFlag: "wit-svid",
+						telemetry.Enabled:     "true",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "no changes",
+			loader: func() (fflag.RawConfig, error) {
+				return fflag.RawConfig{}, nil
+			},
+			expectServer: []*featureflagsv1.FeatureFlag{
+				{Name: "wit-svid", Enabled: false},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Feature flags reloaded; no changes",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "unknown flag",
+			loader: func() (fflag.RawConfig, error) {
+				return fflag.RawConfig{"wit-svid", "bogus"}, nil
+			},
+			expectCode: codes.FailedPrecondition,
+			expectMsg:  "failed to reload feature flags: unknown feature flag(s): [bogus]",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to reload feature flags",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "unknown feature flag(s): [bogus]",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "FailedPrecondition",
+						telemetry.StatusMessage: "failed to reload feature flags: unknown feature flag(s): [bogus]",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "loader fails",
+			loader: func() (fflag.RawConfig, error) {
+				return nil, errors.New("oh no")
+			},
+			expectCode: codes.FailedPrecondition,
+			expectMsg:  "failed to reload feature flags: unable to load feature flags: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to reload feature flags",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "unable to load feature flags: oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "FailedPrecondition",
+						telemetry.StatusMessage: "failed to reload feature flags: unable to load feature flags: oh no",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "reloading not supported",
+			expectCode: codes.Unimplemented,
+			expectMsg:  "reloading feature flags is not supported",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Reloading feature flags is not supported",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "Unimplemented",
+						telemetry.StatusMessage: "reloading feature flags is not supported",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			loadFeatureFlags(t)
+			test := setupServiceTest(t, tt.loader)
+
+			resp, err := test.client.ReloadFeatureFlags(context.Background(), &featureflagsv1.ReloadFeatureFlagsRequest{})
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				require.False(t, fflag.IsSet(fflag.FlagWITSVID))
+				return
+			}
+
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, &featureflagsv1.ReloadFeatureFlagsResponse{
+				Server: tt.expectServer,
+			}, resp)
+		})
+	}
+}
+
+type serviceTest struct {
+	client  featureflagsv1.FeatureFlagsClient
+	service *featureflags.Service
+	ds      *fakedatastore.DataStore
+	clk     *clock.Mock
+	logHook *test.Hook
+}
+
+func (s *serviceTest) createAgent(t *testing.T, agentID spiffeid.ID) {
+	_, err := s.ds.CreateAttestedNode(context.Background(), &common.AttestedNode{
+		SpiffeId:            agentID.String(),
+		AttestationDataType: "test_type",
+		CertSerialNumber:    "12345",
+		CertNotAfter:        s.clk.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+}
+
+func setupServiceTest(t *testing.T, loader fflag.Loader) *serviceTest {
+	log, logHook := test.NewNullLogger()
+	ds := fakedatastore.New(t)
+	clk := clock.NewMock(t)
+	service := featureflags.New(featureflags.Config{
+		Loader:    loader,
+		DataStore: ds,
+		ServerID:  serverID,
+		Log:       log,
+		Clock:     clk,
+	})
+
+	registerFn := func(s grpc.ServiceRegistrar) {
+		featureflags.RegisterService(s, service)
+	}
+	overrideContext := func(ctx context.Context) context.Context {
+		return rpccontext.WithLogger(ctx, log)
+	}
+	server := grpctest.StartServer(t, registerFn,
+		grpctest.OverrideContext(overrideContext),
+		grpctest.Middleware(middleware.WithAuditLog(false)))
+	conn := server.NewGRPCClient(t)
+
+	return &serviceTest{
+		client:  featureflagsv1.NewFeatureFlagsClient(conn),
+		service: service,
+		ds:      ds,
+		clk:     clk,
+		logHook: logHook,
+	}
+}
+
+func loadFeatureFlags(t *testing.T, flags ...string) {
+	require.NoError(t, fflag.Load(flags))
+	t.Cleanup(func() {
+		require.NoError(t, fflag.Unload())
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 )
 
@@ -89,6 +90,30 @@ func VerifyTrustDomainWorkloadID(td spiffeid.TrustDomain, id spiffeid.ID) error
 	return nil
 }
 
+// TrustDomainWorkloadTemplateFromProto parses the SPIFFE ID of an entry
+// template, verifying that the IDs it renders are workload IDs in the given
+// trust domain.
+func TrustDomainWorkloadTemplateFromProto(td spiffeid.TrustDomain, protoID *types.SPIFFEID) (*entrytemplate.Template, error) {
+	if protoID == nil {
+		return nil, errors.New("request must specify SPIFFE ID")
+	}
+	tmplTD, err := spiffeid.TrustDomainFromString(protoID.TrustDomain)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := entrytemplate.New(tmplTD, protoID.Path)
+	if err != nil {
+		return nil, err
+	}
+	if tmplTD != td {
+		return nil, fmt.Errorf("%q is not a member of trust domain %q", tmpl, td)
+	}
+	if idutil.IsReservedPath(tmpl.Path()) {
+		return nil, fmt.Errorf("%q is not a workload in trust domain %q; path is in the reserved namespace", tmpl, td)
+	}
+	return tmpl, nil
+}
+
 // ProtoFromID converts a SPIFFE ID from the given spiffeid.ID to
 // types.SPIFFEID
 func ProtoFromID(id spiffeid.ID) *types.SPIFFEID {
@@ -243,6 +243,202 @@ func TestGetJWTAuthorityState(t *testing.T) {
 	}
 }
 
+func TestGetWITAuthorityState(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		disableWITSVIDs bool
+		currentSlot     *fakeSlot
+		nextSlot        *fakeSlot
+		expectLogs      []spiretest.LogEntry
+		expectCode      codes.Code
+		expectMsg       string
+		expectResp      *localauthorityv1.GetWITAuthorityStateResponse
+	}{
+		{
+			name:            "WIT is disabled",
+			disableWITSVIDs: true,
+			currentSlot:     &fakeSlot{},
+			nextSlot:        &fakeSlot{},
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "WIT functionality is disabled",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "WIT functionality is disabled",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unimplemented",
+						telemetry.StatusMessage: "WIT functionality is disabled",
+					},
+				},
+			},
+		},
+		{
+			name:        "current is set",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    &fakeSlot{},
+			expectResp: &localauthorityv1.GetWITAuthorityStateResponse{
+				Active: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyA,
+					ExpiresAt:   notAfterCurrent.Unix(),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:        "no current slot is set",
+			currentSlot: &fakeSlot{},
+			nextSlot:    createSlot(journal.Status_UNKNOWN, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectCode:  codes.Unavailable,
+			expectMsg:   "server is initializing",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Server is initializing",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unavailable",
+						telemetry.StatusMessage: "server is initializing",
+					},
+				},
+			},
+		},
+		{
+			name:        "next contains an old authority",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectResp: &localauthorityv1.GetWITAuthorityStateResponse{
+				Active: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyA,
+					ExpiresAt:   notAfterCurrent.Unix(),
+				},
+				Old: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyB,
+					ExpiresAt:   notAfterNext.Unix(),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:        "next contains a prepared authority",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectResp: &localauthorityv1.GetWITAuthorityStateResponse{
+				Active: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyA,
+					ExpiresAt:   notAfterCurrent.Unix(),
+				},
+				Prepared: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyB,
+					ExpiresAt:   notAfterNext.Unix(),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:        "next contains an unknown authority",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_UNKNOWN, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectResp: &localauthorityv1.GetWITAuthorityStateResponse{
+				Active: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyA,
+					ExpiresAt:   notAfterCurrent.Unix(),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Slot has an unknown status",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyB,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:        "current slot has no authority ID",
+			currentSlot: createSlot(journal.Status_ACTIVE, "", nil, time.Time{}),
+			nextSlot:    &fakeSlot{},
+			expectCode:  codes.Internal,
+			expectMsg:   "current slot does not contain authority ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Current slot does not contain authority ID",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "current slot does not contain authority ID",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		test := setupServiceTest(t)
+		defer test.Cleanup()
+
+		test.ca.disableWITSVIDs = tt.disableWITSVIDs
+		test.ca.currentWITKeySlot = tt.currentSlot
+		test.ca.nextWITKeySlot = tt.nextSlot
+
+		resp, err := test.client.GetWITAuthorityState(ctx, &localauthorityv1.GetWITAuthorityStateRequest{})
+
+		spiretest.AssertGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+		spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+	}
+}
+
 func TestPrepareJWTAuthority(t *testing.T) {
 	for _, tt := range []struct {
 		name            string
@@ -369,31 +565,29 @@ func TestPrepareJWTAuthority(t *testing.T) {
 	}
 }
 
-func TestActivateJWTAuthority(t *testing.T) {
+func TestPrepareWITAuthority(t *testing.T) {
 	for _, tt := range []struct {
 		name            string
-		disableJWTSVIDs bool
+		disableWITSVIDs bool
 		currentSlot     *fakeSlot
+		prepareErr      error
 		nextSlot        *fakeSlot
-
-		rotateCalled  bool
-		keyToActivate string
-		expectLogs    []spiretest.LogEntry
-		expectCode    codes.Code
-		expectMsg     string
-		expectResp    *localauthorityv1.ActivateJWTAuthorityResponse
+		expectLogs      []spiretest.LogEntry
+		expectCode      codes.Code
+		expectMsg       string
+		expectResp      *localauthorityv1.PrepareWITAuthorityResponse
 	}{
 		{
-			name:            "JWT is disabled",
-			disableJWTSVIDs: true,
+			name:            "WIT is disabled",
+			disableWITSVIDs: true,
 			currentSlot:     &fakeSlot{},
 			nextSlot:        &fakeSlot{},
 			expectCode:      codes.Unimplemented,
-			expectMsg:       "JWT functionality is disabled",
+			expectMsg:       "WIT functionality is disabled",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "JWT functionality is disabled",
+					Message: "WIT functionality is disabled",
 				},
 				{
 					Level:   logrus.InfoLevel,
@@ -402,21 +596,19 @@ func TestActivateJWTAuthority(t *testing.T) {
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
 						telemetry.StatusCode:    "Unimplemented",
-						telemetry.StatusMessage: "JWT functionality is disabled",
+						telemetry.StatusMessage: "WIT functionality is disabled",
 					},
 				},
 			},
 		},
 		{
-			name:          "activate successfully",
-			currentSlot:   createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
-			nextSlot:      createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
-			keyToActivate: authorityIDKeyB,
-			rotateCalled:  true,
-			expectResp: &localauthorityv1.ActivateJWTAuthorityResponse{
-				ActivatedAuthority: &localauthorityv1.AuthorityState{
-					AuthorityId: authorityIDKeyA,
-					ExpiresAt:   notAfterCurrent.Unix(),
+			name:        "using next to prepare",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectResp: &localauthorityv1.PrepareWITAuthorityResponse{
+				PreparedAuthority: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyB,
+					ExpiresAt:   notAfterNext.Unix(),
 				},
 			},
 			expectLogs: []spiretest.LogEntry{
@@ -424,54 +616,184 @@ func TestActivateJWTAuthority(t *testing.T) {
 					Level:   logrus.InfoLevel,
 					Message: "API accessed",
 					Data: logrus.Fields{
-						telemetry.Status:           "success",
-						telemetry.Type:             "audit",
-						telemetry.LocalAuthorityID: authorityIDKeyB,
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
 					},
 				},
 			},
 		},
 		{
-			name:          "activate invalid authority ID",
-			currentSlot:   createSlot(journal.Status_OLD, authorityIDKeyA, keyA.Public(), notAfterCurrent),
-			nextSlot:      createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
-			keyToActivate: authorityIDKeyA,
-			expectCode:    codes.InvalidArgument,
-			expectMsg:     "unexpected authority ID",
+			name:        "current slot is not initialized",
+			currentSlot: createSlot(journal.Status_OLD, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectCode:  codes.Unavailable,
+			expectMsg:   "server is initializing",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: unexpected authority ID",
-					Data: logrus.Fields{
-						telemetry.LocalAuthorityID: authorityIDKeyA,
-					},
+					Message: "Server is initializing",
 				},
 				{
 					Level:   logrus.InfoLevel,
 					Message: "API accessed",
 					Data: logrus.Fields{
-						telemetry.Status:           "error",
-						telemetry.Type:             "audit",
-						telemetry.LocalAuthorityID: authorityIDKeyA,
-						telemetry.StatusCode:       "InvalidArgument",
-						telemetry.StatusMessage:    "unexpected authority ID",
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unavailable",
+						telemetry.StatusMessage: "server is initializing",
 					},
 				},
 			},
 		},
 		{
-			name:          "next slot is not set",
-			currentSlot:   createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
-			nextSlot:      createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
-			keyToActivate: authorityIDKeyB,
-			expectCode:    codes.Internal,
-			expectMsg:     "only Prepared authorities can be activated: unsupported local authority status: OLD",
+			name:        "failed to prepare",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			prepareErr:  errors.New("oh no"),
+			expectCode:  codes.Internal,
+			expectMsg:   "failed to prepare WIT authority: oh no",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Only Prepared authorities can be activated",
+					Message: "Failed to prepare WIT authority",
 					Data: logrus.Fields{
-						telemetry.LocalAuthorityID: authorityIDKeyB,
+						logrus.ErrorKey: "oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to prepare WIT authority: oh no",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			test.ca.disableWITSVIDs = tt.disableWITSVIDs
+			test.ca.currentWITKeySlot = tt.currentSlot
+			test.ca.nextWITKeySlot = tt.nextSlot
+			test.ca.prepareWITKeyErr = tt.prepareErr
+
+			resp, err := test.client.PrepareWITAuthority(ctx, &localauthorityv1.PrepareWITAuthorityRequest{})
+
+			spiretest.AssertGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+		})
+	}
+}
+
+func TestActivateJWTAuthority(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		disableJWTSVIDs bool
+		currentSlot     *fakeSlot
+		nextSlot        *fakeSlot
+
+		rotateCalled  bool
+		keyToActivate string
+		expectLogs    []spiretest.LogEntry
+		expectCode    codes.Code
+		expectMsg     string
+		expectResp    *localauthorityv1.ActivateJWTAuthorityResponse
+	}{
+		{
+			name:            "JWT is disabled",
+			disableJWTSVIDs: true,
+			currentSlot:     &fakeSlot{},
+			nextSlot:        &fakeSlot{},
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "JWT functionality is disabled",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "JWT functionality is disabled",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unimplemented",
+						telemetry.StatusMessage: "JWT functionality is disabled",
+					},
+				},
+			},
+		},
+		{
+			name:          "activate successfully",
+			currentSlot:   createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:      createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			keyToActivate: authorityIDKeyB,
+			rotateCalled:  true,
+			expectResp: &localauthorityv1.ActivateJWTAuthorityResponse{
+				ActivatedAuthority: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyA,
+					ExpiresAt:   notAfterCurrent.Unix(),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyB,
+					},
+				},
+			},
+		},
+		{
+			name:          "activate invalid authority ID",
+			currentSlot:   createSlot(journal.Status_OLD, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:      createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
+			keyToActivate: authorityIDKeyA,
+			expectCode:    codes.InvalidArgument,
+			expectMsg:     "unexpected authority ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: unexpected authority ID",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "unexpected authority ID",
+					},
+				},
+			},
+		},
+		{
+			name:          "next slot is not set",
+			currentSlot:   createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:      createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
+			keyToActivate: authorityIDKeyB,
+			expectCode:    codes.Internal,
+			expectMsg:     "only Prepared authorities can be activated: unsupported local authority status: OLD",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Only Prepared authorities can be activated",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyB,
 						logrus.ErrorKey:            "unsupported local authority status: OLD",
 					},
 				},
@@ -481,58 +803,729 @@ func TestActivateJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "only Prepared authorities can be activated: unsupported local authority status: OLD",
+						telemetry.StatusMessage:    "only Prepared authorities can be activated: unsupported local authority status: OLD",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyB,
+					},
+				},
+			},
+		},
+		{
+			name:        "no authority ID provided",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "no authority ID provided",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: no authority ID provided",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "no authority ID provided",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			test.ca.disableJWTSVIDs = tt.disableJWTSVIDs
+			test.ca.currentJWTKeySlot = tt.currentSlot
+			test.ca.nextJWTKeySlot = tt.nextSlot
+
+			resp, err := test.client.ActivateJWTAuthority(ctx, &localauthorityv1.ActivateJWTAuthorityRequest{
+				AuthorityId: tt.keyToActivate,
+			})
+
+			require.Equal(t, tt.rotateCalled, test.ca.rotateJWTKeyCalled)
+			spiretest.AssertGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+		})
+	}
+}
+
+func TestActivateWITAuthority(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		disableWITSVIDs bool
+		currentSlot     *fakeSlot
+		nextSlot        *fakeSlot
+
+		rotateCalled  bool
+		keyToActivate string
+		expectLogs    []spiretest.LogEntry
+		expectCode    codes.Code
+		expectMsg     string
+		expectResp    *localauthorityv1.ActivateWITAuthorityResponse
+	}{
+		{
+			name:            "WIT is disabled",
+			disableWITSVIDs: true,
+			currentSlot:     &fakeSlot{},
+			nextSlot:        &fakeSlot{},
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "WIT functionality is disabled",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "WIT functionality is disabled",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unimplemented",
+						telemetry.StatusMessage: "WIT functionality is disabled",
+					},
+				},
+			},
+		},
+		{
+			name:          "activate successfully",
+			currentSlot:   createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:      createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			keyToActivate: authorityIDKeyB,
+			rotateCalled:  true,
+			expectResp: &localauthorityv1.ActivateWITAuthorityResponse{
+				ActivatedAuthority: &localauthorityv1.AuthorityState{
+					AuthorityId: authorityIDKeyA,
+					ExpiresAt:   notAfterCurrent.Unix(),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyB,
+					},
+				},
+			},
+		},
+		{
+			name:          "activate invalid authority ID",
+			currentSlot:   createSlot(journal.Status_OLD, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:      createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
+			keyToActivate: authorityIDKeyA,
+			expectCode:    codes.InvalidArgument,
+			expectMsg:     "unexpected authority ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: unexpected authority ID",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "unexpected authority ID",
+					},
+				},
+			},
+		},
+		{
+			name:          "next slot is not set",
+			currentSlot:   createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:      createSlot(journal.Status_OLD, authorityIDKeyB, keyB.Public(), notAfterNext),
+			keyToActivate: authorityIDKeyB,
+			expectCode:    codes.Internal,
+			expectMsg:     "only Prepared authorities can be activated: unsupported local authority status: OLD",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Only Prepared authorities can be activated",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyB,
+						logrus.ErrorKey:            "unsupported local authority status: OLD",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "Internal",
+						telemetry.StatusMessage:    "only Prepared authorities can be activated: unsupported local authority status: OLD",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyB,
+					},
+				},
+			},
+		},
+		{
+			name:        "no authority ID provided",
+			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
+			nextSlot:    createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "no authority ID provided",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: no authority ID provided",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "no authority ID provided",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			test.ca.disableWITSVIDs = tt.disableWITSVIDs
+			test.ca.currentWITKeySlot = tt.currentSlot
+			test.ca.nextWITKeySlot = tt.nextSlot
+
+			resp, err := test.client.ActivateWITAuthority(ctx, &localauthorityv1.ActivateWITAuthorityRequest{
+				AuthorityId: tt.keyToActivate,
+			})
+
+			require.Equal(t, tt.rotateCalled, test.ca.rotateWITKeyCalled)
+			spiretest.AssertGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+		})
+	}
+}
+
+func TestTaintJWTAuthority(t *testing.T) {
+	clk := clock.New()
+
+	currentKey := keyA
+	currentPublicKeyRaw, err := x509.MarshalPKIXPublicKey(currentKey.Public())
+	require.NoError(t, err)
+	currentAuthorityID := "key1"
+	currentKeyNotAfter := clk.Now().Add(time.Minute)
+
+	nextKey := keyB
+	nextPublicKeyRaw, err := x509.MarshalPKIXPublicKey(nextKey.Public())
+	require.NoError(t, err)
+	nextAuthorityID := "key2"
+	nextKeyNotAfter := clk.Now().Add(2 * time.Minute)
+
+	for _, tt := range []struct {
+		name            string
+		disableJWTSVIDs bool
+		currentSlot     *fakeSlot
+		nextSlot        *fakeSlot
+		keyToTaint      string
+
+		expectLogs       []spiretest.LogEntry
+		expectCode       codes.Code
+		expectMsg        string
+		expectResp       *localauthorityv1.TaintJWTAuthorityResponse
+		nextKeyIsTainted bool
+	}{
+		{
+			name:            "JWT is disabled",
+			disableJWTSVIDs: true,
+			currentSlot:     &fakeSlot{},
+			nextSlot:        &fakeSlot{},
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "JWT functionality is disabled",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "JWT functionality is disabled",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unimplemented",
+						telemetry.StatusMessage: "JWT functionality is disabled",
+					},
+				},
+			},
+		},
+		{
+			name:        "taint old authority",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  nextAuthorityID,
+			expectResp: &localauthorityv1.TaintJWTAuthorityResponse{
+				TaintedAuthority: &localauthorityv1.AuthorityState{
+					AuthorityId: nextAuthorityID,
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "JWT authority tainted successfully",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+			},
+		},
+		{
+			name:        "no authority ID provided",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "no authority ID provided",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: no authority ID provided",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "no authority ID provided",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name:        "no allow to taint a prepared key",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_PREPARED, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  nextAuthorityID,
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "only Old local authorities can be tainted",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: only Old local authorities can be tainted",
+					Data: logrus.Fields{
+						logrus.ErrorKey:            "unsupported local authority status: PREPARED",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "only Old local authorities can be tainted: unsupported local authority status: PREPARED",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+			},
+		},
+		{
+			name:        "unable to taint current key",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  currentAuthorityID,
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "unable to taint current local authority",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: unable to taint current local authority",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: currentAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "unable to taint current local authority",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: currentAuthorityID,
+					},
+				},
+			},
+		},
+		{
+			name:        "authority ID not found",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  authorityIDKeyA,
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "unexpected authority ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: unexpected authority ID",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "unexpected authority ID",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+					},
+				},
+			},
+		},
+		{
+			name:             "failed to taint already tainted key",
+			currentSlot:      createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:         createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:       nextAuthorityID,
+			nextKeyIsTainted: true,
+			expectCode:       codes.Internal,
+			expectMsg:        "failed to taint JWT authority: key is already tainted",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to taint JWT authority",
+					Data: logrus.Fields{
+						logrus.ErrorKey:            "rpc error: code = InvalidArgument desc = key is already tainted",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "Internal",
+						telemetry.StatusMessage:    "failed to taint JWT authority: key is already tainted",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+			},
+		},
+	} {
+		test := setupServiceTest(t)
+		defer test.Cleanup()
+
+		test.ca.disableJWTSVIDs = tt.disableJWTSVIDs
+		test.ca.currentJWTKeySlot = tt.currentSlot
+		test.ca.nextJWTKeySlot = tt.nextSlot
+		_, err := test.ds.CreateBundle(ctx, &common.Bundle{
+			TrustDomainId: serverTrustDomain.IDString(),
+			JwtSigningKeys: []*common.PublicKey{
+				{
+					PkixBytes: currentPublicKeyRaw,
+					Kid:       currentAuthorityID,
+					NotAfter:  currentKeyNotAfter.Unix(),
+				},
+				{
+					PkixBytes:  nextPublicKeyRaw,
+					Kid:        nextAuthorityID,
+					NotAfter:   nextKeyNotAfter.Unix(),
+					TaintedKey: tt.nextKeyIsTainted,
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		resp, err := test.client.TaintJWTAuthority(ctx, &localauthorityv1.TaintJWTAuthorityRequest{
+			AuthorityId: tt.keyToTaint,
+		})
+
+		spiretest.AssertGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+		spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+	}
+}
+
+func TestTaintWITAuthority(t *testing.T) {
+	clk := clock.New()
+
+	currentKey := keyA
+	currentPublicKeyRaw, err := x509.MarshalPKIXPublicKey(currentKey.Public())
+	require.NoError(t, err)
+	currentAuthorityID := "key1"
+	currentKeyNotAfter := clk.Now().Add(time.Minute)
+
+	nextKey := keyB
+	nextPublicKeyRaw, err := x509.MarshalPKIXPublicKey(nextKey.Public())
+	require.NoError(t, err)
+	nextAuthorityID := "key2"
+	nextKeyNotAfter := clk.Now().Add(2 * time.Minute)
+
+	for _, tt := range []struct {
+		name            string
+		disableWITSVIDs bool
+		currentSlot     *fakeSlot
+		nextSlot        *fakeSlot
+		keyToTaint      string
+
+		expectLogs       []spiretest.LogEntry
+		expectCode       codes.Code
+		expectMsg        string
+		expectResp       *localauthorityv1.TaintWITAuthorityResponse
+		nextKeyIsTainted bool
+	}{
+		{
+			name:            "WIT is disabled",
+			disableWITSVIDs: true,
+			currentSlot:     &fakeSlot{},
+			nextSlot:        &fakeSlot{},
+			expectCode:      codes.Unimplemented,
+			expectMsg:       "WIT functionality is disabled",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "WIT functionality is disabled",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unimplemented",
+						telemetry.StatusMessage: "WIT functionality is disabled",
+					},
+				},
+			},
+		},
+		{
+			name:        "taint old authority",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  nextAuthorityID,
+			expectResp: &localauthorityv1.TaintWITAuthorityResponse{
+				TaintedAuthority: &localauthorityv1.AuthorityState{
+					AuthorityId: nextAuthorityID,
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "WIT authority tainted successfully",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+			},
+		},
+		{
+			name:        "no authority ID provided",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "no authority ID provided",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: no authority ID provided",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "no authority ID provided",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name:        "no allow to taint a prepared key",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_PREPARED, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  nextAuthorityID,
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "only Old local authorities can be tainted",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: only Old local authorities can be tainted",
+					Data: logrus.Fields{
+						logrus.ErrorKey:            "unsupported local authority status: PREPARED",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "only Old local authorities can be tainted: unsupported local authority status: PREPARED",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+			},
+		},
+		{
+			name:        "unable to taint current key",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  currentAuthorityID,
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "unable to taint current local authority",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: unable to taint current local authority",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: currentAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "unable to taint current local authority",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: currentAuthorityID,
+					},
+				},
+			},
+		},
+		{
+			name:        "authority ID not found",
+			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:  authorityIDKeyA,
+			expectCode:  codes.InvalidArgument,
+			expectMsg:   "unexpected authority ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: unexpected authority ID",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "unexpected authority ID",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: authorityIDKeyA,
+					},
+				},
+			},
+		},
+		{
+			name:             "failed to taint already tainted key",
+			currentSlot:      createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:         createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			keyToTaint:       nextAuthorityID,
+			nextKeyIsTainted: true,
+			expectCode:       codes.Internal,
+			expectMsg:        "failed to taint WIT authority: key is already tainted",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to taint WIT authority",
+					Data: logrus.Fields{
+						logrus.ErrorKey:            "rpc error: code = InvalidArgument desc = key is already tainted",
+						telemetry.LocalAuthorityID: nextAuthorityID,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.StatusCode:       "Internal",
+						telemetry.StatusMessage:    "failed to taint WIT authority: key is already tainted",
 						telemetry.Type:             "audit",
-						telemetry.LocalAuthorityID: authorityIDKeyB,
+						telemetry.LocalAuthorityID: nextAuthorityID,
 					},
 				},
 			},
 		},
-		{
-			name:        "no authority ID provided",
-			currentSlot: createSlot(journal.Status_ACTIVE, authorityIDKeyA, keyA.Public(), notAfterCurrent),
-			nextSlot:    createSlot(journal.Status_PREPARED, authorityIDKeyB, keyB.Public(), notAfterNext),
-			expectCode:  codes.InvalidArgument,
-			expectMsg:   "no authority ID provided",
-			expectLogs: []spiretest.LogEntry{
+	} {
+		test := setupServiceTest(t)
+		defer test.Cleanup()
+
+		test.ca.disableWITSVIDs = tt.disableWITSVIDs
+		test.ca.currentWITKeySlot = tt.currentSlot
+		test.ca.nextWITKeySlot = tt.nextSlot
+		_, err := test.ds.CreateBundle(ctx, &common.Bundle{
+			TrustDomainId: serverTrustDomain.IDString(),
+			WitSigningKeys: []*common.PublicKey{
 				{
-					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: no authority ID provided",
+					PkixBytes: currentPublicKeyRaw,
+					Kid:       currentAuthorityID,
+					NotAfter:  currentKeyNotAfter.Unix(),
 				},
 				{
-					Level:   logrus.InfoLevel,
-					Message: "API accessed",
-					Data: logrus.Fields{
-						telemetry.Status:        "error",
-						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "no authority ID provided",
-						telemetry.Type:          "audit",
-					},
+					PkixBytes:  nextPublicKeyRaw,
+					Kid:        nextAuthorityID,
+					NotAfter:   nextKeyNotAfter.Unix(),
+					TaintedKey: tt.nextKeyIsTainted,
 				},
 			},
-		},
-	} {
-		t.Run(tt.name, func(t *testing.T) {
-			test := setupServiceTest(t)
-			defer test.Cleanup()
-
-			test.ca.disableJWTSVIDs = tt.disableJWTSVIDs
-			test.ca.currentJWTKeySlot = tt.currentSlot
-			test.ca.nextJWTKeySlot = tt.nextSlot
-
-			resp, err := test.client.ActivateJWTAuthority(ctx, &localauthorityv1.ActivateJWTAuthorityRequest{
-				AuthorityId: tt.keyToActivate,
-			})
+		})
+		require.NoError(t, err)
 
-			require.Equal(t, tt.rotateCalled, test.ca.rotateJWTKeyCalled)
-			spiretest.AssertGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
-			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
-			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+		resp, err := test.client.TaintWITAuthority(ctx, &localauthorityv1.TaintWITAuthorityRequest{
+			AuthorityId: tt.keyToTaint,
 		})
+
+		spiretest.AssertGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+		spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
 	}
 }
 
-func TestTaintJWTAuthority(t *testing.T) {
+func TestRevokeJWTAuthority(t *testing.T) {
 	clk := clock.New()
 
 	currentKey := keyA
@@ -545,20 +1538,26 @@ func TestTaintJWTAuthority(t *testing.T) {
 	nextPublicKeyRaw, err := x509.MarshalPKIXPublicKey(nextKey.Public())
 	require.NoError(t, err)
 	nextAuthorityID := "key2"
-	nextKeyNotAfter := clk.Now().Add(2 * time.Minute)
+	nextKeyNotAfter := clk.Now().Add(time.Minute)
+
+	oldKey := keyC
+	oldPublicKeyRaw, err := x509.MarshalPKIXPublicKey(oldKey.Public())
+	require.NoError(t, err)
+	oldAuthorityID := "key3"
+	oldKeyNotAfter := clk.Now()
 
 	for _, tt := range []struct {
 		name            string
 		disableJWTSVIDs bool
 		currentSlot     *fakeSlot
 		nextSlot        *fakeSlot
-		keyToTaint      string
+		keyToRevoke     string
+		noTaintedKeys   bool
 
-		expectLogs       []spiretest.LogEntry
-		expectCode       codes.Code
-		expectMsg        string
-		expectResp       *localauthorityv1.TaintJWTAuthorityResponse
-		nextKeyIsTainted bool
+		expectLogs []spiretest.LogEntry
+		expectCode codes.Code
+		expectMsg  string
+		expectResp *localauthorityv1.RevokeJWTAuthorityResponse
 	}{
 		{
 			name:            "JWT is disabled",
@@ -585,13 +1584,13 @@ func TestTaintJWTAuthority(t *testing.T) {
 			},
 		},
 		{
-			name:        "taint old authority",
+			name:        "revoke authority from parameter",
 			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
 			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
-			keyToTaint:  nextAuthorityID,
-			expectResp: &localauthorityv1.TaintJWTAuthorityResponse{
-				TaintedAuthority: &localauthorityv1.AuthorityState{
-					AuthorityId: nextAuthorityID,
+			keyToRevoke: oldAuthorityID,
+			expectResp: &localauthorityv1.RevokeJWTAuthorityResponse{
+				RevokedAuthority: &localauthorityv1.AuthorityState{
+					AuthorityId: oldAuthorityID,
 				},
 			},
 			expectLogs: []spiretest.LogEntry{
@@ -601,14 +1600,14 @@ func TestTaintJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "success",
 						telemetry.Type:             "audit",
-						telemetry.LocalAuthorityID: nextAuthorityID,
+						telemetry.LocalAuthorityID: oldAuthorityID,
 					},
 				},
 				{
 					Level:   logrus.InfoLevel,
-					Message: "JWT authority tainted successfully",
+					Message: "JWT authority revoked successfully",
 					Data: logrus.Fields{
-						telemetry.LocalAuthorityID: nextAuthorityID,
+						telemetry.LocalAuthorityID: oldAuthorityID,
 					},
 				},
 			},
@@ -616,13 +1615,16 @@ func TestTaintJWTAuthority(t *testing.T) {
 		{
 			name:        "no authority ID provided",
 			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
-			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
+			nextSlot:    createSlot(journal.Status_PREPARED, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
 			expectCode:  codes.InvalidArgument,
-			expectMsg:   "no authority ID provided",
+			expectMsg:   "invalid authority ID: no authority ID provided",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: no authority ID provided",
+					Message: "Invalid argument: invalid authority ID",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "no authority ID provided",
+					},
 				},
 				{
 					Level:   logrus.InfoLevel,
@@ -630,25 +1632,25 @@ func TestTaintJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "no authority ID provided",
+						telemetry.StatusMessage: "invalid authority ID: no authority ID provided",
 						telemetry.Type:          "audit",
 					},
 				},
 			},
 		},
 		{
-			name:        "no allow to taint a prepared key",
+			name:        "not allow to revoke a prepared key",
 			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
 			nextSlot:    createSlot(journal.Status_PREPARED, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
-			keyToTaint:  nextAuthorityID,
+			keyToRevoke: nextAuthorityID,
 			expectCode:  codes.InvalidArgument,
-			expectMsg:   "only Old local authorities can be tainted",
+			expectMsg:   "invalid authority ID: unable to use a prepared key",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: only Old local authorities can be tainted",
+					Message: "Invalid argument: invalid authority ID",
 					Data: logrus.Fields{
-						logrus.ErrorKey:            "unsupported local authority status: PREPARED",
+						logrus.ErrorKey:            "unable to use a prepared key",
 						telemetry.LocalAuthorityID: nextAuthorityID,
 					},
 				},
@@ -658,7 +1660,7 @@ func TestTaintJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "InvalidArgument",
-						telemetry.StatusMessage:    "only Old local authorities can be tainted: unsupported local authority status: PREPARED",
+						telemetry.StatusMessage:    "invalid authority ID: unable to use a prepared key",
 						telemetry.Type:             "audit",
 						telemetry.LocalAuthorityID: nextAuthorityID,
 					},
@@ -666,17 +1668,18 @@ func TestTaintJWTAuthority(t *testing.T) {
 			},
 		},
 		{
-			name:        "unable to taint current key",
+			name:        "unable to revoke current key",
 			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
 			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
-			keyToTaint:  currentAuthorityID,
+			keyToRevoke: currentAuthorityID,
 			expectCode:  codes.InvalidArgument,
-			expectMsg:   "unable to taint current local authority",
+			expectMsg:   "invalid authority ID: unable to use current authority",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: unable to taint current local authority",
+					Message: "Invalid argument: invalid authority ID",
 					Data: logrus.Fields{
+						logrus.ErrorKey:            "unable to use current authority",
 						telemetry.LocalAuthorityID: currentAuthorityID,
 					},
 				},
@@ -686,7 +1689,7 @@ func TestTaintJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "InvalidArgument",
-						telemetry.StatusMessage:    "unable to taint current local authority",
+						telemetry.StatusMessage:    "invalid authority ID: unable to use current authority",
 						telemetry.Type:             "audit",
 						telemetry.LocalAuthorityID: currentAuthorityID,
 					},
@@ -694,17 +1697,18 @@ func TestTaintJWTAuthority(t *testing.T) {
 			},
 		},
 		{
-			name:        "authority ID not found",
+			name:        "ds fails to revoke",
 			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
 			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
-			keyToTaint:  authorityIDKeyA,
+			keyToRevoke: authorityIDKeyA,
 			expectCode:  codes.InvalidArgument,
-			expectMsg:   "unexpected authority ID",
+			expectMsg:   "invalid authority ID: no JWT authority found with provided authority ID",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: unexpected authority ID",
+					Message: "Invalid argument: invalid authority ID",
 					Data: logrus.Fields{
+						logrus.ErrorKey:            "no JWT authority found with provided authority ID",
 						telemetry.LocalAuthorityID: authorityIDKeyA,
 					},
 				},
@@ -714,7 +1718,7 @@ func TestTaintJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "InvalidArgument",
-						telemetry.StatusMessage:    "unexpected authority ID",
+						telemetry.StatusMessage:    "invalid authority ID: no JWT authority found with provided authority ID",
 						telemetry.Type:             "audit",
 						telemetry.LocalAuthorityID: authorityIDKeyA,
 					},
@@ -722,19 +1726,19 @@ func TestTaintJWTAuthority(t *testing.T) {
 			},
 		},
 		{
-			name:             "failed to taint already tainted key",
-			currentSlot:      createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
-			nextSlot:         createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
-			keyToTaint:       nextAuthorityID,
-			nextKeyIsTainted: true,
-			expectCode:       codes.Internal,
-			expectMsg:        "failed to taint JWT authority: key is already tainted",
+			name:          "failed to revoke untainted key",
+			currentSlot:   createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
+			nextSlot:      createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), currentKeyNotAfter),
+			keyToRevoke:   nextAuthorityID,
+			noTaintedKeys: true,
+			expectCode:    codes.Internal,
+			expectMsg:     "failed to revoke JWT authority: it is not possible to revoke an untainted key",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Failed to taint JWT authority",
+					Message: "Failed to revoke JWT authority",
 					Data: logrus.Fields{
-						logrus.ErrorKey:            "rpc error: code = InvalidArgument desc = key is already tainted",
+						logrus.ErrorKey:            "rpc error: code = InvalidArgument desc = it is not possible to revoke an untainted key",
 						telemetry.LocalAuthorityID: nextAuthorityID,
 					},
 				},
@@ -744,7 +1748,7 @@ func TestTaintJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "failed to taint JWT authority: key is already tainted",
+						telemetry.StatusMessage:    "failed to revoke JWT authority: it is not possible to revoke an untainted key",
 						telemetry.Type:             "audit",
 						telemetry.LocalAuthorityID: nextAuthorityID,
 					},
@@ -752,41 +1756,50 @@ func TestTaintJWTAuthority(t *testing.T) {
 			},
 		},
 	} {
-		test := setupServiceTest(t)
-		defer test.Cleanup()
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
 
-		test.ca.disableJWTSVIDs = tt.disableJWTSVIDs
-		test.ca.currentJWTKeySlot = tt.currentSlot
-		test.ca.nextJWTKeySlot = tt.nextSlot
-		_, err := test.ds.CreateBundle(ctx, &common.Bundle{
-			TrustDomainId: serverTrustDomain.IDString(),
-			JwtSigningKeys: []*common.PublicKey{
-				{
-					PkixBytes: currentPublicKeyRaw,
-					Kid:       currentAuthorityID,
-					NotAfter:  currentKeyNotAfter.Unix(),
-				},
-				{
-					PkixBytes:  nextPublicKeyRaw,
-					Kid:        nextAuthorityID,
-					NotAfter:   nextKeyNotAfter.Unix(),
-					TaintedKey: tt.nextKeyIsTainted,
+			test.ca.disableJWTSVIDs = tt.disableJWTSVIDs
+			test.ca.currentJWTKeySlot = tt.currentSlot
+			test.ca.nextJWTKeySlot = tt.nextSlot
+
+			_, err := test.ds.CreateBundle(ctx, &common.Bundle{
+				TrustDomainId: serverTrustDomain.IDString(),
+				JwtSigningKeys: []*common.PublicKey{
+					{
+						PkixBytes: currentPublicKeyRaw,
+						Kid:       currentAuthorityID,
+						NotAfter:  currentKeyNotAfter.Unix(),
+					},
+					{
+						PkixBytes:  nextPublicKeyRaw,
+						Kid:        nextAuthorityID,
+						NotAfter:   nextKeyNotAfter.Unix(),
+						TaintedKey: !tt.noTaintedKeys,
+					},
+					{
+						PkixBytes:  oldPublicKeyRaw,
+						Kid:        oldAuthorityID,
+						NotAfter:   oldKeyNotAfter.Unix(),
+						TaintedKey: !tt.noTaintedKeys,
+					},
 				},
-			},
-		})
-		require.NoError(t, err)
+			})
+			require.NoError(t, err)
 
-		resp, err := test.client.TaintJWTAuthority(ctx, &localauthorityv1.TaintJWTAuthorityRequest{
-			AuthorityId: tt.keyToTaint,
-		})
+			resp, err := test.client.RevokeJWTAuthority(ctx, &localauthorityv1.RevokeJWTAuthorityRequest{
+				AuthorityId: tt.keyToRevoke,
+			})
 
-		spiretest.AssertGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
-		spiretest.AssertProtoEqual(t, tt.expectResp, resp)
-		spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			spiretest.AssertGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+		})
 	}
 }
 
-func TestRevokeJWTAuthority(t *testing.T) {
+func TestRevokeWITAuthority(t *testing.T) {
 	clk := clock.New()
 
 	currentKey := keyA
@@ -809,7 +1822,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 
 	for _, tt := range []struct {
 		name            string
-		disableJWTSVIDs bool
+		disableWITSVIDs bool
 		currentSlot     *fakeSlot
 		nextSlot        *fakeSlot
 		keyToRevoke     string
@@ -818,19 +1831,19 @@ func TestRevokeJWTAuthority(t *testing.T) {
 		expectLogs []spiretest.LogEntry
 		expectCode codes.Code
 		expectMsg  string
-		expectResp *localauthorityv1.RevokeJWTAuthorityResponse
+		expectResp *localauthorityv1.RevokeWITAuthorityResponse
 	}{
 		{
-			name:            "JWT is disabled",
-			disableJWTSVIDs: true,
+			name:            "WIT is disabled",
+			disableWITSVIDs: true,
 			currentSlot:     &fakeSlot{},
 			nextSlot:        &fakeSlot{},
 			expectCode:      codes.Unimplemented,
-			expectMsg:       "JWT functionality is disabled",
+			expectMsg:       "WIT functionality is disabled",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "JWT functionality is disabled",
+					Message: "WIT functionality is disabled",
 				},
 				{
 					Level:   logrus.InfoLevel,
@@ -839,7 +1852,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
 						telemetry.StatusCode:    "Unimplemented",
-						telemetry.StatusMessage: "JWT functionality is disabled",
+						telemetry.StatusMessage: "WIT functionality is disabled",
 					},
 				},
 			},
@@ -849,7 +1862,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 			currentSlot: createSlot(journal.Status_ACTIVE, currentAuthorityID, currentKey.Public(), currentKeyNotAfter),
 			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
 			keyToRevoke: oldAuthorityID,
-			expectResp: &localauthorityv1.RevokeJWTAuthorityResponse{
+			expectResp: &localauthorityv1.RevokeWITAuthorityResponse{
 				RevokedAuthority: &localauthorityv1.AuthorityState{
 					AuthorityId: oldAuthorityID,
 				},
@@ -866,7 +1879,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 				},
 				{
 					Level:   logrus.InfoLevel,
-					Message: "JWT authority revoked successfully",
+					Message: "WIT authority revoked successfully",
 					Data: logrus.Fields{
 						telemetry.LocalAuthorityID: oldAuthorityID,
 					},
@@ -963,13 +1976,13 @@ func TestRevokeJWTAuthority(t *testing.T) {
 			nextSlot:    createSlot(journal.Status_OLD, nextAuthorityID, nextKey.Public(), nextKeyNotAfter),
 			keyToRevoke: authorityIDKeyA,
 			expectCode:  codes.InvalidArgument,
-			expectMsg:   "invalid authority ID: no JWT authority found with provided authority ID",
+			expectMsg:   "invalid authority ID: no WIT authority found with provided authority ID",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
 					Message: "Invalid argument: invalid authority ID",
 					Data: logrus.Fields{
-						logrus.ErrorKey:            "no JWT authority found with provided authority ID",
+						logrus.ErrorKey:            "no WIT authority found with provided authority ID",
 						telemetry.LocalAuthorityID: authorityIDKeyA,
 					},
 				},
@@ -979,7 +1992,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "InvalidArgument",
-						telemetry.StatusMessage:    "invalid authority ID: no JWT authority found with provided authority ID",
+						telemetry.StatusMessage:    "invalid authority ID: no WIT authority found with provided authority ID",
 						telemetry.Type:             "audit",
 						telemetry.LocalAuthorityID: authorityIDKeyA,
 					},
@@ -993,11 +2006,11 @@ func TestRevokeJWTAuthority(t *testing.T) {
 			keyToRevoke:   nextAuthorityID,
 			noTaintedKeys: true,
 			expectCode:    codes.Internal,
-			expectMsg:     "failed to revoke JWT authority: it is not possible to revoke an untainted key",
+			expectMsg:     "failed to revoke WIT authority: it is not possible to revoke an untainted key",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Failed to revoke JWT authority",
+					Message: "Failed to revoke WIT authority",
 					Data: logrus.Fields{
 						logrus.ErrorKey:            "rpc error: code = InvalidArgument desc = it is not possible to revoke an untainted key",
 						telemetry.LocalAuthorityID: nextAuthorityID,
@@ -1009,7 +2022,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:           "error",
 						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "failed to revoke JWT authority: it is not possible to revoke an untainted key",
+						telemetry.StatusMessage:    "failed to revoke WIT authority: it is not possible to revoke an untainted key",
 						telemetry.Type:             "audit",
 						telemetry.LocalAuthorityID: nextAuthorityID,
 					},
@@ -1021,13 +2034,13 @@ func TestRevokeJWTAuthority(t *testing.T) {
 			test := setupServiceTest(t)
 			defer test.Cleanup()
 
-			test.ca.disableJWTSVIDs = tt.disableJWTSVIDs
-			test.ca.currentJWTKeySlot = tt.currentSlot
-			test.ca.nextJWTKeySlot = tt.nextSlot
+			test.ca.disableWITSVIDs = tt.disableWITSVIDs
+			test.ca.currentWITKeySlot = tt.currentSlot
+			test.ca.nextWITKeySlot = tt.nextSlot
 
 			_, err := test.ds.CreateBundle(ctx, &common.Bundle{
 				TrustDomainId: serverTrustDomain.IDString(),
-				JwtSigningKeys: []*common.PublicKey{
+				WitSigningKeys: []*common.PublicKey{
 					{
 						PkixBytes: currentPublicKeyRaw,
 						Kid:       currentAuthorityID,
@@ -1049,7 +2062,7 @@ func TestRevokeJWTAuthority(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			resp, err := test.client.RevokeJWTAuthority(ctx, &localauthorityv1.RevokeJWTAuthorityRequest{
+			resp, err := test.client.RevokeWITAuthority(ctx, &localauthorityv1.RevokeWITAuthorityRequest{
 				AuthorityId: tt.keyToRevoke,
 			})
 
@@ -2616,6 +3629,13 @@ type fakeCAManager struct {
 	prepareJWTKeyErr error
 	disableJWTSVIDs  bool
 
+	currentWITKeySlot  *fakeSlot
+	nextWITKeySlot     *fakeSlot
+	rotateWITKeyCalled bool
+
+	prepareWITKeyErr error
+	disableWITSVIDs  bool
+
 	prepareX509CAErr    error
 	isUpstreamAuthority bool
 
@@ -2651,6 +3671,22 @@ func (m *fakeCAManager) RotateJWTKey(context.Context) {
 	m.rotateJWTKeyCalled = true
 }
 
+func (m *fakeCAManager) GetCurrentWITKeySlot() manager.Slot {
+	return m.currentWITKeySlot
+}
+
+func (m *fakeCAManager) GetNextWITKeySlot() manager.Slot {
+	return m.nextWITKeySlot
+}
+
+func (m *fakeCAManager) PrepareWITKey(context.Context) error {
+	return m.prepareWITKeyErr
+}
+
+func (m *fakeCAManager) RotateWITKey(context.Context) {
+	m.rotateWITKeyCalled = true
+}
+
 func (m *fakeCAManager) GetCurrentX509CASlot() manager.Slot {
 	return m.currentX509CASlot
 }
@@ -2671,6 +3707,10 @@ func (m *fakeCAManager) IsJWTSVIDsDisabled() bool {
 	return m.disableJWTSVIDs
 }
 
+func (m *fakeCAManager) IsWITSVIDsDisabled() bool {
+	return m.disableWITSVIDs
+}
+
 type fakeSlot struct {
 	manager.Slot
 
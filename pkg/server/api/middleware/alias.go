@@ -35,6 +35,10 @@ func WithMetrics(metrics telemetry.Metrics) Middleware {
 	return middleware.WithMetrics(metrics)
 }
 
+func WithTracing() Middleware {
+	return middleware.WithTracing()
+}
+
 func Interceptors(m Middleware) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
 	return middleware.Interceptors(m)
 }
@@ -14,8 +14,15 @@ import (
 )
 
 func WithAuditLog(localTrackerEnabled bool) Middleware {
+	return WithAuditLogSink(localTrackerEnabled, nil)
+}
+
+// WithAuditLogSink returns an audit log middleware that also appends the
+// audit records to the given sink, if not nil.
+func WithAuditLogSink(localTrackerEnabled bool, sink audit.Sink) Middleware {
 	return auditLogMiddleware{
 		localTrackerEnabled: localTrackerEnabled,
+		sink:                sink,
 	}
 }
 
@@ -23,6 +30,7 @@ type auditLogMiddleware struct {
 	Middleware
 
 	localTrackerEnabled bool
+	sink                audit.Sink
 }
 
 func (m auditLogMiddleware) Preprocess(ctx context.Context, _ string, _ any) (context.Context, error) {
@@ -36,7 +44,7 @@ func (m auditLogMiddleware) Preprocess(ctx context.Context, _ string, _ any) (co
 		log = log.WithFields(fields)
 	}
 
-	auditLog := audit.New(log)
+	auditLog := audit.NewWithSink(log, m.sink)
 
 	ctx = rpccontext.WithAuditLog(ctx, auditLog)
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"sync/atomic"
 
 	"github.com/spiffe/spire/pkg/common/api/middleware"
 	"github.com/spiffe/spire/pkg/common/ratelimit"
@@ -73,12 +74,45 @@ func PerIPLimit(limit int) api.RateLimiter {
 // The WithRateLimits middleware depends on the Logger and Authorization
 // middlewares.
 func WithRateLimits(rateLimits map[string]api.RateLimiter, metrics telemetry.Metrics) middleware.Middleware {
+	return WithRateLimiters(NewRateLimiters(rateLimits), metrics)
+}
+
+// WithRateLimiters is like WithRateLimits but the rate limiters can be
+// replaced while the middleware is in use (e.g. when the rate limiting
+// configuration is reloaded).
+func WithRateLimiters(rateLimiters *RateLimiters, metrics telemetry.Metrics) middleware.Middleware {
 	return rateLimitsMiddleware{
-		limiters: rateLimits,
+		limiters: rateLimiters,
 		metrics:  metrics,
 	}
 }
 
+// RateLimiters holds the rate limiters for a group of methods.
+type RateLimiters struct {
+	limiters atomic.Pointer[map[string]api.RateLimiter]
+}
+
+// NewRateLimiters returns the rate limiters for the group of methods
+// described by the rateLimits map. It owns the passed map and assumes it will
+// not be mutated after the function is called.
+func NewRateLimiters(rateLimits map[string]api.RateLimiter) *RateLimiters {
+	r := new(RateLimiters)
+	r.Set(rateLimits)
+	return r
+}
+
+// Set replaces the rate limiters. Calls that are already in progress keep
+// the rate limiter they started with. Set owns the passed map and assumes it
+// will not be mutated after the method is called.
+func (r *RateLimiters) Set(rateLimits map[string]api.RateLimiter) {
+	r.limiters.Store(&rateLimits)
+}
+
+func (r *RateLimiters) get(fullMethod string) (api.RateLimiter, bool) {
+	rateLimiter, ok := (*r.limiters.Load())[fullMethod]
+	return rateLimiter, ok
+}
+
 type noLimit struct{}
 
 func (noLimit) RateLimit(context.Context, int) error {
@@ -130,12 +164,12 @@ func (lim *perIPLimiter) RateLimit(ctx context.Context, count int) error {
 }
 
 type rateLimitsMiddleware struct {
-	limiters map[string]api.RateLimiter
+	limiters *RateLimiters
 	metrics  telemetry.Metrics
 }
 
 func (i rateLimitsMiddleware) Preprocess(ctx context.Context, fullMethod string, _ any) (context.Context, error) {
-	rateLimiter, ok := i.limiters[fullMethod]
+	rateLimiter, ok := i.limiters.get(fullMethod)
 	if !ok {
 		middleware.LogMisconfiguration(ctx, "Rate limiting misconfigured; this is a bug")
 		return nil, status.Errorf(codes.Internal, "rate limiting misconfigured for %q", fullMethod)
@@ -316,6 +316,33 @@ func TestRateLimits(t *testing.T) {
 	}
 }
 
+func TestRateLimitersSet(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	ctx := rpccontext.WithLogger(context.Background(), log)
+	serverInfo := &grpc.UnaryServerInfo{FullMethod: "/fake.Service/WithLimit"}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		if err := rpccontext.RateLimit(ctx, 2); err != nil {
+			return nil, err
+		}
+		return struct{}{}, nil
+	}
+
+	rateLimiters := NewRateLimiters(map[string]api.RateLimiter{
+		"/fake.Service/WithLimit": PerCallLimit(1),
+	})
+	unaryInterceptor := middleware.UnaryInterceptor(WithRateLimiters(rateLimiters, fakemetrics.New()))
+
+	_, err := unaryInterceptor(ctx, struct{}{}, serverInfo, handler)
+	spiretest.AssertGRPCStatus(t, err, codes.ResourceExhausted, "rate (2) exceeds burst size (1)")
+
+	// Calls made after the rate limiters are replaced use the new limits
+	rateLimiters.Set(map[string]api.RateLimiter{
+		"/fake.Service/WithLimit": PerCallLimit(2),
+	})
+	_, err = unaryInterceptor(ctx, struct{}{}, serverInfo, handler)
+	require.NoError(t, err)
+}
+
 type WaitNEvent struct {
 	ID    int
 	Count int
@@ -7,6 +7,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -17,8 +18,10 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
+	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
@@ -248,13 +251,72 @@ func (s *Service) findEntries(ctx context.Context, log logrus.FieldLogger, entri
 		return nil, api.MakeErr(log, codes.Internal, "caller ID missing from request context", nil)
 	}
 
-	foundEntries, err := s.ef.LookupAuthorizedEntries(ctx, callerID, entries)
+	// Entries derived from entry templates are authorized through the
+	// template entry they were derived from.
+	lookupEntries := make(map[string]struct{}, len(entries))
+	derivedEntries := make(map[string]string)
+	for entryID := range entries {
+		if templateEntryID, _, ok := entrytemplate.ParseDerivedEntryID(entryID); ok {
+			derivedEntries[entryID] = templateEntryID
+			lookupEntries[templateEntryID] = struct{}{}
+			continue
+		}
+		lookupEntries[entryID] = struct{}{}
+	}
+
+	foundEntries, err := s.ef.LookupAuthorizedEntries(ctx, callerID, lookupEntries)
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to fetch registration entries", err)
 	}
+
+	for entryID, templateEntryID := range derivedEntries {
+		templateEntry, ok := foundEntries[templateEntryID]
+		if !ok {
+			continue
+		}
+		entry, err := s.deriveEntry(entryID, templateEntry)
+		if err != nil {
+			log.WithError(err).WithField(telemetry.RegistrationID, entryID).Warn("Failed to derive entry from entry template")
+			continue
+		}
+		foundEntries[entryID] = entry
+	}
+
+	// SVIDs cannot be minted for the entry templates themselves
+	for entryID := range foundEntries {
+		if entrytemplate.IsTemplateEntryID(entryID) {
+			delete(foundEntries, entryID)
+		}
+	}
 	return foundEntries, nil
 }
 
+// deriveEntry derives the entry with the given ID from an entry template,
+// rendering its SPIFFE ID from the selectors encoded in the entry ID.
+func (s *Service) deriveEntry(entryID string, templateEntry api.ReadOnlyEntry) (api.ReadOnlyEntry, error) {
+	tmpl, err := api.TrustDomainWorkloadTemplateFromProto(s.td, templateEntry.GetSpiffeId())
+	if err != nil {
+		return api.ReadOnlyEntry{}, fmt.Errorf("invalid entry template: %w", err)
+	}
+
+	_, selectors, _ := entrytemplate.ParseDerivedEntryID(entryID)
+	spiffeID, used, err := tmpl.Render(selectors)
+	if err != nil {
+		return api.ReadOnlyEntry{}, err
+	}
+	if entrytemplate.DerivedEntryID(templateEntry.GetId(), used) != entryID {
+		return api.ReadOnlyEntry{}, errors.New("entry ID does not match the selectors referenced by the entry template")
+	}
+	if err := api.VerifyTrustDomainWorkloadID(s.td, spiffeID); err != nil {
+		return api.ReadOnlyEntry{}, err
+	}
+
+	entry := templateEntry.Clone(protoutil.AllTrueEntryMask)
+	entry.Id = entryID
+	entry.SpiffeId = api.ProtoFromID(spiffeID)
+	return api.NewReadOnlyEntry(entry), nil
+}
+
 // newX509SVID creates an X509-SVID using data from registration entry and key from CSR
 func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDParams, entries map[string]api.ReadOnlyEntry) *svidv1.BatchNewX509SVIDResponse_Result {
 	log := rpccontext.Logger(ctx)
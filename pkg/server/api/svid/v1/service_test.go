@@ -21,6 +21,7 @@ import (
 
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -1572,7 +1573,24 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 		SpiffeId: &types.SPIFFEID{},
 		ParentId: api.ProtoFromID(agentID),
 	}
-	test.ef.entries = []*types.Entry{workloadEntry, dnsEntry, ttlEntry, x509TtlEntry, invalidEntry}
+	templateEntry := &types.Entry{
+		Id:       entrytemplate.EntryID("template"),
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{ .k8s.ns }}"},
+	}
+	test.ef.entries = []*types.Entry{workloadEntry, dnsEntry, ttlEntry, x509TtlEntry, invalidEntry, templateEntry}
+
+	derivedEntryID := entrytemplate.DerivedEntryID(templateEntry.Id, []*common.Selector{
+		{Type: "k8s", Value: "ns:web"},
+	})
+	derivedEntry := &types.Entry{
+		Id:       derivedEntryID,
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/web"},
+	}
+	mismatchedEntryID := entrytemplate.DerivedEntryID(templateEntry.Id, []*common.Selector{
+		{Type: "k8s", Value: "ns:web"},
+		{Type: "k8s", Value: "sa:default"},
+	})
 
 	now := test.ca.Clock().Now().UTC()
 
@@ -1934,6 +1952,108 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 					},
 				}
 			},
+		}, {
+			name: "entry derived from entry template",
+			reqs: []string{derivedEntryID},
+			expectResults: []*expectResult{
+				{
+					entry: derivedEntry,
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "success",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: derivedEntryID,
+							telemetry.Csr:            api.HashByte(m[derivedEntryID]),
+							telemetry.ExpiresAt:      expiresAtFromCAStr,
+							telemetry.SPIFFEID:       "spiffe://example.org/ns/web",
+						},
+					},
+				}
+			},
+		}, {
+			name: "entry derived from entry template with mismatched selectors",
+			reqs: []string{mismatchedEntryID},
+			expectResults: []*expectResult{
+				{
+					status: &types.Status{
+						Code:    int32(codes.NotFound),
+						Message: "entry not found or not authorized",
+					},
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.WarnLevel,
+						Message: "Failed to derive entry from entry template",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: mismatchedEntryID,
+							logrus.ErrorKey:          "entry ID does not match the selectors referenced by the entry template",
+						},
+					},
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Entry not found or not authorized",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: mismatchedEntryID,
+						},
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: mismatchedEntryID,
+							telemetry.Csr:            api.HashByte(m[mismatchedEntryID]),
+							telemetry.StatusCode:     "NotFound",
+							telemetry.StatusMessage:  "entry not found or not authorized",
+							telemetry.SPIFFEID:       "",
+						},
+					},
+				}
+			},
+		}, {
+			name: "entry template",
+			reqs: []string{templateEntry.Id},
+			expectResults: []*expectResult{
+				{
+					status: &types.Status{
+						Code:    int32(codes.NotFound),
+						Message: "entry not found or not authorized",
+					},
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Entry not found or not authorized",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: templateEntry.Id,
+						},
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: templateEntry.Id,
+							telemetry.Csr:            api.HashByte(m[templateEntry.Id]),
+							telemetry.StatusCode:     "NotFound",
+							telemetry.StatusMessage:  "entry not found or not authorized",
+							telemetry.SPIFFEID:       "",
+						},
+					},
+				}
+			},
 		}, {
 			name: "entry not found",
 			reqs: []string{"invalid entry"},
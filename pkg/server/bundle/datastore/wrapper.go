@@ -53,3 +53,11 @@ func (w datastoreWrapper) RevokeJWTKey(ctx context.Context, trustDomainID string
 	}
 	return pubKey, err
 }
+
+func (w datastoreWrapper) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	pubKey, err := w.DataStore.RevokeWITKey(ctx, trustDomainID, authorityID)
+	if err == nil {
+		w.bundleUpdated()
+	}
+	return pubKey, err
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -167,6 +168,10 @@ type Config struct {
 type CA struct {
 	c Config
 
+	// witSVIDsDisabled is initialized from the configuration and can be
+	// toggled at runtime when the WIT-SVID feature flag is reloaded.
+	witSVIDsDisabled atomic.Bool
+
 	mu                   sync.RWMutex
 	x509CA               *X509CA
 	x509CAChain          []*x509.Certificate
@@ -186,6 +191,7 @@ func NewCA(config Config) *CA {
 		// Notify caller about any tainted authority
 		taintedAuthoritiesCh: make(chan []*x509.Certificate, 1),
 	}
+	ca.witSVIDsDisabled.Store(config.DisableWITSVIDs)
 
 	_ = config.HealthChecker.AddCheck("server.ca", &caHealth{
 		ca: ca,
@@ -476,7 +482,11 @@ func (ca *CA) IsJWTSVIDsDisabled() bool {
 }
 
 func (ca *CA) IsWITSVIDsDisabled() bool {
-	return ca.c.DisableWITSVIDs
+	return ca.witSVIDsDisabled.Load()
+}
+
+func (ca *CA) SetWITSVIDsDisabled(disabled bool) {
+	ca.witSVIDsDisabled.Store(disabled)
 }
 
 func (ca *CA) signWITSVID(witKey *WITKey, claims map[string]any) (string, error) {
@@ -104,7 +104,7 @@ func TestNew(t *testing.T) {
 		// Verify entries is empty
 		spiretest.RequireProtoEqual(t, &journal.Entries{}, j.getEntries())
 	}
-	caJournals, err := test.ds.ListCAJournalsForTesting(ctx)
+	caJournals, err := test.ds.ListCAJournals(ctx)
 	require.NoError(t, err)
 	require.Empty(t, caJournals)
 }
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -53,11 +54,13 @@ type ManagedCA interface {
 	SetX509CA(*ca.X509CA)
 	SetJWTKey(*ca.JWTKey)
 	SetWITKey(*ca.WITKey)
+	SetWITSVIDsDisabled(bool)
 	NotifyTaintedX509Authorities([]*x509.Certificate)
 }
 
-type JwtKeyPublisher interface {
+type KeyPublisher interface {
 	PublishJWTKey(ctx context.Context, jwtKey *common.PublicKey) ([]*common.PublicKey, error)
+	PublishWITKey(ctx context.Context, witKey *common.PublicKey) ([]*common.PublicKey, error)
 }
 
 type AuthorityManager interface {
@@ -77,6 +80,7 @@ type AuthorityManager interface {
 	IsJWTSVIDsDisabled() bool
 	IsWITSVIDsDisabled() bool
 	PublishJWTKey(ctx context.Context, jwtKey *common.PublicKey) ([]*common.PublicKey, error)
+	PublishWITKey(ctx context.Context, witKey *common.PublicKey) ([]*common.PublicKey, error)
 	NotifyTaintedX509Authority(ctx context.Context, authorityID string) error
 	SubscribeToLocalBundle(ctx context.Context) error
 }
@@ -118,11 +122,18 @@ type Manager struct {
 	nextWITKey    *witKeySlot
 	witKeyMutex   sync.RWMutex
 
+	// witSVIDsDisabled is initialized from the configuration and can be
+	// toggled at runtime with SetWITSVIDsDisabled.
+	witSVIDsDisabled atomic.Bool
+
 	journal *Journal
 
 	// Used to log a warning only once when the UpstreamAuthority does not support JWT-SVIDs.
 	jwtUnimplementedWarnOnce sync.Once
 
+	// Used to log a warning only once when the UpstreamAuthority does not support WIT-SVIDs.
+	witUnimplementedWarnOnce sync.Once
+
 	// Used for testing backoff, must not be set in regular code
 	triggerBackOffCh chan error
 }
@@ -138,10 +149,13 @@ func NewManager(ctx context.Context, c Config) (*Manager, error) {
 		bundleUpdatedCh:              make(chan struct{}, 1),
 		taintedUpstreamAuthoritiesCh: make(chan []*x509.Certificate, 1),
 	}
+	m.witSVIDsDisabled.Store(c.DisableWITSVIDs)
 
 	if upstreamAuthority, ok := c.Catalog.GetUpstreamAuthority(); ok {
+		witKeyPublisher, _ := c.Catalog.GetWITKeyPublisher()
 		m.upstreamClient = ca.NewUpstreamClient(ca.UpstreamClientConfig{
 			UpstreamAuthority: upstreamAuthority,
+			WITKeyPublisher:   witKeyPublisher,
 			BundleUpdater: &bundleUpdater{
 				log:                         c.Log,
 				trustDomainID:               c.TrustDomain.IDString(),
@@ -238,7 +252,33 @@ func (m *Manager) IsJWTSVIDsDisabled() bool {
 }
 
 func (m *Manager) IsWITSVIDsDisabled() bool {
-	return m.c.DisableWITSVIDs
+	return m.witSVIDsDisabled.Load()
+}
+
+// SetWITSVIDsDisabled enables or disables the WIT-SVID profile at runtime.
+// When disabled, the WIT key is removed from the CA and the WIT key slots are
+// no longer prepared or rotated. When enabled again, the current WIT key is
+// reactivated if it has not expired; otherwise the rotator prepares a new one.
+func (m *Manager) SetWITSVIDsDisabled(ctx context.Context, disabled bool) {
+	m.witKeyMutex.Lock()
+	defer m.witKeyMutex.Unlock()
+
+	if m.witSVIDsDisabled.Load() == disabled {
+		return
+	}
+	m.witSVIDsDisabled.Store(disabled)
+	m.c.CA.SetWITSVIDsDisabled(disabled)
+
+	if disabled {
+		m.c.CA.SetWITKey(nil)
+		m.c.Log.Info("WIT-SVIDs disabled; WIT key deactivated")
+		return
+	}
+
+	m.c.Log.Info("WIT-SVIDs enabled")
+	if !m.currentWITKey.IsEmpty() && m.c.Clock.Now().Before(m.currentWITKey.witKey.NotAfter) {
+		m.activateWITKey(ctx)
+	}
 }
 
 func (m *Manager) GetCurrentX509CASlot() Slot {
@@ -550,8 +590,7 @@ func (m *Manager) PrepareWITKey(ctx context.Context) (err error) {
 		return err
 	}
 
-	_, err = m.appendBundle(ctx, nil, nil, []*common.PublicKey{publicKey})
-	if err != nil {
+	if _, err := m.PublishWITKey(ctx, publicKey); err != nil {
 		return err
 	}
 
@@ -575,23 +614,26 @@ func (m *Manager) PrepareWITKey(ctx context.Context) (err error) {
 }
 
 func (m *Manager) ActivateWITKey(ctx context.Context) {
+	m.witKeyMutex.RLock()
+	defer m.witKeyMutex.RUnlock()
+
+	// Checked while holding the lock so that the key is not activated
+	// after WIT-SVIDs have been disabled.
 	if m.IsWITSVIDsDisabled() {
 		return
 	}
-	m.witKeyMutex.RLock()
-	defer m.witKeyMutex.RUnlock()
 
 	m.activateWITKey(ctx)
 }
 
 func (m *Manager) RotateWITKey(ctx context.Context) {
+	m.witKeyMutex.Lock()
+	defer m.witKeyMutex.Unlock()
+
 	if m.IsWITSVIDsDisabled() {
 		return
 	}
 
-	m.witKeyMutex.Lock()
-	defer m.witKeyMutex.Unlock()
-
 	m.currentWITKey, m.nextWITKey = m.nextWITKey, m.currentWITKey
 	m.nextWITKey.Reset()
 
@@ -602,6 +644,42 @@ func (m *Manager) RotateWITKey(ctx context.Context) {
 	m.activateWITKey(ctx)
 }
 
+// PublishWITKey publishes the passed WIT key to the upstream server using the
+// configured UpstreamAuthority plugin, then syncs the bundle with the WIT keys
+// returned by the upstream server, and finally it returns the updated list of
+// WIT keys contained in the bundle. It handles the same cases as
+// PublishJWTKey.
+func (m *Manager) PublishWITKey(ctx context.Context, witKey *common.PublicKey) ([]*common.PublicKey, error) {
+	if m.upstreamClient != nil {
+		publishCtx, cancel := context.WithTimeout(ctx, publishJWKTimeout)
+		defer cancel()
+		upstreamWITKeys, err := m.upstreamClient.PublishWITKey(publishCtx, witKey)
+		switch {
+		case status.Code(err) == codes.Unimplemented:
+			// WIT Key publishing is not supported by the upstream plugin.
+			// Issue a one-time warning and then fall through to the
+			// appendBundle call below as if an upstream client was not
+			// configured so the WIT key gets pushed into the local bundle.
+			m.witUnimplementedWarnOnce.Do(func() {
+				m.c.Log.WithField("plugin_name", m.upstreamPluginName).Warn("UpstreamAuthority plugin does not support WIT-SVIDs. Workloads managed " +
+					"by this server may have trouble communicating with workloads outside " +
+					"this cluster when using WIT-SVIDs.")
+			})
+		case err != nil:
+			return nil, err
+		default:
+			return upstreamWITKeys, nil
+		}
+	}
+
+	bundle, err := m.appendBundle(ctx, nil, nil, []*common.PublicKey{witKey})
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle.WitSigningKeys, nil
+}
+
 func (m *Manager) SubscribeToLocalBundle(ctx context.Context) error {
 	if m.upstreamClient == nil {
 		return nil
@@ -1168,6 +1246,53 @@ func (u *bundleUpdater) AppendJWTKeys(ctx context.Context, keys []*common.Public
 	return bundle.JwtSigningKeys, nil
 }
 
+// SyncWITKeys appends the WIT keys received from the upstream authority to the
+// bundle. Keys tainted upstream are tainted in the bundle, and tainted keys
+// that are no longer in the upstream bundle are revoked.
+func (u *bundleUpdater) SyncWITKeys(ctx context.Context, keys []*common.PublicKey) ([]*common.PublicKey, error) {
+	witKeys, err := u.fetchWITKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newKeys := make(map[string]struct{}, len(keys))
+	var appendKeys []*common.PublicKey
+	for _, key := range keys {
+		newKeys[key.Kid] = struct{}{}
+
+		found, ok := witKeys[key.Kid]
+		if !ok {
+			appendKeys = append(appendKeys, key)
+			continue
+		}
+		if key.TaintedKey && !found.TaintedKey {
+			if _, err := u.ds.TaintWITKey(ctx, u.trustDomainID, key.Kid); err != nil {
+				return nil, fmt.Errorf("failed to taint WIT key %q: %w", key.Kid, err)
+			}
+			u.log.WithField(telemetry.WITAuthorityKeyID, key.Kid).Info("WIT authority tainted")
+		}
+	}
+
+	for kid, key := range witKeys {
+		// Only tainted keys can be revoked
+		if _, found := newKeys[kid]; key.TaintedKey && !found {
+			if _, err := u.ds.RevokeWITKey(ctx, u.trustDomainID, kid); err != nil {
+				return nil, fmt.Errorf("failed to revoke a tainted WIT key %q: %w", kid, err)
+			}
+			u.log.WithField(telemetry.WITAuthorityKeyID, kid).Info("WIT authority revoked")
+		}
+	}
+
+	bundle, err := u.appendBundle(ctx, &common.Bundle{
+		TrustDomainId:  u.trustDomainID,
+		WitSigningKeys: appendKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundle.WitSigningKeys, nil
+}
+
 func (u *bundleUpdater) LogError(err error, msg string) {
 	u.log.WithError(err).Error(msg)
 }
@@ -1198,6 +1323,19 @@ func (u *bundleUpdater) fetchX509Authorities(ctx context.Context) (map[string]*x
 	return authorities, nil
 }
 
+func (u *bundleUpdater) fetchWITKeys(ctx context.Context) (map[string]*common.PublicKey, error) {
+	bundle, err := u.ds.FetchBundle(ctx, u.trustDomainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bundle: %w", err)
+	}
+
+	witKeys := make(map[string]*common.PublicKey)
+	for _, key := range bundle.GetWitSigningKeys() {
+		witKeys[key.Kid] = key
+	}
+	return witKeys, nil
+}
+
 func (u *bundleUpdater) appendBundle(ctx context.Context, bundle *common.Bundle) (*common.Bundle, error) {
 	dsBundle, err := u.ds.AppendBundle(ctx, bundle)
 	if err != nil {
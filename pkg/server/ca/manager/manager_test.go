@@ -720,6 +720,49 @@ func TestUpstreamAuthorityWithPublishJWTKeyImplemented(t *testing.T) {
 	)
 }
 
+func TestUpstreamAuthorityWithPublishWITKeyImplemented(t *testing.T) {
+	ctx := context.Background()
+	test := setupTest(t)
+	bundle := test.createBundle(ctx)
+	require.Len(t, bundle.WitSigningKeys, 0)
+
+	upstreamAuthority, ua := test.newFakeUpstreamAuthority(t, fakeupstreamauthority.Config{
+		TrustDomain: testTrustDomain,
+	})
+	test.initAndActivateUpstreamSignedManager(ctx, upstreamAuthority)
+
+	spiretest.AssertProtoListEqual(t, ua.WITKeys(), test.fetchBundle(ctx).WitSigningKeys)
+	assert.Equal(t,
+		0,
+		test.countLogEntries(logrus.WarnLevel, "UpstreamAuthority plugin does not support WIT-SVIDs. Workloads managed "+
+			"by this server may have trouble communicating with workloads outside "+
+			"this cluster when using WIT-SVIDs."),
+	)
+}
+
+func TestUpstreamAuthorityWithoutPublishWITKey(t *testing.T) {
+	ctx := context.Background()
+	test := setupTest(t)
+
+	upstreamAuthority, ua := test.newFakeUpstreamAuthority(t, fakeupstreamauthority.Config{
+		TrustDomain:           testTrustDomain,
+		DisallowPublishWITKey: true,
+	})
+	test.initAndActivateUpstreamSignedManager(ctx, upstreamAuthority)
+
+	// The WIT key is only added to the local bundle
+	require.Empty(t, ua.WITKeys())
+	witKeys := test.fetchBundle(ctx).WitSigningKeys
+	require.Len(t, witKeys, 1)
+	assert.Equal(t, test.currentWITKey().Kid, witKeys[0].Kid)
+	assert.Equal(t,
+		1,
+		test.countLogEntries(logrus.WarnLevel, "UpstreamAuthority plugin does not support WIT-SVIDs. Workloads managed "+
+			"by this server may have trouble communicating with workloads outside "+
+			"this cluster when using WIT-SVIDs."),
+	)
+}
+
 func TestUpstreamAuthorityWithSubscribeToBundleUpdate(t *testing.T) {
 	ctx := context.Background()
 	test := setupTest(t)
@@ -1164,7 +1207,7 @@ func TestPruneCAJournals(t *testing.T) {
 			}
 
 			require.NoError(t, test.m.PruneCAJournals(ctx))
-			caJournals, err := test.ds.ListCAJournalsForTesting(ctx)
+			caJournals, err := test.ds.ListCAJournals(ctx)
 			require.NoError(t, err)
 			require.ElementsMatch(t, expectedCAJournals, caJournals)
 		})
@@ -1292,6 +1335,40 @@ func TestDisableWITSVIDs(t *testing.T) {
 	require.True(t, slot.IsEmpty())
 }
 
+func TestToggleWITSVIDs(t *testing.T) {
+	ctx := context.Background()
+
+	test := setupTest(t)
+	test.initAndActivateSelfSignedManager(ctx)
+	witKey := test.currentWITKey()
+
+	// Disabling removes the WIT key from the CA and stops rotation
+	test.m.SetWITSVIDsDisabled(ctx, true)
+	assert.True(t, test.m.IsWITSVIDsDisabled())
+	assert.True(t, test.ca.witSVIDsDisabled)
+	assert.Nil(t, test.ca.WITKey())
+
+	test.m.ActivateWITKey(ctx)
+	assert.Nil(t, test.ca.WITKey())
+
+	test.m.RotateWITKey(ctx)
+	test.requireWITKeyEqual(t, witKey, test.m.currentWITKey.witKey)
+	assert.Nil(t, test.ca.WITKey())
+
+	// Enabling again reactivates the current WIT key
+	test.m.SetWITSVIDsDisabled(ctx, false)
+	assert.False(t, test.m.IsWITSVIDsDisabled())
+	assert.False(t, test.ca.witSVIDsDisabled)
+	test.requireWITKeyEqual(t, witKey, test.currentWITKey())
+
+	// Enabling after the current WIT key expired leaves the CA without a
+	// WIT key until the rotator prepares a new one
+	test.m.SetWITSVIDsDisabled(ctx, true)
+	test.clock.Set(witKey.NotAfter.Add(time.Second))
+	test.m.SetWITSVIDsDisabled(ctx, false)
+	assert.Nil(t, test.ca.WITKey())
+}
+
 func TestAlternateKeyTypes(t *testing.T) {
 	expectRSA := func(t *testing.T, signer crypto.Signer, keySize int) {
 		publicKey, ok := signer.Public().(*rsa.PublicKey)
@@ -1536,11 +1613,14 @@ func setupTest(t *testing.T) *managerTest {
 
 func (m *managerTest) newFakeUpstreamAuthority(t *testing.T, config fakeupstreamauthority.Config) (upstreamauthority.UpstreamAuthority, *fakeupstreamauthority.UpstreamAuthority) {
 	config.Clock = m.clock
-	return fakeupstreamauthority.Load(t, config)
+	upstreamAuthority, witKeyPublisher, fake := fakeupstreamauthority.LoadWithWITKeyPublisher(t, config)
+	m.cat.SetWITKeyPublisher(witKeyPublisher)
+	return upstreamAuthority, fake
 }
 
 func (m *managerTest) initSelfSignedManager() {
 	m.cat.SetUpstreamAuthority(nil)
+	m.cat.ClearWITKeyPublisher()
 	manager, err := NewManager(context.Background(), m.selfSignedConfig())
 	require.NoError(m.t, err)
 	m.m = manager
@@ -1548,6 +1628,7 @@ func (m *managerTest) initSelfSignedManager() {
 
 func (m *managerTest) initAndActivateSelfSignedManager(ctx context.Context) {
 	m.cat.SetUpstreamAuthority(nil)
+	m.cat.ClearWITKeyPublisher()
 	manager, err := NewManager(context.Background(), m.selfSignedConfig())
 	require.NoError(m.t, err)
 
@@ -1863,6 +1944,8 @@ type fakeCA struct {
 	jwtKey *ca.JWTKey
 	witKey *ca.WITKey
 
+	witSVIDsDisabled bool
+
 	taintedAuthoritiesCh chan []*x509.Certificate
 }
 
@@ -1902,6 +1985,12 @@ func (s *fakeCA) SetWITKey(witKey *ca.WITKey) {
 	s.witKey = witKey
 }
 
+func (s *fakeCA) SetWITSVIDsDisabled(disabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.witSVIDsDisabled = disabled
+}
+
 func (s *fakeCA) NotifyTaintedX509Authorities(taintedAuthorities []*x509.Certificate) {
 	s.taintedAuthoritiesCh <- taintedAuthorities
 }
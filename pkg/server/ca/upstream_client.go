@@ -20,6 +20,7 @@ import (
 type BundleUpdater interface {
 	SyncX509Roots(ctx context.Context, roots []*x509certificate.X509Authority) error
 	AppendJWTKeys(ctx context.Context, keys []*common.PublicKey) ([]*common.PublicKey, error)
+	SyncWITKeys(ctx context.Context, keys []*common.PublicKey) ([]*common.PublicKey, error)
 	LogError(err error, msg string)
 }
 
@@ -32,6 +33,11 @@ type ValidateX509CAFunc = func(x509CA, x509Roots []*x509.Certificate) error
 type UpstreamClientConfig struct {
 	UpstreamAuthority upstreamauthority.UpstreamAuthority
 	BundleUpdater     BundleUpdater
+
+	// WITKeyPublisher is the optional WITKeyPublisher service served by the
+	// UpstreamAuthority plugin. If nil, PublishWITKey fails with
+	// Unimplemented.
+	WITKeyPublisher upstreamauthority.WITKeyPublisher
 }
 
 // UpstreamClient is used to interact with and stream updates from the
@@ -43,6 +49,8 @@ type UpstreamClient struct {
 	mintX509CAStream                *streamState
 	publishJWTKeyMtx                sync.Mutex
 	publishJWTKeyStream             *streamState
+	publishWITKeyMtx                sync.Mutex
+	publishWITKeyStream             *streamState
 	subscribeToLocalBundleStreamMtx sync.Mutex
 	subscribeToLocalBundleStream    *streamState
 }
@@ -53,6 +61,7 @@ func NewUpstreamClient(config UpstreamClientConfig) *UpstreamClient {
 		c:                            config,
 		mintX509CAStream:             newStreamState(),
 		publishJWTKeyStream:          newStreamState(),
+		publishWITKeyStream:          newStreamState(),
 		subscribeToLocalBundleStream: newStreamState(),
 	}
 }
@@ -70,6 +79,11 @@ func (u *UpstreamClient) Close() error {
 		defer u.publishJWTKeyMtx.Unlock()
 		u.publishJWTKeyStream.Stop()
 	}()
+	func() {
+		u.publishWITKeyMtx.Lock()
+		defer u.publishWITKeyMtx.Unlock()
+		u.publishWITKeyStream.Stop()
+	}()
 	func() {
 		u.subscribeToLocalBundleStreamMtx.Lock()
 		defer u.subscribeToLocalBundleStreamMtx.Unlock()
@@ -130,6 +144,32 @@ func (u *UpstreamClient) PublishJWTKey(ctx context.Context, jwtKey *common.Publi
 	}
 }
 
+// PublishWITKey publishes the WIT key to the UpstreamAuthority. It maintains
+// an open stream to the UpstreamAuthority plugin to receive and sync WIT key
+// updates to the bundle. The stream remains open until another call to
+// PublishWITKey happens or the client is closed.
+func (u *UpstreamClient) PublishWITKey(ctx context.Context, witKey *common.PublicKey) (_ []*common.PublicKey, err error) {
+	u.publishWITKeyMtx.Lock()
+	defer u.publishWITKeyMtx.Unlock()
+
+	firstResultCh := make(chan publishWITKeyResult, 1)
+	u.publishWITKeyStream.Start(func(streamCtx context.Context) {
+		u.runPublishWITKeyStream(streamCtx, witKey, firstResultCh)
+	})
+	defer func() {
+		if err != nil {
+			u.publishWITKeyStream.Stop()
+		}
+	}()
+
+	select {
+	case result := <-firstResultCh:
+		return result.witKeys, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (u *UpstreamClient) SubscribeToLocalBundle(ctx context.Context) (err error) {
 	u.subscribeToLocalBundleStreamMtx.Lock()
 	defer u.subscribeToLocalBundleStreamMtx.Unlock()
@@ -243,6 +283,49 @@ func (u *UpstreamClient) runPublishJWTKeyStream(ctx context.Context, jwtKey *com
 	}
 }
 
+func (u *UpstreamClient) runPublishWITKeyStream(ctx context.Context, witKey *common.PublicKey, firstResultCh chan<- publishWITKeyResult) {
+	if u.c.WITKeyPublisher == nil {
+		firstResultCh <- publishWITKeyResult{err: status.Error(codes.Unimplemented, "upstream authority does not support publishing WIT keys")}
+		return
+	}
+
+	witKeys, witKeysStream, err := u.c.WITKeyPublisher.PublishWITKey(ctx, witKey)
+	if err != nil {
+		firstResultCh <- publishWITKeyResult{err: err}
+		return
+	}
+	defer witKeysStream.Close()
+
+	updatedKeys, err := u.c.BundleUpdater.SyncWITKeys(ctx, witKeys)
+	if err != nil {
+		firstResultCh <- publishWITKeyResult{err: err}
+		return
+	}
+	firstResultCh <- publishWITKeyResult{witKeys: updatedKeys}
+
+	for {
+		witKeys, err := witKeysStream.RecvUpstreamWITAuthorities()
+		if err != nil {
+			switch {
+			case errors.Is(err, io.EOF):
+				// This is normal if the plugin does not support streaming
+				// bundle updates.
+			case status.Code(err) == codes.Canceled:
+				// This is normal. This client cancels this stream when opening
+				// a new stream.
+			default:
+				u.c.BundleUpdater.LogError(err, "The upstream authority plugin stopped streaming WIT key updates prematurely. Please report this bug. Will retry later.")
+			}
+			return
+		}
+
+		if _, err := u.c.BundleUpdater.SyncWITKeys(ctx, witKeys); err != nil {
+			u.c.BundleUpdater.LogError(err, "Failed to store WIT keys received by the upstream authority plugin.")
+			continue
+		}
+	}
+}
+
 func (u *UpstreamClient) runSubscribeToLocalBundleStream(ctx context.Context, firstResultCh chan<- bundleUpdatesResult) {
 	x509CAs, jwtKeys, authorityStream, err := u.c.UpstreamAuthority.SubscribeToLocalBundle(ctx)
 	if err != nil {
@@ -310,6 +393,11 @@ type publishJWTKeyResult struct {
 	err     error
 }
 
+type publishWITKeyResult struct {
+	witKeys []*common.PublicKey
+	err     error
+}
+
 type bundleUpdatesResult struct {
 	x509CA  []*x509.Certificate
 	jwtKeys []*common.PublicKey
@@ -140,6 +140,38 @@ func TestUpstreamClientPublishJWTKey_HandlesBundleUpdates(t *testing.T) {
 	spiretest.RequireProtoListEqual(t, []*common.PublicKey{key1, key2}, updater.WaitForAppendedJWTKeys(t))
 }
 
+func TestUpstreamClientPublishWITKey_HandlesBundleUpdates(t *testing.T) {
+	client, updater, ua := setupUpstreamClientTest(t, fakeupstreamauthority.Config{
+		TrustDomain: trustDomain,
+	})
+
+	key1 := makePublicKey(t, "KEY1")
+	key2 := makePublicKey(t, "KEY2")
+
+	witKeys, err := client.PublishWITKey(context.Background(), key1)
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, witKeys, ua.WITKeys())
+
+	// Assert that the initial bundle update happened.
+	spiretest.RequireProtoListEqual(t, []*common.PublicKey{key1}, updater.WaitForSyncedWITKeys(t))
+
+	// Now trigger an update to the bundle by appending another key and wait
+	// for the bundle to receive the update.
+	ua.AppendWITKey(key2)
+	spiretest.RequireProtoListEqual(t, []*common.PublicKey{key1, key2}, updater.WaitForSyncedWITKeys(t))
+
+	// Taint the first key upstream and wait for the bundle to receive the
+	// update.
+	tainted := &common.PublicKey{
+		Kid:        key1.Kid,
+		PkixBytes:  key1.PkixBytes,
+		NotAfter:   key1.NotAfter,
+		TaintedKey: true,
+	}
+	ua.SetWITKeys([]*common.PublicKey{tainted, key2})
+	spiretest.RequireProtoListEqual(t, []*common.PublicKey{tainted, key2}, updater.WaitForSyncedWITKeys(t))
+}
+
 func TestUpstreamClientPublishJWTKey_NotImplemented(t *testing.T) {
 	client, _, _ := setupUpstreamClientTest(t, fakeupstreamauthority.Config{
 		TrustDomain:           trustDomain,
@@ -151,6 +183,34 @@ func TestUpstreamClientPublishJWTKey_NotImplemented(t *testing.T) {
 	require.Nil(t, jwtKeys)
 }
 
+func TestUpstreamClientPublishWITKey_NotImplemented(t *testing.T) {
+	client, _, _ := setupUpstreamClientTest(t, fakeupstreamauthority.Config{
+		TrustDomain:           trustDomain,
+		DisallowPublishWITKey: true,
+	})
+
+	witKeys, err := client.PublishWITKey(context.Background(), makePublicKey(t, "KEY"))
+	spiretest.RequireGRPCStatus(t, err, codes.Unimplemented, "upstreamauthority(fake): disallowed")
+	require.Nil(t, witKeys)
+}
+
+func TestUpstreamClientPublishWITKey_NoWITKeyPublisher(t *testing.T) {
+	plugin, _ := fakeupstreamauthority.Load(t, fakeupstreamauthority.Config{
+		TrustDomain: trustDomain,
+	})
+	client := ca.NewUpstreamClient(ca.UpstreamClientConfig{
+		UpstreamAuthority: plugin,
+		BundleUpdater:     newFakeBundleUpdater(),
+	})
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	witKeys, err := client.PublishWITKey(context.Background(), makePublicKey(t, "KEY"))
+	spiretest.RequireGRPCStatus(t, err, codes.Unimplemented, "upstream authority does not support publishing WIT keys")
+	require.Nil(t, witKeys)
+}
+
 func TestUpstreamClientSubscribeToLocalBundle(t *testing.T) {
 	client, updater, ua := setupUpstreamClientTest(t, fakeupstreamauthority.Config{
 		TrustDomain:               trustDomain,
@@ -189,12 +249,13 @@ func TestUpstreamClientSubscribeToLocalBundle(t *testing.T) {
 }
 
 func setupUpstreamClientTest(t *testing.T, config fakeupstreamauthority.Config) (*ca.UpstreamClient, *fakeBundleUpdater, *fakeupstreamauthority.UpstreamAuthority) {
-	plugin, upstreamAuthority := fakeupstreamauthority.Load(t, config)
+	plugin, witKeyPublisher, upstreamAuthority := fakeupstreamauthority.LoadWithWITKeyPublisher(t, config)
 	updater := newFakeBundleUpdater()
 
 	client := ca.NewUpstreamClient(ca.UpstreamClientConfig{
 		UpstreamAuthority: plugin,
 		BundleUpdater:     updater,
+		WITKeyPublisher:   witKeyPublisher,
 	})
 	t.Cleanup(func() {
 		assert.NoError(t, client.Close())
@@ -211,6 +272,7 @@ type bundleUpdateErr struct {
 type fakeBundleUpdater struct {
 	x509RootsCh chan []*x509certificate.X509Authority
 	jwtKeysCh   chan []*common.PublicKey
+	witKeysCh   chan []*common.PublicKey
 	errorCh     chan bundleUpdateErr
 }
 
@@ -218,6 +280,7 @@ func newFakeBundleUpdater() *fakeBundleUpdater {
 	return &fakeBundleUpdater{
 		x509RootsCh: make(chan []*x509certificate.X509Authority, 1),
 		jwtKeysCh:   make(chan []*common.PublicKey, 1),
+		witKeysCh:   make(chan []*common.PublicKey, 1),
 		errorCh:     make(chan bundleUpdateErr, 1),
 	}
 }
@@ -250,6 +313,15 @@ func (u *fakeBundleUpdater) AppendJWTKeys(ctx context.Context, jwtKeys []*common
 	}
 }
 
+func (u *fakeBundleUpdater) SyncWITKeys(ctx context.Context, witKeys []*common.PublicKey) ([]*common.PublicKey, error) {
+	select {
+	case u.witKeysCh <- witKeys:
+		return witKeys, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (u *fakeBundleUpdater) WaitForAppendedJWTKeys(t *testing.T) []*common.PublicKey {
 	select {
 	case <-time.After(time.Minute):
@@ -260,6 +332,16 @@ func (u *fakeBundleUpdater) WaitForAppendedJWTKeys(t *testing.T) []*common.Publi
 	}
 }
 
+func (u *fakeBundleUpdater) WaitForSyncedWITKeys(t *testing.T) []*common.PublicKey {
+	select {
+	case <-time.After(time.Minute):
+		require.FailNow(t, "timed out waiting for WIT keys to be synced")
+		return nil // unreachable
+	case witKeys := <-u.witKeysCh:
+		return witKeys
+	}
+}
+
 func (u *fakeBundleUpdater) LogError(err error, msg string) {
 	e := bundleUpdateErr{
 		err: err,
@@ -124,6 +124,13 @@ func (ds *DatastoreCache) TaintJWTKey(ctx context.Context, trustDomainID string,
 	return
 }
 
+func (ds *DatastoreCache) TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (taintedKey *common.PublicKey, err error) {
+	if taintedKey, err = ds.DataStore.TaintWITKey(ctx, trustDomainID, authorityID); err == nil {
+		ds.invalidateBundleEntry(trustDomainID)
+	}
+	return
+}
+
 func (ds *DatastoreCache) RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (revokedKey *common.PublicKey, err error) {
 	if revokedKey, err = ds.DataStore.RevokeJWTKey(ctx, trustDomainID, authorityID); err == nil {
 		ds.invalidateBundleEntry(trustDomainID)
@@ -131,6 +138,13 @@ func (ds *DatastoreCache) RevokeJWTKey(ctx context.Context, trustDomainID string
 	return
 }
 
+func (ds *DatastoreCache) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (revokedKey *common.PublicKey, err error) {
+	if revokedKey, err = ds.DataStore.RevokeWITKey(ctx, trustDomainID, authorityID); err == nil {
+		ds.invalidateBundleEntry(trustDomainID)
+	}
+	return
+}
+
 func (ds *DatastoreCache) invalidateBundleEntry(trustDomainID string) {
 	ds.bundlesMu.Lock()
 	delete(ds.bundles, trustDomainID)
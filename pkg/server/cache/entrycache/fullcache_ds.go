@@ -33,6 +33,7 @@ type entryIteratorDS struct {
 	next            int
 	err             error
 	paginationToken string
+	templatesListed bool
 }
 
 func makeEntryIteratorDS(ds datastore.DataStore) EntryIterator {
@@ -70,6 +71,18 @@ func (it *entryIteratorDS) Next(ctx context.Context) bool {
 			return false
 		}
 	}
+	if it.next >= len(it.entries) && it.paginationToken == "" && !it.templatesListed {
+		// Entry templates are sent to the agents as entries, after the
+		// registration entries
+		it.templatesListed = true
+		entries, err := it.listEntryTemplates(ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.next = 0
+		it.entries = entries
+	}
 	if it.next >= len(it.entries) {
 		return false
 	}
@@ -77,6 +90,24 @@ func (it *entryIteratorDS) Next(ctx context.Context) bool {
 	return true
 }
 
+func (it *entryIteratorDS) listEntryTemplates(ctx context.Context) ([]*types.Entry, error) {
+	templates, err := it.ds.ListEntryTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*types.Entry, 0, len(templates))
+	for _, template := range templates {
+		// Filter out malformed entry templates
+		entry, err := api.EntryTemplateToProto(template)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 func (it *entryIteratorDS) filterEntries(in []*common.RegistrationEntry) []*common.RegistrationEntry {
 	out := make([]*common.RegistrationEntry, 0, len(in))
 	for _, entry := range in {
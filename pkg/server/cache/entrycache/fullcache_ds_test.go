@@ -71,6 +71,34 @@ func TestEntryIteratorDS(t *testing.T) {
 		assert.ElementsMatch(t, expectedEntries, entries)
 	})
 
+	template, err := ds.CreateEntryTemplate(ctx, &common.EntryTemplate{
+		ParentId:         parentID,
+		SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+		Selectors:        selectors,
+	})
+	require.NoError(t, err)
+	templateEntry, err := api.EntryTemplateToProto(template)
+	require.NoError(t, err)
+	expectedEntries = append(expectedEntries, templateEntry)
+
+	t.Run("existing entries and entry templates", func(t *testing.T) {
+		it := makeEntryIteratorDS(ds)
+		var entries []*types.Entry
+
+		for range numEntries + 1 {
+			assert.True(t, it.Next(ctx))
+			require.NoError(t, it.Err())
+
+			entry := it.Entry()
+			require.NotNil(t, entry)
+			entries = append(entries, entry)
+		}
+
+		assert.False(t, it.Next(ctx))
+		assert.NoError(t, it.Err())
+		assert.ElementsMatch(t, expectedEntries, entries)
+	})
+
 	t.Run("datastore error", func(t *testing.T) {
 		it := makeEntryIteratorDS(ds)
 		for range listEntriesRequestPageSize {
@@ -23,6 +23,7 @@ import (
 	km_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server/keymanager"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	ds_kv "github.com/spiffe/spire/pkg/server/datastore/kvstore"
 	ds_sql "github.com/spiffe/spire/pkg/server/datastore/sqlstore"
 	"github.com/spiffe/spire/pkg/server/hostservice/agentstore"
 	"github.com/spiffe/spire/pkg/server/hostservice/identityprovider"
@@ -50,11 +51,13 @@ var ReconfigureTask = catalog.ReconfigureTask
 type Catalog interface {
 	GetBundlePublishers() []bundlepublisher.BundlePublisher
 	GetCredentialComposers() []credentialcomposer.CredentialComposer
+	GetWITSVIDComposers() []credentialcomposer.WITSVIDComposer
 	GetDataStore() datastore.DataStore
 	GetNodeAttestorNamed(name string) (nodeattestor.NodeAttestor, bool)
 	GetKeyManager() keymanager.KeyManager
 	GetNotifiers() []notifier.Notifier
 	GetUpstreamAuthority() (upstreamauthority.UpstreamAuthority, bool)
+	GetWITKeyPublisher() (upstreamauthority.WITKeyPublisher, bool)
 }
 
 type PluginConfigs = catalog.PluginConfigs
@@ -82,12 +85,23 @@ type Repository struct {
 	upstreamAuthorityRepository
 
 	log      logrus.FieldLogger
+	dsConfig catalog.PluginConfig
 	dsCloser io.Closer
 	catalog  *catalog.Catalog
 }
 
+// builtinDataStore is implemented by the built-in DataStore plugins, which
+// are loaded directly instead of through the plugin catalog.
+type builtinDataStore interface {
+	datastore.DataStore
+	io.Closer
+
+	Configure(ctx context.Context, configuration string) error
+	Validate(ctx context.Context, coreConfig catalog.CoreConfig, configuration string) (*configv1.ValidateResponse, error)
+}
+
 type dsConfigurer struct {
-	ds *ds_sql.Plugin
+	ds builtinDataStore
 }
 
 func (c *dsConfigurer) Configure(ctx context.Context, _ catalog.CoreConfig, configuration string) error {
@@ -110,13 +124,48 @@ func (repo *Repository) Plugins() map[string]catalog.PluginRepo {
 }
 
 func (repo *Repository) Services() []catalog.ServiceRepo {
-	return nil
+	return []catalog.ServiceRepo{
+		witKeyPublisherRepository{Repository: &repo.upstreamAuthorityRepository.Repository},
+		witSVIDComposerRepository{Repository: &repo.credentialComposerRepository.Repository},
+	}
 }
 
 func (repo *Repository) Reconfigure(ctx context.Context) {
 	repo.catalog.Reconfigure(ctx)
 }
 
+// ReconfigureWith reconfigures the plugins with the data from the reloaded
+// plugin configurations. The DataStore is not reconfigurable, so any change
+// to its configuration requires a restart.
+func (repo *Repository) ReconfigureWith(ctx context.Context, pluginConfigs PluginConfigs) catalog.PluginChanges {
+	dataStoreConfigs, pluginConfigs := pluginConfigs.FilterByType(dataStoreType)
+	changes := repo.catalog.ReconfigureWith(ctx, pluginConfigs)
+	if !sameDataStoreConfig(repo.dsConfig, dataStoreConfigs) {
+		changes.RequiresRestart = append(changes.RequiresRestart, fmt.Sprintf("%s %q", dataStoreType, repo.dsConfig.Name))
+	}
+	return changes
+}
+
+func sameDataStoreConfig(loaded catalog.PluginConfig, dataStoreConfigs PluginConfigs) bool {
+	if len(dataStoreConfigs) != 1 {
+		return false
+	}
+	c := dataStoreConfigs[0]
+	if c.Name != loaded.Name || c.Path != loaded.Path || c.Disabled != loaded.Disabled {
+		return false
+	}
+
+	loadedData, err := catalog.GetPluginConfigString(loaded)
+	if err != nil {
+		return false
+	}
+	data, err := catalog.GetPluginConfigString(c)
+	if err != nil {
+		return false
+	}
+	return loadedData == data
+}
+
 func (repo *Repository) Close() {
 	// Must close in reverse initialization order!
 
@@ -157,14 +206,23 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 		TrustDomain: config.TrustDomain,
 	}
 
-	// Strip out the Datastore plugin configuration and load the SQL plugin
-	// directly. This allows us to bypass gRPC and get rid of response limits.
+	// Strip out the Datastore plugin configuration and load the built-in
+	// plugin directly. This allows us to bypass gRPC and get rid of response
+	// limits.
 	dataStoreConfigs, pluginConfigs := config.PluginConfigs.FilterByType(dataStoreType)
-	sqlDataStore, err := loadSQLDataStore(ctx, config, coreConfig, dataStoreConfigs)
+	builtinDS, err := loadDataStore(ctx, config, coreConfig, dataStoreConfigs)
 	if err != nil {
 		return nil, err
 	}
-	repo.dsCloser = sqlDataStore
+	repo.dsCloser = builtinDS
+
+	// Keep the configuration the DataStore was loaded with, so a reloaded
+	// configuration can be compared against it even if the data source is
+	// a file that changes afterwards.
+	repo.dsConfig = dataStoreConfigs[0]
+	if data, err := catalog.GetPluginConfigString(repo.dsConfig); err == nil {
+		repo.dsConfig.DataSource = catalog.FixedData(data)
+	}
 
 	repo.catalog, err = catalog.Load(ctx, catalog.Config{
 		Log:           config.Log,
@@ -180,11 +238,12 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 		return nil, err
 	}
 
-	var dataStore datastore.DataStore = sqlDataStore
+	var dataStore datastore.DataStore = builtinDS
 	_ = config.HealthChecker.AddCheck("catalog.datastore", &datastore.Health{
 		DataStore: dataStore,
 	})
 
+	dataStore = ds_telemetry.WithTracing(dataStore)
 	dataStore = ds_telemetry.WithMetrics(dataStore, config.Metrics)
 	dataStore = dscache.New(dataStore, clock.New())
 
@@ -212,25 +271,30 @@ func ValidateConfig(ctx context.Context, config Config) (pluginNotes map[string]
 
 	pluginNotes = make(map[string][]string)
 	dataStoreConfigs, pluginConfigs := config.PluginConfigs.FilterByType(dataStoreType)
-	datastorePluginId := fmt.Sprintf("%s \"%s\"", dataStoreType, "sql")
 	if len(dataStoreConfigs) == 0 {
+		datastorePluginId := fmt.Sprintf("%s \"%s\"", dataStoreType, ds_sql.PluginName)
 		pluginNotes[datastorePluginId] = append(pluginNotes[datastorePluginId], "'datastore' must be configured")
 	} else {
+		datastorePluginId := fmt.Sprintf("%s \"%s\"", dataStoreType, dataStoreConfigs[0].Name)
 		dsConfigString, err := catalog.GetPluginConfigString(dataStoreConfigs[0])
 		if err != nil {
 			return nil, fmt.Errorf("failed to get DataStore configuration: %w", err)
 		}
 
-		ds := ds_sql.New(config.Log)
-		resp, err := ds.Validate(ctx, coreConfig, dsConfigString)
+		ds, err := newDataStore(dataStoreConfigs[0].Name, config.Log)
 		if err != nil {
 			pluginNotes[datastorePluginId] = append(pluginNotes[datastorePluginId], err.Error())
+		} else {
+			resp, err := ds.Validate(ctx, coreConfig, dsConfigString)
+			if err != nil {
+				pluginNotes[datastorePluginId] = append(pluginNotes[datastorePluginId], err.Error())
+			}
+			if resp != nil && len(resp.Notes) != 0 {
+				pluginNotes[datastorePluginId] = append(pluginNotes[datastorePluginId], resp.Notes...)
+			}
+
+			repo.dsCloser = ds
 		}
-		if resp != nil && len(resp.Notes) != 0 {
-			pluginNotes[datastorePluginId] = append(pluginNotes[datastorePluginId], resp.Notes...)
-		}
-
-		repo.dsCloser = ds
 	}
 
 	validateResp, err := catalog.ValidatePluginConfigs(ctx, catalog.Config{
@@ -251,7 +315,26 @@ func ValidateConfig(ctx context.Context, config Config) (pluginNotes map[string]
 	return pluginNotes, err
 }
 
-func loadSQLDataStore(ctx context.Context, config Config, coreConfig catalog.CoreConfig, datastoreConfigs catalog.PluginConfigs) (*ds_sql.Plugin, error) {
+// DataStoreCloser is a DataStore that must be closed once no longer used.
+type DataStoreCloser interface {
+	datastore.DataStore
+	io.Closer
+}
+
+// LoadDataStore loads only the built-in DataStore plugin from the given
+// plugin configurations. It is used by tooling that operates on the datastore
+// without running the server.
+func LoadDataStore(ctx context.Context, log logrus.FieldLogger, trustDomain spiffeid.TrustDomain, pluginConfigs PluginConfigs) (DataStoreCloser, error) {
+	dataStoreConfigs, _ := pluginConfigs.FilterByType(dataStoreType)
+	return loadDataStore(ctx, Config{
+		Log:         log,
+		TrustDomain: trustDomain,
+	}, catalog.CoreConfig{
+		TrustDomain: trustDomain,
+	}, dataStoreConfigs)
+}
+
+func loadDataStore(ctx context.Context, config Config, coreConfig catalog.CoreConfig, datastoreConfigs catalog.PluginConfigs) (builtinDataStore, error) {
 	switch {
 	case len(datastoreConfigs) == 0:
 		return nil, errors.New("expecting a DataStore plugin")
@@ -259,29 +342,42 @@ func loadSQLDataStore(ctx context.Context, config Config, coreConfig catalog.Cor
 		return nil, errors.New("only one DataStore plugin is allowed")
 	}
 
-	sqlConfig := datastoreConfigs[0]
+	dsConfig := datastoreConfigs[0]
 
-	if sqlConfig.Name != ds_sql.PluginName {
-		return nil, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
+	if dsConfig.IsExternal() {
+		return nil, errDataStoreNotBuiltin
 	}
-	if sqlConfig.IsExternal() {
-		return nil, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
-	}
-	if sqlConfig.DataSource == nil {
-		sqlConfig.DataSource = catalog.FixedData("")
+	if dsConfig.DataSource == nil {
+		dsConfig.DataSource = catalog.FixedData("")
 	}
 
-	dsLog := config.Log.WithField(telemetry.SubsystemName, sqlConfig.Name)
-	ds := ds_sql.New(dsLog)
+	dsLog := config.Log.WithField(telemetry.SubsystemName, dsConfig.Name)
+	ds, err := newDataStore(dsConfig.Name, dsLog)
+	if err != nil {
+		return nil, err
+	}
 	dsConf := &dsConfigurer{ds: ds}
-	if _, err := catalog.ConfigurePlugin(ctx, coreConfig, dsConf, sqlConfig.DataSource, ""); err != nil {
+	if _, err := catalog.ConfigurePlugin(ctx, coreConfig, dsConf, dsConfig.DataSource, ""); err != nil {
 		return nil, err
 	}
 
-	if sqlConfig.DataSource.IsDynamic() {
+	if dsConfig.DataSource.IsDynamic() {
 		config.Log.Warn("DataStore is not reconfigurable even with a dynamic data source")
 	}
 
 	config.Log.WithField(telemetry.Reconfigurable, false).Info("Configured DataStore")
 	return ds, nil
 }
+
+var errDataStoreNotBuiltin = fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q and %q plugins are supported", ds_sql.PluginName, ds_kv.PluginName)
+
+func newDataStore(name string, log logrus.FieldLogger) (builtinDataStore, error) {
+	switch name {
+	case ds_sql.PluginName:
+		return ds_sql.New(log), nil
+	case ds_kv.PluginName:
+		return ds_kv.New(log), nil
+	default:
+		return nil, errDataStoreNotBuiltin
+	}
+}
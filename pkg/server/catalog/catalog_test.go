@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	commoncatalog "github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/server/catalog"
@@ -41,7 +42,32 @@ func Test(t *testing.T) {
 					}
 				}
 			},
-			expectErr: `pluggability for the DataStore is deprecated; only the built-in "sql" plugin is supported`,
+			expectErr: `pluggability for the DataStore is deprecated; only the built-in "sql" and "kv" plugins are supported`,
+		},
+		{
+			desc: "datastore must be a built-in plugin",
+			prepareConfig: func(dir string, config *catalog.Config) {
+				for i, pluginConfig := range config.PluginConfigs {
+					if pluginConfig.Type == "DataStore" {
+						config.PluginConfigs[i].Name = "unknown"
+					}
+				}
+			},
+			expectErr: `pluggability for the DataStore is deprecated; only the built-in "sql" and "kv" plugins are supported`,
+		},
+		{
+			desc: "kv datastore",
+			prepareConfig: func(dir string, config *catalog.Config) {
+				config.TrustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+				for i, pluginConfig := range config.PluginConfigs {
+					if pluginConfig.Type == "DataStore" {
+						config.PluginConfigs[i].Name = "kv"
+						config.PluginConfigs[i].DataSource = commoncatalog.FixedData(fmt.Sprintf(`
+						database_path = %q
+					`, filepath.Join(dir, "datastore.db")))
+					}
+				}
+			},
 		},
 	} {
 		t.Run(tt.desc, func(t *testing.T) {
@@ -87,6 +113,44 @@ func Test(t *testing.T) {
 	}
 }
 
+func TestReconfigureWith(t *testing.T) {
+	dir := t.TempDir()
+	log, _ := test.NewNullLogger()
+
+	dataStoreConfig := func(path string) catalog.PluginConfigs {
+		return catalog.PluginConfigs{
+			{
+				Type: "DataStore",
+				Name: "sql",
+				DataSource: commoncatalog.FixedData(fmt.Sprintf(`
+					database_type = "sqlite3"
+					connection_string = %q
+				`, path)),
+			},
+			{
+				Type: "KeyManager",
+				Name: "memory",
+			},
+		}
+	}
+
+	repo, err := catalog.Load(context.Background(), catalog.Config{
+		Log:           log,
+		HealthChecker: fakeHealthChecker{},
+		PluginConfigs: dataStoreConfig(filepath.Join(dir, "test.sql")),
+	})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	changes := repo.ReconfigureWith(context.Background(), dataStoreConfig(filepath.Join(dir, "test.sql")))
+	require.Equal(t, commoncatalog.PluginChanges{}, changes)
+
+	changes = repo.ReconfigureWith(context.Background(), dataStoreConfig(filepath.Join(dir, "other.sql")))
+	require.Equal(t, commoncatalog.PluginChanges{
+		RequiresRestart: []string{`DataStore "sql"`},
+	}, changes)
+}
+
 type fakeHealthChecker struct{}
 
 func (fakeHealthChecker) AddCheck(string, health.Checkable) error { return nil }
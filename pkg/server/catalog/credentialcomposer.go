@@ -3,6 +3,7 @@ package catalog
 import (
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	credentialcomposertemplate "github.com/spiffe/spire/pkg/server/plugin/credentialcomposer/template"
 	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer/uniqueid"
 )
 
@@ -23,10 +24,38 @@ func (repo *credentialComposerRepository) Versions() []catalog.Version {
 }
 
 func (repo *credentialComposerRepository) BuiltIns() []catalog.BuiltIn {
-	return []catalog.BuiltIn{uniqueid.BuiltIn()}
+	return []catalog.BuiltIn{
+		credentialcomposertemplate.BuiltIn(),
+		uniqueid.BuiltIn(),
+	}
 }
 
 type credentialComposerV1 struct{}
 
 func (credentialComposerV1) New() catalog.Facade { return new(credentialcomposer.V1) }
 func (credentialComposerV1) Deprecated() bool    { return false }
+
+// witSVIDComposerRepository binds the optional WITSVIDComposer service served
+// by CredentialComposer plugins.
+type witSVIDComposerRepository struct {
+	*credentialcomposer.Repository
+}
+
+func (repo witSVIDComposerRepository) Binder() any {
+	return repo.AddWITSVIDComposer
+}
+
+func (repo witSVIDComposerRepository) Versions() []catalog.Version {
+	return []catalog.Version{
+		witSVIDComposerV1{},
+	}
+}
+
+func (repo witSVIDComposerRepository) Clear() {
+	repo.ClearWITSVIDComposers()
+}
+
+type witSVIDComposerV1 struct{}
+
+func (witSVIDComposerV1) New() catalog.Facade { return new(credentialcomposer.WITSVIDComposerV1) }
+func (witSVIDComposerV1) Deprecated() bool    { return false }
@@ -10,6 +10,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager/disk"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager/gcpkms"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager/memory"
+	"github.com/spiffe/spire/pkg/server/plugin/keymanager/pkcs11"
 )
 
 type keyManagerRepository struct {
@@ -36,6 +37,7 @@ func (repo *keyManagerRepository) BuiltIns() []catalog.BuiltIn {
 		azurekeyvault.BuiltIn(),
 		hashicorpvault.BuiltIn(),
 		memory.BuiltIn(),
+		pkcs11.BuiltIn(),
 	}
 }
 
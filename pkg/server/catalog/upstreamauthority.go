@@ -48,3 +48,28 @@ type upstreamAuthorityV1 struct{}
 
 func (upstreamAuthorityV1) New() catalog.Facade { return new(upstreamauthority.V1) }
 func (upstreamAuthorityV1) Deprecated() bool    { return false }
+
+// witKeyPublisherRepository binds the optional WITKeyPublisher service served
+// by the UpstreamAuthority plugin.
+type witKeyPublisherRepository struct {
+	*upstreamauthority.Repository
+}
+
+func (repo witKeyPublisherRepository) Binder() any {
+	return repo.SetWITKeyPublisher
+}
+
+func (repo witKeyPublisherRepository) Versions() []catalog.Version {
+	return []catalog.Version{
+		witKeyPublisherV1{},
+	}
+}
+
+func (repo witKeyPublisherRepository) Clear() {
+	repo.ClearWITKeyPublisher()
+}
+
+type witKeyPublisherV1 struct{}
+
+func (witKeyPublisherV1) New() catalog.Facade { return new(upstreamauthority.WITKeyPublisherV1) }
+func (witKeyPublisherV1) Deprecated() bool    { return false }
@@ -8,9 +8,11 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	common "github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	loggerv1 "github.com/spiffe/spire/pkg/server/api/logger/v1"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
@@ -31,6 +33,10 @@ type Config struct {
 	// If true enables audit logs
 	AuditLogEnabled bool
 
+	// AuditLogSink, if set, configures a dedicated output the audit logs are
+	// also written to, as a hash chain
+	AuditLogSink *audit.SinkConfig
+
 	// ProxyProtocolTrustedCIDRs is a list of trusted CIDRs for PROXY protocol.
 	// When non-empty, the server enables PROXY protocol on the TCP listener and
 	// restricts PROXY header acceptance to connections originating from these
@@ -153,6 +159,15 @@ type Config struct {
 
 	// DisableWITSVIDs, if true, WIT-SVID profile is disabled
 	DisableWITSVIDs bool
+
+	// FeatureFlagsLoader, if set, loads the feature flags from the
+	// configuration so they can be reloaded while the server is running
+	FeatureFlagsLoader fflag.Loader
+
+	// ConfigLoader, if set, loads the configuration again when the server is
+	// signaled to reload it, so the settings that support it can be changed
+	// while the server is running
+	ConfigLoader ConfigLoader
 }
 
 type ExperimentalConfig struct {
@@ -6,10 +6,13 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 	"net/url"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -129,11 +132,13 @@ type Config struct {
 	WITIssuer           string
 	AgentSVIDTTL        time.Duration
 	CredentialComposers []credentialcomposer.CredentialComposer
+	WITSVIDComposers    []credentialcomposer.WITSVIDComposer
 	NewSerialNumber     func() (*big.Int, error)
 	TLSPolicy           tlspolicy.Policy
 }
 
 type Builder struct {
+	mtx    sync.RWMutex
 	config Config
 
 	x509CAID spiffeid.ID
@@ -188,9 +193,32 @@ func NewBuilder(config Config) (*Builder, error) {
 }
 
 func (b *Builder) Config() Config {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
 	return b.config
 }
 
+// SetSVIDTTLs updates the default TTLs of X509-SVIDs, JWT-SVIDs and agent
+// SVIDs, e.g. when the configuration is reloaded. Zero values are replaced
+// by the defaults in the same way as NewBuilder does.
+func (b *Builder) SetSVIDTTLs(x509SVIDTTL, jwtSVIDTTL, agentSVIDTTL time.Duration) {
+	if x509SVIDTTL == 0 {
+		x509SVIDTTL = DefaultX509SVIDTTL
+	}
+	if jwtSVIDTTL == 0 {
+		jwtSVIDTTL = DefaultJWTSVIDTTL
+	}
+	if agentSVIDTTL == 0 {
+		agentSVIDTTL = x509SVIDTTL
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.config.X509SVIDTTL = x509SVIDTTL
+	b.config.JWTSVIDTTL = jwtSVIDTTL
+	b.config.AgentSVIDTTL = agentSVIDTTL
+}
+
 func (b *Builder) BuildSelfSignedX509CATemplate(ctx context.Context, params SelfSignedX509CAParams) (*x509.Certificate, error) {
 	tmpl, err := b.buildX509CATemplate(params.PublicKey, nil, 0)
 	if err != nil {
@@ -276,7 +304,7 @@ func (b *Builder) BuildServerX509SVIDTemplate(ctx context.Context, params Server
 }
 
 func (b *Builder) BuildAgentX509SVIDTemplate(ctx context.Context, params AgentX509SVIDParams) (*x509.Certificate, error) {
-	tmpl, err := b.buildX509SVIDTemplate(params.SPIFFEID, params.PublicKey, params.ParentChain, pkix.Name{}, b.config.AgentSVIDTTL)
+	tmpl, err := b.buildX509SVIDTemplate(params.SPIFFEID, params.PublicKey, params.ParentChain, pkix.Name{}, b.Config().AgentSVIDTTL)
 	if err != nil {
 		return nil, err
 	}
@@ -340,7 +368,7 @@ func (b *Builder) BuildWorkloadJWTSVIDClaims(ctx context.Context, params Workloa
 
 	ttl := params.TTL
 	if ttl <= 0 {
-		ttl = b.config.JWTSVIDTTL
+		ttl = b.Config().JWTSVIDTTL
 	}
 	_, expiresAt := computeCappedLifetime(b.config.Clock, ttl, params.ExpirationCap)
 
@@ -403,19 +431,76 @@ func (b *Builder) BuildWorkloadWITSVIDClaims(ctx context.Context, params Workloa
 	now := b.config.Clock.Now()
 	_, expiresAt := computeCappedLifetime(b.config.Clock, ttl, params.ExpirationCap)
 
-	claims := map[string]any{
-		"sub": params.SPIFFEID.String(),
-		"exp": jwt.NewNumericDate(expiresAt),
-		"iat": jwt.NewNumericDate(now),
-		"cnf": map[string]any{
-			"jwk": params.PublicKey,
+	sub := params.SPIFFEID.String()
+	cnf := map[string]any{
+		"jwk": params.PublicKey,
+	}
+	attributes := credentialcomposer.WITSVIDAttributes{
+		Claims: map[string]any{
+			"sub": sub,
+			"exp": jwt.NewNumericDate(expiresAt),
+			"iat": jwt.NewNumericDate(now),
+			"cnf": cnf,
 		},
 	}
 	if b.config.WITIssuer != "" {
-		claims["iss"] = b.config.WITIssuer
+		attributes.Claims["iss"] = b.config.WITIssuer
+	}
+
+	if len(b.config.WITSVIDComposers) == 0 {
+		return attributes.Claims, nil
+	}
+
+	for _, cc := range b.config.WITSVIDComposers {
+		var err error
+		attributes, err = cc.ComposeWorkloadWITSVID(ctx, params.SPIFFEID, attributes)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return claims, nil
+	// The subject and the confirmation key bind the WIT-SVID to the workload
+	// and cannot be changed by credential composers.
+	for claim, value := range map[string]any{"sub": sub, "cnf": cnf} {
+		same, err := sameJSON(attributes.Claims[claim], value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q claim returned by credential composer: %w", claim, err)
+		}
+		if !same {
+			return nil, fmt.Errorf("credential composer cannot change the %q claim of WIT-SVIDs", claim)
+		}
+		attributes.Claims[claim] = value
+	}
+
+	// Protobuf serializes large integers as float since Claims are represented as google.protobuf.Struct.
+	if iat, ok := attributes.Claims["iat"].(float64); ok {
+		attributes.Claims["iat"] = int64(iat)
+	}
+	if exp, ok := attributes.Claims["exp"].(float64); ok {
+		attributes.Claims["exp"] = int64(exp)
+	}
+
+	return attributes.Claims, nil
+}
+
+// sameJSON returns true if both values have the same JSON representation.
+func sameJSON(a, b any) (bool, error) {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	var aValue, bValue any
+	if err := json.Unmarshal(aJSON, &aValue); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(bJSON, &bValue); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(aValue, bValue), nil
 }
 
 func (b *Builder) buildX509CATemplate(publicKey crypto.PublicKey, parentChain []*x509.Certificate, ttl time.Duration) (*x509.Certificate, error) {
@@ -505,7 +590,7 @@ func (b *Builder) computeX509CALifetime(parentChain []*x509.Certificate, ttl tim
 
 func (b *Builder) computeX509SVIDLifetime(parentChain []*x509.Certificate, ttl time.Duration) (notBefore, notAfter time.Time) {
 	if ttl <= 0 {
-		ttl = b.config.X509SVIDTTL
+		ttl = b.Config().X509SVIDTTL
 	}
 	return computeCappedLifetime(b.config.Clock, ttl, parentChainExpiration(parentChain))
 }
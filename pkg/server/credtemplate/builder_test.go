@@ -25,6 +25,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/proto/private/server/witsvidcomposer"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/testkey"
@@ -122,6 +123,28 @@ func TestNewBuilderAllowsConfigOverrides(t *testing.T) {
 	assert.Equal(t, configIn, configOut)
 }
 
+func TestSetSVIDTTLs(t *testing.T) {
+	builder, err := credtemplate.NewBuilder(credtemplate.Config{
+		TrustDomain: td,
+		X509CATTL:   time.Hour,
+	})
+	require.NoError(t, err)
+
+	builder.SetSVIDTTLs(2*time.Minute, 3*time.Minute, 4*time.Minute)
+	config := builder.Config()
+	assert.Equal(t, 2*time.Minute, config.X509SVIDTTL)
+	assert.Equal(t, 3*time.Minute, config.JWTSVIDTTL)
+	assert.Equal(t, 4*time.Minute, config.AgentSVIDTTL)
+	assert.Equal(t, time.Hour, config.X509CATTL)
+
+	// Unset TTLs fall back to the defaults
+	builder.SetSVIDTTLs(2*time.Minute, 0, 0)
+	config = builder.Config()
+	assert.Equal(t, 2*time.Minute, config.X509SVIDTTL)
+	assert.Equal(t, credtemplate.DefaultJWTSVIDTTL, config.JWTSVIDTTL)
+	assert.Equal(t, 2*time.Minute, config.AgentSVIDTTL)
+}
+
 func TestBuildSelfSignedX509CATemplate(t *testing.T) {
 	oneTwoThreeFourOID, err := x509.ParseOID("1.2.3.4")
 	require.NoError(t, err)
@@ -1283,6 +1306,62 @@ func TestBuildWorkloadWITSVIDClaims(t *testing.T) {
 				expected["iss"] = "ISSUER"
 			},
 		},
+		{
+			desc: "single composer",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.WITSVIDComposers = []credentialcomposer.WITSVIDComposer{fakeCC{id: []byte{1, 2, 3, 4}}}
+			},
+			overrideExpected: func(expected map[string]any) {
+				expected["foo"] = "VALUE-[1 2 3 4]"
+				expected["bar"] = "VALUE-[1 2 3 4]"
+			},
+		},
+		{
+			desc: "two composers",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.WITSVIDComposers = []credentialcomposer.WITSVIDComposer{fakeCC{id: []byte{1, 2, 3, 4}}, fakeCC{id: []byte{2, 3, 4, 5}, onlyFoo: true}}
+			},
+			overrideExpected: func(expected map[string]any) {
+				expected["foo"] = "VALUE-[2 3 4 5]"
+				expected["bar"] = "VALUE-[1 2 3 4]"
+			},
+		},
+		{
+			desc: "credential composers without WIT-SVID support",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.CredentialComposers = []credentialcomposer.CredentialComposer{fakeCC{id: []byte{1, 2, 3, 4}}}
+			},
+		},
+		{
+			desc: "composer fails",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.WITSVIDComposers = []credentialcomposer.WITSVIDComposer{badCC{}}
+			},
+			expectErr: "oh no",
+		},
+		{
+			desc: "composer changes subject",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.WITSVIDComposers = []credentialcomposer.WITSVIDComposer{fakeCC{id: []byte{1, 2, 3, 4}, overrideSub: true}}
+			},
+			expectErr: `credential composer cannot change the "sub" claim of WIT-SVIDs`,
+		},
+		{
+			desc: "real no-op composer",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.WITSVIDComposers = []credentialcomposer.WITSVIDComposer{loadNoopWITSVIDComposer(t)}
+			},
+		},
+		{
+			desc: "real grpc composer",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.WITSVIDComposers = []credentialcomposer.WITSVIDComposer{loadGrpcWITSVIDComposer(t)}
+			},
+			overrideExpected: func(expected map[string]any) {
+				expected["iat"] = now.Unix()
+				expected["exp"] = witSVIDNotAfter.Unix()
+			},
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			testBuilder(t, tc.overrideConfig, func(t *testing.T, credBuilder *credtemplate.Builder) {
@@ -1292,7 +1371,7 @@ func TestBuildWorkloadWITSVIDClaims(t *testing.T) {
 				params := credtemplate.WorkloadWITSVIDParams{
 					SPIFFEID: workloadID,
 					PublicKey: jose.JSONWebKey{
-						Key: signer.PublicKey,
+						Key: &signer.PublicKey,
 					},
 				}
 				if tc.overrideParams != nil {
@@ -1311,7 +1390,7 @@ func TestBuildWorkloadWITSVIDClaims(t *testing.T) {
 					"sub": workloadID.String(),
 					"cnf": map[string]any{
 						"jwk": jose.JSONWebKey{
-							Key: signer.PublicKey,
+							Key: &signer.PublicKey,
 						},
 					},
 				}
@@ -1391,6 +1470,10 @@ func (badCC) ComposeWorkloadJWTSVID(context.Context, spiffeid.ID, credentialcomp
 	return credentialcomposer.JWTSVIDAttributes{}, errors.New("oh no")
 }
 
+func (badCC) ComposeWorkloadWITSVID(context.Context, spiffeid.ID, credentialcomposer.WITSVIDAttributes) (credentialcomposer.WITSVIDAttributes, error) {
+	return credentialcomposer.WITSVIDAttributes{}, errors.New("oh no")
+}
+
 type fakeCC struct {
 	catalog.PluginInfo
 
@@ -1398,6 +1481,7 @@ type fakeCC struct {
 	onlyCommonName bool
 	onlyFoo        bool
 	addInt64       bool
+	overrideSub    bool
 }
 
 func (cc fakeCC) ComposeServerX509CA(_ context.Context, attributes credentialcomposer.X509CAAttributes) (credentialcomposer.X509CAAttributes, error) {
@@ -1442,6 +1526,17 @@ func (cc fakeCC) ComposeWorkloadJWTSVID(_ context.Context, _ spiffeid.ID, attrib
 	return attributes, nil
 }
 
+func (cc fakeCC) ComposeWorkloadWITSVID(_ context.Context, _ spiffeid.ID, attributes credentialcomposer.WITSVIDAttributes) (credentialcomposer.WITSVIDAttributes, error) {
+	attributes.Claims["foo"] = cc.applySuffix("VALUE")
+	if !cc.onlyFoo {
+		attributes.Claims["bar"] = cc.applySuffix("VALUE")
+	}
+	if cc.overrideSub {
+		attributes.Claims["sub"] = "spiffe://domain.test/someone-else"
+	}
+	return attributes, nil
+}
+
 func (cc fakeCC) overrideX509SVIDAttributes(attributes credentialcomposer.X509SVIDAttributes) credentialcomposer.X509SVIDAttributes {
 	attributes.Subject.CommonName = cc.applySuffix("OVERRIDE")
 	if !cc.onlyCommonName {
@@ -1476,6 +1571,7 @@ func loadNoopV1Plugin(t *testing.T) credentialcomposer.CredentialComposer {
 
 type grpcPlugin struct {
 	credentialcomposerv1.UnimplementedCredentialComposerServer
+	witsvidcomposer.UnimplementedWITSVIDComposerServer
 }
 
 func (p grpcPlugin) ComposeWorkloadJWTSVID(_ context.Context, a *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest) (*credentialcomposerv1.ComposeWorkloadJWTSVIDResponse, error) {
@@ -1484,9 +1580,33 @@ func (p grpcPlugin) ComposeWorkloadJWTSVID(_ context.Context, a *credentialcompo
 	}, nil
 }
 
+func (p grpcPlugin) ComposeWorkloadWITSVID(_ context.Context, a *witsvidcomposer.ComposeWorkloadWITSVIDRequest) (*witsvidcomposer.ComposeWorkloadWITSVIDResponse, error) {
+	return &witsvidcomposer.ComposeWorkloadWITSVIDResponse{
+		Attributes: a.Attributes,
+	}, nil
+}
+
 func loadGrpcPlugin(t *testing.T) credentialcomposer.CredentialComposer {
 	server := credentialcomposerv1.CredentialComposerPluginServer(grpcPlugin{})
 	cc := new(credentialcomposer.V1)
 	plugintest.Load(t, catalog.MakeBuiltIn("grpcPlugin", server), cc)
 	return cc
 }
+
+func loadNoopWITSVIDComposer(t *testing.T) credentialcomposer.WITSVIDComposer {
+	cc := new(credentialcomposer.WITSVIDComposerV1)
+	plugintest.Load(t, catalog.MakeBuiltIn("noop",
+		credentialcomposerv1.CredentialComposerPluginServer(credentialcomposerv1.UnimplementedCredentialComposerServer{}),
+		credentialcomposer.WITSVIDComposerServiceServer(witsvidcomposer.UnimplementedWITSVIDComposerServer{}),
+	), new(credentialcomposer.V1), plugintest.Services(cc))
+	return cc
+}
+
+func loadGrpcWITSVIDComposer(t *testing.T) credentialcomposer.WITSVIDComposer {
+	cc := new(credentialcomposer.WITSVIDComposerV1)
+	plugintest.Load(t, catalog.MakeBuiltIn("grpcPlugin",
+		credentialcomposerv1.CredentialComposerPluginServer(grpcPlugin{}),
+		credentialcomposer.WITSVIDComposerServiceServer(grpcPlugin{}),
+	), new(credentialcomposer.V1), plugintest.Services(cc))
+	return cc
+}
@@ -0,0 +1,118 @@
+// Package archive implements a versioned, signed archive format used to move
+// the contents of a SPIRE server datastore between backends.
+//
+// An archive is a stream of newline-delimited JSON records. The first record
+// is a header describing the archive, followed by the data records and a
+// final signature record. The signature covers the SHA-256 digest of every
+// byte that precedes the signature record.
+package archive
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Version is the version of the archive format written by Export. Import
+// rejects archives with any other version.
+const Version = 1
+
+const (
+	kindHeader                 = "header"
+	kindBundle                 = "bundle"
+	kindFederationRelationship = "federation_relationship"
+	kindAttestedNode           = "attested_node"
+	kindRegistrationEntry      = "registration_entry"
+	kindEntryTemplate          = "entry_template"
+	kindJoinToken              = "join_token"
+	kindCAJournal              = "ca_journal"
+	kindSignature              = "signature"
+)
+
+// Header describes an archive.
+type Header struct {
+	Version     int       `json:"version"`
+	TrustDomain string    `json:"trust_domain"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Counts holds the number of records of each kind.
+type Counts struct {
+	Bundles                 int
+	FederationRelationships int
+	AttestedNodes           int
+	RegistrationEntries     int
+	EntryTemplates          int
+	JoinTokens              int
+	CAJournals              int
+}
+
+// Total returns the total number of records.
+func (c Counts) Total() int {
+	return c.Bundles + c.FederationRelationships + c.AttestedNodes + c.RegistrationEntries + c.EntryTemplates + c.JoinTokens + c.CAJournals
+}
+
+type record struct {
+	Kind      string          `json:"kind"`
+	Header    *Header         `json:"header,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Signature *signature      `json:"signature,omitempty"`
+}
+
+type signature struct {
+	Digest string `json:"digest"`
+	Value  []byte `json:"value"`
+}
+
+type federationRelationshipData struct {
+	TrustDomain           string `json:"trust_domain"`
+	BundleEndpointURL     string `json:"bundle_endpoint_url"`
+	BundleEndpointProfile string `json:"bundle_endpoint_profile"`
+	EndpointSPIFFEID      string `json:"endpoint_spiffe_id,omitempty"`
+}
+
+type joinTokenData struct {
+	Token  string `json:"token"`
+	Expiry int64  `json:"expiry"`
+}
+
+type caJournalData struct {
+	ActiveX509AuthorityID string `json:"active_x509_authority_id"`
+	Data                  []byte `json:"data"`
+}
+
+func sign(signer crypto.Signer, digest []byte) ([]byte, error) {
+	var opts crypto.SignerOpts = crypto.SHA256
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		opts = crypto.Hash(0)
+	}
+	return signer.Sign(rand.Reader, digest, opts)
+}
+
+func verify(publicKey crypto.PublicKey, digest, sig []byte) error {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, sig) {
+			return errors.New("invalid archive signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig); err != nil {
+			return errors.New("invalid archive signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, digest, sig) {
+			return errors.New("invalid archive signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported verification key type %T", publicKey)
+	}
+}
@@ -0,0 +1,497 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ctx         = context.Background()
+	td          = spiffeid.RequireTrustDomainFromString("example.org")
+	federatedTD = spiffeid.RequireTrustDomainFromString("federated.org")
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	key := testkey.NewEC256(t)
+	src := populate(t)
+
+	buf := new(bytes.Buffer)
+	counts, err := archive.Export(ctx, src, buf, td, key)
+	require.NoError(t, err)
+	expected := archive.Counts{
+		Bundles:                 2,
+		FederationRelationships: 1,
+		AttestedNodes:           1,
+		RegistrationEntries:     2,
+		EntryTemplates:          1,
+		JoinTokens:              1,
+		CAJournals:              1,
+	}
+	assert.Equal(t, expected, counts)
+
+	dst := fakedatastore.New(t)
+	result, err := archive.Import(ctx, dst, bytes.NewReader(buf.Bytes()), archive.ImportOptions{
+		TrustDomain:     td,
+		VerificationKey: key.Public(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, result.Imported)
+	assert.Equal(t, archive.Counts{}, result.Skipped)
+	assert.Equal(t, archive.Version, result.Header.Version)
+	assert.Equal(t, td.Name(), result.Header.TrustDomain)
+
+	bundle, err := dst.FetchBundle(ctx, federatedTD.IDString())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), bundle.RefreshHint)
+
+	fr, err := dst.FetchFederationRelationship(ctx, federatedTD)
+	require.NoError(t, err)
+	require.NotNil(t, fr)
+	assert.Equal(t, "https://federated.org/bundle", fr.BundleEndpointURL.String())
+	assert.Equal(t, datastore.BundleEndpointWeb, fr.BundleEndpointProfile)
+
+	node, err := dst.FetchAttestedNode(ctx, "spiffe://example.org/spire/agent/node1")
+	require.NoError(t, err)
+	require.NotNil(t, node)
+	assert.Equal(t, "serial", node.CertSerialNumber)
+	selectors, err := dst.GetNodeSelectors(ctx, node.SpiffeId, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, []*common.Selector{{Type: "node", Value: "a"}}, selectors)
+
+	entry, err := dst.FetchRegistrationEntry(ctx, "entry2")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, []string{federatedTD.IDString()}, entry.FederatesWith)
+
+	template, err := dst.FetchEntryTemplate(ctx, "template1")
+	require.NoError(t, err)
+	require.NotNil(t, template)
+	assert.Equal(t, "spiffe://example.org/ns/{{ .k8s.ns }}", template.SpiffeIdTemplate)
+
+	token, err := dst.FetchJoinToken(ctx, "token")
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	caJournal, err := dst.FetchCAJournal(ctx, "authority")
+	require.NoError(t, err)
+	require.NotNil(t, caJournal)
+	assert.Equal(t, []byte("journal"), caJournal.Data)
+}
+
+func TestImportVerification(t *testing.T) {
+	key := testkey.NewEC256(t)
+	buf := new(bytes.Buffer)
+	_, err := archive.Export(ctx, populate(t), buf, td, key)
+	require.NoError(t, err)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name        string
+		archive     []byte
+		opts        archive.ImportOptions
+		expectedErr string
+	}{
+		{
+			name:        "no verification key",
+			archive:     buf.Bytes(),
+			opts:        archive.ImportOptions{TrustDomain: td},
+			expectedErr: "a verification key is required",
+		},
+		{
+			name:        "wrong verification key",
+			archive:     buf.Bytes(),
+			opts:        archive.ImportOptions{TrustDomain: td, VerificationKey: otherKey.Public()},
+			expectedErr: "invalid archive signature",
+		},
+		{
+			name:        "tampered archive",
+			archive:     bytes.Replace(buf.Bytes(), []byte("node1"), []byte("node2"), 1),
+			opts:        archive.ImportOptions{TrustDomain: td, VerificationKey: key.Public()},
+			expectedErr: "archive digest mismatch: the archive has been modified",
+		},
+		{
+			name:        "missing signature",
+			archive:     buf.Bytes()[:bytes.LastIndex(buf.Bytes()[:buf.Len()-1], []byte("\n"))+1],
+			opts:        archive.ImportOptions{TrustDomain: td, VerificationKey: key.Public()},
+			expectedErr: "malformed archive: missing signature",
+		},
+		{
+			name:        "empty archive",
+			opts:        archive.ImportOptions{TrustDomain: td, VerificationKey: key.Public()},
+			expectedErr: "malformed archive: missing header",
+		},
+		{
+			name:        "trust domain mismatch",
+			archive:     buf.Bytes(),
+			opts:        archive.ImportOptions{TrustDomain: federatedTD, VerificationKey: key.Public()},
+			expectedErr: `archive trust domain "example.org" does not match the server trust domain "federated.org"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := fakedatastore.New(t)
+			_, err := archive.Import(ctx, dst, bytes.NewReader(tt.archive), tt.opts)
+			require.EqualError(t, err, tt.expectedErr)
+
+			bundles, err := dst.CountBundles(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, bundles)
+		})
+	}
+}
+
+func TestImportConflicts(t *testing.T) {
+	key := testkey.NewEC256(t)
+	buf := new(bytes.Buffer)
+	src := populate(t)
+	_, err := archive.Export(ctx, src, buf, td, key)
+	require.NoError(t, err)
+
+	importInto := func(ds datastore.DataStore, conflict archive.ConflictMode) (*archive.ImportResult, error) {
+		return archive.Import(ctx, ds, bytes.NewReader(buf.Bytes()), archive.ImportOptions{
+			TrustDomain:     td,
+			VerificationKey: key.Public(),
+			Conflict:        conflict,
+		})
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		_, err := importInto(src, archive.ConflictFail)
+		require.EqualError(t, err, `bundle "spiffe://example.org" already exists`)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		result, err := importInto(src, archive.ConflictSkip)
+		require.NoError(t, err)
+		assert.Zero(t, result.Imported.Total())
+		assert.Equal(t, 9, result.Skipped.Total())
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		_, err := src.UpdateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:         "spiffe://example.org/spire/agent/node1",
+			CertSerialNumber: "changed",
+		}, &common.AttestedNodeMask{CertSerialNumber: true})
+		require.NoError(t, err)
+		_, err = src.DeleteEntryTemplate(ctx, "template1")
+		require.NoError(t, err)
+		_, err = src.CreateEntryTemplate(ctx, &common.EntryTemplate{
+			TemplateId:       "template1",
+			ParentId:         "spiffe://example.org/spire/agent/node1",
+			SpiffeIdTemplate: "spiffe://example.org/changed/{{ .k8s.ns }}",
+			Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+		})
+		require.NoError(t, err)
+
+		result, err := importInto(src, archive.ConflictOverwrite)
+		require.NoError(t, err)
+		assert.Equal(t, 9, result.Imported.Total())
+		assert.Zero(t, result.Skipped.Total())
+
+		node, err := src.FetchAttestedNode(ctx, "spiffe://example.org/spire/agent/node1")
+		require.NoError(t, err)
+		assert.Equal(t, "serial", node.CertSerialNumber)
+
+		template, err := src.FetchEntryTemplate(ctx, "template1")
+		require.NoError(t, err)
+		assert.Equal(t, "spiffe://example.org/ns/{{ .k8s.ns }}", template.SpiffeIdTemplate)
+
+		resp, err := src.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+		require.NoError(t, err)
+		assert.Len(t, resp.Entries, 2)
+	})
+}
+
+func TestImportConflictFailWritesNothing(t *testing.T) {
+	key := testkey.NewEC256(t)
+	buf := new(bytes.Buffer)
+	_, err := archive.Export(ctx, populate(t), buf, td, key)
+	require.NoError(t, err)
+
+	// The CA journal is the last record of the archive
+	dst := fakedatastore.New(t)
+	_, err = dst.SetCAJournal(ctx, &datastore.CAJournal{ActiveX509AuthorityID: "authority"})
+	require.NoError(t, err)
+
+	_, err = archive.Import(ctx, dst, bytes.NewReader(buf.Bytes()), archive.ImportOptions{
+		TrustDomain:     td,
+		VerificationKey: key.Public(),
+	})
+	require.EqualError(t, err, `ca_journal "authority" already exists`)
+
+	bundles, err := dst.CountBundles(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, bundles)
+	entries, err := dst.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
+	require.NoError(t, err)
+	assert.Zero(t, entries)
+}
+
+func TestImportSimilarEntry(t *testing.T) {
+	key := testkey.NewEC256(t)
+	buf := new(bytes.Buffer)
+	_, err := archive.Export(ctx, populate(t), buf, td, key)
+	require.NoError(t, err)
+
+	newDataStore := func(t *testing.T) datastore.DataStore {
+		ds := fakedatastore.New(t)
+		_, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			EntryId:   "similar",
+			ParentId:  "spiffe://example.org/spire/agent/node1",
+			SpiffeId:  "spiffe://example.org/workload1",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			Hint:      "existing",
+		})
+		require.NoError(t, err)
+		return ds
+	}
+	importInto := func(ds datastore.DataStore, conflict archive.ConflictMode, dryRun bool) (*archive.ImportResult, error) {
+		return archive.Import(ctx, ds, bytes.NewReader(buf.Bytes()), archive.ImportOptions{
+			TrustDomain:     td,
+			VerificationKey: key.Public(),
+			Conflict:        conflict,
+			DryRun:          dryRun,
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		_, err := importInto(newDataStore(t), archive.ConflictFail, true)
+		require.EqualError(t, err, `registration_entry "entry1" already exists`)
+
+		result, err := importInto(newDataStore(t), archive.ConflictSkip, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Imported.RegistrationEntries)
+		assert.Equal(t, 1, result.Skipped.RegistrationEntries)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		ds := newDataStore(t)
+		result, err := importInto(ds, archive.ConflictOverwrite, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Imported.RegistrationEntries)
+
+		// The similar entry is updated in place
+		entry, err := ds.FetchRegistrationEntry(ctx, "similar")
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		assert.Empty(t, entry.Hint)
+
+		resp, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+		require.NoError(t, err)
+		assert.Len(t, resp.Entries, 2)
+	})
+}
+
+func TestImportDuplicateRecords(t *testing.T) {
+	key := testkey.NewEC256(t)
+
+	for _, tt := range []struct {
+		name        string
+		ds          datastore.DataStore
+		expectedErr string
+	}{
+		{
+			name: "duplicate record",
+			ds: &duplicatingDataStore{
+				DataStore: populate(t),
+				template:  &common.EntryTemplate{TemplateId: "template1", SpiffeIdTemplate: "spiffe://example.org/{{ .id }}"},
+			},
+			expectedErr: `malformed archive: duplicate entry_template "template1"`,
+		},
+		{
+			name: "similar entries",
+			ds: &duplicatingDataStore{
+				DataStore: populate(t),
+				entry: &common.RegistrationEntry{
+					EntryId:   "entry3",
+					ParentId:  "spiffe://example.org/spire/agent/node1",
+					SpiffeId:  "spiffe://example.org/workload1",
+					Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+				},
+			},
+			expectedErr: `malformed archive: registration entries "entry1" and "entry3" have the same parent ID, SPIFFE ID and selectors`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			_, err := archive.Export(ctx, tt.ds, buf, td, key)
+			require.NoError(t, err)
+
+			dst := fakedatastore.New(t)
+			_, err = archive.Import(ctx, dst, bytes.NewReader(buf.Bytes()), archive.ImportOptions{
+				TrustDomain:     td,
+				VerificationKey: key.Public(),
+			})
+			require.EqualError(t, err, tt.expectedErr)
+
+			bundles, err := dst.CountBundles(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, bundles)
+		})
+	}
+}
+
+func TestImportArchiveModifiedDuringImport(t *testing.T) {
+	key := testkey.NewEC256(t)
+	buf := new(bytes.Buffer)
+	_, err := archive.Export(ctx, populate(t), buf, td, key)
+	require.NoError(t, err)
+
+	dst := fakedatastore.New(t)
+	_, err = archive.Import(ctx, dst, &modifiedOnSeekReader{
+		Reader:   bytes.NewReader(buf.Bytes()),
+		modified: bytes.Replace(buf.Bytes(), []byte("node1"), []byte("node2"), 1),
+	}, archive.ImportOptions{
+		TrustDomain:     td,
+		VerificationKey: key.Public(),
+	})
+	require.EqualError(t, err, "archive digest mismatch: the archive was modified during the import")
+
+	bundles, err := dst.CountBundles(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, bundles)
+}
+
+func TestImportDryRun(t *testing.T) {
+	key := testkey.NewEC256(t)
+	buf := new(bytes.Buffer)
+	_, err := archive.Export(ctx, populate(t), buf, td, key)
+	require.NoError(t, err)
+
+	dst := fakedatastore.New(t)
+	result, err := archive.Import(ctx, dst, bytes.NewReader(buf.Bytes()), archive.ImportOptions{
+		TrustDomain:     td,
+		VerificationKey: key.Public(),
+		DryRun:          true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9, result.Imported.Total())
+
+	bundles, err := dst.CountBundles(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, bundles)
+	entries, err := dst.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
+	require.NoError(t, err)
+	assert.Zero(t, entries)
+}
+
+func TestParseConflictMode(t *testing.T) {
+	mode, err := archive.ParseConflictMode("")
+	require.NoError(t, err)
+	assert.Equal(t, archive.ConflictFail, mode)
+
+	mode, err = archive.ParseConflictMode("overwrite")
+	require.NoError(t, err)
+	assert.Equal(t, archive.ConflictOverwrite, mode)
+
+	_, err = archive.ParseConflictMode("merge")
+	require.EqualError(t, err, `unknown conflict mode "merge": expected "fail", "skip" or "overwrite"`)
+}
+
+func populate(t *testing.T) datastore.DataStore {
+	ds := fakedatastore.New(t)
+
+	_, err := ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: td.IDString()})
+	require.NoError(t, err)
+
+	_, err = ds.CreateFederationRelationship(ctx, &datastore.FederationRelationship{
+		TrustDomain:           federatedTD,
+		BundleEndpointURL:     &url.URL{Scheme: "https", Host: "federated.org", Path: "/bundle"},
+		BundleEndpointProfile: datastore.BundleEndpointWeb,
+		TrustDomainBundle:     &common.Bundle{TrustDomainId: federatedTD.IDString(), RefreshHint: 10},
+	})
+	require.NoError(t, err)
+
+	_, err = ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/node1",
+		AttestationDataType: "test",
+		CertSerialNumber:    "serial",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.SetNodeSelectors(ctx, "spiffe://example.org/spire/agent/node1", []*common.Selector{{Type: "node", Value: "a"}}))
+
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:   "entry1",
+		ParentId:  "spiffe://example.org/spire/agent/node1",
+		SpiffeId:  "spiffe://example.org/workload1",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:       "entry2",
+		ParentId:      "spiffe://example.org/spire/agent/node1",
+		SpiffeId:      "spiffe://example.org/workload2",
+		Selectors:     []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+		FederatesWith: []string{federatedTD.IDString()},
+	})
+	require.NoError(t, err)
+
+	_, err = ds.CreateEntryTemplate(ctx, &common.EntryTemplate{
+		TemplateId:       "template1",
+		ParentId:         "spiffe://example.org/spire/agent/node1",
+		SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+		Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ds.CreateJoinToken(ctx, &datastore.JoinToken{Token: "token", Expiry: time.Now().Add(time.Hour)}))
+
+	_, err = ds.SetCAJournal(ctx, &datastore.CAJournal{ActiveX509AuthorityID: "authority", Data: []byte("journal")})
+	require.NoError(t, err)
+
+	return ds
+}
+
+// duplicatingDataStore lists an extra entry template and registration entry,
+// to export archives that the datastore can't hold.
+type duplicatingDataStore struct {
+	datastore.DataStore
+
+	template *common.EntryTemplate
+	entry    *common.RegistrationEntry
+}
+
+func (ds *duplicatingDataStore) ListEntryTemplates(ctx context.Context) ([]*common.EntryTemplate, error) {
+	templates, err := ds.DataStore.ListEntryTemplates(ctx)
+	if err != nil || ds.template == nil {
+		return templates, err
+	}
+	return append(templates, ds.template), nil
+}
+
+func (ds *duplicatingDataStore) ListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+	resp, err := ds.DataStore.ListRegistrationEntries(ctx, req)
+	if err != nil || ds.entry == nil || req.Pagination.Token != "" {
+		return resp, err
+	}
+	resp.Entries = append(resp.Entries, ds.entry)
+	return resp, nil
+}
+
+// modifiedOnSeekReader reads the modified archive once it is rewound.
+type modifiedOnSeekReader struct {
+	*bytes.Reader
+	modified []byte
+}
+
+func (r *modifiedOnSeekReader) Seek(offset int64, whence int) (int64, error) {
+	r.Reader.Reset(r.modified)
+	return r.Reader.Seek(offset, whence)
+}
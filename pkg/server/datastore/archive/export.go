@@ -0,0 +1,263 @@
+package archive
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// exportPageSize is the page size used when listing records to export.
+const exportPageSize = 1000
+
+// Export writes the contents of the datastore to w as a signed archive. The
+// archive is streamed; records are listed page by page and are never held in
+// memory all at once.
+func Export(ctx context.Context, ds datastore.DataStore, w io.Writer, trustDomain spiffeid.TrustDomain, signer crypto.Signer) (Counts, error) {
+	e := &exporter{
+		ds:     ds,
+		digest: sha256.New(),
+		output: w,
+	}
+	e.w = io.MultiWriter(e.output, e.digest)
+
+	if err := e.writeRecord(&record{
+		Kind: kindHeader,
+		Header: &Header{
+			Version:     Version,
+			TrustDomain: trustDomain.Name(),
+			CreatedAt:   time.Now().UTC().Truncate(time.Second),
+		},
+	}); err != nil {
+		return Counts{}, err
+	}
+
+	for _, export := range []func(context.Context) error{
+		e.exportBundles,
+		e.exportFederationRelationships,
+		e.exportAttestedNodes,
+		e.exportRegistrationEntries,
+		e.exportEntryTemplates,
+		e.exportJoinTokens,
+		e.exportCAJournals,
+	} {
+		if err := export(ctx); err != nil {
+			return Counts{}, err
+		}
+	}
+
+	digest := e.digest.Sum(nil)
+	sig, err := sign(signer, digest)
+	if err != nil {
+		return Counts{}, fmt.Errorf("failed to sign archive: %w", err)
+	}
+
+	// The signature record is written to the output only, since it is not
+	// covered by the digest.
+	e.w = e.output
+	if err := e.writeRecord(&record{
+		Kind: kindSignature,
+		Signature: &signature{
+			Digest: hex.EncodeToString(digest),
+			Value:  sig,
+		},
+	}); err != nil {
+		return Counts{}, err
+	}
+
+	return e.counts, nil
+}
+
+type exporter struct {
+	ds     datastore.DataStore
+	digest hash.Hash
+	output io.Writer
+	w      io.Writer
+	counts Counts
+}
+
+func (e *exporter) exportBundles(ctx context.Context) error {
+	pagination := &datastore.Pagination{PageSize: exportPageSize}
+	for {
+		resp, err := e.ds.ListBundles(ctx, &datastore.ListBundlesRequest{
+			Pagination: pagination,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list bundles: %w", err)
+		}
+		if len(resp.Bundles) == 0 {
+			return nil
+		}
+		for _, bundle := range resp.Bundles {
+			if err := e.writeProto(kindBundle, bundle); err != nil {
+				return err
+			}
+			e.counts.Bundles++
+		}
+		pagination = resp.Pagination
+	}
+}
+
+func (e *exporter) exportFederationRelationships(ctx context.Context) error {
+	pagination := &datastore.Pagination{PageSize: exportPageSize}
+	for {
+		resp, err := e.ds.ListFederationRelationships(ctx, &datastore.ListFederationRelationshipsRequest{
+			Pagination: pagination,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list federation relationships: %w", err)
+		}
+		if len(resp.FederationRelationships) == 0 {
+			return nil
+		}
+		for _, fr := range resp.FederationRelationships {
+			// The trust domain bundle is exported with the rest of the bundles.
+			data := &federationRelationshipData{
+				TrustDomain:           fr.TrustDomain.Name(),
+				BundleEndpointProfile: string(fr.BundleEndpointProfile),
+			}
+			if fr.BundleEndpointURL != nil {
+				data.BundleEndpointURL = fr.BundleEndpointURL.String()
+			}
+			if fr.BundleEndpointProfile == datastore.BundleEndpointSPIFFE {
+				data.EndpointSPIFFEID = fr.EndpointSPIFFEID.String()
+			}
+			if err := e.writeJSON(kindFederationRelationship, data); err != nil {
+				return err
+			}
+			e.counts.FederationRelationships++
+		}
+		pagination = resp.Pagination
+	}
+}
+
+func (e *exporter) exportAttestedNodes(ctx context.Context) error {
+	pagination := &datastore.Pagination{PageSize: exportPageSize}
+	for {
+		resp, err := e.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
+			FetchSelectors: true,
+			Pagination:     pagination,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list attested nodes: %w", err)
+		}
+		if len(resp.Nodes) == 0 {
+			return nil
+		}
+		for _, node := range resp.Nodes {
+			if err := e.writeProto(kindAttestedNode, node); err != nil {
+				return err
+			}
+			e.counts.AttestedNodes++
+		}
+		pagination = resp.Pagination
+	}
+}
+
+func (e *exporter) exportRegistrationEntries(ctx context.Context) error {
+	pagination := &datastore.Pagination{PageSize: exportPageSize}
+	for {
+		resp, err := e.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			Pagination: pagination,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list registration entries: %w", err)
+		}
+		if len(resp.Entries) == 0 {
+			return nil
+		}
+		for _, entry := range resp.Entries {
+			if err := e.writeProto(kindRegistrationEntry, entry); err != nil {
+				return err
+			}
+			e.counts.RegistrationEntries++
+		}
+		pagination = resp.Pagination
+	}
+}
+
+func (e *exporter) exportEntryTemplates(ctx context.Context) error {
+	templates, err := e.ds.ListEntryTemplates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list entry templates: %w", err)
+	}
+	for _, template := range templates {
+		if err := e.writeProto(kindEntryTemplate, template); err != nil {
+			return err
+		}
+		e.counts.EntryTemplates++
+	}
+	return nil
+}
+
+func (e *exporter) exportJoinTokens(ctx context.Context) error {
+	tokens, err := e.ds.ListJoinTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list join tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if err := e.writeJSON(kindJoinToken, &joinTokenData{
+			Token:  token.Token,
+			Expiry: token.Expiry.Unix(),
+		}); err != nil {
+			return err
+		}
+		e.counts.JoinTokens++
+	}
+	return nil
+}
+
+func (e *exporter) exportCAJournals(ctx context.Context) error {
+	caJournals, err := e.ds.ListCAJournals(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list CA journals: %w", err)
+	}
+	for _, caJournal := range caJournals {
+		if err := e.writeJSON(kindCAJournal, &caJournalData{
+			ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
+			Data:                  caJournal.Data,
+		}); err != nil {
+			return err
+		}
+		e.counts.CAJournals++
+	}
+	return nil
+}
+
+func (e *exporter) writeProto(kind string, m proto.Message) error {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return e.writeRecord(&record{Kind: kind, Data: data})
+}
+
+func (e *exporter) writeJSON(kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return e.writeRecord(&record{Kind: kind, Data: data})
+}
+
+func (e *exporter) writeRecord(r *record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s record: %w", r.Kind, err)
+	}
+	line = append(line, '\n')
+	if _, err := e.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
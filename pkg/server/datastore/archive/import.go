@@ -0,0 +1,620 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConflictMode controls how Import handles records that already exist in the
+// datastore.
+type ConflictMode string
+
+const (
+	// ConflictFail aborts the import on the first conflicting record.
+	ConflictFail ConflictMode = "fail"
+
+	// ConflictSkip leaves the existing record untouched.
+	ConflictSkip ConflictMode = "skip"
+
+	// ConflictOverwrite replaces the existing record with the archived one.
+	ConflictOverwrite ConflictMode = "overwrite"
+)
+
+// ParseConflictMode parses a conflict mode. An empty string parses as
+// ConflictFail.
+func ParseConflictMode(s string) (ConflictMode, error) {
+	switch mode := ConflictMode(s); mode {
+	case "":
+		return ConflictFail, nil
+	case ConflictFail, ConflictSkip, ConflictOverwrite:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown conflict mode %q: expected %q, %q or %q", s, ConflictFail, ConflictSkip, ConflictOverwrite)
+	}
+}
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	// TrustDomain is the trust domain of the destination server. Archives
+	// exported from a different trust domain are rejected.
+	TrustDomain spiffeid.TrustDomain
+
+	// VerificationKey is the public key used to verify the archive signature.
+	VerificationKey crypto.PublicKey
+
+	// Conflict is the conflict resolution mode. Defaults to ConflictFail.
+	Conflict ConflictMode
+
+	// DryRun, when set, checks the archive against the datastore without
+	// making any change.
+	DryRun bool
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Header   Header
+	Imported Counts
+	Skipped  Counts
+}
+
+// Import verifies the archive read from r and writes its records into the
+// datastore. The archive is streamed rather than loaded in memory, so it is
+// read several times: once to verify the signature, once to check every
+// record against the datastore and, unless DryRun is set, once more to write
+// the records. Records read after the verification must have the digest they
+// had when the signature was verified. Malformed records, records repeated in
+// the archive and conflicts abort the import before the first write.
+func Import(ctx context.Context, ds datastore.DataStore, r io.ReadSeeker, opts ImportOptions) (*ImportResult, error) {
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictFail
+	}
+
+	header, digests, err := verifyArchive(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := newImporter(ds, opts, header)
+	if err := forEachRecord(r, digests, func(rec *record) error {
+		_, err := planner.planRecord(ctx, rec)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return planner.result, nil
+	}
+
+	// The records are planned again as they are written. Since the archive
+	// has no duplicates, earlier writes don't change the plan of the later
+	// records.
+	writer := newImporter(ds, opts, header)
+	if err := forEachRecord(r, digests, func(rec *record) error {
+		write, err := writer.planRecord(ctx, rec)
+		if err != nil || write == nil {
+			return err
+		}
+		return write()
+	}); err != nil {
+		return nil, err
+	}
+
+	return planner.result, nil
+}
+
+// verifyArchive reads the full archive and checks the header and the
+// signature, returning the header and the digests of the signed records.
+func verifyArchive(r io.Reader, opts ImportOptions) (*Header, [][sha256.Size]byte, error) {
+	if opts.VerificationKey == nil {
+		return nil, nil, errors.New("a verification key is required")
+	}
+
+	var header *Header
+	var sig *signature
+	var digests [][sha256.Size]byte
+	h := sha256.New()
+	br := bufio.NewReader(r)
+	for {
+		line, err := readLine(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if sig != nil {
+			return nil, nil, errors.New("malformed archive: unexpected record after the signature")
+		}
+
+		rec, err := parseRecord(line)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case header == nil:
+			if rec.Kind != kindHeader || rec.Header == nil {
+				return nil, nil, errors.New("malformed archive: missing header")
+			}
+			header = rec.Header
+		case rec.Kind == kindHeader:
+			return nil, nil, errors.New("malformed archive: duplicate header")
+		case rec.Kind == kindSignature:
+			if rec.Signature == nil {
+				return nil, nil, errors.New("malformed archive: empty signature")
+			}
+			sig = rec.Signature
+			continue
+		default:
+			digests = append(digests, sha256.Sum256(line))
+		}
+		_, _ = h.Write(line)
+	}
+
+	switch {
+	case header == nil:
+		return nil, nil, errors.New("malformed archive: missing header")
+	case sig == nil:
+		return nil, nil, errors.New("malformed archive: missing signature")
+	case header.Version != Version:
+		return nil, nil, fmt.Errorf("unsupported archive version %d: expected %d", header.Version, Version)
+	}
+
+	digest := h.Sum(nil)
+	if sig.Digest != hex.EncodeToString(digest) {
+		return nil, nil, errors.New("archive digest mismatch: the archive has been modified")
+	}
+	if err := verify(opts.VerificationKey, digest, sig.Value); err != nil {
+		return nil, nil, err
+	}
+
+	if !opts.TrustDomain.IsZero() && header.TrustDomain != opts.TrustDomain.Name() {
+		return nil, nil, fmt.Errorf("archive trust domain %q does not match the server trust domain %q", header.TrustDomain, opts.TrustDomain.Name())
+	}
+
+	return header, digests, nil
+}
+
+// forEachRecord reads the records of an archive verified by verifyArchive
+// again, calling fn with each of them. Records that no longer have the
+// verified digest are rejected.
+func forEachRecord(r io.ReadSeeker, digests [][sha256.Size]byte, fn func(*record) error) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind archive: %w", err)
+	}
+
+	br := bufio.NewReader(r)
+	// The header was already checked.
+	if _, err := readLine(br); err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	for _, digest := range digests {
+		line, err := readLine(br)
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if sha256.Sum256(line) != digest {
+			return errors.New("archive digest mismatch: the archive was modified during the import")
+		}
+
+		rec, err := parseRecord(line)
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type importer struct {
+	ds     datastore.DataStore
+	opts   ImportOptions
+	result *ImportResult
+
+	// seen holds the kind and key of the records planned so far, and
+	// similarEntries the registration entries by parent ID, SPIFFE ID and
+	// selectors, to detect records repeated in the archive.
+	seen           map[string]bool
+	similarEntries map[string]string
+}
+
+func newImporter(ds datastore.DataStore, opts ImportOptions, header *Header) *importer {
+	return &importer{
+		ds:             ds,
+		opts:           opts,
+		result:         &ImportResult{Header: *header},
+		seen:           make(map[string]bool),
+		similarEntries: make(map[string]string),
+	}
+}
+
+// planRecord checks a record against the datastore and applies the conflict
+// mode. It returns the write that imports the record, or nil if the record is
+// skipped.
+func (i *importer) planRecord(ctx context.Context, rec *record) (func() error, error) {
+	switch rec.Kind {
+	case kindBundle:
+		bundle := new(common.Bundle)
+		if err := unmarshalProto(rec, bundle); err != nil {
+			return nil, err
+		}
+		return i.planBundle(ctx, bundle)
+	case kindFederationRelationship:
+		data := new(federationRelationshipData)
+		if err := unmarshalJSON(rec, data); err != nil {
+			return nil, err
+		}
+		fr, err := data.toFederationRelationship()
+		if err != nil {
+			return nil, err
+		}
+		return i.planFederationRelationship(ctx, fr)
+	case kindAttestedNode:
+		node := new(common.AttestedNode)
+		if err := unmarshalProto(rec, node); err != nil {
+			return nil, err
+		}
+		return i.planAttestedNode(ctx, node)
+	case kindRegistrationEntry:
+		entry := new(common.RegistrationEntry)
+		if err := unmarshalProto(rec, entry); err != nil {
+			return nil, err
+		}
+		return i.planRegistrationEntry(ctx, entry)
+	case kindEntryTemplate:
+		template := new(common.EntryTemplate)
+		if err := unmarshalProto(rec, template); err != nil {
+			return nil, err
+		}
+		return i.planEntryTemplate(ctx, template)
+	case kindJoinToken:
+		data := new(joinTokenData)
+		if err := unmarshalJSON(rec, data); err != nil {
+			return nil, err
+		}
+		return i.planJoinToken(ctx, &datastore.JoinToken{
+			Token:  data.Token,
+			Expiry: time.Unix(data.Expiry, 0),
+		})
+	case kindCAJournal:
+		data := new(caJournalData)
+		if err := unmarshalJSON(rec, data); err != nil {
+			return nil, err
+		}
+		return i.planCAJournal(ctx, &datastore.CAJournal{
+			ActiveX509AuthorityID: data.ActiveX509AuthorityID,
+			Data:                  data.Data,
+		})
+	default:
+		return nil, fmt.Errorf("malformed archive: unknown record kind %q", rec.Kind)
+	}
+}
+
+func (i *importer) planBundle(ctx context.Context, bundle *common.Bundle) (func() error, error) {
+	existing, err := i.ds.FetchBundle(ctx, bundle.TrustDomainId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bundle %q: %w", bundle.TrustDomainId, err)
+	}
+
+	apply := func() error {
+		_, err := i.ds.CreateBundle(ctx, bundle)
+		return err
+	}
+	if existing != nil {
+		apply = func() error {
+			_, err := i.ds.SetBundle(ctx, bundle)
+			return err
+		}
+	}
+
+	return i.resolve(kindBundle, bundle.TrustDomainId, existing != nil, &i.result.Imported.Bundles, &i.result.Skipped.Bundles, apply)
+}
+
+func (i *importer) planFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship) (func() error, error) {
+	existing, err := i.ds.FetchFederationRelationship(ctx, fr.TrustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch federation relationship %q: %w", fr.TrustDomain, err)
+	}
+
+	apply := func() error {
+		_, err := i.ds.CreateFederationRelationship(ctx, fr)
+		return err
+	}
+	if existing != nil {
+		apply = func() error {
+			_, err := i.ds.UpdateFederationRelationship(ctx, fr, &types.FederationRelationshipMask{
+				BundleEndpointUrl:     true,
+				BundleEndpointProfile: true,
+			})
+			return err
+		}
+	}
+
+	return i.resolve(kindFederationRelationship, fr.TrustDomain.Name(), existing != nil, &i.result.Imported.FederationRelationships, &i.result.Skipped.FederationRelationships, apply)
+}
+
+func (i *importer) planAttestedNode(ctx context.Context, node *common.AttestedNode) (func() error, error) {
+	existing, err := i.ds.FetchAttestedNode(ctx, node.SpiffeId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attested node %q: %w", node.SpiffeId, err)
+	}
+
+	apply := func() error {
+		if existing != nil {
+			if _, err := i.ds.UpdateAttestedNode(ctx, node, nil); err != nil {
+				return err
+			}
+		} else if _, err := i.ds.CreateAttestedNode(ctx, node); err != nil {
+			return err
+		}
+		return i.ds.SetNodeSelectors(ctx, node.SpiffeId, node.Selectors)
+	}
+
+	return i.resolve(kindAttestedNode, node.SpiffeId, existing != nil, &i.result.Imported.AttestedNodes, &i.result.Skipped.AttestedNodes, apply)
+}
+
+func (i *importer) planRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (func() error, error) {
+	// The datastore can only hold one of two similar entries.
+	similarKey := similarEntryKey(entry)
+	if other, ok := i.similarEntries[similarKey]; ok {
+		return nil, fmt.Errorf("malformed archive: registration entries %q and %q have the same parent ID, SPIFFE ID and selectors", other, entry.EntryId)
+	}
+	i.similarEntries[similarKey] = entry.EntryId
+
+	existing, err := i.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registration entry %q: %w", entry.EntryId, err)
+	}
+	if existing == nil {
+		// The datastore refuses to create an entry similar to an existing
+		// one (same parent ID, SPIFFE ID and selectors), so a similar entry
+		// is a conflict even if its ID is different.
+		existing, err = i.lookupSimilarEntry(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up registration entries similar to %q: %w", entry.EntryId, err)
+		}
+	}
+
+	if existing == nil {
+		return i.resolve(kindRegistrationEntry, entry.EntryId, false, &i.result.Imported.RegistrationEntries, &i.result.Skipped.RegistrationEntries, func() error {
+			_, existed, err := i.ds.CreateOrReturnRegistrationEntry(ctx, proto.Clone(entry).(*common.RegistrationEntry))
+			if err == nil && existed {
+				err = errors.New("a similar registration entry was created during the import")
+			}
+			return err
+		})
+	}
+
+	// The existing entry is updated in place, keeping its ID.
+	update := proto.Clone(entry).(*common.RegistrationEntry)
+	update.EntryId = existing.EntryId
+	return i.resolve(kindRegistrationEntry, entry.EntryId, true, &i.result.Imported.RegistrationEntries, &i.result.Skipped.RegistrationEntries, func() error {
+		_, err := i.ds.UpdateRegistrationEntry(ctx, update, nil)
+		return err
+	})
+}
+
+// lookupSimilarEntry returns an entry with the same parent ID, SPIFFE ID and
+// selectors as the given entry, if any.
+func (i *importer) lookupSimilarEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
+	resp, err := i.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		ByParentID: entry.ParentId,
+		BySpiffeID: entry.SpiffeId,
+		BySelectors: &datastore.BySelectors{
+			Match:     datastore.Exact,
+			Selectors: entry.Selectors,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range resp.Entries {
+		if sameSelectors(candidate.Selectors, entry.Selectors) {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+func (i *importer) planEntryTemplate(ctx context.Context, template *common.EntryTemplate) (func() error, error) {
+	existing, err := i.ds.FetchEntryTemplate(ctx, template.TemplateId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch entry template %q: %w", template.TemplateId, err)
+	}
+
+	return i.resolve(kindEntryTemplate, template.TemplateId, existing != nil, &i.result.Imported.EntryTemplates, &i.result.Skipped.EntryTemplates, func() error {
+		if existing == nil {
+			_, err := i.ds.CreateEntryTemplate(ctx, template)
+			return err
+		}
+		if proto.Equal(existing, template) {
+			return nil
+		}
+
+		// Entry templates can't be updated, so the existing template is
+		// replaced, and restored if the archived one can't be created.
+		if _, err := i.ds.DeleteEntryTemplate(ctx, template.TemplateId); err != nil {
+			return err
+		}
+		if _, err := i.ds.CreateEntryTemplate(ctx, template); err != nil {
+			_, restoreErr := i.ds.CreateEntryTemplate(ctx, existing)
+			return errors.Join(err, restoreErr)
+		}
+		return nil
+	})
+}
+
+func (i *importer) planJoinToken(ctx context.Context, token *datastore.JoinToken) (func() error, error) {
+	existing, err := i.ds.FetchJoinToken(ctx, token.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch join token: %w", err)
+	}
+
+	return i.resolve(kindJoinToken, token.Token, existing != nil, &i.result.Imported.JoinTokens, &i.result.Skipped.JoinTokens, func() error {
+		if existing == nil {
+			return i.ds.CreateJoinToken(ctx, token)
+		}
+		if existing.Expiry.Equal(token.Expiry) {
+			return nil
+		}
+
+		// Join tokens can't be updated, so the existing token is replaced,
+		// and restored if the archived one can't be created.
+		if err := i.ds.DeleteJoinToken(ctx, token.Token); err != nil {
+			return err
+		}
+		if err := i.ds.CreateJoinToken(ctx, token); err != nil {
+			return errors.Join(err, i.ds.CreateJoinToken(ctx, existing))
+		}
+		return nil
+	})
+}
+
+func (i *importer) planCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (func() error, error) {
+	existing, err := i.ds.FetchCAJournal(ctx, caJournal.ActiveX509AuthorityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CA journal %q: %w", caJournal.ActiveX509AuthorityID, err)
+	}
+	if existing != nil {
+		caJournal.ID = existing.ID
+	}
+
+	return i.resolve(kindCAJournal, caJournal.ActiveX509AuthorityID, existing != nil, &i.result.Imported.CAJournals, &i.result.Skipped.CAJournals, func() error {
+		_, err := i.ds.SetCAJournal(ctx, caJournal)
+		return err
+	})
+}
+
+// resolve applies the conflict mode to a record. It returns the write that
+// calls apply to import the record, or nil if the record is skipped.
+func (i *importer) resolve(kind, key string, exists bool, imported, skipped *int, apply func() error) (func() error, error) {
+	if i.seen[kind+"/"+key] {
+		return nil, fmt.Errorf("malformed archive: duplicate %s %q", kind, key)
+	}
+	i.seen[kind+"/"+key] = true
+
+	if exists {
+		switch i.opts.Conflict {
+		case ConflictSkip:
+			*skipped++
+			return nil, nil
+		case ConflictOverwrite:
+		default:
+			return nil, fmt.Errorf("%s %q already exists", kind, key)
+		}
+	}
+
+	*imported++
+	return func() error {
+		if err := apply(); err != nil {
+			return fmt.Errorf("failed to import %s %q: %w", kind, key, err)
+		}
+		return nil
+	}, nil
+}
+
+// similarEntryKey returns the parent ID, SPIFFE ID and selectors of an entry
+// as a string, which is the same for similar entries.
+func similarEntryKey(entry *common.RegistrationEntry) string {
+	selectors := make([]string, 0, len(entry.Selectors))
+	for _, s := range entry.Selectors {
+		selectors = append(selectors, s.Type+":"+s.Value)
+	}
+	slices.Sort(selectors)
+	selectors = slices.Compact(selectors)
+	return entry.ParentId + "\x00" + entry.SpiffeId + "\x00" + strings.Join(selectors, "\x00")
+}
+
+// sameSelectors returns whether both lists hold the same set of selectors.
+func sameSelectors(a, b []*common.Selector) bool {
+	set := make(map[string]struct{}, len(a))
+	for _, s := range a {
+		set[s.Type+":"+s.Value] = struct{}{}
+	}
+	other := make(map[string]struct{}, len(b))
+	for _, s := range b {
+		if _, ok := set[s.Type+":"+s.Value]; !ok {
+			return false
+		}
+		other[s.Type+":"+s.Value] = struct{}{}
+	}
+	return len(set) == len(other)
+}
+
+func (d *federationRelationshipData) toFederationRelationship() (*datastore.FederationRelationship, error) {
+	td, err := spiffeid.TrustDomainFromString(d.TrustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("malformed federation relationship: %w", err)
+	}
+	bundleEndpointURL, err := url.Parse(d.BundleEndpointURL)
+	if err != nil {
+		return nil, fmt.Errorf("malformed federation relationship %q: %w", d.TrustDomain, err)
+	}
+
+	fr := &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointURL:     bundleEndpointURL,
+		BundleEndpointProfile: datastore.BundleEndpointType(d.BundleEndpointProfile),
+	}
+	if fr.BundleEndpointProfile == datastore.BundleEndpointSPIFFE {
+		fr.EndpointSPIFFEID, err = spiffeid.FromString(d.EndpointSPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("malformed federation relationship %q: %w", d.TrustDomain, err)
+		}
+	}
+	return fr, nil
+}
+
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadBytes('\n')
+	switch {
+	case errors.Is(err, io.EOF) && len(bytes.TrimSpace(line)) > 0:
+		return nil, errors.New("malformed archive: truncated record")
+	case err != nil && !errors.Is(err, io.EOF):
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return line, err
+}
+
+func parseRecord(line []byte) (*record, error) {
+	rec := new(record)
+	if err := json.Unmarshal(line, rec); err != nil {
+		return nil, fmt.Errorf("malformed archive: %w", err)
+	}
+	return rec, nil
+}
+
+func unmarshalProto(rec *record, m proto.Message) error {
+	if err := protojson.Unmarshal(rec.Data, m); err != nil {
+		return fmt.Errorf("malformed %s record: %w", rec.Kind, err)
+	}
+	return nil
+}
+
+func unmarshalJSON(rec *record, v any) error {
+	if err := json.Unmarshal(rec.Data, v); err != nil {
+		return fmt.Errorf("malformed %s record: %w", rec.Kind, err)
+	}
+	return nil
+}
@@ -28,6 +28,8 @@ type DataStore interface {
 	RevokeX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToRevoke string) error
 	TaintJWTKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error)
 	RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error)
+	TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error)
+	RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error)
 
 	// Entries
 	CountRegistrationEntries(context.Context, *CountRegistrationEntriesRequest) (int32, error)
@@ -40,6 +42,12 @@ type DataStore interface {
 	PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) error
 	UpdateRegistrationEntry(context.Context, *common.RegistrationEntry, *common.RegistrationEntryMask) (*common.RegistrationEntry, error)
 
+	// Entry templates
+	CreateEntryTemplate(context.Context, *common.EntryTemplate) (*common.EntryTemplate, error)
+	DeleteEntryTemplate(ctx context.Context, templateID string) (*common.EntryTemplate, error)
+	FetchEntryTemplate(ctx context.Context, templateID string) (*common.EntryTemplate, error)
+	ListEntryTemplates(context.Context) ([]*common.EntryTemplate, error)
+
 	// Entries Events
 	ListRegistrationEntryEvents(ctx context.Context, req *ListRegistrationEntryEventsRequest) (*ListRegistrationEntryEventsResponse, error)
 	PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) error
@@ -72,6 +80,7 @@ type DataStore interface {
 	CreateJoinToken(context.Context, *JoinToken) error
 	DeleteJoinToken(ctx context.Context, token string) error
 	FetchJoinToken(ctx context.Context, token string) (*JoinToken, error)
+	ListJoinTokens(context.Context) ([]*JoinToken, error)
 	PruneJoinTokens(context.Context, time.Time) error
 
 	// Federation Relationships
@@ -85,7 +94,12 @@ type DataStore interface {
 	SetCAJournal(ctx context.Context, caJournal *CAJournal) (*CAJournal, error)
 	FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (*CAJournal, error)
 	PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) error
-	ListCAJournalsForTesting(ctx context.Context) ([]*CAJournal, error)
+	ListCAJournals(ctx context.Context) ([]*CAJournal, error)
+
+	// Feature flag statuses
+	ListFeatureFlagStatuses(ctx context.Context) ([]*FeatureFlagStatus, error)
+	PruneServerFeatureFlagStatuses(ctx context.Context, reportedBefore time.Time) error
+	SetFeatureFlagStatus(ctx context.Context, status *FeatureFlagStatus) error
 }
 
 // DataConsistency indicates the required data consistency for a read operation.
@@ -222,6 +236,24 @@ type CAJournal struct {
 	ActiveX509AuthorityID string
 }
 
+// FeatureFlagStatus holds the feature flags enabled on a server or an agent,
+// as last reported by it. The status of an agent is deleted along with its
+// attested node.
+type FeatureFlagStatus struct {
+	// ReporterID is the ID of the server, or the SPIFFE ID of the agent, that
+	// reported the status.
+	ReporterID string
+
+	// IsServer is true if the status was reported by a server
+	IsServer bool
+
+	// Enabled holds the names of the enabled feature flags
+	Enabled []string
+
+	// ReportedAt is when the status was reported (seconds since Unix epoch)
+	ReportedAt int64
+}
+
 type ListRegistrationEntriesResponse struct {
 	Entries    []*common.RegistrationEntry
 	Pagination *Pagination
@@ -0,0 +1,517 @@
+package kvstore
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/protoutil"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func createBundle(tx *bbolt.Tx, bundle *common.Bundle) (*common.Bundle, error) {
+	data, err := marshalBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	byTD := tx.Bucket(bundlesByTrustDomainBucket)
+	if _, ok := lookupRowID(byTD, bundle.TrustDomainId); ok {
+		return nil, newAlreadyExistsError("bundle %q", bundle.TrustDomainId)
+	}
+
+	rowID, err := putWithNewRowID(tx.Bucket(bundlesBucket), data)
+	if err != nil {
+		return nil, err
+	}
+	if err := byTD.Put(uniqueKey(bundle.TrustDomainId), itob(rowID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return bundle, nil
+}
+
+func updateBundle(tx *bbolt.Tx, newBundle *common.Bundle, mask *common.BundleMask) (*common.Bundle, error) {
+	if newBundle == nil {
+		return nil, newKVError("missing bundle in request")
+	}
+
+	rowID, bundle, err := getBundleRow(tx, newBundle.TrustDomainId)
+	if err != nil {
+		return nil, err
+	}
+
+	if mask == nil {
+		mask = protoutil.AllTrueCommonBundleMask
+	}
+	if mask.RefreshHint {
+		bundle.RefreshHint = newBundle.RefreshHint
+	}
+	if mask.RootCas {
+		bundle.RootCas = newBundle.RootCas
+	}
+	if mask.JwtSigningKeys {
+		bundle.JwtSigningKeys = newBundle.JwtSigningKeys
+	}
+	if mask.WitSigningKeys {
+		bundle.WitSigningKeys = newBundle.WitSigningKeys
+	}
+	if mask.SequenceNumber {
+		bundle.SequenceNumber = newBundle.SequenceNumber
+	}
+
+	if err := putBundle(tx, rowID, bundle); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func setBundle(tx *bbolt.Tx, b *common.Bundle) (*common.Bundle, error) {
+	if b == nil {
+		return nil, newKVError("missing bundle in request")
+	}
+
+	if _, ok := lookupRowID(tx.Bucket(bundlesByTrustDomainBucket), b.TrustDomainId); !ok {
+		return createBundle(tx, b)
+	}
+	return updateBundle(tx, b, nil)
+}
+
+func appendBundle(tx *bbolt.Tx, b *common.Bundle) (*common.Bundle, error) {
+	if b == nil {
+		return nil, newKVError("missing bundle in request")
+	}
+
+	rowID, ok := lookupRowID(tx.Bucket(bundlesByTrustDomainBucket), b.TrustDomainId)
+	if !ok {
+		return createBundle(tx, b)
+	}
+
+	bundle, err := loadBundle(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, changed := bundleutil.MergeBundles(bundle, b)
+	if changed {
+		bundle.SequenceNumber++
+		if err := putBundle(tx, rowID, bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	return bundle, nil
+}
+
+func deleteBundle(tx *bbolt.Tx, trustDomainID string, mode datastore.DeleteMode) error {
+	byTD := tx.Bucket(bundlesByTrustDomainBucket)
+	rowID, ok := lookupRowID(byTD, trustDomainID)
+	if !ok {
+		return newWrappedKVError(errNotFound)
+	}
+
+	federatedEntries := scanIndex(tx.Bucket(entriesByFederatesWithBucket), indexPrefix(trustDomainID))
+	if len(federatedEntries) > 0 {
+		switch mode {
+		case datastore.Delete:
+			for _, entryRowID := range federatedEntries.sorted() {
+				entry, err := loadEntry(tx, entryRowID)
+				if err != nil {
+					return err
+				}
+				if err := removeEntry(tx, entryRowID, entry); err != nil {
+					return err
+				}
+				if err := createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+					EntryID: entry.EntryId,
+				}); err != nil {
+					return err
+				}
+			}
+		case datastore.Dissociate:
+			for _, entryRowID := range federatedEntries.sorted() {
+				entry, err := loadEntry(tx, entryRowID)
+				if err != nil {
+					return err
+				}
+				updated := proto.Clone(entry).(*common.RegistrationEntry)
+				updated.FederatesWith = removeString(updated.FederatesWith, trustDomainID)
+				if err := replaceEntry(tx, entryRowID, entry, updated); err != nil {
+					return err
+				}
+				if err := createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+					EntryID: entry.EntryId,
+				}); err != nil {
+					return err
+				}
+			}
+		default:
+			return status.Newf(codes.FailedPrecondition, "datastore-kv: cannot delete bundle; federated with %d registration entries", len(federatedEntries)).Err()
+		}
+	}
+
+	if err := tx.Bucket(bundlesBucket).Delete(itob(rowID)); err != nil {
+		return newWrappedKVError(err)
+	}
+	if err := byTD.Delete(uniqueKey(trustDomainID)); err != nil {
+		return newWrappedKVError(err)
+	}
+
+	return nil
+}
+
+// fetchBundle returns the bundle matching the specified Trust Domain, or nil
+// if there is no such bundle.
+func fetchBundle(tx *bbolt.Tx, trustDomainID string) (*common.Bundle, error) {
+	rowID, ok := lookupRowID(tx.Bucket(bundlesByTrustDomainBucket), trustDomainID)
+	if !ok {
+		return nil, nil
+	}
+	return loadBundle(tx, rowID)
+}
+
+func countBundles(tx *bbolt.Tx) (int32, error) {
+	return util.CheckedCast[int32](tx.Bucket(bundlesBucket).Stats().KeyN)
+}
+
+func listBundles(tx *bbolt.Tx, req *datastore.ListBundlesRequest) (*datastore.ListBundlesResponse, error) {
+	p := req.Pagination
+	afterID, err := paginationStart(p)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.ListBundlesResponse{}
+	var lastID uint64
+	if err := scanRows(tx.Bucket(bundlesBucket), afterID, nil, func(rowID uint64, v []byte) (bool, error) {
+		bundle, err := unmarshalBundle(v)
+		if err != nil {
+			return false, err
+		}
+		resp.Bundles = append(resp.Bundles, bundle)
+		lastID = rowID
+		return !pageFull(p, len(resp.Bundles)), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if p != nil {
+		p.Token = ""
+		if len(resp.Bundles) > 0 {
+			p.Token = fmt.Sprint(lastID)
+		}
+	}
+	resp.Pagination = p
+
+	return resp, nil
+}
+
+func pruneBundle(tx *bbolt.Tx, trustDomainID string, expiry time.Time, log logrus.FieldLogger) (bool, error) {
+	currentBundle, err := fetchBundle(tx, trustDomainID)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch current bundle: %w", err)
+	}
+
+	if currentBundle == nil {
+		// No bundle to prune
+		return false, nil
+	}
+
+	newBundle, changed, err := bundleutil.PruneBundle(currentBundle, expiry, log)
+	if err != nil {
+		return false, fmt.Errorf("prune failed: %w", err)
+	}
+
+	// Update only if bundle was modified
+	if changed {
+		newBundle.SequenceNumber = currentBundle.SequenceNumber + 1
+		if _, err := updateBundle(tx, newBundle, nil); err != nil {
+			return false, fmt.Errorf("unable to write new bundle: %w", err)
+		}
+	}
+
+	return changed, nil
+}
+
+func taintX509CA(tx *bbolt.Tx, trustDomainID string, subjectKeyIDToTaint string) error {
+	rowID, bundle, err := getBundleRow(tx, trustDomainID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, rootCA := range bundle.RootCas {
+		cert, err := x509.ParseCertificate(rootCA.DerBytes)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to parse rootCA: %v", err)
+		}
+
+		if subjectKeyIDToTaint != x509util.SubjectKeyIDToString(cert.SubjectKeyId) {
+			continue
+		}
+
+		if rootCA.TaintedKey {
+			return status.Errorf(codes.InvalidArgument, "root CA is already tainted")
+		}
+
+		found = true
+		rootCA.TaintedKey = true
+	}
+
+	if !found {
+		return status.Error(codes.NotFound, "no ca found with provided subject key ID")
+	}
+
+	bundle.SequenceNumber++
+	return putBundle(tx, rowID, bundle)
+}
+
+func revokeX509CA(tx *bbolt.Tx, trustDomainID string, subjectKeyIDToRevoke string) error {
+	rowID, bundle, err := getBundleRow(tx, trustDomainID)
+	if err != nil {
+		return err
+	}
+
+	keyFound := false
+	var rootCAs []*common.Certificate
+	for _, ca := range bundle.RootCas {
+		cert, err := x509.ParseCertificate(ca.DerBytes)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to parse root CA: %v", err)
+		}
+
+		if subjectKeyIDToRevoke == x509util.SubjectKeyIDToString(cert.SubjectKeyId) {
+			if !ca.TaintedKey {
+				return status.Error(codes.InvalidArgument, "it is not possible to revoke an untainted root CA")
+			}
+			keyFound = true
+			continue
+		}
+
+		rootCAs = append(rootCAs, ca)
+	}
+
+	if !keyFound {
+		return status.Error(codes.NotFound, "no root CA found with provided subject key ID")
+	}
+
+	bundle.RootCas = rootCAs
+	bundle.SequenceNumber++
+
+	if err := putBundle(tx, rowID, bundle); err != nil {
+		return status.Errorf(codes.Internal, "failed to update bundle: %v", err)
+	}
+
+	return nil
+}
+
+func taintJWTKey(tx *bbolt.Tx, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	rowID, bundle, err := getBundleRow(tx, trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var taintedKey *common.PublicKey
+	for _, jwtKey := range bundle.JwtSigningKeys {
+		if jwtKey.Kid != authorityID {
+			continue
+		}
+
+		if jwtKey.TaintedKey {
+			return nil, status.Error(codes.InvalidArgument, "key is already tainted")
+		}
+
+		// Purely defensive since repeated key IDs are not allowed
+		if taintedKey != nil {
+			return nil, status.Error(codes.Internal, "another JWT Key found with the same KeyID")
+		}
+		taintedKey = jwtKey
+		jwtKey.TaintedKey = true
+	}
+
+	if taintedKey == nil {
+		return nil, status.Error(codes.NotFound, "no JWT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if err := putBundle(tx, rowID, bundle); err != nil {
+		return nil, err
+	}
+
+	return taintedKey, nil
+}
+
+func taintWITKey(tx *bbolt.Tx, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	rowID, bundle, err := getBundleRow(tx, trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var taintedKey *common.PublicKey
+	for _, witKey := range bundle.WitSigningKeys {
+		if witKey.Kid != authorityID {
+			continue
+		}
+
+		if witKey.TaintedKey {
+			return nil, status.Error(codes.InvalidArgument, "key is already tainted")
+		}
+
+		// Purely defensive since repeated key IDs are not allowed
+		if taintedKey != nil {
+			return nil, status.Error(codes.Internal, "another WIT Key found with the same KeyID")
+		}
+		taintedKey = witKey
+		witKey.TaintedKey = true
+	}
+
+	if taintedKey == nil {
+		return nil, status.Error(codes.NotFound, "no WIT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if err := putBundle(tx, rowID, bundle); err != nil {
+		return nil, err
+	}
+
+	return taintedKey, nil
+}
+
+func revokeJWTKey(tx *bbolt.Tx, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	rowID, bundle, err := getBundleRow(tx, trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []*common.PublicKey
+	var revokedKey *common.PublicKey
+	for _, key := range bundle.JwtSigningKeys {
+		if key.Kid == authorityID {
+			// Purely defensive since repeated key IDs are not allowed
+			if revokedKey != nil {
+				return nil, status.Error(codes.Internal, "another key found with the same KeyID")
+			}
+
+			if !key.TaintedKey {
+				return nil, status.Error(codes.InvalidArgument, "it is not possible to revoke an untainted key")
+			}
+
+			revokedKey = key
+			continue
+		}
+		publicKeys = append(publicKeys, key)
+	}
+	bundle.JwtSigningKeys = publicKeys
+
+	if revokedKey == nil {
+		return nil, status.Error(codes.NotFound, "no JWT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if err := putBundle(tx, rowID, bundle); err != nil {
+		return nil, err
+	}
+
+	return revokedKey, nil
+}
+
+func revokeWITKey(tx *bbolt.Tx, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	rowID, bundle, err := getBundleRow(tx, trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []*common.PublicKey
+	var revokedKey *common.PublicKey
+	for _, key := range bundle.WitSigningKeys {
+		if key.Kid == authorityID {
+			// Purely defensive since repeated key IDs are not allowed
+			if revokedKey != nil {
+				return nil, status.Error(codes.Internal, "another key found with the same KeyID")
+			}
+
+			if !key.TaintedKey {
+				return nil, status.Error(codes.InvalidArgument, "it is not possible to revoke an untainted key")
+			}
+
+			revokedKey = key
+			continue
+		}
+		publicKeys = append(publicKeys, key)
+	}
+	bundle.WitSigningKeys = publicKeys
+
+	if revokedKey == nil {
+		return nil, status.Error(codes.NotFound, "no WIT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if err := putBundle(tx, rowID, bundle); err != nil {
+		return nil, err
+	}
+
+	return revokedKey, nil
+}
+
+// getBundleRow returns the bundle for the trust domain along with its row
+// ID, failing with a not found error if there is no such bundle.
+func getBundleRow(tx *bbolt.Tx, trustDomainID string) (uint64, *common.Bundle, error) {
+	rowID, ok := lookupRowID(tx.Bucket(bundlesByTrustDomainBucket), trustDomainID)
+	if !ok {
+		return 0, nil, newWrappedKVError(errNotFound)
+	}
+
+	bundle, err := loadBundle(tx, rowID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rowID, bundle, nil
+}
+
+func loadBundle(tx *bbolt.Tx, rowID uint64) (*common.Bundle, error) {
+	v := tx.Bucket(bundlesBucket).Get(itob(rowID))
+	if v == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	return unmarshalBundle(v)
+}
+
+func putBundle(tx *bbolt.Tx, rowID uint64, bundle *common.Bundle) error {
+	data, err := marshalBundle(bundle)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bundlesBucket).Put(itob(rowID), data); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func marshalBundle(bundle *common.Bundle) ([]byte, error) {
+	if bundle == nil {
+		return nil, newKVError("missing bundle in request")
+	}
+	data, err := proto.Marshal(bundle)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return data, nil
+}
+
+func unmarshalBundle(data []byte) (*common.Bundle, error) {
+	bundle := new(common.Bundle)
+	if err := proto.Unmarshal(data, bundle); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return bundle, nil
+}
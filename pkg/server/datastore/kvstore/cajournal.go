@@ -0,0 +1,135 @@
+package kvstore
+
+import (
+	"encoding/json"
+
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// caJournalRecord is the value stored for a CA journal, keyed by journal ID.
+type caJournalRecord struct {
+	Data                  []byte `json:"data"`
+	ActiveX509AuthorityID string `json:"active_x509_authority_id"`
+}
+
+func createCAJournal(tx *bbolt.Tx, caJournal *datastore.CAJournal) (*datastore.CAJournal, error) {
+	data, err := marshalCAJournal(caJournal)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := putWithNewRowID(tx.Bucket(caJournalsBucket), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &datastore.CAJournal{
+		ID:                    util.MustCast[uint](id),
+		Data:                  caJournal.Data,
+		ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
+	}, nil
+}
+
+func updateCAJournal(tx *bbolt.Tx, caJournal *datastore.CAJournal) (*datastore.CAJournal, error) {
+	b := tx.Bucket(caJournalsBucket)
+	key := itob(uint64(caJournal.ID))
+	if b.Get(key) == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+
+	data, err := marshalCAJournal(caJournal)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Put(key, data); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return &datastore.CAJournal{
+		ID:                    caJournal.ID,
+		Data:                  caJournal.Data,
+		ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
+	}, nil
+}
+
+// fetchCAJournal returns the first CA journal with the given active X509
+// authority ID, or nil if there is none.
+func fetchCAJournal(tx *bbolt.Tx, activeX509AuthorityID string) (*datastore.CAJournal, error) {
+	var caJournal *datastore.CAJournal
+	if err := scanRows(tx.Bucket(caJournalsBucket), 0, nil, func(id uint64, v []byte) (bool, error) {
+		journal, err := unmarshalCAJournal(id, v)
+		if err != nil {
+			return false, err
+		}
+		if journal.ActiveX509AuthorityID != activeX509AuthorityID {
+			return true, nil
+		}
+		caJournal = journal
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	return caJournal, nil
+}
+
+func listCAJournals(tx *bbolt.Tx) ([]*datastore.CAJournal, error) {
+	var caJournals []*datastore.CAJournal
+	if err := scanRows(tx.Bucket(caJournalsBucket), 0, nil, func(id uint64, v []byte) (bool, error) {
+		journal, err := unmarshalCAJournal(id, v)
+		if err != nil {
+			return false, err
+		}
+		caJournals = append(caJournals, journal)
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+	return caJournals, nil
+}
+
+func deleteCAJournal(tx *bbolt.Tx, caJournalID uint) error {
+	b := tx.Bucket(caJournalsBucket)
+	key := itob(uint64(caJournalID))
+	if b.Get(key) == nil {
+		return newWrappedKVError(errNotFound)
+	}
+	if err := b.Delete(key); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func validateCAJournal(caJournal *datastore.CAJournal) error {
+	if caJournal == nil {
+		return status.Error(codes.InvalidArgument, "ca journal is required")
+	}
+
+	return nil
+}
+
+func marshalCAJournal(caJournal *datastore.CAJournal) ([]byte, error) {
+	data, err := json.Marshal(&caJournalRecord{
+		Data:                  caJournal.Data,
+		ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
+	})
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return data, nil
+}
+
+func unmarshalCAJournal(id uint64, data []byte) (*datastore.CAJournal, error) {
+	record := new(caJournalRecord)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return &datastore.CAJournal{
+		ID:                    util.MustCast[uint](id),
+		Data:                  record.Data,
+		ActiveX509AuthorityID: record.ActiveX509AuthorityID,
+	}, nil
+}
@@ -0,0 +1,758 @@
+package kvstore
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Maximum size for additional attributes message in a registration entry
+const maxAdditionalAttributesSize = 65535
+
+var validEntryIDChars = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x002d, 0x002e, 1}, // - | .
+		{0x0030, 0x0039, 1}, // [0-9]
+		{0x0041, 0x005a, 1}, // [A-Z]
+		{0x005f, 0x005f, 1}, // _
+		{0x0061, 0x007a, 1}, // [a-z]
+	},
+	LatinOffset: 5,
+}
+
+func createRegistrationEntry(tx *bbolt.Tx, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
+	entryID, err := createOrReturnEntryID(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAdditionalAttributes(entry.AdditionalAttributes); err != nil {
+		return nil, err
+	}
+
+	if _, ok := lookupRowID(tx.Bucket(entriesByEntryIDBucket), entryID); ok {
+		return nil, newAlreadyExistsError("registration entry %q", entryID)
+	}
+
+	federatesWith, err := makeFederatesWith(tx, entry.FederatesWith)
+	if err != nil {
+		return nil, err
+	}
+
+	newEntry := proto.Clone(entry).(*common.RegistrationEntry)
+	newEntry.EntryId = entryID
+	newEntry.FederatesWith = federatesWith
+	newEntry.RevisionNumber = 0
+	newEntry.CreatedAt = roundedInSecondsUnix(time.Now())
+	newEntry = normalizeEntry(newEntry)
+
+	if err := checkEntryDuplicates(newEntry); err != nil {
+		return nil, err
+	}
+
+	if _, err := insertEntry(tx, newEntry); err != nil {
+		return nil, err
+	}
+
+	return newEntry, nil
+}
+
+func fetchRegistrationEntries(tx *bbolt.Tx, entryIDs []string) (map[string]*common.RegistrationEntry, error) {
+	byEntryID := tx.Bucket(entriesByEntryIDBucket)
+
+	entries := make(map[string]*common.RegistrationEntry, len(entryIDs))
+	for _, entryID := range entryIDs {
+		rowID, ok := lookupRowID(byEntryID, entryID)
+		if !ok {
+			continue
+		}
+		entry, err := loadEntry(tx, rowID)
+		if err != nil {
+			return nil, err
+		}
+		entries[entryID] = entry
+	}
+
+	return entries, nil
+}
+
+func listRegistrationEntries(tx *bbolt.Tx, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+	if req.Pagination != nil && req.Pagination.PageSize == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+	}
+	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+	}
+
+	afterID, err := paginationStart(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	f := entryFilter{
+		byParentID:      req.ByParentID,
+		bySpiffeID:      req.BySpiffeID,
+		bySelectors:     req.BySelectors,
+		byFederatesWith: req.ByFederatesWith,
+		byHint:          req.ByHint,
+		byDownstream:    req.ByDownstream,
+	}
+
+	entries := []*common.RegistrationEntry{}
+	var lastID uint64
+	if err := f.scan(tx, afterID, func(rowID uint64, entry *common.RegistrationEntry) bool {
+		entries = append(entries, entry)
+		lastID = rowID
+		return !pageFull(req.Pagination, len(entries))
+	}); err != nil {
+		return nil, err
+	}
+
+	return &datastore.ListRegistrationEntriesResponse{
+		Entries:    entries,
+		Pagination: nextPagination(req.Pagination, len(entries), lastID),
+	}, nil
+}
+
+func countRegistrationEntries(tx *bbolt.Tx, req *datastore.CountRegistrationEntriesRequest) (int32, error) {
+	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
+		return 0, status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+	}
+
+	f := entryFilter{
+		byParentID:      req.ByParentID,
+		bySpiffeID:      req.BySpiffeID,
+		bySelectors:     req.BySelectors,
+		byFederatesWith: req.ByFederatesWith,
+		byHint:          req.ByHint,
+		byDownstream:    req.ByDownstream,
+	}
+
+	var count int
+	if err := f.scan(tx, 0, func(uint64, *common.RegistrationEntry) bool {
+		count++
+		return true
+	}); err != nil {
+		return 0, err
+	}
+
+	return util.CheckedCast[int32](count)
+}
+
+func updateRegistrationEntry(tx *bbolt.Tx, e *common.RegistrationEntry, mask *common.RegistrationEntryMask) (*common.RegistrationEntry, error) {
+	if err := validateRegistrationEntryForUpdate(e, mask); err != nil {
+		return nil, err
+	}
+
+	rowID, ok := lookupRowID(tx.Bucket(entriesByEntryIDBucket), e.EntryId)
+	if !ok {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	existing, err := loadEntry(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	e = proto.Clone(e).(*common.RegistrationEntry)
+	entry := proto.Clone(existing).(*common.RegistrationEntry)
+	if mask == nil || mask.StoreSvid {
+		entry.StoreSvid = e.StoreSvid
+	}
+	if mask == nil || mask.Selectors {
+		entry.Selectors = e.Selectors
+	}
+
+	// Verify that final selectors contains the same 'type' when entry is used for store SVIDs
+	if entry.StoreSvid && !equalSelectorTypes(entry.Selectors) {
+		return nil, newValidationError("invalid registration entry: selector types must be the same when store SVID is enabled")
+	}
+
+	if mask == nil || mask.DnsNames {
+		entry.DnsNames = e.DnsNames
+	}
+	if mask == nil || mask.SpiffeId {
+		entry.SpiffeId = e.SpiffeId
+	}
+	if mask == nil || mask.ParentId {
+		entry.ParentId = e.ParentId
+	}
+	if mask == nil || mask.X509SvidTtl {
+		entry.X509SvidTtl = e.X509SvidTtl
+	}
+	if mask == nil || mask.Admin {
+		entry.Admin = e.Admin
+	}
+	if mask == nil || mask.Downstream {
+		entry.Downstream = e.Downstream
+	}
+	if mask == nil || mask.EntryExpiry {
+		entry.EntryExpiry = e.EntryExpiry
+	}
+	if mask == nil || mask.JwtSvidTtl {
+		entry.JwtSvidTtl = e.JwtSvidTtl
+	}
+	if mask == nil || mask.Hint {
+		entry.Hint = e.Hint
+	}
+	if mask == nil || mask.AdditionalAttributes {
+		if err := validateAdditionalAttributes(e.AdditionalAttributes); err != nil {
+			return nil, err
+		}
+		entry.AdditionalAttributes = e.AdditionalAttributes
+	}
+	if mask == nil || mask.FederatesWith {
+		entry.FederatesWith, err = makeFederatesWith(tx, e.FederatesWith)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Revision number is increased by 1 on every update call
+	entry.RevisionNumber++
+
+	entry = normalizeEntry(entry)
+	if err := checkEntryDuplicates(entry); err != nil {
+		return nil, err
+	}
+
+	if err := replaceEntry(tx, rowID, existing, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func deleteRegistrationEntry(tx *bbolt.Tx, entryID string) (*common.RegistrationEntry, error) {
+	rowID, ok := lookupRowID(tx.Bucket(entriesByEntryIDBucket), entryID)
+	if !ok {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	entry, err := loadEntry(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := removeEntry(tx, rowID, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func pruneRegistrationEntries(tx *bbolt.Tx, expiresBefore time.Time, logger logrus.FieldLogger) error {
+	// Entries without an expiry are not indexed, so every entry in the index
+	// up to the given time has expired.
+	var expired []uint64
+	c := tx.Bucket(entriesByExpiryBucket).Cursor()
+	for k, _ := c.First(); k != nil && fromSortableInt64(k[:8]) < expiresBefore.Unix(); k, _ = c.Next() {
+		expired = append(expired, btoi(k[8:]))
+	}
+
+	for _, rowID := range expired {
+		entry, err := loadEntry(tx, rowID)
+		if err != nil {
+			return err
+		}
+		if err := removeEntry(tx, rowID, entry); err != nil {
+			return err
+		}
+		if err := createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entry.EntryId,
+		}); err != nil {
+			return err
+		}
+		logger.WithFields(logrus.Fields{
+			telemetry.SPIFFEID:       entry.SpiffeId,
+			telemetry.ParentID:       entry.ParentId,
+			telemetry.RegistrationID: entry.EntryId,
+		}).Info("Pruned an expired registration")
+	}
+
+	return nil
+}
+
+// lookupSimilarEntry returns an entry with the same parent ID, SPIFFE ID and
+// selectors as the given entry, if any.
+func lookupSimilarEntry(tx *bbolt.Tx, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
+	resp, err := listRegistrationEntries(tx, &datastore.ListRegistrationEntriesRequest{
+		BySpiffeID: entry.SpiffeId,
+		ByParentID: entry.ParentId,
+		BySelectors: &datastore.BySelectors{
+			Match:     datastore.Exact,
+			Selectors: entry.Selectors,
+		},
+		Pagination: &datastore.Pagination{
+			PageSize: 1,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Entries) > 0 {
+		return resp.Entries[0], nil
+	}
+	return nil, nil
+}
+
+// entryFilter holds the criteria used to list and count registration entries.
+type entryFilter struct {
+	byParentID      string
+	bySpiffeID      string
+	bySelectors     *datastore.BySelectors
+	byFederatesWith *datastore.ByFederatesWith
+	byHint          string
+	byDownstream    *bool
+}
+
+// scan visits, in row ID order, the entries after the given row ID that match
+// the filter. Candidate rows are gathered from the secondary indexes; every
+// candidate is then checked against the full criteria.
+func (f *entryFilter) scan(tx *bbolt.Tx, afterID uint64, fn func(rowID uint64, entry *common.RegistrationEntry) bool) error {
+	candidates := f.candidates(tx)
+
+	var matchErr error
+	err := scanRows(tx.Bucket(entriesBucket), afterID, candidates, func(rowID uint64, v []byte) (bool, error) {
+		entry, err := unmarshalEntry(v)
+		if err != nil {
+			return false, err
+		}
+		ok, err := f.matches(entry)
+		if err != nil {
+			matchErr = err
+			return false, nil
+		}
+		if !ok {
+			return true, nil
+		}
+		return fn(rowID, entry), nil
+	})
+	if err != nil {
+		return err
+	}
+	return matchErr
+}
+
+// candidates returns the rows that can possibly match the filter according
+// to the secondary indexes, or nil if the filter cannot be narrowed down by
+// the indexes.
+func (f *entryFilter) candidates(tx *bbolt.Tx) rowSet {
+	var sets []rowSet
+	if f.byParentID != "" {
+		sets = append(sets, scanIndex(tx.Bucket(entriesByParentIDBucket), indexPrefix(f.byParentID)))
+	}
+	if f.bySpiffeID != "" {
+		sets = append(sets, scanIndex(tx.Bucket(entriesBySpiffeIDBucket), indexPrefix(f.bySpiffeID)))
+	}
+	if f.byHint != "" {
+		sets = append(sets, scanIndex(tx.Bucket(entriesByHintBucket), indexPrefix(f.byHint)))
+	}
+	if f.bySelectors != nil && len(f.bySelectors.Selectors) > 0 {
+		var selectorSets []rowSet
+		for _, s := range f.bySelectors.Selectors {
+			selectorSets = append(selectorSets, scanIndex(tx.Bucket(entriesBySelectorBucket), indexPrefix(s.Type, s.Value)))
+		}
+		sets = append(sets, combineRowSets(selectorSets, isUnionMatch(f.bySelectors.Match)))
+	}
+	if f.byFederatesWith != nil && len(f.byFederatesWith.TrustDomains) > 0 {
+		var tdSets []rowSet
+		for _, td := range f.byFederatesWith.TrustDomains {
+			tdSets = append(tdSets, scanIndex(tx.Bucket(entriesByFederatesWithBucket), indexPrefix(td)))
+		}
+		sets = append(sets, combineRowSets(tdSets, isUnionMatch(f.byFederatesWith.Match)))
+	}
+
+	if len(sets) == 0 {
+		return nil
+	}
+	return combineRowSets(sets, false)
+}
+
+func (f *entryFilter) matches(entry *common.RegistrationEntry) (bool, error) {
+	if f.byParentID != "" && entry.ParentId != f.byParentID {
+		return false, nil
+	}
+	if f.bySpiffeID != "" && entry.SpiffeId != f.bySpiffeID {
+		return false, nil
+	}
+	if f.byHint != "" && entry.Hint != f.byHint {
+		return false, nil
+	}
+	if f.byDownstream != nil && entry.Downstream != *f.byDownstream {
+		return false, nil
+	}
+	if f.bySelectors != nil && len(f.bySelectors.Selectors) > 0 {
+		ok, err := matchSet(selectorKeys(entry.Selectors), selectorKeys(f.bySelectors.Selectors), f.bySelectors.Match)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if f.byFederatesWith != nil && len(f.byFederatesWith.TrustDomains) > 0 {
+		ok, err := matchSet(entry.FederatesWith, f.byFederatesWith.TrustDomains, f.byFederatesWith.Match)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isUnionMatch returns true if a record matching any of the requested values
+// is a candidate for the match behavior.
+func isUnionMatch(match datastore.MatchBehavior) bool {
+	return match == datastore.Subset || match == datastore.MatchAny
+}
+
+// matchSet returns true if the values of a record satisfy the match behavior
+// against the requested values:
+//   - Exact: the record has exactly the requested values
+//   - Subset: the record has at least one value, all of them requested
+//   - Superset: the record has all the requested values
+//   - MatchAny: the record has at least one of the requested values
+func matchSet(have, want []string, match datastore.MatchBehavior) (bool, error) {
+	wantSet := make(map[string]struct{}, len(want))
+	for _, v := range want {
+		wantSet[v] = struct{}{}
+	}
+	haveSet := make(map[string]struct{}, len(have))
+	for _, v := range have {
+		haveSet[v] = struct{}{}
+	}
+
+	containsAll := func(set map[string]struct{}, values map[string]struct{}) bool {
+		for v := range values {
+			if _, ok := set[v]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch match {
+	case datastore.Exact:
+		return containsAll(haveSet, wantSet) && containsAll(wantSet, haveSet), nil
+	case datastore.Subset:
+		return len(haveSet) > 0 && containsAll(wantSet, haveSet), nil
+	case datastore.Superset:
+		return containsAll(haveSet, wantSet), nil
+	case datastore.MatchAny:
+		for v := range haveSet {
+			if _, ok := wantSet[v]; ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, newKVError("unhandled match behavior %d", match)
+	}
+}
+
+func selectorKeys(selectors []*common.Selector) []string {
+	keys := make([]string, 0, len(selectors))
+	for _, s := range selectors {
+		keys = append(keys, string(indexPrefix(s.Type, s.Value)))
+	}
+	return keys
+}
+
+func insertEntry(tx *bbolt.Tx, entry *common.RegistrationEntry) (uint64, error) {
+	data, err := marshalEntry(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	rowID, err := putWithNewRowID(tx.Bucket(entriesBucket), data)
+	if err != nil {
+		return 0, err
+	}
+	if err := putEntryIndexes(tx, rowID, entry); err != nil {
+		return 0, err
+	}
+	return rowID, nil
+}
+
+func replaceEntry(tx *bbolt.Tx, rowID uint64, existing, entry *common.RegistrationEntry) error {
+	if err := deleteEntryIndexes(tx, rowID, existing); err != nil {
+		return err
+	}
+
+	data, err := marshalEntry(entry)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(entriesBucket).Put(itob(rowID), data); err != nil {
+		return newWrappedKVError(err)
+	}
+
+	return putEntryIndexes(tx, rowID, entry)
+}
+
+func removeEntry(tx *bbolt.Tx, rowID uint64, entry *common.RegistrationEntry) error {
+	if err := deleteEntryIndexes(tx, rowID, entry); err != nil {
+		return err
+	}
+	if err := tx.Bucket(entriesBucket).Delete(itob(rowID)); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+// entryIndexKeys returns the keys of the index entries for the entry, by
+// index bucket.
+func entryIndexKeys(rowID uint64, entry *common.RegistrationEntry) map[string][][]byte {
+	keys := map[string][][]byte{
+		string(entriesByParentIDBucket): {indexKey(rowID, entry.ParentId)},
+		string(entriesBySpiffeIDBucket): {indexKey(rowID, entry.SpiffeId)},
+	}
+	for _, s := range entry.Selectors {
+		keys[string(entriesBySelectorBucket)] = append(keys[string(entriesBySelectorBucket)], indexKey(rowID, s.Type, s.Value))
+	}
+	for _, td := range entry.FederatesWith {
+		keys[string(entriesByFederatesWithBucket)] = append(keys[string(entriesByFederatesWithBucket)], indexKey(rowID, td))
+	}
+	if entry.Hint != "" {
+		keys[string(entriesByHintBucket)] = [][]byte{indexKey(rowID, entry.Hint)}
+	}
+	if entry.EntryExpiry != 0 {
+		keys[string(entriesByExpiryBucket)] = [][]byte{append(sortableInt64(entry.EntryExpiry), itob(rowID)...)}
+	}
+	return keys
+}
+
+func putEntryIndexes(tx *bbolt.Tx, rowID uint64, entry *common.RegistrationEntry) error {
+	if err := tx.Bucket(entriesByEntryIDBucket).Put(uniqueKey(entry.EntryId), itob(rowID)); err != nil {
+		return newWrappedKVError(err)
+	}
+	for bucket, keys := range entryIndexKeys(rowID, entry) {
+		b := tx.Bucket([]byte(bucket))
+		for _, key := range keys {
+			if err := b.Put(key, nil); err != nil {
+				return newWrappedKVError(err)
+			}
+		}
+	}
+	return nil
+}
+
+func deleteEntryIndexes(tx *bbolt.Tx, rowID uint64, entry *common.RegistrationEntry) error {
+	if err := tx.Bucket(entriesByEntryIDBucket).Delete(uniqueKey(entry.EntryId)); err != nil {
+		return newWrappedKVError(err)
+	}
+	for bucket, keys := range entryIndexKeys(rowID, entry) {
+		b := tx.Bucket([]byte(bucket))
+		for _, key := range keys {
+			if err := b.Delete(key); err != nil {
+				return newWrappedKVError(err)
+			}
+		}
+	}
+	return nil
+}
+
+func loadEntry(tx *bbolt.Tx, rowID uint64) (*common.RegistrationEntry, error) {
+	v := tx.Bucket(entriesBucket).Get(itob(rowID))
+	if v == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	return unmarshalEntry(v)
+}
+
+func marshalEntry(entry *common.RegistrationEntry) ([]byte, error) {
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return data, nil
+}
+
+func unmarshalEntry(data []byte) (*common.RegistrationEntry, error) {
+	entry := new(common.RegistrationEntry)
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return entry, nil
+}
+
+// normalizeEntry returns the entry the way it is returned after being read
+// back from the datastore, with empty lists and attributes set to nil.
+func normalizeEntry(entry *common.RegistrationEntry) *common.RegistrationEntry {
+	if len(entry.DnsNames) == 0 {
+		entry.DnsNames = nil
+	}
+	if len(entry.FederatesWith) == 0 {
+		entry.FederatesWith = nil
+	}
+	if entry.AdditionalAttributes != nil && proto.Size(entry.AdditionalAttributes) == 0 {
+		entry.AdditionalAttributes = nil
+	}
+	return entry
+}
+
+// checkEntryDuplicates fails if the entry has repeated selectors or DNS
+// names, which are unique per entry.
+func checkEntryDuplicates(entry *common.RegistrationEntry) error {
+	selectors := make(map[string]struct{}, len(entry.Selectors))
+	for _, s := range entry.Selectors {
+		key := string(indexPrefix(s.Type, s.Value))
+		if _, ok := selectors[key]; ok {
+			return newAlreadyExistsError("duplicate selector %s:%s", s.Type, s.Value)
+		}
+		selectors[key] = struct{}{}
+	}
+
+	dnsNames := make(map[string]struct{}, len(entry.DnsNames))
+	for _, dnsName := range entry.DnsNames {
+		if _, ok := dnsNames[dnsName]; ok {
+			return newAlreadyExistsError("duplicate DNS name %q", dnsName)
+		}
+		dnsNames[dnsName] = struct{}{}
+	}
+
+	return nil
+}
+
+// makeFederatesWith verifies that there is a bundle for each of the trust
+// domains and returns them without duplicates.
+func makeFederatesWith(tx *bbolt.Tx, ids []string) ([]string, error) {
+	byTD := tx.Bucket(bundlesByTrustDomainBucket)
+
+	var federatesWith []string
+	for _, id := range ids {
+		if _, ok := lookupRowID(byTD, id); !ok {
+			return nil, fmt.Errorf("unable to find federated bundle %q", id)
+		}
+		if !slices.Contains(federatesWith, id) {
+			federatesWith = append(federatesWith, id)
+		}
+	}
+
+	return federatesWith, nil
+}
+
+func removeString(values []string, value string) []string {
+	return slices.DeleteFunc(values, func(v string) bool {
+		return v == value
+	})
+}
+
+func validateAdditionalAttributes(additionalAttributes *common.RegistrationEntry_AdditionalAttributes) error {
+	if additionalAttributes == nil {
+		return nil
+	}
+
+	if proto.Size(additionalAttributes) > maxAdditionalAttributesSize {
+		return newValidationError("invalid registration entry: additional attributes size exceeds the maximum allowed size of %d bytes", maxAdditionalAttributesSize)
+	}
+
+	return nil
+}
+
+func validateRegistrationEntry(entry *common.RegistrationEntry) error {
+	if entry == nil {
+		return newValidationError("invalid request: missing registered entry")
+	}
+
+	if len(entry.Selectors) == 0 {
+		return newValidationError("invalid registration entry: missing selector list")
+	}
+
+	// In case of StoreSvid is set, all entries 'must' be the same type,
+	// it is done to avoid users to mix selectors from different platforms in
+	// entries with storable SVIDs
+	if entry.StoreSvid {
+		if entry.AdditionalAttributes.GetDisableX509SvidPrefetch() {
+			return newValidationError("specifying cache behaviour is incompatible with storable SVIDs")
+		}
+		if !equalSelectorTypes(entry.Selectors) {
+			return newValidationError("invalid registration entry: selector types must be the same when store SVID is enabled")
+		}
+	}
+
+	if len(entry.EntryId) > 255 {
+		return newValidationError("invalid registration entry: entry ID too long")
+	}
+
+	for _, e := range entry.EntryId {
+		if !unicode.In(e, validEntryIDChars) {
+			return newValidationError("invalid registration entry: entry ID contains invalid characters")
+		}
+	}
+
+	if len(entry.SpiffeId) == 0 {
+		return newValidationError("invalid registration entry: missing SPIFFE ID")
+	}
+
+	if entry.X509SvidTtl < 0 {
+		return newValidationError("invalid registration entry: X509SvidTtl is not set")
+	}
+
+	if entry.JwtSvidTtl < 0 {
+		return newValidationError("invalid registration entry: JwtSvidTtl is not set")
+	}
+
+	return nil
+}
+
+func validateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) error {
+	if entry == nil {
+		return newValidationError("invalid request: missing registered entry")
+	}
+
+	if (mask == nil || mask.Selectors) && len(entry.Selectors) == 0 {
+		return newValidationError("invalid registration entry: missing selector list")
+	}
+
+	if (mask == nil || mask.SpiffeId) && entry.SpiffeId == "" {
+		return newValidationError("invalid registration entry: missing SPIFFE ID")
+	}
+
+	if (mask == nil || mask.X509SvidTtl) && entry.X509SvidTtl < 0 {
+		return newValidationError("invalid registration entry: X509SvidTtl is not set")
+	}
+
+	if (mask == nil || mask.JwtSvidTtl) && entry.JwtSvidTtl < 0 {
+		return newValidationError("invalid registration entry: JwtSvidTtl is not set")
+	}
+
+	return nil
+}
+
+// equalSelectorTypes validates that all selectors has the same type
+func equalSelectorTypes(selectors []*common.Selector) bool {
+	for _, s := range selectors {
+		if s.Type != selectors[0].Type {
+			return false
+		}
+	}
+	return true
+}
+
+func createOrReturnEntryID(entry *common.RegistrationEntry) (string, error) {
+	if entry.EntryId != "" {
+		return entry.EntryId, nil
+	}
+
+	u, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// roundedInSecondsUnix rounds the time to the nearest second, and return the
+// time in seconds since the unix epoch, matching the precision of the SQL
+// datastore.
+func roundedInSecondsUnix(t time.Time) int64 {
+	return t.Round(time.Second).Unix()
+}
@@ -0,0 +1,145 @@
+package kvstore
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+func createEntryTemplate(tx *bbolt.Tx, template *common.EntryTemplate) (*common.EntryTemplate, error) {
+	templateID := template.TemplateId
+	if templateID == "" {
+		u, err := uuid.NewV4()
+		if err != nil {
+			return nil, newWrappedKVError(err)
+		}
+		templateID = u.String()
+	}
+
+	byTemplateID := tx.Bucket(entryTemplatesByTemplateIDBucket)
+	if _, ok := lookupRowID(byTemplateID, templateID); ok {
+		return nil, newAlreadyExistsError("entry template %q", templateID)
+	}
+
+	newTemplate := proto.Clone(template).(*common.EntryTemplate)
+	newTemplate.TemplateId = templateID
+	newTemplate.CreatedAt = roundedInSecondsUnix(time.Now())
+	data, err := proto.Marshal(newTemplate)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	rowID, err := putWithNewRowID(tx.Bucket(entryTemplatesBucket), data)
+	if err != nil {
+		return nil, err
+	}
+	if err := byTemplateID.Put(uniqueKey(templateID), itob(rowID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return newTemplate, nil
+}
+
+// fetchEntryTemplate returns the entry template, or nil if there is no such
+// entry template.
+func fetchEntryTemplate(tx *bbolt.Tx, templateID string) (*common.EntryTemplate, error) {
+	rowID, ok := lookupRowID(tx.Bucket(entryTemplatesByTemplateIDBucket), templateID)
+	if !ok {
+		return nil, nil
+	}
+	return loadEntryTemplate(tx, rowID)
+}
+
+func listEntryTemplates(tx *bbolt.Tx) ([]*common.EntryTemplate, error) {
+	templates := []*common.EntryTemplate{}
+	if err := scanRows(tx.Bucket(entryTemplatesBucket), 0, nil, func(_ uint64, v []byte) (bool, error) {
+		template, err := unmarshalEntryTemplate(v)
+		if err != nil {
+			return false, err
+		}
+		templates = append(templates, template)
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func deleteEntryTemplate(tx *bbolt.Tx, templateID string) (*common.EntryTemplate, error) {
+	byTemplateID := tx.Bucket(entryTemplatesByTemplateIDBucket)
+	rowID, ok := lookupRowID(byTemplateID, templateID)
+	if !ok {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	template, err := loadEntryTemplate(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Bucket(entryTemplatesBucket).Delete(itob(rowID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	if err := byTemplateID.Delete(uniqueKey(templateID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return template, nil
+}
+
+func loadEntryTemplate(tx *bbolt.Tx, rowID uint64) (*common.EntryTemplate, error) {
+	v := tx.Bucket(entryTemplatesBucket).Get(itob(rowID))
+	if v == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	return unmarshalEntryTemplate(v)
+}
+
+func unmarshalEntryTemplate(data []byte) (*common.EntryTemplate, error) {
+	template := new(common.EntryTemplate)
+	if err := proto.Unmarshal(data, template); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return template, nil
+}
+
+func validateEntryTemplate(template *common.EntryTemplate) error {
+	if template == nil {
+		return newValidationError("invalid request: missing entry template")
+	}
+
+	if len(template.Selectors) == 0 {
+		return newValidationError("invalid entry template: missing selector list")
+	}
+
+	if len(template.TemplateId) > entrytemplate.MaxTemplateIDLength {
+		return newValidationError("invalid entry template: template ID too long")
+	}
+
+	for _, e := range template.TemplateId {
+		if !unicode.In(e, validEntryIDChars) {
+			return newValidationError("invalid entry template: template ID contains invalid characters")
+		}
+	}
+
+	if template.ParentId == "" {
+		return newValidationError("invalid entry template: missing parent ID")
+	}
+
+	if template.SpiffeIdTemplate == "" {
+		return newValidationError("invalid entry template: missing SPIFFE ID template")
+	}
+
+	if template.X509SvidTtl < 0 {
+		return newValidationError("invalid entry template: X509SvidTtl is not set")
+	}
+
+	if template.JwtSvidTtl < 0 {
+		return newValidationError("invalid entry template: JwtSvidTtl is not set")
+	}
+
+	return nil
+}
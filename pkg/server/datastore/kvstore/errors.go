@@ -0,0 +1,112 @@
+package kvstore
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	datastoreKVErrorPrefix         = "datastore-kv"
+	datastoreValidationErrorPrefix = "datastore-validation"
+)
+
+var (
+	errNotFound      = errors.New("record not found")
+	errAlreadyExists = errors.New("record already exists")
+)
+
+type kvError struct {
+	err error
+	msg string
+}
+
+func (k *kvError) Error() string {
+	if k == nil {
+		return ""
+	}
+
+	if k.err != nil {
+		return fmt.Sprintf("%s: %s", datastoreKVErrorPrefix, k.err)
+	}
+
+	return fmt.Sprintf("%s: %s", datastoreKVErrorPrefix, k.msg)
+}
+
+func (k *kvError) Unwrap() error {
+	if k == nil {
+		return nil
+	}
+
+	return k.err
+}
+
+type validationError struct {
+	msg string
+}
+
+func (v *validationError) Error() string {
+	if v == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s: %s", datastoreValidationErrorPrefix, v.msg)
+}
+
+func newKVError(fmtMsg string, args ...any) error {
+	return &kvError{
+		msg: fmt.Sprintf(fmtMsg, args...),
+	}
+}
+
+func newWrappedKVError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &kvError{
+		err: err,
+	}
+}
+
+func newValidationError(fmtMsg string, args ...any) error {
+	return &validationError{
+		msg: fmt.Sprintf(fmtMsg, args...),
+	}
+}
+
+// kvToGRPCStatus converts the given error into a gRPC status error. Errors
+// that are already gRPC status errors are returned unmodified. Otherwise, the
+// code is derived from the wrapped error, defaulting to Unknown.
+func kvToGRPCStatus(err error) error {
+	type grpcStatusError interface {
+		error
+		GRPCStatus() *status.Status
+	}
+
+	var statusError grpcStatusError
+	if errors.As(err, &statusError) {
+		return statusError
+	}
+
+	code := codes.Unknown
+	var vErr *validationError
+	switch {
+	case errors.As(err, &vErr):
+		code = codes.InvalidArgument
+	case errors.Is(err, errNotFound):
+		code = codes.NotFound
+	case errors.Is(err, errAlreadyExists):
+		code = codes.AlreadyExists
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func newAlreadyExistsError(fmtMsg string, args ...any) error {
+	return &kvError{
+		err: fmt.Errorf("%s: %w", fmt.Sprintf(fmtMsg, args...), errAlreadyExists),
+	}
+}
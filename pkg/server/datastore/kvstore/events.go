@@ -0,0 +1,190 @@
+package kvstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.etcd.io/bbolt"
+)
+
+// eventRecord is the value stored for registration entry and attested node
+// events, keyed by event ID.
+type eventRecord struct {
+	// ID is the entry ID or the SPIFFE ID of the node the event is about.
+	ID string `json:"id"`
+	// CreatedAt is the creation time of the event, in Unix nanoseconds.
+	CreatedAt int64 `json:"created_at"`
+}
+
+func createRegistrationEntryEvent(tx *bbolt.Tx, event *datastore.RegistrationEntryEvent) error {
+	return createEvent(tx.Bucket(entryEventsBucket), event.EventID, event.EntryID)
+}
+
+func fetchRegistrationEntryEvent(tx *bbolt.Tx, eventID uint) (*datastore.RegistrationEntryEvent, error) {
+	record, err := fetchEvent(tx.Bucket(entryEventsBucket), eventID)
+	if err != nil {
+		return nil, err
+	}
+	return &datastore.RegistrationEntryEvent{
+		EventID: eventID,
+		EntryID: record.ID,
+	}, nil
+}
+
+func listRegistrationEntryEvents(tx *bbolt.Tx, req *datastore.ListRegistrationEntryEventsRequest) (*datastore.ListRegistrationEntryEventsResponse, error) {
+	resp := &datastore.ListRegistrationEntryEventsResponse{
+		Events: []datastore.RegistrationEntryEvent{},
+	}
+	if err := listEvents(tx.Bucket(entryEventsBucket), req.GreaterThanEventID, req.LessThanEventID, func(eventID uint, record *eventRecord) {
+		resp.Events = append(resp.Events, datastore.RegistrationEntryEvent{
+			EventID: eventID,
+			EntryID: record.ID,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func createAttestedNodeEvent(tx *bbolt.Tx, event *datastore.AttestedNodeEvent) error {
+	return createEvent(tx.Bucket(nodeEventsBucket), event.EventID, event.SpiffeID)
+}
+
+func fetchAttestedNodeEvent(tx *bbolt.Tx, eventID uint) (*datastore.AttestedNodeEvent, error) {
+	record, err := fetchEvent(tx.Bucket(nodeEventsBucket), eventID)
+	if err != nil {
+		return nil, err
+	}
+	return &datastore.AttestedNodeEvent{
+		EventID:  eventID,
+		SpiffeID: record.ID,
+	}, nil
+}
+
+func listAttestedNodeEvents(tx *bbolt.Tx, req *datastore.ListAttestedNodeEventsRequest) (*datastore.ListAttestedNodeEventsResponse, error) {
+	resp := &datastore.ListAttestedNodeEventsResponse{
+		Events: []datastore.AttestedNodeEvent{},
+	}
+	if err := listEvents(tx.Bucket(nodeEventsBucket), req.GreaterThanEventID, req.LessThanEventID, func(eventID uint, record *eventRecord) {
+		resp.Events = append(resp.Events, datastore.AttestedNodeEvent{
+			EventID:  eventID,
+			SpiffeID: record.ID,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// createEvent stores an event. Event IDs are allocated from the bucket
+// sequence unless one is provided, in which case the sequence is moved
+// forward so that later events are still created in order.
+func createEvent(b *bbolt.Bucket, eventID uint, id string) error {
+	data, err := json.Marshal(&eventRecord{
+		ID:        id,
+		CreatedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return newWrappedKVError(err)
+	}
+
+	if eventID == 0 {
+		_, err := putWithNewRowID(b, data)
+		return err
+	}
+
+	key := itob(uint64(eventID))
+	if b.Get(key) != nil {
+		return newAlreadyExistsError("event %d", eventID)
+	}
+	if uint64(eventID) > b.Sequence() {
+		if err := b.SetSequence(uint64(eventID)); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+	if err := b.Put(key, data); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func fetchEvent(b *bbolt.Bucket, eventID uint) (*eventRecord, error) {
+	v := b.Get(itob(uint64(eventID)))
+	if v == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	return unmarshalEvent(v)
+}
+
+func deleteEvent(b *bbolt.Bucket, eventID uint) error {
+	if err := b.Delete(itob(uint64(eventID))); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+// checkEventRange checks that at most one bound is set when listing events.
+func checkEventRange(greaterThanEventID, lessThanEventID uint) error {
+	if greaterThanEventID != 0 && lessThanEventID != 0 {
+		return newWrappedKVError(errors.New("can't set both greater and less than event id"))
+	}
+	return nil
+}
+
+// listEvents visits the events in event ID order, optionally only those
+// after or before the given event ID.
+func listEvents(b *bbolt.Bucket, greaterThanEventID, lessThanEventID uint, fn func(eventID uint, record *eventRecord)) error {
+	if err := checkEventRange(greaterThanEventID, lessThanEventID); err != nil {
+		return err
+	}
+
+	c := b.Cursor()
+	for k, v := c.Seek(itob(uint64(greaterThanEventID) + 1)); k != nil; k, v = c.Next() {
+		eventID := btoi(k)
+		if lessThanEventID != 0 && eventID >= uint64(lessThanEventID) {
+			break
+		}
+		record, err := unmarshalEvent(v)
+		if err != nil {
+			return err
+		}
+		fn(util.MustCast[uint](eventID), record)
+	}
+	return nil
+}
+
+// pruneEvents deletes the events created before the given duration.
+func pruneEvents(b *bbolt.Bucket, olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan).UnixNano()
+
+	var expired [][]byte
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		record, err := unmarshalEvent(v)
+		if err != nil {
+			return err
+		}
+		if record.CreatedAt < cutoff {
+			expired = append(expired, bytes.Clone(k))
+		}
+	}
+
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+	return nil
+}
+
+func unmarshalEvent(data []byte) (*eventRecord, error) {
+	record := new(eventRecord)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return record, nil
+}
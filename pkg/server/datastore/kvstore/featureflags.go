@@ -0,0 +1,133 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.etcd.io/bbolt"
+)
+
+// featureFlagStatusRecord is the value stored for a feature flag status,
+// keyed by the kind and ID of the reporter.
+type featureFlagStatusRecord struct {
+	Enabled    []string `json:"enabled"`
+	ReportedAt int64    `json:"reported_at"`
+}
+
+func listFeatureFlagStatuses(tx *bbolt.Tx) ([]*datastore.FeatureFlagStatus, error) {
+	statuses := []*datastore.FeatureFlagStatus{}
+	c := tx.Bucket(featureFlagStatusesBucket).Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		status, err := unmarshalFeatureFlagStatus(k, v)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func pruneServerFeatureFlagStatuses(tx *bbolt.Tx, reportedBefore time.Time) error {
+	var keys [][]byte
+	c := tx.Bucket(featureFlagStatusesBucket).Cursor()
+	prefix := []byte{featureFlagServerReporter}
+	for k, v := c.Seek(prefix); k != nil && k[0] == featureFlagServerReporter; k, v = c.Next() {
+		status, err := unmarshalFeatureFlagStatus(k, v)
+		if err != nil {
+			return err
+		}
+		if status.ReportedAt < reportedBefore.Unix() {
+			// Keys must not be deleted while iterating with the cursor
+			keys = append(keys, k)
+		}
+	}
+
+	b := tx.Bucket(featureFlagStatusesBucket)
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+	return nil
+}
+
+func setFeatureFlagStatus(tx *bbolt.Tx, status *datastore.FeatureFlagStatus) error {
+	if !status.IsServer {
+		// Statuses are only kept for attested agents, since they are deleted
+		// along with the attested node.
+		if _, ok := lookupRowID(tx.Bucket(nodesBySpiffeIDBucket), status.ReporterID); !ok {
+			return newWrappedKVError(errNotFound)
+		}
+	}
+
+	data, err := json.Marshal(&featureFlagStatusRecord{
+		Enabled:    status.Enabled,
+		ReportedAt: status.ReportedAt,
+	})
+	if err != nil {
+		return newWrappedKVError(err)
+	}
+	if err := tx.Bucket(featureFlagStatusesBucket).Put(featureFlagStatusKey(status.IsServer, status.ReporterID), data); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func deleteAgentFeatureFlagStatus(tx *bbolt.Tx, spiffeID string) error {
+	if err := tx.Bucket(featureFlagStatusesBucket).Delete(featureFlagStatusKey(false, spiffeID)); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func validateFeatureFlagStatus(status *datastore.FeatureFlagStatus) error {
+	if status == nil {
+		return newValidationError("invalid request: missing feature flag status")
+	}
+
+	if status.ReporterID == "" {
+		return newValidationError("invalid feature flag status: missing reporter ID")
+	}
+
+	for _, flag := range status.Enabled {
+		if flag == "" || strings.Contains(flag, ",") {
+			return newValidationError("invalid feature flag status: invalid feature flag name %q", flag)
+		}
+	}
+
+	return nil
+}
+
+const (
+	featureFlagAgentReporter  byte = 'a'
+	featureFlagServerReporter byte = 's'
+)
+
+// featureFlagStatusKey returns the key of the feature flag status of the
+// given reporter. Server statuses sort after the agent statuses.
+func featureFlagStatusKey(isServer bool, reporterID string) []byte {
+	kind := featureFlagAgentReporter
+	if isServer {
+		kind = featureFlagServerReporter
+	}
+	return append([]byte{kind}, reporterID...)
+}
+
+func unmarshalFeatureFlagStatus(k, v []byte) (*datastore.FeatureFlagStatus, error) {
+	record := new(featureFlagStatusRecord)
+	if err := json.Unmarshal(v, record); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	enabled := record.Enabled
+	if enabled == nil {
+		enabled = []string{}
+	}
+	return &datastore.FeatureFlagStatus{
+		ReporterID: string(k[1:]),
+		IsServer:   k[0] == featureFlagServerReporter,
+		Enabled:    enabled,
+		ReportedAt: record.ReportedAt,
+	}, nil
+}
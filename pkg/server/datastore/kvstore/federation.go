@@ -0,0 +1,252 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// federationRecord is the value stored for a federation relationship. The
+// trust domain bundle is stored with the rest of the bundles.
+type federationRecord struct {
+	TrustDomain           string `json:"trust_domain"`
+	BundleEndpointURL     string `json:"bundle_endpoint_url"`
+	BundleEndpointProfile string `json:"bundle_endpoint_profile"`
+	EndpointSPIFFEID      string `json:"endpoint_spiffe_id,omitempty"`
+}
+
+func createFederationRelationship(tx *bbolt.Tx, fr *datastore.FederationRelationship) (*datastore.FederationRelationship, error) {
+	byTD := tx.Bucket(federationByTrustDomainBucket)
+	if _, ok := lookupRowID(byTD, fr.TrustDomain.Name()); ok {
+		return nil, newAlreadyExistsError("federation relationship with %q", fr.TrustDomain.Name())
+	}
+
+	record := &federationRecord{
+		TrustDomain:           fr.TrustDomain.Name(),
+		BundleEndpointURL:     fr.BundleEndpointURL.String(),
+		BundleEndpointProfile: string(fr.BundleEndpointProfile),
+	}
+
+	if fr.BundleEndpointProfile == datastore.BundleEndpointSPIFFE {
+		record.EndpointSPIFFEID = fr.EndpointSPIFFEID.String()
+	}
+
+	if fr.TrustDomainBundle != nil {
+		// overwrite current bundle
+		if _, err := setBundle(tx, fr.TrustDomainBundle); err != nil {
+			return nil, fmt.Errorf("unable to set bundle: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	rowID, err := putWithNewRowID(tx.Bucket(federationRelationshipsBucket), data)
+	if err != nil {
+		return nil, err
+	}
+	if err := byTD.Put(uniqueKey(record.TrustDomain), itob(rowID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return fr, nil
+}
+
+func deleteFederationRelationship(tx *bbolt.Tx, trustDomain spiffeid.TrustDomain) error {
+	byTD := tx.Bucket(federationByTrustDomainBucket)
+	rowID, ok := lookupRowID(byTD, trustDomain.Name())
+	if !ok {
+		return newWrappedKVError(errNotFound)
+	}
+
+	if err := tx.Bucket(federationRelationshipsBucket).Delete(itob(rowID)); err != nil {
+		return newWrappedKVError(err)
+	}
+	if err := byTD.Delete(uniqueKey(trustDomain.Name())); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+// fetchFederationRelationship returns the federation relationship with the
+// trust domain, or nil if there is no such relationship.
+func fetchFederationRelationship(tx *bbolt.Tx, trustDomain spiffeid.TrustDomain) (*datastore.FederationRelationship, error) {
+	rowID, ok := lookupRowID(tx.Bucket(federationByTrustDomainBucket), trustDomain.Name())
+	if !ok {
+		return nil, nil
+	}
+
+	record, err := loadFederationRecord(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+	return recordToFederationRelationship(tx, record)
+}
+
+func listFederationRelationships(tx *bbolt.Tx, req *datastore.ListFederationRelationshipsRequest) (*datastore.ListFederationRelationshipsResponse, error) {
+	p := req.Pagination
+	afterID, err := paginationStart(p)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.ListFederationRelationshipsResponse{
+		FederationRelationships: []*datastore.FederationRelationship{},
+	}
+	var lastID uint64
+	if err := scanRows(tx.Bucket(federationRelationshipsBucket), afterID, nil, func(rowID uint64, v []byte) (bool, error) {
+		record, err := unmarshalFederationRecord(v)
+		if err != nil {
+			return false, err
+		}
+		fr, err := recordToFederationRelationship(tx, record)
+		if err != nil {
+			return false, err
+		}
+		resp.FederationRelationships = append(resp.FederationRelationships, fr)
+		lastID = rowID
+		return !pageFull(p, len(resp.FederationRelationships)), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if p != nil {
+		p.Token = ""
+		if len(resp.FederationRelationships) > 0 {
+			p.Token = fmt.Sprint(lastID)
+		}
+	}
+	resp.Pagination = p
+
+	return resp, nil
+}
+
+func updateFederationRelationship(tx *bbolt.Tx, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (*datastore.FederationRelationship, error) {
+	rowID, ok := lookupRowID(tx.Bucket(federationByTrustDomainBucket), fr.TrustDomain.Name())
+	if !ok {
+		return nil, fmt.Errorf("unable to fetch federation relationship: %w", errNotFound)
+	}
+	record, err := loadFederationRecord(tx, rowID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch federation relationship: %w", err)
+	}
+
+	if mask.BundleEndpointUrl {
+		record.BundleEndpointURL = fr.BundleEndpointURL.String()
+	}
+
+	if mask.BundleEndpointProfile {
+		record.BundleEndpointProfile = string(fr.BundleEndpointProfile)
+
+		if fr.BundleEndpointProfile == datastore.BundleEndpointSPIFFE {
+			record.EndpointSPIFFEID = fr.EndpointSPIFFEID.String()
+		}
+	}
+
+	if mask.TrustDomainBundle && fr.TrustDomainBundle != nil {
+		// overwrite current bundle
+		if _, err := setBundle(tx, fr.TrustDomainBundle); err != nil {
+			return nil, fmt.Errorf("unable to set bundle: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	if err := tx.Bucket(federationRelationshipsBucket).Put(itob(rowID), data); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return recordToFederationRelationship(tx, record)
+}
+
+func validateFederationRelationship(fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) error {
+	if fr == nil {
+		return status.Error(codes.InvalidArgument, "federation relationship is nil")
+	}
+
+	if fr.TrustDomain.IsZero() {
+		return status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	if mask.BundleEndpointUrl && fr.BundleEndpointURL == nil {
+		return status.Error(codes.InvalidArgument, "bundle endpoint URL is required")
+	}
+
+	if mask.BundleEndpointProfile {
+		switch fr.BundleEndpointProfile {
+		case datastore.BundleEndpointWeb:
+		case datastore.BundleEndpointSPIFFE:
+			if fr.EndpointSPIFFEID.IsZero() {
+				return status.Error(codes.InvalidArgument, "bundle endpoint SPIFFE ID is required")
+			}
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown bundle endpoint profile type: %q", fr.BundleEndpointProfile)
+		}
+	}
+
+	return nil
+}
+
+func recordToFederationRelationship(tx *bbolt.Tx, record *federationRecord) (*datastore.FederationRelationship, error) {
+	bundleEndpointURL, err := url.Parse(record.BundleEndpointURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse URL: %w", err)
+	}
+
+	td, err := spiffeid.TrustDomainFromString(record.TrustDomain)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	fr := &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointURL:     bundleEndpointURL,
+		BundleEndpointProfile: datastore.BundleEndpointType(record.BundleEndpointProfile),
+	}
+
+	switch fr.BundleEndpointProfile {
+	case datastore.BundleEndpointWeb:
+	case datastore.BundleEndpointSPIFFE:
+		endpointSPIFFEID, err := spiffeid.FromString(record.EndpointSPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse bundle endpoint SPIFFE ID: %w", err)
+		}
+		fr.EndpointSPIFFEID = endpointSPIFFEID
+	default:
+		return nil, fmt.Errorf("unknown bundle endpoint profile type: %q", record.BundleEndpointProfile)
+	}
+
+	trustDomainBundle, err := fetchBundle(tx, td.IDString())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch bundle: %w", err)
+	}
+	fr.TrustDomainBundle = trustDomainBundle
+
+	return fr, nil
+}
+
+func loadFederationRecord(tx *bbolt.Tx, rowID uint64) (*federationRecord, error) {
+	v := tx.Bucket(federationRelationshipsBucket).Get(itob(rowID))
+	if v == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	return unmarshalFederationRecord(v)
+}
+
+func unmarshalFederationRecord(data []byte) (*federationRecord, error) {
+	record := new(federationRecord)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return record, nil
+}
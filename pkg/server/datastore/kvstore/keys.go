@@ -0,0 +1,264 @@
+package kvstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"strconv"
+
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Buckets holding the primary records are keyed by row ID. Row IDs are
+// allocated from the bucket sequence, so iterating a bucket yields records in
+// insertion order, which is the order the SQL datastore lists them in. The
+// row ID of the last record in a page is used as the pagination token.
+var (
+	metaBucket                       = []byte("meta")
+	bundlesBucket                    = []byte("bundles")
+	bundlesByTrustDomainBucket       = []byte("bundles_by_trust_domain")
+	entriesBucket                    = []byte("entries")
+	entriesByEntryIDBucket           = []byte("entries_by_entry_id")
+	entriesByParentIDBucket          = []byte("entries_by_parent_id")
+	entriesBySpiffeIDBucket          = []byte("entries_by_spiffe_id")
+	entriesBySelectorBucket          = []byte("entries_by_selector")
+	entriesByFederatesWithBucket     = []byte("entries_by_federates_with")
+	entriesByHintBucket              = []byte("entries_by_hint")
+	entriesByExpiryBucket            = []byte("entries_by_expiry")
+	entryEventsBucket                = []byte("entry_events")
+	nodesBucket                      = []byte("nodes")
+	nodesBySpiffeIDBucket            = []byte("nodes_by_spiffe_id")
+	nodesByExpiryBucket              = []byte("nodes_by_expiry")
+	nodeSelectorsBucket              = []byte("node_selectors")
+	nodeSelectorsByValueBucket       = []byte("node_selectors_by_value")
+	nodeEventsBucket                 = []byte("node_events")
+	joinTokensBucket                 = []byte("join_tokens")
+	federationRelationshipsBucket    = []byte("federation_relationships")
+	federationByTrustDomainBucket    = []byte("federation_relationships_by_trust_domain")
+	caJournalsBucket                 = []byte("ca_journals")
+	entryTemplatesBucket             = []byte("entry_templates")
+	entryTemplatesByTemplateIDBucket = []byte("entry_templates_by_template_id")
+	featureFlagStatusesBucket        = []byte("feature_flag_statuses")
+
+	allBuckets = [][]byte{
+		metaBucket,
+		bundlesBucket,
+		bundlesByTrustDomainBucket,
+		entriesBucket,
+		entriesByEntryIDBucket,
+		entriesByParentIDBucket,
+		entriesBySpiffeIDBucket,
+		entriesBySelectorBucket,
+		entriesByFederatesWithBucket,
+		entriesByHintBucket,
+		entriesByExpiryBucket,
+		entryEventsBucket,
+		nodesBucket,
+		nodesBySpiffeIDBucket,
+		nodesByExpiryBucket,
+		nodeSelectorsBucket,
+		nodeSelectorsByValueBucket,
+		nodeEventsBucket,
+		joinTokensBucket,
+		federationRelationshipsBucket,
+		federationByTrustDomainBucket,
+		caJournalsBucket,
+		entryTemplatesBucket,
+		entryTemplatesByTemplateIDBucket,
+		featureFlagStatusesBucket,
+	}
+
+	schemaVersionKey = []byte("schema_version")
+)
+
+// rowSet is a set of row IDs gathered from one or more secondary indexes.
+type rowSet map[uint64]struct{}
+
+func (s rowSet) intersect(other rowSet) rowSet {
+	out := make(rowSet)
+	for id := range s {
+		if _, ok := other[id]; ok {
+			out[id] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (s rowSet) sorted() []uint64 {
+	ids := make([]uint64, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// combineRowSets returns the union or the intersection of the given sets.
+func combineRowSets(sets []rowSet, union bool) rowSet {
+	if len(sets) == 0 {
+		return rowSet{}
+	}
+	out := sets[0]
+	for _, set := range sets[1:] {
+		if union {
+			for id := range set {
+				out[id] = struct{}{}
+			}
+		} else {
+			out = out.intersect(set)
+		}
+	}
+	return out
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
+
+// sortableInt64 encodes a signed value such that the byte order of the
+// encoded values matches their numeric order.
+func sortableInt64(v int64) []byte {
+	return itob(uint64(v) ^ (1 << 63)) //nolint: gosec // intentional reinterpretation of the sign bit
+}
+
+func fromSortableInt64(b []byte) int64 {
+	return int64(btoi(b) ^ (1 << 63)) //nolint: gosec // intentional reinterpretation of the sign bit
+}
+
+// indexPrefix builds the prefix shared by all index keys for the given field
+// values. Each value is length-prefixed so that arbitrary values (including
+// ones that are prefixes of other values) cannot collide.
+func indexPrefix(fields ...string) []byte {
+	var buf bytes.Buffer
+	for _, field := range fields {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(field))) //nolint: gosec // field lengths are bounded by bbolt key limits
+		buf.Write(l[:])
+		buf.WriteString(field)
+	}
+	return buf.Bytes()
+}
+
+// indexKey builds an index key pointing at the given row.
+func indexKey(rowID uint64, fields ...string) []byte {
+	return append(indexPrefix(fields...), itob(rowID)...)
+}
+
+// scanIndex returns the IDs of the rows referenced by index keys with the
+// given prefix.
+func scanIndex(b *bbolt.Bucket, prefix []byte) rowSet {
+	rows := make(rowSet)
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		rows[btoi(k[len(k)-8:])] = struct{}{}
+	}
+	return rows
+}
+
+// scanRows visits the records in the given bucket, in row ID order, with a
+// row ID greater than afterID. If candidates is not nil, only those rows are
+// visited. Iteration stops when the callback returns false.
+func scanRows(b *bbolt.Bucket, afterID uint64, candidates rowSet, fn func(rowID uint64, v []byte) (bool, error)) error {
+	if candidates != nil {
+		for _, rowID := range candidates.sorted() {
+			if rowID <= afterID {
+				continue
+			}
+			v := b.Get(itob(rowID))
+			if v == nil {
+				continue
+			}
+			more, err := fn(rowID, v)
+			if err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.Seek(itob(afterID + 1)); k != nil; k, v = c.Next() {
+		more, err := fn(btoi(k), v)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// paginationStart validates the pagination request and returns the row ID
+// after which the page starts.
+func paginationStart(p *datastore.Pagination) (uint64, error) {
+	if p == nil {
+		return 0, nil
+	}
+	if p.PageSize == 0 {
+		return 0, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+	}
+	if len(p.Token) == 0 {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(p.Token, 10, 32)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "could not parse token '%v'", p.Token)
+	}
+	return id, nil
+}
+
+// pageFull returns true if the page has reached the requested size.
+func pageFull(p *datastore.Pagination, n int) bool {
+	return p != nil && n >= int(p.PageSize)
+}
+
+// nextPagination returns the pagination for the response, which carries the
+// row ID of the last record in the page as the token.
+func nextPagination(p *datastore.Pagination, n int, lastID uint64) *datastore.Pagination {
+	if p == nil {
+		return nil
+	}
+	next := &datastore.Pagination{
+		PageSize: p.PageSize,
+	}
+	if n > 0 {
+		next.Token = strconv.FormatUint(lastID, 10)
+	}
+	return next
+}
+
+// putWithNewRowID stores the value in the bucket under a newly allocated row
+// ID.
+func putWithNewRowID(b *bbolt.Bucket, v []byte) (uint64, error) {
+	rowID, err := b.NextSequence()
+	if err != nil {
+		return 0, newWrappedKVError(err)
+	}
+	if err := b.Put(itob(rowID), v); err != nil {
+		return 0, newWrappedKVError(err)
+	}
+	return rowID, nil
+}
+
+// uniqueKey returns the key for the given value in a unique index bucket.
+// The value is length-prefixed since bbolt does not allow empty keys.
+func uniqueKey(value string) []byte {
+	return indexPrefix(value)
+}
+
+// lookupRowID returns the row ID stored under the given value of a unique
+// index bucket, or false if there is none.
+func lookupRowID(b *bbolt.Bucket, value string) (uint64, bool) {
+	v := b.Get(uniqueKey(value))
+	if v == nil {
+		return 0, false
+	}
+	return btoi(v), true
+}
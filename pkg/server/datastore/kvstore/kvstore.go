@@ -0,0 +1,1021 @@
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/pkg/common/protoutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/journal"
+	"github.com/spiffe/spire/proto/spire/common"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	PluginName = "kv"
+
+	// schemaVersion is the version of the on-disk layout. It is bumped every
+	// time the layout changes in a way older servers cannot read.
+	schemaVersion = 1
+
+	// openTimeout is how long to wait for the file lock on the database,
+	// which is held by any other process that has the database open.
+	openTimeout = time.Second
+)
+
+// Configuration for the kv datastore implementation.
+type configuration struct {
+	DatabasePath string `hcl:"database_path" json:"database_path"`
+}
+
+func (c *configuration) Validate() error {
+	if c.DatabasePath == "" {
+		return newKVError("database_path must be set")
+	}
+	return nil
+}
+
+// Plugin is a DataStore plugin implemented via an embedded key-value store
+type Plugin struct {
+	mu   sync.RWMutex
+	db   *bbolt.DB
+	path string
+	log  logrus.FieldLogger
+}
+
+// New creates a new kv plugin struct. Configure must be called
+// in order to open the database.
+func New(log logrus.FieldLogger) *Plugin {
+	return &Plugin{
+		log: log,
+	}
+}
+
+// CreateBundle stores the given bundle
+func (ds *Plugin) CreateBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		bundle, err = createBundle(tx, b)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// UpdateBundle updates an existing bundle with the given CAs. Overwrites any
+// existing certificates.
+func (ds *Plugin) UpdateBundle(ctx context.Context, b *common.Bundle, mask *common.BundleMask) (bundle *common.Bundle, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		bundle, err = updateBundle(tx, b, mask)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// SetBundle sets bundle contents. If no bundle exists for the trust domain, it is created.
+func (ds *Plugin) SetBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		bundle, err = setBundle(tx, b)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// AppendBundle append bundle contents to the existing bundle (by trust domain). If no existing one is present, create it.
+func (ds *Plugin) AppendBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		bundle, err = appendBundle(tx, b)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// DeleteBundle deletes the bundle with the matching TrustDomain. Any CACert data passed is ignored.
+func (ds *Plugin) DeleteBundle(ctx context.Context, trustDomainID string, mode datastore.DeleteMode) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return deleteBundle(tx, trustDomainID, mode)
+	})
+}
+
+// FetchBundle returns the bundle matching the specified Trust Domain.
+func (ds *Plugin) FetchBundle(ctx context.Context, trustDomainID string) (resp *common.Bundle, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = fetchBundle(tx, trustDomainID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CountBundles can be used to count all existing bundles.
+func (ds *Plugin) CountBundles(ctx context.Context) (count int32, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		count, err = countBundles(tx)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListBundles can be used to fetch all existing bundles.
+func (ds *Plugin) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (resp *datastore.ListBundlesResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listBundles(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PruneBundle removes expired certs and keys from a bundle
+func (ds *Plugin) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (changed bool, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		changed, err = pruneBundle(tx, trustDomainID, expiresBefore, ds.log)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// TaintX509CA taints an X.509 CA signed using the provided public key
+func (ds *Plugin) TaintX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToTaint string) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return taintX509CA(tx, trustDomainID, subjectKeyIDToTaint)
+	})
+}
+
+// RevokeX509CA removes a Root CA from the bundle
+func (ds *Plugin) RevokeX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToRevoke string) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return revokeX509CA(tx, trustDomainID, subjectKeyIDToRevoke)
+	})
+}
+
+// TaintJWTKey taints a JWT Authority key
+func (ds *Plugin) TaintJWTKey(ctx context.Context, trustDomainID string, authorityID string) (taintedKey *common.PublicKey, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		taintedKey, err = taintJWTKey(tx, trustDomainID, authorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return taintedKey, nil
+}
+
+// TaintWITKey taints a WIT Authority key
+func (ds *Plugin) TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (taintedKey *common.PublicKey, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		taintedKey, err = taintWITKey(tx, trustDomainID, authorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return taintedKey, nil
+}
+
+// RevokeJWTKey removes JWT key from the bundle
+func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (revokedKey *common.PublicKey, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		revokedKey, err = revokeJWTKey(tx, trustDomainID, authorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return revokedKey, nil
+}
+
+// RevokeWITKey removes WIT key from the bundle
+func (ds *Plugin) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (revokedKey *common.PublicKey, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		revokedKey, err = revokeWITKey(tx, trustDomainID, authorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return revokedKey, nil
+}
+
+// CreateAttestedNode stores the given attested node
+func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (attestedNode *common.AttestedNode, err error) {
+	if node == nil {
+		return nil, newKVError("invalid request: missing attested node")
+	}
+
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		attestedNode, err = createAttestedNode(tx, node)
+		if err != nil {
+			return err
+		}
+		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: node.SpiffeId,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return attestedNode, nil
+}
+
+// FetchAttestedNode fetches an existing attested node by SPIFFE ID
+func (ds *Plugin) FetchAttestedNode(ctx context.Context, spiffeID string) (attestedNode *common.AttestedNode, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		attestedNode, err = fetchAttestedNode(tx, spiffeID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return attestedNode, nil
+}
+
+// CountAttestedNodes counts all attested nodes
+func (ds *Plugin) CountAttestedNodes(ctx context.Context, req *datastore.CountAttestedNodesRequest) (count int32, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		count, err = countAttestedNodes(tx, req)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListAttestedNodes lists all attested nodes (pagination available)
+func (ds *Plugin) ListAttestedNodes(ctx context.Context, req *datastore.ListAttestedNodesRequest) (resp *datastore.ListAttestedNodesResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listAttestedNodes(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateAttestedNode updates the given node's cert serial and expiration.
+func (ds *Plugin) UpdateAttestedNode(ctx context.Context, n *common.AttestedNode, mask *common.AttestedNodeMask) (node *common.AttestedNode, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		node, err = updateAttestedNode(tx, n, mask)
+		if err != nil {
+			return err
+		}
+		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: n.SpiffeId,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// DeleteAttestedNode deletes the given attested node and the associated node selectors.
+func (ds *Plugin) DeleteAttestedNode(ctx context.Context, spiffeID string) (attestedNode *common.AttestedNode, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		attestedNode, err = deleteAttestedNodeAndSelectors(tx, spiffeID)
+		if err != nil {
+			return err
+		}
+		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: spiffeID,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return attestedNode, nil
+}
+
+// PruneAttestedExpiredNodes deletes attested nodes with expiration time further than a given duration in the past.
+// Non-reattestable nodes are not deleted by default, and have to be included explicitly by setting
+// includeNonReattestable = true. Banned nodes are not deleted.
+func (ds *Plugin) PruneAttestedExpiredNodes(ctx context.Context, expiredBefore time.Time, includeNonReattestable bool) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return pruneAttestedExpiredNodes(tx, expiredBefore, includeNonReattestable, ds.log)
+	})
+}
+
+// ListAttestedNodeEvents lists all attested node events
+func (ds *Plugin) ListAttestedNodeEvents(ctx context.Context, req *datastore.ListAttestedNodeEventsRequest) (resp *datastore.ListAttestedNodeEventsResponse, err error) {
+	if err := checkEventRange(req.GreaterThanEventID, req.LessThanEventID); err != nil {
+		return nil, err
+	}
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listAttestedNodeEvents(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PruneAttestedNodeEvents deletes all attested node events older than a specified duration (i.e. more than 24 hours old)
+func (ds *Plugin) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return pruneEvents(tx.Bucket(nodeEventsBucket), olderThan)
+	})
+}
+
+// CreateAttestedNodeEventForTesting creates an attested node event. Used for unit testing.
+func (ds *Plugin) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return createAttestedNodeEvent(tx, event)
+	})
+}
+
+// DeleteAttestedNodeEventForTesting deletes an attested node event by event ID. Used for unit testing.
+func (ds *Plugin) DeleteAttestedNodeEventForTesting(ctx context.Context, eventID uint) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return deleteEvent(tx.Bucket(nodeEventsBucket), eventID)
+	})
+}
+
+// FetchAttestedNodeEvent fetches an existing attested node event by event ID
+func (ds *Plugin) FetchAttestedNodeEvent(ctx context.Context, eventID uint) (event *datastore.AttestedNodeEvent, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		event, err = fetchAttestedNodeEvent(tx, eventID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// SetNodeSelectors sets node (agent) selectors by SPIFFE ID, deleting old selectors first
+func (ds *Plugin) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		if err := setNodeSelectors(tx, spiffeID, selectors); err != nil {
+			return err
+		}
+		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: spiffeID,
+		})
+	})
+}
+
+// GetNodeSelectors gets node (agent) selectors by SPIFFE ID. There are no
+// read replicas, so the data consistency is always current.
+func (ds *Plugin) GetNodeSelectors(ctx context.Context, spiffeID string, _ datastore.DataConsistency) (selectors []*common.Selector, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		selectors, err = getNodeSelectors(tx, spiffeID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return selectors, nil
+}
+
+// ListNodeSelectors gets node (agent) selectors by SPIFFE ID
+func (ds *Plugin) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (resp *datastore.ListNodeSelectorsResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listNodeSelectors(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateEntryTemplate stores the given entry template. If the entry template
+// has no ID, one is generated.
+func (ds *Plugin) CreateEntryTemplate(ctx context.Context, template *common.EntryTemplate) (entryTemplate *common.EntryTemplate, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		if err = validateEntryTemplate(template); err != nil {
+			return err
+		}
+		entryTemplate, err = createEntryTemplate(tx, template)
+		if err != nil {
+			return err
+		}
+
+		// Entry templates are cached as the entries they are sent to the
+		// agents as, which are updated through the registration entry events.
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entrytemplate.EntryID(entryTemplate.TemplateId),
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplate, nil
+}
+
+// FetchEntryTemplate fetches the entry template with the given ID. If the
+// entry template is not found, nil is returned.
+func (ds *Plugin) FetchEntryTemplate(ctx context.Context, templateID string) (entryTemplate *common.EntryTemplate, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		entryTemplate, err = fetchEntryTemplate(tx, templateID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplate, nil
+}
+
+// ListEntryTemplates returns all the entry templates
+func (ds *Plugin) ListEntryTemplates(ctx context.Context) (entryTemplates []*common.EntryTemplate, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		entryTemplates, err = listEntryTemplates(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplates, nil
+}
+
+// DeleteEntryTemplate deletes the entry template with the given ID
+func (ds *Plugin) DeleteEntryTemplate(ctx context.Context, templateID string) (entryTemplate *common.EntryTemplate, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		entryTemplate, err = deleteEntryTemplate(tx, templateID)
+		if err != nil {
+			return err
+		}
+
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entrytemplate.EntryID(entryTemplate.TemplateId),
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplate, nil
+}
+
+// CreateRegistrationEntry stores the given registration entry
+func (ds *Plugin) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
+	out, _, err := ds.createOrReturnRegistrationEntry(ctx, entry)
+	return out, err
+}
+
+// CreateOrReturnRegistrationEntry stores the given registration entry. If an
+// entry already exists with the same (parentID, spiffeID, selector) tuple,
+// that entry is returned instead.
+func (ds *Plugin) CreateOrReturnRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, bool, error) {
+	return ds.createOrReturnRegistrationEntry(ctx, entry)
+}
+
+func (ds *Plugin) createOrReturnRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (registrationEntry *common.RegistrationEntry, existing bool, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		if err = validateRegistrationEntry(entry); err != nil {
+			return err
+		}
+
+		registrationEntry, err = lookupSimilarEntry(tx, entry)
+		if err != nil {
+			return err
+		}
+		if registrationEntry != nil {
+			existing = true
+			return nil
+		}
+		registrationEntry, err = createRegistrationEntry(tx, entry)
+		if err != nil {
+			return err
+		}
+
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: registrationEntry.EntryId,
+		})
+	}); err != nil {
+		return nil, false, err
+	}
+	return registrationEntry, existing, nil
+}
+
+// FetchRegistrationEntry fetches an existing registration by entry ID
+func (ds *Plugin) FetchRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
+	entries, err := ds.FetchRegistrationEntries(ctx, []string{entryID})
+	if err != nil {
+		return nil, err
+	}
+	return entries[entryID], nil
+}
+
+// FetchRegistrationEntries fetches existing registrations by entry IDs
+func (ds *Plugin) FetchRegistrationEntries(ctx context.Context, entryIDs []string) (entries map[string]*common.RegistrationEntry, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		entries, err = fetchRegistrationEntries(tx, entryIDs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CountRegistrationEntries counts all registrations (pagination available)
+func (ds *Plugin) CountRegistrationEntries(ctx context.Context, req *datastore.CountRegistrationEntriesRequest) (count int32, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		count, err = countRegistrationEntries(tx, req)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListRegistrationEntries lists all registrations (pagination available)
+func (ds *Plugin) ListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (resp *datastore.ListRegistrationEntriesResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listRegistrationEntries(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateRegistrationEntry updates an existing registration entry
+func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context, e *common.RegistrationEntry, mask *common.RegistrationEntryMask) (entry *common.RegistrationEntry, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		entry, err = updateRegistrationEntry(tx, e, mask)
+		if err != nil {
+			return err
+		}
+
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entry.EntryId,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// DeleteRegistrationEntry deletes the given registration
+func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context, entryID string) (registrationEntry *common.RegistrationEntry, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		registrationEntry, err = deleteRegistrationEntry(tx, entryID)
+		if err != nil {
+			return err
+		}
+
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entryID,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return registrationEntry, nil
+}
+
+// PruneRegistrationEntries takes a registration entry message, and deletes all entries which have expired
+// before the date in the message
+func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return pruneRegistrationEntries(tx, expiresBefore, ds.log)
+	})
+}
+
+// ListRegistrationEntryEvents lists all registration entry events
+func (ds *Plugin) ListRegistrationEntryEvents(ctx context.Context, req *datastore.ListRegistrationEntryEventsRequest) (resp *datastore.ListRegistrationEntryEventsResponse, err error) {
+	if err := checkEventRange(req.GreaterThanEventID, req.LessThanEventID); err != nil {
+		return nil, err
+	}
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listRegistrationEntryEvents(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PruneRegistrationEntryEvents deletes all registration entry events older than a specified duration (i.e. more than 24 hours old)
+func (ds *Plugin) PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return pruneEvents(tx.Bucket(entryEventsBucket), olderThan)
+	})
+}
+
+// CreateRegistrationEntryEventForTesting creates a registration entry event. Used for unit testing.
+func (ds *Plugin) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return createRegistrationEntryEvent(tx, event)
+	})
+}
+
+// DeleteRegistrationEntryEventForTesting deletes the given registration entry event. Used for unit testing.
+func (ds *Plugin) DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return deleteEvent(tx.Bucket(entryEventsBucket), eventID)
+	})
+}
+
+// FetchRegistrationEntryEvent fetches an existing registration entry event by event ID
+func (ds *Plugin) FetchRegistrationEntryEvent(ctx context.Context, eventID uint) (event *datastore.RegistrationEntryEvent, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		event, err = fetchRegistrationEntryEvent(tx, eventID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// CreateJoinToken takes a Token message and stores it
+func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) error {
+	if token == nil || token.Token == "" || token.Expiry.IsZero() {
+		return errors.New("token and expiry are required")
+	}
+
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return createJoinToken(tx, token)
+	})
+}
+
+// FetchJoinToken takes a Token message and returns one, populating the fields
+// we have knowledge of
+func (ds *Plugin) FetchJoinToken(ctx context.Context, token string) (resp *datastore.JoinToken, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = fetchJoinToken(tx, token)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListJoinTokens returns all the join tokens
+func (ds *Plugin) ListJoinTokens(ctx context.Context) (resp []*datastore.JoinToken, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listJoinTokens(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteJoinToken deletes the given join token
+func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return deleteJoinToken(tx, token)
+	})
+}
+
+// PruneJoinTokens takes a Token message, and deletes all tokens which have expired
+// before the date in the message
+func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return pruneJoinTokens(tx, expiry)
+	})
+}
+
+// CreateFederationRelationship creates a new federation relationship. If the
+// given federation relationship contains a bundle, the current stored bundle
+// is overridden.
+func (ds *Plugin) CreateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship) (newFr *datastore.FederationRelationship, err error) {
+	if err := validateFederationRelationship(fr, protoutil.AllTrueFederationRelationshipMask); err != nil {
+		return nil, err
+	}
+
+	return newFr, ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		newFr, err = createFederationRelationship(tx, fr)
+		return err
+	})
+}
+
+// DeleteFederationRelationship deletes the federation relationship to the
+// given trust domain. The associated trust bundle is not deleted.
+func (ds *Plugin) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) error {
+	if trustDomain.IsZero() {
+		return status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return deleteFederationRelationship(tx, trustDomain)
+	})
+}
+
+// FetchFederationRelationship fetches the federation relationship that matches
+// the given trust domain. If the federation relationship is not found, nil is returned.
+func (ds *Plugin) FetchFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (fr *datastore.FederationRelationship, err error) {
+	if trustDomain.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		fr, err = fetchFederationRelationship(tx, trustDomain)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
+// ListFederationRelationships can be used to list all existing federation relationships
+func (ds *Plugin) ListFederationRelationships(ctx context.Context, req *datastore.ListFederationRelationshipsRequest) (resp *datastore.ListFederationRelationshipsResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		resp, err = listFederationRelationships(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateFederationRelationship updates the given federation relationship.
+// Attributes are only updated if the correspondent mask value is set to true.
+func (ds *Plugin) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (newFr *datastore.FederationRelationship, err error) {
+	if err := validateFederationRelationship(fr, mask); err != nil {
+		return nil, err
+	}
+
+	return newFr, ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		newFr, err = updateFederationRelationship(tx, fr, mask)
+		return err
+	})
+}
+
+// FetchCAJournal fetches the CA journal that has the given active X509
+// authority domain. If the CA journal is not found, nil is returned.
+func (ds *Plugin) FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (caJournal *datastore.CAJournal, err error) {
+	if activeX509AuthorityID == "" {
+		return nil, status.Error(codes.InvalidArgument, "active X509 authority ID is required")
+	}
+
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		caJournal, err = fetchCAJournal(tx, activeX509AuthorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return caJournal, nil
+}
+
+// ListCAJournals returns all the CA journal records
+func (ds *Plugin) ListCAJournals(ctx context.Context) (caJournals []*datastore.CAJournal, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		caJournals, err = listCAJournals(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return caJournals, nil
+}
+
+// SetCAJournal sets the content for the specified CA journal. If the CA journal
+// does not exist, it is created.
+func (ds *Plugin) SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (caj *datastore.CAJournal, err error) {
+	if err := validateCAJournal(caJournal); err != nil {
+		return nil, err
+	}
+
+	if err = ds.withWriteTx(ctx, func(tx *bbolt.Tx) (err error) {
+		if caJournal.ID == 0 {
+			caj, err = createCAJournal(tx, caJournal)
+			return err
+		}
+
+		// The CA journal already exists, update it.
+		caj, err = updateCAJournal(tx, caJournal)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return caj, nil
+}
+
+// PruneCAJournals prunes the CA journals that have all of their authorities
+// expired.
+func (ds *Plugin) PruneCAJournals(ctx context.Context, allAuthoritiesExpireBefore int64) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return ds.pruneCAJournals(tx, allAuthoritiesExpireBefore)
+	})
+}
+
+// ListFeatureFlagStatuses returns the feature flag statuses reported by the
+// servers and agents
+func (ds *Plugin) ListFeatureFlagStatuses(ctx context.Context) (statuses []*datastore.FeatureFlagStatus, err error) {
+	if err = ds.withReadTx(ctx, func(tx *bbolt.Tx) (err error) {
+		statuses, err = listFeatureFlagStatuses(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// PruneServerFeatureFlagStatuses deletes the feature flag statuses of the
+// servers that have not reported them since the given time. The statuses of
+// agents are deleted along with their attested node instead.
+func (ds *Plugin) PruneServerFeatureFlagStatuses(ctx context.Context, reportedBefore time.Time) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		return pruneServerFeatureFlagStatuses(tx, reportedBefore)
+	})
+}
+
+// SetFeatureFlagStatus sets the feature flag status of a server or agent,
+// replacing the one previously reported by it. The agent must have an
+// attested node.
+func (ds *Plugin) SetFeatureFlagStatus(ctx context.Context, status *datastore.FeatureFlagStatus) error {
+	return ds.withWriteTx(ctx, func(tx *bbolt.Tx) error {
+		if err := validateFeatureFlagStatus(status); err != nil {
+			return err
+		}
+		return setFeatureFlagStatus(tx, status)
+	})
+}
+
+func (ds *Plugin) pruneCAJournals(tx *bbolt.Tx, allAuthoritiesExpireBefore int64) error {
+	caJournals, err := listCAJournals(tx)
+	if err != nil {
+		return err
+	}
+
+checkAuthorities:
+	for _, caJournal := range caJournals {
+		entries := new(journal.Entries)
+		if err := proto.Unmarshal(caJournal.Data, entries); err != nil {
+			return status.Errorf(codes.Internal, "unable to unmarshal entries from CA journal record: %v", err)
+		}
+
+		for _, x509CA := range entries.X509CAs {
+			if x509CA.NotAfter > allAuthoritiesExpireBefore {
+				continue checkAuthorities
+			}
+		}
+		for _, jwtKey := range entries.JwtKeys {
+			if jwtKey.NotAfter > allAuthoritiesExpireBefore {
+				continue checkAuthorities
+			}
+		}
+		if err := deleteCAJournal(tx, caJournal.ID); err != nil {
+			return status.Errorf(codes.Internal, "failed to delete CA journal: %v", err)
+		}
+		ds.log.WithFields(logrus.Fields{
+			telemetry.CAJournalID: caJournal.ID,
+		}).Info("Pruned stale CA journal record")
+	}
+
+	return nil
+}
+
+// Configure parses HCL config payload into config struct and opens the
+// database file, creating it if it does not exist.
+func (ds *Plugin) Configure(_ context.Context, hclConfiguration string) error {
+	config, err := buildConfig(hclConfiguration)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	return ds.openDB(config)
+}
+
+func (ds *Plugin) Validate(_ context.Context, _ catalog.CoreConfig, configuration string) (*configv1.ValidateResponse, error) {
+	config, err := buildConfig(configuration)
+	if err != nil {
+		return &configv1.ValidateResponse{
+			Notes: []string{err.Error()},
+		}, err
+	}
+	err = config.Validate()
+	if err != nil {
+		return &configv1.ValidateResponse{
+			Notes: []string{err.Error()},
+		}, err
+	}
+
+	return &configv1.ValidateResponse{
+		Valid: true,
+	}, nil
+}
+
+func buildConfig(hclConfiguration string) (*configuration, error) {
+	config := &configuration{}
+	if err := hcl.Decode(config, hclConfiguration); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (ds *Plugin) openDB(config *configuration) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.db != nil && ds.path == config.DatabasePath {
+		return nil
+	}
+
+	db, err := bbolt.Open(config.DatabasePath, 0600, &bbolt.Options{
+		Timeout: openTimeout,
+	})
+	switch {
+	case errors.Is(err, bbolt.ErrTimeout):
+		// Another process, usually the running server, holds the lock on
+		// the database file.
+		return newKVError("unable to open database: %s is locked by another process", config.DatabasePath)
+	case err != nil:
+		return newKVError("unable to open database: %v", err)
+	}
+
+	if err := db.Update(initSchema); err != nil {
+		db.Close()
+		return err
+	}
+
+	if ds.db != nil {
+		ds.db.Close()
+	}
+
+	ds.log.WithFields(logrus.Fields{
+		telemetry.Path:    config.DatabasePath,
+		telemetry.Version: schemaVersion,
+	}).Info("Opened key-value database")
+
+	ds.db = db
+	ds.path = config.DatabasePath
+	return nil
+}
+
+// initSchema creates the buckets that do not exist yet and checks that the
+// database was not written by a newer server.
+func initSchema(tx *bbolt.Tx) error {
+	for _, name := range allBuckets {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+
+	meta := tx.Bucket(metaBucket)
+	if v := meta.Get(schemaVersionKey); v != nil {
+		if version := btoi(v); version > schemaVersion {
+			return newKVError("database schema version %d is newer than the supported version %d", version, schemaVersion)
+		}
+		return nil
+	}
+
+	if err := meta.Put(schemaVersionKey, itob(schemaVersion)); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func (ds *Plugin) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.db == nil {
+		return nil
+	}
+	err := ds.db.Close()
+	ds.db = nil
+	return err
+}
+
+// withWriteTx wraps the operation in a read-write transaction. The embedded
+// store allows a single writer at a time, so writes are serialized and
+// read-modify-write operations need no further locking.
+func (ds *Plugin) withWriteTx(ctx context.Context, op func(tx *bbolt.Tx) error) error {
+	return ds.withTx(ctx, op, false)
+}
+
+// withReadTx wraps the operation in a read-only transaction. Any attempt to
+// write from it fails.
+func (ds *Plugin) withReadTx(ctx context.Context, op func(tx *bbolt.Tx) error) error {
+	return ds.withTx(ctx, op, true)
+}
+
+func (ds *Plugin) withTx(ctx context.Context, op func(tx *bbolt.Tx) error, readOnly bool) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	if ds.db == nil {
+		return status.Error(codes.FailedPrecondition, fmt.Sprintf("%s: datastore is not configured", datastoreKVErrorPrefix))
+	}
+
+	var err error
+	if readOnly {
+		err = ds.db.View(op)
+	} else {
+		err = ds.db.Update(op)
+	}
+	if err != nil {
+		return kvToGRPCStatus(err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package kvstore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/datastoretest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+)
+
+var ctx = context.Background()
+
+func TestDataStore(t *testing.T) {
+	spiretest.Run(t, &datastoretest.Suite{
+		NewDataStore: func(t *testing.T, log logrus.FieldLogger) datastore.DataStore {
+			return newPluginWithLog(t, log, filepath.Join(t.TempDir(), "datastore.db"))
+		},
+		ErrorPrefix: "datastore-kv",
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+
+	err := ds.Configure(ctx, "")
+	require.EqualError(t, err, "datastore-kv: database_path must be set")
+
+	err = ds.Configure(ctx, "database_path = ")
+	require.Error(t, err)
+
+	resp, err := ds.Validate(ctx, catalog.CoreConfig{}, "")
+	require.Error(t, err)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, []string{"datastore-kv: database_path must be set"}, resp.Notes)
+
+	resp, err = ds.Validate(ctx, catalog.CoreConfig{}, `database_path = "datastore.db"`)
+	require.NoError(t, err)
+	assert.True(t, resp.Valid)
+
+	// The database is not opened by Validate
+	_, err = ds.FetchBundle(ctx, "spiffe://example.org")
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "datastore-kv: datastore is not configured")
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datastore.db")
+
+	ds := newPlugin(t, path)
+	bundle, err := ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: "spiffe://example.org"})
+	require.NoError(t, err)
+	require.NoError(t, ds.Close())
+
+	ds = newPlugin(t, path)
+	fetched, err := ds.FetchBundle(ctx, "spiffe://example.org")
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, bundle, fetched)
+}
+
+func TestDatabaseLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datastore.db")
+	newPlugin(t, path)
+
+	// The file is locked until the first plugin is closed
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	err := ds.Configure(ctx, fmt.Sprintf("database_path = %q", path))
+	require.EqualError(t, err, fmt.Sprintf("datastore-kv: unable to open database: %s is locked by another process", path))
+}
+
+func TestSchemaVersionTooNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datastore.db")
+
+	db, err := bbolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucket(metaBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(schemaVersionKey, itob(schemaVersion+1))
+	}))
+	require.NoError(t, db.Close())
+
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	err = ds.Configure(ctx, fmt.Sprintf("database_path = %q", path))
+	require.EqualError(t, err, fmt.Sprintf("datastore-kv: database schema version %d is newer than the supported version %d", schemaVersion+1, schemaVersion))
+}
+
+func TestCanceledContext(t *testing.T) {
+	ds := newPlugin(t, filepath.Join(t.TempDir(), "datastore.db"))
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := ds.FetchBundle(ctx, "spiffe://example.org")
+	spiretest.RequireGRPCStatus(t, err, codes.Canceled, "context canceled")
+}
+
+func newPlugin(t *testing.T, path string) *Plugin {
+	log, _ := test.NewNullLogger()
+	return newPluginWithLog(t, log, path)
+}
+
+func newPluginWithLog(t *testing.T, log logrus.FieldLogger, path string) *Plugin {
+	ds := New(log)
+	require.NoError(t, ds.Configure(ctx, fmt.Sprintf("database_path = %q", path)))
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
@@ -0,0 +1,489 @@
+package kvstore
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/protoutil"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Maximum number of nodes pruned in a single call
+const pruneNodesLimit = 1000
+
+func createAttestedNode(tx *bbolt.Tx, node *common.AttestedNode) (*common.AttestedNode, error) {
+	bySpiffeID := tx.Bucket(nodesBySpiffeIDBucket)
+	if _, ok := lookupRowID(bySpiffeID, node.SpiffeId); ok {
+		return nil, newAlreadyExistsError("attested node %q", node.SpiffeId)
+	}
+
+	model := &common.AttestedNode{
+		SpiffeId:            node.SpiffeId,
+		AttestationDataType: node.AttestationDataType,
+		CertSerialNumber:    node.CertSerialNumber,
+		CertNotAfter:        node.CertNotAfter,
+		NewCertSerialNumber: node.NewCertSerialNumber,
+		NewCertNotAfter:     node.NewCertNotAfter,
+		CanReattest:         node.CanReattest,
+		AgentVersion:        node.AgentVersion,
+	}
+
+	data, err := marshalNode(model)
+	if err != nil {
+		return nil, err
+	}
+	rowID, err := putWithNewRowID(tx.Bucket(nodesBucket), data)
+	if err != nil {
+		return nil, err
+	}
+	if err := bySpiffeID.Put(uniqueKey(model.SpiffeId), itob(rowID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	if err := tx.Bucket(nodesByExpiryBucket).Put(nodeExpiryKey(rowID, model), nil); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return model, nil
+}
+
+// fetchAttestedNode returns the node with the given SPIFFE ID, or nil if
+// there is no such node.
+func fetchAttestedNode(tx *bbolt.Tx, spiffeID string) (*common.AttestedNode, error) {
+	rowID, ok := lookupRowID(tx.Bucket(nodesBySpiffeIDBucket), spiffeID)
+	if !ok {
+		return nil, nil
+	}
+	return loadNode(tx, rowID)
+}
+
+func countAttestedNodes(tx *bbolt.Tx, req *datastore.CountAttestedNodesRequest) (int32, error) {
+	if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) == 0 {
+		return -1, status.Error(codes.InvalidArgument, "cannot list by empty selectors set")
+	}
+
+	f := nodeFilter{
+		byAttestationType: req.ByAttestationType,
+		byBanned:          req.ByBanned,
+		byExpiresBefore:   req.ByExpiresBefore,
+		bySelectorMatch:   req.BySelectorMatch,
+		byCanReattest:     req.ByCanReattest,
+	}
+
+	var count int
+	if err := f.scan(tx, 0, func(uint64, *common.AttestedNode) bool {
+		count++
+		return true
+	}); err != nil {
+		return -1, err
+	}
+
+	return util.CheckedCast[int32](count)
+}
+
+func listAttestedNodes(tx *bbolt.Tx, req *datastore.ListAttestedNodesRequest) (*datastore.ListAttestedNodesResponse, error) {
+	if req.Pagination != nil && req.Pagination.PageSize == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+	}
+	if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot list by empty selectors set")
+	}
+
+	afterID, err := paginationStart(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	f := nodeFilter{
+		byAttestationType: req.ByAttestationType,
+		byBanned:          req.ByBanned,
+		byExpiresBefore:   req.ByExpiresBefore,
+		bySelectorMatch:   req.BySelectorMatch,
+		byCanReattest:     req.ByCanReattest,
+		validAt:           req.ValidAt,
+	}
+
+	nodes := []*common.AttestedNode{}
+	var lastID uint64
+	if err := f.scan(tx, afterID, func(rowID uint64, node *common.AttestedNode) bool {
+		nodes = append(nodes, node)
+		lastID = rowID
+		return !pageFull(req.Pagination, len(nodes))
+	}); err != nil {
+		return nil, err
+	}
+
+	if req.FetchSelectors {
+		for _, node := range nodes {
+			node.Selectors, err = getNodeSelectors(tx, node.SpiffeId)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &datastore.ListAttestedNodesResponse{
+		Nodes:      nodes,
+		Pagination: nextPagination(req.Pagination, len(nodes), lastID),
+	}, nil
+}
+
+func updateAttestedNode(tx *bbolt.Tx, n *common.AttestedNode, mask *common.AttestedNodeMask) (*common.AttestedNode, error) {
+	rowID, ok := lookupRowID(tx.Bucket(nodesBySpiffeIDBucket), n.SpiffeId)
+	if !ok {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	existing, err := loadNode(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mask == nil {
+		mask = protoutil.AllTrueCommonAgentMask
+	}
+
+	node := proto.Clone(existing).(*common.AttestedNode)
+	if mask.CertNotAfter {
+		node.CertNotAfter = n.CertNotAfter
+	}
+	if mask.CertSerialNumber {
+		node.CertSerialNumber = n.CertSerialNumber
+	}
+	if mask.NewCertNotAfter {
+		node.NewCertNotAfter = n.NewCertNotAfter
+	}
+	if mask.NewCertSerialNumber {
+		node.NewCertSerialNumber = n.NewCertSerialNumber
+	}
+	if mask.CanReattest {
+		node.CanReattest = n.CanReattest
+	}
+	if mask.AgentVersion {
+		node.AgentVersion = n.AgentVersion
+	}
+
+	data, err := marshalNode(node)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Bucket(nodesBucket).Put(itob(rowID), data); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	byExpiry := tx.Bucket(nodesByExpiryBucket)
+	if err := byExpiry.Delete(nodeExpiryKey(rowID, existing)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	if err := byExpiry.Put(nodeExpiryKey(rowID, node), nil); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return node, nil
+}
+
+func deleteAttestedNodeAndSelectors(tx *bbolt.Tx, spiffeID string) (*common.AttestedNode, error) {
+	if err := setNodeSelectors(tx, spiffeID, nil); err != nil {
+		return nil, err
+	}
+	if err := deleteAgentFeatureFlagStatus(tx, spiffeID); err != nil {
+		return nil, err
+	}
+
+	rowID, ok := lookupRowID(tx.Bucket(nodesBySpiffeIDBucket), spiffeID)
+	if !ok {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	node, err := loadNode(tx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Bucket(nodesBucket).Delete(itob(rowID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	if err := tx.Bucket(nodesBySpiffeIDBucket).Delete(uniqueKey(spiffeID)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	if err := tx.Bucket(nodesByExpiryBucket).Delete(nodeExpiryKey(rowID, node)); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+
+	return node, nil
+}
+
+func pruneAttestedExpiredNodes(tx *bbolt.Tx, expiredBefore time.Time, includeNonReattestable bool, logger logrus.FieldLogger) error {
+	var expiredNodes []*common.AttestedNode
+	c := tx.Bucket(nodesByExpiryBucket).Cursor()
+	for k, _ := c.First(); k != nil && len(expiredNodes) < pruneNodesLimit; k, _ = c.Next() {
+		if !time.Unix(fromSortableInt64(k[:8]), 0).Before(expiredBefore) {
+			break
+		}
+		node, err := loadNode(tx, btoi(k[8:]))
+		if err != nil {
+			return err
+		}
+		// Banned nodes are never pruned
+		if node.CertSerialNumber == "" {
+			continue
+		}
+		if !includeNonReattestable && !node.CanReattest {
+			continue
+		}
+		expiredNodes = append(expiredNodes, node)
+	}
+
+	var count int
+	defer func() { logger.WithField("count", count).Info("Pruned expired agents") }()
+
+	for _, node := range expiredNodes {
+		if _, err := deleteAttestedNodeAndSelectors(tx, node.SpiffeId); err != nil {
+			return err
+		}
+		count++
+
+		if err := createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: node.SpiffeId,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setNodeSelectors replaces the selectors of the node with the given SPIFFE
+// ID. Node selectors are kept independently of the attested node record.
+func setNodeSelectors(tx *bbolt.Tx, spiffeID string, selectors []*common.Selector) error {
+	existing, err := getNodeSelectors(tx, spiffeID)
+	if err != nil {
+		return err
+	}
+
+	byValue := tx.Bucket(nodeSelectorsByValueBucket)
+	for _, s := range existing {
+		if err := byValue.Delete(nodeSelectorValueKey(s, spiffeID)); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+
+	b := tx.Bucket(nodeSelectorsBucket)
+	if len(selectors) == 0 {
+		if err := b.Delete(uniqueKey(spiffeID)); err != nil {
+			return newWrappedKVError(err)
+		}
+		return nil
+	}
+
+	for _, s := range selectors {
+		key := nodeSelectorValueKey(s, spiffeID)
+		if byValue.Get(key) != nil {
+			return newAlreadyExistsError("duplicate selector %s:%s", s.Type, s.Value)
+		}
+		if err := byValue.Put(key, nil); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+
+	data, err := proto.Marshal(&common.Selectors{Entries: selectors})
+	if err != nil {
+		return newWrappedKVError(err)
+	}
+	if err := b.Put(uniqueKey(spiffeID), data); err != nil {
+		return newWrappedKVError(err)
+	}
+
+	return nil
+}
+
+func getNodeSelectors(tx *bbolt.Tx, spiffeID string) ([]*common.Selector, error) {
+	v := tx.Bucket(nodeSelectorsBucket).Get(uniqueKey(spiffeID))
+	if v == nil {
+		return nil, nil
+	}
+	selectors := new(common.Selectors)
+	if err := proto.Unmarshal(v, selectors); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return selectors.Entries, nil
+}
+
+func listNodeSelectors(tx *bbolt.Tx, req *datastore.ListNodeSelectorsRequest) (*datastore.ListNodeSelectorsResponse, error) {
+	resp := &datastore.ListNodeSelectorsResponse{
+		Selectors: make(map[string][]*common.Selector),
+	}
+
+	bySpiffeID := tx.Bucket(nodesBySpiffeIDBucket)
+	c := tx.Bucket(nodeSelectorsBucket).Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		// Keys are length-prefixed SPIFFE IDs
+		spiffeID := string(k[4:])
+
+		if !req.ValidAt.IsZero() {
+			rowID, ok := lookupRowID(bySpiffeID, spiffeID)
+			if !ok {
+				continue
+			}
+			node, err := loadNode(tx, rowID)
+			if err != nil {
+				return nil, err
+			}
+			if !time.Unix(node.CertNotAfter, 0).After(req.ValidAt) {
+				continue
+			}
+		}
+
+		selectors := new(common.Selectors)
+		if err := proto.Unmarshal(v, selectors); err != nil {
+			return nil, newWrappedKVError(err)
+		}
+		resp.Selectors[spiffeID] = selectors.Entries
+	}
+
+	return resp, nil
+}
+
+// nodeFilter holds the criteria used to list and count attested nodes.
+type nodeFilter struct {
+	byAttestationType string
+	byBanned          *bool
+	byExpiresBefore   time.Time
+	bySelectorMatch   *datastore.BySelectors
+	byCanReattest     *bool
+	validAt           time.Time
+}
+
+// scan visits, in row ID order, the nodes after the given row ID that match
+// the filter.
+func (f *nodeFilter) scan(tx *bbolt.Tx, afterID uint64, fn func(rowID uint64, node *common.AttestedNode) bool) error {
+	candidates := f.candidates(tx)
+
+	var matchErr error
+	err := scanRows(tx.Bucket(nodesBucket), afterID, candidates, func(rowID uint64, v []byte) (bool, error) {
+		node, err := unmarshalNode(v)
+		if err != nil {
+			return false, err
+		}
+		ok, err := f.matches(tx, node)
+		if err != nil {
+			matchErr = err
+			return false, nil
+		}
+		if !ok {
+			return true, nil
+		}
+		return fn(rowID, node), nil
+	})
+	if err != nil {
+		return err
+	}
+	return matchErr
+}
+
+// candidates returns the rows that can possibly match the filter according
+// to the secondary indexes, or nil if the filter cannot be narrowed down by
+// the indexes.
+func (f *nodeFilter) candidates(tx *bbolt.Tx) rowSet {
+	var sets []rowSet
+	if !f.byExpiresBefore.IsZero() {
+		expired := make(rowSet)
+		c := tx.Bucket(nodesByExpiryBucket).Cursor()
+		for k, _ := c.First(); k != nil && time.Unix(fromSortableInt64(k[:8]), 0).Before(f.byExpiresBefore); k, _ = c.Next() {
+			expired[btoi(k[8:])] = struct{}{}
+		}
+		sets = append(sets, expired)
+	}
+	if f.bySelectorMatch != nil && len(f.bySelectorMatch.Selectors) > 0 {
+		bySpiffeID := tx.Bucket(nodesBySpiffeIDBucket)
+		byValue := tx.Bucket(nodeSelectorsByValueBucket)
+
+		var selectorSets []rowSet
+		for _, s := range f.bySelectorMatch.Selectors {
+			rows := make(rowSet)
+			prefix := indexPrefix(s.Type, s.Value)
+			c := byValue.Cursor()
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				if rowID, ok := lookupRowID(bySpiffeID, string(k[len(prefix):])); ok {
+					rows[rowID] = struct{}{}
+				}
+			}
+			selectorSets = append(selectorSets, rows)
+		}
+		sets = append(sets, combineRowSets(selectorSets, isUnionMatch(f.bySelectorMatch.Match)))
+	}
+
+	if len(sets) == 0 {
+		return nil
+	}
+	return combineRowSets(sets, false)
+}
+
+func (f *nodeFilter) matches(tx *bbolt.Tx, node *common.AttestedNode) (bool, error) {
+	expiresAt := time.Unix(node.CertNotAfter, 0)
+	if !f.byExpiresBefore.IsZero() && !expiresAt.Before(f.byExpiresBefore) {
+		return false, nil
+	}
+	if !f.validAt.IsZero() && expiresAt.Before(f.validAt) {
+		return false, nil
+	}
+	if f.byAttestationType != "" && node.AttestationDataType != f.byAttestationType {
+		return false, nil
+	}
+	// A node is banned when its serial number is empty
+	if f.byBanned != nil && (node.CertSerialNumber == "") != *f.byBanned {
+		return false, nil
+	}
+	if f.byCanReattest != nil && node.CanReattest != *f.byCanReattest {
+		return false, nil
+	}
+	if f.bySelectorMatch != nil && len(f.bySelectorMatch.Selectors) > 0 {
+		selectors, err := getNodeSelectors(tx, node.SpiffeId)
+		if err != nil {
+			return false, err
+		}
+		ok, err := matchSet(selectorKeys(selectors), selectorKeys(f.bySelectorMatch.Selectors), f.bySelectorMatch.Match)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func nodeExpiryKey(rowID uint64, node *common.AttestedNode) []byte {
+	return append(sortableInt64(node.CertNotAfter), itob(rowID)...)
+}
+
+// nodeSelectorValueKey returns the key indexing the node by the selector.
+// The SPIFFE ID is not length-prefixed since it is the last component.
+func nodeSelectorValueKey(s *common.Selector, spiffeID string) []byte {
+	return append(indexPrefix(s.Type, s.Value), spiffeID...)
+}
+
+func loadNode(tx *bbolt.Tx, rowID uint64) (*common.AttestedNode, error) {
+	v := tx.Bucket(nodesBucket).Get(itob(rowID))
+	if v == nil {
+		return nil, newWrappedKVError(errNotFound)
+	}
+	return unmarshalNode(v)
+}
+
+func marshalNode(node *common.AttestedNode) ([]byte, error) {
+	data, err := proto.Marshal(node)
+	if err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return data, nil
+}
+
+func unmarshalNode(data []byte) (*common.AttestedNode, error) {
+	node := new(common.AttestedNode)
+	if err := proto.Unmarshal(data, node); err != nil {
+		return nil, newWrappedKVError(err)
+	}
+	return node, nil
+}
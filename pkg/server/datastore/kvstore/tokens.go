@@ -0,0 +1,79 @@
+package kvstore
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.etcd.io/bbolt"
+)
+
+func createJoinToken(tx *bbolt.Tx, token *datastore.JoinToken) error {
+	b := tx.Bucket(joinTokensBucket)
+	key := uniqueKey(token.Token)
+	if b.Get(key) != nil {
+		return newAlreadyExistsError("join token")
+	}
+
+	if err := b.Put(key, sortableInt64(token.Expiry.Unix())); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+// fetchJoinToken returns the join token, or nil if there is no such token.
+func fetchJoinToken(tx *bbolt.Tx, token string) (*datastore.JoinToken, error) {
+	v := tx.Bucket(joinTokensBucket).Get(uniqueKey(token))
+	if v == nil {
+		return nil, nil
+	}
+
+	return &datastore.JoinToken{
+		Token:  token,
+		Expiry: time.Unix(fromSortableInt64(v), 0),
+	}, nil
+}
+
+func listJoinTokens(tx *bbolt.Tx) ([]*datastore.JoinToken, error) {
+	tokens := []*datastore.JoinToken{}
+	c := tx.Bucket(joinTokensBucket).Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		tokens = append(tokens, &datastore.JoinToken{
+			Token:  string(k[4:]),
+			Expiry: time.Unix(fromSortableInt64(v), 0),
+		})
+	}
+	return tokens, nil
+}
+
+func deleteJoinToken(tx *bbolt.Tx, token string) error {
+	b := tx.Bucket(joinTokensBucket)
+	key := uniqueKey(token)
+	if b.Get(key) == nil {
+		return newWrappedKVError(errNotFound)
+	}
+
+	if err := b.Delete(key); err != nil {
+		return newWrappedKVError(err)
+	}
+	return nil
+}
+
+func pruneJoinTokens(tx *bbolt.Tx, expiresBefore time.Time) error {
+	b := tx.Bucket(joinTokensBucket)
+
+	var expired [][]byte
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if fromSortableInt64(v) < expiresBefore.Unix() {
+			expired = append(expired, bytes.Clone(k))
+		}
+	}
+
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return newWrappedKVError(err)
+		}
+	}
+	return nil
+}
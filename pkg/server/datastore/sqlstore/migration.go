@@ -284,7 +284,7 @@ import (
 
 const (
 	// the latest schema version of the database in the code
-	latestSchemaVersion = 25
+	latestSchemaVersion = 27
 
 	// lastMinorReleaseSchemaVersion is the schema version supported by the
 	// last minor release. When the migrations are opportunistically pruned
@@ -444,6 +444,8 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		&DNSName{},
 		&FederatedTrustDomain{},
 		CAJournal{},
+		&EntryTemplate{},
+		&FeatureFlagStatus{},
 	}
 
 	if err := tableOptionsForDialect(tx, dbType).AutoMigrate(tables...).Error; err != nil {
@@ -519,6 +521,10 @@ func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versi
 		err = migrateToV24(tx)
 	case 24:
 		err = migrateToV25(tx)
+	case 25:
+		err = migrateToV26(tx)
+	case 26:
+		err = migrateToV27(tx)
 	default:
 		err = newSQLError("no migration support for unknown schema version %d", currVersion)
 	}
@@ -545,6 +551,22 @@ func migrateToV25(tx *gorm.DB) error {
 	return nil
 }
 
+func migrateToV26(tx *gorm.DB) error {
+	// Add entry_templates table
+	if err := tx.AutoMigrate(&EntryTemplate{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV27(tx *gorm.DB) error {
+	// Add feature_flag_statuses table
+	if err := tx.AutoMigrate(&FeatureFlagStatus{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
 func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	// GORM creates the federated_registration_entries implicitly with a primary
 	// key tuple (bundle_id, registered_entry_id). Unfortunately, MySQL5 does
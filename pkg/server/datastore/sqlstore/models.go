@@ -197,6 +197,31 @@ type CAJournal struct {
 	ActiveJWTAuthorityID string `gorm:"index:idx_ca_journals_active_jwt_authority_id"`
 }
 
+// EntryTemplate holds a registration entry template. Entry templates are
+// always listed in full, so the template is stored as a single protobuf blob.
+type EntryTemplate struct {
+	Model
+
+	TemplateID string `gorm:"unique_index"`
+
+	// Data is the protobuf encoding of the entry template.
+	Data []byte `gorm:"size:16777215"` // Make MySQL to use MEDIUMBLOB(max 16MB) - doesn't affect PostgreSQL/SQLite
+}
+
+// FeatureFlagStatus holds the feature flags enabled on a server or an agent,
+// as last reported by it.
+type FeatureFlagStatus struct {
+	Model
+
+	ReporterID string `gorm:"unique_index:idx_feature_flag_statuses_reporter"`
+	IsServer   bool   `gorm:"unique_index:idx_feature_flag_statuses_reporter"`
+
+	// Enabled is the comma separated list of the enabled feature flags
+	Enabled string
+
+	ReportedAt int64 `gorm:"index"`
+}
+
 // Migration holds database schema version number, and
 // the SPIRE Code version number
 type Migration struct {
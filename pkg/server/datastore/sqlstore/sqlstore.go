@@ -27,6 +27,7 @@ import (
 	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
@@ -274,6 +275,18 @@ func (ds *Plugin) TaintJWTKey(ctx context.Context, trustDoaminID string, authori
 	return taintedKey, nil
 }
 
+// TaintWITKey taints a WIT Authority key
+func (ds *Plugin) TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	var taintedKey *common.PublicKey
+	if err := ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		taintedKey, err = taintWITKey(tx, trustDomainID, authorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return taintedKey, nil
+}
+
 // RevokeJWTAuthority removes JWT key from the bundle
 func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDoaminID string, authorityID string) (*common.PublicKey, error) {
 	var revokedKey *common.PublicKey
@@ -286,6 +299,18 @@ func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDoaminID string, author
 	return revokedKey, nil
 }
 
+// RevokeWITKey removes WIT key from the bundle
+func (ds *Plugin) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	var revokedKey *common.PublicKey
+	if err := ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		revokedKey, err = revokeWITKey(tx, trustDomainID, authorityID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return revokedKey, nil
+}
+
 // CreateAttestedNode stores the given attested node
 func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (attestedNode *common.AttestedNode, err error) {
 	if node == nil {
@@ -636,6 +661,69 @@ func (ds *Plugin) FetchRegistrationEntryEvent(ctx context.Context, eventID uint)
 	return event, nil
 }
 
+// CreateEntryTemplate stores the given entry template. If the entry template
+// has no ID, one is generated.
+func (ds *Plugin) CreateEntryTemplate(ctx context.Context, template *common.EntryTemplate) (entryTemplate *common.EntryTemplate, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		if err = validateEntryTemplate(template); err != nil {
+			return err
+		}
+		entryTemplate, err = createEntryTemplate(tx, template)
+		if err != nil {
+			return err
+		}
+
+		// Entry templates are cached as the entries they are sent to the
+		// agents as, which are updated through the registration entry events.
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entrytemplate.EntryID(entryTemplate.TemplateId),
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplate, nil
+}
+
+// FetchEntryTemplate fetches the entry template with the given ID. If the
+// entry template is not found, nil is returned.
+func (ds *Plugin) FetchEntryTemplate(ctx context.Context, templateID string) (entryTemplate *common.EntryTemplate, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entryTemplate, err = fetchEntryTemplate(tx, templateID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplate, nil
+}
+
+// ListEntryTemplates returns all the entry templates
+func (ds *Plugin) ListEntryTemplates(ctx context.Context) (entryTemplates []*common.EntryTemplate, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entryTemplates, err = listEntryTemplates(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplates, nil
+}
+
+// DeleteEntryTemplate deletes the entry template with the given ID
+func (ds *Plugin) DeleteEntryTemplate(ctx context.Context, templateID string) (entryTemplate *common.EntryTemplate, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		entryTemplate, err = deleteEntryTemplate(tx, templateID)
+		if err != nil {
+			return err
+		}
+
+		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entrytemplate.EntryID(entryTemplate.TemplateId),
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return entryTemplate, nil
+}
+
 // CreateJoinToken takes a Token message and stores it
 func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
 	if token == nil || token.Token == "" || token.Expiry.IsZero() {
@@ -661,6 +749,18 @@ func (ds *Plugin) FetchJoinToken(ctx context.Context, token string) (resp *datas
 	return resp, nil
 }
 
+// ListJoinTokens returns all the join tokens
+func (ds *Plugin) ListJoinTokens(ctx context.Context) (resp []*datastore.JoinToken, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listJoinTokens(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // DeleteJoinToken deletes the given join token
 func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) (err error) {
 	return ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
@@ -772,11 +872,10 @@ func (ds *Plugin) FetchCAJournal(ctx context.Context, activeX509AuthorityID stri
 	return caJournal, nil
 }
 
-// ListCAJournalsForTesting returns all the CA journal records, and is meant to
-// be used in tests.
-func (ds *Plugin) ListCAJournalsForTesting(ctx context.Context) (caJournals []*datastore.CAJournal, err error) {
+// ListCAJournals returns all the CA journal records
+func (ds *Plugin) ListCAJournals(ctx context.Context) (caJournals []*datastore.CAJournal, err error) {
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
-		caJournals, err = listCAJournalsForTesting(tx)
+		caJournals, err = listCAJournals(tx)
 		return err
 	}); err != nil {
 		return nil, err
@@ -815,6 +914,39 @@ func (ds *Plugin) PruneCAJournals(ctx context.Context, allAuthoritiesExpireBefor
 	})
 }
 
+// ListFeatureFlagStatuses returns the feature flag statuses reported by the
+// servers and agents
+func (ds *Plugin) ListFeatureFlagStatuses(ctx context.Context) (statuses []*datastore.FeatureFlagStatus, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		statuses, err = listFeatureFlagStatuses(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// PruneServerFeatureFlagStatuses deletes the feature flag statuses of the
+// servers that have not reported them since the given time. The statuses of
+// agents are deleted along with their attested node instead.
+func (ds *Plugin) PruneServerFeatureFlagStatuses(ctx context.Context, reportedBefore time.Time) error {
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		return pruneServerFeatureFlagStatuses(tx, reportedBefore)
+	})
+}
+
+// SetFeatureFlagStatus sets the feature flag status of a server or agent,
+// replacing the one previously reported by it. The agent must have an
+// attested node.
+func (ds *Plugin) SetFeatureFlagStatus(ctx context.Context, status *datastore.FeatureFlagStatus) error {
+	return ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		if err := validateFeatureFlagStatus(status); err != nil {
+			return err
+		}
+		return setFeatureFlagStatus(tx, status)
+	})
+}
+
 func (ds *Plugin) pruneCAJournals(tx *gorm.DB, allAuthoritiesExpireBefore int64) error {
 	var caJournals []CAJournal
 	if err := tx.Find(&caJournals).Error; err != nil {
@@ -1560,6 +1692,44 @@ func taintJWTKey(tx *gorm.DB, trustDomainID string, authorityID string) (*common
 	return taintedKey, nil
 }
 
+func taintWITKey(tx *gorm.DB, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	bundle, err := getBundle(tx, trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var taintedKey *common.PublicKey
+	for _, witKey := range bundle.WitSigningKeys {
+		if witKey.Kid != authorityID {
+			continue
+		}
+
+		if witKey.TaintedKey {
+			return nil, status.Error(codes.InvalidArgument, "key is already tainted")
+		}
+
+		// Check if a WIT Key with the provided keyID was already
+		// tainted in this loop. This is purely defensive since we do not
+		// allow to have repeated key IDs.
+		if taintedKey != nil {
+			return nil, status.Error(codes.Internal, "another WIT Key found with the same KeyID")
+		}
+		taintedKey = witKey
+		witKey.TaintedKey = true
+	}
+
+	if taintedKey == nil {
+		return nil, status.Error(codes.NotFound, "no WIT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if _, err := updateBundle(tx, bundle, nil); err != nil {
+		return nil, err
+	}
+
+	return taintedKey, nil
+}
+
 func revokeJWTKey(tx *gorm.DB, trustDomainID string, authorityID string) (*common.PublicKey, error) {
 	bundle, err := getBundle(tx, trustDomainID)
 	if err != nil {
@@ -1600,6 +1770,46 @@ func revokeJWTKey(tx *gorm.DB, trustDomainID string, authorityID string) (*commo
 	return revokedKey, nil
 }
 
+func revokeWITKey(tx *gorm.DB, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	bundle, err := getBundle(tx, trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []*common.PublicKey
+	var revokedKey *common.PublicKey
+	for _, key := range bundle.WitSigningKeys {
+		if key.Kid == authorityID {
+			// Check if a WIT Key with the provided keyID was already
+			// found in this loop. This is purely defensive since we do not
+			// allow to have repeated key IDs.
+			if revokedKey != nil {
+				return nil, status.Error(codes.Internal, "another key found with the same KeyID")
+			}
+
+			if !key.TaintedKey {
+				return nil, status.Error(codes.InvalidArgument, "it is not possible to revoke an untainted key")
+			}
+
+			revokedKey = key
+			continue
+		}
+		publicKeys = append(publicKeys, key)
+	}
+	bundle.WitSigningKeys = publicKeys
+
+	if revokedKey == nil {
+		return nil, status.Error(codes.NotFound, "no WIT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if _, err := updateBundle(tx, bundle, nil); err != nil {
+		return nil, err
+	}
+
+	return revokedKey, nil
+}
+
 func getBundle(tx *gorm.DB, trustDomainID string) (*common.Bundle, error) {
 	model := &Bundle{}
 	if err := tx.Find(model, "trust_domain = ?", trustDomainID).Error; err != nil {
@@ -2405,6 +2615,10 @@ func deleteAttestedNodeAndSelectors(tx *gorm.DB, spiffeID string) (*common.Attes
 		return nil, newWrappedSQLError(err)
 	}
 
+	if err := deleteAgentFeatureFlagStatus(tx, spiffeID); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Find(&nodeModel, "spiffe_id = ?", spiffeID).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
@@ -4280,6 +4494,130 @@ func buildListEventsQueryString(greaterThanEventID, lessThanEventID uint) (*stri
 	return query, id, nil
 }
 
+func createEntryTemplate(tx *gorm.DB, template *common.EntryTemplate) (*common.EntryTemplate, error) {
+	templateID := template.TemplateId
+	if templateID == "" {
+		var err error
+		templateID, err = newRegistrationEntryID()
+		if err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+	}
+
+	template = proto.Clone(template).(*common.EntryTemplate)
+	template.TemplateId = ""
+	template.CreatedAt = 0
+	data, err := proto.Marshal(template)
+	if err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	model := EntryTemplate{
+		TemplateID: templateID,
+		Data:       data,
+	}
+	if err := tx.Create(&model).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	return modelToEntryTemplate(model)
+}
+
+func fetchEntryTemplate(tx *gorm.DB, templateID string) (*common.EntryTemplate, error) {
+	var model EntryTemplate
+	err := tx.Find(&model, "template_id = ?", templateID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, newWrappedSQLError(err)
+	}
+
+	return modelToEntryTemplate(model)
+}
+
+func listEntryTemplates(tx *gorm.DB) ([]*common.EntryTemplate, error) {
+	var models []EntryTemplate
+	if err := tx.Order("id ASC").Find(&models).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	templates := make([]*common.EntryTemplate, 0, len(models))
+	for _, model := range models {
+		template, err := modelToEntryTemplate(model)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func deleteEntryTemplate(tx *gorm.DB, templateID string) (*common.EntryTemplate, error) {
+	var model EntryTemplate
+	if err := tx.Find(&model, "template_id = ?", templateID).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	template, err := modelToEntryTemplate(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(&model).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return template, nil
+}
+
+func validateEntryTemplate(template *common.EntryTemplate) error {
+	if template == nil {
+		return newValidationError("invalid request: missing entry template")
+	}
+
+	if len(template.Selectors) == 0 {
+		return newValidationError("invalid entry template: missing selector list")
+	}
+
+	if len(template.TemplateId) > entrytemplate.MaxTemplateIDLength {
+		return newValidationError("invalid entry template: template ID too long")
+	}
+
+	for _, e := range template.TemplateId {
+		if !unicode.In(e, validEntryIDChars) {
+			return newValidationError("invalid entry template: template ID contains invalid characters")
+		}
+	}
+
+	if template.ParentId == "" {
+		return newValidationError("invalid entry template: missing parent ID")
+	}
+
+	if template.SpiffeIdTemplate == "" {
+		return newValidationError("invalid entry template: missing SPIFFE ID template")
+	}
+
+	if template.X509SvidTtl < 0 {
+		return newValidationError("invalid entry template: X509SvidTtl is not set")
+	}
+
+	if template.JwtSvidTtl < 0 {
+		return newValidationError("invalid entry template: JwtSvidTtl is not set")
+	}
+
+	return nil
+}
+
+func modelToEntryTemplate(model EntryTemplate) (*common.EntryTemplate, error) {
+	template := new(common.EntryTemplate)
+	if err := proto.Unmarshal(model.Data, template); err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	template.TemplateId = model.TemplateID
+	template.CreatedAt = roundedInSecondsUnix(model.CreatedAt)
+	return template, nil
+}
+
 func createJoinToken(tx *gorm.DB, token *datastore.JoinToken) error {
 	t := JoinToken{
 		Token:  token.Token,
@@ -4305,6 +4643,19 @@ func fetchJoinToken(tx *gorm.DB, token string) (*datastore.JoinToken, error) {
 	return modelToJoinToken(model), nil
 }
 
+func listJoinTokens(tx *gorm.DB) ([]*datastore.JoinToken, error) {
+	var models []JoinToken
+	if err := tx.Order("id ASC").Find(&models).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	tokens := make([]*datastore.JoinToken, 0, len(models))
+	for _, model := range models {
+		tokens = append(tokens, modelToJoinToken(model))
+	}
+	return tokens, nil
+}
+
 func deleteJoinToken(tx *gorm.DB, token string) error {
 	var model JoinToken
 	if err := tx.Find(&model, "token = ?", token).Error; err != nil {
@@ -4981,7 +5332,7 @@ func fetchCAJournal(tx *gorm.DB, activeX509AuthorityID string) (*datastore.CAJou
 	return modelToCAJournal(model), nil
 }
 
-func listCAJournalsForTesting(tx *gorm.DB) (caJournals []*datastore.CAJournal, err error) {
+func listCAJournals(tx *gorm.DB) (caJournals []*datastore.CAJournal, err error) {
 	var caJournalsModel []CAJournal
 	if err := tx.Find(&caJournalsModel).Error; err != nil {
 		return nil, newWrappedSQLError(err)
@@ -5028,6 +5379,94 @@ func deleteCAJournal(tx *gorm.DB, caJournalID uint) error {
 	return nil
 }
 
+func listFeatureFlagStatuses(tx *gorm.DB) ([]*datastore.FeatureFlagStatus, error) {
+	var models []FeatureFlagStatus
+	if err := tx.Order("id ASC").Find(&models).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	statuses := make([]*datastore.FeatureFlagStatus, 0, len(models))
+	for _, model := range models {
+		statuses = append(statuses, modelToFeatureFlagStatus(model))
+	}
+	return statuses, nil
+}
+
+func pruneServerFeatureFlagStatuses(tx *gorm.DB, reportedBefore time.Time) error {
+	if err := tx.Where("is_server = ? AND reported_at < ?", true, reportedBefore.Unix()).Delete(&FeatureFlagStatus{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func setFeatureFlagStatus(tx *gorm.DB, status *datastore.FeatureFlagStatus) error {
+	if !status.IsServer {
+		// Statuses are only kept for attested agents, since they are deleted
+		// along with the attested node.
+		if err := tx.Find(&AttestedNode{}, "spiffe_id = ?", status.ReporterID).Error; err != nil {
+			return newWrappedSQLError(err)
+		}
+	}
+
+	var model FeatureFlagStatus
+	err := tx.Find(&model, "reporter_id = ? AND is_server = ?", status.ReporterID, status.IsServer).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model = FeatureFlagStatus{
+			ReporterID: status.ReporterID,
+			IsServer:   status.IsServer,
+		}
+	case err != nil:
+		return newWrappedSQLError(err)
+	}
+
+	model.Enabled = strings.Join(status.Enabled, ",")
+	model.ReportedAt = status.ReportedAt
+
+	if err := tx.Save(&model).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func deleteAgentFeatureFlagStatus(tx *gorm.DB, spiffeID string) error {
+	if err := tx.Where("reporter_id = ? AND is_server = ?", spiffeID, false).Delete(&FeatureFlagStatus{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func validateFeatureFlagStatus(status *datastore.FeatureFlagStatus) error {
+	if status == nil {
+		return newValidationError("invalid request: missing feature flag status")
+	}
+
+	if status.ReporterID == "" {
+		return newValidationError("invalid feature flag status: missing reporter ID")
+	}
+
+	for _, flag := range status.Enabled {
+		if flag == "" || strings.Contains(flag, ",") {
+			return newValidationError("invalid feature flag status: invalid feature flag name %q", flag)
+		}
+	}
+
+	return nil
+}
+
+func modelToFeatureFlagStatus(model FeatureFlagStatus) *datastore.FeatureFlagStatus {
+	enabled := []string{}
+	if model.Enabled != "" {
+		enabled = strings.Split(model.Enabled, ",")
+	}
+	return &datastore.FeatureFlagStatus{
+		ReporterID: model.ReporterID,
+		IsServer:   model.IsServer,
+		Enabled:    enabled,
+		ReportedAt: model.ReportedAt,
+	}
+}
+
 func parseDatabaseTypeASTNode(node ast.Node) (*dbTypeConfig, error) {
 	lt, ok := node.(*ast.LiteralType)
 	if ok {
@@ -14,6 +14,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/authorizedentries"
 	"github.com/spiffe/spire/pkg/server/cache/nodecache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ api.AuthorizedEntryFetcher = (*AuthorizedEntryFetcherEvents)(nil)
@@ -61,19 +62,29 @@ func NewAuthorizedEntryFetcherEvents(ctx context.Context, trustDomain string, c
 }
 
 func (a *AuthorizedEntryFetcherEvents) LookupAuthorizedEntries(ctx context.Context, agentID spiffeid.ID, entryIDs map[string]struct{}) (map[string]api.ReadOnlyEntry, error) {
+	_, span := startEntryFetcherSpan(ctx, "LookupAuthorizedEntries", agentID)
+	defer span.End()
+
 	a.mu.RLock()
 	cache := a.cache
 	a.mu.RUnlock()
 
-	return cache.LookupAuthorizedEntries(agentID, entryIDs), nil
+	entries := cache.LookupAuthorizedEntries(agentID, entryIDs)
+	span.SetAttributes(attribute.Int(spanAttrEntryCount, len(entries)))
+	return entries, nil
 }
 
-func (a *AuthorizedEntryFetcherEvents) FetchAuthorizedEntries(_ context.Context, agentID spiffeid.ID) ([]api.ReadOnlyEntry, error) {
+func (a *AuthorizedEntryFetcherEvents) FetchAuthorizedEntries(ctx context.Context, agentID spiffeid.ID) ([]api.ReadOnlyEntry, error) {
+	_, span := startEntryFetcherSpan(ctx, "FetchAuthorizedEntries", agentID)
+	defer span.End()
+
 	a.mu.RLock()
 	cache := a.cache
 	a.mu.RUnlock()
 
-	return cache.GetAuthorizedEntries(agentID), nil
+	entries := cache.GetAuthorizedEntries(agentID)
+	span.SetAttributes(attribute.Int(spanAttrEntryCount, len(entries)))
+	return entries, nil
 }
 
 // RunUpdateCacheTask starts a ticker which rebuilds the in-memory entry cache.
@@ -133,14 +144,20 @@ func (a *AuthorizedEntryFetcherEvents) pruneEvents(ctx context.Context, olderTha
 	return errors.Join(pruneRegistrationEntryEventsErr, pruneAttestedNodeEventsErr)
 }
 
-func (a *AuthorizedEntryFetcherEvents) updateCache(ctx context.Context) error {
+func (a *AuthorizedEntryFetcherEvents) updateCache(ctx context.Context) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "entryfetcher.updateCache")
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	updateRegistrationEntriesCacheErr := a.registrationEntries.updateCache(ctx)
 	updateAttestedNodesCacheErr := a.attestedNodes.updateCache(ctx)
 
 	return errors.Join(updateRegistrationEntriesCacheErr, updateAttestedNodesCacheErr)
 }
 
-func (a *AuthorizedEntryFetcherEvents) buildCache(ctx context.Context) error {
+func (a *AuthorizedEntryFetcherEvents) buildCache(ctx context.Context) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "entryfetcher.buildCache")
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	cache := authorizedentries.NewCache(a.c.clk, a.trustDomain)
 
 	registrationEntries, err := buildRegistrationEntriesCache(ctx, a.c.log, a.c.metrics, a.c.ds, a.c.clk, cache, pageSize, a.c.cacheReloadInterval, a.c.eventTimeout)
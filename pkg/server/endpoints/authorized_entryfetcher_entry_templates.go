@@ -0,0 +1,54 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+)
+
+// Entry templates are kept in the cache as the entries they are sent to the
+// agents as. Creating or deleting an entry template records a registration
+// entry event for its template entry ID, so they are updated along with the
+// registration entries.
+
+// loadEntryTemplates adds all the entry templates to the cache.
+func (a *registrationEntries) loadEntryTemplates(ctx context.Context) error {
+	templates, err := a.ds.ListEntryTemplates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list entry templates: %w", err)
+	}
+
+	for _, template := range templates {
+		entry, err := api.EntryTemplateToProto(template)
+		if err != nil {
+			a.log.WithError(err).WithField(telemetry.EntryTemplateID, template.TemplateId).Warn("Ignoring malformed entry template")
+			continue
+		}
+		a.cache.UpdateEntry(entry)
+	}
+	return nil
+}
+
+// updateCachedEntryTemplate updates or removes the entry the entry template
+// with the given ID is sent to the agents as.
+func (a *registrationEntries) updateCachedEntryTemplate(ctx context.Context, entryID, templateID string) error {
+	template, err := a.ds.FetchEntryTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		a.cache.RemoveEntry(entryID)
+		return nil
+	}
+
+	entry, err := api.EntryTemplateToProto(template)
+	if err != nil {
+		a.cache.RemoveEntry(entryID)
+		a.log.WithError(err).WithField(telemetry.EntryTemplateID, templateID).Warn("Removed malformed entry template from cache")
+		return nil
+	}
+	a.cache.UpdateEntry(entry)
+	return nil
+}
@@ -0,0 +1,77 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/authorizedentries"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryTemplatesCache(t *testing.T) {
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	ds := &noListEntryTemplatesDataStore{DataStore: fakedatastore.New(t)}
+	clk := clock.NewMock(t)
+	cache := authorizedentries.NewCache(clk, "example.org")
+	agentID := spiffeid.RequireFromString("spiffe://example.org/agent")
+
+	createTemplate := func(templateID string) {
+		_, err := ds.CreateEntryTemplate(ctx, &common.EntryTemplate{
+			TemplateId:       templateID,
+			ParentId:         agentID.String(),
+			SpiffeIdTemplate: "spiffe://example.org/ns/{{ .k8s.ns }}",
+			Selectors:        []*common.Selector{{Type: "k8s", Value: "sa:default"}},
+		})
+		require.NoError(t, err)
+	}
+	requireCachedEntries := func(expected ...string) {
+		var entryIDs []string
+		for _, entry := range cache.GetAuthorizedEntries(agentID) {
+			entryIDs = append(entryIDs, entry.GetId())
+		}
+		require.ElementsMatch(t, expected, entryIDs)
+	}
+
+	createTemplate("template1")
+
+	registrationEntries, err := buildRegistrationEntriesCache(ctx, log, fakemetrics.New(), ds, clk, cache, pageSize, defaultCacheReloadInterval, defaultEventTimeout)
+	require.NoError(t, err)
+	requireCachedEntries("template:template1")
+
+	// Entry templates are only listed when the cache is built. Updates follow
+	// the registration entry events recorded for the template entries.
+	ds.failListEntryTemplates = true
+
+	// Entry templates created since the last update are added
+	createTemplate("template2")
+	require.NoError(t, registrationEntries.updateCache(ctx))
+	requireCachedEntries("template:template1", "template:template2")
+
+	// Entry templates deleted since the last update are removed
+	_, err = ds.DeleteEntryTemplate(ctx, "template1")
+	require.NoError(t, err)
+	require.NoError(t, registrationEntries.updateCache(ctx))
+	requireCachedEntries("template:template2")
+}
+
+type noListEntryTemplatesDataStore struct {
+	datastore.DataStore
+
+	failListEntryTemplates bool
+}
+
+func (ds *noListEntryTemplatesDataStore) ListEntryTemplates(ctx context.Context) ([]*common.EntryTemplate, error) {
+	if ds.failListEntryTemplates {
+		return nil, errors.New("entry templates listed on update")
+	}
+	return ds.DataStore.ListEntryTemplates(ctx)
+}
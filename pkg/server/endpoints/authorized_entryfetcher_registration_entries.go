@@ -4,12 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"maps"
-	"slices"
 	"time"
 
 	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	server_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server"
 	"github.com/spiffe/spire/pkg/server/api"
@@ -165,7 +164,7 @@ func (a *registrationEntries) loadCache(ctx context.Context) error {
 			a.cache.UpdateEntry(entry)
 		}
 	}
-	return nil
+	return a.loadEntryTemplates(ctx)
 }
 
 // buildRegistrationEntriesCache Fetches all registration entries and adds them to the cache
@@ -223,7 +222,17 @@ func (a *registrationEntries) updateCache(ctx context.Context) error {
 
 // updateCacheEntry update/deletes/creates an individual registration entry in the cache.
 func (a *registrationEntries) updateCachedEntries(ctx context.Context) error {
-	entryIds := slices.Collect(maps.Keys(a.fetchEntries))
+	var entryIds []string
+	for entryId := range a.fetchEntries {
+		if templateID, ok := entrytemplate.ParseEntryID(entryId); ok {
+			if err := a.updateCachedEntryTemplate(ctx, entryId, templateID); err != nil {
+				return err
+			}
+			delete(a.fetchEntries, entryId)
+			continue
+		}
+		entryIds = append(entryIds, entryId)
+	}
 	for pageStart := 0; pageStart < len(entryIds); pageStart += int(a.pageSize) {
 		fetchEntries := a.fetchEntriesPage(entryIds, pageStart)
 		commonEntries, err := a.ds.FetchRegistrationEntries(ctx, fetchEntries)
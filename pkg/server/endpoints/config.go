@@ -13,13 +13,17 @@ import (
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/server/api"
 	agentv1 "github.com/spiffe/spire/pkg/server/api/agent/v1"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	bundlev1 "github.com/spiffe/spire/pkg/server/api/bundle/v1"
 	debugv1 "github.com/spiffe/spire/pkg/server/api/debug/v1"
 	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
+	entrytemplatev1 "github.com/spiffe/spire/pkg/server/api/entrytemplate/v1"
+	featureflagsv1 "github.com/spiffe/spire/pkg/server/api/featureflags/v1"
 	healthv1 "github.com/spiffe/spire/pkg/server/api/health/v1"
 	localauthorityv1 "github.com/spiffe/spire/pkg/server/api/localauthority/v1"
 	loggerv1 "github.com/spiffe/spire/pkg/server/api/logger/v1"
@@ -73,6 +77,14 @@ type Config struct {
 	// The default (original config) log level
 	LaunchLogLevel logrus.Level
 
+	// FeatureFlagsLoader loads the feature flags from the server
+	// configuration when they are reloaded through the API
+	FeatureFlagsLoader fflag.Loader
+
+	// ServerID identifies this server among the servers sharing the
+	// datastore when recording its feature flags
+	ServerID string
+
 	Metrics telemetry.Metrics
 
 	// RateLimit holds rate limiting configurations.
@@ -99,6 +111,9 @@ type Config struct {
 
 	AuditLogEnabled bool
 
+	// AuditLogSink, if set, receives the audit records in addition to the log
+	AuditLogSink audit.Sink
+
 	// ProxyProtocolTrustedCIDRs is a list of trusted CIDRs for PROXY protocol.
 	// When non-empty, PROXY protocol is enabled and only connections from
 	// these CIDRs are allowed to send PROXY headers.
@@ -164,7 +179,17 @@ func (c *Config) maybeMakeBundleEndpointServer() (Server, func(context.Context)
 	}), certificateReloadTask
 }
 
-func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServers {
+func (c *Config) makeFeatureFlagsServer() *featureflagsv1.Service {
+	return featureflagsv1.New(featureflagsv1.Config{
+		Loader:    c.FeatureFlagsLoader,
+		DataStore: c.Catalog.GetDataStore(),
+		ServerID:  c.ServerID,
+		Log:       c.Log,
+		Clock:     c.Clock,
+	})
+}
+
+func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher, featureFlags *featureflagsv1.Service) APIServers {
 	ds := c.Catalog.GetDataStore()
 	upstreamPublisher := UpstreamPublisher(c.AuthorityManager)
 
@@ -176,6 +201,7 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			Catalog:                 c.Catalog,
 			Clock:                   c.Clock,
 			AgentSpiffeIdAsSelector: c.AgentSpiffeIdAsSelector,
+			FeatureFlags:            featureFlags,
 		}),
 		BundleServer: bundlev1.New(bundlev1.Config{
 			TrustDomain:       c.TrustDomain,
@@ -194,6 +220,11 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			DataStore:    ds,
 			EntryFetcher: entryFetcher,
 		}),
+		EntryTemplatesServer: entrytemplatev1.New(entrytemplatev1.Config{
+			TrustDomain: c.TrustDomain,
+			DataStore:   ds,
+		}),
+		FeatureFlagsServer: featureFlags,
 		HealthServer: healthv1.New(healthv1.Config{
 			TrustDomain: c.TrustDomain,
 			DataStore:   ds,
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
@@ -38,10 +39,13 @@ import (
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/svid"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
 )
 
 const (
@@ -97,7 +101,9 @@ type Endpoints struct {
 	EntryFetcherCacheRebuildTask func(context.Context) error
 	EntryFetcherPruneEventsTask  func(context.Context) error
 	CertificateReloadTask        func(context.Context) error
+	FeatureFlagsReportTask       func(context.Context) error
 	AuditLogEnabled              bool
+	AuditLogSink                 audit.Sink
 	ProxyProtocolTrustedCIDRs    []string
 	AuthPolicyEngine             *authpolicy.Engine
 	AdminIDs                     []spiffeid.ID
@@ -105,6 +111,9 @@ type Endpoints struct {
 	MaxAttestedNodeInfoStaleness time.Duration
 	nodeCache                    api.AttestedNodeCache
 
+	rateLimitersOnce sync.Once
+	rateLimiters     *middleware.RateLimiters
+
 	hooks struct {
 		// test hook used to indicate that is listening
 		listening chan struct{}
@@ -116,6 +125,8 @@ type APIServers struct {
 	BundleServer         bundlev1.BundleServer
 	DebugServer          debugv1_pb.DebugServer
 	EntryServer          entryv1.EntryServer
+	EntryTemplatesServer entrytemplatev1.EntryTemplatesServer
+	FeatureFlagsServer   featureflagsv1.FeatureFlagsServer
 	HealthServer         grpc_health_v1.HealthServer
 	LoggerServer         loggerv1.LoggerServer
 	SVIDServer           svidv1.SVIDServer
@@ -209,6 +220,7 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 	}
 
 	bundleEndpointServer, certificateReloadTask := c.maybeMakeBundleEndpointServer()
+	featureFlags := c.makeFeatureFlagsServer()
 
 	return &Endpoints{
 		TCPAddr:                      c.TCPAddr,
@@ -217,7 +229,7 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		TrustDomain:                  c.TrustDomain,
 		DataStore:                    ds,
 		BundleCache:                  bundle.NewCache(ds, c.Clock),
-		APIServers:                   c.makeAPIServers(ef),
+		APIServers:                   c.makeAPIServers(ef, featureFlags),
 		BundleEndpointServer:         bundleEndpointServer,
 		Log:                          c.Log,
 		Metrics:                      c.Metrics,
@@ -226,7 +238,9 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		EntryFetcherCacheRebuildTask: cacheRebuildTask,
 		EntryFetcherPruneEventsTask:  pruneEventsTask,
 		CertificateReloadTask:        certificateReloadTask,
+		FeatureFlagsReportTask:       featureFlags.ReportServerFeatureFlags,
 		AuditLogEnabled:              c.AuditLogEnabled,
+		AuditLogSink:                 c.AuditLogSink,
 		ProxyProtocolTrustedCIDRs:    c.ProxyProtocolTrustedCIDRs,
 		AuthPolicyEngine:             c.AuthPolicyEngine,
 		AdminIDs:                     c.AdminIDs,
@@ -269,6 +283,8 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 
 	// UDS only
 	loggerv1.RegisterLoggerServer(udsServer, e.APIServers.LoggerServer)
+	entrytemplatev1.RegisterEntryTemplatesServer(udsServer, e.APIServers.EntryTemplatesServer)
+	featureflagsv1.RegisterFeatureFlagsServer(udsServer, e.APIServers.FeatureFlagsServer)
 	grpc_health_v1.RegisterHealthServer(udsServer, e.APIServers.HealthServer)
 	debugv1_pb.RegisterDebugServer(udsServer, e.APIServers.DebugServer)
 
@@ -295,6 +311,10 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 		tasks = append(tasks, e.CertificateReloadTask)
 	}
 
+	if e.FeatureFlagsReportTask != nil {
+		tasks = append(tasks, e.FeatureFlagsReportTask)
+	}
+
 	err := util.RunTasks(ctx, tasks...)
 	if errors.Is(err, context.Canceled) {
 		err = nil
@@ -482,7 +502,21 @@ func (e *Endpoints) getTLSConfig(ctx context.Context) func(*tls.ClientHelloInfo)
 func (e *Endpoints) makeInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
 	log := e.Log.WithField(telemetry.SubsystemName, "api")
 
-	return middleware.Interceptors(Middleware(log, e.Metrics, e.DataStore, e.nodeCache, e.MaxAttestedNodeInfoStaleness, clock.New(), e.RateLimit, e.AuthPolicyEngine, e.AuditLogEnabled, e.AdminIDs))
+	return middleware.Interceptors(Middleware(log, e.Metrics, e.DataStore, e.nodeCache, e.MaxAttestedNodeInfoStaleness, clock.New(), e.getRateLimiters(), e.AuthPolicyEngine, e.AuditLogEnabled, e.AuditLogSink, e.AdminIDs))
+}
+
+// SetRateLimit replaces the rate limits applied to the API calls, e.g. when
+// the rate limiting configuration is reloaded. The state of the current rate
+// limiters is discarded.
+func (e *Endpoints) SetRateLimit(config RateLimitConfig) {
+	e.getRateLimiters().Set(RateLimits(config))
+}
+
+func (e *Endpoints) getRateLimiters() *middleware.RateLimiters {
+	e.rateLimitersOnce.Do(func() {
+		e.rateLimiters = middleware.NewRateLimiters(RateLimits(e.RateLimit))
+	})
+	return e.rateLimiters
 }
 
 func (e *Endpoints) triggerListeningHook() {
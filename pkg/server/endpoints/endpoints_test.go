@@ -35,6 +35,8 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
+	entrytemplatev1 "github.com/spiffe/spire/proto/private/server/entrytemplate"
+	featureflagsv1 "github.com/spiffe/spire/proto/private/server/featureflags"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -119,6 +121,8 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, endpoints.APIServers.BundleServer)
 	assert.NotNil(t, endpoints.APIServers.DebugServer)
 	assert.NotNil(t, endpoints.APIServers.EntryServer)
+	assert.NotNil(t, endpoints.APIServers.EntryTemplatesServer)
+	assert.NotNil(t, endpoints.APIServers.FeatureFlagsServer)
 	assert.NotNil(t, endpoints.APIServers.HealthServer)
 	assert.NotNil(t, endpoints.APIServers.LoggerServer)
 	assert.NotNil(t, endpoints.APIServers.SVIDServer)
@@ -229,6 +233,8 @@ func TestListenAndServe(t *testing.T) {
 			BundleServer:         bundleServer{},
 			DebugServer:          debugServer{},
 			EntryServer:          entryServer{},
+			EntryTemplatesServer: entryTemplatesServer{},
+			FeatureFlagsServer:   featureFlagsServer{},
 			HealthServer:         healthServer{},
 			LoggerServer:         loggerServer{},
 			SVIDServer:           svidServer{},
@@ -348,6 +354,12 @@ func TestListenAndServe(t *testing.T) {
 	t.Run("Logger", func(t *testing.T) {
 		testLoggerAPI(ctx, t, conns)
 	})
+	t.Run("EntryTemplates", func(t *testing.T) {
+		testEntryTemplatesAPI(ctx, t, conns)
+	})
+	t.Run("FeatureFlags", func(t *testing.T) {
+		testFeatureFlagsAPI(ctx, t, conns)
+	})
 	t.Run("Bundle", func(t *testing.T) {
 		testBundleAPI(ctx, t, conns)
 	})
@@ -595,6 +607,65 @@ func testLoggerAPI(ctx context.Context, t *testing.T, conns testConns) {
 	})
 }
 
+func testEntryTemplatesAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, entrytemplatev1.NewEntryTemplatesClient(conns.local), map[string]bool{
+			"CreateEntryTemplate": true,
+			"ListEntryTemplates":  true,
+			"DeleteEntryTemplate": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, entrytemplatev1.NewEntryTemplatesClient(conns.noAuth))
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, entrytemplatev1.NewEntryTemplatesClient(conns.agent))
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, entrytemplatev1.NewEntryTemplatesClient(conns.admin))
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, entrytemplatev1.NewEntryTemplatesClient(conns.federatedAdmin))
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, entrytemplatev1.NewEntryTemplatesClient(conns.downstream))
+	})
+}
+
+func testFeatureFlagsAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, featureflagsv1.NewFeatureFlagsClient(conns.local), map[string]bool{
+			"ListFeatureFlags":   true,
+			"ReloadFeatureFlags": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, featureflagsv1.NewFeatureFlagsClient(conns.noAuth))
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, featureflagsv1.NewFeatureFlagsClient(conns.agent))
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, featureflagsv1.NewFeatureFlagsClient(conns.admin))
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, featureflagsv1.NewFeatureFlagsClient(conns.federatedAdmin))
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		assertServiceUnavailable(ctx, t, featureflagsv1.NewFeatureFlagsClient(conns.downstream))
+	})
+}
+
 func testDebugAPI(ctx context.Context, t *testing.T, conns testConns) {
 	t.Run("Local", func(t *testing.T) {
 		testAuthorization(ctx, t, debugv1.NewDebugClient(conns.local), map[string]bool{
@@ -1406,6 +1477,34 @@ func (loggerServer) ResetLogLevel(context.Context, *loggerv1.ResetLogLevelReques
 	return &types.Logger{}, nil
 }
 
+type entryTemplatesServer struct {
+	entrytemplatev1.UnsafeEntryTemplatesServer
+}
+
+func (entryTemplatesServer) CreateEntryTemplate(context.Context, *entrytemplatev1.CreateEntryTemplateRequest) (*entrytemplatev1.CreateEntryTemplateResponse, error) {
+	return &entrytemplatev1.CreateEntryTemplateResponse{}, nil
+}
+
+func (entryTemplatesServer) ListEntryTemplates(context.Context, *entrytemplatev1.ListEntryTemplatesRequest) (*entrytemplatev1.ListEntryTemplatesResponse, error) {
+	return &entrytemplatev1.ListEntryTemplatesResponse{}, nil
+}
+
+func (entryTemplatesServer) DeleteEntryTemplate(context.Context, *entrytemplatev1.DeleteEntryTemplateRequest) (*entrytemplatev1.DeleteEntryTemplateResponse, error) {
+	return &entrytemplatev1.DeleteEntryTemplateResponse{}, nil
+}
+
+type featureFlagsServer struct {
+	featureflagsv1.UnsafeFeatureFlagsServer
+}
+
+func (featureFlagsServer) ListFeatureFlags(context.Context, *featureflagsv1.ListFeatureFlagsRequest) (*featureflagsv1.ListFeatureFlagsResponse, error) {
+	return &featureflagsv1.ListFeatureFlagsResponse{}, nil
+}
+
+func (featureFlagsServer) ReloadFeatureFlags(context.Context, *featureflagsv1.ReloadFeatureFlagsRequest) (*featureflagsv1.ReloadFeatureFlagsResponse, error) {
+	return &featureflagsv1.ReloadFeatureFlagsResponse{}, nil
+}
+
 type svidServer struct {
 	svidv1.UnsafeSVIDServer
 }
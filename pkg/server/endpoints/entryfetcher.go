@@ -9,9 +9,12 @@ import (
 	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ api.AuthorizedEntryFetcher = (*AuthorizedEntryFetcherWithFullCache)(nil)
@@ -49,21 +52,45 @@ func NewAuthorizedEntryFetcherWithFullCache(ctx context.Context, buildCache entr
 }
 
 func (a *AuthorizedEntryFetcherWithFullCache) LookupAuthorizedEntries(ctx context.Context, agentID spiffeid.ID, entryIDs map[string]struct{}) (map[string]api.ReadOnlyEntry, error) {
+	_, span := startEntryFetcherSpan(ctx, "LookupAuthorizedEntries", agentID)
+	defer span.End()
+
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.cache.LookupAuthorizedEntries(agentID, entryIDs), nil
+	entries := a.cache.LookupAuthorizedEntries(agentID, entryIDs)
+	span.SetAttributes(attribute.Int(spanAttrEntryCount, len(entries)))
+	return entries, nil
 }
 
-func (a *AuthorizedEntryFetcherWithFullCache) FetchAuthorizedEntries(_ context.Context, agentID spiffeid.ID) ([]api.ReadOnlyEntry, error) {
+func (a *AuthorizedEntryFetcherWithFullCache) FetchAuthorizedEntries(ctx context.Context, agentID spiffeid.ID) ([]api.ReadOnlyEntry, error) {
+	_, span := startEntryFetcherSpan(ctx, "FetchAuthorizedEntries", agentID)
+	defer span.End()
+
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.cache.GetAuthorizedEntries(agentID), nil
+	entries := a.cache.GetAuthorizedEntries(agentID)
+	span.SetAttributes(attribute.Int(spanAttrEntryCount, len(entries)))
+	return entries, nil
+}
+
+const (
+	spanAttrAgentID    = "spire.agent_id"
+	spanAttrEntryCount = "spire.entry_count"
+)
+
+// startEntryFetcherSpan starts a span for a call to an authorized entry
+// fetcher on behalf of the given agent.
+func startEntryFetcherSpan(ctx context.Context, method string, agentID spiffeid.ID) (context.Context, trace.Span) {
+	return telemetry.StartSpan(ctx, "entryfetcher."+method,
+		trace.WithAttributes(attribute.String(spanAttrAgentID, agentID.String())))
 }
 
 // RunRebuildCacheTask starts a ticker which rebuilds the in-memory entry cache.
 func (a *AuthorizedEntryFetcherWithFullCache) RunRebuildCacheTask(ctx context.Context) error {
 	rebuild := func() {
+		ctx, span := telemetry.StartSpan(ctx, "entryfetcher.buildCache")
 		cache, err := a.buildCache(ctx)
+		telemetry.EndSpan(span, err)
 		if err != nil {
 			a.log.WithError(err).Error("Failed to reload entry cache")
 		} else {
@@ -17,6 +17,10 @@ import (
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var (
@@ -118,6 +122,52 @@ func TestFetchRegistrationEntries(t *testing.T) {
 	assert.Equal(t, expected, entriesFromReadOnlyEntries(entries))
 }
 
+func TestFetchRegistrationEntriesTracing(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+	agentID := spiffeid.RequireFromPath(trustDomain, "/root")
+	expected := setupExpectedEntriesData(t, agentID)
+
+	buildCacheFn := func(ctx context.Context) (entrycache.Cache, error) {
+		return newStaticEntryCache(map[spiffeid.ID][]*types.Entry{agentID: expected}), nil
+	}
+
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, log, clk, ds, defaultCacheReloadInterval, defaultPruneEventsOlderThan)
+	require.NoError(t, err)
+
+	parentCtx, parent := otel.Tracer("test").Start(ctx, "caller")
+	_, err = ef.FetchAuthorizedEntries(parentCtx, agentID)
+	require.NoError(t, err)
+	_, err = ef.LookupAuthorizedEntries(parentCtx, agentID, map[string]struct{}{expected[0].Id: {}})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for i, tt := range []struct {
+		name  string
+		count int
+	}{
+		{name: "entryfetcher.FetchAuthorizedEntries", count: len(expected)},
+		{name: "entryfetcher.LookupAuthorizedEntries", count: 1},
+	} {
+		span := spans[i]
+		assert.Equal(t, tt.name, span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			attribute.String("spire.agent_id", agentID.String()),
+			attribute.Int("spire.entry_count", tt.count),
+		}, span.Attributes())
+	}
+}
+
 func TestRunRebuildCacheTask(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	watchErr := make(chan error, 1)
@@ -11,6 +11,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/errorutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/pkg/server/api/bundle/v1"
 	"github.com/spiffe/spire/pkg/server/api/limits"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
@@ -24,17 +25,18 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, nodeCache api.AttestedNodeCache, maxAttestedNodeInfoStaleness time.Duration, clk clock.Clock, rlConf RateLimitConfig, policyEngine *authpolicy.Engine, auditLogEnabled bool, adminIDs []spiffeid.ID) middleware.Middleware {
+func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, nodeCache api.AttestedNodeCache, maxAttestedNodeInfoStaleness time.Duration, clk clock.Clock, rateLimiters *middleware.RateLimiters, policyEngine *authpolicy.Engine, auditLogEnabled bool, auditLogSink audit.Sink, adminIDs []spiffeid.ID) middleware.Middleware {
 	chain := []middleware.Middleware{
+		middleware.WithTracing(),
 		middleware.WithLogger(log),
 		middleware.WithMetrics(metrics),
 		middleware.WithAuthorization(policyEngine, EntryFetcher(ds), AgentAuthorizer(ds, nodeCache, maxAttestedNodeInfoStaleness, clk), adminIDs),
-		middleware.WithRateLimits(RateLimits(rlConf), metrics),
+		middleware.WithRateLimiters(rateLimiters, metrics),
 	}
 
 	if auditLogEnabled {
 		// Add audit log with local tracking enabled
-		chain = append(chain, middleware.WithAuditLog(true))
+		chain = append(chain, middleware.WithAuditLogSink(true, auditLogSink))
 	}
 
 	return middleware.Chain(
@@ -54,8 +56,8 @@ func EntryFetcher(ds datastore.DataStore) middleware.EntryFetcher {
 	})
 }
 
-func UpstreamPublisher(jwtKeyPublisher manager.JwtKeyPublisher) bundle.UpstreamPublisher {
-	return bundle.UpstreamPublisherFunc(jwtKeyPublisher.PublishJWTKey)
+func UpstreamPublisher(keyPublisher manager.KeyPublisher) bundle.UpstreamPublisher {
+	return keyPublisher
 }
 
 func AgentAuthorizer(ds datastore.DataStore, nodeCache api.AttestedNodeCache, maxAttestedNodeInfoStaleness time.Duration, clk clock.Clock) middleware.AgentAuthorizer {
@@ -183,6 +185,11 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.logger.v1.Logger/GetLogger":                                   noLimit,
 		"/spire.api.server.logger.v1.Logger/SetLogLevel":                                 noLimit,
 		"/spire.api.server.logger.v1.Logger/ResetLogLevel":                               noLimit,
+		"/spire.private.server.entrytemplate.EntryTemplates/CreateEntryTemplate":         noLimit,
+		"/spire.private.server.entrytemplate.EntryTemplates/ListEntryTemplates":          noLimit,
+		"/spire.private.server.entrytemplate.EntryTemplates/DeleteEntryTemplate":         noLimit,
+		"/spire.private.server.featureflags.FeatureFlags/ListFeatureFlags":               noLimit,
+		"/spire.private.server.featureflags.FeatureFlags/ReloadFeatureFlags":             noLimit,
 		"/spire.api.server.agent.v1.Agent/CountAgents":                                   noLimit,
 		"/spire.api.server.agent.v1.Agent/ListAgents":                                    noLimit,
 		"/spire.api.server.agent.v1.Agent/GetAgent":                                      noLimit,
@@ -20,6 +20,14 @@ type CredentialComposer interface {
 	ComposeWorkloadJWTSVID(ctx context.Context, id spiffeid.ID, attributes JWTSVIDAttributes) (JWTSVIDAttributes, error)
 }
 
+// WITSVIDComposer is implemented by the CredentialComposer plugins serving the
+// optional WITSVIDComposer service.
+type WITSVIDComposer interface {
+	catalog.PluginInfo
+
+	ComposeWorkloadWITSVID(ctx context.Context, id spiffeid.ID, attributes WITSVIDAttributes) (WITSVIDAttributes, error)
+}
+
 type X509CAAttributes struct {
 	Subject         pkix.Name
 	Policies        []x509.OID
@@ -35,3 +43,7 @@ type X509SVIDAttributes struct {
 type JWTSVIDAttributes struct {
 	Claims map[string]any
 }
+
+type WITSVIDAttributes struct {
+	Claims map[string]any
+}
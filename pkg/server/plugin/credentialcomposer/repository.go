@@ -2,6 +2,7 @@ package credentialcomposer
 
 type Repository struct {
 	CredentialComposers []CredentialComposer
+	WITSVIDComposers    []WITSVIDComposer
 }
 
 func (repo *Repository) GetCredentialComposers() []CredentialComposer {
@@ -12,6 +13,19 @@ func (repo *Repository) AddCredentialComposer(credentialComposer CredentialCompo
 	repo.CredentialComposers = append(repo.CredentialComposers, credentialComposer)
 }
 
+func (repo *Repository) GetWITSVIDComposers() []WITSVIDComposer {
+	return repo.WITSVIDComposers
+}
+
+func (repo *Repository) AddWITSVIDComposer(witSVIDComposer WITSVIDComposer) {
+	repo.WITSVIDComposers = append(repo.WITSVIDComposers, witSVIDComposer)
+}
+
+func (repo *Repository) ClearWITSVIDComposers() {
+	repo.WITSVIDComposers = nil
+}
+
 func (repo *Repository) Clear() {
 	repo.CredentialComposers = nil
+	repo.WITSVIDComposers = nil
 }
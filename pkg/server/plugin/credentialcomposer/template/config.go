@@ -0,0 +1,218 @@
+package template
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/spire/pkg/common/agentpathtemplate"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+)
+
+var (
+	// reservedJWTSVIDClaims are the claims set by the server that cannot be
+	// templated.
+	reservedJWTSVIDClaims = []string{"sub", "aud", "exp", "iat", "iss", "jti", "nbf"}
+
+	// reservedWITSVIDClaims are the claims set by the server that cannot be
+	// templated.
+	reservedWITSVIDClaims = []string{"sub", "cnf", "exp", "iat", "iss", "nbf"}
+)
+
+// Config holds the configuration of the plugin.
+type Config struct {
+	X509SVID *X509SVIDConfig `hcl:"x509_svid" json:"x509_svid"`
+	JWTSVID  *ClaimsConfig   `hcl:"jwt_svid" json:"jwt_svid"`
+	WITSVID  *ClaimsConfig   `hcl:"wit_svid" json:"wit_svid"`
+}
+
+// X509SVIDConfig holds the templates for workload X509-SVIDs.
+type X509SVIDConfig struct {
+	DNSNames   []string                   `hcl:"dns_names" json:"dns_names"`
+	Subject    *SubjectConfig             `hcl:"subject" json:"subject"`
+	Extensions map[string]ExtensionConfig `hcl:"extension" json:"extension"`
+}
+
+// SubjectConfig holds the templates for the subject of workload X509-SVIDs.
+type SubjectConfig struct {
+	CommonName         string   `hcl:"common_name" json:"common_name"`
+	Country            []string `hcl:"country" json:"country"`
+	Organization       []string `hcl:"organization" json:"organization"`
+	OrganizationalUnit []string `hcl:"organizational_unit" json:"organizational_unit"`
+	Locality           []string `hcl:"locality" json:"locality"`
+	Province           []string `hcl:"province" json:"province"`
+}
+
+// ExtensionConfig holds the template for an extension of workload
+// X509-SVIDs. Extensions are keyed by their OID.
+type ExtensionConfig struct {
+	Value    string `hcl:"value" json:"value"`
+	Critical bool   `hcl:"critical" json:"critical"`
+}
+
+// ClaimsConfig holds the templates for the claims of workload JWT-SVIDs or
+// WIT-SVIDs.
+type ClaimsConfig struct {
+	Claims map[string]string `hcl:"claims" json:"claims"`
+}
+
+// templates holds the parsed templates of the configuration.
+type templates struct {
+	x509SVID *x509SVIDTemplates
+	jwtSVID  map[string]*agentpathtemplate.Template
+	witSVID  map[string]*agentpathtemplate.Template
+}
+
+type x509SVIDTemplates struct {
+	dnsNames   []*agentpathtemplate.Template
+	subject    *subjectTemplates
+	extensions []extensionTemplate
+}
+
+type subjectTemplates struct {
+	commonName         *agentpathtemplate.Template
+	country            []*agentpathtemplate.Template
+	organization       []*agentpathtemplate.Template
+	organizationalUnit []*agentpathtemplate.Template
+	locality           []*agentpathtemplate.Template
+	province           []*agentpathtemplate.Template
+}
+
+type extensionTemplate struct {
+	oid      string
+	value    *agentpathtemplate.Template
+	critical bool
+}
+
+func buildConfig(_ catalog.CoreConfig, hclText string, status *pluginconf.Status) *templates {
+	newConfig := new(Config)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if newConfig.X509SVID == nil && newConfig.JWTSVID == nil && newConfig.WITSVID == nil {
+		status.ReportInfo("No templates configured, credentials will not be modified")
+	}
+
+	p := &parser{status: status}
+	t := &templates{
+		x509SVID: p.parseX509SVID(newConfig.X509SVID),
+		jwtSVID:  p.parseClaims("jwt_svid", newConfig.JWTSVID, reservedJWTSVIDClaims),
+		witSVID:  p.parseClaims("wit_svid", newConfig.WITSVID, reservedWITSVIDClaims),
+	}
+	if p.failed {
+		return nil
+	}
+	return t
+}
+
+// parser parses the templates of the configuration, reporting the errors to
+// the status.
+type parser struct {
+	status *pluginconf.Status
+	failed bool
+}
+
+func (p *parser) reportErrorf(format string, args ...any) {
+	p.status.ReportErrorf(format, args...)
+	p.failed = true
+}
+
+func (p *parser) parse(key, text string) *agentpathtemplate.Template {
+	tmpl, err := agentpathtemplate.Parse(text)
+	if err != nil {
+		p.reportErrorf("unable to parse %s template: %v", key, err)
+		return nil
+	}
+	return tmpl
+}
+
+func (p *parser) parseList(key string, texts []string) []*agentpathtemplate.Template {
+	var tmpls []*agentpathtemplate.Template
+	for i, text := range texts {
+		tmpls = append(tmpls, p.parse(fmt.Sprintf("%s[%d]", key, i), text))
+	}
+	return tmpls
+}
+
+func (p *parser) parseX509SVID(config *X509SVIDConfig) *x509SVIDTemplates {
+	if config == nil {
+		return nil
+	}
+
+	t := &x509SVIDTemplates{
+		dnsNames: p.parseList("x509_svid.dns_names", config.DNSNames),
+	}
+
+	if subject := config.Subject; subject != nil {
+		t.subject = &subjectTemplates{
+			country:            p.parseList("x509_svid.subject.country", subject.Country),
+			organization:       p.parseList("x509_svid.subject.organization", subject.Organization),
+			organizationalUnit: p.parseList("x509_svid.subject.organizational_unit", subject.OrganizationalUnit),
+			locality:           p.parseList("x509_svid.subject.locality", subject.Locality),
+			province:           p.parseList("x509_svid.subject.province", subject.Province),
+		}
+		if subject.CommonName != "" {
+			t.subject.commonName = p.parse("x509_svid.subject.common_name", subject.CommonName)
+		}
+	}
+
+	for _, oid := range slices.Sorted(maps.Keys(config.Extensions)) {
+		if err := validateOID(oid); err != nil {
+			p.reportErrorf("invalid x509_svid.extension oid %q: %v", oid, err)
+			continue
+		}
+		extension := config.Extensions[oid]
+		t.extensions = append(t.extensions, extensionTemplate{
+			oid:      oid,
+			value:    p.parse(fmt.Sprintf("x509_svid.extension %q value", oid), extension.Value),
+			critical: extension.Critical,
+		})
+	}
+
+	return t
+}
+
+func (p *parser) parseClaims(key string, config *ClaimsConfig, reserved []string) map[string]*agentpathtemplate.Template {
+	if config == nil {
+		return nil
+	}
+
+	t := make(map[string]*agentpathtemplate.Template)
+	for name, text := range config.Claims {
+		if slices.Contains(reserved, name) {
+			p.reportErrorf("%s claim %q is set by the server and cannot be templated", key, name)
+			continue
+		}
+		t[name] = p.parse(fmt.Sprintf("%s.claims.%s", key, name), text)
+	}
+	return t
+}
+
+// validateOID validates that the OID is in dotted-decimal form (e.g.
+// "1.2.3.4").
+func validateOID(s string) error {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return errors.New("must have at least two arcs")
+	}
+	oid := make(asn1.ObjectIdentifier, 0, len(parts))
+	for _, part := range parts {
+		arc, err := strconv.Atoi(part)
+		if err != nil || arc < 0 {
+			return fmt.Errorf("arc %q is not a non-negative integer", part)
+		}
+		oid = append(oid, arc)
+	}
+	if _, err := asn1.Marshal(oid); err != nil {
+		return err
+	}
+	return nil
+}
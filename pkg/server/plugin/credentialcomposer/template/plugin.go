@@ -0,0 +1,320 @@
+package template
+
+import (
+	"context"
+	"encoding/asn1"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	credentialcomposerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/credentialcomposer/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/agentpathtemplate"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/proto/private/server/witsvidcomposer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	pluginName = "template"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtIn(New())
+}
+
+func builtIn(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		credentialcomposerv1.CredentialComposerPluginServer(p),
+		configv1.ConfigServiceServer(p),
+		credentialcomposer.WITSVIDComposerServiceServer(p),
+	)
+}
+
+// Plugin composes workload credentials from templates executed over the
+// SPIFFE ID of the workload and the attributes of the credential.
+type Plugin struct {
+	credentialcomposerv1.UnsafeCredentialComposerServer
+	configv1.UnsafeConfigServer
+	witsvidcomposer.UnsafeWITSVIDComposerServer
+
+	log hclog.Logger
+
+	mtx       sync.RWMutex
+	templates *templates
+}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+// SetLogger sets a logger in the plugin.
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+// Configure configures the plugin.
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newTemplates, notes, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		p.log.Warn(note)
+	}
+
+	p.mtx.Lock()
+	p.templates = newTemplates
+	p.mtx.Unlock()
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+// Validate validates the configuration of the plugin.
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *Plugin) ComposeServerX509CA(context.Context, *credentialcomposerv1.ComposeServerX509CARequest) (*credentialcomposerv1.ComposeServerX509CAResponse, error) {
+	// Intentionally not implemented.
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (p *Plugin) ComposeServerX509SVID(context.Context, *credentialcomposerv1.ComposeServerX509SVIDRequest) (*credentialcomposerv1.ComposeServerX509SVIDResponse, error) {
+	// Intentionally not implemented.
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (p *Plugin) ComposeAgentX509SVID(context.Context, *credentialcomposerv1.ComposeAgentX509SVIDRequest) (*credentialcomposerv1.ComposeAgentX509SVIDResponse, error) {
+	// Intentionally not implemented.
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (p *Plugin) ComposeWorkloadX509SVID(_ context.Context, req *credentialcomposerv1.ComposeWorkloadX509SVIDRequest) (*credentialcomposerv1.ComposeWorkloadX509SVIDResponse, error) {
+	t, err := p.getTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if t.x509SVID == nil || req.Attributes == nil {
+		return &credentialcomposerv1.ComposeWorkloadX509SVIDResponse{}, nil
+	}
+
+	data, err := newTemplateData(req.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+	data.DNSNames = req.Attributes.DnsSans
+
+	attributes := req.Attributes
+	for i, tmpl := range t.x509SVID.dnsNames {
+		dnsName, err := execute(tmpl, data, "x509_svid.dns_names", i)
+		if err != nil {
+			return nil, err
+		}
+		if dnsName != "" && !slices.Contains(attributes.DnsSans, dnsName) {
+			attributes.DnsSans = append(attributes.DnsSans, dnsName)
+		}
+	}
+
+	if subject := t.x509SVID.subject; subject != nil {
+		if attributes.Subject == nil {
+			attributes.Subject = &credentialcomposerv1.DistinguishedName{}
+		}
+		if subject.commonName != nil {
+			commonName, err := execute(subject.commonName, data, "x509_svid.subject.common_name", -1)
+			if err != nil {
+				return nil, err
+			}
+			if commonName != "" {
+				attributes.Subject.CommonName = commonName
+			}
+		}
+		for _, field := range []struct {
+			key    string
+			tmpls  []*agentpathtemplate.Template
+			values *[]string
+		}{
+			{key: "x509_svid.subject.country", tmpls: subject.country, values: &attributes.Subject.Country},
+			{key: "x509_svid.subject.organization", tmpls: subject.organization, values: &attributes.Subject.Organization},
+			{key: "x509_svid.subject.organizational_unit", tmpls: subject.organizationalUnit, values: &attributes.Subject.OrganizationalUnit},
+			{key: "x509_svid.subject.locality", tmpls: subject.locality, values: &attributes.Subject.Locality},
+			{key: "x509_svid.subject.province", tmpls: subject.province, values: &attributes.Subject.Province},
+		} {
+			for i, tmpl := range field.tmpls {
+				value, err := execute(tmpl, data, field.key, i)
+				if err != nil {
+					return nil, err
+				}
+				if value != "" && !slices.Contains(*field.values, value) {
+					*field.values = append(*field.values, value)
+				}
+			}
+		}
+	}
+
+	for _, extension := range t.x509SVID.extensions {
+		key := fmt.Sprintf("x509_svid.extension %q value", extension.oid)
+		value, err := execute(extension.value, data, key, -1)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+		der, err := asn1.MarshalWithParams(value, "utf8")
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode %s: %v", key, err)
+		}
+		attributes.ExtraExtensions = slices.DeleteFunc(attributes.ExtraExtensions, func(e *credentialcomposerv1.X509Extension) bool {
+			return e.Oid == extension.oid
+		})
+		attributes.ExtraExtensions = append(attributes.ExtraExtensions, &credentialcomposerv1.X509Extension{
+			Oid:      extension.oid,
+			Value:    der,
+			Critical: extension.critical,
+		})
+	}
+
+	return &credentialcomposerv1.ComposeWorkloadX509SVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) ComposeWorkloadJWTSVID(_ context.Context, req *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest) (*credentialcomposerv1.ComposeWorkloadJWTSVIDResponse, error) {
+	t, err := p.getTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if len(t.jwtSVID) == 0 || req.Attributes == nil {
+		return &credentialcomposerv1.ComposeWorkloadJWTSVIDResponse{}, nil
+	}
+
+	attributes := req.Attributes
+	attributes.Claims, err = composeClaims(req.SpiffeId, attributes.Claims, t.jwtSVID, "jwt_svid")
+	if err != nil {
+		return nil, err
+	}
+	return &credentialcomposerv1.ComposeWorkloadJWTSVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+// ComposeWorkloadWITSVID composes the claims of workload WIT-SVIDs. It is
+// served through the SPIRE-internal WITSVIDComposer service.
+func (p *Plugin) ComposeWorkloadWITSVID(_ context.Context, req *witsvidcomposer.ComposeWorkloadWITSVIDRequest) (*witsvidcomposer.ComposeWorkloadWITSVIDResponse, error) {
+	t, err := p.getTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if len(t.witSVID) == 0 || req.Attributes == nil {
+		return &witsvidcomposer.ComposeWorkloadWITSVIDResponse{}, nil
+	}
+
+	attributes := req.Attributes
+	attributes.Claims, err = composeClaims(req.SpiffeId, attributes.Claims, t.witSVID, "wit_svid")
+	if err != nil {
+		return nil, err
+	}
+	return &witsvidcomposer.ComposeWorkloadWITSVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) getTemplates() (*templates, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	if p.templates == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.templates, nil
+}
+
+// composeClaims adds the templated claims to the given claims.
+func composeClaims(rawID string, claims *structpb.Struct, tmpls map[string]*agentpathtemplate.Template, key string) (*structpb.Struct, error) {
+	data, err := newTemplateData(rawID)
+	if err != nil {
+		return nil, err
+	}
+	data.Claims = claims.AsMap()
+
+	if claims == nil {
+		claims = &structpb.Struct{}
+	}
+	if claims.Fields == nil {
+		claims.Fields = make(map[string]*structpb.Value)
+	}
+	for name, tmpl := range tmpls {
+		value, err := execute(tmpl, data, key+".claims."+name, -1)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			claims.Fields[name] = structpb.NewStringValue(value)
+		}
+	}
+	return claims, nil
+}
+
+// templateData is the data the templates are executed with. It does not
+// include the metadata of the registration entry (e.g. its selectors or
+// hint), since the requests of the credential composer services only carry
+// the SPIFFE ID and the attributes of the credential. WIT-SVIDs minted
+// through the SVID API are not even backed by an entry.
+type templateData struct {
+	// SPIFFEID is the SPIFFE ID of the workload.
+	SPIFFEID string
+	// TrustDomain is the trust domain name of the SPIFFE ID.
+	TrustDomain string
+	// Path is the path of the SPIFFE ID.
+	Path string
+	// PathSegments are the segments of the path of the SPIFFE ID.
+	PathSegments []string
+	// DNSNames are the DNS names of the registration entry. Only set for
+	// X509-SVIDs.
+	DNSNames []string
+	// Claims are the claims of the credential as built by the server and the
+	// credential composers that ran before. Only set for JWT-SVIDs and
+	// WIT-SVIDs.
+	Claims map[string]any
+}
+
+func newTemplateData(rawID string) (*templateData, error) {
+	id, err := spiffeid.FromString(rawID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed SPIFFE ID: %v", err)
+	}
+	data := &templateData{
+		SPIFFEID:    id.String(),
+		TrustDomain: id.TrustDomain().Name(),
+		Path:        id.Path(),
+	}
+	if path := strings.TrimPrefix(id.Path(), "/"); path != "" {
+		data.PathSegments = strings.Split(path, "/")
+	}
+	return data, nil
+}
+
+// execute executes the template. The index, if not negative, identifies the
+// template within a list in error messages.
+func execute(tmpl *agentpathtemplate.Template, data *templateData, key string, index int) (string, error) {
+	value, err := tmpl.Execute(data)
+	if err != nil {
+		if index >= 0 {
+			return "", status.Errorf(codes.Internal, "failed to execute %s[%d] template: %v", key, index, err)
+		}
+		return "", status.Errorf(codes.Internal, "failed to execute %s template: %v", key, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,251 @@
+package template_test
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer/template"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	td  = spiffeid.RequireTrustDomainFromString("example.org")
+	id  = spiffeid.RequireFromString("spiffe://example.org/ns/payments/sa/api")
+	key = testkey.MustEC256()
+	ctx = context.Background()
+)
+
+const config = `
+x509_svid {
+	dns_names = [
+		"{{ index .PathSegments 3 }}.{{ index .PathSegments 1 }}.svc",
+		"{{ if has \"api.example.org\" .DNSNames }}api.internal{{ end }}",
+	]
+	subject {
+		common_name = "{{ index .PathSegments 3 }}"
+		organization = ["{{ .TrustDomain }}"]
+		organizational_unit = ["{{ index .PathSegments 1 }}"]
+	}
+	extension "1.3.6.1.4.1.99999.1" {
+		value = "{{ .SPIFFEID }}"
+	}
+	extension "1.3.6.1.4.1.99999.2" {
+		value = "{{ .Path }}"
+		critical = true
+	}
+}
+
+jwt_svid {
+	claims = {
+		namespace = "{{ index .PathSegments 1 }}"
+		audience = "{{ index .Claims.aud 0 }}"
+	}
+}
+
+wit_svid {
+	claims = {
+		namespace = "{{ index .PathSegments 1 }}"
+		subject = "{{ .Claims.sub }}"
+	}
+}
+`
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		config    string
+		expectErr string
+	}{
+		{
+			name:   "no templates",
+			config: "",
+		},
+		{
+			name:   "all templates",
+			config: config,
+		},
+		{
+			name:      "malformed template",
+			config:    `x509_svid { dns_names = ["{{ .TrustDomain "] }`,
+			expectErr: "unable to parse x509_svid.dns_names[0] template",
+		},
+		{
+			name:      "reserved JWT-SVID claim",
+			config:    `jwt_svid { claims = { aud = "{{ .TrustDomain }}" } }`,
+			expectErr: `jwt_svid claim "aud" is set by the server and cannot be templated`,
+		},
+		{
+			name:      "reserved WIT-SVID claim",
+			config:    `wit_svid { claims = { cnf = "{{ .TrustDomain }}" } }`,
+			expectErr: `wit_svid claim "cnf" is set by the server and cannot be templated`,
+		},
+		{
+			name:      "malformed extension OID",
+			config:    `x509_svid { extension "1.two.3" { value = "v" } }`,
+			expectErr: `invalid x509_svid.extension oid "1.two.3": arc "two" is not a non-negative integer`,
+		},
+		{
+			name:      "malformed extension value",
+			config:    `x509_svid { extension "1.2.3" { value = "{{ .Path" } }`,
+			expectErr: `unable to parse x509_svid.extension "1.2.3" value template`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			plugintest.Load(t, template.BuiltIn(), new(credentialcomposer.V1),
+				plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+				plugintest.Configure(tt.config),
+				plugintest.CaptureConfigureError(&err),
+			)
+			if tt.expectErr != "" {
+				spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	cc := new(credentialcomposer.V1)
+	plugintest.Load(t, template.BuiltIn(), cc)
+
+	_, err := cc.ComposeWorkloadJWTSVID(ctx, id, credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"sub": id.String()}})
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "credentialcomposer(template): not configured")
+}
+
+func TestPlugin(t *testing.T) {
+	cc, witSVIDComposer := loadPlugin(t, config)
+
+	t.Run("ComposeServerX509CA", func(t *testing.T) {
+		want := credentialcomposer.X509CAAttributes{}
+		got, err := cc.ComposeServerX509CA(ctx, want)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("ComposeServerX509SVID", func(t *testing.T) {
+		want := credentialcomposer.X509SVIDAttributes{}
+		got, err := cc.ComposeServerX509SVID(ctx, want)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("ComposeAgentX509SVID", func(t *testing.T) {
+		want := credentialcomposer.X509SVIDAttributes{}
+		got, err := cc.ComposeAgentX509SVID(ctx, id, key.Public(), want)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("ComposeWorkloadX509SVID", func(t *testing.T) {
+		got, err := cc.ComposeWorkloadX509SVID(ctx, id, key.Public(), credentialcomposer.X509SVIDAttributes{
+			Subject:  pkix.Name{Organization: []string{"Acme"}},
+			DNSNames: []string{"api.example.org"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, credentialcomposer.X509SVIDAttributes{
+			Subject: pkix.Name{
+				CommonName:         "api",
+				Organization:       []string{"Acme", "example.org"},
+				OrganizationalUnit: []string{"payments"},
+			},
+			DNSNames: []string{"api.example.org", "api.payments.svc", "api.internal"},
+			ExtraExtensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, Value: utf8String(t, id.String())},
+				{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 2}, Value: utf8String(t, id.Path()), Critical: true},
+			},
+		}, got)
+	})
+
+	t.Run("ComposeWorkloadX509SVID skips empty values", func(t *testing.T) {
+		got, err := cc.ComposeWorkloadX509SVID(ctx, id, key.Public(), credentialcomposer.X509SVIDAttributes{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api.payments.svc"}, got.DNSNames)
+	})
+
+	t.Run("ComposeWorkloadX509SVID fails to execute template", func(t *testing.T) {
+		_, err := cc.ComposeWorkloadX509SVID(ctx, spiffeid.RequireFromString("spiffe://example.org/short"), key.Public(), credentialcomposer.X509SVIDAttributes{})
+		spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "failed to execute x509_svid.dns_names[0] template")
+	})
+
+	t.Run("ComposeWorkloadJWTSVID", func(t *testing.T) {
+		got, err := cc.ComposeWorkloadJWTSVID(ctx, id, credentialcomposer.JWTSVIDAttributes{
+			Claims: map[string]any{
+				"sub": id.String(),
+				"aud": []string{"billing"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, credentialcomposer.JWTSVIDAttributes{
+			Claims: map[string]any{
+				"sub":       id.String(),
+				"aud":       []any{"billing"},
+				"namespace": "payments",
+				"audience":  "billing",
+			},
+		}, got)
+	})
+
+	t.Run("ComposeWorkloadWITSVID", func(t *testing.T) {
+		got, err := witSVIDComposer.ComposeWorkloadWITSVID(ctx, id, credentialcomposer.WITSVIDAttributes{
+			Claims: map[string]any{
+				"sub": id.String(),
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, credentialcomposer.WITSVIDAttributes{
+			Claims: map[string]any{
+				"sub":       id.String(),
+				"namespace": "payments",
+				"subject":   id.String(),
+			},
+		}, got)
+	})
+}
+
+func TestPluginWithoutTemplates(t *testing.T) {
+	cc, witSVIDComposer := loadPlugin(t, "")
+
+	x509SVIDAttributes := credentialcomposer.X509SVIDAttributes{DNSNames: []string{"api.example.org"}}
+	gotX509SVIDAttributes, err := cc.ComposeWorkloadX509SVID(ctx, id, key.Public(), x509SVIDAttributes)
+	require.NoError(t, err)
+	assert.Equal(t, x509SVIDAttributes, gotX509SVIDAttributes)
+
+	jwtSVIDAttributes := credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"sub": id.String()}}
+	gotJWTSVIDAttributes, err := cc.ComposeWorkloadJWTSVID(ctx, id, jwtSVIDAttributes)
+	require.NoError(t, err)
+	assert.Equal(t, jwtSVIDAttributes, gotJWTSVIDAttributes)
+
+	witSVIDAttributes := credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"sub": id.String()}}
+	gotWITSVIDAttributes, err := witSVIDComposer.ComposeWorkloadWITSVID(ctx, id, witSVIDAttributes)
+	require.NoError(t, err)
+	assert.Equal(t, witSVIDAttributes, gotWITSVIDAttributes)
+}
+
+func loadPlugin(t *testing.T, config string) (credentialcomposer.CredentialComposer, credentialcomposer.WITSVIDComposer) {
+	cc := new(credentialcomposer.V1)
+	witSVIDComposer := new(credentialcomposer.WITSVIDComposerV1)
+	plugintest.Load(t, template.BuiltIn(), cc,
+		plugintest.Services(witSVIDComposer),
+		plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+		plugintest.Configure(config),
+	)
+	return cc, witSVIDComposer
+}
+
+func utf8String(t *testing.T, s string) []byte {
+	der, err := asn1.MarshalWithParams(s, "utf8")
+	require.NoError(t, err)
+	return der
+}
@@ -219,21 +219,29 @@ type jwtSVIDAttributesResponseV1 interface {
 }
 
 func jwtSVIDAttributesToV1(attributes JWTSVIDAttributes) (*credentialcomposerv1.JWTSVIDAttributes, error) {
-	if len(attributes.Claims) == 0 {
+	claims, err := claimsToV1(attributes.Claims)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialcomposerv1.JWTSVIDAttributes{
+		Claims: claims,
+	}, nil
+}
+
+func claimsToV1(claims map[string]any) (*structpb.Struct, error) {
+	if len(claims) == 0 {
 		return nil, errors.New("invalid claims: cannot be empty")
 	}
 	// structpb.NewValue cannot handle Go types such as jwt.NumericDate so we marshal them into their JSON representation first
-	jsonClaims, err := json.Marshal(attributes.Claims)
+	jsonClaims, err := json.Marshal(claims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal claims: %w", err)
 	}
-	claims := &structpb.Struct{}
-	if err := claims.UnmarshalJSON(jsonClaims); err != nil {
+	pb := &structpb.Struct{}
+	if err := pb.UnmarshalJSON(jsonClaims); err != nil {
 		return nil, fmt.Errorf("failed to encode claims: %w", err)
 	}
-	return &credentialcomposerv1.JWTSVIDAttributes{
-		Claims: claims,
-	}, nil
+	return pb, nil
 }
 
 func jwtSVIDAttributesFromV1(pb *credentialcomposerv1.JWTSVIDAttributes) JWTSVIDAttributes {
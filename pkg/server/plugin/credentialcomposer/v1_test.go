@@ -12,6 +12,7 @@ import (
 	credentialcomposerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/credentialcomposer/v1"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/proto/private/server/witsvidcomposer"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testkey"
@@ -858,15 +859,121 @@ func TestV1ComposeWorkloadJWTSVID(t *testing.T) {
 	}
 }
 
+func TestV1ComposeWorkloadWITSVID(t *testing.T) {
+	id := spiffeid.RequireFromString("spiffe://domain.test/workload")
+	for _, tt := range []struct {
+		test      string
+		pluginErr error
+
+		idIn            spiffeid.ID
+		attributesIn    credentialcomposer.WITSVIDAttributes
+		expectRequestIn *witsvidcomposer.ComposeWorkloadWITSVIDRequest
+
+		responseOut         *witsvidcomposer.ComposeWorkloadWITSVIDResponse
+		expectAttributesOut credentialcomposer.WITSVIDAttributes
+
+		expectCode    codes.Code
+		expectMessage string
+	}{
+		{
+			test:          "invalid ID",
+			expectCode:    codes.Internal,
+			expectMessage: "credentialcomposer(test): invalid workload ID: empty",
+		},
+		{
+			test:          "plugin fails",
+			idIn:          id,
+			attributesIn:  credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+			pluginErr:     status.Error(codes.Internal, "oh no"),
+			expectCode:    codes.Internal,
+			expectMessage: "credentialcomposer(test): oh no",
+		},
+		{
+			test:          "invalid claims input",
+			idIn:          id,
+			attributesIn:  credentialcomposer.WITSVIDAttributes{},
+			expectCode:    codes.Internal,
+			expectMessage: "credentialcomposer(test): invalid workload WITSVID attributes: invalid claims: cannot be empty",
+		},
+		{
+			test:         "attributes unchanged if unimplemented",
+			pluginErr:    status.Error(codes.Unimplemented, "not implemented"),
+			idIn:         id,
+			attributesIn: credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+			expectRequestIn: &witsvidcomposer.ComposeWorkloadWITSVIDRequest{
+				SpiffeId: id.String(),
+				Attributes: &witsvidcomposer.WITSVIDAttributes{
+					Claims: &structpb.Struct{Fields: map[string]*structpb.Value{"ORIGINAL_KEY": structpb.NewStringValue("ORIGINAL_VALUE")}},
+				},
+			},
+			responseOut:         &witsvidcomposer.ComposeWorkloadWITSVIDResponse{},
+			expectAttributesOut: credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+		},
+		{
+			test:         "attributes unchanged if plugin does not respond with attributes",
+			idIn:         id,
+			attributesIn: credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+			expectRequestIn: &witsvidcomposer.ComposeWorkloadWITSVIDRequest{
+				SpiffeId: id.String(),
+				Attributes: &witsvidcomposer.WITSVIDAttributes{
+					Claims: &structpb.Struct{Fields: map[string]*structpb.Value{"ORIGINAL_KEY": structpb.NewStringValue("ORIGINAL_VALUE")}},
+				},
+			},
+			responseOut:         &witsvidcomposer.ComposeWorkloadWITSVIDResponse{},
+			expectAttributesOut: credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+		},
+		{
+			test:         "attributes overridden by plugin",
+			idIn:         id,
+			attributesIn: credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+			expectRequestIn: &witsvidcomposer.ComposeWorkloadWITSVIDRequest{
+				SpiffeId: id.String(),
+				Attributes: &witsvidcomposer.WITSVIDAttributes{
+					Claims: &structpb.Struct{Fields: map[string]*structpb.Value{"ORIGINAL_KEY": structpb.NewStringValue("ORIGINAL_VALUE")}},
+				},
+			},
+			responseOut: &witsvidcomposer.ComposeWorkloadWITSVIDResponse{
+				Attributes: &witsvidcomposer.WITSVIDAttributes{
+					Claims: &structpb.Struct{Fields: map[string]*structpb.Value{"NEW_KEY": structpb.NewStringValue("NEW_VALUE")}},
+				},
+			},
+			expectAttributesOut: credentialcomposer.WITSVIDAttributes{Claims: map[string]any{"NEW_KEY": "NEW_VALUE"}},
+		},
+	} {
+		t.Run(tt.test, func(t *testing.T) {
+			plugin := &fakeV1Plugin{err: tt.pluginErr, composeWorkloadWITSVIDResponseOut: tt.responseOut}
+			cc := loadWITSVIDComposerV1(t, plugin)
+			require.True(t, cc.IsInitialized())
+			attributesOut, err := cc.ComposeWorkloadWITSVID(context.Background(), tt.idIn, tt.attributesIn)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMessage)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, plugin.composeWorkloadWITSVIDRequestIn, tt.expectRequestIn)
+			assert.Equal(t, attributesOut, tt.expectAttributesOut)
+		})
+	}
+}
+
 func loadV1Plugin(t *testing.T, plugin *fakeV1Plugin) credentialcomposer.CredentialComposer {
-	server := credentialcomposerv1.CredentialComposerPluginServer(plugin)
 	cc := new(credentialcomposer.V1)
-	plugintest.Load(t, catalog.MakeBuiltIn("test", server), cc)
+	plugintest.Load(t, catalog.MakeBuiltIn("test", credentialcomposerv1.CredentialComposerPluginServer(plugin)), cc)
 	return cc
 }
 
+func loadWITSVIDComposerV1(t *testing.T, plugin *fakeV1Plugin) *credentialcomposer.WITSVIDComposerV1 {
+	witSVIDComposer := new(credentialcomposer.WITSVIDComposerV1)
+	plugintest.Load(t, catalog.MakeBuiltIn("test",
+		credentialcomposerv1.CredentialComposerPluginServer(plugin),
+		credentialcomposer.WITSVIDComposerServiceServer(plugin),
+	), new(credentialcomposer.V1), plugintest.Services(witSVIDComposer))
+	return witSVIDComposer
+}
+
 type fakeV1Plugin struct {
 	credentialcomposerv1.UnimplementedCredentialComposerServer
+	witsvidcomposer.UnimplementedWITSVIDComposerServer
 
 	err                                error
 	composeServerX509CARequestIn       *credentialcomposerv1.ComposeServerX509CARequest
@@ -879,6 +986,8 @@ type fakeV1Plugin struct {
 	composeWorkloadX509SVIDResponseOut *credentialcomposerv1.ComposeWorkloadX509SVIDResponse
 	composeWorkloadJWTSVIDRequestIn    *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest
 	composeWorkloadJWTSVIDResponseOut  *credentialcomposerv1.ComposeWorkloadJWTSVIDResponse
+	composeWorkloadWITSVIDRequestIn    *witsvidcomposer.ComposeWorkloadWITSVIDRequest
+	composeWorkloadWITSVIDResponseOut  *witsvidcomposer.ComposeWorkloadWITSVIDResponse
 }
 
 func (p *fakeV1Plugin) ComposeServerX509CA(_ context.Context, req *credentialcomposerv1.ComposeServerX509CARequest) (*credentialcomposerv1.ComposeServerX509CAResponse, error) {
@@ -906,6 +1015,11 @@ func (p *fakeV1Plugin) ComposeWorkloadJWTSVID(_ context.Context, req *credential
 	return p.composeWorkloadJWTSVIDResponseOut, p.err
 }
 
+func (p *fakeV1Plugin) ComposeWorkloadWITSVID(_ context.Context, req *witsvidcomposer.ComposeWorkloadWITSVIDRequest) (*witsvidcomposer.ComposeWorkloadWITSVIDResponse, error) {
+	p.composeWorkloadWITSVIDRequestIn = req
+	return p.composeWorkloadWITSVIDResponseOut, p.err
+}
+
 func makeOID(tb testing.TB, ids ...uint64) x509.OID {
 	oid, err := x509.OIDFromInts(ids)
 	require.NoError(tb, err)
@@ -0,0 +1,100 @@
+package credentialcomposer
+
+import (
+	"context"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
+	"github.com/spiffe/spire/pkg/common/plugin"
+	"github.com/spiffe/spire/proto/private/server/witsvidcomposer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The CredentialComposer service in the plugin SDK does not support
+// WIT-SVIDs. CredentialComposer plugins that can compose WIT-SVIDs serve the
+// SPIRE-internal WITSVIDComposer service next to it. The service is optional;
+// WIT-SVIDs are not composed by plugins that do not serve it.
+const witSVIDComposerServiceName = "spire.private.server.witsvidcomposer.WITSVIDComposer"
+
+var _ WITSVIDComposer = (*WITSVIDComposerV1)(nil)
+
+// WITSVIDComposerServiceServer returns a service server for the
+// WITSVIDComposer service, to be served by the CredentialComposer plugin
+// alongside the plugin server.
+func WITSVIDComposerServiceServer(server witsvidcomposer.WITSVIDComposerServer) pluginsdk.ServiceServer {
+	return witSVIDComposerServiceServer{WITSVIDComposerServer: server}
+}
+
+type witSVIDComposerServiceServer struct {
+	witsvidcomposer.WITSVIDComposerServer
+}
+
+func (s witSVIDComposerServiceServer) GRPCServiceName() string {
+	return witSVIDComposerServiceName
+}
+
+func (s witSVIDComposerServiceServer) RegisterServer(server *grpc.Server) any {
+	witsvidcomposer.RegisterWITSVIDComposerServer(server, s.WITSVIDComposerServer)
+	return s.WITSVIDComposerServer
+}
+
+// WITSVIDComposerV1 is the facade for the WITSVIDComposer service.
+type WITSVIDComposerV1 struct {
+	plugin.Facade
+	witsvidcomposer.WITSVIDComposerClient
+}
+
+func (v1 *WITSVIDComposerV1) IsInitialized() bool {
+	return v1.WITSVIDComposerClient != nil
+}
+
+func (v1 *WITSVIDComposerV1) GRPCServiceName() string {
+	return witSVIDComposerServiceName
+}
+
+func (v1 *WITSVIDComposerV1) InitClient(conn grpc.ClientConnInterface) any {
+	v1.WITSVIDComposerClient = witsvidcomposer.NewWITSVIDComposerClient(conn)
+	return v1.WITSVIDComposerClient
+}
+
+func (v1 *WITSVIDComposerV1) ComposeWorkloadWITSVID(ctx context.Context, id spiffeid.ID, attributes WITSVIDAttributes) (WITSVIDAttributes, error) {
+	if id.IsZero() {
+		return WITSVIDAttributes{}, v1.Error(codes.Internal, "invalid workload ID: empty")
+	}
+	attributesIn, err := witSVIDAttributesToV1(attributes)
+	if err != nil {
+		return WITSVIDAttributes{}, v1.Errorf(codes.Internal, "invalid workload WITSVID attributes: %v", err)
+	}
+	resp, err := v1.WITSVIDComposerClient.ComposeWorkloadWITSVID(ctx, &witsvidcomposer.ComposeWorkloadWITSVIDRequest{
+		SpiffeId:   id.String(),
+		Attributes: attributesIn,
+	})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return attributes, nil
+		}
+		return WITSVIDAttributes{}, v1.WrapErr(err)
+	}
+	if pb := resp.GetAttributes(); pb != nil {
+		attributes = witSVIDAttributesFromV1(pb)
+	}
+	return attributes, nil
+}
+
+func witSVIDAttributesToV1(attributes WITSVIDAttributes) (*witsvidcomposer.WITSVIDAttributes, error) {
+	claims, err := claimsToV1(attributes.Claims)
+	if err != nil {
+		return nil, err
+	}
+	return &witsvidcomposer.WITSVIDAttributes{
+		Claims: claims,
+	}, nil
+}
+
+func witSVIDAttributesFromV1(pb *witsvidcomposer.WITSVIDAttributes) WITSVIDAttributes {
+	return WITSVIDAttributes{
+		Claims: pb.Claims.AsMap(),
+	}
+}
@@ -258,13 +258,13 @@ func makeKeyEntry(keyID string, keyType keymanagerv1.KeyType, privateKey crypto.
 func MakeKeyEntryFromKey(id string, privateKey crypto.PrivateKey) (*KeyEntry, error) {
 	switch privateKey := privateKey.(type) {
 	case *ecdsa.PrivateKey:
-		keyType, err := ecdsaKeyType(privateKey)
+		keyType, err := ecdsaKeyType(&privateKey.PublicKey)
 		if err != nil {
 			return nil, fmt.Errorf("unable to make key entry for key %q: %w", id, err)
 		}
 		return makeKeyEntry(id, keyType, privateKey)
 	case *rsa.PrivateKey:
-		keyType, err := rsaKeyType(privateKey)
+		keyType, err := rsaKeyType(&privateKey.PublicKey)
 		if err != nil {
 			return nil, fmt.Errorf("unable to make key entry for key %q: %w", id, err)
 		}
@@ -274,8 +274,27 @@ func MakeKeyEntryFromKey(id string, privateKey crypto.PrivateKey) (*KeyEntry, er
 	}
 }
 
-func rsaKeyType(privateKey *rsa.PrivateKey) (keymanagerv1.KeyType, error) {
-	bits := privateKey.N.BitLen()
+// MakeKeyEntryFromSigner makes a key entry for a signer whose private key is
+// not available, e.g. because it is stored on a hardware token.
+func MakeKeyEntryFromSigner(id string, signer crypto.Signer) (*KeyEntry, error) {
+	var keyType keymanagerv1.KeyType
+	var err error
+	switch publicKey := signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		keyType, err = ecdsaKeyType(publicKey)
+	case *rsa.PublicKey:
+		keyType, err = rsaKeyType(publicKey)
+	default:
+		return nil, fmt.Errorf("unexpected public key type %T for key %q", publicKey, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to make key entry for key %q: %w", id, err)
+	}
+	return makeKeyEntry(id, keyType, signer)
+}
+
+func rsaKeyType(publicKey *rsa.PublicKey) (keymanagerv1.KeyType, error) {
+	bits := publicKey.N.BitLen()
 	switch bits {
 	case 2048:
 		return keymanagerv1.KeyType_RSA_2048, nil
@@ -286,15 +305,15 @@ func rsaKeyType(privateKey *rsa.PrivateKey) (keymanagerv1.KeyType, error) {
 	}
 }
 
-func ecdsaKeyType(privateKey *ecdsa.PrivateKey) (keymanagerv1.KeyType, error) {
+func ecdsaKeyType(publicKey *ecdsa.PublicKey) (keymanagerv1.KeyType, error) {
 	switch {
-	case privateKey.Curve == elliptic.P256():
+	case publicKey.Curve == elliptic.P256():
 		return keymanagerv1.KeyType_EC_P256, nil
-	case privateKey.Curve == elliptic.P384():
+	case publicKey.Curve == elliptic.P384():
 		return keymanagerv1.KeyType_EC_P384, nil
 	default:
 		return keymanagerv1.KeyType_UNSPECIFIED_KEY_TYPE, fmt.Errorf("no EC key type for EC curve: %s",
-			privateKey.Curve.Params().Name)
+			publicKey.Curve.Params().Name)
 	}
 }
 
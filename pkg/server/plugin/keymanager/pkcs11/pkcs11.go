@@ -0,0 +1,285 @@
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	keymanagerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/keymanager/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/pkcs11"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	keymanagerbase "github.com/spiffe/spire/pkg/server/plugin/keymanager/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "pkcs11"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		keymanagerv1.KeyManagerPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// Config provides configuration context for the plugin.
+type Config struct {
+	ModulePath         string `hcl:"module_path" json:"module_path"`
+	Slot               *int   `hcl:"slot" json:"slot"`
+	TokenLabel         string `hcl:"token_label" json:"token_label"`
+	Pin                string `hcl:"pin" json:"pin"`
+	KeyIdentifierFile  string `hcl:"key_identifier_file" json:"key_identifier_file"`
+	KeyIdentifierValue string `hcl:"key_identifier_value" json:"key_identifier_value"`
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Config {
+	newConfig := new(Config)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if newConfig.ModulePath == "" {
+		status.ReportError("module_path is required")
+	}
+
+	switch {
+	case newConfig.Slot == nil && newConfig.TokenLabel == "":
+		status.ReportError("one of slot or token_label is required")
+	case newConfig.Slot != nil && newConfig.TokenLabel != "":
+		status.ReportError("only one of slot or token_label can be configured")
+	case newConfig.Slot != nil && *newConfig.Slot < 0:
+		status.ReportErrorf("invalid slot %d", *newConfig.Slot)
+	}
+
+	if newConfig.Pin == "" {
+		status.ReportError("pin is required")
+	}
+
+	switch {
+	case newConfig.KeyIdentifierFile == "" && newConfig.KeyIdentifierValue == "":
+		status.ReportError("one of key_identifier_file or key_identifier_value is required")
+	case newConfig.KeyIdentifierFile != "" && newConfig.KeyIdentifierValue != "":
+		status.ReportError("only one of key_identifier_file or key_identifier_value can be configured")
+	case strings.Contains(newConfig.KeyIdentifierValue, "/"):
+		status.ReportError("key_identifier_value cannot contain '/'")
+	}
+
+	return newConfig
+}
+
+// keyIdentifier returns the configured key identifier, which is read from
+// the key identifier file, or generated and persisted in it on the first run.
+func (c *Config) keyIdentifier() (string, error) {
+	if c.KeyIdentifierValue != "" {
+		return c.KeyIdentifierValue, nil
+	}
+	return pkcs11.GetOrCreateKeyIdentifier(c.KeyIdentifierFile)
+}
+
+func (c *Config) tokenConfig(keyIdentifier string) pkcs11.TokenConfig {
+	tokenConfig := pkcs11.TokenConfig{
+		TokenLabel:    c.TokenLabel,
+		Pin:           c.Pin,
+		KeyIdentifier: keyIdentifier,
+	}
+	if c.Slot != nil {
+		slotID := uint(*c.Slot)
+		tokenConfig.SlotID = &slotID
+	}
+	return tokenConfig
+}
+
+// sameToken returns whether both configurations select the same keys on the
+// same token.
+func (c *Config) sameToken(other *Config) bool {
+	return c.ModulePath == other.ModulePath &&
+		c.TokenLabel == other.TokenLabel &&
+		(c.Slot == nil) == (other.Slot == nil) &&
+		(c.Slot == nil || *c.Slot == *other.Slot) &&
+		c.KeyIdentifierFile == other.KeyIdentifierFile &&
+		c.KeyIdentifierValue == other.KeyIdentifierValue
+}
+
+// Plugin is the main representation of this keymanager plugin
+type Plugin struct {
+	*keymanagerbase.Base
+	configv1.UnsafeConfigServer
+
+	mu     sync.Mutex
+	config *Config
+	token  *pkcs11.Token
+
+	hooks struct {
+		openModule func(path string) (pkcs11.Module, error)
+	}
+}
+
+// New returns an instantiated plugin
+func New() *Plugin {
+	p := &Plugin{}
+	p.Base = keymanagerbase.New(keymanagerbase.Config{
+		Generator:    generator{p: p},
+		WriteEntries: p.writeEntries,
+	})
+	p.hooks.openModule = pkcs11.OpenModule
+	return p
+}
+
+// Configure sets up the plugin. The token is opened on the first
+// configuration, and the keys stored on it are loaded.
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != nil {
+		if !p.config.sameToken(newConfig) {
+			return nil, status.Error(codes.InvalidArgument, "module_path, slot, token_label, key_identifier_file and key_identifier_value cannot be changed without a restart")
+		}
+		p.config = newConfig
+		return &configv1.ConfigureResponse{}, nil
+	}
+
+	keyIdentifier, err := newConfig.keyIdentifier()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get key identifier: %v", err)
+	}
+
+	module, err := p.hooks.openModule(newConfig.ModulePath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to load PKCS#11 module: %v", err)
+	}
+
+	token, err := pkcs11.OpenToken(module, newConfig.tokenConfig(keyIdentifier))
+	if err != nil {
+		_ = module.Close()
+		return nil, status.Errorf(codes.Internal, "unable to open token: %v", err)
+	}
+
+	entries, err := loadEntries(token)
+	if err != nil {
+		_ = token.Close()
+		return nil, err
+	}
+
+	p.Base.SetEntries(entries)
+	p.config = newConfig
+	p.token = token
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+// Close closes the session on the token and unloads the module.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == nil {
+		return nil
+	}
+	err := p.token.Close()
+	p.token = nil
+	return err
+}
+
+func (p *Plugin) getToken() (*pkcs11.Token, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.token, nil
+}
+
+func (p *Plugin) writeEntries(_ context.Context, entries []*keymanagerbase.KeyEntry) error {
+	token, err := p.getToken()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]pkcs11.Key, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, pkcs11.Key{ID: entry.Id, Signer: entry.PrivateKey})
+	}
+
+	if err := token.StoreKeys(keys); err != nil {
+		return status.Errorf(codes.Internal, "unable to store keys on the token: %v", err)
+	}
+	return nil
+}
+
+func loadEntries(token *pkcs11.Token) ([]*keymanagerbase.KeyEntry, error) {
+	keys, err := token.LoadKeys()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to load keys from the token: %v", err)
+	}
+
+	entries := make([]*keymanagerbase.KeyEntry, 0, len(keys))
+	for _, key := range keys {
+		entry, err := keymanagerbase.MakeKeyEntryFromSigner(key.ID, key.Signer)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to make entry %q: %v", key.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// generator generates the keys on the token of the plugin.
+type generator struct {
+	p *Plugin
+}
+
+func (g generator) GenerateRSA2048Key() (crypto.Signer, error) {
+	return g.generate((*pkcs11.Token).GenerateRSA2048Key)
+}
+
+func (g generator) GenerateRSA4096Key() (crypto.Signer, error) {
+	return g.generate((*pkcs11.Token).GenerateRSA4096Key)
+}
+
+func (g generator) GenerateEC256Key() (crypto.Signer, error) {
+	return g.generate((*pkcs11.Token).GenerateEC256Key)
+}
+
+func (g generator) GenerateEC384Key() (crypto.Signer, error) {
+	return g.generate((*pkcs11.Token).GenerateEC384Key)
+}
+
+func (g generator) generate(fn func(*pkcs11.Token) (crypto.Signer, error)) (crypto.Signer, error) {
+	token, err := g.p.getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := fn(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to generate key on the token: %v", err)
+	}
+	return signer, nil
+}
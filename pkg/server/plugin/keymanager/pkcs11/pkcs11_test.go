@@ -0,0 +1,320 @@
+package pkcs11
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/pkcs11"
+	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
+	keymanagertest "github.com/spiffe/spire/pkg/server/plugin/keymanager/test"
+	"github.com/spiffe/spire/test/fakes/fakepkcs11"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ctx = context.Background()
+
+	testToken = fakepkcs11.Token{SlotID: 1, Label: "spire", Pin: "1234"}
+)
+
+func TestKeyManagerContract(t *testing.T) {
+	keymanagertest.Test(t, keymanagertest.Config{
+		Create: func(t *testing.T) keymanager.KeyManager {
+			km, _, err := loadPlugin(t, fakepkcs11.New(testToken), `
+				module_path = "module.so"
+				token_label = "spire"
+				pin = "1234"
+				key_identifier_value = "spire-server"
+			`)
+			require.NoError(t, err)
+			return km
+		},
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		config     string
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed",
+			config:     "{ malformed json }",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name:       "missing module path",
+			config:     `slot = 1 pin = "1234"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "module_path is required",
+		},
+		{
+			name:       "missing slot and token label",
+			config:     `module_path = "module.so" pin = "1234" key_identifier_value = "spire-server"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "one of slot or token_label is required",
+		},
+		{
+			name:       "both slot and token label",
+			config:     `module_path = "module.so" slot = 1 token_label = "spire" pin = "1234" key_identifier_value = "spire-server"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "only one of slot or token_label can be configured",
+		},
+		{
+			name:       "negative slot",
+			config:     `module_path = "module.so" slot = -1 pin = "1234" key_identifier_value = "spire-server"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid slot -1",
+		},
+		{
+			name:       "missing pin",
+			config:     `module_path = "module.so" slot = 1`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "pin is required",
+		},
+		{
+			name:       "missing key identifier",
+			config:     `module_path = "module.so" slot = 1 pin = "1234"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "one of key_identifier_file or key_identifier_value is required",
+		},
+		{
+			name:       "both key identifier file and value",
+			config:     `module_path = "module.so" slot = 1 pin = "1234" key_identifier_file = "id" key_identifier_value = "spire"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "only one of key_identifier_file or key_identifier_value can be configured",
+		},
+		{
+			name:       "invalid key identifier value",
+			config:     `module_path = "module.so" slot = 1 pin = "1234" key_identifier_value = "spire/server"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "key_identifier_value cannot contain '/'",
+		},
+		{
+			name:       "module fails to load",
+			config:     `module_path = "bad.so" slot = 1 pin = "1234" key_identifier_value = "spire-server"`,
+			expectCode: codes.Internal,
+			expectMsg:  "unable to load PKCS#11 module: oh no",
+		},
+		{
+			name:       "no token in slot",
+			config:     `module_path = "module.so" slot = 2 pin = "1234" key_identifier_value = "spire-server"`,
+			expectCode: codes.Internal,
+			expectMsg:  "unable to open token: no token found in slot 2",
+		},
+		{
+			name:       "no token with label",
+			config:     `module_path = "module.so" token_label = "other" pin = "1234" key_identifier_value = "spire-server"`,
+			expectCode: codes.Internal,
+			expectMsg:  `unable to open token: no token found with label "other"`,
+		},
+		{
+			name:       "incorrect pin",
+			config:     `module_path = "module.so" slot = 1 pin = "4321" key_identifier_value = "spire-server"`,
+			expectCode: codes.Internal,
+			expectMsg:  "unable to open token: unable to open session on slot 1",
+		},
+		{
+			name:   "success with slot",
+			config: `module_path = "module.so" slot = 1 pin = "1234" key_identifier_value = "spire-server"`,
+		},
+		{
+			name:   "success with token label",
+			config: `module_path = "module.so" token_label = "spire" pin = "1234" key_identifier_value = "spire-server"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			module := fakepkcs11.New(testToken)
+			_, _, err := loadPlugin(t, module, tt.config)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	p := New()
+	p.hooks.openModule = func(string) (pkcs11.Module, error) { return module, nil }
+	plugintest.Load(t, builtin(p), nil)
+
+	configure := func(config string) error {
+		_, err := p.Configure(ctx, &configv1.ConfigureRequest{
+			HclConfiguration:  config,
+			CoreConfiguration: &configv1.CoreConfiguration{TrustDomain: "example.org"},
+		})
+		return err
+	}
+
+	require.NoError(t, configure(`module_path = "module.so" slot = 1 pin = "1234" key_identifier_value = "spire-server"`))
+
+	// The pin can be changed without reopening the token
+	require.NoError(t, configure(`module_path = "module.so" slot = 1 pin = "5678" key_identifier_value = "spire-server"`))
+
+	err := configure(`module_path = "module.so" token_label = "spire" pin = "1234" key_identifier_value = "spire-server"`)
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "module_path, slot, token_label, key_identifier_file and key_identifier_value cannot be changed without a restart")
+
+	err = configure(`module_path = "module.so" slot = 1 pin = "1234" key_identifier_value = "other"`)
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "module_path, slot, token_label, key_identifier_file and key_identifier_value cannot be changed without a restart")
+
+	err = configure(`module_path = "module.so" slot = 1 pin = "1234" key_identifier_file = "id"`)
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "module_path, slot, token_label, key_identifier_file and key_identifier_value cannot be changed without a restart")
+}
+
+func TestGenerateKeyBeforeConfigure(t *testing.T) {
+	km := new(keymanager.V1)
+	plugintest.Load(t, BuiltIn(), km)
+
+	_, err := km.GenerateKey(ctx, "id", keymanager.ECP256)
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "keymanager(pkcs11): failed to generate key: not configured")
+}
+
+func TestGenerateKeyPersistence(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	config := `module_path = "module.so" token_label = "spire" pin = "1234"`
+	keyIdentifier := ` key_identifier_value = "spire-server"`
+
+	km, p, err := loadPlugin(t, module, config+keyIdentifier)
+	require.NoError(t, err)
+
+	keyIn, err := km.GenerateKey(ctx, "x509-CA-A", keymanager.ECP256)
+	require.NoError(t, err)
+	_, err = km.GenerateKey(ctx, "JWT-Signer-A", keymanager.RSA2048)
+	require.NoError(t, err)
+	require.Equal(t, []string{"spire-server/JWT-Signer-A", "spire-server/x509-CA-A"}, labels(t, module))
+
+	// Rotate the key. The replaced key is removed from the token.
+	keyIn, err = km.GenerateKey(ctx, "x509-CA-A", keymanager.ECP384)
+	require.NoError(t, err)
+	require.Equal(t, []string{"spire-server/JWT-Signer-A", "spire-server/x509-CA-A"}, labels(t, module))
+
+	// Fail to store the rotated key. The original key should remain.
+	module.SetError("SetAttributes", errors.New("oh no"))
+	_, err = km.GenerateKey(ctx, "x509-CA-A", keymanager.ECP256)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "unable to store keys on the token")
+	module.SetError("SetAttributes", nil)
+	require.Equal(t, []string{"spire-server/JWT-Signer-A", "spire-server/x509-CA-A"}, labels(t, module))
+
+	keyOut, err := km.GetKey(ctx, "x509-CA-A")
+	require.NoError(t, err)
+	require.Equal(t, publicKeyBytes(t, keyIn), publicKeyBytes(t, keyOut))
+
+	// Restart the plugin. The keys should have persisted on the token.
+	require.NoError(t, p.Close())
+	require.True(t, module.Closed(), "module was not closed")
+
+	km, _, err = loadPlugin(t, module, config+keyIdentifier)
+	require.NoError(t, err)
+
+	keys, err := km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	keyOut, err = km.GetKey(ctx, "x509-CA-A")
+	require.NoError(t, err)
+	require.Equal(t, publicKeyBytes(t, keyIn), publicKeyBytes(t, keyOut))
+
+	// Keys stored under another key identifier are not loaded.
+	km, _, err = loadPlugin(t, module, config+` key_identifier_value = "other"`)
+	require.NoError(t, err)
+	keys, err = km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestKeyIdentifierFile(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	keyIdentifierFile := filepath.Join(t.TempDir(), "key_identifier")
+	config := fmt.Sprintf(`module_path = "module.so" slot = 1 pin = "1234" key_identifier_file = %q`, keyIdentifierFile)
+
+	// The key identifier is generated and persisted on the first run
+	km, p, err := loadPlugin(t, module, config)
+	require.NoError(t, err)
+	keyIdentifier, err := os.ReadFile(keyIdentifierFile)
+	require.NoError(t, err)
+	_, err = uuid.FromString(string(keyIdentifier))
+	require.NoError(t, err)
+
+	_, err = km.GenerateKey(ctx, "x509-CA-A", keymanager.ECP256)
+	require.NoError(t, err)
+	require.Equal(t, []string{string(keyIdentifier) + "/x509-CA-A"}, labels(t, module))
+
+	// The persisted key identifier is used after a restart
+	require.NoError(t, p.Close())
+	km, p, err = loadPlugin(t, module, config)
+	require.NoError(t, err)
+	keys, err := km.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NoError(t, p.Close())
+
+	require.NoError(t, os.WriteFile(keyIdentifierFile, []byte("malformed"), 0600))
+	_, _, err = loadPlugin(t, module, config)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "unable to get key identifier: unable to parse key identifier from path")
+}
+
+func TestGenerateKeyFailure(t *testing.T) {
+	module := fakepkcs11.New(testToken)
+	km, _, err := loadPlugin(t, module, `module_path = "module.so" slot = 1 pin = "1234" key_identifier_value = "spire-server"`)
+	require.NoError(t, err)
+
+	module.SetError("GenerateKeyPair", errors.New("oh no"))
+	_, err = km.GenerateKey(ctx, "id", keymanager.ECP256)
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "keymanager(pkcs11): failed to generate key: unable to generate key on the token: unable to generate key pair: oh no")
+}
+
+func loadPlugin(t *testing.T, module *fakepkcs11.Module, config string) (keymanager.KeyManager, *Plugin, error) {
+	p := New()
+	p.hooks.openModule = func(path string) (pkcs11.Module, error) {
+		if path == "bad.so" {
+			return nil, errors.New("oh no")
+		}
+		return module, nil
+	}
+
+	km := new(keymanager.V1)
+	var configErr error
+	plugintest.Load(t, builtin(p), km,
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(config),
+		plugintest.CaptureConfigureError(&configErr),
+	)
+	return km, p, configErr
+}
+
+func labels(t *testing.T, module *fakepkcs11.Module) []string {
+	// Each key pair is made of a private and a public key object
+	var pairs []string
+	all := module.Labels(testToken.SlotID)
+	for i := 0; i < len(all); i += 2 {
+		require.Equal(t, all[i], all[i+1])
+		pairs = append(pairs, all[i])
+	}
+	return pairs
+}
+
+func publicKeyBytes(t *testing.T, key keymanager.Key) []byte {
+	b, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	return b
+}
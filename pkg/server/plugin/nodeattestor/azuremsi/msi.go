@@ -263,7 +263,7 @@ func (p *MSIAttestorPlugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServ
 		return status.Errorf(codes.InvalidArgument, "unable to parse token: %v", err)
 	}
 
-	keyID, ok := getTokenKeyID(token)
+	keyID, ok := jwtutil.GetTokenKeyID(token)
 	if !ok {
 		return status.Error(codes.InvalidArgument, "token missing key id")
 	}
@@ -501,12 +501,3 @@ func resourceGroupName(resourceGroup, name string) string {
 func selectorValue(parts ...string) string {
 	return strings.Join(parts, ":")
 }
-
-func getTokenKeyID(token *jwt.JSONWebToken) (string, bool) {
-	for _, h := range token.Headers {
-		if h.KeyID != "" {
-			return h.KeyID, true
-		}
-	}
-	return "", false
-}
@@ -0,0 +1,370 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/jwtutil"
+	"github.com/spiffe/spire/pkg/common/plugin/nomad"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = nomad.PluginName
+
+	// Workload identities have the not-before ("nbf") claim. If there are
+	// clock differences between the Nomad servers and the SPIRE server then
+	// token validation may fail unless we give a little leeway.
+	tokenLeeway = time.Minute
+
+	keySetRefreshInterval = time.Hour
+
+	allocationStatusRunning = "running"
+)
+
+var (
+	defaultAudience = []string{"spire-server"}
+
+	// Nomad signs workload identities with RS256 by default. Older versions
+	// used EdDSA.
+	allowedJWTSignatureAlgorithms = []jose.SignatureAlgorithm{
+		jose.RS256,
+		jose.EdDSA,
+	}
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *AttestorPlugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// AttestorConfig contains a map of clusters that uses cluster name as key
+type AttestorConfig struct {
+	Clusters map[string]*ClusterConfig `hcl:"clusters"`
+}
+
+// ClusterConfig holds a single cluster configuration
+type ClusterConfig struct {
+	// Address of the Nomad HTTP API
+	Address string `hcl:"address"`
+
+	// ACL token used to query the Nomad HTTP API
+	Token string `hcl:"token"`
+
+	// Path to the CA certificates used to verify the Nomad HTTP API
+	// certificate. If empty, the system roots are used.
+	CACertPath string `hcl:"ca_cert_path"`
+
+	// Array of allowed jobs, in the "<namespace>/<job ID>" form
+	// Attestation is denied if coming from a job that is not in the list
+	JobAllowList []string `hcl:"job_allow_list"`
+
+	// Audience for workload identity validation
+	// If audience is not configured, defaultAudience will be used
+	Audience []string `hcl:"audience"`
+
+	// Node metadata keys that are allowed to use as selectors
+	AllowedNodeMetaKeys []string `hcl:"allowed_node_meta_keys"`
+}
+
+type attestorConfig struct {
+	trustDomain string
+	clusters    map[string]*clusterConfig
+}
+
+type clusterConfig struct {
+	jobs                map[string]bool
+	audience            []string
+	client              nomad.Client
+	keySetProvider      jwtutil.KeySetProvider
+	allowedNodeMetaKeys map[string]bool
+}
+
+func (p *AttestorPlugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *attestorConfig {
+	hclConfig := new(AttestorConfig)
+	if err := hcl.Decode(hclConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if len(hclConfig.Clusters) < 1 {
+		status.ReportInfo("No clusters configured, Nomad attestation is effectively disabled")
+	}
+
+	newConfig := &attestorConfig{
+		trustDomain: coreConfig.TrustDomain.String(),
+		clusters:    make(map[string]*clusterConfig),
+	}
+
+	for name, hclCluster := range hclConfig.Clusters {
+		if hclCluster.Address == "" {
+			status.ReportErrorf("cluster %q configuration is missing the address", name)
+			continue
+		}
+		if len(hclCluster.JobAllowList) == 0 {
+			status.ReportErrorf("cluster %q configuration must have at least one job allowed", name)
+		}
+
+		jobs := make(map[string]bool)
+		for _, job := range hclCluster.JobAllowList {
+			jobs[job] = true
+		}
+
+		audience := hclCluster.Audience
+		if len(audience) == 0 {
+			audience = defaultAudience
+		}
+
+		allowedNodeMetaKeys := make(map[string]bool)
+		for _, key := range hclCluster.AllowedNodeMetaKeys {
+			allowedNodeMetaKeys[key] = true
+		}
+
+		client, err := p.hooks.newClient(nomad.ClientConfig{
+			Address:    hclCluster.Address,
+			Token:      hclCluster.Token,
+			CACertPath: hclCluster.CACertPath,
+		})
+		if err != nil {
+			status.ReportErrorf("unable to create client for cluster %q: %v", name, err)
+			continue
+		}
+
+		newConfig.clusters[name] = &clusterConfig{
+			jobs:                jobs,
+			audience:            audience,
+			client:              client,
+			keySetProvider:      jwtutil.NewCachingKeySetProvider(jwtutil.KeySetProviderFunc(client.GetKeySet), keySetRefreshInterval),
+			allowedNodeMetaKeys: allowedNodeMetaKeys,
+		}
+	}
+
+	return newConfig
+}
+
+// AttestorPlugin is a Nomad workload identity node attestor plugin
+type AttestorPlugin struct {
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	mu     sync.RWMutex
+	config *attestorConfig
+	log    hclog.Logger
+
+	hooks struct {
+		now       func() time.Time
+		newClient func(nomad.ClientConfig) (nomad.Client, error)
+	}
+}
+
+// New creates a new Nomad node attestor plugin
+func New() *AttestorPlugin {
+	p := &AttestorPlugin{}
+	p.hooks.now = time.Now
+	p.hooks.newClient = nomad.NewClient
+	return p
+}
+
+var _ nodeattestorv1.NodeAttestorServer = (*AttestorPlugin)(nil)
+
+// SetLogger sets up plugin logging
+func (p *AttestorPlugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *AttestorPlugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	payload := req.GetPayload()
+	if payload == nil {
+		return status.Error(codes.InvalidArgument, "missing attestation payload")
+	}
+
+	attestationData := new(nomad.AttestationData)
+	if err := json.Unmarshal(payload, attestationData); err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to unmarshal data payload: %v", err)
+	}
+
+	if attestationData.Cluster == "" {
+		return status.Error(codes.InvalidArgument, "missing cluster in attestation data")
+	}
+
+	if attestationData.Token == "" {
+		return status.Error(codes.InvalidArgument, "missing token in attestation data")
+	}
+
+	cluster := config.clusters[attestationData.Cluster]
+	if cluster == nil {
+		return status.Errorf(codes.InvalidArgument, "not configured for cluster %q", attestationData.Cluster)
+	}
+
+	claims, err := p.validateToken(stream.Context(), cluster, attestationData.Token)
+	if err != nil {
+		return err
+	}
+
+	job := claims.Namespace + "/" + claims.JobID
+	if !cluster.jobs[job] {
+		return status.Errorf(codes.PermissionDenied, "%q is not an allowed job for cluster %q", job, attestationData.Cluster)
+	}
+
+	alloc, err := cluster.client.GetAllocation(stream.Context(), claims.AllocationID)
+	switch {
+	case errors.Is(err, nomad.ErrNotFound):
+		return status.Errorf(codes.PermissionDenied, "allocation %q not found in cluster %q", claims.AllocationID, attestationData.Cluster)
+	case err != nil:
+		return status.Errorf(codes.Internal, "unable to get allocation from Nomad API for cluster %q: %v", attestationData.Cluster, err)
+	}
+
+	// The workload identity is only valid while the allocation that it was
+	// issued to is running. This prevents tokens of stopped allocations,
+	// which may have been rescheduled on a different node, from being used.
+	switch {
+	case alloc.Namespace != claims.Namespace || alloc.JobID != claims.JobID:
+		return status.Errorf(codes.PermissionDenied, "allocation %q does not belong to job %q", alloc.ID, job)
+	case alloc.ClientStatus != allocationStatusRunning:
+		return status.Errorf(codes.PermissionDenied, "allocation %q is not running (status %q)", alloc.ID, alloc.ClientStatus)
+	case alloc.NodeID == "":
+		return status.Errorf(codes.Internal, "allocation %q is not placed on a node", alloc.ID)
+	}
+
+	node, err := cluster.client.GetNode(stream.Context(), alloc.NodeID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to get node from Nomad API for cluster %q: %v", attestationData.Cluster, err)
+	}
+
+	selectorValues := []string{
+		nomad.MakeSelectorValue("cluster", attestationData.Cluster),
+		nomad.MakeSelectorValue("namespace", claims.Namespace),
+		nomad.MakeSelectorValue("job_id", claims.JobID),
+		nomad.MakeSelectorValue("alloc_id", alloc.ID),
+		nomad.MakeSelectorValue("node_id", node.ID),
+		nomad.MakeSelectorValue("node_name", node.Name),
+		nomad.MakeSelectorValue("datacenter", node.Datacenter),
+		nomad.MakeSelectorValue("node_pool", node.NodePool),
+	}
+
+	if node.NodeClass != "" {
+		selectorValues = append(selectorValues, nomad.MakeSelectorValue("node_class", node.NodeClass))
+	}
+
+	for key, value := range node.Meta {
+		if cluster.allowedNodeMetaKeys[key] {
+			selectorValues = append(selectorValues, nomad.MakeSelectorValue("node_meta", key, value))
+		}
+	}
+
+	return stream.Send(&nodeattestorv1.AttestResponse{
+		Response: &nodeattestorv1.AttestResponse_AgentAttributes{
+			AgentAttributes: &nodeattestorv1.AgentAttributes{
+				CanReattest:    true,
+				SpiffeId:       nomad.AgentID(pluginName, config.trustDomain, attestationData.Cluster, node.ID),
+				SelectorValues: selectorValues,
+			},
+		},
+	})
+}
+
+func (p *AttestorPlugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, p.buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *AttestorPlugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, p.buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *AttestorPlugin) getConfig() (*attestorConfig, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+// validateToken verifies the signature of the workload identity against the
+// JWKS of the cluster and validates its claims.
+func (p *AttestorPlugin) validateToken(ctx context.Context, cluster *clusterConfig, rawToken string) (*nomad.IdentityClaims, error) {
+	token, err := jwt.ParseSigned(rawToken, allowedJWTSignatureAlgorithms)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to parse token: %v", err)
+	}
+
+	keyID, ok := jwtutil.GetTokenKeyID(token)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "token missing key id")
+	}
+
+	keySet, err := cluster.keySetProvider.GetKeySet(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to obtain JWKS: %v", err)
+	}
+
+	keys := keySet.Key(keyID)
+	if len(keys) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "key id %q not found", keyID)
+	}
+
+	claims := new(nomad.IdentityClaims)
+	if err := token.Claims(&keys[0], claims); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to verify token: %v", err)
+	}
+
+	if err := claims.ValidateWithLeeway(jwt.Expected{
+		AnyAudience: cluster.audience,
+		Time:        p.hooks.now(),
+	}, tokenLeeway); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "unable to validate token claims: %v", err)
+	}
+
+	switch {
+	case claims.Namespace == "":
+		return nil, status.Error(codes.InvalidArgument, "token missing namespace claim")
+	case claims.JobID == "":
+		return nil, status.Error(codes.InvalidArgument, "token missing job ID claim")
+	case claims.AllocationID == "":
+		return nil, status.Error(codes.InvalidArgument, "token missing allocation ID claim")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,483 @@
+package nomad
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/nomad"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/test/fakes/fakenomad"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testKeyID     = "KEYID"
+	testACLToken  = "ACLTOKEN"
+	testAllocID   = "5b2d7ac1-4f6a-9c3e-63b9-02ba2e46b3e2"
+	testNodeID    = "f7a13b36-6f71-4c2a-93b0-6d2b1c9e2a41"
+	testNamespace = "spire"
+	testJobID     = "spire-agent"
+)
+
+var (
+	testAlloc = &nomad.Allocation{
+		ID:           testAllocID,
+		Name:         "spire-agent.agent[0]",
+		Namespace:    testNamespace,
+		NodeID:       testNodeID,
+		JobID:        testJobID,
+		TaskGroup:    "agent",
+		ClientStatus: "running",
+	}
+	testNode = &nomad.Node{
+		ID:         testNodeID,
+		Name:       "node1",
+		Datacenter: "dc1",
+		NodeClass:  "batch",
+		NodePool:   "default",
+		Meta: map[string]string{
+			"rack":   "r1",
+			"secret": "value",
+		},
+	}
+)
+
+func TestAttest(t *testing.T) {
+	key := testkey.NewRSA2048(t)
+	otherKey := testkey.NewRSA2048(t)
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name            string
+		payload         func() []byte
+		alloc           *nomad.Allocation
+		expectID        string
+		expectSelectors []string
+		expectCode      codes.Code
+		expectMsg       string
+	}{
+		{
+			name: "success",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, testKeyID, now, validClaims()))
+			},
+			expectID: "spiffe://example.org/spire/agent/nomad/prod/" + testNodeID,
+			expectSelectors: []string{
+				"alloc_id:" + testAllocID,
+				"cluster:prod",
+				"datacenter:dc1",
+				"job_id:spire-agent",
+				"namespace:spire",
+				"node_class:batch",
+				"node_id:" + testNodeID,
+				"node_meta:rack:r1",
+				"node_name:node1",
+				"node_pool:default",
+			},
+		},
+		{
+			name: "missing payload",
+			payload: func() []byte {
+				return nil
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "payload cannot be empty",
+		},
+		{
+			name: "malformed payload",
+			payload: func() []byte {
+				return []byte("{")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): failed to unmarshal data payload",
+		},
+		{
+			name: "missing cluster",
+			payload: func() []byte {
+				return makePayload("", "token")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): missing cluster in attestation data",
+		},
+		{
+			name: "missing token",
+			payload: func() []byte {
+				return makePayload("prod", "")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): missing token in attestation data",
+		},
+		{
+			name: "unknown cluster",
+			payload: func() []byte {
+				return makePayload("dev", "token")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `nodeattestor(nomad): not configured for cluster "dev"`,
+		},
+		{
+			name: "malformed token",
+			payload: func() []byte {
+				return makePayload("prod", "blah")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): unable to parse token",
+		},
+		{
+			name: "token missing key id",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, "", now, validClaims()))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): token missing key id",
+		},
+		{
+			name: "key id not found",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, "OTHERKEYID", now, validClaims()))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `nodeattestor(nomad): key id "OTHERKEYID" not found`,
+		},
+		{
+			name: "bad signature",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, otherKey, testKeyID, now, validClaims()))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): unable to verify token",
+		},
+		{
+			name: "unexpected audience",
+			payload: func() []byte {
+				claims := validClaims()
+				claims["aud"] = []string{"vault.io"}
+				return makePayload("prod", signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(nomad): unable to validate token claims: go-jose/go-jose/jwt: validation failed, invalid audience claim (aud)",
+		},
+		{
+			name: "expired token",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, testKeyID, now.Add(-time.Hour), validClaims()))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(nomad): unable to validate token claims: go-jose/go-jose/jwt: validation failed, token is expired (exp)",
+		},
+		{
+			name: "token missing allocation id",
+			payload: func() []byte {
+				claims := validClaims()
+				delete(claims, "nomad_allocation_id")
+				return makePayload("prod", signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(nomad): token missing allocation ID claim",
+		},
+		{
+			name: "job not allowed",
+			payload: func() []byte {
+				claims := validClaims()
+				claims["nomad_job_id"] = "web"
+				return makePayload("prod", signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(nomad): "spire/web" is not an allowed job for cluster "prod"`,
+		},
+		{
+			name: "allocation not found",
+			payload: func() []byte {
+				claims := validClaims()
+				claims["nomad_allocation_id"] = "f00f00f0-0000-0000-0000-000000000000"
+				return makePayload("prod", signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(nomad): allocation "f00f00f0-0000-0000-0000-000000000000" not found in cluster "prod"`,
+		},
+		{
+			name: "allocation of another job",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, testKeyID, now, validClaims()))
+			},
+			alloc: func() *nomad.Allocation {
+				alloc := *testAlloc
+				alloc.JobID = "web"
+				return &alloc
+			}(),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  fmt.Sprintf(`nodeattestor(nomad): allocation %q does not belong to job "spire/spire-agent"`, testAllocID),
+		},
+		{
+			name: "allocation not running",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, testKeyID, now, validClaims()))
+			},
+			alloc: func() *nomad.Allocation {
+				alloc := *testAlloc
+				alloc.ClientStatus = "complete"
+				return &alloc
+			}(),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  fmt.Sprintf(`nodeattestor(nomad): allocation %q is not running (status "complete")`, testAllocID),
+		},
+		{
+			name: "node not found",
+			payload: func() []byte {
+				return makePayload("prod", signToken(t, key, testKeyID, now, validClaims()))
+			},
+			alloc: func() *nomad.Allocation {
+				alloc := *testAlloc
+				alloc.NodeID = "unknown"
+				return &alloc
+			}(),
+			expectCode: codes.Internal,
+			expectMsg:  `nodeattestor(nomad): unable to get node from Nomad API for cluster "prod": not found`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakenomad.New(t, testACLToken)
+			server.SetKeySet(&jose.JSONWebKeySet{
+				Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: testKeyID, Algorithm: string(jose.RS256)}},
+			})
+			alloc := testAlloc
+			if tt.alloc != nil {
+				alloc = tt.alloc
+			}
+			server.AddAllocation(alloc)
+			server.AddNode(testNode)
+
+			attestor := loadPlugin(t, now, fmt.Sprintf(`
+				clusters = {
+					"prod" = {
+						address = %q
+						token = %q
+						job_allow_list = ["spire/spire-agent"]
+						allowed_node_meta_keys = ["rack"]
+					}
+				}
+			`, server.URL, testACLToken))
+
+			result, err := attestor.Attest(context.Background(), tt.payload(), expectNoChallenge)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			require.Equal(t, tt.expectID, result.AgentID)
+			var selectorValues []string
+			for _, selector := range result.Selectors {
+				require.Equal(t, pluginName, selector.Type)
+				selectorValues = append(selectorValues, selector.Value)
+			}
+			require.ElementsMatch(t, tt.expectSelectors, selectorValues)
+		})
+	}
+}
+
+func TestAttestWithUnauthorizedToken(t *testing.T) {
+	key := testkey.NewRSA2048(t)
+	now := time.Now()
+
+	server := fakenomad.New(t, testACLToken)
+	server.SetKeySet(&jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: testKeyID}},
+	})
+	server.AddAllocation(testAlloc)
+
+	attestor := loadPlugin(t, now, fmt.Sprintf(`
+		clusters = {
+			"prod" = {
+				address = %q
+				token = "WRONG"
+				job_allow_list = ["spire/spire-agent"]
+			}
+		}
+	`, server.URL))
+
+	_, err := attestor.Attest(context.Background(), makePayload("prod", signToken(t, key, testKeyID, now, validClaims())), expectNoChallenge)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, `nodeattestor(nomad): unable to get allocation from Nomad API for cluster "prod": unexpected status 403: Permission denied`)
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		config     string
+		expectCode codes.Code
+		expectMsg  string
+		verify     func(t *testing.T, config *attestorConfig)
+	}{
+		{
+			name:       "malformed",
+			config:     "{ not a config }",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name: "no clusters",
+			verify: func(t *testing.T, config *attestorConfig) {
+				require.Empty(t, config.clusters)
+			},
+		},
+		{
+			name: "missing address",
+			config: `clusters = {
+				"prod" = {
+					job_allow_list = ["spire/spire-agent"]
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `cluster "prod" configuration is missing the address`,
+		},
+		{
+			name: "invalid address",
+			config: `clusters = {
+				"prod" = {
+					address = "unix:///run/nomad.sock"
+					job_allow_list = ["spire/spire-agent"]
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `unable to create client for cluster "prod": invalid address "unix:///run/nomad.sock": must be an http or https URL`,
+		},
+		{
+			name: "missing allowed jobs",
+			config: `clusters = {
+				"prod" = {
+					address = "https://nomad.example.org:4646"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `cluster "prod" configuration must have at least one job allowed`,
+		},
+		{
+			name: "invalid CA certificates",
+			config: `clusters = {
+				"prod" = {
+					address = "https://nomad.example.org:4646"
+					ca_cert_path = "/does/not/exist"
+					job_allow_list = ["spire/spire-agent"]
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `unable to create client for cluster "prod": unable to load CA certificates`,
+		},
+		{
+			name: "defaults",
+			config: `clusters = {
+				"prod" = {
+					address = "https://nomad.example.org:4646"
+					job_allow_list = ["spire/spire-agent"]
+				}
+			}`,
+			verify: func(t *testing.T, config *attestorConfig) {
+				require.Equal(t, "example.org", config.trustDomain)
+				require.Len(t, config.clusters, 1)
+				require.Equal(t, []string{"spire-server"}, config.clusters["prod"].audience)
+				require.Equal(t, map[string]bool{"spire/spire-agent": true}, config.clusters["prod"].jobs)
+				require.Empty(t, config.clusters["prod"].allowedNodeMetaKeys)
+			},
+		},
+		{
+			name: "custom audience and node meta keys",
+			config: `clusters = {
+				"prod" = {
+					address = "https://nomad.example.org:4646"
+					job_allow_list = ["spire/spire-agent"]
+					audience = ["spiffe://example.org/spire/server"]
+					allowed_node_meta_keys = ["rack"]
+				}
+			}`,
+			verify: func(t *testing.T, config *attestorConfig) {
+				require.Equal(t, []string{"spiffe://example.org/spire/server"}, config.clusters["prod"].audience)
+				require.Equal(t, map[string]bool{"rack": true}, config.clusters["prod"].allowedNodeMetaKeys)
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			var err error
+			plugintest.Load(t, builtin(p), new(nodeattestor.V1),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+				}),
+				plugintest.Configure(tt.config),
+				plugintest.CaptureConfigureError(&err))
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				return
+			}
+			tt.verify(t, p.config)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, now time.Time, config string) nodeattestor.NodeAttestor {
+	p := New()
+	p.hooks.now = func() time.Time { return now }
+
+	v1 := new(nodeattestor.V1)
+	plugintest.Load(t, builtin(p), v1,
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(config),
+	)
+	return v1
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"aud":                 []string{"spire-server"},
+		"sub":                 "global:spire:spire-agent:agent:agent:spire-server",
+		"nomad_namespace":     testNamespace,
+		"nomad_job_id":        testJobID,
+		"nomad_allocation_id": testAllocID,
+		"nomad_task":          "agent",
+	}
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, keyID string, issuedAt time.Time, claims map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.RS256,
+		Key: jose.JSONWebKey{
+			Key:   key,
+			KeyID: keyID,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).
+		Claims(jwt.Claims{
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			Expiry:    jwt.NewNumericDate(issuedAt.Add(30 * time.Minute)),
+		}).
+		Claims(claims).
+		Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func makePayload(cluster, token string) []byte {
+	payload, _ := json.Marshal(nomad.AttestationData{Cluster: cluster, Token: token})
+	return payload
+}
+
+func expectNoChallenge(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("challenge is not expected")
+}
@@ -0,0 +1,578 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/agentpathtemplate"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/jwtutil"
+	"github.com/spiffe/spire/pkg/common/plugin/oidc"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	nodeattestorbase "github.com/spiffe/spire/pkg/server/plugin/nodeattestor/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = oidc.PluginName
+
+	// Tokens usually have the not-before ("nbf") claim. If there are clock
+	// differences between the issuer and the server then token validation
+	// may fail unless we give a little leeway.
+	tokenLeeway = time.Minute
+
+	defaultJWKSRefreshInterval = time.Hour
+
+	// defaultMaxTokenLifetime bounds how long a token is accepted for, since
+	// a leaked token can be used to attest until it expires.
+	defaultMaxTokenLifetime = 24 * time.Hour
+
+	// Names of the selectors generated for every token, which cannot be
+	// overridden with selector templates.
+	issuerSelector  = "issuer"
+	subjectSelector = "subject"
+)
+
+var (
+	defaultAudience = []string{"spire-server"}
+
+	// Accept the most common signature algorithms that are known to be secure.
+	allowedJWTSignatureAlgorithms = []jose.SignatureAlgorithm{
+		jose.RS256,
+		jose.RS384,
+		jose.RS512,
+		jose.ES256,
+		jose.ES384,
+		jose.ES512,
+		jose.PS256,
+		jose.PS384,
+		jose.PS512,
+		jose.EdDSA,
+	}
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// Config contains a map of issuers that uses an arbitrary name as key
+type Config struct {
+	Issuers map[string]*IssuerConfig `hcl:"issuers"`
+}
+
+// IssuerConfig holds a single issuer configuration
+type IssuerConfig struct {
+	// Issuer is the expected "iss" claim of the tokens. Unless JWKSURI or
+	// JWKSPath are set, it is also used to discover the JWKS through the
+	// OpenID Connect discovery document.
+	Issuer string `hcl:"issuer"`
+
+	// JWKSURI is the URI of the JWKS used to verify the tokens.
+	JWKSURI string `hcl:"jwks_uri"`
+
+	// JWKSPath is the path to a file holding the JWKS used to verify the
+	// tokens, for issuers that cannot be reached by the server.
+	JWKSPath string `hcl:"jwks_path"`
+
+	// JWKSRefreshInterval is how often the JWKS is refreshed when it is
+	// fetched from the issuer.
+	JWKSRefreshInterval string `hcl:"jwks_refresh_interval"`
+
+	// Audience for token validation
+	// If audience is not configured, defaultAudience will be used
+	Audience []string `hcl:"audience"`
+
+	// RequiredClaims maps claim names to the values allowed for them.
+	// Attestation is denied if the token does not have an allowed value for
+	// each of the claims.
+	RequiredClaims map[string][]string `hcl:"required_claims"`
+
+	// SelectorTemplates maps selector names to the templates that render
+	// their values from the claims of the token.
+	SelectorTemplates map[string]string `hcl:"selector_templates"`
+
+	// AgentPathTemplate is the template used to render the agent ID path
+	AgentPathTemplate string `hcl:"agent_path_template"`
+
+	// CanReattest, if true, allows agents to re-attest with a new token.
+	// Otherwise, each agent ID can only be attested once.
+	CanReattest bool `hcl:"can_reattest"`
+
+	// MaxTokenLifetime is the maximum lifetime of the accepted tokens,
+	// measured from the "iat" claim, or from the attestation time for tokens
+	// without one.
+	MaxTokenLifetime string `hcl:"max_token_lifetime"`
+}
+
+type issuerConfig struct {
+	name              string
+	issuer            string
+	audience          []string
+	keySetProvider    jwtutil.KeySetProvider
+	requiredClaims    map[string]map[string]bool
+	selectorTemplates map[string]*agentpathtemplate.Template
+	agentPathTemplate *agentpathtemplate.Template
+	canReattest       bool
+	maxTokenLifetime  time.Duration
+}
+
+type pluginConfig struct {
+	trustDomain spiffeid.TrustDomain
+	// issuers holds the issuer configurations keyed by the "iss" claim
+	issuers map[string]*issuerConfig
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *pluginConfig {
+	hclConfig := new(Config)
+	if err := hcl.Decode(hclConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if len(hclConfig.Issuers) < 1 {
+		status.ReportInfo("No issuers configured, OIDC attestation is effectively disabled")
+	}
+
+	newConfig := &pluginConfig{
+		trustDomain: coreConfig.TrustDomain,
+		issuers:     make(map[string]*issuerConfig),
+	}
+
+	for name, hclIssuer := range hclConfig.Issuers {
+		if hclIssuer.Issuer == "" {
+			status.ReportErrorf("issuer %q configuration is missing the issuer", name)
+			continue
+		}
+		if other, ok := newConfig.issuers[hclIssuer.Issuer]; ok {
+			status.ReportErrorf("issuers %q and %q have the same issuer %q", other.name, name, hclIssuer.Issuer)
+			continue
+		}
+
+		keySetProvider, err := buildKeySetProvider(hclIssuer)
+		if err != nil {
+			status.ReportErrorf("invalid issuer %q configuration: %v", name, err)
+			continue
+		}
+
+		if hclIssuer.AgentPathTemplate == "" {
+			status.ReportErrorf("issuer %q configuration is missing the agent path template", name)
+			continue
+		}
+		agentPathTemplate, err := agentpathtemplate.Parse(hclIssuer.AgentPathTemplate)
+		if err != nil {
+			status.ReportErrorf("failed to parse agent path template of issuer %q: %v", name, err)
+			continue
+		}
+
+		selectorTemplates := make(map[string]*agentpathtemplate.Template)
+		for selectorName, text := range hclIssuer.SelectorTemplates {
+			if selectorName == issuerSelector || selectorName == subjectSelector {
+				status.ReportErrorf("selector %q of issuer %q is reserved and cannot be templated", selectorName, name)
+				continue
+			}
+			tmpl, err := agentpathtemplate.Parse(text)
+			if err != nil {
+				status.ReportErrorf("failed to parse template of selector %q of issuer %q: %v", selectorName, name, err)
+				continue
+			}
+			selectorTemplates[selectorName] = tmpl
+		}
+
+		requiredClaims := make(map[string]map[string]bool)
+		for claim, values := range hclIssuer.RequiredClaims {
+			if len(values) == 0 {
+				status.ReportErrorf("required claim %q of issuer %q must have at least one allowed value", claim, name)
+				continue
+			}
+			requiredClaims[claim] = make(map[string]bool)
+			for _, value := range values {
+				requiredClaims[claim][value] = true
+			}
+		}
+		if len(requiredClaims) == 0 {
+			status.ReportInfof("Issuer %q has no required claims; any token from the issuer for the audience will be attested", name)
+		}
+
+		audience := hclIssuer.Audience
+		if len(audience) == 0 {
+			audience = defaultAudience
+		}
+
+		maxTokenLifetime := defaultMaxTokenLifetime
+		if hclIssuer.MaxTokenLifetime != "" {
+			maxTokenLifetime, err = time.ParseDuration(hclIssuer.MaxTokenLifetime)
+			if err != nil || maxTokenLifetime <= 0 {
+				status.ReportErrorf("invalid max_token_lifetime of issuer %q: %q", name, hclIssuer.MaxTokenLifetime)
+				continue
+			}
+		}
+
+		newConfig.issuers[hclIssuer.Issuer] = &issuerConfig{
+			name:              name,
+			issuer:            hclIssuer.Issuer,
+			audience:          audience,
+			keySetProvider:    keySetProvider,
+			requiredClaims:    requiredClaims,
+			selectorTemplates: selectorTemplates,
+			agentPathTemplate: agentPathTemplate,
+			canReattest:       hclIssuer.CanReattest,
+			maxTokenLifetime:  maxTokenLifetime,
+		}
+	}
+
+	return newConfig
+}
+
+func buildKeySetProvider(c *IssuerConfig) (jwtutil.KeySetProvider, error) {
+	if c.JWKSURI != "" && c.JWKSPath != "" {
+		return nil, errors.New("jwks_uri and jwks_path are mutually exclusive")
+	}
+
+	if c.JWKSPath != "" {
+		if c.JWKSRefreshInterval != "" {
+			return nil, errors.New("jwks_refresh_interval cannot be used with jwks_path")
+		}
+		jwks, err := loadKeySet(c.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+		return jwtutil.KeySetProviderFunc(func(context.Context) (*jose.JSONWebKeySet, error) {
+			return jwks, nil
+		}), nil
+	}
+
+	refreshInterval := defaultJWKSRefreshInterval
+	if c.JWKSRefreshInterval != "" {
+		var err error
+		refreshInterval, err = time.ParseDuration(c.JWKSRefreshInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwks_refresh_interval: %w", err)
+		}
+	}
+
+	var provider jwtutil.KeySetProvider = jwtutil.OIDCIssuer(c.Issuer)
+	if c.JWKSURI != "" {
+		jwksURI := c.JWKSURI
+		provider = jwtutil.KeySetProviderFunc(func(ctx context.Context) (*jose.JSONWebKeySet, error) {
+			return jwtutil.FetchKeySet(ctx, jwksURI)
+		})
+	}
+	return jwtutil.NewCachingKeySetProvider(provider, refreshInterval), nil
+}
+
+func loadKeySet(path string) (*jose.JSONWebKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read JWKS file: %w", err)
+	}
+	jwks := new(jose.JSONWebKeySet)
+	if err := json.Unmarshal(data, jwks); err != nil {
+		return nil, fmt.Errorf("unable to parse JWKS file: %w", err)
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %q has no keys", path)
+	}
+	return jwks, nil
+}
+
+// Plugin is a generic OIDC node attestor plugin
+type Plugin struct {
+	nodeattestorbase.Base
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+
+	mu     sync.RWMutex
+	config *pluginConfig
+
+	hooks struct {
+		now func() time.Time
+	}
+}
+
+var _ nodeattestorv1.NodeAttestorServer = (*Plugin)(nil)
+
+// New creates a new OIDC node attestor plugin
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.now = time.Now
+	return p
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	payload := req.GetPayload()
+	if payload == nil {
+		return status.Error(codes.InvalidArgument, "missing attestation payload")
+	}
+
+	attestationData := new(oidc.AttestationData)
+	if err := json.Unmarshal(payload, attestationData); err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to unmarshal data payload: %v", err)
+	}
+
+	if attestationData.Token == "" {
+		return status.Error(codes.InvalidArgument, "missing token in attestation data")
+	}
+
+	token, err := jwt.ParseSigned(attestationData.Token, allowedJWTSignatureAlgorithms)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to parse token: %v", err)
+	}
+
+	// The issuer is used to pick the keys that verify the token, so it is
+	// read before the token is verified. It is validated afterwards.
+	unverifiedClaims := new(jwt.Claims)
+	if err := token.UnsafeClaimsWithoutVerification(unverifiedClaims); err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to read token claims: %v", err)
+	}
+	issuer, ok := config.issuers[unverifiedClaims.Issuer]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "token issuer %q is not configured", unverifiedClaims.Issuer)
+	}
+
+	claims, allClaims, err := p.verifyToken(stream.Context(), issuer, token)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRequiredClaims(issuer.requiredClaims, allClaims); err != nil {
+		return status.Errorf(codes.PermissionDenied, "token does not satisfy the required claims of issuer %q: %v", issuer.name, err)
+	}
+
+	templateData := oidc.TemplateData{
+		PluginName: pluginName,
+		IssuerName: issuer.name,
+		Issuer:     claims.Issuer,
+		Subject:    claims.Subject,
+		Claims:     allClaims,
+	}
+
+	agentID, err := oidc.MakeAgentID(config.trustDomain, issuer.agentPathTemplate, templateData)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to make agent ID: %v", err)
+	}
+
+	if !issuer.canReattest {
+		if err := p.AssessTOFU(stream.Context(), agentID.String(), p.log); err != nil {
+			return err
+		}
+	}
+
+	return stream.Send(&nodeattestorv1.AttestResponse{
+		Response: &nodeattestorv1.AttestResponse_AgentAttributes{
+			AgentAttributes: &nodeattestorv1.AgentAttributes{
+				SpiffeId:       agentID.String(),
+				CanReattest:    issuer.canReattest,
+				SelectorValues: p.buildSelectorValues(issuer, templateData),
+			},
+		},
+	})
+}
+
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+func (p *Plugin) getConfig() (*pluginConfig, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+// verifyToken verifies the signature of the token against the keys of the
+// issuer and validates its registered claims. It returns the registered
+// claims along with all the claims of the token.
+func (p *Plugin) verifyToken(ctx context.Context, issuer *issuerConfig, token *jwt.JSONWebToken) (*jwt.Claims, map[string]any, error) {
+	keySet, err := issuer.keySetProvider.GetKeySet(ctx)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "unable to obtain JWKS of issuer %q: %v", issuer.name, err)
+	}
+
+	// Tokens without a key ID are accepted when signed by any of the keys,
+	// which is common for key sets that only have one key.
+	keys := keySet.Keys
+	if keyID, ok := jwtutil.GetTokenKeyID(token); ok {
+		keys = keySet.Key(keyID)
+		if len(keys) == 0 {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "key id %q not found", keyID)
+		}
+	}
+
+	claims := new(jwt.Claims)
+	allClaims := make(map[string]any)
+	verified := false
+	for i := range keys {
+		if err := token.Claims(&keys[i], claims, &allClaims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, nil, status.Error(codes.InvalidArgument, "unable to verify token signature")
+	}
+	normalizeClaims(allClaims)
+
+	// The expiration is only validated when the token has one, so tokens
+	// without it would be accepted forever.
+	if claims.Expiry == nil {
+		return nil, nil, status.Error(codes.PermissionDenied, "token missing exp claim")
+	}
+
+	now := p.hooks.now()
+	if err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      issuer.issuer,
+		AnyAudience: issuer.audience,
+		Time:        now,
+	}, tokenLeeway); err != nil {
+		return nil, nil, status.Errorf(codes.PermissionDenied, "unable to validate token claims: %v", err)
+	}
+
+	issuedAt := now
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time()
+	}
+	if lifetime := claims.Expiry.Time().Sub(issuedAt); lifetime > issuer.maxTokenLifetime+tokenLeeway {
+		return nil, nil, status.Errorf(codes.PermissionDenied, "token lifetime %s exceeds the maximum of %s", lifetime, issuer.maxTokenLifetime)
+	}
+
+	return claims, allClaims, nil
+}
+
+// buildSelectorValues returns the selectors of the agent. Selectors whose
+// template cannot be rendered, e.g. because the token does not have a claim
+// used by the template, are omitted.
+func (p *Plugin) buildSelectorValues(issuer *issuerConfig, data oidc.TemplateData) []string {
+	selectorValues := []string{
+		fmt.Sprintf("%s:%s", issuerSelector, issuer.name),
+	}
+	if data.Subject != "" {
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", subjectSelector, data.Subject))
+	}
+
+	for name, tmpl := range issuer.selectorTemplates {
+		value, err := tmpl.Execute(data)
+		if err != nil {
+			p.log.Debug("Omitting selector that cannot be rendered", "issuer", issuer.name, "selector", name, "error", err)
+			continue
+		}
+		selectorValues = append(selectorValues, fmt.Sprintf("%s:%s", name, value))
+	}
+
+	sort.Strings(selectorValues)
+	return selectorValues
+}
+
+// checkRequiredClaims verifies that the token has an allowed value for each of
+// the required claims. For claims holding a list, e.g. "groups", any of the
+// values of the list must be allowed.
+func checkRequiredClaims(requiredClaims map[string]map[string]bool, claims map[string]any) error {
+	for claim, allowed := range requiredClaims {
+		value, ok := claims[claim]
+		if !ok {
+			return fmt.Errorf("missing claim %q", claim)
+		}
+
+		values := []any{value}
+		if list, ok := value.([]any); ok {
+			values = list
+		}
+
+		satisfied := false
+		for _, v := range values {
+			if allowed[claimValueString(v)] {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			return fmt.Errorf("claim %q has a value that is not allowed", claim)
+		}
+	}
+	return nil
+}
+
+func claimValueString(value any) string {
+	if v, ok := value.(string); ok {
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// normalizeClaims converts the integral JSON numbers of the claims, which are
+// decoded as float64, to int64 so that they are rendered without an exponent
+// by the templates (e.g. a "run_id" of 12345678 instead of 1.2345678e+07).
+func normalizeClaims(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeClaims(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeClaims(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,693 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/agentstore/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/oidc"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/test/fakes/fakeagentstore"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testKeyID = "KEYID"
+)
+
+func TestAttest(t *testing.T) {
+	key := testkey.NewEC256(t)
+	otherKey := testkey.NewEC256(t)
+	now := time.Now()
+	issuer := startIssuer(t, key)
+
+	config := fmt.Sprintf(`
+		issuers = {
+			"ci" = {
+				issuer = %q
+				audience = ["spire"]
+				required_claims = {
+					repository_owner = ["acme"]
+					run_attempt = ["1", "2"]
+				}
+				selector_templates = {
+					repository = "{{ .Claims.repository }}"
+					ref = "{{ .Claims.ref }}"
+					environment = "{{ .Claims.environment }}"
+				}
+				agent_path_template = "/{{ .PluginName }}/{{ .IssuerName }}/{{ .Claims.repository | replace \"/\" \"_\" }}/{{ .Claims.run_id }}"
+			}
+		}
+	`, issuer.URL)
+
+	for _, tt := range []struct {
+		name            string
+		payload         func() []byte
+		setup           func(agentStore *fakeagentstore.AgentStore)
+		expectID        string
+		expectSelectors []string
+		expectCode      codes.Code
+		expectMsg       string
+	}{
+		{
+			name: "success",
+			payload: func() []byte {
+				return makePayload(signToken(t, key, testKeyID, now, validClaims(issuer.URL)))
+			},
+			expectID: "spiffe://example.org/spire/agent/oidc/ci/acme_widgets/12345678",
+			expectSelectors: []string{
+				"issuer:ci",
+				"ref:refs/heads/main",
+				"repository:acme/widgets",
+				"subject:repo:acme/widgets:ref:refs/heads/main",
+			},
+		},
+		{
+			name: "missing payload",
+			payload: func() []byte {
+				return nil
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "payload cannot be empty",
+		},
+		{
+			name: "malformed payload",
+			payload: func() []byte {
+				return []byte("{")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): failed to unmarshal data payload",
+		},
+		{
+			name: "missing token",
+			payload: func() []byte {
+				return makePayload("")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): missing token in attestation data",
+		},
+		{
+			name: "malformed token",
+			payload: func() []byte {
+				return makePayload("blah")
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): unable to parse token",
+		},
+		{
+			name: "unknown issuer",
+			payload: func() []byte {
+				return makePayload(signToken(t, key, testKeyID, now, validClaims("https://issuer.example.org")))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): token issuer "https://issuer.example.org" is not configured`,
+		},
+		{
+			name: "key id not found",
+			payload: func() []byte {
+				return makePayload(signToken(t, key, "OTHERKEYID", now, validClaims(issuer.URL)))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `nodeattestor(oidc): key id "OTHERKEYID" not found`,
+		},
+		{
+			name: "bad signature",
+			payload: func() []byte {
+				return makePayload(signToken(t, otherKey, testKeyID, now, validClaims(issuer.URL)))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): unable to verify token signature",
+		},
+		{
+			name: "token without key id",
+			payload: func() []byte {
+				return makePayload(signToken(t, key, "", now, validClaims(issuer.URL)))
+			},
+			expectID: "spiffe://example.org/spire/agent/oidc/ci/acme_widgets/12345678",
+			expectSelectors: []string{
+				"issuer:ci",
+				"ref:refs/heads/main",
+				"repository:acme/widgets",
+				"subject:repo:acme/widgets:ref:refs/heads/main",
+			},
+		},
+		{
+			name: "unexpected audience",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				claims["aud"] = "sts.amazonaws.com"
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): unable to validate token claims: go-jose/go-jose/jwt: validation failed, invalid audience claim (aud)",
+		},
+		{
+			name: "expired token",
+			payload: func() []byte {
+				return makePayload(signToken(t, key, testKeyID, now.Add(-time.Hour), validClaims(issuer.URL)))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): unable to validate token claims: go-jose/go-jose/jwt: validation failed, token is expired (exp)",
+		},
+		{
+			name: "token without expiration",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				claims["iat"] = now.Unix()
+				return makePayload(signClaims(t, key, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): token missing exp claim",
+		},
+		{
+			name: "token lifetime exceeds the maximum",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				claims["exp"] = now.Add(48 * time.Hour).Unix()
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): token lifetime 48h0m0s exceeds the maximum of 24h0m0s",
+		},
+		{
+			name: "required claim missing",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				delete(claims, "repository_owner")
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): token does not satisfy the required claims of issuer "ci": missing claim "repository_owner"`,
+		},
+		{
+			name: "required claim not allowed",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				claims["repository_owner"] = "evil"
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): token does not satisfy the required claims of issuer "ci": claim "repository_owner" has a value that is not allowed`,
+		},
+		{
+			name: "numeric required claim",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				claims["run_attempt"] = 2
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectID: "spiffe://example.org/spire/agent/oidc/ci/acme_widgets/12345678",
+			expectSelectors: []string{
+				"issuer:ci",
+				"ref:refs/heads/main",
+				"repository:acme/widgets",
+				"subject:repo:acme/widgets:ref:refs/heads/main",
+			},
+		},
+		{
+			name: "optional claims rendered",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				claims["environment"] = "production"
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectID: "spiffe://example.org/spire/agent/oidc/ci/acme_widgets/12345678",
+			expectSelectors: []string{
+				"environment:production",
+				"issuer:ci",
+				"ref:refs/heads/main",
+				"repository:acme/widgets",
+				"subject:repo:acme/widgets:ref:refs/heads/main",
+			},
+		},
+		{
+			name: "claim missing from agent path template",
+			payload: func() []byte {
+				claims := validClaims(issuer.URL)
+				delete(claims, "run_id")
+				return makePayload(signToken(t, key, testKeyID, now, claims))
+			},
+			expectCode: codes.Internal,
+			expectMsg:  `map has no entry for key "run_id"`,
+		},
+		{
+			name: "already attested",
+			payload: func() []byte {
+				return makePayload(signToken(t, key, testKeyID, now, validClaims(issuer.URL)))
+			},
+			setup: func(agentStore *fakeagentstore.AgentStore) {
+				agentStore.SetAgentInfo(&agentstorev1.AgentInfo{
+					AgentId: "spiffe://example.org/spire/agent/oidc/ci/acme_widgets/12345678",
+				})
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): attestation data has already been used to attest an agent",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			agentStore := fakeagentstore.New()
+			if tt.setup != nil {
+				tt.setup(agentStore)
+			}
+			attestor := loadPlugin(t, now, agentStore, config)
+
+			result, err := attestor.Attest(context.Background(), tt.payload(), expectNoChallenge)
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			require.Equal(t, tt.expectID, result.AgentID)
+			require.False(t, result.CanReattest)
+			var selectorValues []string
+			for _, selector := range result.Selectors {
+				require.Equal(t, pluginName, selector.Type)
+				selectorValues = append(selectorValues, selector.Value)
+			}
+			require.Equal(t, tt.expectSelectors, selectorValues)
+		})
+	}
+}
+
+func TestAttestWithKeySetSources(t *testing.T) {
+	key := testkey.NewEC256(t)
+	now := time.Now()
+	issuer := startIssuer(t, key)
+
+	jwksPath := filepath.Join(spiretest.TempDir(t), "jwks.json")
+	jwksBytes, err := json.Marshal(keySet(key))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(jwksPath, jwksBytes, 0o600))
+
+	for _, tt := range []struct {
+		name   string
+		issuer string
+		config string
+	}{
+		{
+			name:   "discovery",
+			issuer: issuer.URL,
+			config: fmt.Sprintf(`issuer = %q`, issuer.URL),
+		},
+		{
+			name:   "jwks uri",
+			issuer: "https://issuer.example.org",
+			config: fmt.Sprintf(`
+				issuer = "https://issuer.example.org"
+				jwks_uri = "%s/keys"
+			`, issuer.URL),
+		},
+		{
+			name:   "jwks file",
+			issuer: "https://issuer.example.org",
+			config: fmt.Sprintf(`
+				issuer = "https://issuer.example.org"
+				jwks_path = %q
+			`, jwksPath),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			attestor := loadPlugin(t, now, fakeagentstore.New(), fmt.Sprintf(`
+				issuers = {
+					"k8s" = {
+						%s
+						agent_path_template = "/{{ .PluginName }}/{{ .IssuerName }}/{{ .Subject | sha256sum | trunc 16 }}"
+						can_reattest = true
+					}
+				}
+			`, tt.config))
+
+			claims := map[string]any{
+				"iss": tt.issuer,
+				"aud": "spire-server",
+				"sub": "system:serviceaccount:spire:spire-agent",
+			}
+
+			result, err := attestor.Attest(context.Background(), makePayload(signToken(t, key, testKeyID, now, claims)), expectNoChallenge)
+			require.NoError(t, err)
+			require.Equal(t, "spiffe://example.org/spire/agent/oidc/k8s/76e27855922c5974", result.AgentID)
+			require.True(t, result.CanReattest)
+		})
+	}
+}
+
+func TestAttestWithUnreachableIssuer(t *testing.T) {
+	key := testkey.NewEC256(t)
+	now := time.Now()
+	issuer := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(issuer.Close)
+
+	attestor := loadPlugin(t, now, fakeagentstore.New(), fmt.Sprintf(`
+		issuers = {
+			"ci" = {
+				issuer = %q
+				agent_path_template = "/{{ .PluginName }}/{{ .Subject }}"
+			}
+		}
+	`, issuer.URL))
+
+	claims := map[string]any{"iss": issuer.URL, "aud": "spire-server", "sub": "agent"}
+	_, err := attestor.Attest(context.Background(), makePayload(signToken(t, key, testKeyID, now, claims)), expectNoChallenge)
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, `nodeattestor(oidc): unable to obtain JWKS of issuer "ci": unexpected status code 404`)
+}
+
+func TestConfigure(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	emptyJWKSPath := filepath.Join(dir, "empty.json")
+	require.NoError(t, os.WriteFile(emptyJWKSPath, []byte(`{"keys":[]}`), 0o600))
+
+	for _, tt := range []struct {
+		name       string
+		config     string
+		expectCode codes.Code
+		expectMsg  string
+		verify     func(t *testing.T, config *pluginConfig)
+	}{
+		{
+			name:       "malformed",
+			config:     "{ not a config }",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name: "no issuers",
+			verify: func(t *testing.T, config *pluginConfig) {
+				require.Empty(t, config.issuers)
+			},
+		},
+		{
+			name: "missing issuer",
+			config: `issuers = {
+				"ci" = {
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `issuer "ci" configuration is missing the issuer`,
+		},
+		{
+			name: "missing agent path template",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `issuer "ci" configuration is missing the agent path template`,
+		},
+		{
+			name: "invalid agent path template",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject "
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `failed to parse agent path template of issuer "ci"`,
+		},
+		{
+			name: "invalid selector template",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject }}"
+					selector_templates = {
+						repository = "{{ .Claims.repository "
+					}
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `failed to parse template of selector "repository" of issuer "ci"`,
+		},
+		{
+			name: "reserved selector template",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject }}"
+					selector_templates = {
+						subject = "{{ .Claims.repository }}"
+					}
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `selector "subject" of issuer "ci" is reserved and cannot be templated`,
+		},
+		{
+			name: "required claim without values",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject }}"
+					required_claims = {
+						repository_owner = []
+					}
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `required claim "repository_owner" of issuer "ci" must have at least one allowed value`,
+		},
+		{
+			name: "both jwks uri and path",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					jwks_uri = "https://issuer.example.org/keys"
+					jwks_path = "/keys.json"
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid issuer "ci" configuration: jwks_uri and jwks_path are mutually exclusive`,
+		},
+		{
+			name: "refresh interval with jwks path",
+			config: fmt.Sprintf(`issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					jwks_path = %q
+					jwks_refresh_interval = "1m"
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`, emptyJWKSPath),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid issuer "ci" configuration: jwks_refresh_interval cannot be used with jwks_path`,
+		},
+		{
+			name: "invalid refresh interval",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					jwks_refresh_interval = "often"
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid issuer "ci" configuration: invalid jwks_refresh_interval`,
+		},
+		{
+			name: "invalid max token lifetime",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					max_token_lifetime = "-1h"
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid max_token_lifetime of issuer "ci": "-1h"`,
+		},
+		{
+			name: "missing jwks file",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					jwks_path = "/does/not/exist.json"
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid issuer "ci" configuration: unable to read JWKS file`,
+		},
+		{
+			name: "empty jwks file",
+			config: fmt.Sprintf(`issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					jwks_path = %q
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`, emptyJWKSPath),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  fmt.Sprintf(`invalid issuer "ci" configuration: JWKS file %q has no keys`, emptyJWKSPath),
+		},
+		{
+			name: "duplicated issuer",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject }}"
+				}
+				"ci2" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject }}"
+				}
+			}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `have the same issuer "https://issuer.example.org"`,
+		},
+		{
+			name: "success",
+			config: `issuers = {
+				"ci" = {
+					issuer = "https://issuer.example.org"
+					agent_path_template = "/{{ .Subject }}"
+					required_claims = {
+						repository_owner = ["acme", "acme-labs"]
+					}
+					selector_templates = {
+						repository = "{{ .Claims.repository }}"
+					}
+				}
+			}`,
+			verify: func(t *testing.T, config *pluginConfig) {
+				require.Len(t, config.issuers, 1)
+				issuer := config.issuers["https://issuer.example.org"]
+				require.Equal(t, "ci", issuer.name)
+				require.Equal(t, []string{"spire-server"}, issuer.audience)
+				require.Equal(t, map[string]map[string]bool{
+					"repository_owner": {"acme": true, "acme-labs": true},
+				}, issuer.requiredClaims)
+				require.Contains(t, issuer.selectorTemplates, "repository")
+				require.False(t, issuer.canReattest)
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			var err error
+			plugintest.Load(t, builtin(p), new(nodeattestor.V1),
+				plugintest.HostServices(agentstorev1.AgentStoreServiceServer(fakeagentstore.New())),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+				}),
+				plugintest.Configure(tt.config),
+				plugintest.CaptureConfigureError(&err))
+			spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				return
+			}
+			tt.verify(t, p.config)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, now time.Time, agentStore *fakeagentstore.AgentStore, config string) nodeattestor.NodeAttestor {
+	p := New()
+	p.hooks.now = func() time.Time { return now }
+
+	v1 := new(nodeattestor.V1)
+	plugintest.Load(t, builtin(p), v1,
+		plugintest.HostServices(agentstorev1.AgentStoreServiceServer(agentStore)),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(config),
+	)
+	return v1
+}
+
+// startIssuer starts a fake OIDC issuer serving the discovery document and
+// the JWKS with the given key.
+func startIssuer(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL,
+			"jwks_uri": server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(keySet(key))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func keySet(key *ecdsa.PrivateKey) *jose.JSONWebKeySet {
+	return &jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: testKeyID, Algorithm: string(jose.ES256)}},
+	}
+}
+
+func validClaims(issuer string) map[string]any {
+	return map[string]any{
+		"iss":              issuer,
+		"aud":              "spire",
+		"sub":              "repo:acme/widgets:ref:refs/heads/main",
+		"repository":       "acme/widgets",
+		"repository_owner": "acme",
+		"ref":              "refs/heads/main",
+		"run_id":           12345678,
+		"run_attempt":      "1",
+	}
+}
+
+func signToken(t *testing.T, key *ecdsa.PrivateKey, keyID string, issuedAt time.Time, claims map[string]any) string {
+	signingKey := jose.SigningKey{Algorithm: jose.ES256, Key: key}
+	if keyID != "" {
+		signingKey.Key = jose.JSONWebKey{Key: key, KeyID: keyID}
+	}
+	signer, err := jose.NewSigner(signingKey, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).
+		Claims(jwt.Claims{
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			Expiry:    jwt.NewNumericDate(issuedAt.Add(10 * time.Minute)),
+		}).
+		Claims(claims).
+		Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func signClaims(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func makePayload(token string) []byte {
+	payload, _ := json.Marshal(oidc.AttestationData{Token: token})
+	return payload
+}
+
+func expectNoChallenge(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("challenge is not expected")
+}
@@ -2,6 +2,7 @@ package upstreamauthority
 
 type Repository struct {
 	UpstreamAuthority UpstreamAuthority
+	WITKeyPublisher   WITKeyPublisher
 }
 
 func (repo *Repository) GetUpstreamAuthority() (UpstreamAuthority, bool) {
@@ -16,6 +17,19 @@ func (repo *Repository) ClearUpstreamAuthority() {
 	repo.UpstreamAuthority = nil
 }
 
+func (repo *Repository) GetWITKeyPublisher() (WITKeyPublisher, bool) {
+	return repo.WITKeyPublisher, repo.WITKeyPublisher != nil
+}
+
+func (repo *Repository) SetWITKeyPublisher(witKeyPublisher WITKeyPublisher) {
+	repo.WITKeyPublisher = witKeyPublisher
+}
+
+func (repo *Repository) ClearWITKeyPublisher() {
+	repo.WITKeyPublisher = nil
+}
+
 func (repo *Repository) Clear() {
 	repo.UpstreamAuthority = nil
+	repo.WITKeyPublisher = nil
 }
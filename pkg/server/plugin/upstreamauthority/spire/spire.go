@@ -17,10 +17,13 @@ import (
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/coretypes/bundle"
 	"github.com/spiffe/spire/pkg/common/coretypes/jwtkey"
+	"github.com/spiffe/spire/pkg/common/coretypes/witkey"
 	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/pluginconf"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -69,12 +72,14 @@ func builtin(p *Plugin) catalog.BuiltIn {
 	return catalog.MakeBuiltIn(pluginName,
 		upstreamauthorityv1.UpstreamAuthorityPluginServer(p),
 		configv1.ConfigServiceServer(p),
+		upstreamauthority.WITKeyPublisherServiceServer(p),
 	)
 }
 
 type Plugin struct {
 	upstreamauthorityv1.UnsafeUpstreamAuthorityServer
 	configv1.UnsafeConfigServer
+	witkeypublisher.UnsafeWITKeyPublisherServer
 
 	clk clock.Clock
 	log hclog.Logger
@@ -310,6 +315,62 @@ func (p *Plugin) PublishJWTKeyAndSubscribe(req *upstreamauthorityv1.PublishJWTKe
 	return nil
 }
 
+// PublishWITKeyAndSubscribe publishes the WIT key with the upstream server and
+// streams the upstream WIT authorities, so that WIT authorities tainted or
+// revoked upstream are also tainted or revoked by the downstream server.
+func (p *Plugin) PublishWITKeyAndSubscribe(req *witkeypublisher.PublishWITKeyAndSubscribeRequest, stream witkeypublisher.WITKeyPublisher_PublishWITKeyAndSubscribeServer) error {
+	err := p.subscribeToPolling(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer p.unsubscribeToPolling()
+
+	witKey, err := witkey.ToAPIFromPublisherProto(req.WitKey)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to parse WITKey into api WITKey: %v", err)
+	}
+
+	// Publish WIT authority
+	resp, err := p.serverClient.publishWITAuthority(stream.Context(), witKey)
+	if err != nil {
+		return err
+	}
+
+	witKeys, err := witkey.ToPluginFromAPIProtos(resp)
+	if err != nil {
+		return err
+	}
+
+	// Set WIT authority
+	p.setBundleWITAuthorities(witKeys)
+
+	err = sendWITKeys(stream, witKeys)
+	if err != nil {
+		p.log.Error("Cannot send upstream WIT keys", "error", err)
+		return err
+	}
+
+	ticker := p.clk.Ticker(internalPollFreq)
+	defer ticker.Stop()
+	for {
+		updateCh := p.getBundleUpdateCh()
+
+		newWITKeys := p.getBundle().WitAuthorities
+		if !arePublicKeysEqual(witKeys, newWITKeys) {
+			err := sendWITKeys(stream, newWITKeys)
+			if err == nil {
+				witKeys = newWITKeys
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-updateCh:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
 func (p *Plugin) pollBundleUpdates(ctx context.Context) {
 	ticker := p.clk.Ticker(upstreamPollFreq)
 	defer ticker.Stop()
@@ -398,6 +459,24 @@ func (p *Plugin) setBundleJWTAuthorities(keys []*plugintypes.JWTKey) {
 	p.signalBundleUpdate()
 }
 
+func sendWITKeys(stream witkeypublisher.WITKeyPublisher_PublishWITKeyAndSubscribeServer, witKeys []*plugintypes.WITKey) error {
+	upstreamWITAuthorities, err := witkey.ToPublisherFromPluginProtos(witKeys)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to convert WIT authorities: %v", err)
+	}
+	return stream.Send(&witkeypublisher.PublishWITKeyAndSubscribeResponse{
+		UpstreamWitAuthorities: upstreamWITAuthorities,
+	})
+}
+
+func (p *Plugin) setBundleWITAuthorities(keys []*plugintypes.WITKey) {
+	p.bundleMtx.Lock()
+	defer p.bundleMtx.Unlock()
+	p.currentBundle.WitAuthorities = keys
+	p.bundleVersion++
+	p.signalBundleUpdate()
+}
+
 func (p *Plugin) setBundleX509Authorities(rootCAs []*plugintypes.X509Certificate) {
 	p.bundleMtx.Lock()
 	defer p.bundleMtx.Unlock()
@@ -467,7 +546,7 @@ func areRootsEqual(a, b []*plugintypes.X509Certificate) bool {
 	return true
 }
 
-func arePublicKeysEqual(a, b []*plugintypes.JWTKey) bool {
+func arePublicKeysEqual[K proto.Message](a, b []K) bool {
 	if len(a) != len(b) {
 		return false
 	}
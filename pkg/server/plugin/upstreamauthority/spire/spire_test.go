@@ -424,6 +424,84 @@ func TestPublishJWTKey(t *testing.T) {
 	spiretest.RequireGRPCStatusHasPrefix(t, err, codes.Internal, "upstreamauthority(spire): failed to push JWT authority: rpc error: code = Unknown desc = some erro")
 }
 
+func TestPublishWITKey(t *testing.T) {
+	ca := testca.New(t, trustDomain)
+	serverCert, serverKey := ca.CreateX509Certificate(
+		testca.WithID(spiffeid.RequireFromPath(trustDomain, "/spire/server")),
+	)
+	s := ca.CreateX509SVID(
+		spiffeid.RequireFromPath(trustDomain, "/workload"),
+	)
+	svidCert, svidKey, err := s.MarshalRaw()
+	require.NoError(t, err)
+
+	key := testkey.NewEC256(t)
+	pkixBytes, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	key2 := testkey.NewEC256(t)
+	pkixBytes2, err := x509.MarshalPKIXPublicKey(key2.Public())
+	require.NoError(t, err)
+
+	// Setup servers
+	mockClock := clock.NewMock(t)
+	server := testHandler{}
+	server.startTestServers(t, mockClock, ca, serverCert, serverKey, svidCert, svidKey)
+	_, witKeyPublisher := newWITKeyPublisherWithDefault(t, mockClock, server.sAPIServer.addr, server.wAPIServer.workloadAPIAddr)
+
+	// Get first response
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	upstreamWitKeys, stream, err := witKeyPublisher.PublishWITKey(ctx, &common.PublicKey{
+		Kid:       "kid-1",
+		PkixBytes: pkixBytes,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, stream)
+	require.Len(t, upstreamWitKeys, 1)
+	assert.Equal(t, "kid-1", upstreamWitKeys[0].Kid)
+	assert.Equal(t, pkixBytes, upstreamWitKeys[0].PkixBytes)
+
+	// Taint the published key and add another one upstream. Advance the
+	// clock past the upstream poll frequency to trigger a fetch.
+	server.sAPIServer.setBundle(&types.Bundle{
+		TrustDomain: trustDomain.Name(),
+		WitAuthorities: []*types.WITKey{
+			{KeyId: "kid-1", PublicKey: pkixBytes, Tainted: true},
+			{KeyId: "kid-2", PublicKey: pkixBytes2},
+		},
+	})
+	mockClock.Add(upstreamPollFreq)
+	mockClock.Add(upstreamPollFreq)
+
+	// Get WIT authorities update
+	resp, err := stream.RecvUpstreamWITAuthorities()
+	require.NoError(t, err)
+	require.Len(t, resp, 2)
+	assert.Equal(t, "kid-1", resp[0].Kid)
+	assert.True(t, resp[0].TaintedKey)
+	assert.Equal(t, "kid-2", resp[1].Kid)
+	assert.Equal(t, pkixBytes2, resp[1].PkixBytes)
+
+	// Cancel ctx to stop getting updates
+	cancel()
+
+	// Verify stream is closed
+	resp, err = stream.RecvUpstreamWITAuthorities()
+	require.Nil(t, resp)
+	spiretest.RequireGRPCStatusHasPrefix(t, err, codes.Canceled, "upstreamauthority(spire): context canceled")
+
+	// Fail to push WIT authority
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server.sAPIServer.setError(errors.New("some error"))
+	upstreamWitKeys, _, err = witKeyPublisher.PublishWITKey(ctx, &common.PublicKey{
+		Kid:       "kid-3",
+		PkixBytes: pkixBytes,
+	})
+	require.Nil(t, upstreamWitKeys)
+	spiretest.RequireGRPCStatusHasPrefix(t, err, codes.Internal, "upstreamauthority(spire): failed to push WIT authority: rpc error: code = Unknown desc = some erro")
+}
+
 func TestGetTrustBundle(t *testing.T) {
 	ca := testca.New(t, trustDomain)
 	serverCert, serverKey := ca.CreateX509Certificate(
@@ -481,6 +559,11 @@ func TestGetTrustBundle(t *testing.T) {
 }
 
 func newWithDefault(t *testing.T, mockClock *clock.Mock, serverAddr string, workloadAPIAddr net.Addr) *upstreamauthority.V1 {
+	ua, _ := newWITKeyPublisherWithDefault(t, mockClock, serverAddr, workloadAPIAddr)
+	return ua
+}
+
+func newWITKeyPublisherWithDefault(t *testing.T, mockClock *clock.Mock, serverAddr string, workloadAPIAddr net.Addr) (*upstreamauthority.V1, *upstreamauthority.WITKeyPublisherV1) {
 	host, port, _ := net.SplitHostPort(serverAddr)
 	config := Configuration{
 		ServerAddr: host,
@@ -492,14 +575,16 @@ func newWithDefault(t *testing.T, mockClock *clock.Mock, serverAddr string, work
 	p.clk = mockClock
 
 	ua := new(upstreamauthority.V1)
+	witKeyPublisher := new(upstreamauthority.WITKeyPublisherV1)
 	plugintest.Load(t, builtin(p), ua,
 		plugintest.CoreConfig(catalog.CoreConfig{
 			TrustDomain: trustDomain,
 		}),
 		plugintest.ConfigureJSON(config),
+		plugintest.Services(witKeyPublisher),
 	)
 
-	return ua
+	return ua, witKeyPublisher
 }
 
 func certChainURIs(chain []*x509.Certificate) []string {
@@ -44,6 +44,20 @@ type UpstreamAuthority interface {
 	SubscribeToLocalBundle(ctx context.Context) (x509CAs []*x509certificate.X509Authority, jwtAuthorities []*common.PublicKey, stream LocalBundleUpdateStream, err error)
 }
 
+// WITKeyPublisher is implemented by the UpstreamAuthority plugins serving the
+// optional WITKeyPublisher service.
+type WITKeyPublisher interface {
+	catalog.PluginInfo
+
+	// PublishWITKey publishes the given WIT key with the upstream authority.
+	// The function returns the latest set of upstream WIT authorities and a
+	// stream for streaming upstream WIT authority updates. The returned stream
+	// MUST be closed when the caller is no longer interested in updates. If
+	// the upstream authority does not support streaming updates, the stream
+	// will return io.EOF when called.
+	PublishWITKey(ctx context.Context, witKey *common.PublicKey) (witAuthorities []*common.PublicKey, stream UpstreamWITAuthorityStream, err error)
+}
+
 type UpstreamX509AuthorityStream interface {
 	// RecvUpstreamX509Authorities returns the latest set of upstream X.509
 	// authorities. The call blocks until the update is received, the Close()
@@ -70,6 +84,19 @@ type UpstreamJWTAuthorityStream interface {
 	Close()
 }
 
+type UpstreamWITAuthorityStream interface {
+	// RecvUpstreamWITAuthorities returns the latest set of upstream WIT
+	// authorities. The call blocks until the update is received, the Close()
+	// method is called, or the context originally passed into PublishWITKey is
+	// canceled. If the function returns an error, no more updates will be
+	// available over the stream.
+	RecvUpstreamWITAuthorities() ([]*common.PublicKey, error)
+
+	// Close() closes the stream. It MUST be called by callers of PublishWITKey
+	// when they are done with the stream.
+	Close()
+}
+
 type LocalBundleUpdateStream interface {
 	// RecvLocalBundleUpdate returns the latest local trust domain bundle
 	// The call blocks until the update is received, the Close()
@@ -17,8 +17,10 @@ import (
 	"github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/coretypes/jwtkey"
+	"github.com/spiffe/spire/pkg/common/coretypes/witkey"
 	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/spiretest"
@@ -396,6 +398,108 @@ func TestV1PublishJWTKey(t *testing.T) {
 	}
 }
 
+func TestV1PublishWITKey(t *testing.T) {
+	key := testkey.NewEC256(t)
+	pkixBytes, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	expectedUpstreamWITKeys := []*common.PublicKey{
+		{
+			Kid:       "UPSTREAM KEY",
+			PkixBytes: pkixBytes,
+		},
+	}
+
+	withoutID := &witkeypublisher.PublishWITKeyAndSubscribeResponse{
+		UpstreamWitAuthorities: []*witkeypublisher.WITKey{
+			{PublicKey: pkixBytes},
+		},
+	}
+	withIDAndPKIXData := &witkeypublisher.PublishWITKeyAndSubscribeResponse{
+		UpstreamWitAuthorities: witkey.RequireToPublisherFromCommonProtos(expectedUpstreamWITKeys),
+	}
+
+	builder := BuildV1()
+
+	for _, tt := range []struct {
+		test                string
+		builder             *V1Builder
+		expectCode          codes.Code
+		expectMessage       string
+		expectStreamUpdates bool
+		expectStreamCode    codes.Code
+		expectStreamMessage string
+	}{
+		{
+			test:          "plugin returns before sending first response",
+			builder:       builder.WithPreSendError(nil),
+			expectCode:    codes.Internal,
+			expectMessage: "upstreamauthority(test): plugin closed stream unexpectedly",
+		},
+		{
+			test:          "plugin response missing WIT key ID",
+			builder:       builder.WithPublishWITKeyResponse(withoutID),
+			expectCode:    codes.Internal,
+			expectMessage: "upstreamauthority(test): invalid plugin response: missing key ID for WIT key",
+		},
+		{
+			test:          "success but plugin does not support streaming updates",
+			builder:       builder.WithPublishWITKeyResponse(withIDAndPKIXData),
+			expectCode:    codes.OK,
+			expectMessage: "",
+		},
+		{
+			test: "success and plugin supports streaming updates",
+			builder: builder.
+				WithPublishWITKeyResponse(withIDAndPKIXData).
+				WithPublishWITKeyResponse(withIDAndPKIXData),
+			expectCode:          codes.OK,
+			expectMessage:       "",
+			expectStreamUpdates: true,
+			expectStreamCode:    codes.OK,
+			expectStreamMessage: "",
+		},
+		{
+			test: "plugin fails to stream updates",
+			builder: builder.
+				WithPublishWITKeyResponse(withIDAndPKIXData).
+				WithPostSendError(errors.New("ohno")),
+			expectCode:          codes.OK,
+			expectMessage:       "",
+			expectStreamUpdates: true,
+			expectStreamCode:    codes.Unknown,
+			expectStreamMessage: "upstreamauthority(test): ohno",
+		},
+	} {
+		t.Run(tt.test, func(t *testing.T) {
+			witKeyPublisher := tt.builder.LoadWITKeyPublisher(t)
+			upstreamWITKeys, upstreamWITKeysStream, err := witKeyPublisher.PublishWITKey(context.Background(), jwtKey)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMessage)
+			if tt.expectCode != codes.OK {
+				return
+			}
+			require.NotNil(t, upstreamWITKeysStream, "stream should have been returned")
+			defer upstreamWITKeysStream.Close()
+			spiretest.AssertProtoListEqual(t, expectedUpstreamWITKeys, upstreamWITKeys)
+
+			switch {
+			case !tt.expectStreamUpdates:
+				upstreamWITKeys, err := upstreamWITKeysStream.RecvUpstreamWITAuthorities()
+				assert.Equal(t, io.EOF, err, "stream should have returned EOF")
+				assert.Nil(t, upstreamWITKeys, "no WIT keys should be received")
+			case tt.expectStreamCode == codes.OK:
+				upstreamWITKeys, err := upstreamWITKeysStream.RecvUpstreamWITAuthorities()
+				assert.NoError(t, err, "stream should have returned update")
+				spiretest.AssertProtoListEqual(t, expectedUpstreamWITKeys, upstreamWITKeys)
+			default:
+				upstreamWITKeys, err = upstreamWITKeysStream.RecvUpstreamWITAuthorities()
+				spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectStreamCode, tt.expectStreamMessage)
+				assert.Nil(t, upstreamWITKeys)
+			}
+		})
+	}
+}
+
 func TestV1SubscribeToLocalBundle(t *testing.T) {
 	upstreamCA := testca.New(t, spiffeid.RequireTrustDomainFromString("example.org"))
 
@@ -570,6 +674,12 @@ func (b *V1Builder) WithPublishJWTKeyResponse(response *upstreamauthorityv1.Publ
 	return b
 }
 
+func (b *V1Builder) WithPublishWITKeyResponse(response *witkeypublisher.PublishWITKeyAndSubscribeResponse) *V1Builder {
+	b = b.clone()
+	b.p.publishWITKeyResponses = append(b.p.publishWITKeyResponses, response)
+	return b
+}
+
 func (b *V1Builder) WithSubscribeToLocalBundleResponse(response *upstreamauthorityv1.SubscribeToLocalBundleResponse) *V1Builder {
 	b = b.clone()
 	b.p.subscribeToLocalBundleResponses = append(b.p.subscribeToLocalBundleResponses, response)
@@ -584,25 +694,38 @@ func (b *V1Builder) clone() *V1Builder {
 }
 
 func (b *V1Builder) Load(t *testing.T) upstreamauthority.UpstreamAuthority {
-	server := upstreamauthorityv1.UpstreamAuthorityPluginServer(b.clone().p)
+	ua, _ := b.load(t)
+	return ua
+}
 
-	var opts []plugintest.Option
+func (b *V1Builder) LoadWITKeyPublisher(t *testing.T) *upstreamauthority.WITKeyPublisherV1 {
+	_, witKeyPublisher := b.load(t)
+	return witKeyPublisher
+}
+
+func (b *V1Builder) load(t *testing.T) (*upstreamauthority.V1, *upstreamauthority.WITKeyPublisherV1) {
+	p := b.clone().p
+
+	witKeyPublisher := new(upstreamauthority.WITKeyPublisherV1)
+	opts := []plugintest.Option{plugintest.Services(witKeyPublisher)}
 	if b.log != nil {
 		opts = append(opts, plugintest.Log(b.log))
 	}
 
 	ua := new(upstreamauthority.V1)
-	plugintest.Load(t, catalog.MakeBuiltIn("test", server), ua, opts...)
-	return ua
+	plugintest.Load(t, catalog.MakeBuiltIn("test", upstreamauthorityv1.UpstreamAuthorityPluginServer(p), upstreamauthority.WITKeyPublisherServiceServer(p)), ua, opts...)
+	return ua, witKeyPublisher
 }
 
 type v1Plugin struct {
 	upstreamauthorityv1.UnimplementedUpstreamAuthorityServer
+	witkeypublisher.UnimplementedWITKeyPublisherServer
 
 	preSendErr                      *error
 	postSendErr                     error
 	mintX509CAResponses             []*upstreamauthorityv1.MintX509CAResponse
 	publishJWTKeyResponses          []*upstreamauthorityv1.PublishJWTKeyResponse
+	publishWITKeyResponses          []*witkeypublisher.PublishWITKeyAndSubscribeResponse
 	subscribeToLocalBundleResponses []*upstreamauthorityv1.SubscribeToLocalBundleResponse
 }
 
@@ -645,6 +768,24 @@ func (v1 *v1Plugin) PublishJWTKeyAndSubscribe(req *upstreamauthorityv1.PublishJW
 	return v1.postSendErr
 }
 
+func (v1 *v1Plugin) PublishWITKeyAndSubscribe(req *witkeypublisher.PublishWITKeyAndSubscribeRequest, stream witkeypublisher.WITKeyPublisher_PublishWITKeyAndSubscribeServer) error {
+	if diff := cmp.Diff(witkey.RequireToPublisherFromCommonProto(jwtKey), req.WitKey, protocmp.Transform()); diff != "" {
+		return fmt.Errorf("unexpected public key: %s", diff)
+	}
+
+	if v1.preSendErr != nil {
+		return *v1.preSendErr
+	}
+
+	for _, response := range v1.publishWITKeyResponses {
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+
+	return v1.postSendErr
+}
+
 func (v1 *v1Plugin) SubscribeToLocalBundle(req *upstreamauthorityv1.SubscribeToLocalBundleRequest, stream upstreamauthorityv1.UpstreamAuthority_SubscribeToLocalBundleServer) error {
 	if v1.preSendErr != nil {
 		return *v1.preSendErr
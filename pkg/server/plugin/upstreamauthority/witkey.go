@@ -0,0 +1,139 @@
+package upstreamauthority
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
+	"github.com/spiffe/spire/pkg/common/coretypes/witkey"
+	"github.com/spiffe/spire/pkg/common/plugin"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// The UpstreamAuthority service in the plugin SDK does not support WIT keys.
+// UpstreamAuthority plugins that can publish WIT keys serve the SPIRE-internal
+// WITKeyPublisher service next to it. The service is optional; the server
+// falls back to the local bundle when the plugin does not serve it.
+const witKeyPublisherServiceName = "spire.private.server.witkeypublisher.WITKeyPublisher"
+
+// WITKeyPublisherServiceServer returns a service server for the
+// WITKeyPublisher service, to be served by the UpstreamAuthority plugin
+// alongside the plugin server.
+func WITKeyPublisherServiceServer(server witkeypublisher.WITKeyPublisherServer) pluginsdk.ServiceServer {
+	return witKeyPublisherServiceServer{WITKeyPublisherServer: server}
+}
+
+type witKeyPublisherServiceServer struct {
+	witkeypublisher.WITKeyPublisherServer
+}
+
+func (s witKeyPublisherServiceServer) GRPCServiceName() string {
+	return witKeyPublisherServiceName
+}
+
+func (s witKeyPublisherServiceServer) RegisterServer(server *grpc.Server) any {
+	witkeypublisher.RegisterWITKeyPublisherServer(server, s.WITKeyPublisherServer)
+	return s.WITKeyPublisherServer
+}
+
+// WITKeyPublisherV1 is the facade for the WITKeyPublisher service.
+type WITKeyPublisherV1 struct {
+	plugin.Facade
+	witkeypublisher.WITKeyPublisherClient
+}
+
+func (v1 *WITKeyPublisherV1) IsInitialized() bool {
+	return v1.WITKeyPublisherClient != nil
+}
+
+func (v1 *WITKeyPublisherV1) GRPCServiceName() string {
+	return witKeyPublisherServiceName
+}
+
+func (v1 *WITKeyPublisherV1) InitClient(conn grpc.ClientConnInterface) any {
+	v1.WITKeyPublisherClient = witkeypublisher.NewWITKeyPublisherClient(conn)
+	return v1.WITKeyPublisherClient
+}
+
+// PublishWITKey provides the V1 implementation of the WITKeyPublisher
+// interface method of the same name.
+func (v1 *WITKeyPublisherV1) PublishWITKey(ctx context.Context, witKey *common.PublicKey) (_ []*common.PublicKey, _ UpstreamWITAuthorityStream, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		// Only cancel the context if the function fails. Otherwise, the
+		// returned stream will be in charge of cancellation.
+		if err != nil {
+			defer cancel()
+		}
+	}()
+
+	pb, err := witkey.ToPublisherFromCommonProto(witKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stream, err := v1.WITKeyPublisherClient.PublishWITKeyAndSubscribe(ctx, &witkeypublisher.PublishWITKeyAndSubscribeRequest{
+		WitKey: pb,
+	})
+	if err != nil {
+		return nil, nil, v1.WrapErr(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, v1.Error(codes.Internal, "plugin closed stream unexpectedly")
+		}
+		return nil, nil, v1.WrapErr(err)
+	}
+
+	witKeys, err := v1.toCommonProtos(resp.UpstreamWitAuthorities)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return witKeys, &v1UpstreamWITAuthorityStream{v1: v1, stream: stream, cancel: cancel}, nil
+}
+
+func (v1 *WITKeyPublisherV1) toCommonProtos(pbs []*witkeypublisher.WITKey) ([]*common.PublicKey, error) {
+	witKeys, err := witkey.ToCommonFromPublisherProtos(pbs)
+	if err != nil {
+		return nil, v1.Errorf(codes.Internal, "invalid plugin response: %v", err)
+	}
+	return witKeys, nil
+}
+
+type v1UpstreamWITAuthorityStream struct {
+	v1     *WITKeyPublisherV1
+	stream witkeypublisher.WITKeyPublisher_PublishWITKeyAndSubscribeClient
+	cancel context.CancelFunc
+}
+
+func (s *v1UpstreamWITAuthorityStream) RecvUpstreamWITAuthorities() ([]*common.PublicKey, error) {
+	for {
+		resp, err := s.stream.Recv()
+		switch {
+		case errors.Is(err, io.EOF):
+			// This is expected if the plugin does not support streaming
+			// authority updates.
+			return nil, io.EOF
+		case err != nil:
+			return nil, s.v1.WrapErr(err)
+		}
+
+		witKeys, err := s.v1.toCommonProtos(resp.UpstreamWitAuthorities)
+		if err != nil {
+			s.v1.Log.WithError(err).Warn("Failed to parse a WIT key update from the upstream authority plugin. Please report this bug.")
+			continue
+		}
+		return witKeys, nil
+	}
+}
+
+func (s *v1UpstreamWITAuthorityStream) Close() {
+	s.cancel()
+}
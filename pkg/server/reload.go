@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	common "github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/config"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
+	"github.com/spiffe/spire/pkg/server/credtemplate"
+	"github.com/spiffe/spire/pkg/server/endpoints"
+)
+
+// ReloadableConfig holds the settings that can be changed while the server is
+// running, as loaded from the configuration file when the server is signaled
+// to reload it.
+type ReloadableConfig struct {
+	// RateLimit holds rate limiting configurations.
+	RateLimit endpoints.RateLimitConfig
+
+	// AgentTTL is time-to-live for agent SVIDs
+	AgentTTL time.Duration
+
+	// X509SVIDTTL is default time-to-live for X509-SVIDs
+	X509SVIDTTL time.Duration
+
+	// JWTSVIDTTL is default time-to-live for JWT-SVIDs
+	JWTSVIDTTL time.Duration
+
+	// FederatesWith holds the federation configuration for trust domains this
+	// server federates with.
+	FederatesWith map[spiffeid.TrustDomain]bundle_client.TrustDomainConfig
+
+	// Telemetry provides the configuration for metrics exporting
+	Telemetry telemetry.FileConfig
+
+	// PluginConfigs holds the configurations for server plugins
+	PluginConfigs common.PluginConfigs
+
+	// FeatureFlags holds the raw feature flag configuration
+	FeatureFlags fflag.RawConfig
+
+	// Changed holds the settings, named after their configuration keys, that
+	// changed since the configuration was last loaded and can be applied
+	// while the server is running.
+	Changed []string
+
+	// RequiresRestart holds the settings, named after their configuration
+	// keys, that changed since the server started but can only be applied
+	// by restarting it.
+	RequiresRestart []string
+}
+
+// ConfigLoader loads the reloadable configuration, e.g. by parsing the
+// configuration file again.
+type ConfigLoader func() (*ReloadableConfig, error)
+
+type pluginReconfigurer interface {
+	ReconfigureWith(ctx context.Context, pluginConfigs common.PluginConfigs) common.PluginChanges
+}
+
+type metricsReloader interface {
+	Reload(ctx context.Context, fileConfig telemetry.FileConfig) error
+}
+
+// reloadables holds the components that are reconfigured when the
+// configuration is reloaded.
+type reloadables struct {
+	endpoints     *endpoints.Endpoints
+	credBuilder   *credtemplate.Builder
+	federatesWith *bundle_client.TrustDomainConfigSet
+	bundleManager *bundle_client.Manager
+	metrics       metricsReloader
+	catalog       pluginReconfigurer
+}
+
+// reloadConfig loads the configuration again and applies the settings that
+// can be changed while the server is running. Settings that require a
+// restart are only reported.
+func (s *Server) reloadConfig(ctx context.Context, r reloadables) {
+	log := s.config.Log.WithField(telemetry.SubsystemName, telemetry.ConfigReloader)
+
+	rc, err := s.config.ConfigLoader()
+	if err != nil {
+		log.WithError(err).Error("Failed to reload configuration")
+		return
+	}
+
+	changedFlags, err := fflag.Reload(rc.FeatureFlags)
+	if err != nil {
+		log.WithError(err).Error("Failed to reload feature flags")
+	} else {
+		fflag.LogReloaded(s.config.Log.WithField(telemetry.SubsystemName, telemetry.FeatureFlags), changedFlags)
+	}
+
+	if changed(rc, "server.ratelimit") {
+		r.endpoints.SetRateLimit(rc.RateLimit)
+	}
+
+	if changed(rc, "server.agent_ttl", "server.default_x509_svid_ttl", "server.default_jwt_svid_ttl") {
+		r.credBuilder.SetSVIDTTLs(rc.X509SVIDTTL, rc.JWTSVIDTTL, rc.AgentTTL)
+	}
+
+	if changed(rc, "server.federation.federates_with") {
+		r.federatesWith.SetAll(rc.FederatesWith)
+		r.bundleManager.TriggerConfigReload()
+	}
+
+	if changed(rc, "telemetry") {
+		if err := r.metrics.Reload(ctx, rc.Telemetry); err != nil {
+			log.WithError(err).Error("Failed to reload telemetry")
+		}
+	}
+
+	logReloaded(log, rc.Changed, rc.RequiresRestart)
+	r.catalog.ReconfigureWith(ctx, rc.PluginConfigs).Log(log)
+}
+
+// changed returns true if any of the given settings, or the settings nested
+// under them, changed.
+func changed(rc *ReloadableConfig, settings ...string) bool {
+	return slices.ContainsFunc(rc.Changed, func(path string) bool {
+		return slices.ContainsFunc(settings, func(setting string) bool {
+			return config.HasPathPrefix(path, setting)
+		})
+	})
+}
+
+func logReloaded(log logrus.FieldLogger, changed, requiresRestart []string) {
+	if len(changed) == 0 {
+		log.Info("Configuration reloaded; no changes")
+	} else {
+		log.WithField(telemetry.Settings, changed).Info("Configuration reloaded")
+	}
+	if len(requiresRestart) > 0 {
+		log.WithField(telemetry.Settings, requiresRestart).Warn("Configuration changes require a restart to take effect")
+	}
+}
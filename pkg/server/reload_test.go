@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
+	"github.com/spiffe/spire/pkg/server/credtemplate"
+	"github.com/spiffe/spire/pkg/server/endpoints"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	federatedTD = spiffeid.RequireTrustDomainFromString("federated.test")
+)
+
+func TestReloadConfig(t *testing.T) {
+	require.NoError(t, fflag.Load(nil))
+	t.Cleanup(func() { assert.NoError(t, fflag.Unload()) })
+
+	for _, tt := range []struct {
+		name                 string
+		config               *ReloadableConfig
+		configErr            error
+		metricsErr           error
+		pluginChanges        catalog.PluginChanges
+		expectX509SVIDTTL    time.Duration
+		expectFederatesWith  []spiffeid.TrustDomain
+		expectTelemetry      *telemetry.FileConfig
+		expectPluginsChecked bool
+		expectLogs           []spiretest.LogEntry
+	}{
+		{
+			name:              "failed to load configuration",
+			configErr:         errors.New("oh no"),
+			expectX509SVIDTTL: time.Hour,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to reload configuration",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						logrus.ErrorKey:         "oh no",
+					},
+				},
+			},
+		},
+		{
+			name:                 "no changes",
+			config:               &ReloadableConfig{},
+			expectX509SVIDTTL:    time.Hour,
+			expectPluginsChecked: true,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Feature flags reloaded; no changes",
+					Data:    logrus.Fields{telemetry.SubsystemName: telemetry.FeatureFlags},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Configuration reloaded; no changes",
+					Data:    logrus.Fields{telemetry.SubsystemName: telemetry.ConfigReloader},
+				},
+			},
+		},
+		{
+			name: "reloadable changes",
+			config: &ReloadableConfig{
+				X509SVIDTTL: 2 * time.Hour,
+				FederatesWith: map[spiffeid.TrustDomain]bundle_client.TrustDomainConfig{
+					federatedTD: {EndpointURL: "https://federated.test/bundle"},
+				},
+				Telemetry: telemetry.FileConfig{MetricPrefix: "spire"},
+				Changed: []string{
+					"server.default_x509_svid_ttl",
+					"server.federation.federates_with.federated.test",
+					"telemetry.MetricPrefix",
+				},
+			},
+			pluginChanges: catalog.PluginChanges{
+				Reconfigured: []string{`KeyManager "disk"`},
+			},
+			expectX509SVIDTTL:    2 * time.Hour,
+			expectFederatesWith:  []spiffeid.TrustDomain{federatedTD},
+			expectTelemetry:      &telemetry.FileConfig{MetricPrefix: "spire"},
+			expectPluginsChecked: true,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Feature flags reloaded; no changes",
+					Data:    logrus.Fields{telemetry.SubsystemName: telemetry.FeatureFlags},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Configuration reloaded",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						telemetry.Settings:      "[server.default_x509_svid_ttl server.federation.federates_with.federated.test telemetry.MetricPrefix]",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Plugins reconfigured",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						telemetry.Plugins:       `[KeyManager "disk"]`,
+					},
+				},
+			},
+		},
+		{
+			name: "changes that require a restart",
+			config: &ReloadableConfig{
+				Telemetry:       telemetry.FileConfig{MetricPrefix: "spire"},
+				Changed:         []string{"telemetry.MetricPrefix"},
+				RequiresRestart: []string{"server.bind_port"},
+			},
+			metricsErr: errors.New("oh no"),
+			pluginChanges: catalog.PluginChanges{
+				Failed:          []string{`Notifier "k8sbundle"`},
+				RequiresRestart: []string{`NodeAttestor "join_token"`},
+			},
+			expectX509SVIDTTL:    time.Hour,
+			expectTelemetry:      &telemetry.FileConfig{MetricPrefix: "spire"},
+			expectPluginsChecked: true,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Feature flags reloaded; no changes",
+					Data:    logrus.Fields{telemetry.SubsystemName: telemetry.FeatureFlags},
+				},
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to reload telemetry",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						logrus.ErrorKey:         "oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Configuration reloaded",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						telemetry.Settings:      "[telemetry.MetricPrefix]",
+					},
+				},
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Configuration changes require a restart to take effect",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						telemetry.Settings:      "[server.bind_port]",
+					},
+				},
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to reconfigure plugins; they keep their previous configuration",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						telemetry.Plugins:       `[Notifier "k8sbundle"]`,
+					},
+				},
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Plugin changes require a restart to take effect",
+					Data: logrus.Fields{
+						telemetry.SubsystemName: telemetry.ConfigReloader,
+						telemetry.Plugins:       `[NodeAttestor "join_token"]`,
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+
+			credBuilder, err := credtemplate.NewBuilder(credtemplate.Config{
+				TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+				X509SVIDTTL: time.Hour,
+			})
+			require.NoError(t, err)
+
+			federatesWith := bundle_client.NewTrustDomainConfigSet(nil)
+			metrics := &fakeMetricsReloader{err: tt.metricsErr}
+			cat := &fakePluginReconfigurer{changes: tt.pluginChanges}
+
+			s := New(Config{
+				Log: log,
+				ConfigLoader: func() (*ReloadableConfig, error) {
+					return tt.config, tt.configErr
+				},
+			})
+			s.reloadConfig(context.Background(), reloadables{
+				endpoints:     &endpoints.Endpoints{},
+				credBuilder:   credBuilder,
+				federatesWith: federatesWith,
+				bundleManager: bundle_client.NewManager(bundle_client.ManagerConfig{}),
+				metrics:       metrics,
+				catalog:       cat,
+			})
+
+			spiretest.AssertLogs(t, hook.AllEntries(), tt.expectLogs)
+
+			assert.Equal(t, tt.expectX509SVIDTTL, credBuilder.Config().X509SVIDTTL)
+			assert.Equal(t, tt.expectTelemetry, metrics.reloaded)
+			assert.Equal(t, tt.expectPluginsChecked, cat.called)
+
+			configs, err := federatesWith.GetTrustDomainConfigs(context.Background())
+			require.NoError(t, err)
+			var federatesWithTDs []spiffeid.TrustDomain
+			for td := range configs {
+				federatesWithTDs = append(federatesWithTDs, td)
+			}
+			assert.Equal(t, tt.expectFederatesWith, federatesWithTDs)
+		})
+	}
+}
+
+func TestChanged(t *testing.T) {
+	rc := &ReloadableConfig{
+		Changed: []string{"server.ratelimit.signing", "telemetry"},
+	}
+	assert.True(t, changed(rc, "server.ratelimit"))
+	assert.True(t, changed(rc, "server.agent_ttl", "telemetry"))
+	assert.False(t, changed(rc, "server.ratelimit.attestation"))
+	assert.False(t, changed(rc, "server.rate"))
+}
+
+type fakeMetricsReloader struct {
+	err      error
+	reloaded *telemetry.FileConfig
+}
+
+func (f *fakeMetricsReloader) Reload(_ context.Context, fileConfig telemetry.FileConfig) error {
+	f.reloaded = &fileConfig
+	return f.err
+}
+
+type fakePluginReconfigurer struct {
+	changes catalog.PluginChanges
+	called  bool
+}
+
+func (f *fakePluginReconfigurer) ReconfigureWith(context.Context, catalog.PluginConfigs) catalog.PluginChanges {
+	f.called = true
+	return f.changes
+}
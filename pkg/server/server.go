@@ -8,6 +8,7 @@ import (
 	"net/http"
 	_ "net/http/pprof" //nolint: gosec // import registers routes on DefaultServeMux
 	"net/url"
+	"os"
 	"runtime"
 	"sync"
 	"time"
@@ -18,14 +19,17 @@ import (
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	server_util "github.com/spiffe/spire/cmd/spire-server/util"
+	"github.com/spiffe/spire/pkg/common/config"
 	"github.com/spiffe/spire/pkg/common/diskutil"
 	"github.com/spiffe/spire/pkg/common/errorutil"
+	"github.com/spiffe/spire/pkg/common/fflag"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/profiling"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/uptime"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/common/version"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
 	ds_pubmanager "github.com/spiffe/spire/pkg/server/bundle/datastore"
@@ -226,9 +230,18 @@ func (s *Server) run(ctx context.Context) (err error) {
 		return fmt.Errorf("unable to obtain authpolicy engine: %w", err)
 	}
 
-	bundleManager := s.newBundleManager(cat, metrics)
+	federatesWith := bundle_client.NewTrustDomainConfigSet(s.config.Federation.FederatesWith)
+	bundleManager := s.newBundleManager(cat, metrics, federatesWith)
 
-	endpointsServer, err := s.newEndpointsServer(ctx, cat, svidRotator, serverCA, metrics, caManager, authPolicyEngine, bundleManager)
+	auditLogSink, err := s.newAuditLogSink()
+	if err != nil {
+		return err
+	}
+	if auditLogSink != nil {
+		defer auditLogSink.Close()
+	}
+
+	endpointsServer, err := s.newEndpointsServer(ctx, cat, svidRotator, serverCA, metrics, caManager, authPolicyEngine, bundleManager, auditLogSink)
 	if err != nil {
 		return err
 	}
@@ -270,6 +283,23 @@ func (s *Server) run(ctx context.Context) (err error) {
 		registrationManager.Run,
 		bundlePublishingManager.Run,
 		catalog.ReconfigureTask(s.config.Log.WithField(telemetry.SubsystemName, "reconfigurer"), cat),
+		s.watchFeatureFlags(caManager),
+	}
+
+	if s.config.ConfigLoader != nil {
+		reloadables := reloadables{
+			endpoints:     endpointsServer,
+			credBuilder:   credBuilder,
+			federatesWith: federatesWith,
+			bundleManager: bundleManager,
+			metrics:       metrics,
+			catalog:       cat,
+		}
+		tasks = append(tasks, func(ctx context.Context) error {
+			return config.ReloadOnSignal(ctx, s.config.Log.WithField(telemetry.SubsystemName, telemetry.ConfigReloader), func(ctx context.Context) {
+				s.reloadConfig(ctx, reloadables)
+			})
+		})
 	}
 
 	if s.config.LogReopener != nil {
@@ -374,6 +404,7 @@ func (s *Server) newCredBuilder(cat catalog.Catalog) (*credtemplate.Builder, err
 		JWTIssuer:           s.config.JWTIssuer,
 		WITIssuer:           s.config.WITIssuer,
 		CredentialComposers: cat.GetCredentialComposers(),
+		WITSVIDComposers:    cat.GetWITSVIDComposers(),
 		TLSPolicy:           s.config.TLSPolicy,
 	})
 }
@@ -420,6 +451,24 @@ func (s *Server) newCAManager(ctx context.Context, cat catalog.Catalog, metrics
 	return caManager, nil
 }
 
+// watchFeatureFlags returns a task that applies the feature flags that can be
+// toggled while the server is running whenever they are reloaded.
+func (s *Server) watchFeatureFlags(caManager *manager.Manager) func(context.Context) error {
+	return func(ctx context.Context) error {
+		changed, unsubscribe := fflag.Subscribe()
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-changed:
+				caManager.SetWITSVIDsDisabled(ctx, !fflag.IsSet(fflag.FlagWITSVID))
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
 func (s *Server) newCASync(ctx context.Context, healthChecker health.Checker, caManager *manager.Manager) (*rotator.Rotator, error) {
 	caSync := rotator.NewRotator(rotator.Config{
 		Log:           s.config.Log.WithField(telemetry.SubsystemName, telemetry.CAManager),
@@ -468,7 +517,12 @@ func (s *Server) newSVIDRotator(ctx context.Context, serverCA ca.ServerCA, metri
 	return svidRotator, nil
 }
 
-func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, authorityManager manager.AuthorityManager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager) (endpoints.Server, error) {
+func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, authorityManager manager.AuthorityManager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager, auditLogSink *audit.ChainSink) (*endpoints.Endpoints, error) {
+	serverID, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
 	config := endpoints.Config{
 		TCPAddr:                      s.config.BindAddress,
 		LocalAddr:                    s.config.BindLocalAddress,
@@ -495,6 +549,11 @@ func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog
 		AdminIDs:                     s.config.AdminIDs,
 		MaxAttestedNodeInfoStaleness: s.config.MaxAttestedNodeInfoStaleness,
 		AgentSpiffeIdAsSelector:      s.config.Experimental.AgentSpiffeIdAsSelector,
+		FeatureFlagsLoader:           s.config.FeatureFlagsLoader,
+		ServerID:                     serverID,
+	}
+	if auditLogSink != nil {
+		config.AuditLogSink = auditLogSink
 	}
 	if s.config.Federation.BundleEndpoint != nil {
 		config.BundleEndpoint.Address = s.config.Federation.BundleEndpoint.Address
@@ -505,14 +564,27 @@ func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog
 	return endpoints.New(ctx, config)
 }
 
-func (s *Server) newBundleManager(cat catalog.Catalog, metrics telemetry.Metrics) *bundle_client.Manager {
+// newAuditLogSink returns the configured audit log sink, or nil if no sink
+// is configured.
+func (s *Server) newAuditLogSink() (*audit.ChainSink, error) {
+	if s.config.AuditLogSink == nil {
+		return nil, nil
+	}
+	sink, err := audit.NewChainSink(*s.config.AuditLogSink, s.config.Log.WithField(telemetry.SubsystemName, telemetry.AuditLogSink))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log sink: %w", err)
+	}
+	return sink, nil
+}
+
+func (s *Server) newBundleManager(cat catalog.Catalog, metrics telemetry.Metrics, federatesWith *bundle_client.TrustDomainConfigSet) *bundle_client.Manager {
 	log := s.config.Log.WithField(telemetry.SubsystemName, "bundle_client")
 	return bundle_client.NewManager(bundle_client.ManagerConfig{
 		Log:       log,
 		Metrics:   metrics,
 		DataStore: cat.GetDataStore(),
 		Source: bundle_client.MergeTrustDomainConfigSources(
-			bundle_client.NewTrustDomainConfigSet(s.config.Federation.FederatesWith),
+			federatesWith,
 			bundle_client.DataStoreTrustDomainConfigSource(log, cat.GetDataStore()),
 		),
 	})
@@ -0,0 +1,371 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11-devel
+// 	protoc        v7.35.0
+// source: private/server/entrytemplate/entrytemplate.proto
+
+package entrytemplate
+
+import (
+	common "github.com/spiffe/spire/proto/spire/common"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateEntryTemplateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The entry template to create. If the template ID is not set, one is
+	// generated.
+	Template      *common.EntryTemplate `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEntryTemplateRequest) Reset() {
+	*x = CreateEntryTemplateRequest{}
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEntryTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEntryTemplateRequest) ProtoMessage() {}
+
+func (x *CreateEntryTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEntryTemplateRequest.ProtoReflect.Descriptor instead.
+func (*CreateEntryTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateEntryTemplateRequest) GetTemplate() *common.EntryTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+type CreateEntryTemplateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The created entry template.
+	Template      *common.EntryTemplate `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEntryTemplateResponse) Reset() {
+	*x = CreateEntryTemplateResponse{}
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEntryTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEntryTemplateResponse) ProtoMessage() {}
+
+func (x *CreateEntryTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEntryTemplateResponse.ProtoReflect.Descriptor instead.
+func (*CreateEntryTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateEntryTemplateResponse) GetTemplate() *common.EntryTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+type ListEntryTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntryTemplatesRequest) Reset() {
+	*x = ListEntryTemplatesRequest{}
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntryTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntryTemplatesRequest) ProtoMessage() {}
+
+func (x *ListEntryTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntryTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListEntryTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP(), []int{2}
+}
+
+type ListEntryTemplatesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The entry templates.
+	Templates     []*common.EntryTemplate `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntryTemplatesResponse) Reset() {
+	*x = ListEntryTemplatesResponse{}
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntryTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntryTemplatesResponse) ProtoMessage() {}
+
+func (x *ListEntryTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntryTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListEntryTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListEntryTemplatesResponse) GetTemplates() []*common.EntryTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type DeleteEntryTemplateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the entry template to delete.
+	TemplateId    string `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEntryTemplateRequest) Reset() {
+	*x = DeleteEntryTemplateRequest{}
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEntryTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEntryTemplateRequest) ProtoMessage() {}
+
+func (x *DeleteEntryTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEntryTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteEntryTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteEntryTemplateRequest) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+type DeleteEntryTemplateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The deleted entry template.
+	Template      *common.EntryTemplate `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEntryTemplateResponse) Reset() {
+	*x = DeleteEntryTemplateResponse{}
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEntryTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEntryTemplateResponse) ProtoMessage() {}
+
+func (x *DeleteEntryTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_entrytemplate_entrytemplate_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEntryTemplateResponse.ProtoReflect.Descriptor instead.
+func (*DeleteEntryTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteEntryTemplateResponse) GetTemplate() *common.EntryTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+var File_private_server_entrytemplate_entrytemplate_proto protoreflect.FileDescriptor
+
+const file_private_server_entrytemplate_entrytemplate_proto_rawDesc = "" +
+	"\n" +
+	"0private/server/entrytemplate/entrytemplate.proto\x12\"spire.private.server.entrytemplate\x1a\x19spire/common/common.proto\"U\n" +
+	"\x1aCreateEntryTemplateRequest\x127\n" +
+	"\btemplate\x18\x01 \x01(\v2\x1b.spire.common.EntryTemplateR\btemplate\"V\n" +
+	"\x1bCreateEntryTemplateResponse\x127\n" +
+	"\btemplate\x18\x01 \x01(\v2\x1b.spire.common.EntryTemplateR\btemplate\"\x1b\n" +
+	"\x19ListEntryTemplatesRequest\"W\n" +
+	"\x1aListEntryTemplatesResponse\x129\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x1b.spire.common.EntryTemplateR\ttemplates\"=\n" +
+	"\x1aDeleteEntryTemplateRequest\x12\x1f\n" +
+	"\vtemplate_id\x18\x01 \x01(\tR\n" +
+	"templateId\"V\n" +
+	"\x1bDeleteEntryTemplateResponse\x127\n" +
+	"\btemplate\x18\x01 \x01(\v2\x1b.spire.common.EntryTemplateR\btemplate2\xd8\x03\n" +
+	"\x0eEntryTemplates\x12\x96\x01\n" +
+	"\x13CreateEntryTemplate\x12>.spire.private.server.entrytemplate.CreateEntryTemplateRequest\x1a?.spire.private.server.entrytemplate.CreateEntryTemplateResponse\x12\x93\x01\n" +
+	"\x12ListEntryTemplates\x12=.spire.private.server.entrytemplate.ListEntryTemplatesRequest\x1a>.spire.private.server.entrytemplate.ListEntryTemplatesResponse\x12\x96\x01\n" +
+	"\x13DeleteEntryTemplate\x12>.spire.private.server.entrytemplate.DeleteEntryTemplateRequest\x1a?.spire.private.server.entrytemplate.DeleteEntryTemplateResponseB<Z:github.com/spiffe/spire/proto/private/server/entrytemplateb\x06proto3"
+
+var (
+	file_private_server_entrytemplate_entrytemplate_proto_rawDescOnce sync.Once
+	file_private_server_entrytemplate_entrytemplate_proto_rawDescData []byte
+)
+
+func file_private_server_entrytemplate_entrytemplate_proto_rawDescGZIP() []byte {
+	file_private_server_entrytemplate_entrytemplate_proto_rawDescOnce.Do(func() {
+		file_private_server_entrytemplate_entrytemplate_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_private_server_entrytemplate_entrytemplate_proto_rawDesc), len(file_private_server_entrytemplate_entrytemplate_proto_rawDesc)))
+	})
+	return file_private_server_entrytemplate_entrytemplate_proto_rawDescData
+}
+
+var file_private_server_entrytemplate_entrytemplate_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_private_server_entrytemplate_entrytemplate_proto_goTypes = []any{
+	(*CreateEntryTemplateRequest)(nil),  // 0: spire.private.server.entrytemplate.CreateEntryTemplateRequest
+	(*CreateEntryTemplateResponse)(nil), // 1: spire.private.server.entrytemplate.CreateEntryTemplateResponse
+	(*ListEntryTemplatesRequest)(nil),   // 2: spire.private.server.entrytemplate.ListEntryTemplatesRequest
+	(*ListEntryTemplatesResponse)(nil),  // 3: spire.private.server.entrytemplate.ListEntryTemplatesResponse
+	(*DeleteEntryTemplateRequest)(nil),  // 4: spire.private.server.entrytemplate.DeleteEntryTemplateRequest
+	(*DeleteEntryTemplateResponse)(nil), // 5: spire.private.server.entrytemplate.DeleteEntryTemplateResponse
+	(*common.EntryTemplate)(nil),        // 6: spire.common.EntryTemplate
+}
+var file_private_server_entrytemplate_entrytemplate_proto_depIdxs = []int32{
+	6, // 0: spire.private.server.entrytemplate.CreateEntryTemplateRequest.template:type_name -> spire.common.EntryTemplate
+	6, // 1: spire.private.server.entrytemplate.CreateEntryTemplateResponse.template:type_name -> spire.common.EntryTemplate
+	6, // 2: spire.private.server.entrytemplate.ListEntryTemplatesResponse.templates:type_name -> spire.common.EntryTemplate
+	6, // 3: spire.private.server.entrytemplate.DeleteEntryTemplateResponse.template:type_name -> spire.common.EntryTemplate
+	0, // 4: spire.private.server.entrytemplate.EntryTemplates.CreateEntryTemplate:input_type -> spire.private.server.entrytemplate.CreateEntryTemplateRequest
+	2, // 5: spire.private.server.entrytemplate.EntryTemplates.ListEntryTemplates:input_type -> spire.private.server.entrytemplate.ListEntryTemplatesRequest
+	4, // 6: spire.private.server.entrytemplate.EntryTemplates.DeleteEntryTemplate:input_type -> spire.private.server.entrytemplate.DeleteEntryTemplateRequest
+	1, // 7: spire.private.server.entrytemplate.EntryTemplates.CreateEntryTemplate:output_type -> spire.private.server.entrytemplate.CreateEntryTemplateResponse
+	3, // 8: spire.private.server.entrytemplate.EntryTemplates.ListEntryTemplates:output_type -> spire.private.server.entrytemplate.ListEntryTemplatesResponse
+	5, // 9: spire.private.server.entrytemplate.EntryTemplates.DeleteEntryTemplate:output_type -> spire.private.server.entrytemplate.DeleteEntryTemplateResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_private_server_entrytemplate_entrytemplate_proto_init() }
+func file_private_server_entrytemplate_entrytemplate_proto_init() {
+	if File_private_server_entrytemplate_entrytemplate_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_private_server_entrytemplate_entrytemplate_proto_rawDesc), len(file_private_server_entrytemplate_entrytemplate_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_private_server_entrytemplate_entrytemplate_proto_goTypes,
+		DependencyIndexes: file_private_server_entrytemplate_entrytemplate_proto_depIdxs,
+		MessageInfos:      file_private_server_entrytemplate_entrytemplate_proto_msgTypes,
+	}.Build()
+	File_private_server_entrytemplate_entrytemplate_proto = out.File
+	file_private_server_entrytemplate_entrytemplate_proto_goTypes = nil
+	file_private_server_entrytemplate_entrytemplate_proto_depIdxs = nil
+}
@@ -0,0 +1,191 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v7.35.0
+// source: private/server/entrytemplate/entrytemplate.proto
+
+package entrytemplate
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	EntryTemplates_CreateEntryTemplate_FullMethodName = "/spire.private.server.entrytemplate.EntryTemplates/CreateEntryTemplate"
+	EntryTemplates_ListEntryTemplates_FullMethodName  = "/spire.private.server.entrytemplate.EntryTemplates/ListEntryTemplates"
+	EntryTemplates_DeleteEntryTemplate_FullMethodName = "/spire.private.server.entrytemplate.EntryTemplates/DeleteEntryTemplate"
+)
+
+// EntryTemplatesClient is the client API for EntryTemplates service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EntryTemplatesClient interface {
+	// Creates an entry template.
+	CreateEntryTemplate(ctx context.Context, in *CreateEntryTemplateRequest, opts ...grpc.CallOption) (*CreateEntryTemplateResponse, error)
+	// Lists the entry templates.
+	ListEntryTemplates(ctx context.Context, in *ListEntryTemplatesRequest, opts ...grpc.CallOption) (*ListEntryTemplatesResponse, error)
+	// Deletes an entry template. The entries derived from it are removed by
+	// the agents.
+	DeleteEntryTemplate(ctx context.Context, in *DeleteEntryTemplateRequest, opts ...grpc.CallOption) (*DeleteEntryTemplateResponse, error)
+}
+
+type entryTemplatesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEntryTemplatesClient(cc grpc.ClientConnInterface) EntryTemplatesClient {
+	return &entryTemplatesClient{cc}
+}
+
+func (c *entryTemplatesClient) CreateEntryTemplate(ctx context.Context, in *CreateEntryTemplateRequest, opts ...grpc.CallOption) (*CreateEntryTemplateResponse, error) {
+	out := new(CreateEntryTemplateResponse)
+	err := c.cc.Invoke(ctx, EntryTemplates_CreateEntryTemplate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *entryTemplatesClient) ListEntryTemplates(ctx context.Context, in *ListEntryTemplatesRequest, opts ...grpc.CallOption) (*ListEntryTemplatesResponse, error) {
+	out := new(ListEntryTemplatesResponse)
+	err := c.cc.Invoke(ctx, EntryTemplates_ListEntryTemplates_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *entryTemplatesClient) DeleteEntryTemplate(ctx context.Context, in *DeleteEntryTemplateRequest, opts ...grpc.CallOption) (*DeleteEntryTemplateResponse, error) {
+	out := new(DeleteEntryTemplateResponse)
+	err := c.cc.Invoke(ctx, EntryTemplates_DeleteEntryTemplate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EntryTemplatesServer is the server API for EntryTemplates service.
+// All implementations must embed UnimplementedEntryTemplatesServer
+// for forward compatibility
+type EntryTemplatesServer interface {
+	// Creates an entry template.
+	CreateEntryTemplate(context.Context, *CreateEntryTemplateRequest) (*CreateEntryTemplateResponse, error)
+	// Lists the entry templates.
+	ListEntryTemplates(context.Context, *ListEntryTemplatesRequest) (*ListEntryTemplatesResponse, error)
+	// Deletes an entry template. The entries derived from it are removed by
+	// the agents.
+	DeleteEntryTemplate(context.Context, *DeleteEntryTemplateRequest) (*DeleteEntryTemplateResponse, error)
+	mustEmbedUnimplementedEntryTemplatesServer()
+}
+
+// UnimplementedEntryTemplatesServer must be embedded to have forward compatible implementations.
+type UnimplementedEntryTemplatesServer struct {
+}
+
+func (UnimplementedEntryTemplatesServer) CreateEntryTemplate(context.Context, *CreateEntryTemplateRequest) (*CreateEntryTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateEntryTemplate not implemented")
+}
+func (UnimplementedEntryTemplatesServer) ListEntryTemplates(context.Context, *ListEntryTemplatesRequest) (*ListEntryTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEntryTemplates not implemented")
+}
+func (UnimplementedEntryTemplatesServer) DeleteEntryTemplate(context.Context, *DeleteEntryTemplateRequest) (*DeleteEntryTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteEntryTemplate not implemented")
+}
+func (UnimplementedEntryTemplatesServer) mustEmbedUnimplementedEntryTemplatesServer() {}
+
+// UnsafeEntryTemplatesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EntryTemplatesServer will
+// result in compilation errors.
+type UnsafeEntryTemplatesServer interface {
+	mustEmbedUnimplementedEntryTemplatesServer()
+}
+
+func RegisterEntryTemplatesServer(s grpc.ServiceRegistrar, srv EntryTemplatesServer) {
+	s.RegisterService(&EntryTemplates_ServiceDesc, srv)
+}
+
+func _EntryTemplates_CreateEntryTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateEntryTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntryTemplatesServer).CreateEntryTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntryTemplates_CreateEntryTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntryTemplatesServer).CreateEntryTemplate(ctx, req.(*CreateEntryTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EntryTemplates_ListEntryTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEntryTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntryTemplatesServer).ListEntryTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntryTemplates_ListEntryTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntryTemplatesServer).ListEntryTemplates(ctx, req.(*ListEntryTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EntryTemplates_DeleteEntryTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteEntryTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntryTemplatesServer).DeleteEntryTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntryTemplates_DeleteEntryTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntryTemplatesServer).DeleteEntryTemplate(ctx, req.(*DeleteEntryTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EntryTemplates_ServiceDesc is the grpc.ServiceDesc for EntryTemplates service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EntryTemplates_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.server.entrytemplate.EntryTemplates",
+	HandlerType: (*EntryTemplatesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateEntryTemplate",
+			Handler:    _EntryTemplates_CreateEntryTemplate_Handler,
+		},
+		{
+			MethodName: "ListEntryTemplates",
+			Handler:    _EntryTemplates_ListEntryTemplates_Handler,
+		},
+		{
+			MethodName: "DeleteEntryTemplate",
+			Handler:    _EntryTemplates_DeleteEntryTemplate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/server/entrytemplate/entrytemplate.proto",
+}
@@ -0,0 +1,475 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11-devel
+// 	protoc        v7.35.0
+// source: private/server/featureflags/featureflags.proto
+
+package featureflags
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FeatureFlag struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the feature flag (e.g. "wit-svid").
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Whether or not the feature flag is enabled.
+	Enabled       bool `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeatureFlag) Reset() {
+	*x = FeatureFlag{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeatureFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureFlag) ProtoMessage() {}
+
+func (x *FeatureFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureFlag.ProtoReflect.Descriptor instead.
+func (*FeatureFlag) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FeatureFlag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FeatureFlag) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type AgentFeatureFlags struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The SPIFFE ID of the agent.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The names of the feature flags enabled on the agent.
+	Enabled []string `protobuf:"bytes,2,rep,name=enabled,proto3" json:"enabled,omitempty"`
+	// When the agent last reported its feature flags (unix epoch in seconds).
+	ReportedAt    int64 `protobuf:"varint,3,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentFeatureFlags) Reset() {
+	*x = AgentFeatureFlags{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentFeatureFlags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentFeatureFlags) ProtoMessage() {}
+
+func (x *AgentFeatureFlags) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentFeatureFlags.ProtoReflect.Descriptor instead.
+func (*AgentFeatureFlags) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AgentFeatureFlags) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *AgentFeatureFlags) GetEnabled() []string {
+	if x != nil {
+		return x.Enabled
+	}
+	return nil
+}
+
+func (x *AgentFeatureFlags) GetReportedAt() int64 {
+	if x != nil {
+		return x.ReportedAt
+	}
+	return 0
+}
+
+type ServerFeatureFlags struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the server (the hostname of the server).
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	// The names of the feature flags enabled on the server.
+	Enabled []string `protobuf:"bytes,2,rep,name=enabled,proto3" json:"enabled,omitempty"`
+	// When the server last reported its feature flags (unix epoch in seconds).
+	ReportedAt    int64 `protobuf:"varint,3,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerFeatureFlags) Reset() {
+	*x = ServerFeatureFlags{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerFeatureFlags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerFeatureFlags) ProtoMessage() {}
+
+func (x *ServerFeatureFlags) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerFeatureFlags.ProtoReflect.Descriptor instead.
+func (*ServerFeatureFlags) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ServerFeatureFlags) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *ServerFeatureFlags) GetEnabled() []string {
+	if x != nil {
+		return x.Enabled
+	}
+	return nil
+}
+
+func (x *ServerFeatureFlags) GetReportedAt() int64 {
+	if x != nil {
+		return x.ReportedAt
+	}
+	return 0
+}
+
+type ListFeatureFlagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFeatureFlagsRequest) Reset() {
+	*x = ListFeatureFlagsRequest{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFeatureFlagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFeatureFlagsRequest) ProtoMessage() {}
+
+func (x *ListFeatureFlagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFeatureFlagsRequest.ProtoReflect.Descriptor instead.
+func (*ListFeatureFlagsRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{3}
+}
+
+type ListFeatureFlagsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The feature flags of the server handling the request.
+	Server []*FeatureFlag `protobuf:"bytes,1,rep,name=server,proto3" json:"server,omitempty"`
+	// The feature flags reported by the agents.
+	Agents []*AgentFeatureFlags `protobuf:"bytes,2,rep,name=agents,proto3" json:"agents,omitempty"`
+	// The feature flags reported by the servers, including the server
+	// handling the request.
+	Servers       []*ServerFeatureFlags `protobuf:"bytes,3,rep,name=servers,proto3" json:"servers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFeatureFlagsResponse) Reset() {
+	*x = ListFeatureFlagsResponse{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFeatureFlagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFeatureFlagsResponse) ProtoMessage() {}
+
+func (x *ListFeatureFlagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFeatureFlagsResponse.ProtoReflect.Descriptor instead.
+func (*ListFeatureFlagsResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListFeatureFlagsResponse) GetServer() []*FeatureFlag {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+func (x *ListFeatureFlagsResponse) GetAgents() []*AgentFeatureFlags {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *ListFeatureFlagsResponse) GetServers() []*ServerFeatureFlags {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+type ReloadFeatureFlagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadFeatureFlagsRequest) Reset() {
+	*x = ReloadFeatureFlagsRequest{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadFeatureFlagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadFeatureFlagsRequest) ProtoMessage() {}
+
+func (x *ReloadFeatureFlagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadFeatureFlagsRequest.ProtoReflect.Descriptor instead.
+func (*ReloadFeatureFlagsRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{5}
+}
+
+type ReloadFeatureFlagsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The feature flags of the server after the reload.
+	Server        []*FeatureFlag `protobuf:"bytes,1,rep,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadFeatureFlagsResponse) Reset() {
+	*x = ReloadFeatureFlagsResponse{}
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadFeatureFlagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadFeatureFlagsResponse) ProtoMessage() {}
+
+func (x *ReloadFeatureFlagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_featureflags_featureflags_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadFeatureFlagsResponse.ProtoReflect.Descriptor instead.
+func (*ReloadFeatureFlagsResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_featureflags_featureflags_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReloadFeatureFlagsResponse) GetServer() []*FeatureFlag {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+var File_private_server_featureflags_featureflags_proto protoreflect.FileDescriptor
+
+const file_private_server_featureflags_featureflags_proto_rawDesc = "" +
+	"\n" +
+	".private/server/featureflags/featureflags.proto\x12!spire.private.server.featureflags\";\n" +
+	"\vFeatureFlag\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\"k\n" +
+	"\x11AgentFeatureFlags\x12\x1b\n" +
+	"\tspiffe_id\x18\x01 \x01(\tR\bspiffeId\x12\x18\n" +
+	"\aenabled\x18\x02 \x03(\tR\aenabled\x12\x1f\n" +
+	"\vreported_at\x18\x03 \x01(\x03R\n" +
+	"reportedAt\"l\n" +
+	"\x12ServerFeatureFlags\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x18\n" +
+	"\aenabled\x18\x02 \x03(\tR\aenabled\x12\x1f\n" +
+	"\vreported_at\x18\x03 \x01(\x03R\n" +
+	"reportedAt\"\x19\n" +
+	"\x17ListFeatureFlagsRequest\"\x81\x02\n" +
+	"\x18ListFeatureFlagsResponse\x12F\n" +
+	"\x06server\x18\x01 \x03(\v2..spire.private.server.featureflags.FeatureFlagR\x06server\x12L\n" +
+	"\x06agents\x18\x02 \x03(\v24.spire.private.server.featureflags.AgentFeatureFlagsR\x06agents\x12O\n" +
+	"\aservers\x18\x03 \x03(\v25.spire.private.server.featureflags.ServerFeatureFlagsR\aservers\"\x1b\n" +
+	"\x19ReloadFeatureFlagsRequest\"d\n" +
+	"\x1aReloadFeatureFlagsResponse\x12F\n" +
+	"\x06server\x18\x01 \x03(\v2..spire.private.server.featureflags.FeatureFlagR\x06server2\xb0\x02\n" +
+	"\fFeatureFlags\x12\x8b\x01\n" +
+	"\x10ListFeatureFlags\x12:.spire.private.server.featureflags.ListFeatureFlagsRequest\x1a;.spire.private.server.featureflags.ListFeatureFlagsResponse\x12\x91\x01\n" +
+	"\x12ReloadFeatureFlags\x12<.spire.private.server.featureflags.ReloadFeatureFlagsRequest\x1a=.spire.private.server.featureflags.ReloadFeatureFlagsResponseB;Z9github.com/spiffe/spire/proto/private/server/featureflagsb\x06proto3"
+
+var (
+	file_private_server_featureflags_featureflags_proto_rawDescOnce sync.Once
+	file_private_server_featureflags_featureflags_proto_rawDescData []byte
+)
+
+func file_private_server_featureflags_featureflags_proto_rawDescGZIP() []byte {
+	file_private_server_featureflags_featureflags_proto_rawDescOnce.Do(func() {
+		file_private_server_featureflags_featureflags_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_private_server_featureflags_featureflags_proto_rawDesc), len(file_private_server_featureflags_featureflags_proto_rawDesc)))
+	})
+	return file_private_server_featureflags_featureflags_proto_rawDescData
+}
+
+var file_private_server_featureflags_featureflags_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_private_server_featureflags_featureflags_proto_goTypes = []any{
+	(*FeatureFlag)(nil),                // 0: spire.private.server.featureflags.FeatureFlag
+	(*AgentFeatureFlags)(nil),          // 1: spire.private.server.featureflags.AgentFeatureFlags
+	(*ServerFeatureFlags)(nil),         // 2: spire.private.server.featureflags.ServerFeatureFlags
+	(*ListFeatureFlagsRequest)(nil),    // 3: spire.private.server.featureflags.ListFeatureFlagsRequest
+	(*ListFeatureFlagsResponse)(nil),   // 4: spire.private.server.featureflags.ListFeatureFlagsResponse
+	(*ReloadFeatureFlagsRequest)(nil),  // 5: spire.private.server.featureflags.ReloadFeatureFlagsRequest
+	(*ReloadFeatureFlagsResponse)(nil), // 6: spire.private.server.featureflags.ReloadFeatureFlagsResponse
+}
+var file_private_server_featureflags_featureflags_proto_depIdxs = []int32{
+	0, // 0: spire.private.server.featureflags.ListFeatureFlagsResponse.server:type_name -> spire.private.server.featureflags.FeatureFlag
+	1, // 1: spire.private.server.featureflags.ListFeatureFlagsResponse.agents:type_name -> spire.private.server.featureflags.AgentFeatureFlags
+	2, // 2: spire.private.server.featureflags.ListFeatureFlagsResponse.servers:type_name -> spire.private.server.featureflags.ServerFeatureFlags
+	0, // 3: spire.private.server.featureflags.ReloadFeatureFlagsResponse.server:type_name -> spire.private.server.featureflags.FeatureFlag
+	3, // 4: spire.private.server.featureflags.FeatureFlags.ListFeatureFlags:input_type -> spire.private.server.featureflags.ListFeatureFlagsRequest
+	5, // 5: spire.private.server.featureflags.FeatureFlags.ReloadFeatureFlags:input_type -> spire.private.server.featureflags.ReloadFeatureFlagsRequest
+	4, // 6: spire.private.server.featureflags.FeatureFlags.ListFeatureFlags:output_type -> spire.private.server.featureflags.ListFeatureFlagsResponse
+	6, // 7: spire.private.server.featureflags.FeatureFlags.ReloadFeatureFlags:output_type -> spire.private.server.featureflags.ReloadFeatureFlagsResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_private_server_featureflags_featureflags_proto_init() }
+func file_private_server_featureflags_featureflags_proto_init() {
+	if File_private_server_featureflags_featureflags_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_private_server_featureflags_featureflags_proto_rawDesc), len(file_private_server_featureflags_featureflags_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_private_server_featureflags_featureflags_proto_goTypes,
+		DependencyIndexes: file_private_server_featureflags_featureflags_proto_depIdxs,
+		MessageInfos:      file_private_server_featureflags_featureflags_proto_msgTypes,
+	}.Build()
+	File_private_server_featureflags_featureflags_proto = out.File
+	file_private_server_featureflags_featureflags_proto_goTypes = nil
+	file_private_server_featureflags_featureflags_proto_depIdxs = nil
+}
@@ -0,0 +1,152 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v7.35.0
+// source: private/server/featureflags/featureflags.proto
+
+package featureflags
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	FeatureFlags_ListFeatureFlags_FullMethodName   = "/spire.private.server.featureflags.FeatureFlags/ListFeatureFlags"
+	FeatureFlags_ReloadFeatureFlags_FullMethodName = "/spire.private.server.featureflags.FeatureFlags/ReloadFeatureFlags"
+)
+
+// FeatureFlagsClient is the client API for FeatureFlags service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FeatureFlagsClient interface {
+	// Lists the feature flags of the server along with the feature flags
+	// reported by each server and agent sharing its datastore.
+	ListFeatureFlags(ctx context.Context, in *ListFeatureFlagsRequest, opts ...grpc.CallOption) (*ListFeatureFlagsResponse, error)
+	// Reloads the feature flags from the server configuration file.
+	ReloadFeatureFlags(ctx context.Context, in *ReloadFeatureFlagsRequest, opts ...grpc.CallOption) (*ReloadFeatureFlagsResponse, error)
+}
+
+type featureFlagsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFeatureFlagsClient(cc grpc.ClientConnInterface) FeatureFlagsClient {
+	return &featureFlagsClient{cc}
+}
+
+func (c *featureFlagsClient) ListFeatureFlags(ctx context.Context, in *ListFeatureFlagsRequest, opts ...grpc.CallOption) (*ListFeatureFlagsResponse, error) {
+	out := new(ListFeatureFlagsResponse)
+	err := c.cc.Invoke(ctx, FeatureFlags_ListFeatureFlags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagsClient) ReloadFeatureFlags(ctx context.Context, in *ReloadFeatureFlagsRequest, opts ...grpc.CallOption) (*ReloadFeatureFlagsResponse, error) {
+	out := new(ReloadFeatureFlagsResponse)
+	err := c.cc.Invoke(ctx, FeatureFlags_ReloadFeatureFlags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FeatureFlagsServer is the server API for FeatureFlags service.
+// All implementations must embed UnimplementedFeatureFlagsServer
+// for forward compatibility
+type FeatureFlagsServer interface {
+	// Lists the feature flags of the server along with the feature flags
+	// reported by each server and agent sharing its datastore.
+	ListFeatureFlags(context.Context, *ListFeatureFlagsRequest) (*ListFeatureFlagsResponse, error)
+	// Reloads the feature flags from the server configuration file.
+	ReloadFeatureFlags(context.Context, *ReloadFeatureFlagsRequest) (*ReloadFeatureFlagsResponse, error)
+	mustEmbedUnimplementedFeatureFlagsServer()
+}
+
+// UnimplementedFeatureFlagsServer must be embedded to have forward compatible implementations.
+type UnimplementedFeatureFlagsServer struct {
+}
+
+func (UnimplementedFeatureFlagsServer) ListFeatureFlags(context.Context, *ListFeatureFlagsRequest) (*ListFeatureFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFeatureFlags not implemented")
+}
+func (UnimplementedFeatureFlagsServer) ReloadFeatureFlags(context.Context, *ReloadFeatureFlagsRequest) (*ReloadFeatureFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadFeatureFlags not implemented")
+}
+func (UnimplementedFeatureFlagsServer) mustEmbedUnimplementedFeatureFlagsServer() {}
+
+// UnsafeFeatureFlagsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FeatureFlagsServer will
+// result in compilation errors.
+type UnsafeFeatureFlagsServer interface {
+	mustEmbedUnimplementedFeatureFlagsServer()
+}
+
+func RegisterFeatureFlagsServer(s grpc.ServiceRegistrar, srv FeatureFlagsServer) {
+	s.RegisterService(&FeatureFlags_ServiceDesc, srv)
+}
+
+func _FeatureFlags_ListFeatureFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFeatureFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagsServer).ListFeatureFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlags_ListFeatureFlags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagsServer).ListFeatureFlags(ctx, req.(*ListFeatureFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlags_ReloadFeatureFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadFeatureFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagsServer).ReloadFeatureFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlags_ReloadFeatureFlags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagsServer).ReloadFeatureFlags(ctx, req.(*ReloadFeatureFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FeatureFlags_ServiceDesc is the grpc.ServiceDesc for FeatureFlags service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FeatureFlags_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.server.featureflags.FeatureFlags",
+	HandlerType: (*FeatureFlagsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListFeatureFlags",
+			Handler:    _FeatureFlags_ListFeatureFlags_Handler,
+		},
+		{
+			MethodName: "ReloadFeatureFlags",
+			Handler:    _FeatureFlags_ReloadFeatureFlags_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "private/server/featureflags/featureflags.proto",
+}
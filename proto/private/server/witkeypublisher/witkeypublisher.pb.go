@@ -0,0 +1,260 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11-devel
+// 	protoc        v7.35.0
+// source: private/server/witkeypublisher/witkeypublisher.proto
+
+package witkeypublisher
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WITKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The PKIX encoded public key.
+	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// The key identifier.
+	KeyId string `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// When the key expires (seconds since Unix epoch). If zero, the key does
+	// not expire.
+	ExpiresAt int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Whether the key has been tainted. A tainted key is not safe to be used
+	// anymore.
+	Tainted       bool `protobuf:"varint,4,opt,name=tainted,proto3" json:"tainted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WITKey) Reset() {
+	*x = WITKey{}
+	mi := &file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WITKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WITKey) ProtoMessage() {}
+
+func (x *WITKey) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WITKey.ProtoReflect.Descriptor instead.
+func (*WITKey) Descriptor() ([]byte, []int) {
+	return file_private_server_witkeypublisher_witkeypublisher_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WITKey) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *WITKey) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *WITKey) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *WITKey) GetTainted() bool {
+	if x != nil {
+		return x.Tainted
+	}
+	return false
+}
+
+type PublishWITKeyAndSubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The WIT key to publish.
+	WitKey        *WITKey `protobuf:"bytes,1,opt,name=wit_key,json=witKey,proto3" json:"wit_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishWITKeyAndSubscribeRequest) Reset() {
+	*x = PublishWITKeyAndSubscribeRequest{}
+	mi := &file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishWITKeyAndSubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishWITKeyAndSubscribeRequest) ProtoMessage() {}
+
+func (x *PublishWITKeyAndSubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishWITKeyAndSubscribeRequest.ProtoReflect.Descriptor instead.
+func (*PublishWITKeyAndSubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_witkeypublisher_witkeypublisher_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PublishWITKeyAndSubscribeRequest) GetWitKey() *WITKey {
+	if x != nil {
+		return x.WitKey
+	}
+	return nil
+}
+
+type PublishWITKeyAndSubscribeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The WIT authorities of the upstream authority, including the published
+	// key.
+	UpstreamWitAuthorities []*WITKey `protobuf:"bytes,1,rep,name=upstream_wit_authorities,json=upstreamWitAuthorities,proto3" json:"upstream_wit_authorities,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *PublishWITKeyAndSubscribeResponse) Reset() {
+	*x = PublishWITKeyAndSubscribeResponse{}
+	mi := &file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishWITKeyAndSubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishWITKeyAndSubscribeResponse) ProtoMessage() {}
+
+func (x *PublishWITKeyAndSubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishWITKeyAndSubscribeResponse.ProtoReflect.Descriptor instead.
+func (*PublishWITKeyAndSubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_witkeypublisher_witkeypublisher_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PublishWITKeyAndSubscribeResponse) GetUpstreamWitAuthorities() []*WITKey {
+	if x != nil {
+		return x.UpstreamWitAuthorities
+	}
+	return nil
+}
+
+var File_private_server_witkeypublisher_witkeypublisher_proto protoreflect.FileDescriptor
+
+const file_private_server_witkeypublisher_witkeypublisher_proto_rawDesc = "" +
+	"\n" +
+	"4private/server/witkeypublisher/witkeypublisher.proto\x12$spire.private.server.witkeypublisher\"w\n" +
+	"\x06WITKey\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x01 \x01(\fR\tpublicKey\x12\x15\n" +
+	"\x06key_id\x18\x02 \x01(\tR\x05keyId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12\x18\n" +
+	"\atainted\x18\x04 \x01(\bR\atainted\"i\n" +
+	" PublishWITKeyAndSubscribeRequest\x12E\n" +
+	"\awit_key\x18\x01 \x01(\v2,.spire.private.server.witkeypublisher.WITKeyR\x06witKey\"\x8b\x01\n" +
+	"!PublishWITKeyAndSubscribeResponse\x12f\n" +
+	"\x18upstream_wit_authorities\x18\x01 \x03(\v2,.spire.private.server.witkeypublisher.WITKeyR\x16upstreamWitAuthorities2\xc2\x01\n" +
+	"\x0fWITKeyPublisher\x12\xae\x01\n" +
+	"\x19PublishWITKeyAndSubscribe\x12F.spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeRequest\x1aG.spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeResponse0\x01B>Z<github.com/spiffe/spire/proto/private/server/witkeypublisherb\x06proto3"
+
+var (
+	file_private_server_witkeypublisher_witkeypublisher_proto_rawDescOnce sync.Once
+	file_private_server_witkeypublisher_witkeypublisher_proto_rawDescData []byte
+)
+
+func file_private_server_witkeypublisher_witkeypublisher_proto_rawDescGZIP() []byte {
+	file_private_server_witkeypublisher_witkeypublisher_proto_rawDescOnce.Do(func() {
+		file_private_server_witkeypublisher_witkeypublisher_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_private_server_witkeypublisher_witkeypublisher_proto_rawDesc), len(file_private_server_witkeypublisher_witkeypublisher_proto_rawDesc)))
+	})
+	return file_private_server_witkeypublisher_witkeypublisher_proto_rawDescData
+}
+
+var file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_private_server_witkeypublisher_witkeypublisher_proto_goTypes = []any{
+	(*WITKey)(nil),                            // 0: spire.private.server.witkeypublisher.WITKey
+	(*PublishWITKeyAndSubscribeRequest)(nil),  // 1: spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeRequest
+	(*PublishWITKeyAndSubscribeResponse)(nil), // 2: spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeResponse
+}
+var file_private_server_witkeypublisher_witkeypublisher_proto_depIdxs = []int32{
+	0, // 0: spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeRequest.wit_key:type_name -> spire.private.server.witkeypublisher.WITKey
+	0, // 1: spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeResponse.upstream_wit_authorities:type_name -> spire.private.server.witkeypublisher.WITKey
+	1, // 2: spire.private.server.witkeypublisher.WITKeyPublisher.PublishWITKeyAndSubscribe:input_type -> spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeRequest
+	2, // 3: spire.private.server.witkeypublisher.WITKeyPublisher.PublishWITKeyAndSubscribe:output_type -> spire.private.server.witkeypublisher.PublishWITKeyAndSubscribeResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_private_server_witkeypublisher_witkeypublisher_proto_init() }
+func file_private_server_witkeypublisher_witkeypublisher_proto_init() {
+	if File_private_server_witkeypublisher_witkeypublisher_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_private_server_witkeypublisher_witkeypublisher_proto_rawDesc), len(file_private_server_witkeypublisher_witkeypublisher_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_private_server_witkeypublisher_witkeypublisher_proto_goTypes,
+		DependencyIndexes: file_private_server_witkeypublisher_witkeypublisher_proto_depIdxs,
+		MessageInfos:      file_private_server_witkeypublisher_witkeypublisher_proto_msgTypes,
+	}.Build()
+	File_private_server_witkeypublisher_witkeypublisher_proto = out.File
+	file_private_server_witkeypublisher_witkeypublisher_proto_goTypes = nil
+	file_private_server_witkeypublisher_witkeypublisher_proto_depIdxs = nil
+}
@@ -0,0 +1,142 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v7.35.0
+// source: private/server/witkeypublisher/witkeypublisher.proto
+
+package witkeypublisher
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	WITKeyPublisher_PublishWITKeyAndSubscribe_FullMethodName = "/spire.private.server.witkeypublisher.WITKeyPublisher/PublishWITKeyAndSubscribe"
+)
+
+// WITKeyPublisherClient is the client API for WITKeyPublisher service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WITKeyPublisherClient interface {
+	// Publishes a WIT key with the upstream authority and streams the WIT
+	// authorities of the upstream authority. The first response is sent once
+	// the key has been published.
+	PublishWITKeyAndSubscribe(ctx context.Context, in *PublishWITKeyAndSubscribeRequest, opts ...grpc.CallOption) (WITKeyPublisher_PublishWITKeyAndSubscribeClient, error)
+}
+
+type wITKeyPublisherClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWITKeyPublisherClient(cc grpc.ClientConnInterface) WITKeyPublisherClient {
+	return &wITKeyPublisherClient{cc}
+}
+
+func (c *wITKeyPublisherClient) PublishWITKeyAndSubscribe(ctx context.Context, in *PublishWITKeyAndSubscribeRequest, opts ...grpc.CallOption) (WITKeyPublisher_PublishWITKeyAndSubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &WITKeyPublisher_ServiceDesc.Streams[0], WITKeyPublisher_PublishWITKeyAndSubscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &wITKeyPublisherPublishWITKeyAndSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WITKeyPublisher_PublishWITKeyAndSubscribeClient interface {
+	Recv() (*PublishWITKeyAndSubscribeResponse, error)
+	grpc.ClientStream
+}
+
+type wITKeyPublisherPublishWITKeyAndSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *wITKeyPublisherPublishWITKeyAndSubscribeClient) Recv() (*PublishWITKeyAndSubscribeResponse, error) {
+	m := new(PublishWITKeyAndSubscribeResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WITKeyPublisherServer is the server API for WITKeyPublisher service.
+// All implementations must embed UnimplementedWITKeyPublisherServer
+// for forward compatibility
+type WITKeyPublisherServer interface {
+	// Publishes a WIT key with the upstream authority and streams the WIT
+	// authorities of the upstream authority. The first response is sent once
+	// the key has been published.
+	PublishWITKeyAndSubscribe(*PublishWITKeyAndSubscribeRequest, WITKeyPublisher_PublishWITKeyAndSubscribeServer) error
+	mustEmbedUnimplementedWITKeyPublisherServer()
+}
+
+// UnimplementedWITKeyPublisherServer must be embedded to have forward compatible implementations.
+type UnimplementedWITKeyPublisherServer struct {
+}
+
+func (UnimplementedWITKeyPublisherServer) PublishWITKeyAndSubscribe(*PublishWITKeyAndSubscribeRequest, WITKeyPublisher_PublishWITKeyAndSubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method PublishWITKeyAndSubscribe not implemented")
+}
+func (UnimplementedWITKeyPublisherServer) mustEmbedUnimplementedWITKeyPublisherServer() {}
+
+// UnsafeWITKeyPublisherServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WITKeyPublisherServer will
+// result in compilation errors.
+type UnsafeWITKeyPublisherServer interface {
+	mustEmbedUnimplementedWITKeyPublisherServer()
+}
+
+func RegisterWITKeyPublisherServer(s grpc.ServiceRegistrar, srv WITKeyPublisherServer) {
+	s.RegisterService(&WITKeyPublisher_ServiceDesc, srv)
+}
+
+func _WITKeyPublisher_PublishWITKeyAndSubscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PublishWITKeyAndSubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WITKeyPublisherServer).PublishWITKeyAndSubscribe(m, &wITKeyPublisherPublishWITKeyAndSubscribeServer{stream})
+}
+
+type WITKeyPublisher_PublishWITKeyAndSubscribeServer interface {
+	Send(*PublishWITKeyAndSubscribeResponse) error
+	grpc.ServerStream
+}
+
+type wITKeyPublisherPublishWITKeyAndSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *wITKeyPublisherPublishWITKeyAndSubscribeServer) Send(m *PublishWITKeyAndSubscribeResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// WITKeyPublisher_ServiceDesc is the grpc.ServiceDesc for WITKeyPublisher service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WITKeyPublisher_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.private.server.witkeypublisher.WITKeyPublisher",
+	HandlerType: (*WITKeyPublisherServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PublishWITKeyAndSubscribe",
+			Handler:       _WITKeyPublisher_PublishWITKeyAndSubscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "private/server/witkeypublisher/witkeypublisher.proto",
+}
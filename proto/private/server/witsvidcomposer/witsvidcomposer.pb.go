@@ -0,0 +1,244 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11-devel
+// 	protoc        v7.35.0
+// source: private/server/witsvidcomposer/witsvidcomposer.proto
+
+package witsvidcomposer
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WITSVIDAttributes struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The claims of the WIT-SVID. The "sub" and "cnf" claims bind
+	// the WIT-SVID to the workload and cannot be changed.
+	Claims        *structpb.Struct `protobuf:"bytes,1,opt,name=claims,proto3" json:"claims,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WITSVIDAttributes) Reset() {
+	*x = WITSVIDAttributes{}
+	mi := &file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WITSVIDAttributes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WITSVIDAttributes) ProtoMessage() {}
+
+func (x *WITSVIDAttributes) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WITSVIDAttributes.ProtoReflect.Descriptor instead.
+func (*WITSVIDAttributes) Descriptor() ([]byte, []int) {
+	return file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WITSVIDAttributes) GetClaims() *structpb.Struct {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+type ComposeWorkloadWITSVIDRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The SPIFFE ID of the WIT-SVID.
+	SpiffeId string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The default attributes that will be applied to the WIT-SVID.
+	Attributes    *WITSVIDAttributes `protobuf:"bytes,2,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComposeWorkloadWITSVIDRequest) Reset() {
+	*x = ComposeWorkloadWITSVIDRequest{}
+	mi := &file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComposeWorkloadWITSVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComposeWorkloadWITSVIDRequest) ProtoMessage() {}
+
+func (x *ComposeWorkloadWITSVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComposeWorkloadWITSVIDRequest.ProtoReflect.Descriptor instead.
+func (*ComposeWorkloadWITSVIDRequest) Descriptor() ([]byte, []int) {
+	return file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ComposeWorkloadWITSVIDRequest) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *ComposeWorkloadWITSVIDRequest) GetAttributes() *WITSVIDAttributes {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type ComposeWorkloadWITSVIDResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional. The attributes to apply to the WIT-SVID. If unset, the
+	// attributes are unchanged.
+	Attributes    *WITSVIDAttributes `protobuf:"bytes,1,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComposeWorkloadWITSVIDResponse) Reset() {
+	*x = ComposeWorkloadWITSVIDResponse{}
+	mi := &file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComposeWorkloadWITSVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComposeWorkloadWITSVIDResponse) ProtoMessage() {}
+
+func (x *ComposeWorkloadWITSVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComposeWorkloadWITSVIDResponse.ProtoReflect.Descriptor instead.
+func (*ComposeWorkloadWITSVIDResponse) Descriptor() ([]byte, []int) {
+	return file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ComposeWorkloadWITSVIDResponse) GetAttributes() *WITSVIDAttributes {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+var File_private_server_witsvidcomposer_witsvidcomposer_proto protoreflect.FileDescriptor
+
+const file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDesc = "" +
+	"\n" +
+	"4private/server/witsvidcomposer/witsvidcomposer.proto\x12$spire.private.server.witsvidcomposer\x1a\x1cgoogle/protobuf/struct.proto\"D\n" +
+	"\x11WITSVIDAttributes\x12/\n" +
+	"\x06claims\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06claims\"\x95\x01\n" +
+	"\x1dComposeWorkloadWITSVIDRequest\x12\x1b\n" +
+	"\tspiffe_id\x18\x01 \x01(\tR\bspiffeId\x12W\n" +
+	"\n" +
+	"attributes\x18\x02 \x01(\v27.spire.private.server.witsvidcomposer.WITSVIDAttributesR\n" +
+	"attributes\"y\n" +
+	"\x1eComposeWorkloadWITSVIDResponse\x12W\n" +
+	"\n" +
+	"attributes\x18\x01 \x01(\v27.spire.private.server.witsvidcomposer.WITSVIDAttributesR\n" +
+	"attributes2\xb7\x01\n" +
+	"\x0fWITSVIDComposer\x12\xa3\x01\n" +
+	"\x16ComposeWorkloadWITSVID\x12C.spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDRequest\x1aD.spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDResponseB>Z<github.com/spiffe/spire/proto/private/server/witsvidcomposerb\x06proto3"
+
+var (
+	file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescOnce sync.Once
+	file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescData []byte
+)
+
+func file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescGZIP() []byte {
+	file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescOnce.Do(func() {
+		file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDesc), len(file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDesc)))
+	})
+	return file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDescData
+}
+
+var file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_private_server_witsvidcomposer_witsvidcomposer_proto_goTypes = []any{
+	(*WITSVIDAttributes)(nil),              // 0: spire.private.server.witsvidcomposer.WITSVIDAttributes
+	(*ComposeWorkloadWITSVIDRequest)(nil),  // 1: spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDRequest
+	(*ComposeWorkloadWITSVIDResponse)(nil), // 2: spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDResponse
+	(*structpb.Struct)(nil),                // 3: google.protobuf.Struct
+}
+var file_private_server_witsvidcomposer_witsvidcomposer_proto_depIdxs = []int32{
+	3, // 0: spire.private.server.witsvidcomposer.WITSVIDAttributes.claims:type_name -> google.protobuf.Struct
+	0, // 1: spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDRequest.attributes:type_name -> spire.private.server.witsvidcomposer.WITSVIDAttributes
+	0, // 2: spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDResponse.attributes:type_name -> spire.private.server.witsvidcomposer.WITSVIDAttributes
+	1, // 3: spire.private.server.witsvidcomposer.WITSVIDComposer.ComposeWorkloadWITSVID:input_type -> spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDRequest
+	2, // 4: spire.private.server.witsvidcomposer.WITSVIDComposer.ComposeWorkloadWITSVID:output_type -> spire.private.server.witsvidcomposer.ComposeWorkloadWITSVIDResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_private_server_witsvidcomposer_witsvidcomposer_proto_init() }
+func file_private_server_witsvidcomposer_witsvidcomposer_proto_init() {
+	if File_private_server_witsvidcomposer_witsvidcomposer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDesc), len(file_private_server_witsvidcomposer_witsvidcomposer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_private_server_witsvidcomposer_witsvidcomposer_proto_goTypes,
+		DependencyIndexes: file_private_server_witsvidcomposer_witsvidcomposer_proto_depIdxs,
+		MessageInfos:      file_private_server_witsvidcomposer_witsvidcomposer_proto_msgTypes,
+	}.Build()
+	File_private_server_witsvidcomposer_witsvidcomposer_proto = out.File
+	file_private_server_witsvidcomposer_witsvidcomposer_proto_goTypes = nil
+	file_private_server_witsvidcomposer_witsvidcomposer_proto_depIdxs = nil
+}
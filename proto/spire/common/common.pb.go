@@ -716,6 +716,139 @@ func (x *RegistrationEntries) GetEntries() []*RegistrationEntry {
 	return nil
 }
 
+// * An EntryTemplate is a template for the registration entries of the
+// workloads it matches. The SPIFFE ID of each workload is rendered from the
+// selectors of the workload.
+type EntryTemplate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// * Entry template ID
+	TemplateId string `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	// * A list of selectors.
+	Selectors []*Selector `protobuf:"bytes,2,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	// * The SPIFFE ID of an entity that is authorized to attest the validity
+	// of a selector
+	ParentId string `protobuf:"bytes,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	// * The SPIFFE ID template, whose path is a template over the selectors
+	// of the workloads matching the entry template (e.g.
+	// "spiffe://example.org/ns/{{ .k8s.ns }}/sa/{{ .k8s.sa }}").
+	SpiffeIdTemplate string `protobuf:"bytes,4,opt,name=spiffe_id_template,json=spiffeIdTemplate,proto3" json:"spiffe_id_template,omitempty"`
+	// * Time to live for X509-SVIDs generated from this entry template.
+	X509SvidTtl int32 `protobuf:"varint,5,opt,name=x509_svid_ttl,json=x509SvidTtl,proto3" json:"x509_svid_ttl,omitempty"`
+	// * Time to live for JWT-SVIDs generated from this entry template.
+	JwtSvidTtl int32 `protobuf:"varint,6,opt,name=jwt_svid_ttl,json=jwtSvidTtl,proto3" json:"jwt_svid_ttl,omitempty"`
+	// * A list of federated trust domain SPIFFE IDs.
+	FederatesWith []string `protobuf:"bytes,7,rep,name=federates_with,json=federatesWith,proto3" json:"federates_with,omitempty"`
+	// * DNS entries
+	DnsNames []string `protobuf:"bytes,8,rep,name=dns_names,json=dnsNames,proto3" json:"dns_names,omitempty"`
+	// * An operator-specified string used to provide guidance on how this
+	// identity should be used by a workload when more than one SVID is returned.
+	Hint string `protobuf:"bytes,9,opt,name=hint,proto3" json:"hint,omitempty"`
+	// * Time of creation, in seconds from epoch
+	CreatedAt     int64 `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryTemplate) Reset() {
+	*x = EntryTemplate{}
+	mi := &file_spire_common_common_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryTemplate) ProtoMessage() {}
+
+func (x *EntryTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_common_common_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryTemplate.ProtoReflect.Descriptor instead.
+func (*EntryTemplate) Descriptor() ([]byte, []int) {
+	return file_spire_common_common_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *EntryTemplate) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *EntryTemplate) GetSelectors() []*Selector {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+func (x *EntryTemplate) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *EntryTemplate) GetSpiffeIdTemplate() string {
+	if x != nil {
+		return x.SpiffeIdTemplate
+	}
+	return ""
+}
+
+func (x *EntryTemplate) GetX509SvidTtl() int32 {
+	if x != nil {
+		return x.X509SvidTtl
+	}
+	return 0
+}
+
+func (x *EntryTemplate) GetJwtSvidTtl() int32 {
+	if x != nil {
+		return x.JwtSvidTtl
+	}
+	return 0
+}
+
+func (x *EntryTemplate) GetFederatesWith() []string {
+	if x != nil {
+		return x.FederatesWith
+	}
+	return nil
+}
+
+func (x *EntryTemplate) GetDnsNames() []string {
+	if x != nil {
+		return x.DnsNames
+	}
+	return nil
+}
+
+func (x *EntryTemplate) GetHint() string {
+	if x != nil {
+		return x.Hint
+	}
+	return ""
+}
+
+func (x *EntryTemplate) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
 // * Certificate represents a ASN.1/DER encoded X509 certificate
 type Certificate struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -727,7 +860,7 @@ type Certificate struct {
 
 func (x *Certificate) Reset() {
 	*x = Certificate{}
-	mi := &file_spire_common_common_proto_msgTypes[8]
+	mi := &file_spire_common_common_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -739,7 +872,7 @@ func (x *Certificate) String() string {
 func (*Certificate) ProtoMessage() {}
 
 func (x *Certificate) ProtoReflect() protoreflect.Message {
-	mi := &file_spire_common_common_proto_msgTypes[8]
+	mi := &file_spire_common_common_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -752,7 +885,7 @@ func (x *Certificate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
 func (*Certificate) Descriptor() ([]byte, []int) {
-	return file_spire_common_common_proto_rawDescGZIP(), []int{8}
+	return file_spire_common_common_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Certificate) GetDerBytes() []byte {
@@ -786,7 +919,7 @@ type PublicKey struct {
 
 func (x *PublicKey) Reset() {
 	*x = PublicKey{}
-	mi := &file_spire_common_common_proto_msgTypes[9]
+	mi := &file_spire_common_common_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -798,7 +931,7 @@ func (x *PublicKey) String() string {
 func (*PublicKey) ProtoMessage() {}
 
 func (x *PublicKey) ProtoReflect() protoreflect.Message {
-	mi := &file_spire_common_common_proto_msgTypes[9]
+	mi := &file_spire_common_common_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -811,7 +944,7 @@ func (x *PublicKey) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PublicKey.ProtoReflect.Descriptor instead.
 func (*PublicKey) Descriptor() ([]byte, []int) {
-	return file_spire_common_common_proto_rawDescGZIP(), []int{9}
+	return file_spire_common_common_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *PublicKey) GetPkixBytes() []byte {
@@ -864,7 +997,7 @@ type Bundle struct {
 
 func (x *Bundle) Reset() {
 	*x = Bundle{}
-	mi := &file_spire_common_common_proto_msgTypes[10]
+	mi := &file_spire_common_common_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -876,7 +1009,7 @@ func (x *Bundle) String() string {
 func (*Bundle) ProtoMessage() {}
 
 func (x *Bundle) ProtoReflect() protoreflect.Message {
-	mi := &file_spire_common_common_proto_msgTypes[10]
+	mi := &file_spire_common_common_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -889,7 +1022,7 @@ func (x *Bundle) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Bundle.ProtoReflect.Descriptor instead.
 func (*Bundle) Descriptor() ([]byte, []int) {
-	return file_spire_common_common_proto_rawDescGZIP(), []int{10}
+	return file_spire_common_common_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Bundle) GetTrustDomainId() string {
@@ -948,7 +1081,7 @@ type BundleMask struct {
 
 func (x *BundleMask) Reset() {
 	*x = BundleMask{}
-	mi := &file_spire_common_common_proto_msgTypes[11]
+	mi := &file_spire_common_common_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -960,7 +1093,7 @@ func (x *BundleMask) String() string {
 func (*BundleMask) ProtoMessage() {}
 
 func (x *BundleMask) ProtoReflect() protoreflect.Message {
-	mi := &file_spire_common_common_proto_msgTypes[11]
+	mi := &file_spire_common_common_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -973,7 +1106,7 @@ func (x *BundleMask) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BundleMask.ProtoReflect.Descriptor instead.
 func (*BundleMask) Descriptor() ([]byte, []int) {
-	return file_spire_common_common_proto_rawDescGZIP(), []int{11}
+	return file_spire_common_common_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *BundleMask) GetRootCas() bool {
@@ -1033,7 +1166,7 @@ type AttestedNodeMask struct {
 
 func (x *AttestedNodeMask) Reset() {
 	*x = AttestedNodeMask{}
-	mi := &file_spire_common_common_proto_msgTypes[12]
+	mi := &file_spire_common_common_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1045,7 +1178,7 @@ func (x *AttestedNodeMask) String() string {
 func (*AttestedNodeMask) ProtoMessage() {}
 
 func (x *AttestedNodeMask) ProtoReflect() protoreflect.Message {
-	mi := &file_spire_common_common_proto_msgTypes[12]
+	mi := &file_spire_common_common_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1058,7 +1191,7 @@ func (x *AttestedNodeMask) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AttestedNodeMask.ProtoReflect.Descriptor instead.
 func (*AttestedNodeMask) Descriptor() ([]byte, []int) {
-	return file_spire_common_common_proto_rawDescGZIP(), []int{12}
+	return file_spire_common_common_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *AttestedNodeMask) GetAttestationDataType() bool {
@@ -1135,7 +1268,7 @@ type RegistrationEntry_AdditionalAttributes struct {
 
 func (x *RegistrationEntry_AdditionalAttributes) Reset() {
 	*x = RegistrationEntry_AdditionalAttributes{}
-	mi := &file_spire_common_common_proto_msgTypes[13]
+	mi := &file_spire_common_common_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1147,7 +1280,7 @@ func (x *RegistrationEntry_AdditionalAttributes) String() string {
 func (*RegistrationEntry_AdditionalAttributes) ProtoMessage() {}
 
 func (x *RegistrationEntry_AdditionalAttributes) ProtoReflect() protoreflect.Message {
-	mi := &file_spire_common_common_proto_msgTypes[13]
+	mi := &file_spire_common_common_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1249,7 +1382,22 @@ const file_spire_common_common_proto_rawDesc = "" +
 	"\x04hint\x18\r \x01(\bR\x04hint\x123\n" +
 	"\x15additional_attributes\x18\x0e \x01(\bR\x14additionalAttributes\"P\n" +
 	"\x13RegistrationEntries\x129\n" +
-	"\aentries\x18\x01 \x03(\v2\x1f.spire.common.RegistrationEntryR\aentries\"K\n" +
+	"\aentries\x18\x01 \x03(\v2\x1f.spire.common.RegistrationEntryR\aentries\"\xee\x02\n" +
+	"\rEntryTemplate\x12\x1f\n" +
+	"\vtemplate_id\x18\x01 \x01(\tR\n" +
+	"templateId\x124\n" +
+	"\tselectors\x18\x02 \x03(\v2\x16.spire.common.SelectorR\tselectors\x12\x1b\n" +
+	"\tparent_id\x18\x03 \x01(\tR\bparentId\x12,\n" +
+	"\x12spiffe_id_template\x18\x04 \x01(\tR\x10spiffeIdTemplate\x12\"\n" +
+	"\rx509_svid_ttl\x18\x05 \x01(\x05R\vx509SvidTtl\x12 \n" +
+	"\fjwt_svid_ttl\x18\x06 \x01(\x05R\n" +
+	"jwtSvidTtl\x12%\n" +
+	"\x0efederates_with\x18\a \x03(\tR\rfederatesWith\x12\x1b\n" +
+	"\tdns_names\x18\b \x03(\tR\bdnsNames\x12\x12\n" +
+	"\x04hint\x18\t \x01(\tR\x04hint\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\x03R\tcreatedAt\"K\n" +
 	"\vCertificate\x12\x1b\n" +
 	"\tder_bytes\x18\x01 \x01(\fR\bderBytes\x12\x1f\n" +
 	"\vtainted_key\x18\x02 \x01(\bR\n" +
@@ -1297,7 +1445,7 @@ func file_spire_common_common_proto_rawDescGZIP() []byte {
 	return file_spire_common_common_proto_rawDescData
 }
 
-var file_spire_common_common_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_spire_common_common_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_spire_common_common_proto_goTypes = []any{
 	(*Empty)(nil),                                  // 0: spire.common.Empty
 	(*AttestationData)(nil),                        // 1: spire.common.AttestationData
@@ -1307,27 +1455,29 @@ var file_spire_common_common_proto_goTypes = []any{
 	(*RegistrationEntry)(nil),                      // 5: spire.common.RegistrationEntry
 	(*RegistrationEntryMask)(nil),                  // 6: spire.common.RegistrationEntryMask
 	(*RegistrationEntries)(nil),                    // 7: spire.common.RegistrationEntries
-	(*Certificate)(nil),                            // 8: spire.common.Certificate
-	(*PublicKey)(nil),                              // 9: spire.common.PublicKey
-	(*Bundle)(nil),                                 // 10: spire.common.Bundle
-	(*BundleMask)(nil),                             // 11: spire.common.BundleMask
-	(*AttestedNodeMask)(nil),                       // 12: spire.common.AttestedNodeMask
-	(*RegistrationEntry_AdditionalAttributes)(nil), // 13: spire.common.RegistrationEntry.AdditionalAttributes
+	(*EntryTemplate)(nil),                          // 8: spire.common.EntryTemplate
+	(*Certificate)(nil),                            // 9: spire.common.Certificate
+	(*PublicKey)(nil),                              // 10: spire.common.PublicKey
+	(*Bundle)(nil),                                 // 11: spire.common.Bundle
+	(*BundleMask)(nil),                             // 12: spire.common.BundleMask
+	(*AttestedNodeMask)(nil),                       // 13: spire.common.AttestedNodeMask
+	(*RegistrationEntry_AdditionalAttributes)(nil), // 14: spire.common.RegistrationEntry.AdditionalAttributes
 }
 var file_spire_common_common_proto_depIdxs = []int32{
 	2,  // 0: spire.common.Selectors.entries:type_name -> spire.common.Selector
 	2,  // 1: spire.common.AttestedNode.selectors:type_name -> spire.common.Selector
 	2,  // 2: spire.common.RegistrationEntry.selectors:type_name -> spire.common.Selector
-	13, // 3: spire.common.RegistrationEntry.additional_attributes:type_name -> spire.common.RegistrationEntry.AdditionalAttributes
+	14, // 3: spire.common.RegistrationEntry.additional_attributes:type_name -> spire.common.RegistrationEntry.AdditionalAttributes
 	5,  // 4: spire.common.RegistrationEntries.entries:type_name -> spire.common.RegistrationEntry
-	8,  // 5: spire.common.Bundle.root_cas:type_name -> spire.common.Certificate
-	9,  // 6: spire.common.Bundle.jwt_signing_keys:type_name -> spire.common.PublicKey
-	9,  // 7: spire.common.Bundle.wit_signing_keys:type_name -> spire.common.PublicKey
-	8,  // [8:8] is the sub-list for method output_type
-	8,  // [8:8] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	2,  // 5: spire.common.EntryTemplate.selectors:type_name -> spire.common.Selector
+	9,  // 6: spire.common.Bundle.root_cas:type_name -> spire.common.Certificate
+	10, // 7: spire.common.Bundle.jwt_signing_keys:type_name -> spire.common.PublicKey
+	10, // 8: spire.common.Bundle.wit_signing_keys:type_name -> spire.common.PublicKey
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_spire_common_common_proto_init() }
@@ -1342,7 +1492,7 @@ func file_spire_common_common_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_spire_common_common_proto_rawDesc), len(file_spire_common_common_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
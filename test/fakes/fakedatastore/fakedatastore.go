@@ -232,6 +232,13 @@ func (s *DataStore) TaintJWTKey(ctx context.Context, trustDomainID string, autho
 	return s.ds.TaintJWTKey(ctx, trustDomainID, authorityID)
 }
 
+func (s *DataStore) TaintWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.TaintWITKey(ctx, trustDomainID, authorityID)
+}
+
 func (s *DataStore) RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -239,6 +246,13 @@ func (s *DataStore) RevokeJWTKey(ctx context.Context, trustDomainID string, auth
 	return s.ds.RevokeJWTKey(ctx, trustDomainID, authorityID)
 }
 
+func (s *DataStore) RevokeWITKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.RevokeWITKey(ctx, trustDomainID, authorityID)
+}
+
 func (s *DataStore) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -368,6 +382,55 @@ func (s *DataStore) FetchRegistrationEntryEvent(ctx context.Context, eventID uin
 	return s.ds.FetchRegistrationEntryEvent(ctx, eventID)
 }
 
+func (s *DataStore) CreateEntryTemplate(ctx context.Context, template *common.EntryTemplate) (*common.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.CreateEntryTemplate(ctx, template)
+}
+
+func (s *DataStore) DeleteEntryTemplate(ctx context.Context, templateID string) (*common.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.DeleteEntryTemplate(ctx, templateID)
+}
+
+func (s *DataStore) FetchEntryTemplate(ctx context.Context, templateID string) (*common.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.FetchEntryTemplate(ctx, templateID)
+}
+
+func (s *DataStore) ListEntryTemplates(ctx context.Context) ([]*common.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListEntryTemplates(ctx)
+}
+
+func (s *DataStore) ListFeatureFlagStatuses(ctx context.Context) ([]*datastore.FeatureFlagStatus, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListFeatureFlagStatuses(ctx)
+}
+
+func (s *DataStore) PruneServerFeatureFlagStatuses(ctx context.Context, reportedBefore time.Time) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.PruneServerFeatureFlagStatuses(ctx, reportedBefore)
+}
+
+func (s *DataStore) SetFeatureFlagStatus(ctx context.Context, status *datastore.FeatureFlagStatus) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.SetFeatureFlagStatus(ctx, status)
+}
+
 func (s *DataStore) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -382,6 +445,13 @@ func (s *DataStore) FetchJoinToken(ctx context.Context, token string) (*datastor
 	return s.ds.FetchJoinToken(ctx, token)
 }
 
+func (s *DataStore) ListJoinTokens(ctx context.Context) ([]*datastore.JoinToken, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListJoinTokens(ctx)
+}
+
 func (s *DataStore) DeleteJoinToken(ctx context.Context, token string) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -438,11 +508,11 @@ func (s *DataStore) FetchCAJournal(ctx context.Context, activeX509AuthorityID st
 	return s.ds.FetchCAJournal(ctx, activeX509AuthorityID)
 }
 
-func (s *DataStore) ListCAJournalsForTesting(ctx context.Context) ([]*datastore.CAJournal, error) {
+func (s *DataStore) ListCAJournals(ctx context.Context) ([]*datastore.CAJournal, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
 	}
-	return s.ds.ListCAJournalsForTesting(ctx)
+	return s.ds.ListCAJournals(ctx)
 }
 
 func (s *DataStore) SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (*datastore.CAJournal, error) {
@@ -0,0 +1,102 @@
+package fakenomad
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spiffe/spire/pkg/common/plugin/nomad"
+)
+
+// Server is a stand-in for the Nomad HTTP API, serving the endpoints used by
+// the nomad plugins.
+type Server struct {
+	*httptest.Server
+
+	mtx         sync.RWMutex
+	token       string
+	jwks        *jose.JSONWebKeySet
+	allocations map[string]*nomad.Allocation
+	nodes       map[string]*nomad.Node
+}
+
+// New starts a fake Nomad HTTP API. When token is not empty, requests to the
+// /v1 endpoints must present it in the X-Nomad-Token header.
+func New(t *testing.T, token string) *Server {
+	s := &Server{
+		token:       token,
+		jwks:        new(jose.JSONWebKeySet),
+		allocations: make(map[string]*nomad.Allocation),
+		nodes:       make(map[string]*nomad.Node),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		s.mtx.RLock()
+		defer s.mtx.RUnlock()
+		writeJSON(w, s.jwks)
+	})
+	mux.HandleFunc("GET /v1/allocation/{id}", s.authorized(func(w http.ResponseWriter, r *http.Request) {
+		s.mtx.RLock()
+		defer s.mtx.RUnlock()
+		alloc, ok := s.allocations[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "alloc not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, alloc)
+	}))
+	mux.HandleFunc("GET /v1/node/{id}", s.authorized(func(w http.ResponseWriter, r *http.Request) {
+		s.mtx.RLock()
+		defer s.mtx.RUnlock()
+		node, ok := s.nodes[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "node not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, node)
+	}))
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// SetKeySet sets the keys served by the JWKS endpoint.
+func (s *Server) SetKeySet(jwks *jose.JSONWebKeySet) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.jwks = jwks
+}
+
+// AddAllocation adds or replaces an allocation.
+func (s *Server) AddAllocation(alloc *nomad.Allocation) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.allocations[alloc.ID] = alloc
+}
+
+// AddNode adds or replaces a client node.
+func (s *Server) AddNode(node *nomad.Node) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.nodes[node.ID] = node
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && r.Header.Get("X-Nomad-Token") != s.token {
+			http.Error(w, "Permission denied", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
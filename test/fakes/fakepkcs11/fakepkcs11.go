@@ -0,0 +1,465 @@
+package fakepkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/spiffe/spire/pkg/common/plugin/pkcs11"
+	"github.com/spiffe/spire/test/testkey"
+)
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+
+	pssHashes = map[uint]crypto.Hash{
+		p11.CKM_SHA224: crypto.SHA224,
+		p11.CKM_SHA256: crypto.SHA256,
+		p11.CKM_SHA384: crypto.SHA384,
+		p11.CKM_SHA512: crypto.SHA512,
+	}
+
+	ckrNames = map[ckError]string{
+		p11.CKR_ATTRIBUTE_TYPE_INVALID:  "CKR_ATTRIBUTE_TYPE_INVALID",
+		p11.CKR_CURVE_NOT_SUPPORTED:     "CKR_CURVE_NOT_SUPPORTED",
+		p11.CKR_KEY_HANDLE_INVALID:      "CKR_KEY_HANDLE_INVALID",
+		p11.CKR_KEY_SIZE_RANGE:          "CKR_KEY_SIZE_RANGE",
+		p11.CKR_KEY_TYPE_INCONSISTENT:   "CKR_KEY_TYPE_INCONSISTENT",
+		p11.CKR_MECHANISM_INVALID:       "CKR_MECHANISM_INVALID",
+		p11.CKR_MECHANISM_PARAM_INVALID: "CKR_MECHANISM_PARAM_INVALID",
+		p11.CKR_OBJECT_HANDLE_INVALID:   "CKR_OBJECT_HANDLE_INVALID",
+		p11.CKR_PIN_INCORRECT:           "CKR_PIN_INCORRECT",
+		p11.CKR_SESSION_HANDLE_INVALID:  "CKR_SESSION_HANDLE_INVALID",
+		p11.CKR_SLOT_ID_INVALID:         "CKR_SLOT_ID_INVALID",
+		p11.CKR_TEMPLATE_INCONSISTENT:   "CKR_TEMPLATE_INCONSISTENT",
+	}
+)
+
+// ckError mimics the return values reported by the PKCS#11 library, whose
+// error type is only available to cgo builds.
+type ckError uint
+
+func (e ckError) Error() string {
+	return fmt.Sprintf("pkcs11: 0x%X: %s", uint(e), ckrNames[e])
+}
+
+// Is reports the errors of lost sessions like the module loaded with cgo.
+func (e ckError) Is(target error) bool {
+	return target == pkcs11.ErrSessionLost && e == p11.CKR_SESSION_HANDLE_INVALID
+}
+
+// Token describes a token of the fake module.
+type Token struct {
+	SlotID uint
+	Label  string
+	Pin    string
+}
+
+// Module is an in-memory PKCS#11 module that stands in for a software token,
+// like SoftHSM, in tests. Objects are kept when the module is closed, so it
+// can be reopened to test persistence.
+type Module struct {
+	generator testkey.Generator
+
+	mtx        sync.Mutex
+	tokens     []*token
+	nextHandle pkcs11.ObjectHandle
+	errs       map[string]error
+	closed     bool
+
+	// generation is incremented when the tokens are reinserted, which
+	// invalidates the open sessions.
+	generation int
+}
+
+type token struct {
+	Token
+	objects map[pkcs11.ObjectHandle]*object
+}
+
+type object struct {
+	attributes map[uint][]byte
+	privateKey crypto.Signer
+}
+
+// New returns a new module with the given tokens.
+func New(tokens ...Token) *Module {
+	m := &Module{
+		nextHandle: 1,
+		errs:       make(map[string]error),
+	}
+	for _, t := range tokens {
+		m.tokens = append(m.tokens, &token{
+			Token:   t,
+			objects: make(map[pkcs11.ObjectHandle]*object),
+		})
+	}
+	return m
+}
+
+// SetError makes the operation with the given name, e.g. "Sign" or
+// "OpenSession", fail with the given error. A nil error clears the failure.
+func (m *Module) SetError(operation string, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err == nil {
+		delete(m.errs, operation)
+		return
+	}
+	m.errs[operation] = err
+}
+
+// ReinsertTokens simulates the tokens being removed and inserted again. The
+// open sessions become invalid and the objects get new handles.
+func (m *Module) ReinsertTokens() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.generation++
+	for _, t := range m.tokens {
+		handles := slices.Sorted(maps.Keys(t.objects))
+		objects := make(map[pkcs11.ObjectHandle]*object, len(handles))
+		for _, handle := range handles {
+			objects[m.nextHandle] = t.objects[handle]
+			m.nextHandle++
+		}
+		t.objects = objects
+	}
+}
+
+// Labels returns the sorted labels of the objects on the token in the given
+// slot.
+func (m *Module) Labels(slotID uint) []string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var labels []string
+	for _, t := range m.tokens {
+		if t.SlotID != slotID {
+			continue
+		}
+		for _, o := range t.objects {
+			labels = append(labels, string(o.attributes[p11.CKA_LABEL]))
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Closed returns whether the module was closed.
+func (m *Module) Closed() bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.closed
+}
+
+func (m *Module) Slots() ([]pkcs11.SlotInfo, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var slots []pkcs11.SlotInfo
+	for _, t := range m.tokens {
+		slots = append(slots, pkcs11.SlotInfo{ID: t.SlotID, TokenLabel: t.Label})
+	}
+	return slots, nil
+}
+
+func (m *Module) OpenSession(slotID uint, pin string) (pkcs11.Session, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := m.errs["OpenSession"]; err != nil {
+		return nil, err
+	}
+
+	for _, t := range m.tokens {
+		if t.SlotID != slotID {
+			continue
+		}
+		if t.Pin != pin {
+			return nil, ckError(p11.CKR_PIN_INCORRECT)
+		}
+		m.closed = false
+		return &session{m: m, t: t, generation: m.generation}, nil
+	}
+	return nil, ckError(p11.CKR_SLOT_ID_INVALID)
+}
+
+func (m *Module) Close() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.closed = true
+	return nil
+}
+
+type session struct {
+	m          *Module
+	t          *token
+	generation int
+}
+
+// check returns the error of the given operation, if any. The module mutex
+// must be held.
+func (s *session) check(operation string) error {
+	if s.generation != s.m.generation {
+		return ckError(p11.CKR_SESSION_HANDLE_INVALID)
+	}
+	return s.m.errs[operation]
+}
+
+func (s *session) FindObjects(template []pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	if err := s.check("FindObjects"); err != nil {
+		return nil, err
+	}
+
+	var found []pkcs11.ObjectHandle
+	for handle, o := range s.t.objects {
+		if o.matches(template) {
+			found = append(found, handle)
+		}
+	}
+	slices.Sort(found)
+	return found, nil
+}
+
+func (s *session) GetAttributes(handle pkcs11.ObjectHandle, types []uint) ([][]byte, error) {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	if err := s.check("GetAttributes"); err != nil {
+		return nil, err
+	}
+
+	o, ok := s.t.objects[handle]
+	if !ok {
+		return nil, ckError(p11.CKR_OBJECT_HANDLE_INVALID)
+	}
+
+	values := make([][]byte, 0, len(types))
+	for _, typ := range types {
+		value, ok := o.attributes[typ]
+		if !ok {
+			return nil, ckError(p11.CKR_ATTRIBUTE_TYPE_INVALID)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (s *session) SetAttributes(handle pkcs11.ObjectHandle, attributes []pkcs11.Attribute) error {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	if err := s.check("SetAttributes"); err != nil {
+		return err
+	}
+
+	o, ok := s.t.objects[handle]
+	if !ok {
+		return ckError(p11.CKR_OBJECT_HANDLE_INVALID)
+	}
+	for _, attribute := range attributes {
+		o.attributes[attribute.Type] = encode(attribute.Value)
+	}
+	return nil
+}
+
+func (s *session) GenerateKeyPair(mechanism pkcs11.Mechanism, publicTemplate, privateTemplate []pkcs11.Attribute) (pkcs11.ObjectHandle, pkcs11.ObjectHandle, error) {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	if err := s.check("GenerateKeyPair"); err != nil {
+		return 0, 0, err
+	}
+
+	publicKey := newObject(publicTemplate)
+	privateKey := newObject(privateTemplate)
+
+	var err error
+	switch mechanism.Type {
+	case p11.CKM_EC_KEY_PAIR_GEN:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(publicKey.attributes[p11.CKA_EC_PARAMS], &oid); err != nil {
+			return 0, 0, ckError(p11.CKR_TEMPLATE_INCONSISTENT)
+		}
+		switch {
+		case oid.Equal(oidNamedCurveP256):
+			privateKey.privateKey, err = s.m.generator.GenerateEC256Key()
+		case oid.Equal(oidNamedCurveP384):
+			privateKey.privateKey, err = s.m.generator.GenerateEC384Key()
+		default:
+			return 0, 0, ckError(p11.CKR_CURVE_NOT_SUPPORTED)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		ecPublicKey := privateKey.privateKey.Public().(*ecdsa.PublicKey)
+		point, err := ecPublicKey.Bytes()
+		if err != nil {
+			return 0, 0, err
+		}
+		publicKey.attributes[p11.CKA_EC_POINT], err = asn1.Marshal(point)
+		if err != nil {
+			return 0, 0, err
+		}
+	case p11.CKM_RSA_PKCS_KEY_PAIR_GEN:
+		switch binary.NativeEndian.Uint64(publicKey.attributes[p11.CKA_MODULUS_BITS]) {
+		case 2048:
+			privateKey.privateKey, err = s.m.generator.GenerateRSA2048Key()
+		case 4096:
+			privateKey.privateKey, err = s.m.generator.GenerateRSA4096Key()
+		default:
+			return 0, 0, ckError(p11.CKR_KEY_SIZE_RANGE)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		rsaPublicKey := privateKey.privateKey.Public().(*rsa.PublicKey)
+		publicKey.attributes[p11.CKA_MODULUS] = rsaPublicKey.N.Bytes()
+		publicKey.attributes[p11.CKA_PUBLIC_EXPONENT] = big64(rsaPublicKey.E)
+	default:
+		return 0, 0, ckError(p11.CKR_MECHANISM_INVALID)
+	}
+
+	publicHandle := s.m.addObject(s.t, publicKey)
+	privateHandle := s.m.addObject(s.t, privateKey)
+	return publicHandle, privateHandle, nil
+}
+
+func (s *session) Sign(mechanism pkcs11.Mechanism, handle pkcs11.ObjectHandle, data []byte) ([]byte, error) {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	if err := s.check("Sign"); err != nil {
+		return nil, err
+	}
+
+	o, ok := s.t.objects[handle]
+	if !ok || o.privateKey == nil {
+		return nil, ckError(p11.CKR_KEY_HANDLE_INVALID)
+	}
+
+	switch privateKey := o.privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		if mechanism.Type != p11.CKM_ECDSA {
+			return nil, ckError(p11.CKR_KEY_TYPE_INCONSISTENT)
+		}
+		r, ss, err := ecdsa.Sign(rand.Reader, privateKey, data)
+		if err != nil {
+			return nil, err
+		}
+		size := (privateKey.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		ss.FillBytes(signature[size:])
+		return signature, nil
+	case *rsa.PrivateKey:
+		switch mechanism.Type {
+		case p11.CKM_RSA_PKCS:
+			// The data is the DigestInfo, which is signed as is
+			return rsa.SignPKCS1v15(rand.Reader, privateKey, 0, data)
+		case p11.CKM_RSA_PKCS_PSS:
+			if mechanism.PSSParams == nil {
+				return nil, ckError(p11.CKR_MECHANISM_PARAM_INVALID)
+			}
+			hash, ok := pssHashes[mechanism.PSSParams.HashAlg]
+			if !ok {
+				return nil, ckError(p11.CKR_MECHANISM_PARAM_INVALID)
+			}
+			return rsa.SignPSS(rand.Reader, privateKey, hash, data, &rsa.PSSOptions{
+				SaltLength: int(mechanism.PSSParams.SaltLength),
+			})
+		default:
+			return nil, ckError(p11.CKR_KEY_TYPE_INCONSISTENT)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected private key type %T", privateKey)
+	}
+}
+
+func (s *session) DestroyObject(handle pkcs11.ObjectHandle) error {
+	s.m.mtx.Lock()
+	defer s.m.mtx.Unlock()
+
+	if err := s.check("DestroyObject"); err != nil {
+		return err
+	}
+
+	if _, ok := s.t.objects[handle]; !ok {
+		return ckError(p11.CKR_OBJECT_HANDLE_INVALID)
+	}
+	delete(s.t.objects, handle)
+	return nil
+}
+
+func (s *session) Close() error {
+	return nil
+}
+
+func (m *Module) addObject(t *token, o *object) pkcs11.ObjectHandle {
+	handle := m.nextHandle
+	m.nextHandle++
+	t.objects[handle] = o
+	return handle
+}
+
+func newObject(template []pkcs11.Attribute) *object {
+	o := &object{attributes: make(map[uint][]byte)}
+	for _, attribute := range template {
+		o.attributes[attribute.Type] = encode(attribute.Value)
+	}
+	return o
+}
+
+func (o *object) matches(template []pkcs11.Attribute) bool {
+	for _, attribute := range template {
+		value, ok := o.attributes[attribute.Type]
+		if !ok || string(value) != string(encode(attribute.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// encode encodes attribute values like the PKCS#11 library does.
+func encode(value any) []byte {
+	switch value := value.(type) {
+	case bool:
+		if value {
+			return []byte{1}
+		}
+		return []byte{0}
+	case uint:
+		b := make([]byte, 8)
+		binary.NativeEndian.PutUint64(b, uint64(value))
+		return b
+	case string:
+		return []byte(value)
+	case []byte:
+		return slices.Clone(value)
+	default:
+		panic(fmt.Sprintf("unsupported attribute value type %T", value))
+	}
+}
+
+func big64(e int) []byte {
+	b := binary.BigEndian.AppendUint64(nil, uint64(e))
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
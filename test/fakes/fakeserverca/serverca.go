@@ -242,3 +242,7 @@ func (c *CA) SetDisableJWTSVIDs(disableJWTSVIDs bool) {
 func (c *CA) SetDisableWITSVIDs(disableWITSVIDs bool) {
 	c.disableWITSVIDs = disableWITSVIDs
 }
+
+func (c *CA) SetWITSVIDsDisabled(disabled bool) {
+	c.SetDisableWITSVIDs(disabled)
+}
@@ -10,11 +10,20 @@ import (
 )
 
 func Load(t *testing.T, config Config) (upstreamauthority.UpstreamAuthority, *UpstreamAuthority) {
-	fake := New(t, config)
+	v1, _, fake := LoadWithWITKeyPublisher(t, config)
+	return v1, fake
+}
 
-	server := upstreamauthorityv1.UpstreamAuthorityPluginServer(fake)
+// LoadWithWITKeyPublisher loads the fake, also returning the facade for the
+// WITKeyPublisher service served by the fake.
+func LoadWithWITKeyPublisher(t *testing.T, config Config) (upstreamauthority.UpstreamAuthority, upstreamauthority.WITKeyPublisher, *UpstreamAuthority) {
+	fake := New(t, config)
 
 	v1 := new(upstreamauthority.V1)
-	plugintest.Load(t, catalog.MakeBuiltIn("fake", server), v1)
-	return v1, fake
+	witKeyPublisher := new(upstreamauthority.WITKeyPublisherV1)
+	plugintest.Load(t, catalog.MakeBuiltIn("fake",
+		upstreamauthorityv1.UpstreamAuthorityPluginServer(fake),
+		upstreamauthority.WITKeyPublisherServiceServer(fake),
+	), v1, plugintest.Services(witKeyPublisher))
+	return v1, witKeyPublisher, fake
 }
@@ -15,9 +15,11 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
 	"github.com/spiffe/spire/pkg/common/coretypes/jwtkey"
+	"github.com/spiffe/spire/pkg/common/coretypes/witkey"
 	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
 	"github.com/spiffe/spire/pkg/common/x509svid"
 	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/proto/private/server/witkeypublisher"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/testkey"
@@ -36,6 +38,7 @@ type Config struct {
 	TrustDomain                 spiffeid.TrustDomain
 	UseIntermediate             bool
 	DisallowPublishJWTKey       bool
+	DisallowPublishWITKey       bool
 	UseSubscribeToLocalBundle   bool
 	KeyUsage                    x509.KeyUsage
 	MutateMintX509CAResponse    func(*upstreamauthorityv1.MintX509CAResponse)
@@ -44,6 +47,7 @@ type Config struct {
 
 type UpstreamAuthority struct {
 	upstreamauthorityv1.UnimplementedUpstreamAuthorityServer
+	witkeypublisher.UnimplementedWITKeyPublisherServer
 
 	t      *testing.T
 	config Config
@@ -58,9 +62,13 @@ type UpstreamAuthority struct {
 	jwtKeysMtx sync.RWMutex
 	jwtKeys    []*common.PublicKey
 
+	witKeysMtx sync.RWMutex
+	witKeys    []*common.PublicKey
+
 	streamsMtx           sync.Mutex
 	mintX509CAStreams    map[chan struct{}]struct{}
 	publishJWTKeyStreams map[chan struct{}]struct{}
+	publishWITKeyStreams map[chan struct{}]struct{}
 }
 
 func New(t *testing.T, config Config) *UpstreamAuthority {
@@ -72,6 +80,7 @@ func New(t *testing.T, config Config) *UpstreamAuthority {
 		config:               config,
 		mintX509CAStreams:    make(map[chan struct{}]struct{}),
 		publishJWTKeyStreams: make(map[chan struct{}]struct{}),
+		publishWITKeyStreams: make(map[chan struct{}]struct{}),
 	}
 	ua.RotateX509CA()
 	return ua
@@ -142,6 +151,31 @@ func (ua *UpstreamAuthority) PublishJWTKeyAndSubscribe(req *upstreamauthorityv1.
 	}
 }
 
+func (ua *UpstreamAuthority) PublishWITKeyAndSubscribe(req *witkeypublisher.PublishWITKeyAndSubscribeRequest, stream witkeypublisher.WITKeyPublisher_PublishWITKeyAndSubscribeServer) error {
+	if ua.config.DisallowPublishWITKey {
+		return status.Error(codes.Unimplemented, "disallowed")
+	}
+
+	streamCh := ua.newPublishWITKeyStream()
+	defer ua.removePublishWITKeyStream(streamCh)
+
+	ua.AppendWITKey(witkey.RequireToCommonFromPublisherProto(req.WitKey))
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-streamCh:
+			if err := stream.Send(&witkeypublisher.PublishWITKeyAndSubscribeResponse{
+				UpstreamWitAuthorities: witkey.RequireToPublisherFromCommonProtos(ua.WITKeys()),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (ua *UpstreamAuthority) SubscribeToLocalBundle(req *upstreamauthorityv1.SubscribeToLocalBundleRequest, stream upstreamauthorityv1.UpstreamAuthority_SubscribeToLocalBundleServer) error {
 	if !ua.config.UseSubscribeToLocalBundle {
 		return status.Error(codes.Unimplemented, "fetching upstream trust bundle is unsupported")
@@ -251,6 +285,24 @@ func (ua *UpstreamAuthority) AppendJWTKey(jwtKey *common.PublicKey) {
 	ua.TriggerJWTKeysChanged()
 }
 
+func (ua *UpstreamAuthority) WITKeys() []*common.PublicKey {
+	ua.witKeysMtx.RLock()
+	defer ua.witKeysMtx.RUnlock()
+	return ua.witKeys
+}
+
+func (ua *UpstreamAuthority) AppendWITKey(witKey *common.PublicKey) {
+	ua.SetWITKeys(append(ua.WITKeys(), witKey))
+}
+
+// SetWITKeys replaces the upstream WIT keys, e.g. to taint or revoke them.
+func (ua *UpstreamAuthority) SetWITKeys(witKeys []*common.PublicKey) {
+	ua.witKeysMtx.Lock()
+	defer ua.witKeysMtx.Unlock()
+	ua.witKeys = witKeys
+	ua.TriggerWITKeysChanged()
+}
+
 func (ua *UpstreamAuthority) TriggerX509RootsChanged() {
 	ua.streamsMtx.Lock()
 	defer ua.streamsMtx.Unlock()
@@ -273,6 +325,17 @@ func (ua *UpstreamAuthority) TriggerJWTKeysChanged() {
 	}
 }
 
+func (ua *UpstreamAuthority) TriggerWITKeysChanged() {
+	ua.streamsMtx.Lock()
+	defer ua.streamsMtx.Unlock()
+	for streamCh := range ua.publishWITKeyStreams {
+		select {
+		case streamCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (ua *UpstreamAuthority) newMintX509CAStream() chan struct{} {
 	streamCh := make(chan struct{}, 1)
 	ua.streamsMtx.Lock()
@@ -323,6 +386,20 @@ func (ua *UpstreamAuthority) removePublishJWTKeyStream(streamCh chan struct{}) {
 	ua.streamsMtx.Unlock()
 }
 
+func (ua *UpstreamAuthority) newPublishWITKeyStream() chan struct{} {
+	streamCh := make(chan struct{}, 1)
+	ua.streamsMtx.Lock()
+	ua.publishWITKeyStreams[streamCh] = struct{}{}
+	ua.streamsMtx.Unlock()
+	return streamCh
+}
+
+func (ua *UpstreamAuthority) removePublishWITKeyStream(streamCh chan struct{}) {
+	ua.streamsMtx.Lock()
+	delete(ua.publishWITKeyStreams, streamCh)
+	ua.streamsMtx.Unlock()
+}
+
 func (ua *UpstreamAuthority) sendPublishJWTKeyStream(stream upstreamauthorityv1.UpstreamAuthority_PublishJWTKeyAndSubscribeServer, resp *upstreamauthorityv1.PublishJWTKeyResponse) error {
 	if ua.config.MutatePublishJWTKeyResponse != nil {
 		ua.config.MutatePublishJWTKeyResponse(resp)